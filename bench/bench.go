@@ -0,0 +1,251 @@
+// Package bench measures vector stores on a dataset: ingest throughput,
+// query latency percentiles and recall@K against exact search, for a list of
+// index variants.
+//
+//	dataset := bench.Synthetic(bench.SyntheticOptions{Records: 50000, Dimension: 256, Seed: 1})
+//	report, err := bench.Run(ctx, store, dataset, bench.Config{
+//		Backend: "postgres",
+//		TopK:    10,
+//		Variants: []bench.Variant{
+//			{Name: "exact"},
+//			{Name: "hnsw ef=40", Index: &hnsw, SessionSettings: map[string]string{"hnsw.ef_search": "40"}},
+//		},
+//	})
+//	report.WriteText(os.Stdout)
+//
+// The same dataset and seed give the same vectors on every backend, so the
+// reports of different stores are comparable.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultCollection  = "bench"
+	defaultTopK        = 10
+	defaultBatchSize   = 500
+	defaultConcurrency = 1
+)
+
+// Config configures a run.
+type Config struct {
+	// Backend labels the report, e.g. "postgres" or "typesense".
+	Backend string
+	// Collection is the collection name (default "bench"). It is created
+	// with the dataset dimension and Metric.
+	Collection string
+	Metric     vectordata.DistanceMetric
+	// TopK is the K of recall@K (default 10).
+	TopK int
+	// BatchSize is the number of records per Upsert (default 500).
+	BatchSize int
+	// Concurrency is the number of queries in flight (default 1).
+	Concurrency int
+	// SkipIngest reuses records already in the collection, e.g. to compare
+	// more variants without loading the dataset again.
+	SkipIngest bool
+	// Variants are measured in order. Without variants, one unnamed variant
+	// searches with the collection's current indexes.
+	Variants []Variant
+}
+
+// Variant is a search configuration to measure.
+type Variant struct {
+	Name string
+	// Index, when set, is ensured before the variant's queries run, and the
+	// build time is reported.
+	Index *vectordata.IndexOptions
+	// SessionSettings are passed with every search, e.g. "hnsw.ef_search"
+	// or "ivfflat.probes" on Postgres.
+	SessionSettings map[string]string
+}
+
+// Run ingests dataset into a collection of store and measures every variant.
+func Run(ctx context.Context, store vectordata.VectorStore, dataset Dataset, cfg Config) (Report, error) {
+	cfg = cfg.withDefaults()
+	if dataset.Dimension <= 0 || len(dataset.Queries) == 0 {
+		return Report{}, errors.New("bench: dataset needs a dimension and queries")
+	}
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      cfg.Collection,
+		Dimension: dataset.Dimension,
+		Metric:    cfg.Metric,
+	})
+	if err != nil {
+		return Report{}, fmt.Errorf("bench: ensure collection: %w", err)
+	}
+
+	report := Report{
+		Backend:   cfg.Backend,
+		Dataset:   dataset.Name,
+		Records:   len(dataset.Records),
+		Queries:   len(dataset.Queries),
+		Dimension: dataset.Dimension,
+		Metric:    cfg.Metric,
+		TopK:      cfg.TopK,
+	}
+	if !cfg.SkipIngest {
+		report.Ingest, err = ingest(ctx, collection, dataset.Records, cfg.BatchSize)
+		if err != nil {
+			return Report{}, err
+		}
+	}
+
+	truth := GroundTruth(dataset, cfg.Metric, cfg.TopK)
+	for _, variant := range cfg.Variants {
+		result, err := measure(ctx, collection, dataset.Queries, truth, variant, cfg)
+		if err != nil {
+			return Report{}, fmt.Errorf("bench: variant %q: %w", variant.Name, err)
+		}
+		report.Variants = append(report.Variants, result)
+	}
+	return report, nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.Collection == "" {
+		c.Collection = defaultCollection
+	}
+	if c.Metric == "" {
+		c.Metric = vectordata.DistanceCosine
+	}
+	if c.TopK <= 0 {
+		c.TopK = defaultTopK
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultConcurrency
+	}
+	if len(c.Variants) == 0 {
+		c.Variants = []Variant{{Name: "default"}}
+	}
+	return c
+}
+
+func ingest(ctx context.Context, collection vectordata.Collection, records []vectordata.Record, batchSize int) (IngestResult, error) {
+	started := time.Now()
+	for start := 0; start < len(records); start += batchSize {
+		end := min(start+batchSize, len(records))
+		if err := collection.Upsert(ctx, records[start:end]); err != nil {
+			return IngestResult{}, fmt.Errorf("bench: ingest records %d-%d: %w", start, end-1, err)
+		}
+	}
+	elapsed := time.Since(started)
+	return IngestResult{
+		Duration:         elapsed,
+		RecordsPerSecond: float64(len(records)) / elapsed.Seconds(),
+	}, nil
+}
+
+func measure(ctx context.Context, collection vectordata.Collection, queries [][]float32, truth [][]string, variant Variant, cfg Config) (VariantResult, error) {
+	result := VariantResult{Name: variant.Name}
+	if variant.Index != nil {
+		started := time.Now()
+		if err := collection.EnsureIndexes(ctx, *variant.Index); err != nil {
+			return VariantResult{}, fmt.Errorf("ensure indexes: %w", err)
+		}
+		result.IndexBuild = time.Since(started)
+	}
+
+	projection := vectordata.Projection{}
+	opts := vectordata.SearchOptions{Projection: &projection, SessionSettings: variant.SessionSettings}
+	latencies := make([]time.Duration, len(queries))
+	recalls := make([]float64, len(queries))
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	next := make(chan int)
+	started := time.Now()
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				queryStarted := time.Now()
+				results, err := collection.SearchByVector(ctx, queries[i], cfg.TopK, opts)
+				latencies[i] = time.Since(queryStarted)
+				if err != nil {
+					errOnce.Do(func() { firstErr = fmt.Errorf("query %d: %w", i, err) })
+					continue
+				}
+				recalls[i] = Recall(results, truth[i])
+			}
+		}()
+	}
+	for i := range queries {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if firstErr != nil {
+		return VariantResult{}, firstErr
+	}
+
+	elapsed := time.Since(started)
+	result.QueriesPerSecond = float64(len(queries)) / elapsed.Seconds()
+	result.Latency = latencyOf(latencies)
+	for _, recall := range recalls {
+		result.Recall += recall
+	}
+	result.Recall /= float64(len(recalls))
+	return result, nil
+}
+
+// GroundTruth returns the IDs of the exact topK records of every query,
+// nearest first.
+func GroundTruth(dataset Dataset, metric vectordata.DistanceMetric, topK int) [][]string {
+	type scored struct {
+		id       string
+		distance float64
+	}
+	truth := make([][]string, len(dataset.Queries))
+	scores := make([]scored, len(dataset.Records))
+	for q, query := range dataset.Queries {
+		for i, record := range dataset.Records {
+			scores[i] = scored{id: record.ID, distance: vectordata.Distance(metric, query, record.Vector)}
+		}
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].distance != scores[j].distance {
+				return scores[i].distance < scores[j].distance
+			}
+			return scores[i].id < scores[j].id
+		})
+		k := min(topK, len(scores))
+		truth[q] = make([]string, k)
+		for i := range k {
+			truth[q][i] = scores[i].id
+		}
+	}
+	return truth
+}
+
+// Recall is the share of want, the exact topK IDs, found in results.
+func Recall(results []vectordata.SearchResult, want []string) float64 {
+	if len(want) == 0 {
+		return 1
+	}
+	found := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		found[result.Record.ID] = struct{}{}
+	}
+	hits := 0
+	for _, id := range want {
+		if _, ok := found[id]; ok {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(want))
+}
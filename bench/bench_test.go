@@ -0,0 +1,212 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type memoryStore struct {
+	collection *memoryCollection
+}
+
+func (s *memoryStore) EnsureCollection(_ context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	if s.collection == nil {
+		s.collection = &memoryCollection{metric: spec.Metric}
+	}
+	return s.collection, nil
+}
+
+func (s *memoryStore) Collection(string, int, vectordata.DistanceMetric) vectordata.Collection {
+	return s.collection
+}
+
+// memoryCollection searches exactly, or skips the nearest record of every
+// query once an index is ensured, to simulate an approximate index.
+type memoryCollection struct {
+	vectordata.Collection
+	metric   vectordata.DistanceMetric
+	records  []vectordata.Record
+	upserts  int
+	indexed  bool
+	mu       sync.Mutex
+	settings []map[string]string
+}
+
+func (c *memoryCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	c.upserts++
+	c.records = append(c.records, records...)
+	return nil
+}
+
+func (c *memoryCollection) EnsureIndexes(context.Context, vectordata.IndexOptions) error {
+	c.indexed = true
+	return nil
+}
+
+func (c *memoryCollection) SearchByVector(_ context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.mu.Lock()
+	c.settings = append(c.settings, opts.SessionSettings)
+	c.mu.Unlock()
+	results := make([]vectordata.SearchResult, len(c.records))
+	for i, record := range c.records {
+		results[i] = vectordata.SearchResult{Record: record, Distance: vectordata.Distance(c.metric, vector, record.Vector)}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Distance < results[j].Distance })
+	if c.indexed {
+		results = results[1:]
+	}
+	return results[:min(topK, len(results))], nil
+}
+
+func TestRunMeasuresRecallPerVariant(t *testing.T) {
+	// Arrange
+	dataset := Synthetic(SyntheticOptions{Records: 200, Queries: 20, Dimension: 8, Seed: 7})
+	store := &memoryStore{}
+	index := vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW}}
+
+	// Act
+	report, err := Run(context.Background(), store, dataset, Config{
+		Backend:     "memory",
+		TopK:        5,
+		BatchSize:   64,
+		Concurrency: 1,
+		Variants: []Variant{
+			{Name: "exact"},
+			{Name: "approximate", Index: &index, SessionSettings: map[string]string{"hnsw.ef_search": "40"}},
+		},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if store.collection.upserts != 4 || report.Records != 200 || report.Ingest.RecordsPerSecond <= 0 {
+		t.Fatalf("unexpected ingest: %d upserts, %+v", store.collection.upserts, report)
+	}
+	if len(report.Variants) != 2 {
+		t.Fatalf("expected 2 variants, got %+v", report.Variants)
+	}
+	if exact := report.Variants[0]; exact.Recall != 1 || exact.IndexBuild != 0 || exact.Latency.P99 < exact.Latency.P50 {
+		t.Fatalf("unexpected exact variant %+v", exact)
+	}
+	if approximate := report.Variants[1]; math.Abs(approximate.Recall-0.8) > 1e-9 {
+		t.Fatalf("expected recall 4/5 when the nearest record is missed, got %v", approximate.Recall)
+	}
+	if last := store.collection.settings[len(store.collection.settings)-1]; last["hnsw.ef_search"] != "40" {
+		t.Fatalf("expected session settings to reach searches, got %v", last)
+	}
+}
+
+func TestRunConcurrentQueries(t *testing.T) {
+	// Arrange
+	dataset := Synthetic(SyntheticOptions{Records: 50, Queries: 30, Dimension: 4, Seed: 1})
+	store := &memoryStore{}
+
+	// Act
+	report, err := Run(context.Background(), store, dataset, Config{Metric: vectordata.DistanceL2, Concurrency: 4})
+
+	// Assert
+	if err != nil || len(report.Variants) != 1 || report.Variants[0].Recall != 1 {
+		t.Fatalf("unexpected report %+v (%v)", report, err)
+	}
+}
+
+func TestSyntheticIsReproducible(t *testing.T) {
+	// Act
+	a := Synthetic(SyntheticOptions{Records: 10, Queries: 2, Dimension: 3, Seed: 42})
+	b := Synthetic(SyntheticOptions{Records: 10, Queries: 2, Dimension: 3, Seed: 42})
+	c := Synthetic(SyntheticOptions{Records: 10, Queries: 2, Dimension: 3, Seed: 43})
+
+	// Assert
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("expected the same seed to give the same dataset")
+	}
+	if reflect.DeepEqual(a.Queries, c.Queries) {
+		t.Fatal("expected another seed to give other vectors")
+	}
+}
+
+func TestLoadJSONL(t *testing.T) {
+	// Arrange
+	records := `{"id":"a","vector":[1,0],"metadata":{"k":"v"}}
+{"id":"b","vector":[0,1]}
+`
+	queries := "[1,1]\n{\"vector\":[0.5,0]}\n"
+
+	// Act
+	dataset, err := LoadJSONL("tiny", strings.NewReader(records), strings.NewReader(queries))
+	_, mismatchErr := LoadJSONL("bad", strings.NewReader(records), strings.NewReader("[1,2,3]\n"))
+
+	// Assert
+	if err != nil || dataset.Dimension != 2 || len(dataset.Records) != 2 || len(dataset.Queries) != 2 || dataset.Records[0].Metadata["k"] != "v" {
+		t.Fatalf("unexpected dataset %+v (%v)", dataset, err)
+	}
+	if !errors.Is(mismatchErr, vectordata.ErrDimensionMismatch) || !strings.Contains(mismatchErr.Error(), "line 1") {
+		t.Fatalf("expected a dimension mismatch on line 1, got %v", mismatchErr)
+	}
+}
+
+func TestGroundTruthByMetric(t *testing.T) {
+	// Arrange
+	dataset := Dataset{
+		Dimension: 2,
+		Records: []vectordata.Record{
+			{ID: "near", Vector: []float32{1, 0}},
+			{ID: "long", Vector: []float32{10, 1}},
+		},
+		Queries: [][]float32{{1, 0}},
+	}
+
+	// Act
+	l2 := GroundTruth(dataset, vectordata.DistanceL2, 1)
+	ip := GroundTruth(dataset, vectordata.DistanceInnerProduct, 1)
+
+	// Assert
+	if l2[0][0] != "near" || ip[0][0] != "long" {
+		t.Fatalf("l2 = %v, inner product = %v", l2, ip)
+	}
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	// Arrange
+	durations := make([]time.Duration, 100)
+	for i := range durations {
+		durations[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	// Act
+	latency := latencyOf(durations)
+
+	// Assert
+	if latency.P50 != 50*time.Millisecond || latency.P99 != 99*time.Millisecond || latency.Max != 100*time.Millisecond || latency.Mean != 50500*time.Microsecond {
+		t.Fatalf("unexpected latency %+v", latency)
+	}
+}
+
+func TestWriteComparison(t *testing.T) {
+	// Arrange
+	reports := []Report{
+		{Backend: "postgres", Dataset: "d", Variants: []VariantResult{{Name: "hnsw", Recall: 0.95}}},
+		{Backend: "typesense", Dataset: "d", Variants: []VariantResult{{Name: "default", Recall: 0.9}}},
+	}
+	var out bytes.Buffer
+
+	// Act
+	err := WriteComparison(&out, reports...)
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if err != nil || len(lines) != 3 || !strings.Contains(lines[1], "postgres") || !strings.Contains(lines[2], "0.9000") {
+		t.Fatalf("unexpected table (%v):\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// Report is the outcome of a run. It encodes to JSON for storing and
+// comparing runs.
+type Report struct {
+	Backend   string                    `json:"backend"`
+	Dataset   string                    `json:"dataset"`
+	Records   int                       `json:"records"`
+	Queries   int                       `json:"queries"`
+	Dimension int                       `json:"dimension"`
+	Metric    vectordata.DistanceMetric `json:"metric"`
+	TopK      int                       `json:"top_k"`
+	// Ingest is zero when Config.SkipIngest was set.
+	Ingest   IngestResult    `json:"ingest"`
+	Variants []VariantResult `json:"variants"`
+}
+
+// IngestResult measures loading the dataset.
+type IngestResult struct {
+	Duration         time.Duration `json:"duration_ns"`
+	RecordsPerSecond float64       `json:"records_per_second"`
+}
+
+// VariantResult measures the queries of one variant.
+type VariantResult struct {
+	Name string `json:"name"`
+	// IndexBuild is the time EnsureIndexes took, zero without an index.
+	IndexBuild       time.Duration `json:"index_build_ns"`
+	Latency          Latency       `json:"latency"`
+	QueriesPerSecond float64       `json:"queries_per_second"`
+	// Recall is the mean recall@K over the queries.
+	Recall float64 `json:"recall"`
+}
+
+// Latency summarizes query latencies. Percentiles use the nearest-rank
+// method.
+type Latency struct {
+	Mean time.Duration `json:"mean_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P90  time.Duration `json:"p90_ns"`
+	P95  time.Duration `json:"p95_ns"`
+	P99  time.Duration `json:"p99_ns"`
+	Max  time.Duration `json:"max_ns"`
+}
+
+func latencyOf(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		return sorted[max(rank, 1)-1]
+	}
+	return Latency{
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// WriteText writes the report as a table.
+func (r Report) WriteText(w io.Writer) error {
+	return WriteComparison(w, r)
+}
+
+// WriteComparison writes one table row per variant of every report, so runs
+// of different backends or index parameters line up.
+func WriteComparison(w io.Writer, reports ...Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "backend\tdataset\tvariant\tingest rec/s\tindex build\tqps\tp50\tp95\tp99\trecall@k\t")
+	for _, report := range reports {
+		for _, variant := range report.Variants {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.0f\t%s\t%.1f\t%s\t%s\t%s\t%.4f\t\n",
+				report.Backend,
+				report.Dataset,
+				variant.Name,
+				report.Ingest.RecordsPerSecond,
+				round(variant.IndexBuild),
+				variant.QueriesPerSecond,
+				round(variant.Latency.P50),
+				round(variant.Latency.P95),
+				round(variant.Latency.P99),
+				variant.Recall,
+			)
+		}
+	}
+	return tw.Flush()
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/bench"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func (a *app) bench(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	collection := flags.String("collection", "bench", "collection to create and load")
+	records := flags.Int("records", 10000, "synthetic records")
+	queries := flags.Int("queries", 100, "synthetic queries")
+	dimension := flags.Int("dim", 128, "synthetic vector dimension")
+	seed := flags.Uint64("seed", 1, "synthetic dataset seed")
+	dataFile := flags.String("data", "", "JSONL records to load instead of synthetic data")
+	queryFile := flags.String("query-file", "", "JSONL query vectors, required with -data")
+	metric := flags.String("metric", string(vectordata.DistanceCosine), "distance metric")
+	topK := flags.Int("k", 10, "K of recall@K")
+	batch := flags.Int("batch", 500, "records per Upsert")
+	concurrency := flags.Int("concurrency", 1, "queries in flight")
+	exact := flags.Bool("exact", true, "measure a variant without vector index first")
+	hnswEf := flags.String("hnsw-ef", "40,100", "comma-separated hnsw.ef_search values, or empty to skip HNSW")
+	hnswM := flags.Int("hnsw-m", 16, "HNSW m")
+	ivfProbes := flags.String("ivfflat-probes", "", "comma-separated ivfflat.probes values, or empty to skip IVFFlat")
+	ivfLists := flags.Int("ivfflat-lists", 100, "IVFFlat lists")
+	skipIngest := flags.Bool("skip-ingest", false, "reuse records already in the collection")
+	asJSON := flags.Bool("json", false, "write the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("bench: unexpected arguments; name the collection with -collection")
+	}
+
+	dataset, err := loadDataset(*dataFile, *queryFile, bench.SyntheticOptions{
+		Records:   *records,
+		Queries:   *queries,
+		Dimension: *dimension,
+		Seed:      *seed,
+	})
+	if err != nil {
+		return err
+	}
+	variants, err := benchVariants(*exact, *hnswEf, *hnswM, *ivfProbes, *ivfLists)
+	if err != nil {
+		return err
+	}
+
+	report, err := bench.Run(ctx, a.store, dataset, bench.Config{
+		Backend:     "postgres",
+		Collection:  *collection,
+		Metric:      vectordata.DistanceMetric(*metric),
+		TopK:        *topK,
+		BatchSize:   *batch,
+		Concurrency: *concurrency,
+		SkipIngest:  *skipIngest,
+		Variants:    variants,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return report.WriteText(a.stdout)
+}
+
+func loadDataset(dataFile, queryFile string, synthetic bench.SyntheticOptions) (bench.Dataset, error) {
+	if dataFile == "" {
+		return bench.Synthetic(synthetic), nil
+	}
+	if queryFile == "" {
+		return bench.Dataset{}, errors.New("bench: -query-file is required with -data")
+	}
+	records, err := os.Open(dataFile)
+	if err != nil {
+		return bench.Dataset{}, err
+	}
+	defer records.Close()
+	queries, err := os.Open(queryFile)
+	if err != nil {
+		return bench.Dataset{}, err
+	}
+	defer queries.Close()
+	return bench.LoadJSONL(dataFile, records, queries)
+}
+
+// benchVariants builds the exact variant, then one variant per HNSW
+// ef_search or IVFFlat probes value. Variants run in order, so the exact one
+// runs before any vector index exists. HNSW and IVFFlat are measured in
+// separate runs, since with both indexes the planner picks one of them.
+func benchVariants(exact bool, hnswEf string, hnswM int, ivfProbes string, ivfLists int) ([]bench.Variant, error) {
+	if strings.TrimSpace(hnswEf) != "" && strings.TrimSpace(ivfProbes) != "" {
+		return nil, errors.New("bench: measure HNSW and IVFFlat in separate runs; set -hnsw-ef= to skip HNSW")
+	}
+	var variants []bench.Variant
+	if exact {
+		variants = append(variants, bench.Variant{Name: "exact"})
+	}
+	efs, err := parseInts(hnswEf)
+	if err != nil {
+		return nil, fmt.Errorf("bench: -hnsw-ef: %w", err)
+	}
+	hnsw := &vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{
+		Method: vectordata.IndexMethodHNSW,
+		HNSW:   vectordata.HNSWOptions{M: hnswM, EfConstruction: 64},
+	}}
+	for _, ef := range efs {
+		variants = append(variants, bench.Variant{
+			Name:            fmt.Sprintf("hnsw m=%d ef=%d", hnswM, ef),
+			Index:           hnsw,
+			SessionSettings: map[string]string{"hnsw.ef_search": strconv.Itoa(ef)},
+		})
+	}
+	probes, err := parseInts(ivfProbes)
+	if err != nil {
+		return nil, fmt.Errorf("bench: -ivfflat-probes: %w", err)
+	}
+	ivfflat := &vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{
+		Method:  vectordata.IndexMethodIVFFlat,
+		IVFFlat: vectordata.IVFFlatOptions{Lists: ivfLists},
+	}}
+	for _, probe := range probes {
+		variants = append(variants, bench.Variant{
+			Name:            fmt.Sprintf("ivfflat lists=%d probes=%d", ivfLists, probe),
+			Index:           ivfflat,
+			SessionSettings: map[string]string{"ivfflat.probes": strconv.Itoa(probe)},
+		})
+	}
+	return variants, nil
+}
+
+func parseInts(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var out []int
+	for _, part := range strings.Split(raw, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("%q is not a positive integer", part)
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
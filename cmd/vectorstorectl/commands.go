@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultImportBatch = 500
+	exportPageSize     = 1000
+	maxSnippetRunes    = 60
+)
+
+// recordLine is the JSONL form of a record used by import and export.
+type recordLine struct {
+	ID         string         `json:"id"`
+	Vector     []float32      `json:"vector,omitempty"`
+	Vector64   []float64      `json:"vector64,omitempty"`
+	VectorInt8 []int8         `json:"vector_int8,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	Content    *string        `json:"content,omitempty"`
+}
+
+func (l recordLine) record() vectordata.Record {
+	return vectordata.Record{
+		ID:         l.ID,
+		Vector:     l.Vector,
+		Vector64:   l.Vector64,
+		VectorInt8: l.VectorInt8,
+		Metadata:   l.Metadata,
+		Content:    l.Content,
+	}
+}
+
+func lineOf(record vectordata.Record) recordLine {
+	return recordLine{
+		ID:         record.ID,
+		Vector:     record.Vector,
+		Vector64:   record.Vector64,
+		VectorInt8: record.VectorInt8,
+		Metadata:   record.Metadata,
+		Content:    record.Content,
+	}
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	collections, err := a.store.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDIMENSION\tMETRIC\tELEMENT\tCREATED")
+	for _, info := range collections {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", info.Name, info.Dimension, info.Metric, info.ElementType, info.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func (a *app) create(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	dimension := flags.Int("dim", 0, "vector dimension (required)")
+	metric := flags.String("metric", string(vectordata.DistanceCosine), "distance metric")
+	element := flags.String("element", string(vectordata.ElementFloat32), "vector element type")
+	fastDimension := flags.Int("fast-dim", 0, "reduced dimension searched before rescoring (0 disables)")
+	normalize := flags.Bool("normalize", false, "scale vectors to unit length")
+	autoMigrate := flags.Bool("auto-migrate", false, "add missing columns to an existing table instead of failing")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	if *dimension <= 0 {
+		return errors.New("create: -dim must be > 0")
+	}
+	mode := vectordata.EnsureStrict
+	if *autoMigrate {
+		mode = vectordata.EnsureAutoMigrate
+	}
+	collection, err := a.store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:             name,
+		Dimension:        *dimension,
+		Metric:           vectordata.DistanceMetric(*metric),
+		Mode:             mode,
+		NormalizeVectors: *normalize,
+		ElementType:      vectordata.VectorElementType(*element),
+		FastDimension:    *fastDimension,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "collection %q ready (dimension %d, metric %s)\n", collection.Name(), collection.Dimension(), collection.Metric())
+	return nil
+}
+
+func (a *app) describe(ctx context.Context, args []string) error {
+	name, err := parseCommand(flag.NewFlagSet("describe", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	info, err := a.store.DescribeCollection(ctx, name)
+	if err != nil {
+		if errors.Is(err, vectordata.ErrNotFound) {
+			return fmt.Errorf("collection %q not found", name)
+		}
+		return err
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "name\t%s\n", info.Name)
+	fmt.Fprintf(w, "dimension\t%d\n", info.Dimension)
+	fmt.Fprintf(w, "metric\t%s\n", info.Metric)
+	fmt.Fprintf(w, "element type\t%s\n", info.ElementType)
+	fmt.Fprintf(w, "normalize vectors\t%t\n", info.NormalizeVectors)
+	fmt.Fprintf(w, "fast dimension\t%d\n", info.FastDimension)
+	fmt.Fprintf(w, "created\t%s\n", info.CreatedAt.Format(time.RFC3339))
+	return w.Flush()
+}
+
+func (a *app) drop(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("drop", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "confirm dropping the collection and all its records")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		return fmt.Errorf("drop: refusing to drop %q without -yes", name)
+	}
+	if err := a.store.DropCollection(ctx, name); err != nil {
+		if errors.Is(err, vectordata.ErrNotFound) {
+			return fmt.Errorf("collection %q not found", name)
+		}
+		return err
+	}
+	fmt.Fprintf(a.stdout, "collection %q dropped\n", name)
+	return nil
+}
+
+func (a *app) index(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("index", flag.ContinueOnError)
+	method := flags.String("method", string(vectordata.IndexMethodHNSW), "vector index method, or none")
+	m := flags.Int("m", 16, "HNSW m")
+	efConstruction := flags.Int("ef-construction", 64, "HNSW ef_construction")
+	lists := flags.Int("lists", 100, "IVFFlat lists")
+	metadata := flags.Bool("metadata", false, "ensure the metadata JSONB index")
+	text := flags.Bool("text", false, "ensure the full-text index over content")
+	concurrent := flags.Bool("concurrent", false, "build the vector index without blocking writes")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	var opts vectordata.IndexOptions
+	if *method != "none" {
+		opts.Vector = &vectordata.VectorIndexOptions{
+			Method:     vectordata.IndexMethod(*method),
+			Metric:     collection.Metric(),
+			HNSW:       vectordata.HNSWOptions{M: *m, EfConstruction: *efConstruction},
+			IVFFlat:    vectordata.IVFFlatOptions{Lists: *lists},
+			Concurrent: *concurrent,
+		}
+	}
+	if *metadata {
+		opts.Metadata = &vectordata.MetadataIndexOptions{UsePathOps: true}
+	}
+	if *text {
+		opts.Text = &vectordata.TextIndexOptions{}
+	}
+	if err := collection.EnsureIndexes(ctx, opts); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "indexes of %q ensured\n", name)
+	return nil
+}
+
+func (a *app) importRecords(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "-", "JSONL file, or - for stdin")
+	batch := flags.Int("batch", defaultImportBatch, "records per Upsert")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	if *batch <= 0 {
+		return errors.New("import: -batch must be > 0")
+	}
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+	in, closeIn, err := a.input(*file)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20)
+	var pending []vectordata.Record
+	imported, lineNumber := 0, 0
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := collection.Upsert(ctx, pending); err != nil {
+			return fmt.Errorf("import: upsert records before line %d: %w", lineNumber+1, err)
+		}
+		imported += len(pending)
+		pending = pending[:0]
+		return nil
+	}
+	for scanner.Scan() {
+		lineNumber++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var line recordLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			return fmt.Errorf("import: line %d: %w", lineNumber, err)
+		}
+		pending = append(pending, line.record())
+		if len(pending) == *batch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("import: read: %w", err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "imported %d records into %q\n", imported, name)
+	return nil
+}
+
+func (a *app) exportRecords(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "-", "JSONL file, or - for stdout")
+	rawFilter := flags.String("filter", "", "filter in the JSON filter DSL")
+	vectors := flags.Bool("vectors", true, "include vectors")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	filter, err := vectordata.ParseFilterJSON([]byte(*rawFilter))
+	if err != nil {
+		return err
+	}
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+	lister, ok := collection.(vectordata.RecordLister)
+	if !ok {
+		return fmt.Errorf("export: collection %q cannot list records", name)
+	}
+	out, closeOut, err := a.output(*file)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	writer := bufio.NewWriter(out)
+	encoder := json.NewEncoder(writer)
+	projection := vectordata.Projection{IncludeVector: *vectors, IncludeMetadata: true, IncludeContent: true}
+	opts := vectordata.ListOptions{Filter: filter, Limit: exportPageSize, Projection: &projection}
+	for {
+		page, err := lister.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, record := range page {
+			if err := encoder.Encode(lineOf(record)); err != nil {
+				return fmt.Errorf("export: write: %w", err)
+			}
+		}
+		if len(page) < opts.Limit {
+			break
+		}
+		opts.After = page[len(page)-1].ID
+	}
+	return writer.Flush()
+}
+
+func (a *app) search(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	rawVector := flags.String("vector", "", "comma-separated query vector")
+	text := flags.String("text", "", "query text, embedded with the OpenAI-compatible embeddings API")
+	topK := flags.Int("k", 10, "number of results")
+	rawFilter := flags.String("filter", "", "filter in the JSON filter DSL")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+	if (*rawVector == "") == (*text == "") {
+		return errors.New("search: exactly one of -vector and -text is required")
+	}
+	filter, err := vectordata.ParseFilterJSON([]byte(*rawFilter))
+	if err != nil {
+		return err
+	}
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	var vector []float32
+	if *rawVector != "" {
+		vector, err = parseVector(*rawVector)
+	} else {
+		var embedder vectordata.Embedder
+		if embedder, err = a.textEmbedder(); err != nil {
+			return err
+		}
+		vector, err = embedder.Embed(ctx, *text)
+	}
+	if err != nil {
+		return fmt.Errorf("search: query vector: %w", err)
+	}
+
+	results, err := collection.SearchByVector(ctx, vector, *topK, vectordata.SearchOptions{Filter: filter})
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tSCORE\tDISTANCE\tCONTENT")
+	for i, result := range results {
+		fmt.Fprintf(w, "%d\t%s\t%.4f\t%.4f\t%s\n", i+1, result.Record.ID, result.Score, result.Distance, snippet(result.Record.Content))
+	}
+	return w.Flush()
+}
+
+func (a *app) stats(ctx context.Context, args []string) error {
+	name, err := parseCommand(flag.NewFlagSet("stats", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+	count, err := collection.Count(ctx, nil)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "name\t%s\n", collection.Name())
+	fmt.Fprintf(w, "dimension\t%d\n", collection.Dimension())
+	fmt.Fprintf(w, "metric\t%s\n", collection.Metric())
+	fmt.Fprintf(w, "records\t%d\n", count)
+	return w.Flush()
+}
+
+// textEmbedder returns the embedder of -text queries and ingested content,
+// configured from the environment on first use.
+func (a *app) textEmbedder() (vectordata.Embedder, error) {
+	if a.embedder == nil {
+		embedder, err := newOpenAIEmbedderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		a.embedder = embedder
+	}
+	return a.embedder, nil
+}
+
+func (a *app) input(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return a.stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+func (a *app) output(path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return a.stdout, func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// parseVector parses a comma-separated vector, optionally in brackets.
+func parseVector(raw string) ([]float32, error) {
+	raw = strings.Trim(strings.TrimSpace(raw), "[]")
+	parts := strings.Split(raw, ",")
+	vector := make([]float32, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("component %d: %w", i, err)
+		}
+		vector[i] = float32(value)
+	}
+	return vector, nil
+}
+
+func snippet(content *string) string {
+	if content == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(*content), " ")
+	if runes := []rune(text); len(runes) > maxSnippetRunes {
+		return string(runes[:maxSnippetRunes]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/ingest"
+)
+
+func (a *app) ingest(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	file := flags.String("file", "-", "CSV, JSONL or Parquet file, or - for stdin (CSV and JSONL only)")
+	format := flags.String("format", "", "csv, jsonl or parquet (default from the file extension)")
+	delimiter := flags.String("delimiter", ",", "CSV field delimiter")
+	idColumn := flags.String("id", "id", "ID column")
+	vectorColumn := flags.String("vector", "vector", "vector column")
+	contentColumn := flags.String("content", "content", "content column")
+	metadataObject := flags.String("metadata-object", "metadata", "column holding a JSON object of metadata")
+	metadataColumns := flags.String("metadata", "", "comma-separated columns copied into metadata")
+	embed := flags.Bool("embed", false, "embed the content of rows without a vector with the OpenAI-compatible embeddings API")
+	batch := flags.Int("batch", defaultImportBatch, "records per Upsert")
+	checkpoint := flags.String("checkpoint", "", "file recording progress; a rerun resumes after the rows it records")
+	name, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	source, closeSource, err := a.ingestSource(*file, *format, *delimiter)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+	collection, err := a.open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	opts := ingest.Options{
+		Mapping: ingest.Mapping{
+			ID:             *idColumn,
+			Vector:         *vectorColumn,
+			Content:        *contentColumn,
+			MetadataObject: *metadataObject,
+			Metadata:       splitList(*metadataColumns),
+		},
+		BatchSize: *batch,
+		Progress: func(result ingest.Result) {
+			fmt.Fprintf(a.stdout, "%d rows done\n", result.Rows)
+		},
+	}
+	if *embed {
+		if opts.Embedder, err = a.textEmbedder(); err != nil {
+			return err
+		}
+	}
+	if *checkpoint != "" {
+		opts.Checkpoint = ingest.FileCheckpoint(*checkpoint)
+	}
+	result, err := ingest.Run(ctx, collection, source, opts)
+	if err != nil {
+		return err
+	}
+	if result.Resumed > 0 {
+		fmt.Fprintf(a.stdout, "resumed after %d rows; ", result.Resumed)
+	}
+	fmt.Fprintf(a.stdout, "ingested %d records into %q\n", result.Upserted, name)
+	return nil
+}
+
+func (a *app) ingestSource(file, format, delimiter string) (ingest.Source, func(), error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	}
+	if format == "ndjson" {
+		format = "jsonl"
+	}
+	switch format {
+	case "csv", "jsonl":
+	case "parquet":
+		return parquetSource(file)
+	case "":
+		return nil, nil, errors.New("ingest: set -format for stdin or files without an extension")
+	default:
+		return nil, nil, fmt.Errorf("ingest: unsupported format %q, expected csv, jsonl or parquet", format)
+	}
+	comma := []rune(delimiter)
+	if len(comma) != 1 {
+		return nil, nil, errors.New("ingest: -delimiter must be one character")
+	}
+
+	in, closeIn, err := a.input(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format == "csv" {
+		return ingest.NewCSVSource(in, ingest.CSVOptions{Comma: comma[0]}), closeIn, nil
+	}
+	return ingest.NewJSONLSource(in), closeIn, nil
+}
+
+// parquetSource opens a Parquet file, which is read by offset and so cannot
+// come from stdin.
+func parquetSource(file string) (ingest.Source, func(), error) {
+	if file == "-" {
+		return nil, nil, errors.New("ingest: Parquet is read from a file, not stdin")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	source, err := ingest.NewParquetSource(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("ingest: %w", err)
+	}
+	return source, func() { f.Close() }, nil
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+// Command vectorstorectl administers vector collections: it creates,
+// describes and drops collections, ensures indexes, imports and exports
+// JSONL, ingests CSV, JSONL or Parquet with column mapping, runs searches,
+// shows stats and runs benchmarks.
+//
+//	vectorstorectl -dsn postgres://localhost/app create -dim 768 docs
+//	vectorstorectl import -file docs.jsonl docs
+//	vectorstorectl search -text "cheap hosting" -k 5 docs
+//
+// The DSN is taken from -dsn or VECTORSTORE_DSN. Only Postgres DSNs are
+// supported; this module has no SQL Server store.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/stores/postgres"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// admin is the store API the commands use. *postgres.PostgresVectorStore
+// implements it.
+type admin interface {
+	vectordata.VectorStore
+	ListCollections(ctx context.Context) ([]postgres.CollectionInfo, error)
+	DescribeCollection(ctx context.Context, name string) (postgres.CollectionInfo, error)
+	DropCollection(ctx context.Context, name string) error
+}
+
+type app struct {
+	store  admin
+	stdin  io.Reader
+	stdout io.Writer
+	// embedder embeds search -text queries; nil until needed.
+	embedder vectordata.Embedder
+}
+
+type command struct {
+	usage   string
+	summary string
+	run     func(a *app, ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"list":     {"list", "List collections", (*app).list},
+	"create":   {"create -dim N [-metric cosine|l2|inner_product] [-element float32|float64|int8] [-fast-dim N] [-normalize] NAME", "Create a collection or check its schema", (*app).create},
+	"describe": {"describe NAME", "Show the catalog entry of a collection", (*app).describe},
+	"drop":     {"drop -yes NAME", "Drop a collection and its records", (*app).drop},
+	"index":    {"index [-method hnsw|ivfflat] [-m N] [-ef-construction N] [-lists N] [-metadata] [-text] [-concurrent] NAME", "Ensure indexes", (*app).index},
+	"import":   {"import [-file PATH] [-batch N] NAME", "Upsert JSONL records from a file or stdin", (*app).importRecords},
+	"export":   {"export [-file PATH] [-filter JSON] [-vectors=false] NAME", "Write records as JSONL to a file or stdout", (*app).exportRecords},
+	"search":   {"search (-vector 0.1,0.2,... | -text QUERY) [-k N] [-filter JSON] NAME", "Search by an inline vector, or by text embedded with OPENAI_API_KEY", (*app).search},
+	"stats":    {"stats NAME", "Show the schema and record count of a collection", (*app).stats},
+	"ingest":   {"ingest [-file PATH] [-format csv|jsonl|parquet] [-id COL] [-vector COL] [-content COL] [-metadata COL,...] [-embed] [-checkpoint PATH] NAME", "Stream CSV, JSONL or Parquet rows into a collection with column mapping", (*app).ingest},
+	"bench":    {"bench [-collection NAME] [-records N -dim N | -data PATH -query-file PATH] [-k N] [-hnsw-ef 40,100 | -ivfflat-probes 1,10] [-json]", "Measure ingest, latency and recall@K", (*app).bench},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "vectorstorectl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("vectorstorectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dsn := flags.String("dsn", os.Getenv("VECTORSTORE_DSN"), "store DSN (default $VECTORSTORE_DSN)")
+	schema := flags.String("schema", "public", "schema holding the collections")
+	flags.Usage = func() { usage(flags, stderr) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	store, closeStore, err := openStore(ctx, *dsn, *schema)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+	a := &app{store: store, stdin: stdin, stdout: stdout}
+	return cmd.run(a, ctx, flags.Args()[1:])
+}
+
+func usage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: vectorstorectl [-dsn DSN] [-schema SCHEMA] COMMAND [flags] [NAME]")
+	fmt.Fprintln(w)
+	for _, name := range []string{"list", "create", "describe", "drop", "index", "import", "export", "ingest", "search", "stats", "bench"} {
+		fmt.Fprintf(w, "  %-9s %s\n            %s\n", name, commands[name].summary, commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	flags.PrintDefaults()
+}
+
+// openStore connects to the store named by dsn.
+func openStore(ctx context.Context, dsn, schema string) (admin, func(), error) {
+	if dsn == "" {
+		return nil, nil, errors.New("no DSN: set -dsn or VECTORSTORE_DSN")
+	}
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse DSN: %w", err)
+	}
+	switch parsed.Scheme {
+	case "postgres", "postgresql":
+	case "sqlserver", "mssql":
+		return nil, nil, fmt.Errorf("DSN scheme %q: no SQL Server store is available in this module", parsed.Scheme)
+	default:
+		return nil, nil, fmt.Errorf("unsupported DSN scheme %q, expected postgres", parsed.Scheme)
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect: %w", err)
+	}
+	opts := postgres.DefaultStoreOptions()
+	opts.Schema = schema
+	store, err := postgres.NewVectorStore(pool, opts)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return store, pool.Close, nil
+}
+
+// parseCommand parses the flags of a command that takes one collection name.
+func parseCommand(flags *flag.FlagSet, args []string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if flags.NArg() != 1 || strings.TrimSpace(flags.Arg(0)) == "" {
+		return "", fmt.Errorf("%s: expected one collection name", flags.Name())
+	}
+	return flags.Arg(0), nil
+}
+
+// open returns a handle to an existing collection, configured from its
+// catalog entry.
+func (a *app) open(ctx context.Context, name string) (vectordata.Collection, error) {
+	info, err := a.store.DescribeCollection(ctx, name)
+	if err != nil {
+		if errors.Is(err, vectordata.ErrNotFound) {
+			return nil, fmt.Errorf("collection %q not found", name)
+		}
+		return nil, err
+	}
+	return a.store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:             info.Name,
+		Dimension:        info.Dimension,
+		Metric:           info.Metric,
+		Mode:             vectordata.EnsureStrict,
+		NormalizeVectors: info.NormalizeVectors,
+		ElementType:      info.ElementType,
+		FastDimension:    info.FastDimension,
+	})
+}
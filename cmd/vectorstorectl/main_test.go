@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/stores/postgres"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/parquet-go/parquet-go"
+)
+
+type fakeStore struct {
+	infos       map[string]postgres.CollectionInfo
+	collections map[string]*fakeCollection
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{infos: map[string]postgres.CollectionInfo{}, collections: map[string]*fakeCollection{}}
+}
+
+func (s *fakeStore) EnsureCollection(_ context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	if _, ok := s.collections[spec.Name]; !ok {
+		s.infos[spec.Name] = postgres.CollectionInfo{Name: spec.Name, Dimension: spec.Dimension, Metric: spec.Metric, ElementType: spec.ElementType}
+		s.collections[spec.Name] = &fakeCollection{name: spec.Name, dimension: spec.Dimension, metric: spec.Metric, records: map[string]vectordata.Record{}}
+	}
+	return s.collections[spec.Name], nil
+}
+
+func (s *fakeStore) Collection(name string, _ int, _ vectordata.DistanceMetric) vectordata.Collection {
+	return s.collections[name]
+}
+
+func (s *fakeStore) ListCollections(context.Context) ([]postgres.CollectionInfo, error) {
+	var out []postgres.CollectionInfo
+	for _, info := range s.infos {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *fakeStore) DescribeCollection(_ context.Context, name string) (postgres.CollectionInfo, error) {
+	info, ok := s.infos[name]
+	if !ok {
+		return postgres.CollectionInfo{}, vectordata.ErrNotFound
+	}
+	return info, nil
+}
+
+func (s *fakeStore) DropCollection(_ context.Context, name string) error {
+	if _, ok := s.infos[name]; !ok {
+		return vectordata.ErrNotFound
+	}
+	delete(s.infos, name)
+	delete(s.collections, name)
+	return nil
+}
+
+type fakeCollection struct {
+	vectordata.Collection
+	name       string
+	dimension  int
+	metric     vectordata.DistanceMetric
+	records    map[string]vectordata.Record
+	upserts    int
+	lastVector []float32
+}
+
+func (c *fakeCollection) Name() string                      { return c.name }
+func (c *fakeCollection) Dimension() int                    { return c.dimension }
+func (c *fakeCollection) Metric() vectordata.DistanceMetric { return c.metric }
+
+func (c *fakeCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	c.upserts++
+	for _, record := range records {
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *fakeCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	return int64(len(c.records)), nil
+}
+
+func (c *fakeCollection) SearchByVector(_ context.Context, vector []float32, topK int, _ vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.lastVector = vector
+	var results []vectordata.SearchResult
+	for _, id := range c.ids() {
+		results = append(results, vectordata.SearchResult{Record: c.records[id], Score: 1})
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func (c *fakeCollection) List(_ context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	var page []vectordata.Record
+	for _, id := range c.ids() {
+		if id > opts.After && len(page) < opts.Limit {
+			page = append(page, c.records[id])
+		}
+	}
+	return page, nil
+}
+
+func (c *fakeCollection) ids() []string {
+	ids := make([]string, 0, len(c.records))
+	for id := range c.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func newTestApp(stdin string) (*app, *fakeStore, *bytes.Buffer) {
+	store := newFakeStore()
+	stdout := &bytes.Buffer{}
+	return &app{store: store, stdin: strings.NewReader(stdin), stdout: stdout}, store, stdout
+}
+
+func TestImportExportRoundTrip(t *testing.T) {
+	// Arrange
+	input := `{"id":"b","vector":[0,1],"metadata":{"kind":"x"}}
+
+{"id":"a","vector":[1,0],"content":"alpha"}
+{"id":"c","vector":[1,1]}
+`
+	a, store, stdout := newTestApp(input)
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	importErr := a.importRecords(ctx, []string{"-batch", "2", "docs"})
+	stdout.Reset()
+	exportErr := a.exportRecords(ctx, []string{"docs"})
+
+	// Assert
+	if importErr != nil || exportErr != nil {
+		t.Fatalf("import: %v, export: %v", importErr, exportErr)
+	}
+	if got := store.collections["docs"].upserts; got != 2 {
+		t.Fatalf("expected 2 batches, got %d", got)
+	}
+	want := `{"id":"a","vector":[1,0],"content":"alpha"}
+{"id":"b","vector":[0,1],"metadata":{"kind":"x"}}
+{"id":"c","vector":[1,1]}
+`
+	if stdout.String() != want {
+		t.Fatalf("export =\n%s\nwant\n%s", stdout.String(), want)
+	}
+}
+
+func TestImportReportsLineNumbers(t *testing.T) {
+	// Arrange
+	a, _, _ := newTestApp("{\"id\":\"a\"}\n{not json}\n")
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	err := a.importRecords(ctx, []string{"docs"})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a line 2 error, got %v", err)
+	}
+}
+
+func TestSearchByVectorAndText(t *testing.T) {
+	// Arrange
+	a, store, stdout := newTestApp("")
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	content := "first document"
+	_ = store.collections["docs"].Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Content: &content}})
+	a.embedder = vectordata.EmbedderFunc(func(context.Context, string) ([]float32, error) { return []float32{0, 1}, nil })
+
+	// Act
+	vectorErr := a.search(ctx, []string{"-vector", "[0.5, 0.25]", "docs"})
+	vectorQuery := store.collections["docs"].lastVector
+	textErr := a.search(ctx, []string{"-text", "hello", "-k", "1", "docs"})
+	bothErr := a.search(ctx, []string{"-text", "hello", "-vector", "1,0", "docs"})
+
+	// Assert
+	if vectorErr != nil || textErr != nil {
+		t.Fatalf("search: %v, %v", vectorErr, textErr)
+	}
+	if len(vectorQuery) != 2 || vectorQuery[0] != 0.5 || vectorQuery[1] != 0.25 {
+		t.Fatalf("unexpected inline vector %v", vectorQuery)
+	}
+	if got := store.collections["docs"].lastVector; got[1] != 1 {
+		t.Fatalf("expected the embedded vector, got %v", got)
+	}
+	if bothErr == nil {
+		t.Fatal("expected an error for both -text and -vector")
+	}
+	if !strings.Contains(stdout.String(), "first document") {
+		t.Fatalf("expected results in output, got %q", stdout.String())
+	}
+}
+
+func TestDropRequiresConfirmation(t *testing.T) {
+	// Arrange
+	a, store, _ := newTestApp("")
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	unconfirmed := a.drop(ctx, []string{"docs"})
+	confirmed := a.drop(ctx, []string{"-yes", "docs"})
+	missing := a.stats(ctx, []string{"docs"})
+
+	// Assert
+	if unconfirmed == nil || confirmed != nil {
+		t.Fatalf("drop without -yes: %v, with -yes: %v", unconfirmed, confirmed)
+	}
+	if _, ok := store.infos["docs"]; ok {
+		t.Fatal("expected the collection to be dropped")
+	}
+	if missing == nil || !strings.Contains(missing.Error(), "not found") {
+		t.Fatalf("expected not found after drop, got %v", missing)
+	}
+}
+
+func TestRunRejectsUnsupportedDSNs(t *testing.T) {
+	for _, dsn := range []string{"", "sqlserver://sa@localhost/db", "mysql://localhost/db"} {
+		// Act
+		err := run(context.Background(), []string{"-dsn", dsn, "list"}, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+
+		// Assert
+		if err == nil {
+			t.Fatalf("expected an error for DSN %q", dsn)
+		}
+	}
+}
+
+func TestBenchVariants(t *testing.T) {
+	// Act
+	variants, err := benchVariants(true, "40, 100", 16, "", 100)
+	_, bothErr := benchVariants(true, "40", 16, "10", 100)
+
+	// Assert
+	if err != nil || len(variants) != 3 || variants[0].Index != nil || variants[2].SessionSettings["hnsw.ef_search"] != "100" {
+		t.Fatalf("unexpected variants %+v (%v)", variants, err)
+	}
+	if bothErr == nil {
+		t.Fatal("expected HNSW and IVFFlat in one run to be rejected")
+	}
+}
+
+func TestIngestCSVWithMapping(t *testing.T) {
+	// Arrange
+	a, store, _ := newTestApp("sku;emb;brand\nx1;0.5 0.5;acme\nx2;1 0;zeta\n")
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	err := a.ingest(ctx, []string{"-format", "csv", "-delimiter", ";", "-id", "sku", "-vector", "emb", "-metadata", "brand", "docs"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	record := store.collections["docs"].records["x2"]
+	if len(record.Vector) != 2 || record.Vector[0] != 1 || record.Metadata["brand"] != "zeta" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestIngestParquet(t *testing.T) {
+	// Arrange
+	type row struct {
+		SKU    string    `parquet:"sku"`
+		Vector []float32 `parquet:"vector,list"`
+		Brand  string    `parquet:"brand"`
+	}
+	path := filepath.Join(t.TempDir(), "rows.parquet")
+	if err := parquet.WriteFile(path, []row{{SKU: "x1", Vector: []float32{0.5, 0.5}, Brand: "acme"}, {SKU: "x2", Vector: []float32{1, 0}, Brand: "zeta"}}); err != nil {
+		t.Fatalf("write Parquet: %v", err)
+	}
+	a, store, _ := newTestApp("")
+	ctx := context.Background()
+	if err := a.create(ctx, []string{"-dim", "2", "docs"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Act
+	err := a.ingest(ctx, []string{"-file", path, "-id", "sku", "-metadata", "brand", "docs"})
+	_, _, stdinErr := a.ingestSource("-", "parquet", ",")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	record := store.collections["docs"].records["x2"]
+	if len(record.Vector) != 2 || record.Vector[0] != 1 || record.Metadata["brand"] != "zeta" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if stdinErr == nil || !strings.Contains(stdinErr.Error(), "stdin") {
+		t.Fatalf("expected Parquet on stdin to be rejected, got %v", stdinErr)
+	}
+}
@@ -0,0 +1,60 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint stores how many source rows a run has written.
+type Checkpoint interface {
+	// Load returns the rows done, 0 for a new run.
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, rows int64) error
+}
+
+// FileCheckpoint keeps the checkpoint in a JSON file. Saves write a temporary
+// file and rename it, so an interrupted save leaves the previous checkpoint.
+type FileCheckpoint string
+
+type checkpointFile struct {
+	Rows int64 `json:"rows"`
+}
+
+func (f FileCheckpoint) Load(context.Context) (int64, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var checkpoint checkpointFile
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return 0, err
+	}
+	return checkpoint.Rows, nil
+}
+
+func (f FileCheckpoint) Save(_ context.Context, rows int64) error {
+	data, err := json.Marshal(checkpointFile{Rows: rows})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
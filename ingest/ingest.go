@@ -0,0 +1,326 @@
+// Package ingest streams records from CSV, JSONL, Parquet or any other row
+// source into a collection, mapping columns onto record fields, embedding
+// content when a row has no vector, and checkpointing progress so an
+// interrupted import resumes where it stopped.
+//
+//	source := ingest.NewCSVSource(file, ingest.CSVOptions{})
+//	result, err := ingest.Run(ctx, collection, source, ingest.Options{
+//		Mapping:    ingest.Mapping{ID: "sku", Content: "description", Metadata: []string{"brand", "price"}},
+//		Embedder:   embedder,
+//		Checkpoint: ingest.FileCheckpoint("products.checkpoint"),
+//	})
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const defaultBatchSize = 500
+
+// Mapping names the columns that become record fields. Empty names use the
+// defaults "id", "vector", "content" and "metadata".
+type Mapping struct {
+	ID string
+	// Vector holds a JSON array, or numbers separated by commas or spaces,
+	// optionally in brackets.
+	Vector  string
+	Content string
+	// MetadataObject holds a JSON object (a string in CSV) whose keys become
+	// metadata.
+	MetadataObject string
+	// Metadata columns are copied into metadata under their own names, over
+	// the keys of MetadataObject.
+	Metadata []string
+}
+
+func (m Mapping) withDefaults() Mapping {
+	if m.ID == "" {
+		m.ID = "id"
+	}
+	if m.Vector == "" {
+		m.Vector = "vector"
+	}
+	if m.Content == "" {
+		m.Content = "content"
+	}
+	if m.MetadataObject == "" {
+		m.MetadataObject = "metadata"
+	}
+	return m
+}
+
+// Options configures Run.
+type Options struct {
+	Mapping Mapping
+	// Embedder, when set, embeds the content of rows without a vector. Rows
+	// without a vector fail otherwise.
+	Embedder vectordata.Embedder
+	// BatchSize is the number of records per Upsert (default 500).
+	BatchSize int
+	// Checkpoint, when set, is loaded before the first row and saved after
+	// every batch. Rows it records as done are skipped.
+	Checkpoint Checkpoint
+	// Progress, when set, is called after every batch.
+	Progress func(Result)
+}
+
+// Result counts the rows of a run.
+type Result struct {
+	// Rows is the number of source rows done, including rows skipped by a
+	// resumed checkpoint.
+	Rows int64
+	// Resumed is the number of rows skipped from the checkpoint.
+	Resumed int64
+	// Upserted is the number of records written by this run.
+	Upserted int64
+}
+
+// Run streams the rows of source into collection. Records are upserted, so a
+// run resumed from a checkpoint may rewrite the batch that was in flight
+// without duplicating it. Errors name the 1-based source row.
+func Run(ctx context.Context, collection vectordata.Collection, source Source, opts Options) (Result, error) {
+	mapping := opts.Mapping.withDefaults()
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var result Result
+	if opts.Checkpoint != nil {
+		done, err := opts.Checkpoint.Load(ctx)
+		if err != nil {
+			return result, fmt.Errorf("ingest: load checkpoint: %w", err)
+		}
+		for result.Resumed < done {
+			if _, err := source.Next(); err != nil {
+				if errors.Is(err, io.EOF) {
+					return result, fmt.Errorf("ingest: checkpoint is at row %d but the source has %d rows", done, result.Resumed)
+				}
+				return result, fmt.Errorf("ingest: row %d: %w", result.Resumed+1, err)
+			}
+			result.Resumed++
+		}
+		result.Rows = result.Resumed
+	}
+
+	batch := make([]vectordata.Record, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := collection.Upsert(ctx, batch); err != nil {
+			return fmt.Errorf("ingest: upsert rows %d-%d: %w", result.Rows-int64(len(batch))+1, result.Rows, err)
+		}
+		result.Upserted += int64(len(batch))
+		batch = batch[:0]
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint.Save(ctx, result.Rows); err != nil {
+				return fmt.Errorf("ingest: save checkpoint: %w", err)
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		row, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("ingest: row %d: %w", result.Rows+1, err)
+		}
+		record, err := mapping.record(ctx, row, opts.Embedder)
+		if err != nil {
+			return result, fmt.Errorf("ingest: row %d: %w", result.Rows+1, err)
+		}
+		batch = append(batch, record)
+		result.Rows++
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (m Mapping) record(ctx context.Context, row Row, embedder vectordata.Embedder) (vectordata.Record, error) {
+	id, err := idOf(row[m.ID])
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("column %q: %w", m.ID, err)
+	}
+	record := vectordata.Record{ID: id}
+
+	if value, ok := row[m.Content]; ok && value != nil {
+		content, ok := value.(string)
+		if !ok {
+			return vectordata.Record{}, fmt.Errorf("column %q: expected a string, got %T", m.Content, value)
+		}
+		record.Content = &content
+	}
+
+	if value, ok := row[m.Vector]; ok && value != nil && value != "" {
+		record.Vector, err = vectorOf(value)
+		if err != nil {
+			return vectordata.Record{}, fmt.Errorf("column %q: %w", m.Vector, err)
+		}
+	} else if embedder != nil && record.Content != nil {
+		record.Vector, err = embedder.Embed(ctx, *record.Content)
+		if err != nil {
+			return vectordata.Record{}, fmt.Errorf("embed content: %w", err)
+		}
+	} else {
+		return vectordata.Record{}, fmt.Errorf("no vector in column %q and no content to embed", m.Vector)
+	}
+
+	record.Metadata, err = m.metadata(row)
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	return record, nil
+}
+
+func (m Mapping) metadata(row Row) (map[string]any, error) {
+	metadata := map[string]any{}
+	switch value := row[m.MetadataObject].(type) {
+	case nil:
+	case map[string]any:
+		for k, v := range value {
+			metadata[k] = jsonNumbers(v)
+		}
+	case string:
+		if strings.TrimSpace(value) != "" {
+			if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+				return nil, fmt.Errorf("column %q: %w", m.MetadataObject, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("column %q: expected a JSON object, got %T", m.MetadataObject, value)
+	}
+	for _, column := range m.Metadata {
+		if value, ok := row[column]; ok {
+			metadata[column] = jsonNumbers(value)
+		}
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+func idOf(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return "", errors.New("empty ID")
+		}
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return "", fmt.Errorf("ID %v is not an integer", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			return string(v), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return "", fmt.Errorf("ID %v: %w", v, err)
+		}
+		return idOf(f)
+	case nil:
+		return "", errors.New("missing ID")
+	default:
+		return "", fmt.Errorf("expected a string or number ID, got %T", value)
+	}
+}
+
+// jsonNumbers returns value with the json.Numbers in it, at any depth,
+// converted to float64, as encoding/json decodes numbers by default.
+func jsonNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = jsonNumbers(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = jsonNumbers(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func vectorOf(value any) ([]float32, error) {
+	switch v := value.(type) {
+	case []float32:
+		return v, nil
+	case []float64:
+		vector := make([]float32, len(v))
+		for i, component := range v {
+			vector[i] = float32(component)
+		}
+		return vector, nil
+	case []any:
+		vector := make([]float32, len(v))
+		for i, component := range v {
+			switch number := jsonNumbers(component).(type) {
+			case float64:
+				vector[i] = float32(number)
+			case float32:
+				vector[i] = number
+			default:
+				return nil, fmt.Errorf("component %d: expected a number, got %T", i, component)
+			}
+		}
+		return vector, nil
+	case string:
+		parts := strings.FieldsFunc(strings.Trim(strings.TrimSpace(v), "[]"), func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		vector := make([]float32, len(parts))
+		for i, part := range parts {
+			component, err := strconv.ParseFloat(part, 32)
+			if err != nil {
+				return nil, fmt.Errorf("component %d: %w", i, err)
+			}
+			vector[i] = float32(component)
+		}
+		return vector, nil
+	default:
+		return nil, fmt.Errorf("expected an array or a string, got %T", value)
+	}
+}
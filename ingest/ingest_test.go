@@ -0,0 +1,267 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/parquet-go/parquet-go"
+)
+
+type recordingCollection struct {
+	vectordata.Collection
+	batches [][]vectordata.Record
+	// failAt fails the Upsert with this 1-based index.
+	failAt int
+}
+
+func (c *recordingCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	if len(c.batches)+1 == c.failAt {
+		c.failAt = 0
+		return errors.New("connection reset")
+	}
+	c.batches = append(c.batches, append([]vectordata.Record(nil), records...))
+	return nil
+}
+
+func (c *recordingCollection) ids() []string {
+	var ids []string
+	for _, batch := range c.batches {
+		for _, record := range batch {
+			ids = append(ids, record.ID)
+		}
+	}
+	return ids
+}
+
+type memoryCheckpoint struct{ rows int64 }
+
+func (c *memoryCheckpoint) Load(context.Context) (int64, error) { return c.rows, nil }
+
+func (c *memoryCheckpoint) Save(_ context.Context, rows int64) error {
+	c.rows = rows
+	return nil
+}
+
+func TestRunMapsCSVColumnsAndEmbedsContent(t *testing.T) {
+	// Arrange
+	input := "sku,title,brand,vec,attrs\n" +
+		"a1,Red shoe,acme,\"[1, 0]\",\"{\"\"size\"\":42}\"\n" +
+		"a2,Blue hat,zeta,,\n"
+	collection := &recordingCollection{}
+	embedder := vectordata.EmbedderFunc(func(_ context.Context, text string) ([]float32, error) {
+		return []float32{0, float32(len(text))}, nil
+	})
+
+	// Act
+	result, err := Run(context.Background(), collection, NewCSVSource(strings.NewReader(input), CSVOptions{}), Options{
+		Mapping:  Mapping{ID: "sku", Vector: "vec", Content: "title", MetadataObject: "attrs", Metadata: []string{"brand"}},
+		Embedder: embedder,
+	})
+
+	// Assert
+	if err != nil || result != (Result{Rows: 2, Upserted: 2}) {
+		t.Fatalf("unexpected result %+v (%v)", result, err)
+	}
+	records := collection.batches[0]
+	if !reflect.DeepEqual(records[0].Vector, []float32{1, 0}) || records[0].Metadata["size"] != 42.0 || records[0].Metadata["brand"] != "acme" {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	if !reflect.DeepEqual(records[1].Vector, []float32{0, 8}) || *records[1].Content != "Blue hat" {
+		t.Fatalf("expected the second record to be embedded, got %+v", records[1])
+	}
+}
+
+func TestRunJSONLDefaultsAndRowErrors(t *testing.T) {
+	// Arrange
+	input := `{"id": 7, "vector": [0.5, 0.5], "metadata": {"lang": "en"}, "content": "hi"}
+
+{"id": "b", "content": "no vector"}
+`
+	collection := &recordingCollection{}
+
+	// Act
+	result, err := Run(context.Background(), collection, NewJSONLSource(strings.NewReader(input)), Options{BatchSize: 1})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("expected an error on row 2, got %v", err)
+	}
+	if result.Upserted != 1 || collection.batches[0][0].ID != "7" || collection.batches[0][0].Metadata["lang"] != "en" {
+		t.Fatalf("unexpected result %+v, batches %+v", result, collection.batches)
+	}
+}
+
+func TestRunJSONLKeepsLargeIntegerIDs(t *testing.T) {
+	// Arrange
+	input := `{"id": 9007199254740993, "vector": [1, 0], "metadata": {"rank": 3, "tags": [1.5]}}
+{"id": 9007199254740992, "vector": [0, 1]}
+{"id": 4e3, "vector": [0, 1]}
+`
+	collection := &recordingCollection{}
+
+	// Act
+	result, err := Run(context.Background(), collection, NewJSONLSource(strings.NewReader(input)), Options{})
+
+	// Assert
+	if err != nil || result.Upserted != 3 {
+		t.Fatalf("unexpected result %+v (%v)", result, err)
+	}
+	if ids := collection.ids(); !reflect.DeepEqual(ids, []string{"9007199254740993", "9007199254740992", "4000"}) {
+		t.Fatalf("unexpected IDs %v", ids)
+	}
+	first := collection.batches[0][0]
+	if first.Metadata["rank"] != 3.0 || !reflect.DeepEqual(first.Metadata["tags"], []any{1.5}) || !reflect.DeepEqual(first.Vector, []float32{1, 0}) {
+		t.Fatalf("expected float64 metadata and a decoded vector, got %+v", first)
+	}
+}
+
+type parquetRow struct {
+	ID       int64     `parquet:"id"`
+	Vector   []float32 `parquet:"vector,list"`
+	Content  *string   `parquet:"content,optional"`
+	Metadata string    `parquet:"metadata,json"`
+	Brand    string    `parquet:"brand"`
+}
+
+func writeParquet(t *testing.T, rows []parquetRow) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, rows); err != nil {
+		t.Fatalf("write Parquet: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestRunMapsParquetColumnsAndResumes(t *testing.T) {
+	// Arrange
+	content := "Red shoe"
+	input := writeParquet(t, []parquetRow{
+		{ID: 9007199254740993, Vector: []float32{1, 0}, Content: &content, Metadata: `{"size": 42}`, Brand: "acme"},
+		{ID: 2, Vector: []float32{0, 1}, Metadata: `{}`, Brand: "zeta"},
+		{ID: 3, Vector: []float32{0.5, 0.5}, Metadata: `{}`, Brand: "acme"},
+	})
+	source, err := NewParquetSource(input, input.Size())
+	if err != nil {
+		t.Fatalf("NewParquetSource: %v", err)
+	}
+	collection := &recordingCollection{}
+
+	// Act
+	result, err := Run(context.Background(), collection, source, Options{
+		Mapping:    Mapping{Metadata: []string{"brand"}},
+		Checkpoint: &memoryCheckpoint{rows: 1},
+	})
+
+	// Assert
+	if err != nil || result != (Result{Rows: 3, Resumed: 1, Upserted: 2}) {
+		t.Fatalf("unexpected result %+v (%v)", result, err)
+	}
+	if ids := collection.ids(); !reflect.DeepEqual(ids, []string{"2", "3"}) {
+		t.Fatalf("unexpected IDs %v", ids)
+	}
+	record := collection.batches[0][0]
+	if !reflect.DeepEqual(record.Vector, []float32{0, 1}) || record.Content != nil || record.Metadata["brand"] != "zeta" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestParquetSourceKeepsLargeIntegerIDs(t *testing.T) {
+	// Arrange
+	content := "Red shoe"
+	input := writeParquet(t, []parquetRow{
+		{ID: 9007199254740993, Vector: []float32{1, 0}, Content: &content, Metadata: `{"size": 42}`, Brand: "acme"},
+	})
+	source, err := NewParquetSource(input, input.Size())
+	if err != nil {
+		t.Fatalf("NewParquetSource: %v", err)
+	}
+	collection := &recordingCollection{}
+
+	// Act
+	_, err = Run(context.Background(), collection, source, Options{Mapping: Mapping{Metadata: []string{"brand"}}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	record := collection.batches[0][0]
+	if record.ID != "9007199254740993" || *record.Content != content || record.Metadata["size"] != 42.0 || record.Metadata["brand"] != "acme" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestNewParquetSourceRejectsOtherFiles(t *testing.T) {
+	// Arrange
+	input := strings.NewReader("id,vector\n")
+
+	// Act
+	_, err := NewParquetSource(input, input.Size())
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected a CSV file to be rejected")
+	}
+}
+
+func TestRunResumesFromCheckpoint(t *testing.T) {
+	// Arrange
+	var input strings.Builder
+	input.WriteString("id,vector\n")
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		input.WriteString(id + ",1 2\n")
+	}
+	checkpoint := &memoryCheckpoint{}
+	collection := &recordingCollection{failAt: 2}
+	opts := Options{BatchSize: 2, Checkpoint: checkpoint}
+
+	// Act
+	_, firstErr := Run(context.Background(), collection, NewCSVSource(strings.NewReader(input.String()), CSVOptions{}), opts)
+	afterFailure := checkpoint.rows
+	result, err := Run(context.Background(), collection, NewCSVSource(strings.NewReader(input.String()), CSVOptions{}), opts)
+
+	// Assert
+	if firstErr == nil || !strings.Contains(firstErr.Error(), "rows 3-4") || afterFailure != 2 {
+		t.Fatalf("expected the second batch to fail with the checkpoint at 2, got %v at %d", firstErr, afterFailure)
+	}
+	if err != nil || result != (Result{Rows: 5, Resumed: 2, Upserted: 3}) || checkpoint.rows != 5 {
+		t.Fatalf("unexpected resumed result %+v (%v), checkpoint %d", result, err, checkpoint.rows)
+	}
+	if ids := collection.ids(); !reflect.DeepEqual(ids, []string{"a", "b", "c", "d", "e"}) {
+		t.Fatalf("expected every row written once, got %v", ids)
+	}
+}
+
+func TestFileCheckpoint(t *testing.T) {
+	// Arrange
+	checkpoint := FileCheckpoint(filepath.Join(t.TempDir(), "import.checkpoint"))
+	ctx := context.Background()
+
+	// Act
+	initial, loadErr := checkpoint.Load(ctx)
+	saveErr := checkpoint.Save(ctx, 1500)
+	saved, reloadErr := checkpoint.Load(ctx)
+
+	// Assert
+	if initial != 0 || loadErr != nil || saveErr != nil || reloadErr != nil || saved != 1500 {
+		t.Fatalf("initial %d (%v), save %v, saved %d (%v)", initial, loadErr, saveErr, saved, reloadErr)
+	}
+}
+
+func TestRunRejectsCheckpointBeyondSource(t *testing.T) {
+	// Arrange
+	source := NewJSONLSource(strings.NewReader(`{"id":"a","vector":[1]}`))
+
+	// Act
+	_, err := Run(context.Background(), &recordingCollection{}, source, Options{Checkpoint: &memoryCheckpoint{rows: 3}})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "checkpoint is at row 3") {
+		t.Fatalf("expected a checkpoint error, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type parquetSource struct {
+	reader *parquet.Reader
+}
+
+// NewParquetSource reads rows from a Parquet file of size bytes, e.g. an
+// *os.File and its size from Stat. Rows are keyed by top-level column name.
+// Integers are int32 or int64, floating-point numbers float32 or float64,
+// lists []any, and JSON columns and groups map[string]any; null values are
+// nil. A vector column is a list of floats, or a string as in CSV.
+func NewParquetSource(r io.ReaderAt, size int64) (Source, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open Parquet file: %w", err)
+	}
+	return &parquetSource{reader: parquet.NewReader(file)}, nil
+}
+
+func (s *parquetSource) Next() (Row, error) {
+	row := Row{}
+	if err := s.reader.Read(&row); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("read Parquet row: %w", err)
+	}
+	return row, nil
+}
@@ -0,0 +1,115 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Row is one input row keyed by column name. CSV values are strings; JSONL
+// values are decoded with encoding/json, with numbers as json.Number so that
+// integer IDs beyond 2^53 keep their digits, and are converted to float64 in
+// metadata. Parquet values are described at NewParquetSource.
+type Row map[string]any
+
+// Source yields rows. Next returns io.EOF after the last row.
+type Source interface {
+	Next() (Row, error)
+}
+
+// SourceFunc adapts a function to Source, e.g. to read another format
+// through a reader of your choice.
+type SourceFunc func() (Row, error)
+
+func (f SourceFunc) Next() (Row, error) {
+	return f()
+}
+
+// CSVOptions configures NewCSVSource.
+type CSVOptions struct {
+	// Comma is the field delimiter (default ',').
+	Comma rune
+	// Header names the columns. Without it, the first record is the header.
+	Header []string
+}
+
+type csvSource struct {
+	reader *csv.Reader
+	header []string
+}
+
+// NewCSVSource reads rows from CSV with a header.
+func NewCSVSource(r io.Reader, opts CSVOptions) Source {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.ReuseRecord = true
+	return &csvSource{reader: reader, header: opts.Header}
+}
+
+func (s *csvSource) Next() (Row, error) {
+	if s.header == nil {
+		header, err := s.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV header: %w", err)
+		}
+		s.header = append([]string(nil), header...)
+	}
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(record) != len(s.header) {
+		return nil, fmt.Errorf("CSV record has %d fields, header has %d", len(record), len(s.header))
+	}
+	row := make(Row, len(record))
+	for i, value := range record {
+		row[s.header[i]] = value
+	}
+	return row, nil
+}
+
+type jsonlSource struct {
+	scanner *bufio.Scanner
+}
+
+// NewJSONLSource reads one JSON object per line. Blank lines are skipped.
+func NewJSONLSource(r io.Reader) Source {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20)
+	return &jsonlSource{scanner: scanner}
+}
+
+func (s *jsonlSource) Next() (Row, error) {
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var row Row
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&row); err != nil {
+			return nil, err
+		}
+		if decoder.More() {
+			return nil, errors.New("expected one JSON object per line")
+		}
+		if row == nil {
+			return nil, errors.New("expected a JSON object, got null")
+		}
+		return row, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
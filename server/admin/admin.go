@@ -0,0 +1,278 @@
+// Package admin serves a small web UI for browsing collections during
+// development: it lists collections, pages through and inspects records,
+// runs test searches by a pasted vector or by text embedded with a
+// configured embedder, and shows index and size information.
+//
+//	mux.Handle("/admin/", http.StripPrefix("/admin", admin.NewHandler(admin.ForPostgres(store))))
+//
+// The UI is read-only, but it shows every record of every collection it can
+// open: put it behind authentication with Options.Middleware, or do not serve
+// it outside development.
+package admin
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/server/internal/wire"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+	maxTopK         = 1000
+	maxBodyBytes    = 4 << 20
+)
+
+//go:embed ui
+var ui embed.FS
+
+// CollectionInfo is a collection as listed by the UI.
+type CollectionInfo struct {
+	Name        string                       `json:"name"`
+	Dimension   int                          `json:"dimension"`
+	Metric      vectordata.DistanceMetric    `json:"metric"`
+	ElementType vectordata.VectorElementType `json:"element_type,omitempty"`
+}
+
+// Options configures a handler.
+type Options struct {
+	// Collections lists the collections of the sidebar. Without it, the UI
+	// opens collections by name.
+	Collections func(ctx context.Context) ([]CollectionInfo, error)
+	// Open returns the handle of a collection. Handles are cached per name
+	// after the first successful call. Required.
+	Open func(ctx context.Context, name string) (vectordata.Collection, error)
+	// Stats, when set, returns details shown with a collection, e.g. its
+	// indexes and sizes. The value is encoded as JSON.
+	Stats func(ctx context.Context, name string) (any, error)
+	// Embedder, when set, enables searching by text.
+	Embedder vectordata.Embedder
+	// Middleware wraps the handler, first entry outermost, e.g. for
+	// authentication.
+	Middleware []func(http.Handler) http.Handler
+}
+
+type handler struct {
+	opts Options
+
+	mu          sync.RWMutex
+	collections map[string]vectordata.Collection
+}
+
+// NewHandler returns the UI and the JSON API it calls, rooted at "/". Mount
+// it under a prefix with http.StripPrefix.
+func NewHandler(opts Options) http.Handler {
+	h := &handler{opts: opts, collections: make(map[string]vectordata.Collection)}
+	static, _ := fs.Sub(ui, "ui")
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/config", h.config)
+	mux.HandleFunc("GET /api/collections", h.list)
+	mux.HandleFunc("GET /api/collections/{name}", h.describe)
+	mux.HandleFunc("GET /api/collections/{name}/records", h.records)
+	mux.HandleFunc("GET /api/collections/{name}/records/{id}", h.record)
+	mux.HandleFunc("POST /api/collections/{name}/search", h.search)
+
+	var handler http.Handler = mux
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		handler = opts.Middleware[i](handler)
+	}
+	return handler
+}
+
+type describeJSON struct {
+	CollectionInfo
+	Count int64 `json:"count"`
+	Stats any   `json:"stats,omitempty"`
+}
+
+type searchRequest struct {
+	Vector []float32       `json:"vector,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	TopK   int             `json:"top_k"`
+	Filter json.RawMessage `json:"filter,omitempty"`
+}
+
+func (h *handler) config(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]bool{
+		"collections": h.opts.Collections != nil,
+		"embedder":    h.opts.Embedder != nil,
+		"stats":       h.opts.Stats != nil,
+	})
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Collections == nil {
+		writeJSON(w, http.StatusOK, []CollectionInfo{})
+		return
+	}
+	collections, err := h.opts.Collections(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, collections)
+}
+
+func (h *handler) describe(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.collection(w, r)
+	if !ok {
+		return
+	}
+	count, err := collection.Count(r.Context(), nil)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := describeJSON{
+		CollectionInfo: CollectionInfo{Name: collection.Name(), Dimension: collection.Dimension(), Metric: collection.Metric()},
+		Count:          count,
+	}
+	if h.opts.Stats != nil {
+		if out.Stats, err = h.opts.Stats(r.Context(), collection.Name()); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// records answers one page of records in ID order, with the ID to pass as
+// after for the next page.
+func (h *handler) records(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.collection(w, r)
+	if !ok {
+		return
+	}
+	lister, ok := collection.(vectordata.RecordLister)
+	if !ok {
+		writeError(w, fmt.Errorf("collection %q cannot list records: %w", collection.Name(), errors.ErrUnsupported))
+		return
+	}
+	query := r.URL.Query()
+	filter, err := vectordata.ParseFilterJSON([]byte(query.Get("filter")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	limit := defaultPageSize
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxPageSize {
+			writeError(w, fmt.Errorf("%w: limit must be between 1 and %d", errBadRequest, maxPageSize))
+			return
+		}
+	}
+	projection := vectordata.Projection{IncludeMetadata: true, IncludeContent: true}
+	page, err := lister.List(r.Context(), vectordata.ListOptions{Filter: filter, After: query.Get("after"), Limit: limit, Projection: &projection})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := struct {
+		Records []wire.Record `json:"records"`
+		Next    string        `json:"next,omitempty"`
+	}{Records: make([]wire.Record, len(page))}
+	for i, record := range page {
+		out.Records[i] = wire.FromRecord(record)
+	}
+	if len(page) == limit {
+		out.Next = page[len(page)-1].ID
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *handler) record(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.collection(w, r)
+	if !ok {
+		return
+	}
+	record, err := collection.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, wire.FromRecord(record))
+}
+
+func (h *handler) search(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.collection(w, r)
+	if !ok {
+		return
+	}
+	var body searchRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeError(w, fmt.Errorf("%w: decode request body: %v", errBadRequest, err))
+		return
+	}
+	if body.TopK <= 0 || body.TopK > maxTopK {
+		writeError(w, fmt.Errorf("%w: top_k must be between 1 and %d", errBadRequest, maxTopK))
+		return
+	}
+	filter, err := vectordata.ParseFilterJSON(body.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	vector := body.Vector
+	switch {
+	case (len(body.Vector) == 0) == (body.Text == ""):
+		writeError(w, fmt.Errorf("%w: exactly one of vector and text is required", errBadRequest))
+		return
+	case body.Text != "" && h.opts.Embedder == nil:
+		writeError(w, fmt.Errorf("searching by text needs an embedder: %w", errors.ErrUnsupported))
+		return
+	case body.Text != "":
+		if vector, err = h.opts.Embedder.Embed(r.Context(), body.Text); err != nil {
+			writeError(w, fmt.Errorf("embed query: %w", err))
+			return
+		}
+	}
+
+	results, err := collection.SearchByVector(r.Context(), vector, body.TopK, vectordata.SearchOptions{Filter: filter})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]wire.Result, len(results))
+	for i, result := range results {
+		out[i] = wire.FromResult(result)
+	}
+	writeJSON(w, http.StatusOK, map[string][]wire.Result{"results": out})
+}
+
+func (h *handler) collection(w http.ResponseWriter, r *http.Request) (vectordata.Collection, bool) {
+	name := r.PathValue("name")
+	h.mu.RLock()
+	collection, ok := h.collections[name]
+	h.mu.RUnlock()
+	if ok {
+		return collection, true
+	}
+	if h.opts.Open == nil {
+		writeError(w, fmt.Errorf("%w: no Open function configured", errors.ErrUnsupported))
+		return nil, false
+	}
+	collection, err := h.opts.Open(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	h.mu.Lock()
+	h.collections[name] = collection
+	h.mu.Unlock()
+	return collection, true
+}
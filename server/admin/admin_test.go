@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/server/internal/wire"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type stubCollection struct {
+	vectordata.Collection
+	records    map[string]vectordata.Record
+	lastVector []float32
+}
+
+func (c *stubCollection) Name() string                      { return "docs" }
+func (c *stubCollection) Dimension() int                    { return 2 }
+func (c *stubCollection) Metric() vectordata.DistanceMetric { return vectordata.DistanceCosine }
+
+func (c *stubCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	return int64(len(c.records)), nil
+}
+
+func (c *stubCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	record, ok := c.records[id]
+	if !ok {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *stubCollection) List(_ context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	ids := make([]string, 0, len(c.records))
+	for id := range c.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var page []vectordata.Record
+	for _, id := range ids {
+		if id > opts.After && len(page) < opts.Limit {
+			page = append(page, c.records[id])
+		}
+	}
+	return page, nil
+}
+
+func (c *stubCollection) SearchByVector(_ context.Context, vector []float32, _ int, _ vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.lastVector = vector
+	return []vectordata.SearchResult{{Record: c.records["a"], Distance: 0.1, Score: 0.9}}, nil
+}
+
+func newTestServer(t *testing.T, embedder vectordata.Embedder) (*httptest.Server, *stubCollection, *int) {
+	t.Helper()
+	content := "alpha"
+	collection := &stubCollection{records: map[string]vectordata.Record{
+		"a": {ID: "a", Vector: []float32{1, 0}, Content: &content},
+		"b": {ID: "b", Vector: []float32{0, 1}},
+		"c": {ID: "c", Vector: []float32{1, 1}},
+	}}
+	opens := 0
+	handler := NewHandler(Options{
+		Collections: func(context.Context) ([]CollectionInfo, error) {
+			return []CollectionInfo{{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine}}, nil
+		},
+		Open: func(_ context.Context, name string) (vectordata.Collection, error) {
+			if name != "docs" {
+				return nil, vectordata.ErrNotFound
+			}
+			opens++
+			return collection, nil
+		},
+		Stats: func(context.Context, string) (any, error) {
+			return map[string]any{"total_bytes": 8192}, nil
+		},
+		Embedder: embedder,
+	})
+	server := httptest.NewServer(http.StripPrefix("/admin", handler))
+	t.Cleanup(server.Close)
+	return server, collection, &opens
+}
+
+func getJSON(t *testing.T, url string, out any) int {
+	t.Helper()
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer response.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+	return response.StatusCode
+}
+
+func postJSON(t *testing.T, url, body string, out any) int {
+	t.Helper()
+	response, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer response.Body.Close()
+	if out != nil {
+		_ = json.NewDecoder(response.Body).Decode(out)
+	}
+	return response.StatusCode
+}
+
+func TestServesUIUnderPrefix(t *testing.T) {
+	// Arrange
+	server, _, _ := newTestServer(t, nil)
+
+	// Act
+	response, err := http.Get(server.URL + "/admin/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	// Assert
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "<title>vectorstore admin</title>") {
+		t.Fatalf("unexpected UI response %d:\n%s", response.StatusCode, body)
+	}
+}
+
+func TestDescribeAndBrowseRecords(t *testing.T) {
+	// Arrange
+	server, _, opens := newTestServer(t, nil)
+	base := server.URL + "/admin/api/collections"
+
+	// Act
+	var collections []CollectionInfo
+	listStatus := getJSON(t, base, &collections)
+	var info struct {
+		Count int64          `json:"count"`
+		Stats map[string]any `json:"stats"`
+	}
+	describeStatus := getJSON(t, base+"/docs", &info)
+	var first, second struct {
+		Records []wire.Record `json:"records"`
+		Next    string        `json:"next"`
+	}
+	getJSON(t, base+"/docs/records?limit=2", &first)
+	getJSON(t, base+"/docs/records?limit=2&after="+first.Next, &second)
+	var record wire.Record
+	getJSON(t, base+"/docs/records/a", &record)
+	missingStatus := getJSON(t, base+"/other", nil)
+
+	// Assert
+	if listStatus != http.StatusOK || len(collections) != 1 || collections[0].Name != "docs" {
+		t.Fatalf("unexpected collections %d %+v", listStatus, collections)
+	}
+	if describeStatus != http.StatusOK || info.Count != 3 || info.Stats["total_bytes"] != 8192.0 {
+		t.Fatalf("unexpected description %d %+v", describeStatus, info)
+	}
+	if len(first.Records) != 2 || first.Next != "b" || len(second.Records) != 1 || second.Next != "" {
+		t.Fatalf("unexpected pages %+v then %+v", first, second)
+	}
+	if record.ID != "a" || len(record.Vector) != 2 || *record.Content != "alpha" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if missingStatus != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown collection, got %d", missingStatus)
+	}
+	if *opens != 1 {
+		t.Fatalf("expected the handle to be opened once, got %d", *opens)
+	}
+}
+
+func TestSearchByVectorAndText(t *testing.T) {
+	// Arrange
+	embedder := vectordata.EmbedderFunc(func(_ context.Context, text string) ([]float32, error) {
+		if text == "fail" {
+			return nil, errors.New("quota exceeded")
+		}
+		return []float32{0.5, 0.5}, nil
+	})
+	server, collection, _ := newTestServer(t, embedder)
+	withoutEmbedder, _, _ := newTestServer(t, nil)
+	path := "/admin/api/collections/docs/search"
+
+	// Act
+	var byVector struct {
+		Results []wire.Result `json:"results"`
+	}
+	vectorStatus := postJSON(t, server.URL+path, `{"vector":[1,0],"top_k":3}`, &byVector)
+	textStatus := postJSON(t, server.URL+path, `{"text":"hello","top_k":3}`, nil)
+	textVector := collection.lastVector
+	bothStatus := postJSON(t, server.URL+path, `{"vector":[1,0],"text":"hello","top_k":3}`, nil)
+	unsupportedStatus := postJSON(t, withoutEmbedder.URL+path, `{"text":"hello","top_k":3}`, nil)
+
+	// Assert
+	if vectorStatus != http.StatusOK || len(byVector.Results) != 1 || byVector.Results[0].Record.ID != "a" || byVector.Results[0].Score != 0.9 {
+		t.Fatalf("unexpected vector search %d %+v", vectorStatus, byVector)
+	}
+	if textStatus != http.StatusOK || len(textVector) != 2 || textVector[0] != 0.5 {
+		t.Fatalf("expected the text to be embedded, got %d %v", textStatus, textVector)
+	}
+	if bothStatus != http.StatusBadRequest || unsupportedStatus != http.StatusNotImplemented {
+		t.Fatalf("expected 400 and 501, got %d and %d", bothStatus, unsupportedStatus)
+	}
+}
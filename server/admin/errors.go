@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var errBadRequest = errors.New("bad request")
+
+// statusOf maps vectordata errors to HTTP status codes.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, vectordata.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errBadRequest),
+		errors.Is(err, vectordata.ErrInvalidFilter),
+		errors.Is(err, vectordata.ErrDimensionMismatch),
+		errors.Is(err, vectordata.ErrSchemaMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, vectordata.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, vectordata.ErrUnavailable), errors.Is(err, vectordata.ErrNotReady):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, errors.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gabisonia/go-vectorstore/stores/postgres"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// ForPostgres returns options that list the catalog of store, open
+// collections from their catalog entry and show their indexes and sizes.
+// Partitioned collections cannot be opened, since the catalog does not record
+// partitioning. Set Embedder to search by text.
+func ForPostgres(store *postgres.PostgresVectorStore) Options {
+	return Options{
+		Collections: func(ctx context.Context) ([]CollectionInfo, error) {
+			infos, err := store.ListCollections(ctx)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]CollectionInfo, len(infos))
+			for i, info := range infos {
+				out[i] = CollectionInfo{Name: info.Name, Dimension: info.Dimension, Metric: info.Metric, ElementType: info.ElementType}
+			}
+			return out, nil
+		},
+		Open: func(ctx context.Context, name string) (vectordata.Collection, error) {
+			info, err := store.DescribeCollection(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			return store.EnsureCollection(ctx, vectordata.CollectionSpec{
+				Name:             info.Name,
+				Dimension:        info.Dimension,
+				Metric:           info.Metric,
+				Mode:             vectordata.EnsureStrict,
+				NormalizeVectors: info.NormalizeVectors,
+				ElementType:      info.ElementType,
+				FastDimension:    info.FastDimension,
+			})
+		},
+		Stats: func(ctx context.Context, name string) (any, error) {
+			stats, err := store.CollectionStats(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			indexes := make([]map[string]any, len(stats.Indexes))
+			for i, index := range stats.Indexes {
+				indexes[i] = map[string]any{"name": index.Name, "definition": index.Definition, "bytes": index.Bytes, "valid": index.Valid}
+			}
+			return map[string]any{
+				"estimated_rows":    stats.EstimatedRows,
+				"total_bytes":       stats.TotalBytes,
+				"normalize_vectors": stats.NormalizeVectors,
+				"fast_dimension":    stats.FastDimension,
+				"created_at":        stats.CreatedAt,
+				"indexes":           indexes,
+			}, nil
+		},
+	}
+}
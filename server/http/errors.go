@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var (
+	errBadRequest         = errors.New("bad request")
+	errCollectionNotFound = errors.New("collection not found")
+)
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// statusOf maps vectordata errors to HTTP status codes.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, vectordata.ErrNotFound), errors.Is(err, errCollectionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errBadRequest),
+		errors.Is(err, vectordata.ErrInvalidFilter),
+		errors.Is(err, vectordata.ErrInvalidCursor),
+		errors.Is(err, vectordata.ErrInvalidRecord),
+		errors.Is(err, vectordata.ErrDimensionMismatch),
+		errors.Is(err, vectordata.ErrSchemaMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, vectordata.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, vectordata.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, vectordata.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, vectordata.ErrUnavailable), errors.Is(err, vectordata.ErrNotReady):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, errors.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), errorJSON{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// ndjsonWriter streams newline-delimited JSON. Errors after the first line
+// can no longer change the status, so they are written as a final
+// {"error": ...} line.
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	encoder *json.Encoder
+	started bool
+}
+
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	return &ndjsonWriter{w: w, encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) start() {
+	if !n.started {
+		n.w.Header().Set("Content-Type", ndjsonContentType)
+		n.w.WriteHeader(http.StatusOK)
+		n.started = true
+	}
+}
+
+// write encodes one line and reports whether the client is still reading.
+func (n *ndjsonWriter) write(line any) bool {
+	n.start()
+	return n.encoder.Encode(line) == nil
+}
+
+func (n *ndjsonWriter) flush() {
+	n.start()
+	if flusher, ok := n.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (n *ndjsonWriter) fail(err error) {
+	if !n.started {
+		writeError(n.w, err)
+		return
+	}
+	_ = n.encoder.Encode(errorJSON{Error: err.Error()})
+}
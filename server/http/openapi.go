@@ -0,0 +1,232 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// route is one API endpoint. NewHandler registers the routes and OpenAPI
+// documents them, so the two cannot drift apart.
+type route struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	// request and response name schemas of the OpenAPI document.
+	request  string
+	response string
+	// ndjson marks responses that may stream newline-delimited JSON of the
+	// item schema, and ndjsonOnly those that always do.
+	ndjson     bool
+	ndjsonOnly bool
+	item       string
+	// query lists the query parameters.
+	query []string
+	serve func(s *server, w http.ResponseWriter, r *http.Request)
+}
+
+var routes = []route{
+	{
+		method: http.MethodPut, path: "/collections/{name}", id: "ensureCollection",
+		summary: "Create the collection or check its schema",
+		request: "Collection", response: "Collection",
+		serve: (*server).ensureCollection,
+	},
+	{
+		method: http.MethodPost, path: "/collections/{name}/records", id: "upsertRecords",
+		summary: "Insert or replace records",
+		request: "UpsertRequest", response: "UpsertResponse",
+		serve: (*server).upsert,
+	},
+	{
+		method: http.MethodGet, path: "/collections/{name}/records", id: "listRecords",
+		summary: "Stream records in ascending ID order as NDJSON",
+		ndjson:  true, ndjsonOnly: true, item: "Record",
+		query: []string{"filter", "after", "page_size", "include_vector", "include_metadata", "include_content"},
+		serve: (*server).list,
+	},
+	{
+		method: http.MethodGet, path: "/collections/{name}/records/{id}", id: "getRecord",
+		summary:  "Get a record by ID",
+		response: "Record",
+		serve:    (*server).get,
+	},
+	{
+		method: http.MethodPost, path: "/collections/{name}/search", id: "searchByVector",
+		summary: "Rank records by vector similarity",
+		request: "SearchRequest", response: "SearchResponse", ndjson: true, item: "SearchResult",
+		serve: (*server).search,
+	},
+	{
+		method: http.MethodPost, path: "/collections/{name}/delete", id: "deleteRecords",
+		summary: "Delete records by ID or by filter",
+		request: "DeleteRequest", response: "DeleteResponse",
+		serve: (*server).delete,
+	},
+	{
+		method: http.MethodPost, path: "/collections/{name}/count", id: "countRecords",
+		summary: "Count records matching a filter",
+		request: "CountRequest", response: "CountResponse",
+		serve: (*server).count,
+	},
+}
+
+// OpenAPI returns the OpenAPI 3 document of the API served by NewHandler,
+// ready to be encoded as JSON.
+func OpenAPI() map[string]any {
+	paths := make(map[string]any)
+	for _, route := range routes {
+		operation := map[string]any{
+			"operationId": route.id,
+			"summary":     route.summary,
+			"parameters":  parameters(route),
+			"responses":   responses(route),
+		}
+		if route.request != "" {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemaRef(route.request)}},
+			}
+		}
+		item, _ := paths[route.path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "go-vectorstore",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas()},
+	}
+}
+
+func parameters(route route) []any {
+	var out []any
+	for _, segment := range strings.Split(route.path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			out = append(out, map[string]any{
+				"name": strings.Trim(segment, "{}"), "in": "path", "required": true,
+				"schema": map[string]any{"type": "string"},
+			})
+		}
+	}
+	for _, name := range route.query {
+		schema := map[string]any{"type": "string"}
+		switch {
+		case name == "page_size":
+			schema = map[string]any{"type": "integer", "minimum": 1, "maximum": maxListPageSize}
+		case strings.HasPrefix(name, "include_"):
+			schema = map[string]any{"type": "boolean"}
+		}
+		parameter := map[string]any{"name": name, "in": "query", "schema": schema}
+		if name == "filter" {
+			parameter["description"] = "Filter in the JSON filter DSL"
+		}
+		out = append(out, parameter)
+	}
+	return out
+}
+
+func responses(route route) map[string]any {
+	content := map[string]any{}
+	if !route.ndjsonOnly {
+		content["application/json"] = map[string]any{"schema": schemaRef(route.response)}
+	}
+	if route.ndjson {
+		content[ndjsonContentType] = map[string]any{"schema": schemaRef(route.item)}
+	}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": schemaRef("Error")}},
+	}
+	return map[string]any{
+		"200":     map[string]any{"description": "OK", "content": content},
+		"default": errorResponse,
+	}
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func object(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func arrayOf(items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": items}
+}
+
+func schemas() map[string]any {
+	str := map[string]any{"type": "string"}
+	integer := map[string]any{"type": "integer"}
+	number := map[string]any{"type": "number"}
+	boolean := map[string]any{"type": "boolean"}
+	anyValue := map[string]any{}
+
+	return map[string]any{
+		"Collection": object(map[string]any{
+			"name":              str,
+			"dimension":         integer,
+			"metric":            map[string]any{"type": "string", "enum": []string{"cosine", "l2", "inner_product"}},
+			"mode":              map[string]any{"type": "string", "enum": []string{"strict", "auto_migrate"}},
+			"normalize_vectors": boolean,
+			"element_type":      map[string]any{"type": "string", "enum": []string{"float32", "float64", "int8"}},
+			"fast_dimension":    integer,
+		}, "dimension"),
+		"Record": object(map[string]any{
+			"id":          str,
+			"vector":      arrayOf(number),
+			"vector64":    arrayOf(number),
+			"vector_int8": arrayOf(integer),
+			"metadata":    map[string]any{"type": "object", "additionalProperties": true},
+			"content":     str,
+		}, "id"),
+		"Filter": object(map[string]any{
+			"op": map[string]any{"type": "string", "enum": []string{
+				"eq", "in", "gt", "lt", "exists", "contains", "similar", "and", "or", "not",
+			}},
+			"field":   map[string]any{"type": "string", "description": `A column such as "id" or "content", or "metadata." followed by a dot-separated path`},
+			"path":    map[string]any{"type": "array", "items": str, "description": `Metadata path for field "metadata", for keys containing dots`},
+			"value":   anyValue,
+			"values":  arrayOf(anyValue),
+			"filters": arrayOf(schemaRef("Filter")),
+			"filter":  schemaRef("Filter"),
+		}, "op"),
+		"UpsertRequest":  object(map[string]any{"records": arrayOf(schemaRef("Record"))}, "records"),
+		"UpsertResponse": object(map[string]any{"upserted": integer}),
+		"SearchRequest": object(map[string]any{
+			"vector":           arrayOf(number),
+			"top_k":            map[string]any{"type": "integer", "minimum": 1, "maximum": maxTopK},
+			"filter":           schemaRef("Filter"),
+			"threshold":        number,
+			"include_vector":   boolean,
+			"include_metadata": boolean,
+			"include_content":  boolean,
+		}, "vector", "top_k"),
+		"SearchResult": object(map[string]any{
+			"record":   schemaRef("Record"),
+			"distance": number,
+			"score":    number,
+		}),
+		"SearchResponse": object(map[string]any{"results": arrayOf(schemaRef("SearchResult"))}),
+		"DeleteRequest": object(map[string]any{
+			"ids":    arrayOf(str),
+			"filter": schemaRef("Filter"),
+		}),
+		"DeleteResponse": object(map[string]any{"deleted": integer}),
+		"CountRequest":   object(map[string]any{"filter": schemaRef("Filter")}),
+		"CountResponse":  object(map[string]any{"count": integer}),
+		"Error":          object(map[string]any{"error": str}, "error"),
+	}
+}
@@ -0,0 +1,431 @@
+// Package http exposes vectordata collections over a JSON REST API.
+//
+// NewHandler serves the collections of one store:
+//
+//	handler := http.NewHandler(store, http.Options{
+//		Authorize: func(r *nethttp.Request, collection string, op vectordata.Operation) error { ... },
+//	})
+//	nethttp.ListenAndServe(":8080", handler)
+//
+// Collections are ensured with PUT /collections/{name} and then written,
+// read, searched, counted and deleted under that path. Filters use the JSON
+// filter DSL of vectordata.ParseFilterJSON. Searches answer with one JSON
+// document, or with newline-delimited JSON flushed per result when the
+// request accepts application/x-ndjson; listing records always streams
+// NDJSON, page by page. GET /openapi.json serves the OpenAPI 3 document of
+// the API.
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/server/internal/wire"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultMaxBodyBytes = 32 << 20
+	defaultListPageSize = 500
+	maxListPageSize     = 10000
+	maxTopK             = 1000
+
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// Operations the API authorizes besides the collection methods named by
+// vectordata.Operation.
+const (
+	OpEnsureCollection vectordata.Operation = "EnsureCollection"
+	OpList             vectordata.Operation = "List"
+	// OpDeleteByFilter is authorized after vectordata.OpDelete, which every
+	// delete request needs before its body is read.
+	OpDeleteByFilter vectordata.Operation = "DeleteByFilter"
+)
+
+// ErrUnauthenticated is returned by Authorize hooks to answer 401 instead of
+// 403.
+var ErrUnauthenticated = errors.New("http: unauthenticated")
+
+// Options configures a handler.
+type Options struct {
+	// Middleware wraps the handler, first entry outermost, e.g. for
+	// authentication, logging or CORS.
+	Middleware []func(http.Handler) http.Handler
+	// Authorize, when set, is called before every collection operation.
+	// Errors wrapping ErrUnauthenticated answer 401 and other errors 403.
+	Authorize func(r *http.Request, collection string, op vectordata.Operation) error
+	// Resolve, when set, returns collections that were not ensured through
+	// the API, e.g. handles created at startup. Without it, such collections
+	// answer 404.
+	Resolve func(ctx context.Context, name string) (vectordata.Collection, error)
+	// MaxBodyBytes bounds request bodies (default 32 MiB).
+	MaxBodyBytes int64
+}
+
+type server struct {
+	store vectordata.VectorStore
+	opts  Options
+
+	mu          sync.RWMutex
+	collections map[string]vectordata.Collection
+}
+
+// NewHandler returns the REST API over store.
+func NewHandler(store vectordata.VectorStore, opts Options) http.Handler {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	s := &server{store: store, opts: opts, collections: make(map[string]vectordata.Collection)}
+
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		serve := route.serve
+		mux.HandleFunc(route.method+" "+route.path, func(w http.ResponseWriter, r *http.Request) {
+			serve(s, w, r)
+		})
+	}
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, OpenAPI())
+	})
+
+	var handler http.Handler = mux
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		handler = opts.Middleware[i](handler)
+	}
+	return handler
+}
+
+type collectionJSON struct {
+	Name             string                       `json:"name"`
+	Dimension        int                          `json:"dimension"`
+	Metric           vectordata.DistanceMetric    `json:"metric,omitempty"`
+	Mode             vectordata.EnsureMode        `json:"mode,omitempty"`
+	NormalizeVectors bool                         `json:"normalize_vectors,omitempty"`
+	ElementType      vectordata.VectorElementType `json:"element_type,omitempty"`
+	FastDimension    int                          `json:"fast_dimension,omitempty"`
+}
+
+type upsertRequest struct {
+	Records []wire.Record `json:"records"`
+}
+
+type searchRequest struct {
+	Vector          []float32       `json:"vector"`
+	TopK            int             `json:"top_k"`
+	Filter          json.RawMessage `json:"filter,omitempty"`
+	Threshold       *float64        `json:"threshold,omitempty"`
+	IncludeVector   *bool           `json:"include_vector,omitempty"`
+	IncludeMetadata *bool           `json:"include_metadata,omitempty"`
+	IncludeContent  *bool           `json:"include_content,omitempty"`
+}
+
+type deleteRequest struct {
+	IDs    []string        `json:"ids,omitempty"`
+	Filter json.RawMessage `json:"filter,omitempty"`
+}
+
+type countRequest struct {
+	Filter json.RawMessage `json:"filter,omitempty"`
+}
+
+func (s *server) ensureCollection(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !s.authorize(w, r, name, OpEnsureCollection) {
+		return
+	}
+	var body collectionJSON
+	if !s.decode(w, r, &body) {
+		return
+	}
+	collection, err := s.store.EnsureCollection(r.Context(), vectordata.CollectionSpec{
+		Name:             name,
+		Dimension:        body.Dimension,
+		Metric:           body.Metric,
+		Mode:             body.Mode,
+		NormalizeVectors: body.NormalizeVectors,
+		ElementType:      body.ElementType,
+		FastDimension:    body.FastDimension,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mu.Lock()
+	s.collections[name] = collection
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, collectionJSON{
+		Name:             collection.Name(),
+		Dimension:        collection.Dimension(),
+		Metric:           collection.Metric(),
+		Mode:             body.Mode,
+		NormalizeVectors: body.NormalizeVectors,
+		ElementType:      body.ElementType,
+		FastDimension:    body.FastDimension,
+	})
+}
+
+func (s *server) upsert(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, vectordata.OpUpsert)
+	if !ok {
+		return
+	}
+	var body upsertRequest
+	if !s.decode(w, r, &body) {
+		return
+	}
+	records := make([]vectordata.Record, len(body.Records))
+	for i, record := range body.Records {
+		records[i] = record.ToRecord()
+	}
+	if err := collection.Upsert(r.Context(), records); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"upserted": len(records)})
+}
+
+func (s *server) get(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, vectordata.OpGet)
+	if !ok {
+		return
+	}
+	record, err := collection.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, wire.FromRecord(record))
+}
+
+func (s *server) list(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, OpList)
+	if !ok {
+		return
+	}
+	lister, ok := collection.(vectordata.RecordLister)
+	if !ok {
+		writeError(w, fmt.Errorf("collection %q cannot list records: %w", collection.Name(), errors.ErrUnsupported))
+		return
+	}
+	query := r.URL.Query()
+	filter, err := vectordata.ParseFilterJSON([]byte(query.Get("filter")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	pageSize := defaultListPageSize
+	if raw := query.Get("page_size"); raw != "" {
+		pageSize, err = strconv.Atoi(raw)
+		if err != nil || pageSize <= 0 || pageSize > maxListPageSize {
+			writeError(w, fmt.Errorf("%w: page_size must be between 1 and %d", errBadRequest, maxListPageSize))
+			return
+		}
+	}
+	projection := vectordata.Projection{
+		IncludeVector:   query.Get("include_vector") == "true",
+		IncludeMetadata: query.Get("include_metadata") != "false",
+		IncludeContent:  query.Get("include_content") != "false",
+	}
+
+	opts := vectordata.ListOptions{Filter: filter, After: query.Get("after"), Limit: pageSize, Projection: &projection}
+	stream := newNDJSONWriter(w)
+	for {
+		page, err := lister.List(r.Context(), opts)
+		if err != nil {
+			stream.fail(err)
+			return
+		}
+		for _, record := range page {
+			if !stream.write(wire.FromRecord(record)) {
+				return
+			}
+		}
+		stream.flush()
+		if len(page) < opts.Limit {
+			return
+		}
+		opts.After = page[len(page)-1].ID
+	}
+}
+
+func (s *server) search(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, vectordata.OpSearchByVector)
+	if !ok {
+		return
+	}
+	var body searchRequest
+	if !s.decode(w, r, &body) {
+		return
+	}
+	filter, err := vectordata.ParseFilterJSON(body.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if body.TopK <= 0 || body.TopK > maxTopK {
+		writeError(w, fmt.Errorf("%w: top_k must be between 1 and %d", errBadRequest, maxTopK))
+		return
+	}
+	projection := vectordata.DefaultProjection()
+	setFlag(&projection.IncludeVector, body.IncludeVector)
+	setFlag(&projection.IncludeMetadata, body.IncludeMetadata)
+	setFlag(&projection.IncludeContent, body.IncludeContent)
+
+	results, err := collection.SearchByVector(r.Context(), body.Vector, body.TopK, vectordata.SearchOptions{
+		Filter:     filter,
+		Projection: &projection,
+		Threshold:  body.Threshold,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if r.Header.Get("Accept") == ndjsonContentType {
+		stream := newNDJSONWriter(w)
+		for _, result := range results {
+			if !stream.write(wire.FromResult(result)) {
+				return
+			}
+			stream.flush()
+		}
+		return
+	}
+	out := make([]wire.Result, len(results))
+	for i, result := range results {
+		out[i] = wire.FromResult(result)
+	}
+	writeJSON(w, http.StatusOK, map[string][]wire.Result{"results": out})
+}
+
+func (s *server) delete(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, vectordata.OpDelete)
+	if !ok {
+		return
+	}
+	var body deleteRequest
+	if !s.decode(w, r, &body) {
+		return
+	}
+	filter, err := vectordata.ParseFilterJSON(body.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if (filter == nil) == (len(body.IDs) == 0) {
+		writeError(w, fmt.Errorf("%w: exactly one of ids and filter is required", errBadRequest))
+		return
+	}
+
+	var deleted int64
+	if filter == nil {
+		deleted, err = collection.Delete(r.Context(), body.IDs)
+	} else {
+		if !s.authorize(w, r, r.PathValue("name"), OpDeleteByFilter) {
+			return
+		}
+		deleter, ok := collection.(vectordata.FilterDeleter)
+		if !ok {
+			writeError(w, fmt.Errorf("collection %q cannot delete by filter: %w", collection.Name(), errors.ErrUnsupported))
+			return
+		}
+		deleted, err = deleter.DeleteByFilter(r.Context(), filter)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"deleted": deleted})
+}
+
+func (s *server) count(w http.ResponseWriter, r *http.Request) {
+	collection, ok := s.collection(w, r, vectordata.OpCount)
+	if !ok {
+		return
+	}
+	var body countRequest
+	if !s.decode(w, r, &body) {
+		return
+	}
+	filter, err := vectordata.ParseFilterJSON(body.Filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	count, err := collection.Count(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"count": count})
+}
+
+// collection authorizes op on the collection named in the path and returns
+// it, or writes the error response.
+func (s *server) collection(w http.ResponseWriter, r *http.Request, op vectordata.Operation) (vectordata.Collection, bool) {
+	name := r.PathValue("name")
+	if !s.authorize(w, r, name, op) {
+		return nil, false
+	}
+	s.mu.RLock()
+	collection, ok := s.collections[name]
+	s.mu.RUnlock()
+	if ok {
+		return collection, true
+	}
+	if s.opts.Resolve == nil {
+		writeError(w, fmt.Errorf("%w: collection %q is not ensured", errCollectionNotFound, name))
+		return nil, false
+	}
+	collection, err := s.opts.Resolve(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	if collection == nil {
+		writeError(w, fmt.Errorf("%w: collection %q", errCollectionNotFound, name))
+		return nil, false
+	}
+	return collection, true
+}
+
+func (s *server) authorize(w http.ResponseWriter, r *http.Request, collection string, op vectordata.Operation) bool {
+	if s.opts.Authorize == nil {
+		return true
+	}
+	if err := s.opts.Authorize(r, collection, op); err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, ErrUnauthenticated) {
+			status = http.StatusUnauthorized
+		}
+		writeJSON(w, status, errorJSON{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+func (s *server) decode(w http.ResponseWriter, r *http.Request, out any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, fmt.Errorf("%w: request body exceeds %d bytes", vectordata.ErrTooLarge, tooLarge.Limit))
+		} else {
+			writeError(w, fmt.Errorf("%w: decode request body: %v", errBadRequest, err))
+		}
+		return false
+	}
+	return true
+}
+
+func setFlag(flag *bool, value *bool) {
+	if value != nil {
+		*flag = *value
+	}
+}
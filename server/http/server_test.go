@@ -0,0 +1,394 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/server/internal/wire"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type stubStore struct {
+	collections map[string]*stubCollection
+}
+
+func (s *stubStore) EnsureCollection(_ context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	if spec.Dimension <= 0 {
+		return nil, vectordata.ErrDimensionMismatch
+	}
+	collection := &stubCollection{name: spec.Name, dimension: spec.Dimension, records: map[string]vectordata.Record{}}
+	s.collections[spec.Name] = collection
+	return collection, nil
+}
+
+func (s *stubStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.collections[name]
+}
+
+type stubCollection struct {
+	vectordata.Collection
+	name       string
+	dimension  int
+	records    map[string]vectordata.Record
+	lastFilter vectordata.Filter
+	listCalls  int
+}
+
+func (c *stubCollection) Name() string                      { return c.name }
+func (c *stubCollection) Dimension() int                    { return c.dimension }
+func (c *stubCollection) Metric() vectordata.DistanceMetric { return vectordata.DistanceCosine }
+
+func (c *stubCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	for _, record := range records {
+		if len(record.Vector) != c.dimension {
+			return vectordata.ErrDimensionMismatch
+		}
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *stubCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	record, ok := c.records[id]
+	if !ok {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *stubCollection) Delete(_ context.Context, ids []string) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := c.records[id]; ok {
+			delete(c.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *stubCollection) Count(_ context.Context, filter vectordata.Filter) (int64, error) {
+	c.lastFilter = filter
+	return int64(len(c.records)), nil
+}
+
+func (c *stubCollection) SearchByVector(_ context.Context, _ []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.lastFilter = opts.Filter
+	var results []vectordata.SearchResult
+	for _, id := range c.sortedIDs() {
+		results = append(results, vectordata.SearchResult{Record: c.records[id], Distance: 0.1, Score: 0.9})
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func (c *stubCollection) List(_ context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	c.listCalls++
+	var page []vectordata.Record
+	for _, id := range c.sortedIDs() {
+		if id > opts.After && len(page) < opts.Limit {
+			page = append(page, c.records[id])
+		}
+	}
+	return page, nil
+}
+
+func (c *stubCollection) sortedIDs() []string {
+	ids := make([]string, 0, len(c.records))
+	for id := range c.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func newTestServer(t *testing.T, opts Options) (*httptest.Server, *stubStore) {
+	t.Helper()
+	store := &stubStore{collections: map[string]*stubCollection{}}
+	server := httptest.NewServer(NewHandler(store, opts))
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+func do(t *testing.T, method, url, body string, header ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeBody(t *testing.T, resp *http.Response, out any) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+}
+
+func seed(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	if resp := do(t, http.MethodPut, server.URL+"/collections/docs", `{"dimension":2}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("ensure status = %d", resp.StatusCode)
+	}
+	resp := do(t, http.MethodPost, server.URL+"/collections/docs/records", `{"records":[
+		{"id":"a","vector":[1,0],"metadata":{"category":"news"},"content":"alpha"},
+		{"id":"b","vector":[0,1],"metadata":{"category":"blog"}},
+		{"id":"c","vector":[1,1]}
+	]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("upsert status = %d", resp.StatusCode)
+	}
+}
+
+func TestRecordsRoundTrip(t *testing.T) {
+	// Arrange
+	server, _ := newTestServer(t, Options{})
+	seed(t, server)
+
+	// Act
+	resp := do(t, http.MethodGet, server.URL+"/collections/docs/records/a", "")
+
+	// Assert
+	var record wire.Record
+	decodeBody(t, resp, &record)
+	if resp.StatusCode != http.StatusOK || record.ID != "a" || record.Content == nil || *record.Content != "alpha" || record.Metadata["category"] != "news" {
+		t.Fatalf("unexpected response %d %+v", resp.StatusCode, record)
+	}
+}
+
+func TestSearchParsesFilterDSL(t *testing.T) {
+	// Arrange
+	server, store := newTestServer(t, Options{})
+	seed(t, server)
+
+	// Act
+	resp := do(t, http.MethodPost, server.URL+"/collections/docs/search",
+		`{"vector":[1,0],"top_k":2,"filter":{"op":"eq","field":"metadata.category","value":"news"}}`)
+
+	// Assert
+	var body struct {
+		Results []wire.Result `json:"results"`
+	}
+	decodeBody(t, resp, &body)
+	if resp.StatusCode != http.StatusOK || len(body.Results) != 2 || body.Results[0].Record.ID != "a" || body.Results[0].Score != 0.9 {
+		t.Fatalf("unexpected response %d %+v", resp.StatusCode, body)
+	}
+	want := vectordata.Eq(vectordata.Metadata("category"), "news")
+	if got := store.collections["docs"].lastFilter; !reflect.DeepEqual(got, want) {
+		t.Fatalf("filter = %#v, want %#v", got, want)
+	}
+}
+
+func TestSearchStreamsNDJSON(t *testing.T) {
+	// Arrange
+	server, _ := newTestServer(t, Options{})
+	seed(t, server)
+
+	// Act
+	resp := do(t, http.MethodPost, server.URL+"/collections/docs/search", `{"vector":[1,0],"top_k":10}`, "Accept", ndjsonContentType)
+
+	// Assert
+	if got := resp.Header.Get("Content-Type"); got != ndjsonContentType {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var result wire.Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, result.Record.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Fatalf("streamed ids = %v", ids)
+	}
+}
+
+func TestListStreamsAllPages(t *testing.T) {
+	// Arrange
+	server, store := newTestServer(t, Options{})
+	seed(t, server)
+
+	// Act
+	resp := do(t, http.MethodGet, server.URL+"/collections/docs/records?page_size=2", "")
+
+	// Assert
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var record wire.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, record.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c" || store.collections["docs"].listCalls != 2 {
+		t.Fatalf("ids = %v after %d pages", ids, store.collections["docs"].listCalls)
+	}
+}
+
+func TestCountAndDelete(t *testing.T) {
+	// Arrange
+	server, _ := newTestServer(t, Options{})
+	seed(t, server)
+
+	// Act
+	deleteResp := do(t, http.MethodPost, server.URL+"/collections/docs/delete", `{"ids":["a","missing"]}`)
+	countResp := do(t, http.MethodPost, server.URL+"/collections/docs/count", `{}`)
+
+	// Assert
+	var deleted struct{ Deleted int64 }
+	var count struct{ Count int64 }
+	decodeBody(t, deleteResp, &deleted)
+	decodeBody(t, countResp, &count)
+	if deleted.Deleted != 1 || count.Count != 2 {
+		t.Fatalf("deleted = %d, count = %d", deleted.Deleted, count.Count)
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	// Arrange
+	server, _ := newTestServer(t, Options{MaxBodyBytes: 256})
+	seed(t, server)
+
+	cases := []struct {
+		name, method, path, body string
+		status                   int
+	}{
+		{"unknown collection", http.MethodPost, "/collections/other/count", `{}`, http.StatusNotFound},
+		{"missing record", http.MethodGet, "/collections/docs/records/zzz", "", http.StatusNotFound},
+		{"invalid filter", http.MethodPost, "/collections/docs/count", `{"filter":{"op":"between"}}`, http.StatusBadRequest},
+		{"dimension mismatch", http.MethodPost, "/collections/docs/records", `{"records":[{"id":"x","vector":[1]}]}`, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/collections/docs/count", `{"where":1}`, http.StatusBadRequest},
+		{"top_k too large", http.MethodPost, "/collections/docs/search", `{"vector":[1,0],"top_k":1001}`, http.StatusBadRequest},
+		{"delete without target", http.MethodPost, "/collections/docs/delete", `{}`, http.StatusBadRequest},
+		{"delete by filter unsupported", http.MethodPost, "/collections/docs/delete", `{"filter":{"op":"exists","field":"metadata.a"}}`, http.StatusNotImplemented},
+		{"body too large", http.MethodPost, "/collections/docs/records", `{"records":[` + strings.Repeat(`{"id":"x","vector":[1,1]},`, 20) + `]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			resp := do(t, tc.method, server.URL+tc.path, tc.body)
+
+			// Assert
+			var body errorJSON
+			decodeBody(t, resp, &body)
+			if resp.StatusCode != tc.status || body.Error == "" {
+				t.Fatalf("status = %d (%q), want %d", resp.StatusCode, body.Error, tc.status)
+			}
+		})
+	}
+}
+
+func TestAuthorizeAndMiddleware(t *testing.T) {
+	// Arrange
+	var seen []string
+	server, _ := newTestServer(t, Options{
+		Middleware: []func(http.Handler) http.Handler{
+			func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					seen = append(seen, r.URL.Path)
+					next.ServeHTTP(w, r)
+				})
+			},
+		},
+		Authorize: func(r *http.Request, collection string, op vectordata.Operation) error {
+			switch {
+			case r.Header.Get("Authorization") == "":
+				return ErrUnauthenticated
+			case op == vectordata.OpUpsert, op == vectordata.OpDelete:
+				return errors.New("read-only token")
+			}
+			return nil
+		},
+	})
+
+	// Act
+	anonymous := do(t, http.MethodPut, server.URL+"/collections/docs", `{"dimension":2}`)
+	ensured := do(t, http.MethodPut, server.URL+"/collections/docs", `{"dimension":2}`, "Authorization", "Bearer t")
+	write := do(t, http.MethodPost, server.URL+"/collections/docs/records", `{"records":[]}`, "Authorization", "Bearer t")
+	malformedDelete := do(t, http.MethodPost, server.URL+"/collections/docs/delete", `not json`, "Authorization", "Bearer t")
+
+	// Assert
+	if anonymous.StatusCode != http.StatusUnauthorized || ensured.StatusCode != http.StatusOK || write.StatusCode != http.StatusForbidden {
+		t.Fatalf("statuses = %d, %d, %d", anonymous.StatusCode, ensured.StatusCode, write.StatusCode)
+	}
+	if malformedDelete.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected the delete to be refused before its body is read, got %d", malformedDelete.StatusCode)
+	}
+	if len(seen) != 4 {
+		t.Fatalf("middleware saw %v", seen)
+	}
+}
+
+func TestResolveServesCollectionsNotEnsured(t *testing.T) {
+	// Arrange
+	existing := &stubCollection{name: "docs", dimension: 2, records: map[string]vectordata.Record{"a": {ID: "a"}}}
+	server, _ := newTestServer(t, Options{
+		Resolve: func(_ context.Context, name string) (vectordata.Collection, error) {
+			if name == "docs" {
+				return existing, nil
+			}
+			return nil, nil
+		},
+	})
+
+	// Act
+	found := do(t, http.MethodGet, server.URL+"/collections/docs/records/a", "")
+	missing := do(t, http.MethodGet, server.URL+"/collections/other/records/a", "")
+
+	// Assert
+	if found.StatusCode != http.StatusOK || missing.StatusCode != http.StatusNotFound {
+		t.Fatalf("statuses = %d, %d", found.StatusCode, missing.StatusCode)
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	// Arrange
+	server, _ := newTestServer(t, Options{})
+
+	// Act
+	resp := do(t, http.MethodGet, server.URL+"/openapi.json", "")
+
+	// Assert
+	var document struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	decodeBody(t, resp, &document)
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Fatalf("openapi = %q", document.OpenAPI)
+	}
+	for _, route := range routes {
+		if _, ok := document.Paths[route.path][strings.ToLower(route.method)]; !ok {
+			t.Fatalf("%s %s is not documented", route.method, route.path)
+		}
+	}
+	raw, _ := json.Marshal(OpenAPI())
+	for _, ref := range strings.Split(string(raw), `"$ref":"#/components/schemas/`)[1:] {
+		name := ref[:strings.IndexByte(ref, '"')]
+		if _, ok := schemas()[name]; !ok {
+			t.Fatalf("dangling schema reference %q", name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package wire holds the JSON forms of vectordata values shared by the REST
+// API and the admin server.
+package wire
+
+import "github.com/gabisonia/go-vectorstore/vectordata"
+
+// Record is the wire form of vectordata.Record.
+type Record struct {
+	ID         string         `json:"id"`
+	Vector     []float32      `json:"vector,omitempty"`
+	Vector64   []float64      `json:"vector64,omitempty"`
+	VectorInt8 []int8         `json:"vector_int8,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	Content    *string        `json:"content,omitempty"`
+}
+
+// FromRecord returns the wire form of record.
+func FromRecord(record vectordata.Record) Record {
+	return Record{
+		ID:         record.ID,
+		Vector:     record.Vector,
+		Vector64:   record.Vector64,
+		VectorInt8: record.VectorInt8,
+		Metadata:   record.Metadata,
+		Content:    record.Content,
+	}
+}
+
+// ToRecord returns the vectordata.Record of r.
+func (r Record) ToRecord() vectordata.Record {
+	return vectordata.Record{
+		ID:         r.ID,
+		Vector:     r.Vector,
+		Vector64:   r.Vector64,
+		VectorInt8: r.VectorInt8,
+		Metadata:   r.Metadata,
+		Content:    r.Content,
+	}
+}
+
+// Result is the wire form of vectordata.SearchResult.
+type Result struct {
+	Record   Record  `json:"record"`
+	Distance float64 `json:"distance"`
+	Score    float64 `json:"score"`
+}
+
+// FromResult returns the wire form of result.
+func FromResult(result vectordata.SearchResult) Result {
+	return Result{Record: FromRecord(result.Record), Distance: result.Distance, Score: result.Score}
+}
@@ -0,0 +1,35 @@
+package faiss
+
+import "github.com/gabisonia/go-vectorstore/vectordata"
+
+// capabilities describes the backend for the store and its collections.
+func capabilities() vectordata.Capabilities {
+	return vectordata.Capabilities{
+		Metrics:          []vectordata.DistanceMetric{vectordata.DistanceCosine, vectordata.DistanceL2, vectordata.DistanceInnerProduct},
+		IndexManagement:  true,
+		IndexMethods:     []vectordata.IndexMethod{vectordata.IndexMethodHNSW},
+		NormalizeVectors: true,
+		SearchCursors:    true,
+	}
+}
+
+// Capabilities reports the features of the backend.
+func (s *FaissVectorStore) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// Capabilities reports the features of the backend.
+func (c *FaissCollection) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// SupportsFilter returns the error a query with filter would fail with, if
+// any.
+func (c *FaissCollection) SupportsFilter(filter vectordata.Filter) error {
+	if filter == nil {
+		return nil
+	}
+	// Filters run in Go against each record.
+	_, err := vectordata.MatchFilter(filter, vectordata.Record{})
+	return err
+}
@@ -0,0 +1,532 @@
+package faiss
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type writeMode int
+
+const (
+	writeModeInsert writeMode = iota
+	writeModeUpsert
+	// writeModeSkip writes records whose ID is absent and ignores the rest.
+	writeModeSkip
+	// writeModeMergeMetadata upserts, merging the new metadata into the
+	// existing metadata.
+	writeModeMergeMetadata
+)
+
+var (
+	_ vectordata.ConflictUpserter = (*FaissCollection)(nil)
+	_ vectordata.ReturningDeleter = (*FaissCollection)(nil)
+	_ vectordata.Evictor          = (*FaissCollection)(nil)
+	_ vectordata.Reindexer        = (*FaissCollection)(nil)
+)
+
+// FaissCollection is a FAISS-backed vector collection.
+type FaissCollection struct {
+	store     *FaissVectorStore
+	name      string
+	dimension int
+	metric    vectordata.DistanceMetric
+	// maxRecords and evictBy are set for handles returned by EnsureCollection
+	// with CollectionSpec.MaxRecords.
+	maxRecords int64
+	evictBy    string
+}
+
+func (c *FaissCollection) Name() string {
+	return c.name
+}
+
+func (c *FaissCollection) Dimension() int {
+	return c.dimension
+}
+
+func (c *FaissCollection) Metric() vectordata.DistanceMetric {
+	return c.metric
+}
+
+func (c *FaissCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeInsert)
+}
+
+func (c *FaissCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeUpsert)
+}
+
+// UpsertWithOptions resolves conflicts under the collection's write lock, so
+// no other write can interleave.
+func (c *FaissCollection) UpsertWithOptions(ctx context.Context, records []vectordata.Record, opts vectordata.UpsertOptions) error {
+	if err := opts.OnConflict.Validate(); err != nil {
+		return err
+	}
+	switch opts.OnConflict {
+	case vectordata.ConflictSkip:
+		return c.writeRecords(ctx, records, writeModeSkip)
+	case vectordata.ConflictMergeMetadata:
+		return c.writeRecords(ctx, records, writeModeMergeMetadata)
+	default:
+		return c.writeRecords(ctx, records, writeModeUpsert)
+	}
+}
+
+func (c *FaissCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	state, err := c.readState()
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	defer state.mu.RUnlock()
+
+	stored, ok := state.records[id]
+	if !ok {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	return projectRecord(stored.record, vectordata.Projection{
+		IncludeVector:   true,
+		IncludeMetadata: true,
+		IncludeContent:  true,
+	}), nil
+}
+
+func (c *FaissCollection) Delete(_ context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	state, err := c.writeState()
+	if err != nil {
+		return 0, err
+	}
+	defer state.mu.Unlock()
+
+	deleted, err := state.remove(ids)
+	if err != nil || len(deleted) == 0 {
+		return int64(len(deleted)), err
+	}
+	return int64(len(deleted)), state.persist()
+}
+
+// DeleteReturning deletes ids and returns the ones that existed.
+func (c *FaissCollection) DeleteReturning(_ context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	state, err := c.writeState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.mu.Unlock()
+
+	deleted, err := state.remove(ids)
+	if err != nil || len(deleted) == 0 {
+		return deleted, err
+	}
+	return deleted, state.persist()
+}
+
+// Evict deletes the records past the handle's MaxRecords in EvictBy order.
+// Writes through the handle evict under the same lock, so this is only
+// needed after writes through other handles.
+func (c *FaissCollection) Evict(_ context.Context) (int64, error) {
+	if c.maxRecords == 0 {
+		return 0, nil
+	}
+	state, err := c.writeState()
+	if err != nil {
+		return 0, err
+	}
+	defer state.mu.Unlock()
+
+	evicted, err := state.evict(c.maxRecords, c.evictBy)
+	if err != nil || len(evicted) == 0 {
+		return int64(len(evicted)), err
+	}
+	return int64(len(evicted)), state.persist()
+}
+
+// Count evaluates the filter in Go against every record.
+func (c *FaissCollection) Count(_ context.Context, filter vectordata.Filter) (int64, error) {
+	state, err := c.readState()
+	if err != nil {
+		return 0, err
+	}
+	defer state.mu.RUnlock()
+
+	if filter == nil {
+		return int64(len(state.records)), nil
+	}
+
+	var count int64
+	for _, stored := range state.records {
+		matched, err := vectordata.MatchFilter(filter, stored.record)
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// List returns up to opts.Limit records matching opts.Filter with IDs after
+// opts.After, in ascending ID order.
+func (c *FaissCollection) List(_ context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	if opts.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be > 0")
+	}
+	state, err := c.readState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.mu.RUnlock()
+
+	ids := make([]string, 0, len(state.records))
+	for id, stored := range state.records {
+		if id <= opts.After {
+			continue
+		}
+		if opts.Filter != nil {
+			matched, err := vectordata.MatchFilter(opts.Filter, stored.record)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	projection := resolveProjection(opts.Projection)
+	out := make([]vectordata.Record, 0, min(opts.Limit, len(ids)))
+	for _, id := range ids[:min(opts.Limit, len(ids))] {
+		out = append(out, projectRecord(state.records[id].record, projection))
+	}
+	return out, nil
+}
+
+// SearchByVector queries the FAISS index and applies filters in Go. Filtered
+// searches, and those after a cursor, fetch topK*OverFetch candidates and
+// widen the search until topK records match or the index is exhausted.
+func (c *FaissCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	if opts.Boosted() {
+		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	if topK <= 0 {
+		return nil, fmt.Errorf("topK must be > 0")
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return nil, err
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return nil, err
+	}
+	cursor, paged, err := vectordata.DecodeCursor(vector, opts)
+	if err != nil {
+		return nil, err
+	}
+	vector, err = vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Filter != nil {
+		// Surface invalid filters even when the collection is empty.
+		if _, err := vectordata.MatchFilter(opts.Filter, vectordata.Record{}); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := c.readState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.mu.RUnlock()
+
+	if state.normalize {
+		vector = vectordata.NormalizeVector(vector)
+	}
+	var after *vectordata.SearchCursor
+	if paged {
+		after = &cursor
+	}
+	results, err := c.search(state, prepareVector(c.metric, vector), topK, opts, after)
+	if err != nil {
+		return nil, err
+	}
+	return vectordata.NormalizeScores(c.metric, results, opts.ScoreNormalization), nil
+}
+
+// EnsureIndexes rebuilds the collection index with the requested HNSW
+// parameters. Metadata indexes are not applicable because filters run in Go
+// and are ignored.
+func (c *FaissCollection) EnsureIndexes(_ context.Context, opts vectordata.IndexOptions) error {
+	if opts.Vector == nil {
+		return nil
+	}
+	return c.buildIndex(opts.Vector, false)
+}
+
+// Reindex rebuilds the index with opts even when its description is
+// unchanged. Searches wait for the rebuild under the collection's write
+// lock, and the old index serves nothing afterwards.
+func (c *FaissCollection) Reindex(_ context.Context, opts vectordata.VectorIndexOptions) error {
+	return c.buildIndex(&opts, true)
+}
+
+// buildIndex rebuilds the index when force is set, the description changed
+// or removed records left tombstones.
+func (c *FaissCollection) buildIndex(opts *vectordata.VectorIndexOptions, force bool) error {
+	if opts.Metric != "" && opts.Metric != c.metric {
+		return fmt.Errorf("%w: index metric %q differs from collection metric %q", vectordata.ErrSchemaMismatch, opts.Metric, c.metric)
+	}
+	description, err := indexDescription(opts)
+	if err != nil {
+		return err
+	}
+
+	state, err := c.writeState()
+	if err != nil {
+		return err
+	}
+	defer state.mu.Unlock()
+
+	if !force && state.description == description && state.tombstones == 0 {
+		return nil
+	}
+	if err := state.rebuild(description); err != nil {
+		return err
+	}
+	return state.persist()
+}
+
+func (c *FaissCollection) search(state *collectionState, query []float32, topK int, opts vectordata.SearchOptions, cursor *vectordata.SearchCursor) ([]vectordata.SearchResult, error) {
+	projection := resolveProjection(opts.Projection)
+	total := state.indexSize()
+
+	// Tombstoned labels can occupy result slots, so they are always fetched
+	// on top, as is one more record to find ties with the last result.
+	k := topK + 1 + state.tombstones
+	if opts.Filter != nil || cursor != nil {
+		k = topK*c.store.opts.OverFetch + state.tombstones
+	}
+
+	for {
+		if k > total {
+			k = total
+		}
+		distances, labels, err := state.index.Search(query, k)
+		if err != nil {
+			return nil, fmt.Errorf("search index: %w", err)
+		}
+
+		results := make([]vectordata.SearchResult, 0, topK)
+		exhausted, complete := k >= total, false
+		for i, label := range labels {
+			if label < 0 {
+				continue
+			}
+			id, ok := state.labels[label]
+			if !ok {
+				continue
+			}
+			distance := normalizeDistance(c.metric, distances[i])
+			if len(results) >= topK && distance > results[topK-1].Distance {
+				// Results are ordered by distance, so only ties with the
+				// last one could still qualify; they are ordered by ID.
+				complete = true
+				break
+			}
+			if opts.Threshold != nil && distance > *opts.Threshold {
+				// Results are ordered by distance, so nothing further qualifies.
+				exhausted = true
+				break
+			}
+			record := state.records[id].record
+			if opts.Filter != nil {
+				matched, err := vectordata.MatchFilter(opts.Filter, record)
+				if err != nil {
+					return nil, err
+				}
+				if !matched {
+					continue
+				}
+			}
+			if cursor != nil && !cursor.After(distance, id) {
+				continue
+			}
+			results = append(results, vectordata.SearchResult{
+				Record:   record,
+				Distance: distance,
+				Score:    vectordata.ScoreFromDistance(c.metric, distance),
+			})
+		}
+
+		if complete || exhausted {
+			// Records are projected after ordering, which may read fields
+			// the projection leaves out.
+			if len(opts.OrderBy) > 0 {
+				if err := vectordata.OrderResults(results, opts.OrderBy); err != nil {
+					return nil, err
+				}
+			} else {
+				vectordata.SortResults(results)
+			}
+			results = results[:min(topK, len(results))]
+			for i := range results {
+				results[i].Record = projectRecord(results[i].Record, projection)
+			}
+			return results, nil
+		}
+		k *= 2
+	}
+}
+
+func (c *FaissCollection) writeRecords(_ context.Context, records []vectordata.Record, mode writeMode) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := c.store.opts.RecordLimits.Check(records); err != nil {
+		return err
+	}
+
+	batch := make([]vectordata.Record, 0, len(records))
+	positions := make(map[string]int, len(records))
+	for _, record := range records {
+		if strings.TrimSpace(record.ID) == "" {
+			return fmt.Errorf("record id is empty")
+		}
+		if err := c.validateVectorDimension(record.Vector); err != nil {
+			return err
+		}
+		cloned, err := cloneRecord(record)
+		if err != nil {
+			return fmt.Errorf("encode metadata for record %q: %w", record.ID, err)
+		}
+		if pos, ok := positions[record.ID]; ok {
+			switch mode {
+			case writeModeInsert:
+				return fmt.Errorf("%w: record %q already exists", vectordata.ErrConflict, record.ID)
+			case writeModeSkip:
+				continue
+			case writeModeMergeMetadata:
+				cloned.Metadata = vectordata.MergeMetadata(batch[pos].Metadata, cloned.Metadata)
+			}
+			batch[pos] = cloned
+			continue
+		}
+		positions[record.ID] = len(batch)
+		batch = append(batch, cloned)
+	}
+
+	state, err := c.writeState()
+	if err != nil {
+		return err
+	}
+	defer state.mu.Unlock()
+
+	if state.normalize {
+		for i := range batch {
+			batch[i].Vector = vectordata.NormalizeVector(batch[i].Vector)
+		}
+	}
+	switch mode {
+	case writeModeInsert:
+		for _, record := range batch {
+			if _, ok := state.records[record.ID]; ok {
+				return fmt.Errorf("%w: record %q already exists", vectordata.ErrConflict, record.ID)
+			}
+		}
+	case writeModeSkip:
+		absent := batch[:0]
+		for _, record := range batch {
+			if _, ok := state.records[record.ID]; !ok {
+				absent = append(absent, record)
+			}
+		}
+		if len(absent) == 0 {
+			return nil
+		}
+		batch = absent
+	case writeModeMergeMetadata:
+		for i, record := range batch {
+			if existing, ok := state.records[record.ID]; ok {
+				batch[i].Metadata = vectordata.MergeMetadata(existing.record.Metadata, record.Metadata)
+			}
+		}
+	}
+
+	if err := state.put(batch); err != nil {
+		return err
+	}
+	if c.maxRecords > 0 {
+		if _, err := state.evict(c.maxRecords, c.evictBy); err != nil {
+			return err
+		}
+	}
+	return state.persist()
+}
+
+// readState returns the collection state with its read lock held.
+func (c *FaissCollection) readState() (*collectionState, error) {
+	state, err := c.checkedState()
+	if err != nil {
+		return nil, err
+	}
+	state.mu.RLock()
+	if state.index == nil {
+		state.mu.RUnlock()
+		return nil, errCollectionClosed(c.name)
+	}
+	return state, nil
+}
+
+// writeState returns the collection state with its write lock held.
+func (c *FaissCollection) writeState() (*collectionState, error) {
+	state, err := c.checkedState()
+	if err != nil {
+		return nil, err
+	}
+	state.mu.Lock()
+	if state.index == nil {
+		state.mu.Unlock()
+		return nil, errCollectionClosed(c.name)
+	}
+	return state, nil
+}
+
+func (c *FaissCollection) checkedState() (*collectionState, error) {
+	state, err := c.store.state(c.name)
+	if err != nil {
+		return nil, err
+	}
+	if state.dimension != c.dimension {
+		return nil, fmt.Errorf("%w: collection %q has dimension %d, handle expects %d", vectordata.ErrDimensionMismatch, c.name, state.dimension, c.dimension)
+	}
+	if state.metric != c.metric {
+		return nil, fmt.Errorf("%w: collection %q uses metric %q, handle expects %q", vectordata.ErrSchemaMismatch, c.name, state.metric, c.metric)
+	}
+	return state, nil
+}
+
+func (c *FaissCollection) validateVectorDimension(vector []float32) error {
+	if len(vector) != c.dimension {
+		return fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, c.dimension, len(vector))
+	}
+	return nil
+}
+
+func errCollectionClosed(name string) error {
+	return fmt.Errorf("faiss: collection %q is closed", name)
+}
@@ -0,0 +1,855 @@
+package faiss
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// fakeIndex is a brute-force stand-in for a FAISS IDMap index. It reports raw
+// distances the way FAISS does: squared L2, or inner product.
+type fakeIndex struct {
+	metric   vectordata.DistanceMetric
+	vectors  map[int64][]float32
+	searches []int
+	closed   bool
+}
+
+func newFakeIndex(dimension int, metric vectordata.DistanceMetric, _ string) (annIndex, error) {
+	return &fakeIndex{metric: metric, vectors: make(map[int64][]float32)}, nil
+}
+
+func (f *fakeIndex) Add(vectors []float32, labels []int64) error {
+	dimension := len(vectors) / max(len(labels), 1)
+	for i, label := range labels {
+		f.vectors[label] = append([]float32(nil), vectors[i*dimension:(i+1)*dimension]...)
+	}
+	return nil
+}
+
+func (f *fakeIndex) Search(query []float32, k int) ([]float32, []int64, error) {
+	f.searches = append(f.searches, k)
+
+	type hit struct {
+		label int64
+		raw   float32
+	}
+	hits := make([]hit, 0, len(f.vectors))
+	for label, v := range f.vectors {
+		var raw float32
+		for i := range v {
+			if f.metric == vectordata.DistanceL2 {
+				d := v[i] - query[i]
+				raw += d * d
+			} else {
+				raw += v[i] * query[i]
+			}
+		}
+		hits = append(hits, hit{label: label, raw: raw})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if f.metric == vectordata.DistanceL2 {
+			return hits[i].raw < hits[j].raw
+		}
+		return hits[i].raw > hits[j].raw
+	})
+
+	distances := make([]float32, k)
+	labels := make([]int64, k)
+	for i := range labels {
+		labels[i] = -1
+		if i < len(hits) {
+			distances[i] = hits[i].raw
+			labels[i] = hits[i].label
+		}
+	}
+	return distances, labels, nil
+}
+
+func (f *fakeIndex) Close() {
+	f.closed = true
+}
+
+func newTestStore(t *testing.T, opts StoreOptions) *FaissVectorStore {
+	t.Helper()
+	store, err := newVectorStore(opts, newFakeIndex)
+	if err != nil {
+		t.Fatalf("newVectorStore: %v", err)
+	}
+	return store
+}
+
+func newTestCollection(t *testing.T, store *FaissVectorStore, metric vectordata.DistanceMetric) *FaissCollection {
+	t.Helper()
+	collection, err := store.EnsureCollection(context.Background(), vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    metric,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	return collection.(*FaissCollection)
+}
+
+func TestFaissCollection_CRUD(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceCosine)
+	content := "hello"
+
+	// Act
+	insertErr := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"rank": 1}, Content: &content},
+		{ID: "b", Vector: []float32{0, 1}},
+	})
+	duplicateErr := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 1}}})
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{{ID: "b", Vector: []float32{1, 1}, Metadata: map[string]any{"rank": 2}}})
+	got, getErr := collection.Get(ctx, "a")
+	deleted, deleteErr := collection.Delete(ctx, []string{"b", "missing"})
+	_, missingErr := collection.Get(ctx, "b")
+	count, countErr := collection.Count(ctx, nil)
+
+	// Assert
+	for name, err := range map[string]error{"insert": insertErr, "upsert": upsertErr, "get": getErr, "delete": deleteErr, "count": countErr} {
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if !errors.Is(duplicateErr, vectordata.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", duplicateErr)
+	}
+	if got.Content == nil || *got.Content != "hello" || got.Metadata["rank"] != float64(1) || len(got.Vector) != 2 {
+		t.Fatalf("unexpected record: %#v", got)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted record, got %d", deleted)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+	if count != 1 {
+		t.Fatalf("expected count 1, got %d", count)
+	}
+}
+
+func TestFaissCollection_SearchConvertsDistances(t *testing.T) {
+	cases := map[vectordata.DistanceMetric]struct {
+		distance float64
+		score    float64
+	}{
+		vectordata.DistanceCosine:       {distance: 1 - 4/math.Sqrt(20), score: 4 / math.Sqrt(20)},
+		vectordata.DistanceL2:           {distance: 2, score: 1.0 / 3},
+		vectordata.DistanceInnerProduct: {distance: -4, score: 4},
+	}
+
+	for metric, tc := range cases {
+		t.Run(string(metric), func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), metric)
+			if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 3}}}); err != nil {
+				t.Fatalf("Insert: %v", err)
+			}
+
+			// Act
+			results, err := collection.SearchByVector(ctx, []float32{1, 1}, 1, vectordata.SearchOptions{})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("SearchByVector: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			if math.Abs(results[0].Distance-tc.distance) > 1e-5 {
+				t.Fatalf("expected distance %v, got %v", tc.distance, results[0].Distance)
+			}
+			if math.Abs(results[0].Score-tc.score) > 1e-5 {
+				t.Fatalf("expected score %v, got %v", tc.score, results[0].Score)
+			}
+		})
+	}
+}
+
+func TestFaissCollection_SearchMovesAwayFromNegatives(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceCosine)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "shoes", Vector: []float32{1, 0.2}},
+		{ID: "boots", Vector: []float32{0.8, 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	query := []float32{1, 0.8}
+
+	// Act
+	plain, plainErr := collection.SearchByVector(ctx, query, 1, vectordata.SearchOptions{})
+	adjusted, adjustedErr := collection.SearchByVector(ctx, query, 1, vectordata.SearchOptions{
+		Negatives: []vectordata.NegativeVector{{Vector: []float32{0, 1}, Weight: 0.7}},
+	})
+
+	// Assert
+	if plainErr != nil || adjustedErr != nil {
+		t.Fatalf("SearchByVector: %v, %v", plainErr, adjustedErr)
+	}
+	if plain[0].Record.ID != "boots" || adjusted[0].Record.ID != "shoes" {
+		t.Fatalf("expected boots then shoes, got %s then %s", plain[0].Record.ID, adjusted[0].Record.ID)
+	}
+}
+
+func TestFaissCollection_SearchFusesWeightedQueries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "title", Vector: []float32{1, 0}},
+		{ID: "clicks", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	queries := []vectordata.QueryVector{{Vector: []float32{1, 0}, Weight: 0.3}, {Vector: []float32{0, 1}, Weight: 0.7}}
+
+	// Act
+	combined, combinedErr := collection.SearchByVector(ctx, nil, 2, vectordata.SearchOptions{Queries: queries})
+	fused, fusedErr := collection.SearchByVector(ctx, nil, 2, vectordata.SearchOptions{Queries: queries, QueryFusion: vectordata.QueryFusionScores})
+
+	// Assert
+	if combinedErr != nil || fusedErr != nil {
+		t.Fatalf("SearchByVector: %v, %v", combinedErr, fusedErr)
+	}
+	if len(combined) != 2 || combined[0].Record.ID != "clicks" {
+		t.Fatalf("expected clicks first, got %+v", combined)
+	}
+	if len(fused) != 2 || fused[0].Record.ID != "clicks" || fused[0].Distance != 0 {
+		t.Fatalf("expected clicks first at distance 0, got %+v", fused)
+	}
+}
+
+func TestFaissCollection_FilteredSearchWidensCandidates(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	opts := DefaultStoreOptions()
+	opts.OverFetch = 2
+	store := newTestStore(t, opts)
+	collection := newTestCollection(t, store, vectordata.DistanceL2)
+
+	records := make([]vectordata.Record, 0, 20)
+	for i := 0; i < 20; i++ {
+		kind := "near"
+		if i >= 18 {
+			kind = "far"
+		}
+		records = append(records, vectordata.Record{
+			ID:       string(rune('a' + i)),
+			Vector:   []float32{float32(i), 0},
+			Metadata: map[string]any{"kind": kind},
+		})
+	}
+	if err := collection.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{0, 0}, 2, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("kind"), "far"),
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(results) != 2 || results[0].Record.ID != "s" || results[1].Record.ID != "t" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	state, _ := store.state("docs")
+	searches := state.index.(*fakeIndex).searches
+	if len(searches) < 2 || searches[0] != 4 || searches[len(searches)-1] != 20 {
+		t.Fatalf("expected widening searches from 4 to 20, got %v", searches)
+	}
+}
+
+func TestFaissCollection_SearchThresholdAndProjection(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 1}, Metadata: map[string]any{"k": "v"}},
+		{ID: "b", Vector: []float32{0, 5}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	threshold := 2.0
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{0, 0}, 10, vectordata.SearchOptions{
+		Threshold:  &threshold,
+		Projection: &vectordata.Projection{IncludeVector: true},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(results) != 1 || results[0].Record.ID != "a" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results[0].Record.Metadata != nil || len(results[0].Record.Vector) != 2 {
+		t.Fatalf("projection not applied: %#v", results[0].Record)
+	}
+}
+
+func TestFaissCollection_SearchRedactsProjection(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	content := "contact ana@example.com"
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 1}, Metadata: map[string]any{"owner": "ana"}, Content: &content},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	projection := vectordata.DefaultProjection()
+	projection.Redact = &vectordata.Redaction{MetadataKeys: []string{"owner"}, ContentPatterns: []*regexp.Regexp{vectordata.EmailPattern}}
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{0, 0}, 1, vectordata.SearchOptions{Projection: &projection})
+	stored, getErr := collection.Get(ctx, "a")
+
+	// Assert
+	if err != nil || getErr != nil {
+		t.Fatalf("unexpected errors: %v %v", err, getErr)
+	}
+	if *results[0].Record.Content != "contact [REDACTED]" || results[0].Record.Metadata["owner"] != "[REDACTED]" {
+		t.Fatalf("redaction not applied: %#v", results[0].Record)
+	}
+	if *stored.Content != content || stored.Metadata["owner"] != "ana" {
+		t.Fatalf("expected the stored record to be unchanged, got %#v", stored)
+	}
+}
+
+func TestFaissCollection_SearchPagesWithCursor(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	// c, a, e and b are tied at distance 1, so pages must break the tie by ID.
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "c", Vector: []float32{1, 0}},
+		{ID: "a", Vector: []float32{0, 1}},
+		{ID: "e", Vector: []float32{-1, 0}},
+		{ID: "b", Vector: []float32{0, -1}},
+		{ID: "d", Vector: []float32{0, 0}},
+		{ID: "f", Vector: []float32{3, 0}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	query := []float32{0, 0}
+	opts := vectordata.SearchOptions{}
+
+	// Act
+	var ids, cursors []string
+	for range 4 {
+		page, err := collection.SearchByVector(ctx, query, 2, opts)
+		if err != nil {
+			t.Fatalf("SearchByVector: %v", err)
+		}
+		for _, result := range page {
+			ids = append(ids, result.Record.ID)
+		}
+		opts.Cursor = vectordata.NextCursor(query, opts, page)
+		cursors = append(cursors, opts.Cursor)
+	}
+	_, otherQueryErr := collection.SearchByVector(ctx, []float32{1, 1}, 2, vectordata.SearchOptions{Cursor: cursors[0]})
+
+	// Assert
+	if strings.Join(ids, ",") != "d,a,b,c,e,f" {
+		t.Fatalf("unexpected pages: %v", ids)
+	}
+	if cursors[3] != "" {
+		t.Fatalf("expected no cursor after the last page, got %q", cursors[3])
+	}
+	if !errors.Is(otherQueryErr, vectordata.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for another query, got %v", otherQueryErr)
+	}
+}
+
+func TestFaissCollection_SearchOrdersTiesByMetadata(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"published_at": "2024-01-01T00:00:00Z"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"published_at": "2024-06-01T00:00:00Z"}},
+		{ID: "c", Vector: []float32{-1, 0}},
+		{ID: "d", Vector: []float32{0, 0}, Metadata: map[string]any{"published_at": "2020-01-01T00:00:00Z"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	projection := vectordata.Projection{}
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{0, 0}, 3, vectordata.SearchOptions{
+		Projection: &projection,
+		OrderBy:    []vectordata.OrderSpec{vectordata.Desc(vectordata.Metadata("published_at"))},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Record.ID)
+		if result.Record.Metadata != nil {
+			t.Fatalf("expected the projection to leave out metadata, got %#v", result.Record)
+		}
+	}
+	if strings.Join(ids, ",") != "d,b,a" {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+}
+
+func TestFaissCollection_TombstonesTriggerRebuild(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := newTestStore(t, DefaultStoreOptions())
+	collection := newTestCollection(t, store, vectordata.DistanceL2)
+	records := make([]vectordata.Record, 0, 8)
+	for i := 0; i < 8; i++ {
+		records = append(records, vectordata.Record{ID: string(rune('a' + i)), Vector: []float32{float32(i), 0}})
+	}
+	if err := collection.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	state, _ := store.state("docs")
+	original := state.index.(*fakeIndex)
+
+	// Act
+	_, firstErr := collection.Delete(ctx, []string{"a"})
+	tombstonesAfterFirst := state.tombstones
+	_, secondErr := collection.Delete(ctx, []string{"b", "c"})
+	results, searchErr := collection.SearchByVector(ctx, []float32{0, 0}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if firstErr != nil || secondErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", firstErr, secondErr, searchErr)
+	}
+	if tombstonesAfterFirst != 1 {
+		t.Fatalf("expected 1 tombstone before rebuild, got %d", tombstonesAfterFirst)
+	}
+	if state.tombstones != 0 || !original.closed || len(state.index.(*fakeIndex).vectors) != 5 {
+		t.Fatalf("expected index rebuild with 5 live vectors")
+	}
+	if len(results) != 1 || results[0].Record.ID != "d" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+}
+
+func TestFaissCollection_Validation(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := newTestStore(t, DefaultStoreOptions())
+	collection := newTestCollection(t, store, vectordata.DistanceCosine)
+
+	// Act
+	dimensionErr := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1}}})
+	emptyIDErr := collection.Upsert(ctx, []vectordata.Record{{ID: " ", Vector: []float32{1, 0}}})
+	topKErr := func() error {
+		_, err := collection.SearchByVector(ctx, []float32{1, 0}, 0, vectordata.SearchOptions{})
+		return err
+	}()
+	filterErr := func() error {
+		_, err := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{Filter: vectordata.In(vectordata.Metadata("a"))})
+		return err
+	}()
+	ivfErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodIVFFlat}})
+	_, missingErr := store.Collection("other", 2, vectordata.DistanceCosine).Count(ctx, nil)
+	_, metricErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	_, nameErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "../x", Dimension: 2})
+
+	// Assert
+	if !errors.Is(dimensionErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", dimensionErr)
+	}
+	if emptyIDErr == nil || topKErr == nil {
+		t.Fatalf("expected validation errors, got %v and %v", emptyIDErr, topKErr)
+	}
+	if !errors.Is(filterErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", filterErr)
+	}
+	for name, err := range map[string]error{"ivfflat": ivfErr, "missing": missingErr, "metric": metricErr, "name": nameErr} {
+		if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+			t.Fatalf("%s: expected ErrSchemaMismatch, got %v", name, err)
+		}
+	}
+}
+
+func TestNewVectorStore_RequiresBuildTag(t *testing.T) {
+	if faissAvailable {
+		t.Skip("built with faiss support")
+	}
+
+	// Act
+	_, err := NewVectorStore(DefaultStoreOptions())
+
+	// Assert
+	if !errors.Is(err, errFaissUnavailable) {
+		t.Fatalf("expected errFaissUnavailable, got %v", err)
+	}
+}
+
+func TestFaissCollection_RecordLimits(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.RecordLimits = vectordata.RecordLimits{MaxIDLength: 4, RejectNonFinite: true}
+	collection := newTestCollection(t, newTestStore(t, opts), vectordata.DistanceCosine)
+	ctx := context.Background()
+
+	// Act
+	err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "ok", Vector: []float32{1, 0}},
+		{ID: "nan", Vector: []float32{float32(math.NaN()), 0}},
+	})
+	count, countErr := collection.Count(ctx, nil)
+
+	// Assert
+	var recordErr *vectordata.RecordError
+	if !errors.As(err, &recordErr) || recordErr.Index != 1 || recordErr.Field != "vector" {
+		t.Fatalf("expected record 1 vector error, got %v", err)
+	}
+	if countErr != nil || count != 0 {
+		t.Fatalf("expected nothing written, got count %d (%v)", count, countErr)
+	}
+}
+
+func TestFaissCollection_SampleIsSeededAndFiltered(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceCosine)
+	records := make([]vectordata.Record, 0, 20)
+	for i := range 20 {
+		records = append(records, vectordata.Record{
+			ID:       string(rune('a' + i)),
+			Vector:   []float32{1, float32(i)},
+			Metadata: map[string]any{"even": i%2 == 0},
+		})
+	}
+	if err := collection.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	seed := int64(7)
+
+	// Act
+	first, firstErr := collection.Sample(ctx, 5, vectordata.SampleOptions{Seed: &seed})
+	second, secondErr := collection.Sample(ctx, 5, vectordata.SampleOptions{Seed: &seed})
+	even, evenErr := collection.Sample(ctx, 50, vectordata.SampleOptions{Filter: vectordata.Eq(vectordata.Metadata("even"), true)})
+	_, sizeErr := collection.Sample(ctx, 0, vectordata.SampleOptions{})
+
+	// Assert
+	if firstErr != nil || secondErr != nil || evenErr != nil {
+		t.Fatalf("Sample: %v, %v, %v", firstErr, secondErr, evenErr)
+	}
+	if len(first) != 5 || len(second) != 5 || len(first[0].Vector) != 2 {
+		t.Fatalf("expected 5 complete records, got %v", first)
+	}
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Fatalf("expected seeded samples to repeat, got %v and %v", first, second)
+		}
+	}
+	if len(even) != 10 {
+		t.Fatalf("expected all 10 even records, got %d", len(even))
+	}
+	for _, record := range even {
+		if record.Metadata["even"] != true {
+			t.Fatalf("sample ignored filter: %v", record)
+		}
+	}
+	if sizeErr == nil {
+		t.Fatalf("expected a non-positive size to fail")
+	}
+}
+
+func TestFaissCollection_FindDuplicatesScansInBatches(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{1, 0.001}},
+		{ID: "c", Vector: []float32{0, 1}},
+		{ID: "d", Vector: []float32{5, 5}},
+		{ID: "e", Vector: []float32{5, 5}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	var groups [][]string
+
+	// Act
+	err := vectordata.FindDuplicates(ctx, collection, 0.01, vectordata.DuplicateOptions{
+		BatchSize: 2,
+		OnGroup: func(group vectordata.DuplicateGroup) error {
+			groups = append(groups, group.IDs)
+			return nil
+		},
+	})
+	page, listErr := collection.List(ctx, vectordata.ListOptions{After: "b", Limit: 2})
+
+	// Assert
+	if err != nil || listErr != nil {
+		t.Fatalf("FindDuplicates: %v, List: %v", err, listErr)
+	}
+	if len(groups) != 2 || strings.Join(groups[0], ",") != "a,b" || strings.Join(groups[1], ",") != "d,e" {
+		t.Fatalf("expected groups a,b and d,e, got %v", groups)
+	}
+	if len(page) != 2 || page[0].ID != "c" || page[1].ID != "d" {
+		t.Fatalf("expected c and d after b, got %v", page)
+	}
+}
+
+func TestFaissCollection_CentroidsAccumulateListedRecords(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceCosine)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"topic": "go"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"topic": "go"}},
+		{ID: "c", Vector: []float32{0, 3}, Metadata: map[string]any{"topic": 7}},
+		{ID: "d", Vector: []float32{1, 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	centroids, err := vectordata.Centroids(ctx, collection, vectordata.Metadata("topic"), nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Centroids: %v", err)
+	}
+	if len(centroids) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", centroids)
+	}
+	if centroids[0].Group != "go" || centroids[0].Count != 2 || math.Abs(float64(centroids[0].Vector[0])-math.Sqrt2/2) > 1e-6 {
+		t.Fatalf("expected the go group first with a unit-length mean, got %+v", centroids[0])
+	}
+	if centroids[1].Group != float64(7) || centroids[1].Count != 1 || centroids[1].Vector[1] != 1 {
+		t.Fatalf("expected group 7 with a unit vector, got %+v", centroids[1])
+	}
+}
+
+func TestFaissCollection_UpsertWithConflictStrategies(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"lang": "en", "rank": 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	skipErr := vectordata.UpsertWithOptions(ctx, collection, []vectordata.Record{
+		{ID: "a", Vector: []float32{9, 9}},
+		{ID: "b", Vector: []float32{0, 1}},
+	}, vectordata.UpsertOptions{OnConflict: vectordata.ConflictSkip})
+	skipped, _ := collection.Get(ctx, "a")
+	mergeErr := vectordata.UpsertWithOptions(ctx, collection, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 2}, Metadata: map[string]any{"rank": 2}},
+	}, vectordata.UpsertOptions{OnConflict: vectordata.ConflictMergeMetadata})
+	merged, _ := collection.Get(ctx, "a")
+	count, _ := collection.Count(ctx, nil)
+
+	// Assert
+	if skipErr != nil || mergeErr != nil {
+		t.Fatalf("UpsertWithOptions: %v, %v", skipErr, mergeErr)
+	}
+	if skipped.Vector[0] != 1 || count != 2 {
+		t.Fatalf("expected a kept and b inserted, got %v and count %d", skipped, count)
+	}
+	if merged.Vector[1] != 2 || merged.Metadata["lang"] != "en" || merged.Metadata["rank"] != float64(2) {
+		t.Fatalf("expected a new vector and merged metadata, got %+v", merged)
+	}
+}
+
+func TestFaissCollection_PatchMetadataMergesAndRemovesKeys(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{{
+		ID:       "a",
+		Vector:   []float32{1, 0},
+		Metadata: map[string]any{"lang": "en", "stats": map[string]any{"views": 1, "likes": 2}},
+	}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	err := vectordata.PatchMetadata(ctx, collection, "a", map[string]any{
+		"lang":  nil,
+		"stats": map[string]any{"views": 5, "likes": nil},
+		"tags":  []any{"new"},
+	})
+	patched, _ := collection.Get(ctx, "a")
+	missingErr := vectordata.PatchMetadata(ctx, collection, "missing", map[string]any{"x": 1})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PatchMetadata: %v", err)
+	}
+	expected := map[string]any{"stats": map[string]any{"views": float64(5)}, "tags": []any{"new"}}
+	if !reflect.DeepEqual(patched.Metadata, expected) {
+		t.Fatalf("unexpected metadata %#v", patched.Metadata)
+	}
+	if patched.Vector[0] != 1 {
+		t.Fatalf("expected the vector to be kept, got %v", patched.Vector)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestFaissCollection_UpdateMetadataArrayAppendsAbsentValues(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tags": []any{"draft", "go"}, "owner": "ana"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	err := vectordata.UpdateMetadataArray(ctx, collection, "a", vectordata.ArrayUpdate{
+		Field:  vectordata.Metadata("tags"),
+		Append: []any{"reviewed", "go"},
+		Remove: []any{"draft"},
+	})
+	updated, _ := collection.Get(ctx, "a")
+	scalarErr := vectordata.AppendToArray(ctx, collection, "a", []string{"owner"}, "x")
+	missingErr := vectordata.AppendToArray(ctx, collection, "missing", []string{"tags"}, "x")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("UpdateMetadataArray: %v", err)
+	}
+	if !reflect.DeepEqual(updated.Metadata["tags"], []any{"go", "reviewed"}) {
+		t.Fatalf("unexpected tags %#v", updated.Metadata["tags"])
+	}
+	if !errors.Is(scalarErr, vectordata.ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", scalarErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestFaissCollection_DeleteWithResultsReportsMissingIDs(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := newTestCollection(t, newTestStore(t, DefaultStoreOptions()), vectordata.DistanceL2)
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	results, err := vectordata.DeleteWithResults(ctx, collection, []string{"b", "missing"})
+	count, _ := collection.Count(ctx, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("DeleteWithResults: %v", err)
+	}
+	expected := []vectordata.DeleteResult{
+		{ID: "b", Status: vectordata.DeleteStatusDeleted},
+		{ID: "missing", Status: vectordata.DeleteStatusNotFound},
+	}
+	if !reflect.DeepEqual(results, expected) || count != 1 {
+		t.Fatalf("unexpected results %v with %d records left", results, count)
+	}
+}
+
+func TestFaissCollection_MaxRecordsEvictsOldestOnWrite(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := newTestStore(t, DefaultStoreOptions())
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "cache", Dimension: 2, MaxRecords: 2, EvictBy: "used_at"})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	_, invalidErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "cache", Dimension: 2, EvictBy: "used_at"})
+
+	// Act
+	insertErr := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"used_at": "2026-01-03T00:00:00Z"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"used_at": "2026-01-01T00:00:00Z"}},
+		{ID: "c", Vector: []float32{1, 1}, Metadata: map[string]any{"used_at": "2026-01-02T00:00:00Z"}},
+	})
+	_, evictedErr := collection.Get(ctx, "b")
+	unbounded := store.Collection("cache", 2, vectordata.DistanceCosine)
+	upsertErr := unbounded.Upsert(ctx, []vectordata.Record{{ID: "d", Vector: []float32{1, 0}}})
+	evicted, evictErr := vectordata.Evict(ctx, collection)
+	count, _ := collection.Count(ctx, nil)
+	_, missingErr := collection.Get(ctx, "d")
+
+	// Assert
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected EvictBy without MaxRecords to be rejected, got %v", invalidErr)
+	}
+	if insertErr != nil || upsertErr != nil || evictErr != nil {
+		t.Fatalf("write: %v, %v, %v", insertErr, upsertErr, evictErr)
+	}
+	if !errors.Is(evictedErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected the oldest record evicted on write, got %v", evictedErr)
+	}
+	if evicted != 1 || count != 2 || !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected Evict to drop d, which has no timestamp, got %d evicted, %d left, %v", evicted, count, missingErr)
+	}
+}
+
+func TestFaissCollection_ReindexRebuildsUnchangedIndex(t *testing.T) {
+	// Arrange
+	store := newTestStore(t, DefaultStoreOptions())
+	collection := newTestCollection(t, store, vectordata.DistanceL2)
+	ctx := context.Background()
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}, {ID: "b", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	opts := vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 16}}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &opts}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	state, _ := store.state("docs")
+	before := state.index
+
+	// Act
+	ensureErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &opts})
+	ensured := state.index
+	reindexErr := vectordata.Reindex(ctx, collection, opts)
+	results, searchErr := collection.SearchByVector(ctx, []float32{0, 1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if ensureErr != nil || reindexErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", ensureErr, reindexErr, searchErr)
+	}
+	if ensured != before {
+		t.Fatal("expected EnsureIndexes to keep an unchanged index")
+	}
+	if state.index == before || state.description != "HNSW16" {
+		t.Fatalf("expected Reindex to rebuild HNSW16, got %q", state.description)
+	}
+	if len(results) != 1 || results[0].Record.ID != "b" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+}
@@ -0,0 +1,16 @@
+package faiss
+
+import (
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/gabisonia/go-vectorstore/vectordata/vectordatatest"
+)
+
+func TestConformance(t *testing.T) {
+	vectordatatest.Run(t, vectordatatest.Options{
+		NewStore: func(t *testing.T) vectordata.VectorStore {
+			return newTestStore(t, DefaultStoreOptions())
+		},
+	})
+}
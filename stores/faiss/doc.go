@@ -0,0 +1,13 @@
+// Package faiss provides an in-process vectordata implementation backed by a
+// FAISS index.
+//
+// The FAISS bindings use cgo and the faiss_c library, so they are only
+// compiled with the faiss build tag:
+//
+//	go build -tags faiss ./...
+//
+// Without the tag the package still builds, but NewVectorStore returns an
+// error. Records live in memory next to the index and, when
+// StoreOptions.Dir is set, are persisted to one snapshot file per collection.
+// Metadata filters are evaluated in Go with vectordata.MatchFilter.
+package faiss
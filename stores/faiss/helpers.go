@@ -0,0 +1,152 @@
+package faiss
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const snapshotExtension = ".jsonl"
+
+func defaultMetric(metric vectordata.DistanceMetric) vectordata.DistanceMetric {
+	if metric == "" {
+		return vectordata.DistanceCosine
+	}
+	return metric
+}
+
+func defaultMode(mode vectordata.EnsureMode, strictByDefault bool) vectordata.EnsureMode {
+	if mode != "" {
+		return mode
+	}
+	if strictByDefault {
+		return vectordata.EnsureStrict
+	}
+	return vectordata.EnsureAutoMigrate
+}
+
+// validateCollectionName rejects names that cannot be used as a snapshot file name.
+func validateCollectionName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, 0) {
+		return fmt.Errorf("%w: invalid collection name %q", vectordata.ErrSchemaMismatch, name)
+	}
+	return nil
+}
+
+func (s *FaissVectorStore) snapshotPath(name string) string {
+	if s.opts.Dir == "" {
+		return ""
+	}
+	return filepath.Join(s.opts.Dir, name+snapshotExtension)
+}
+
+// indexDescription maps vector index options onto an index_factory description.
+func indexDescription(opts *vectordata.VectorIndexOptions) (string, error) {
+	method := vectordata.IndexMethodHNSW
+	if opts.Method != "" {
+		method = opts.Method
+	}
+	// IVF indexes need a training pass over representative data, which the
+	// store does not perform.
+	if method != vectordata.IndexMethodHNSW {
+		return "", fmt.Errorf("%w: unsupported index method %q", vectordata.ErrSchemaMismatch, method)
+	}
+
+	m := opts.HNSW.M
+	if m <= 0 {
+		m = 32
+	}
+	return fmt.Sprintf("HNSW%d", m), nil
+}
+
+// cloneRecord detaches a record from caller-owned memory. Metadata is
+// round-tripped through JSON so filters see the same value types as the
+// persisted snapshot.
+func cloneRecord(record vectordata.Record) (vectordata.Record, error) {
+	out := vectordata.Record{
+		ID:     record.ID,
+		Vector: append([]float32(nil), record.Vector...),
+	}
+	if record.Content != nil {
+		content := *record.Content
+		out.Content = &content
+	}
+	metadata, err := normalizeMetadata(record.Metadata)
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	out.Metadata = metadata
+	return out, nil
+}
+
+func normalizeMetadata(metadata map[string]any) (map[string]any, error) {
+	if metadata == nil {
+		return map[string]any{}, nil
+	}
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// projectRecord copies the fields selected by a projection and applies its
+// redaction.
+func projectRecord(record vectordata.Record, projection vectordata.Projection) vectordata.Record {
+	out := vectordata.Record{ID: record.ID}
+	if projection.IncludeVector {
+		out.Vector = append([]float32(nil), record.Vector...)
+	}
+	if projection.IncludeMetadata {
+		out.Metadata = copyMetadata(record.Metadata)
+	}
+	if projection.IncludeContent && record.Content != nil {
+		content := *record.Content
+		out.Content = &content
+	}
+	return projection.Redact.Record(out)
+}
+
+// copyMetadata returns a deep copy of JSON-shaped metadata.
+func copyMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	out := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		out[key] = copyJSONValue(value)
+	}
+	return out
+}
+
+func copyJSONValue(v any) any {
+	switch typed := v.(type) {
+	case map[string]any:
+		return copyMetadata(typed)
+	case []any:
+		out := make([]any, len(typed))
+		for i, item := range typed {
+			out[i] = copyJSONValue(item)
+		}
+		return out
+	default:
+		return typed
+	}
+}
+
+func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
+	if projection == nil {
+		return vectordata.DefaultProjection()
+	}
+	return *projection
+}
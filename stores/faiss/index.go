@@ -0,0 +1,46 @@
+package faiss
+
+import (
+	"math"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/gabisonia/go-vectorstore/vectordata/vectormath"
+)
+
+// annIndex is the subset of a FAISS index used by the store. Labels are
+// assigned by the store; removed labels are tombstoned in Go and dropped when
+// the index is rebuilt.
+type annIndex interface {
+	Add(vectors []float32, labels []int64) error
+	// Search returns up to k raw FAISS distances and labels. Missing results
+	// are reported with label -1.
+	Search(query []float32, k int) ([]float32, []int64, error)
+	Close()
+}
+
+// indexBuilder creates an empty index for the given dimension and metric.
+type indexBuilder func(dimension int, metric vectordata.DistanceMetric, description string) (annIndex, error)
+
+// prepareVector returns the vector as stored in the index. Cosine collections
+// index unit vectors so that inner product equals cosine similarity.
+func prepareVector(metric vectordata.DistanceMetric, v []float32) []float32 {
+	if metric != vectordata.DistanceCosine {
+		return v
+	}
+	return vectormath.Normalize(v)
+}
+
+// normalizeDistance converts a raw FAISS distance into the pgvector-compatible
+// distance used by vectordata.ScoreFromDistance. FAISS reports squared L2
+// distances and inner-product similarities.
+func normalizeDistance(metric vectordata.DistanceMetric, raw float32) float64 {
+	d := float64(raw)
+	switch metric {
+	case vectordata.DistanceL2:
+		return math.Sqrt(math.Max(d, 0))
+	case vectordata.DistanceInnerProduct:
+		return -d
+	default:
+		return 1 - d
+	}
+}
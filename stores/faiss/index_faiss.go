@@ -0,0 +1,101 @@
+//go:build faiss
+
+package faiss
+
+/*
+#cgo LDFLAGS: -lfaiss_c
+#include <stdlib.h>
+#include <faiss/c_api/Index_c.h>
+#include <faiss/c_api/index_factory_c.h>
+#include <faiss/c_api/error_c.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const faissAvailable = true
+
+var errFaissUnavailable error
+
+type cIndex struct {
+	ptr       *C.FaissIndex
+	dimension int
+}
+
+// newFaissIndex builds an index with index_factory. The description is wrapped
+// in IDMap2 so the store controls labels for any underlying index type.
+func newFaissIndex(dimension int, metric vectordata.DistanceMetric, description string) (annIndex, error) {
+	var metricType C.FaissMetricType = C.METRIC_L2
+	if metric != vectordata.DistanceL2 {
+		metricType = C.METRIC_INNER_PRODUCT
+	}
+
+	desc := C.CString("IDMap2," + description)
+	defer C.free(unsafe.Pointer(desc))
+
+	var ptr *C.FaissIndex
+	if code := C.faiss_index_factory(&ptr, C.int(dimension), desc, metricType); code != 0 {
+		return nil, lastError("index_factory")
+	}
+	return &cIndex{ptr: ptr, dimension: dimension}, nil
+}
+
+func (i *cIndex) Add(vectors []float32, labels []int64) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	if len(vectors) != len(labels)*i.dimension {
+		return fmt.Errorf("faiss: add: %d values for %d labels of dimension %d", len(vectors), len(labels), i.dimension)
+	}
+	code := C.faiss_Index_add_with_ids(
+		i.ptr,
+		C.idx_t(len(labels)),
+		(*C.float)(unsafe.Pointer(&vectors[0])),
+		(*C.idx_t)(unsafe.Pointer(&labels[0])),
+	)
+	if code != 0 {
+		return lastError("add_with_ids")
+	}
+	return nil
+}
+
+func (i *cIndex) Search(query []float32, k int) ([]float32, []int64, error) {
+	if k <= 0 {
+		return nil, nil, nil
+	}
+	distances := make([]float32, k)
+	labels := make([]int64, k)
+	code := C.faiss_Index_search(
+		i.ptr,
+		1,
+		(*C.float)(unsafe.Pointer(&query[0])),
+		C.idx_t(k),
+		(*C.float)(unsafe.Pointer(&distances[0])),
+		(*C.idx_t)(unsafe.Pointer(&labels[0])),
+	)
+	if code != 0 {
+		return nil, nil, lastError("search")
+	}
+	return distances, labels, nil
+}
+
+func (i *cIndex) Close() {
+	if i.ptr != nil {
+		C.faiss_Index_free(i.ptr)
+		i.ptr = nil
+	}
+}
+
+func lastError(op string) error {
+	msg := C.faiss_get_last_error()
+	if msg == nil {
+		return errors.New("faiss: " + op + " failed")
+	}
+	return fmt.Errorf("faiss: %s: %s", op, C.GoString(msg))
+}
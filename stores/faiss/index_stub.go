@@ -0,0 +1,17 @@
+//go:build !faiss
+
+package faiss
+
+import (
+	"errors"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const faissAvailable = false
+
+var errFaissUnavailable = errors.New("faiss: support not compiled in, build with -tags faiss")
+
+func newFaissIndex(int, vectordata.DistanceMetric, string) (annIndex, error) {
+	return nil, errFaissUnavailable
+}
@@ -0,0 +1,64 @@
+package faiss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var (
+	_ vectordata.MetadataPatcher      = (*FaissCollection)(nil)
+	_ vectordata.MetadataArrayUpdater = (*FaissCollection)(nil)
+)
+
+// PatchMetadata applies patch to the metadata of record id as a JSON merge
+// patch under the collection's write lock. The vector is left in the index
+// as is.
+func (c *FaissCollection) PatchMetadata(_ context.Context, id string, patch map[string]any) error {
+	state, err := c.writeState()
+	if err != nil {
+		return err
+	}
+	defer state.mu.Unlock()
+
+	return c.replaceMetadata(state, id, func(metadata map[string]any) (map[string]any, error) {
+		return vectordata.MergePatch(metadata, patch), nil
+	})
+}
+
+// UpdateMetadataArray applies update under the collection's write lock.
+func (c *FaissCollection) UpdateMetadataArray(_ context.Context, id string, update vectordata.ArrayUpdate) error {
+	state, err := c.writeState()
+	if err != nil {
+		return err
+	}
+	defer state.mu.Unlock()
+
+	return c.replaceMetadata(state, id, func(metadata map[string]any) (map[string]any, error) {
+		return vectordata.ApplyArrayUpdate(metadata, update)
+	})
+}
+
+// replaceMetadata swaps the metadata of record id for what update derives
+// from it and persists the collection. The write lock must be held.
+func (c *FaissCollection) replaceMetadata(state *collectionState, id string, update func(map[string]any) (map[string]any, error)) error {
+	stored, ok := state.records[id]
+	if !ok {
+		return fmt.Errorf("%w: record %q", vectordata.ErrNotFound, id)
+	}
+	metadata, err := update(stored.record.Metadata)
+	if err != nil {
+		return fmt.Errorf("record %q: %w", id, err)
+	}
+	patched := stored.record
+	patched.Metadata, err = normalizeMetadata(metadata)
+	if err != nil {
+		return fmt.Errorf("encode metadata for record %q: %w", id, err)
+	}
+	if err := c.store.opts.RecordLimits.Check([]vectordata.Record{patched}); err != nil {
+		return err
+	}
+	stored.record = patched
+	return state.persist()
+}
@@ -0,0 +1,156 @@
+package faiss
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// snapshotHeader is the first line of a collection snapshot file.
+type snapshotHeader struct {
+	Name        string                    `json:"name"`
+	Dimension   int                       `json:"dimension"`
+	Metric      vectordata.DistanceMetric `json:"metric"`
+	Description string                    `json:"index"`
+	// NormalizeVectors is omitted for collections that keep raw vectors.
+	NormalizeVectors bool `json:"normalize_vectors,omitempty"`
+}
+
+// snapshotRecord is one record line of a collection snapshot file.
+type snapshotRecord struct {
+	ID       string         `json:"id"`
+	Vector   []float32      `json:"vector"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Content  *string        `json:"content,omitempty"`
+}
+
+// persist writes the collection to its snapshot file. The file is written to
+// a temporary name and renamed so readers never observe a partial snapshot.
+// The caller holds mu.
+func (s *collectionState) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.writeSnapshot(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("rename snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *collectionState) writeSnapshot(f *os.File) error {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	if err := enc.Encode(snapshotHeader{
+		Name:             s.name,
+		Dimension:        s.dimension,
+		Metric:           s.metric,
+		Description:      s.description,
+		NormalizeVectors: s.normalize,
+	}); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(s.records))
+	for id := range s.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		record := s.records[id].record
+		if err := enc.Encode(snapshotRecord{
+			ID:       record.ID,
+			Vector:   record.Vector,
+			Metadata: record.Metadata,
+			Content:  record.Content,
+		}); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// loadSnapshot reads a snapshot file and rebuilds its index. It returns a nil
+// state when the file does not exist.
+func loadSnapshot(path string, builder indexBuilder) (*collectionState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("decode snapshot header %s: %w", path, err)
+	}
+	if header.Dimension <= 0 {
+		return nil, fmt.Errorf("%w: snapshot %s has invalid dimension %d", vectordata.ErrSchemaMismatch, path, header.Dimension)
+	}
+	if err := header.Metric.Validate(); err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]*storedRecord)
+	for dec.More() {
+		var line snapshotRecord
+		if err := dec.Decode(&line); err != nil {
+			return nil, fmt.Errorf("decode snapshot record %s: %w", path, err)
+		}
+		if len(line.Vector) != header.Dimension {
+			return nil, fmt.Errorf("%w: snapshot record %q: expected %d, got %d", vectordata.ErrDimensionMismatch, line.ID, header.Dimension, len(line.Vector))
+		}
+		metadata := line.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		records[line.ID] = &storedRecord{record: vectordata.Record{
+			ID:       line.ID,
+			Vector:   line.Vector,
+			Metadata: metadata,
+			Content:  line.Content,
+		}}
+	}
+
+	state, err := newCollectionState(header.Name, header.Dimension, header.Metric, header.Description, builder)
+	if err != nil {
+		return nil, err
+	}
+	state.path = path
+	state.normalize = header.NormalizeVectors
+	state.records = records
+	if err := state.rebuild(header.Description); err != nil {
+		state.index.Close()
+		return nil, err
+	}
+	return state, nil
+}
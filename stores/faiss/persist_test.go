@@ -0,0 +1,135 @@
+package faiss
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestFaissVectorStore_PersistsSnapshots(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	opts := DefaultStoreOptions()
+	opts.Dir = dir
+	content := "body"
+
+	store := newTestStore(t, opts)
+	collection := newTestCollection(t, store, vectordata.DistanceCosine)
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "x"}, Content: &content},
+		{ID: "b", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 16}}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Act
+	reopened := newTestStore(t, opts)
+	handle := reopened.Collection("docs", 2, vectordata.DistanceCosine)
+	got, getErr := handle.Get(ctx, "a")
+	results, searchErr := handle.SearchByVector(ctx, []float32{0, 1}, 1, vectordata.SearchOptions{})
+	state, _ := reopened.state("docs")
+
+	// Assert
+	if getErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v", getErr, searchErr)
+	}
+	if got.Content == nil || *got.Content != "body" || got.Metadata["kind"] != "x" {
+		t.Fatalf("unexpected record: %#v", got)
+	}
+	if len(results) != 1 || results[0].Record.ID != "b" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if state.description != "HNSW16" {
+		t.Fatalf("expected persisted index description HNSW16, got %q", state.description)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "docs"+snapshotExtension {
+		t.Fatalf("expected a single snapshot file, got %v", entries)
+	}
+}
+
+func TestFaissVectorStore_EnsureCollectionValidatesSnapshot(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	opts := DefaultStoreOptions()
+	opts.Dir = dir
+	header := `{"name":"docs","dimension":3,"metric":"l2","index":"Flat"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "docs"+snapshotExtension), []byte(header), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	store := newTestStore(t, opts)
+
+	// Act
+	_, mismatchErr := store.EnsureCollection(context.Background(), vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	collection, okErr := store.EnsureCollection(context.Background(), vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceL2})
+
+	// Assert
+	if mismatchErr == nil {
+		t.Fatalf("expected dimension mismatch")
+	}
+	if okErr != nil {
+		t.Fatalf("EnsureCollection: %v", okErr)
+	}
+	if collection.Dimension() != 3 {
+		t.Fatalf("unexpected dimension %d", collection.Dimension())
+	}
+}
+
+func TestFaissVectorStore_NormalizeVectorsPersistsAndRejectsMixedUsage(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	opts := DefaultStoreOptions()
+	opts.Dir = t.TempDir()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2, NormalizeVectors: true}
+
+	store := newTestStore(t, opts)
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{3, 4}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Act
+	reopened := newTestStore(t, opts)
+	_, mixedErr := reopened.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	handle, ensureErr := reopened.EnsureCollection(ctx, spec)
+	if ensureErr != nil {
+		t.Fatalf("EnsureCollection: %v", ensureErr)
+	}
+	got, getErr := handle.Get(ctx, "a")
+	results, searchErr := handle.SearchByVector(ctx, []float32{6, 8}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if !errors.Is(mixedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a spec without NormalizeVectors, got %v", mixedErr)
+	}
+	if getErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v", getErr, searchErr)
+	}
+	if math.Abs(float64(got.Vector[0])-0.6) > 1e-6 || math.Abs(float64(got.Vector[1])-0.8) > 1e-6 {
+		t.Fatalf("expected stored unit vector, got %v", got.Vector)
+	}
+	if len(results) != 1 || results[0].Distance > 1e-6 {
+		t.Fatalf("expected the query to be normalized to an exact match, got %#v", results)
+	}
+}
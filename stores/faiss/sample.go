@@ -0,0 +1,58 @@
+package faiss
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.Sampler = (*FaissCollection)(nil)
+
+// Sample shuffles the records matching opts.Filter and returns the first n.
+// Records are ordered by ID before shuffling so a seed always yields the same
+// sample for the same contents.
+func (c *FaissCollection) Sample(_ context.Context, n int, opts vectordata.SampleOptions) ([]vectordata.Record, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be > 0")
+	}
+
+	state, err := c.readState()
+	if err != nil {
+		return nil, err
+	}
+	defer state.mu.RUnlock()
+
+	matched := make([]vectordata.Record, 0, len(state.records))
+	for _, stored := range state.records {
+		if opts.Filter != nil {
+			ok, err := vectordata.MatchFilter(opts.Filter, stored.record)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		matched = append(matched, stored.record)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	random := rand.Shuffle
+	if opts.Seed != nil {
+		random = rand.New(rand.NewPCG(uint64(*opts.Seed), 0)).Shuffle
+	}
+	random(len(matched), func(i, j int) { matched[i], matched[j] = matched[j], matched[i] })
+
+	out := make([]vectordata.Record, 0, min(n, len(matched)))
+	for _, record := range matched[:min(n, len(matched))] {
+		out = append(out, projectRecord(record, vectordata.Projection{
+			IncludeVector:   true,
+			IncludeMetadata: true,
+			IncludeContent:  true,
+		}))
+	}
+	return out, nil
+}
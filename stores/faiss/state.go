@@ -0,0 +1,179 @@
+package faiss
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// rebuildRatio triggers an index rebuild once tombstoned labels exceed this
+// fraction of live records. FAISS HNSW indexes cannot remove vectors, so
+// replaced and deleted records stay in the index until it is rebuilt.
+const rebuildRatio = 0.25
+
+type storedRecord struct {
+	label  int64
+	record vectordata.Record
+}
+
+// collectionState holds the records and FAISS index of one collection.
+type collectionState struct {
+	mu sync.RWMutex
+
+	name        string
+	dimension   int
+	metric      vectordata.DistanceMetric
+	description string
+	// normalize scales vectors to unit length on write and query.
+	normalize bool
+	path      string
+	newIndex  indexBuilder
+
+	index      annIndex
+	records    map[string]*storedRecord
+	labels     map[int64]string
+	nextLabel  int64
+	tombstones int
+}
+
+func newCollectionState(name string, dimension int, metric vectordata.DistanceMetric, description string, builder indexBuilder) (*collectionState, error) {
+	index, err := builder(dimension, metric, description)
+	if err != nil {
+		return nil, err
+	}
+	return &collectionState{
+		name:        name,
+		dimension:   dimension,
+		metric:      metric,
+		description: description,
+		newIndex:    builder,
+		index:       index,
+		records:     make(map[string]*storedRecord),
+		labels:      make(map[int64]string),
+	}, nil
+}
+
+// put adds records to the index and replaces any existing records with the
+// same ID. The caller holds mu and has validated the batch.
+func (s *collectionState) put(records []vectordata.Record) error {
+	labels := make([]int64, len(records))
+	vectors := make([]float32, 0, len(records)*s.dimension)
+	for i, record := range records {
+		labels[i] = s.nextLabel + int64(i)
+		vectors = append(vectors, prepareVector(s.metric, record.Vector)...)
+	}
+	if err := s.index.Add(vectors, labels); err != nil {
+		return fmt.Errorf("add vectors: %w", err)
+	}
+	s.nextLabel += int64(len(records))
+
+	for i, record := range records {
+		if existing, ok := s.records[record.ID]; ok {
+			delete(s.labels, existing.label)
+			s.tombstones++
+		}
+		s.records[record.ID] = &storedRecord{label: labels[i], record: record}
+		s.labels[labels[i]] = record.ID
+	}
+	return s.maybeRebuild()
+}
+
+// remove deletes records by ID and reports how many existed.
+// remove deletes ids and returns the ones that existed.
+func (s *collectionState) remove(ids []string) ([]string, error) {
+	var deleted []string
+	for _, id := range ids {
+		existing, ok := s.records[id]
+		if !ok {
+			continue
+		}
+		delete(s.records, id)
+		delete(s.labels, existing.label)
+		s.tombstones++
+		deleted = append(deleted, id)
+	}
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+	return deleted, s.maybeRebuild()
+}
+
+// evict removes the records past maxRecords in vectordata.SortForEviction
+// order and returns their IDs. The caller holds mu.
+func (s *collectionState) evict(maxRecords int64, evictBy string) ([]string, error) {
+	excess := int64(len(s.records)) - maxRecords
+	if excess <= 0 {
+		return nil, nil
+	}
+	records := make([]vectordata.Record, 0, len(s.records))
+	for _, stored := range s.records {
+		records = append(records, stored.record)
+	}
+	vectordata.SortForEviction(records, evictBy)
+	ids := make([]string, excess)
+	for i := range ids {
+		ids[i] = records[i].ID
+	}
+	return s.remove(ids)
+}
+
+func (s *collectionState) maybeRebuild() error {
+	if s.tombstones == 0 || float64(s.tombstones) <= rebuildRatio*float64(len(s.records)) {
+		return nil
+	}
+	return s.rebuild(s.description)
+}
+
+// rebuild replaces the index with a fresh one holding only live records.
+// Labels are reassigned in ID order so rebuilt indexes are deterministic.
+func (s *collectionState) rebuild(description string) error {
+	index, err := s.newIndex(s.dimension, s.metric, description)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(s.records))
+	for id := range s.records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	labels := make([]int64, len(ids))
+	vectors := make([]float32, 0, len(ids)*s.dimension)
+	for i, id := range ids {
+		labels[i] = int64(i)
+		vectors = append(vectors, prepareVector(s.metric, s.records[id].record.Vector)...)
+	}
+	if err := index.Add(vectors, labels); err != nil {
+		index.Close()
+		return fmt.Errorf("rebuild index: %w", err)
+	}
+
+	s.index.Close()
+	s.index = index
+	s.description = description
+	s.labels = make(map[int64]string, len(ids))
+	for i, id := range ids {
+		s.records[id].label = labels[i]
+		s.labels[labels[i]] = id
+	}
+	s.nextLabel = int64(len(ids))
+	s.tombstones = 0
+	return nil
+}
+
+// indexSize is the number of vectors held by the index, including tombstones.
+func (s *collectionState) indexSize() int {
+	return len(s.records) + s.tombstones
+}
+
+func (s *collectionState) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index != nil {
+		s.index.Close()
+		s.index = nil
+	}
+}
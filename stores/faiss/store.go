@@ -0,0 +1,231 @@
+package faiss
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// StoreOptions configures FaissVectorStore behavior.
+type StoreOptions struct {
+	// Dir holds one snapshot file per collection. When empty, collections
+	// live in memory only.
+	Dir string
+	// IndexDescription is the FAISS index_factory description used for new
+	// collections, e.g. "HNSW32" or "Flat".
+	IndexDescription string
+	// OverFetch multiplies topK for filtered searches, since metadata filters
+	// are applied after the ANN lookup. Searches widen until topK matches are
+	// found or the index is exhausted.
+	OverFetch       int
+	StrictByDefault bool
+	// RecordLimits validates records in Insert and Upsert before they are
+	// indexed.
+	RecordLimits vectordata.RecordLimits
+}
+
+// DefaultStoreOptions returns production-safe defaults.
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		IndexDescription: "HNSW32",
+		OverFetch:        4,
+		StrictByDefault:  true,
+	}
+}
+
+// FaissVectorStore implements vectordata.VectorStore with in-process FAISS indexes.
+type FaissVectorStore struct {
+	opts     StoreOptions
+	newIndex indexBuilder
+
+	mu          sync.Mutex
+	collections map[string]*collectionState
+}
+
+// NewVectorStore creates a FAISS-backed vector store. It fails unless the
+// package was built with the faiss build tag.
+func NewVectorStore(opts StoreOptions) (*FaissVectorStore, error) {
+	if !faissAvailable {
+		return nil, errFaissUnavailable
+	}
+	return newVectorStore(opts, newFaissIndex)
+}
+
+func newVectorStore(opts StoreOptions, builder indexBuilder) (*FaissVectorStore, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &FaissVectorStore{
+		opts:        opts,
+		newIndex:    builder,
+		collections: make(map[string]*collectionState),
+	}, nil
+}
+
+// Collection returns a handle to a collection without schema checks.
+// Operations fail until the collection has been created with EnsureCollection.
+func (s *FaissVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.newCollectionHandle(strings.TrimSpace(name), dimension, metric)
+}
+
+// EnsureCollection creates or validates a collection and returns its handle.
+// Existing collections are loaded from StoreOptions.Dir on first use.
+func (s *FaissVectorStore) EnsureCollection(_ context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	normalizedSpec, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.lookupLocked(normalizedSpec.Name)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state, err = s.createLocked(normalizedSpec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if state.dimension != normalizedSpec.Dimension {
+		return nil, fmt.Errorf("%w: collection %q has dimension %d, expected %d", vectordata.ErrSchemaMismatch, normalizedSpec.Name, state.dimension, normalizedSpec.Dimension)
+	}
+	if state.metric != normalizedSpec.Metric {
+		return nil, fmt.Errorf("%w: collection %q uses metric %q, expected %q", vectordata.ErrSchemaMismatch, normalizedSpec.Name, state.metric, normalizedSpec.Metric)
+	}
+	if state.normalize != normalizedSpec.NormalizeVectors {
+		return nil, fmt.Errorf("%w: collection %q has NormalizeVectors %t, expected %t", vectordata.ErrSchemaMismatch, normalizedSpec.Name, state.normalize, normalizedSpec.NormalizeVectors)
+	}
+
+	collection := s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric).(*FaissCollection)
+	collection.maxRecords = normalizedSpec.MaxRecords
+	collection.evictBy = normalizedSpec.EvictBy
+	return collection, nil
+}
+
+// Close releases all FAISS indexes. Collections are reloaded from
+// StoreOptions.Dir if the store is used again.
+func (s *FaissVectorStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, state := range s.collections {
+		state.close()
+		delete(s.collections, name)
+	}
+	return nil
+}
+
+func (s *FaissVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if err := validateCollectionName(spec.Name); err != nil {
+		return vectordata.CollectionSpec{}, err
+	}
+	if spec.Dimension <= 0 {
+		return vectordata.CollectionSpec{}, fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	spec.Metric = defaultMetric(spec.Metric)
+	if err := spec.Metric.Validate(); err != nil {
+		return vectordata.CollectionSpec{}, err
+	}
+
+	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+		return vectordata.CollectionSpec{}, fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
+	}
+	if spec.ElementType != "" && spec.ElementType != vectordata.ElementFloat32 {
+		return vectordata.CollectionSpec{}, fmt.Errorf("%w: vector element type %q is not supported", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension != 0 {
+		return vectordata.CollectionSpec{}, fmt.Errorf("%w: FastDimension is not supported", vectordata.ErrSchemaMismatch)
+	}
+	spec, err := vectordata.ValidateEviction(spec)
+	if err != nil {
+		return vectordata.CollectionSpec{}, err
+	}
+	spec.Mode = mode
+	return spec, nil
+}
+
+// state resolves a collection for an operation, loading it from disk if needed.
+func (s *FaissVectorStore) state(name string) (*collectionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.lookupLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%w: collection %q does not exist", vectordata.ErrSchemaMismatch, name)
+	}
+	return state, nil
+}
+
+func (s *FaissVectorStore) lookupLocked(name string) (*collectionState, error) {
+	if state, ok := s.collections[name]; ok {
+		return state, nil
+	}
+	if s.opts.Dir == "" {
+		return nil, nil
+	}
+	if err := validateCollectionName(name); err != nil {
+		return nil, err
+	}
+
+	state, err := loadSnapshot(s.snapshotPath(name), s.newIndex)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	s.collections[name] = state
+	return state, nil
+}
+
+func (s *FaissVectorStore) createLocked(spec vectordata.CollectionSpec) (*collectionState, error) {
+	state, err := newCollectionState(spec.Name, spec.Dimension, spec.Metric, s.opts.IndexDescription, s.newIndex)
+	if err != nil {
+		return nil, err
+	}
+	state.path = s.snapshotPath(spec.Name)
+	state.normalize = spec.NormalizeVectors
+	if err := state.persist(); err != nil {
+		state.close()
+		return nil, err
+	}
+	s.collections[spec.Name] = state
+	return state, nil
+}
+
+func (s *FaissVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return &FaissCollection{
+		store:     s,
+		name:      name,
+		dimension: dimension,
+		metric:    defaultMetric(metric),
+	}
+}
+
+func (o StoreOptions) withDefaults() StoreOptions {
+	defaults := DefaultStoreOptions()
+	if strings.TrimSpace(o.IndexDescription) == "" {
+		o.IndexDescription = defaults.IndexDescription
+	}
+	if o.OverFetch == 0 {
+		o.OverFetch = defaults.OverFetch
+	}
+	return o
+}
+
+func (o StoreOptions) validate() error {
+	if o.OverFetch < 1 {
+		return fmt.Errorf("%w: OverFetch must be >= 1", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
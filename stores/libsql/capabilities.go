@@ -0,0 +1,32 @@
+package libsql
+
+import "github.com/gabisonia/go-vectorstore/vectordata"
+
+// capabilities describes the backend for the store and its collections.
+func capabilities() vectordata.Capabilities {
+	return vectordata.Capabilities{
+		Metrics:         []vectordata.DistanceMetric{vectordata.DistanceCosine, vectordata.DistanceL2},
+		IndexManagement: true,
+		IndexMethods:    []vectordata.IndexMethod{vectordata.IndexMethodHNSW},
+	}
+}
+
+// Capabilities reports the features of the backend.
+func (s *LibSQLVectorStore) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// Capabilities reports the features of the backend.
+func (c *LibSQLCollection) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// SupportsFilter returns the error a query with filter would fail with, if
+// any.
+func (c *LibSQLCollection) SupportsFilter(filter vectordata.Filter) error {
+	if filter == nil {
+		return nil
+	}
+	_, _, err := compileFilterSQL(filter)
+	return err
+}
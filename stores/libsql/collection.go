@@ -0,0 +1,618 @@
+package libsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const maxRowsPerStatement = 500
+
+type writeMode int
+
+const (
+	writeModeInsert writeMode = iota
+	writeModeUpsert
+)
+
+type searchPlan struct {
+	query      string
+	args       []any
+	projection vectordata.Projection
+}
+
+// LibSQLCollection is a libSQL-backed vector collection.
+type LibSQLCollection struct {
+	store     *LibSQLVectorStore
+	name      string
+	dimension int
+	metric    vectordata.DistanceMetric
+	// vectorIndex names the libsql_vector_idx index used for unfiltered searches.
+	vectorIndex atomic.Pointer[string]
+}
+
+func (c *LibSQLCollection) Name() string {
+	return c.name
+}
+
+func (c *LibSQLCollection) Dimension() int {
+	return c.dimension
+}
+
+func (c *LibSQLCollection) Metric() vectordata.DistanceMetric {
+	return c.metric
+}
+
+func (c *LibSQLCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeInsert)
+}
+
+func (c *LibSQLCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeUpsert)
+}
+
+func (c *LibSQLCollection) Get(ctx context.Context, id string) (vectordata.Record, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, vector_extract(%s), %s, %s
+		FROM %s
+		WHERE %s = ?
+	`,
+		quoteIdent(idColumn),
+		quoteIdent(vectorColumn),
+		quoteIdent(metadataColumn),
+		quoteIdent(contentColumn),
+		quoteIdent(c.name),
+		quoteIdent(idColumn),
+	)
+
+	var out vectordata.Record
+	var vectorText, metadataRaw string
+	var content sql.NullString
+	if err := c.store.db.QueryRowContext(ctx, query, id).Scan(&out.ID, &vectorText, &metadataRaw, &content); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return vectordata.Record{}, vectordata.ErrNotFound
+		}
+		return vectordata.Record{}, classifyError(err)
+	}
+
+	vector, err := parseVectorText(vectorText)
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("decode vector: %w", err)
+	}
+	metadata, err := parseMetadata(metadataRaw)
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("decode metadata: %w", err)
+	}
+	out.Vector = vector
+	out.Metadata = metadata
+	if content.Valid {
+		out.Content = &content.String
+	}
+
+	return out, nil
+}
+
+func (c *LibSQLCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	for start := 0; start < len(ids); start += maxRowsPerStatement {
+		end := start + maxRowsPerStatement
+		if end > len(ids) {
+			end = len(ids)
+		}
+		placeholders := make([]string, 0, end-start)
+		args := make([]any, 0, end-start)
+		for _, id := range ids[start:end] {
+			placeholders = append(placeholders, "?")
+			args = append(args, id)
+		}
+
+		query := fmt.Sprintf(`DELETE FROM %s WHERE %s IN (%s)`, quoteIdent(c.name), quoteIdent(idColumn), strings.Join(placeholders, ", "))
+		res, err := c.store.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deleted, classifyError(err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += affected
+	}
+
+	if err := c.store.syncAfterWrite(ctx); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+func (c *LibSQLCollection) Count(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, quoteIdent(c.name))
+	whereSQL, args, err := compileFilterSQL(filter)
+	if err != nil {
+		return 0, err
+	}
+	if whereSQL != "" {
+		query += " WHERE " + whereSQL
+	}
+
+	var count int64
+	if err := c.store.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, classifyError(err)
+	}
+	return count, nil
+}
+
+// Sample returns up to n random records matching opts.Filter, ordered with
+// ORDER BY random(). SQLite's random() cannot be seeded, so a non-nil
+// opts.Seed fails with errors.ErrUnsupported.
+func (c *LibSQLCollection) Sample(ctx context.Context, n int, opts vectordata.SampleOptions) ([]vectordata.Record, error) {
+	query, args, err := c.buildSampleQuery(n, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryRecords(ctx, query, args, vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true})
+}
+
+// List returns up to opts.Limit records matching opts.Filter with IDs after
+// opts.After, in ascending ID order.
+func (c *LibSQLCollection) List(ctx context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	query, args, err := c.buildListQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryRecords(ctx, query, args, resolveProjection(opts.Projection))
+}
+
+func (c *LibSQLCollection) buildListQuery(opts vectordata.ListOptions) (string, []any, error) {
+	if opts.Limit <= 0 {
+		return "", nil, fmt.Errorf("list limit must be > 0")
+	}
+	whereSQL, args, err := compileFilterSQL(opts.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Every record has a non-empty ID, so the first page lists after "".
+	where := quoteIdent(idColumn) + " > ?"
+	if whereSQL != "" {
+		where = whereSQL + " AND " + where
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT ?`,
+		strings.Join(projectedColumns(resolveProjection(opts.Projection)), ", "),
+		quoteIdent(c.name),
+		where,
+		quoteIdent(idColumn),
+	)
+	return query, append(args, opts.After, opts.Limit), nil
+}
+
+// queryRecords runs a query selecting projectedColumns(projection).
+func (c *LibSQLCollection) queryRecords(ctx context.Context, query string, args []any, projection vectordata.Projection) ([]vectordata.Record, error) {
+	rows, err := c.store.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	defer rows.Close()
+
+	records := make([]vectordata.Record, 0)
+	for rows.Next() {
+		var rec vectordata.Record
+		var vectorText, metadataRaw string
+		var content sql.NullString
+		scanTargets := []any{&rec.ID}
+		if projection.IncludeVector {
+			scanTargets = append(scanTargets, &vectorText)
+		}
+		if projection.IncludeMetadata {
+			scanTargets = append(scanTargets, &metadataRaw)
+		}
+		if projection.IncludeContent {
+			scanTargets = append(scanTargets, &content)
+		}
+		if err := rows.Scan(scanTargets...); err != nil {
+			return nil, err
+		}
+		if projection.IncludeVector {
+			if rec.Vector, err = parseVectorText(vectorText); err != nil {
+				return nil, fmt.Errorf("decode vector: %w", err)
+			}
+		}
+		if projection.IncludeMetadata {
+			if rec.Metadata, err = parseMetadata(metadataRaw); err != nil {
+				return nil, fmt.Errorf("decode metadata: %w", err)
+			}
+		}
+		if content.Valid {
+			rec.Content = &content.String
+		}
+		records = append(records, projection.Redact.Record(rec))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyError(err)
+	}
+	return records, nil
+}
+
+// projectedColumns lists the columns selected for projection, in the order
+// queryRecords scans them.
+func projectedColumns(projection vectordata.Projection) []string {
+	cols := []string{quoteIdent(idColumn)}
+	if projection.IncludeVector {
+		cols = append(cols, fmt.Sprintf("vector_extract(%s)", quoteIdent(vectorColumn)))
+	}
+	if projection.IncludeMetadata {
+		cols = append(cols, quoteIdent(metadataColumn))
+	}
+	if projection.IncludeContent {
+		cols = append(cols, quoteIdent(contentColumn))
+	}
+	return cols
+}
+
+func (c *LibSQLCollection) buildSampleQuery(n int, opts vectordata.SampleOptions) (string, []any, error) {
+	if n <= 0 {
+		return "", nil, fmt.Errorf("sample size must be > 0")
+	}
+	if opts.Seed != nil {
+		return "", nil, fmt.Errorf("%w: libSQL cannot seed samples", errors.ErrUnsupported)
+	}
+	whereSQL, args, err := compileFilterSQL(opts.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s`,
+		strings.Join(projectedColumns(vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}), ", "),
+		quoteIdent(c.name),
+	)
+	if whereSQL != "" {
+		query += " WHERE " + whereSQL
+	}
+	query += " ORDER BY random() LIMIT ?"
+	return query, append(args, n), nil
+}
+
+func (c *LibSQLCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	if opts.Boosted() {
+		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: libSQL cannot page search results with a cursor", errors.ErrUnsupported)
+	}
+	vector, err := vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.buildSearchPlan(vector, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.executeSearchPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	results = vectordata.NormalizeScores(defaultMetric(c.metric), results, opts.ScoreNormalization)
+	// Ties in distance are ordered in process.
+	if len(opts.OrderBy) > 0 {
+		if err := vectordata.OrderResults(results, opts.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// EnsureIndexes creates a libsql_vector_idx (DiskANN) index. HNSW options are
+// mapped onto it, with HNSW.M used as max_neighbors. Metadata indexes are not
+// supported by libSQL and are ignored.
+func (c *LibSQLCollection) EnsureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
+	if opts.Vector == nil {
+		return nil
+	}
+	return c.ensureVectorIndex(ctx, opts.Vector)
+}
+
+func (c *LibSQLCollection) buildSearchPlan(vector []float32, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+
+	distanceFn, err := distanceFunction(defaultMetric(c.metric))
+	if err != nil {
+		return searchPlan{}, err
+	}
+	projection := resolveProjection(opts.Projection)
+	literal := vectorLiteral(vector)
+
+	// The ANN index is only used for unfiltered searches: vector_top_k selects
+	// candidates before the WHERE clause runs, so filters could drop results.
+	indexName := ""
+	if opts.Filter == nil {
+		if name := c.vectorIndex.Load(); name != nil {
+			indexName = *name
+		}
+	}
+
+	alias := ""
+	if indexName != "" {
+		alias = "t."
+	}
+	distanceExpr := fmt.Sprintf("%s(%s%s, vector32(?))", distanceFn, alias, quoteIdent(vectorColumn))
+
+	selectCols := []string{alias + quoteIdent(idColumn)}
+	if projection.IncludeVector {
+		selectCols = append(selectCols, fmt.Sprintf("vector_extract(%s%s)", alias, quoteIdent(vectorColumn)))
+	}
+	if projection.IncludeMetadata {
+		selectCols = append(selectCols, alias+quoteIdent(metadataColumn))
+	}
+	if projection.IncludeContent {
+		selectCols = append(selectCols, alias+quoteIdent(contentColumn))
+	}
+	selectCols = append(selectCols, distanceExpr+" AS distance")
+
+	args := []any{literal}
+	whereParts := make([]string, 0, 2)
+
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	b.WriteString(strings.Join(selectCols, ", "))
+	if indexName != "" {
+		b.WriteString(" FROM vector_top_k(?, vector32(?), ?) AS top JOIN ")
+		b.WriteString(quoteIdent(c.name))
+		b.WriteString(" AS t ON t.rowid = top.id")
+		args = append(args, indexName, literal, topK)
+	} else {
+		b.WriteString(" FROM ")
+		b.WriteString(quoteIdent(c.name))
+	}
+
+	if opts.Filter != nil {
+		whereSQL, filterArgs, err := compileFilterSQL(opts.Filter)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		if whereSQL != "" {
+			whereParts = append(whereParts, whereSQL)
+		}
+		args = append(args, filterArgs...)
+	}
+
+	if opts.Threshold != nil {
+		whereParts = append(whereParts, fmt.Sprintf("(%s <= ?)", distanceExpr))
+		args = append(args, literal, *opts.Threshold)
+	}
+
+	if len(whereParts) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(whereParts, " AND "))
+	}
+	b.WriteString(" ORDER BY distance ASC LIMIT ?")
+	args = append(args, topK)
+
+	return searchPlan{
+		query:      b.String(),
+		args:       args,
+		projection: projection,
+	}, nil
+}
+
+func (c *LibSQLCollection) executeSearchPlan(ctx context.Context, plan searchPlan) ([]vectordata.SearchResult, error) {
+	rows, err := c.store.db.QueryContext(ctx, plan.query, plan.args...)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	defer rows.Close()
+
+	results := make([]vectordata.SearchResult, 0)
+	for rows.Next() {
+		result, err := c.scanSearchResult(rows, plan.projection)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyError(err)
+	}
+	return results, nil
+}
+
+func (c *LibSQLCollection) scanSearchResult(rows *sql.Rows, projection vectordata.Projection) (vectordata.SearchResult, error) {
+	var rec vectordata.Record
+	var vectorText, metadataRaw string
+	var content sql.NullString
+	var distance float64
+
+	scanTargets := []any{&rec.ID}
+	if projection.IncludeVector {
+		scanTargets = append(scanTargets, &vectorText)
+	}
+	if projection.IncludeMetadata {
+		scanTargets = append(scanTargets, &metadataRaw)
+	}
+	if projection.IncludeContent {
+		scanTargets = append(scanTargets, &content)
+	}
+	scanTargets = append(scanTargets, &distance)
+
+	if err := rows.Scan(scanTargets...); err != nil {
+		return vectordata.SearchResult{}, err
+	}
+
+	if projection.IncludeVector {
+		parsed, err := parseVectorText(vectorText)
+		if err != nil {
+			return vectordata.SearchResult{}, fmt.Errorf("decode vector: %w", err)
+		}
+		rec.Vector = parsed
+	}
+	if projection.IncludeMetadata {
+		parsed, err := parseMetadata(metadataRaw)
+		if err != nil {
+			return vectordata.SearchResult{}, fmt.Errorf("decode metadata: %w", err)
+		}
+		rec.Metadata = parsed
+	}
+	if projection.IncludeContent && content.Valid {
+		rec.Content = &content.String
+	}
+
+	return vectordata.SearchResult{
+		Record:   projection.Redact.Record(rec),
+		Distance: distance,
+		Score:    vectordata.ScoreFromDistance(defaultMetric(c.metric), distance),
+	}, nil
+}
+
+func (c *LibSQLCollection) writeRecords(ctx context.Context, records []vectordata.Record, mode writeMode) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	for start := 0; start < len(records); start += maxRowsPerStatement {
+		end := start + maxRowsPerStatement
+		if end > len(records) {
+			end = len(records)
+		}
+
+		query, args, err := c.buildWriteBatch(records[start:end], mode)
+		if err != nil {
+			return err
+		}
+		if _, err := c.store.db.ExecContext(ctx, query, args...); err != nil {
+			return classifyError(err)
+		}
+	}
+	return c.store.syncAfterWrite(ctx)
+}
+
+func (c *LibSQLCollection) buildWriteBatch(records []vectordata.Record, mode writeMode) (string, []any, error) {
+	args := make([]any, 0, len(records)*4)
+	values := make([]string, 0, len(records))
+
+	for _, record := range records {
+		if strings.TrimSpace(record.ID) == "" {
+			return "", nil, fmt.Errorf("record id is empty")
+		}
+		if err := c.validateVectorDimension(record.Vector); err != nil {
+			return "", nil, err
+		}
+
+		metadataPayload, err := metadataJSON(record.Metadata)
+		if err != nil {
+			return "", nil, fmt.Errorf("encode metadata for record %q: %w", record.ID, err)
+		}
+
+		values = append(values, "(?, vector32(?), ?, ?)")
+		var content any
+		if record.Content != nil {
+			content = *record.Content
+		}
+		args = append(args, record.ID, vectorLiteral(record.Vector), metadataPayload, content)
+	}
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(quoteIdent(c.name))
+	b.WriteString(" (")
+	b.WriteString(strings.Join([]string{
+		quoteIdent(idColumn),
+		quoteIdent(vectorColumn),
+		quoteIdent(metadataColumn),
+		quoteIdent(contentColumn),
+	}, ", "))
+	b.WriteString(") VALUES ")
+	b.WriteString(strings.Join(values, ", "))
+
+	if mode == writeModeUpsert {
+		b.WriteString(" ON CONFLICT (")
+		b.WriteString(quoteIdent(idColumn))
+		b.WriteString(") DO UPDATE SET ")
+		b.WriteString(quoteIdent(vectorColumn) + " = excluded." + quoteIdent(vectorColumn) + ", ")
+		b.WriteString(quoteIdent(metadataColumn) + " = excluded." + quoteIdent(metadataColumn) + ", ")
+		b.WriteString(quoteIdent(contentColumn) + " = excluded." + quoteIdent(contentColumn))
+	}
+
+	return b.String(), args, nil
+}
+
+func (c *LibSQLCollection) ensureVectorIndex(ctx context.Context, opts *vectordata.VectorIndexOptions) error {
+	query, indexName, err := c.buildVectorIndexQuery(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := c.store.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ensure vector index: %w", err)
+	}
+	c.vectorIndex.Store(&indexName)
+	return c.store.syncAfterWrite(ctx)
+}
+
+func (c *LibSQLCollection) buildVectorIndexQuery(opts *vectordata.VectorIndexOptions) (string, string, error) {
+	method := vectordata.IndexMethodHNSW
+	if opts.Method != "" {
+		method = opts.Method
+	}
+	if method != vectordata.IndexMethodHNSW {
+		return "", "", fmt.Errorf("%w: unsupported index method %q", vectordata.ErrSchemaMismatch, method)
+	}
+
+	metric := defaultMetric(c.metric)
+	if opts.Metric != "" && opts.Metric != metric {
+		return "", "", fmt.Errorf("%w: index metric %q differs from collection metric %q", vectordata.ErrSchemaMismatch, opts.Metric, metric)
+	}
+	metricName, err := indexMetric(metric)
+	if err != nil {
+		return "", "", err
+	}
+
+	indexName := opts.Name
+	if indexName == "" {
+		indexName = fmt.Sprintf("idx_%s_vector", c.name)
+	}
+
+	settings := []string{"'metric=" + metricName + "'"}
+	if opts.HNSW.M > 0 {
+		settings = append(settings, fmt.Sprintf("'max_neighbors=%d'", opts.HNSW.M))
+	}
+
+	query := fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (libsql_vector_idx(%s, %s))",
+		quoteIdent(indexName),
+		quoteIdent(c.name),
+		quoteIdent(vectorColumn),
+		strings.Join(settings, ", "),
+	)
+	return query, indexName, nil
+}
+
+func (c *LibSQLCollection) validateVectorDimension(vector []float32) error {
+	if len(vector) != c.dimension {
+		return fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, c.dimension, len(vector))
+	}
+	return nil
+}
+
+func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
+	if projection == nil {
+		return vectordata.DefaultProjection()
+	}
+	return *projection
+}
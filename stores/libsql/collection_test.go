@@ -0,0 +1,258 @@
+package libsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newTestCollection(t *testing.T, metric vectordata.DistanceMetric) *LibSQLCollection {
+	t.Helper()
+	store, err := NewVectorStore(&sql.DB{}, DefaultStoreOptions())
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return store.Collection("docs", 2, metric).(*LibSQLCollection)
+}
+
+func TestLibSQLCollection_BuildSearchPlanExact(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceCosine)
+	threshold := 0.3
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 5, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Column("id"), "a"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSearchPlan: %v", err)
+	}
+	expected := `SELECT "id", "metadata", "content", vector_distance_cos("vector", vector32(?)) AS distance FROM "docs" WHERE ("id" = ?) AND (vector_distance_cos("vector", vector32(?)) <= ?) ORDER BY distance ASC LIMIT ?`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	expectedArgs := []any{"[1,0]", "a", "[1,0]", 0.3, 5}
+	if !reflect.DeepEqual(plan.args, expectedArgs) {
+		t.Fatalf("unexpected args: %#v", plan.args)
+	}
+}
+
+func TestLibSQLCollection_BuildSearchPlanUsesVectorIndex(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceL2)
+	indexName := "idx_docs_vector"
+	collection.vectorIndex.Store(&indexName)
+
+	// Act
+	unfiltered, unfilteredErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{})
+	filtered, filteredErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("kind"), "a"),
+	})
+
+	// Assert
+	if unfilteredErr != nil || filteredErr != nil {
+		t.Fatalf("buildSearchPlan: %v / %v", unfilteredErr, filteredErr)
+	}
+	expected := `SELECT t."id", t."metadata", t."content", vector_distance_l2(t."vector", vector32(?)) AS distance FROM vector_top_k(?, vector32(?), ?) AS top JOIN "docs" AS t ON t.rowid = top.id ORDER BY distance ASC LIMIT ?`
+	if unfiltered.query != expected {
+		t.Fatalf("unexpected ANN query\nwant: %s\n got: %s", expected, unfiltered.query)
+	}
+	if !reflect.DeepEqual(unfiltered.args, []any{"[1,0]", indexName, "[1,0]", 3, 3}) {
+		t.Fatalf("unexpected ANN args: %#v", unfiltered.args)
+	}
+	if strings.Contains(filtered.query, "vector_top_k") {
+		t.Fatalf("expected filtered search to scan exactly: %s", filtered.query)
+	}
+}
+
+func TestLibSQLCollection_BuildVectorIndexQuery(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceCosine)
+
+	// Act
+	query, name, err := collection.buildVectorIndexQuery(&vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 32}})
+	_, _, ivfErr := collection.buildVectorIndexQuery(&vectordata.VectorIndexOptions{Method: vectordata.IndexMethodIVFFlat})
+	_, _, metricErr := collection.buildVectorIndexQuery(&vectordata.VectorIndexOptions{Metric: vectordata.DistanceInnerProduct})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildVectorIndexQuery: %v", err)
+	}
+	expected := `CREATE INDEX IF NOT EXISTS "idx_docs_vector" ON "docs" (libsql_vector_idx("vector", 'metric=cosine', 'max_neighbors=32'))`
+	if query != expected || name != "idx_docs_vector" {
+		t.Fatalf("unexpected index query\nwant: %s\n got: %s", expected, query)
+	}
+	if !errors.Is(ivfErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected IVFFlat to be rejected, got %v", ivfErr)
+	}
+	if !errors.Is(metricErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected metric mismatch, got %v", metricErr)
+	}
+}
+
+func TestLibSQLCollection_ValidatesInput(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceInnerProduct)
+
+	// Act
+	_, metricErr := collection.buildSearchPlan([]float32{1, 0}, 1, vectordata.SearchOptions{})
+	_, dimErr := newTestCollection(t, vectordata.DistanceCosine).buildSearchPlan([]float32{1}, 1, vectordata.SearchOptions{})
+	_, _, idErr := collection.buildWriteBatch([]vectordata.Record{{Vector: []float32{1, 0}}}, writeModeInsert)
+
+	// Assert
+	if !errors.Is(metricErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected inner product to be rejected, got %v", metricErr)
+	}
+	if !errors.Is(dimErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", dimErr)
+	}
+	if idErr == nil {
+		t.Fatal("expected empty record id to fail")
+	}
+}
+
+func TestNewVectorStore_ValidatesSyncOptions(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.SyncAfterWrite = true
+	calls := 0
+
+	// Act
+	_, missingErr := NewVectorStore(&sql.DB{}, opts)
+	opts.Sync = func(context.Context) error {
+		calls++
+		return nil
+	}
+	store, err := NewVectorStore(&sql.DB{}, opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	syncErr := store.syncAfterWrite(context.Background())
+
+	// Assert
+	if !errors.Is(missingErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected SyncAfterWrite without Sync to fail, got %v", missingErr)
+	}
+	if syncErr != nil || calls != 1 {
+		t.Fatalf("expected one sync call, got %d (%v)", calls, syncErr)
+	}
+}
+
+func TestParseVectorDimension(t *testing.T) {
+	cases := map[string]int{"F32_BLOB(384)": 384, "float32(3)": 3}
+	for typeName, expected := range cases {
+		// Act
+		dim, err := parseVectorDimension(typeName)
+
+		// Assert
+		if err != nil || dim != expected {
+			t.Fatalf("parseVectorDimension(%q) = %d, %v", typeName, dim, err)
+		}
+	}
+	if _, err := parseVectorDimension("BLOB"); err == nil {
+		t.Fatal("expected non-vector type to fail")
+	}
+}
+
+func TestLibSQLVectorStore_PlanCollectionAndIndexes(t *testing.T) {
+	// Arrange
+	store, err := NewVectorStore(&sql.DB{}, DefaultStoreOptions())
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 4, Metric: vectordata.DistanceL2}
+
+	// Act
+	tables, tableErr := store.PlanCollection(spec)
+	indexes, indexErr := store.PlanIndexes(spec, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 32}}})
+
+	// Assert
+	if tableErr != nil || len(tables) != 1 || !strings.Contains(tables[0], `"vector" F32_BLOB(4) NOT NULL`) {
+		t.Fatalf("unexpected table plan %q (%v)", tables, tableErr)
+	}
+	expected := `CREATE INDEX IF NOT EXISTS "idx_docs_vector" ON "docs" (libsql_vector_idx("vector", 'metric=l2', 'max_neighbors=32'))`
+	if indexErr != nil || !reflect.DeepEqual(indexes, []string{expected}) {
+		t.Fatalf("unexpected index plan %q (%v)", indexes, indexErr)
+	}
+}
+
+func TestLibSQLCollection_BuildSampleQuery(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceCosine)
+	seed := int64(1)
+
+	// Act
+	query, args, err := collection.buildSampleQuery(3, vectordata.SampleOptions{
+		Filter: vectordata.Eq(vectordata.Column("id"), "a"),
+	})
+	_, _, seedErr := collection.buildSampleQuery(3, vectordata.SampleOptions{Seed: &seed})
+	_, _, sizeErr := collection.buildSampleQuery(0, vectordata.SampleOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSampleQuery: %v", err)
+	}
+	expected := `SELECT "id", vector_extract("vector"), "metadata", "content" FROM "docs" WHERE ("id" = ?) ORDER BY random() LIMIT ?`
+	if query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"a", 3}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if !errors.Is(seedErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for a seed, got %v", seedErr)
+	}
+	if sizeErr == nil {
+		t.Fatalf("expected a non-positive size to fail")
+	}
+}
+
+func TestLibSQLCollection_BuildListQuery(t *testing.T) {
+	// Arrange
+	collection := newTestCollection(t, vectordata.DistanceCosine)
+
+	// Act
+	query, args, err := collection.buildListQuery(vectordata.ListOptions{
+		Filter:     vectordata.Eq(vectordata.Column("id"), "a"),
+		After:      "0",
+		Limit:      10,
+		Projection: &vectordata.Projection{},
+	})
+	_, _, limitErr := collection.buildListQuery(vectordata.ListOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildListQuery: %v", err)
+	}
+	expected := `SELECT "id" FROM "docs" WHERE ("id" = ?) AND "id" > ? ORDER BY "id" LIMIT ?`
+	if query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"a", "0", 10}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if limitErr == nil {
+		t.Fatalf("expected a non-positive limit to fail")
+	}
+}
+
+func TestColumnMigration_Statement(t *testing.T) {
+	// Arrange
+	migration := columnMigration{column: "content", definition: "TEXT"}
+
+	// Act
+	statement := migration.statement("docs")
+
+	// Assert
+	if statement != `ALTER TABLE "docs" ADD COLUMN "content" TEXT` {
+		t.Fatalf("unexpected statement %s", statement)
+	}
+}
@@ -0,0 +1,6 @@
+// Package libsql provides a libSQL/Turso-backed vectordata implementation.
+//
+// The store works on a caller-provided *sql.DB opened with any libSQL driver,
+// including embedded replicas. Vectors are stored in native F32_BLOB columns
+// and ranked with vector_distance_cos / vector_distance_l2.
+package libsql
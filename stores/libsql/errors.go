@@ -0,0 +1,46 @@
+package libsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// sqliteErrorCategories maps fragments of SQLite error messages to
+// vectordata errors. The store works with any database/sql driver, so
+// errors are recognized by the messages and result code names that SQLite,
+// libSQL and their drivers report rather than by driver error types.
+var sqliteErrorCategories = []struct {
+	fragment string
+	category error
+}{
+	{"UNIQUE constraint failed", vectordata.ErrConflict},
+	{"SQLITE_CONSTRAINT_PRIMARYKEY", vectordata.ErrConflict},
+	{"SQLITE_CONSTRAINT_UNIQUE", vectordata.ErrConflict},
+	{"database is locked", vectordata.ErrUnavailable},       // SQLITE_BUSY
+	{"database table is locked", vectordata.ErrUnavailable}, // SQLITE_LOCKED
+	{"SQLITE_BUSY", vectordata.ErrUnavailable},
+	{"SQLITE_LOCKED", vectordata.ErrUnavailable},
+}
+
+// classifyError wraps err in the vectordata error matching its cause, so
+// callers can tell duplicate IDs and a locked database apart without
+// inspecting driver errors. Cancellations are returned unchanged.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", vectordata.ErrTimeout, err)
+	}
+	message := err.Error()
+	for _, entry := range sqliteErrorCategories {
+		if strings.Contains(message, entry.fragment) {
+			return fmt.Errorf("%w: %w", entry.category, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package libsql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		want      error
+		retryable bool
+	}{
+		{"unique violation", errors.New(`UNIQUE constraint failed: docs.id`), vectordata.ErrConflict, false},
+		{"primary key code", errors.New(`SQLite error: SQLITE_CONSTRAINT_PRIMARYKEY`), vectordata.ErrConflict, false},
+		{"busy", errors.New(`database is locked (5) (SQLITE_BUSY)`), vectordata.ErrUnavailable, true},
+		{"locked table", errors.New(`database table is locked: docs`), vectordata.ErrUnavailable, true},
+		{"locked code", errors.New(`SQLITE_LOCKED: database schema is locked`), vectordata.ErrUnavailable, true},
+		{"deadline", context.DeadlineExceeded, vectordata.ErrTimeout, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := classifyError(tc.err)
+
+			// Assert
+			if !errors.Is(err, tc.want) || !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v wrapping %v, got %v", tc.want, tc.err, err)
+			}
+			if got := vectordata.IsRetryable(err); got != tc.retryable {
+				t.Fatalf("IsRetryable = %v, want %v", got, tc.retryable)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorKeepsOtherErrors(t *testing.T) {
+	cases := []error{
+		nil,
+		context.Canceled,
+		errors.New(`no such table: docs`),
+	}
+	for _, err := range cases {
+		// Act
+		got := classifyError(err)
+
+		// Assert
+		if got != err {
+			t.Fatalf("expected %v unchanged, got %v", err, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package libsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// compileFilterSQL compiles a filter AST into a SQLite WHERE fragment with
+// positional ? placeholders. Metadata is stored as JSON text and addressed
+// with JSON paths bound as arguments.
+//
+// Equality compares the JSON representation of the metadata value, so types
+// must match as they do with jsonb in the Postgres store.
+func compileFilterSQL(filter vectordata.Filter) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+	c := filterCompiler{}
+	out, err := c.compile(filter)
+	if err != nil {
+		return "", nil, err
+	}
+	return out, c.args, nil
+}
+
+type filterCompiler struct {
+	args []any
+}
+
+func (c *filterCompiler) compile(f vectordata.Filter) (string, error) {
+	switch node := f.(type) {
+	case vectordata.EqFilter:
+		expr, err := c.valueExpr(node.Field)
+		if err != nil {
+			return "", err
+		}
+		value, err := c.bindValue(node.Field, node.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s = %s)", expr, value), nil
+	case vectordata.InFilter:
+		if len(node.Values) == 0 {
+			return "", fmt.Errorf("%w: IN requires at least one value", vectordata.ErrInvalidFilter)
+		}
+		expr, err := c.valueExpr(node.Field)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, 0, len(node.Values))
+		for _, v := range node.Values {
+			value, err := c.bindValue(node.Field, v)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, value)
+		}
+		return fmt.Sprintf("(%s IN (%s))", expr, strings.Join(parts, ", ")), nil
+	case vectordata.GtFilter:
+		return c.compileCompare(node.Field, ">", node.Value)
+	case vectordata.LtFilter:
+		return c.compileCompare(node.Field, "<", node.Value)
+	case vectordata.ExistsFilter:
+		field, err := vectordata.NormalizeFieldRef(node.Field)
+		if err != nil {
+			return "", err
+		}
+		if field.Kind == vectordata.FieldColumn {
+			column, err := columnExpr(field.Name)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("(%s IS NOT NULL)", column), nil
+		}
+		path, err := c.bindPath(field.Path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(json_type(%s, %s) IS NOT NULL)", quoteIdent(metadataColumn), path), nil
+	case vectordata.AndFilter:
+		return c.compileLogical("AND", node.Children)
+	case vectordata.OrFilter:
+		return c.compileLogical("OR", node.Children)
+	case vectordata.NotFilter:
+		if node.Child == nil {
+			return "", fmt.Errorf("%w: NOT requires a child", vectordata.ErrInvalidFilter)
+		}
+		child, err := c.compile(node.Child)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(NOT %s)", child), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported node type %T", vectordata.ErrInvalidFilter, f)
+	}
+}
+
+func (c *filterCompiler) compileCompare(ref vectordata.FieldRef, op string, value any) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		column, err := columnExpr(field.Name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", column, op, c.bind(value)), nil
+	}
+
+	num, ok := toFloat64(value)
+	if !ok {
+		path, err := c.bindPath(field.Path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(json_extract(%s, %s) %s %s)", quoteIdent(metadataColumn), path, op, c.bind(fmt.Sprint(value))), nil
+	}
+
+	typePath, err := c.bindPath(field.Path)
+	if err != nil {
+		return "", err
+	}
+	valuePath, err := c.bindPath(field.Path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"(CASE WHEN json_type(%s, %s) IN ('integer', 'real') THEN json_extract(%s, %s) %s %s ELSE 0 END)",
+		quoteIdent(metadataColumn), typePath,
+		quoteIdent(metadataColumn), valuePath,
+		op, c.bind(num),
+	), nil
+}
+
+func (c *filterCompiler) compileLogical(op string, children []vectordata.Filter) (string, error) {
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: %s requires at least one child", vectordata.ErrInvalidFilter, op)
+	}
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		if child == nil {
+			return "", fmt.Errorf("%w: %s contains nil child", vectordata.ErrInvalidFilter, op)
+		}
+		childSQL, err := c.compile(child)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, childSQL)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, fmt.Sprintf(" %s ", op))), nil
+}
+
+// valueExpr returns the expression compared by Eq and In: the raw column, or
+// the minified JSON text of a metadata value.
+func (c *filterCompiler) valueExpr(ref vectordata.FieldRef) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		return columnExpr(field.Name)
+	}
+	path, err := c.bindPath(field.Path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s -> %s)", quoteIdent(metadataColumn), path), nil
+}
+
+func (c *filterCompiler) bindValue(ref vectordata.FieldRef, v any) (string, error) {
+	if ref.Kind == vectordata.FieldColumn {
+		return c.bind(v), nil
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: JSON encode value: %v", vectordata.ErrInvalidFilter, err)
+	}
+	return c.bind(string(encoded)), nil
+}
+
+func (c *filterCompiler) bindPath(path []string) (string, error) {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, segment := range path {
+		if strings.Contains(segment, `"`) {
+			return "", fmt.Errorf("%w: metadata path segment %q contains a double quote", vectordata.ErrInvalidFilter, segment)
+		}
+		b.WriteString(`."`)
+		b.WriteString(segment)
+		b.WriteByte('"')
+	}
+	return c.bind(b.String()), nil
+}
+
+func (c *filterCompiler) bind(v any) string {
+	c.args = append(c.args, v)
+	return "?"
+}
+
+func columnExpr(name string) (string, error) {
+	switch name {
+	case idColumn, contentColumn:
+		return quoteIdent(name), nil
+	default:
+		return "", fmt.Errorf("%w: unknown column %q", vectordata.ErrInvalidFilter, name)
+	}
+}
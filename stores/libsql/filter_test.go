@@ -0,0 +1,76 @@
+package libsql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestCompileFilterSQL_Complex(t *testing.T) {
+	// Arrange
+	filter := vectordata.And(
+		vectordata.Eq(vectordata.Column("id"), "r1"),
+		vectordata.In(vectordata.Metadata("category"), "news", 3),
+		vectordata.Or(
+			vectordata.Gt(vectordata.Metadata("stats", "views"), 10),
+			vectordata.Not(vectordata.Exists(vectordata.Metadata("archived"))),
+		),
+	)
+
+	// Act
+	sql, args, err := compileFilterSQL(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterSQL error: %v", err)
+	}
+	expectedSQL := `(("id" = ?) AND (("metadata" -> ?) IN (?, ?)) AND ((CASE WHEN json_type("metadata", ?) IN ('integer', 'real') THEN json_extract("metadata", ?) > ? ELSE 0 END) OR (NOT (json_type("metadata", ?) IS NOT NULL))))`
+	if sql != expectedSQL {
+		t.Fatalf("unexpected SQL\nwant: %s\n got: %s", expectedSQL, sql)
+	}
+	expectedArgs := []any{"r1", `$."category"`, `"news"`, "3", `$."stats"."views"`, `$."stats"."views"`, float64(10), `$."archived"`}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Fatalf("unexpected args\nwant: %#v\n got: %#v", expectedArgs, args)
+	}
+}
+
+func TestCompileFilterSQL_TextComparison(t *testing.T) {
+	// Arrange
+	filter := vectordata.Lt(vectordata.Metadata("name"), "m")
+
+	// Act
+	sql, args, err := compileFilterSQL(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterSQL error: %v", err)
+	}
+	if sql != `(json_extract("metadata", ?) < ?)` {
+		t.Fatalf("unexpected SQL: %s", sql)
+	}
+	if !reflect.DeepEqual(args, []any{`$."name"`, "m"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestCompileFilterSQL_RejectsInvalid(t *testing.T) {
+	cases := map[string]vectordata.Filter{
+		"quote":    vectordata.Eq(vectordata.Metadata(`a"b`), "x"),
+		"column":   vectordata.Eq(vectordata.Column("vector"), "x"),
+		"empty in": vectordata.In(vectordata.Metadata("a")),
+		"empty or": vectordata.Or(),
+	}
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, _, err := compileFilterSQL(filter)
+
+			// Assert
+			if !errors.Is(err, vectordata.ErrInvalidFilter) {
+				t.Fatalf("expected ErrInvalidFilter, got %v", err)
+			}
+		})
+	}
+}
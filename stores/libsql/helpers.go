@@ -0,0 +1,183 @@
+package libsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	idColumn       = "id"
+	vectorColumn   = "vector"
+	metadataColumn = "metadata"
+	contentColumn  = "content"
+)
+
+func quoteIdent(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func defaultMetric(metric vectordata.DistanceMetric) vectordata.DistanceMetric {
+	if metric == "" {
+		return vectordata.DistanceCosine
+	}
+	return metric
+}
+
+func defaultMode(mode vectordata.EnsureMode, strictByDefault bool) vectordata.EnsureMode {
+	if mode != "" {
+		return mode
+	}
+	if strictByDefault {
+		return vectordata.EnsureStrict
+	}
+	return vectordata.EnsureAutoMigrate
+}
+
+// distanceFunction maps a metric to the libSQL distance function.
+// libSQL has no inner-product distance.
+func distanceFunction(metric vectordata.DistanceMetric) (string, error) {
+	switch metric {
+	case vectordata.DistanceCosine:
+		return "vector_distance_cos", nil
+	case vectordata.DistanceL2:
+		return "vector_distance_l2", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+}
+
+// indexMetric maps a metric to the libsql_vector_idx metric setting.
+func indexMetric(metric vectordata.DistanceMetric) (string, error) {
+	switch metric {
+	case vectordata.DistanceCosine:
+		return "cosine", nil
+	case vectordata.DistanceL2:
+		return "l2", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+}
+
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 8)
+	b.WriteByte('[')
+	for i, n := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(n), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// parseVectorText parses the output of vector_extract, e.g. "[1,0.5,-2]".
+func parseVectorText(raw string) ([]float32, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if len(raw) < 2 || raw[0] != '[' || raw[len(raw)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector value %q", raw)
+	}
+	body := strings.TrimSpace(raw[1 : len(raw)-1])
+	if body == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(body, ",")
+	out := make([]float32, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse vector element %q: %w", part, err)
+		}
+		out = append(out, float32(f))
+	}
+	return out, nil
+}
+
+// parseVectorDimension reads the dimension from a declared column type such
+// as F32_BLOB(384) or FLOAT32(384).
+func parseVectorDimension(typeName string) (int, error) {
+	upper := strings.ToUpper(strings.TrimSpace(typeName))
+	for _, prefix := range []string{"F32_BLOB(", "FLOAT32("} {
+		if !strings.HasPrefix(upper, prefix) || !strings.HasSuffix(upper, ")") {
+			continue
+		}
+		inside := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(upper, prefix), ")"))
+		dim, err := strconv.Atoi(inside)
+		if err != nil {
+			return 0, fmt.Errorf("invalid vector dimension %q", inside)
+		}
+		if dim <= 0 {
+			return 0, fmt.Errorf("invalid vector dimension %d", dim)
+		}
+		return dim, nil
+	}
+	return 0, fmt.Errorf("unexpected vector type %q", typeName)
+}
+
+func normalizeMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return map[string]any{}
+	}
+	return metadata
+}
+
+func metadataJSON(metadata map[string]any) (string, error) {
+	encoded, err := json.Marshal(normalizeMetadata(metadata))
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func parseMetadata(raw string) (map[string]any, error) {
+	if raw == "" {
+		return map[string]any{}, nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return map[string]any{}, nil
+	}
+	return out, nil
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,176 @@
+//go:build integration
+
+package libsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// The module does not depend on a libSQL driver, so these tests run against a
+// driver registered by the caller's test binary (for example via a local
+// _test.go file importing github.com/tursodatabase/libsql-client-go/libsql).
+// Set LIBSQL_TEST_DSN and optionally LIBSQL_TEST_DRIVER (default "libsql").
+func newTestStore(t *testing.T) *LibSQLVectorStore {
+	t.Helper()
+	dsn := strings.TrimSpace(os.Getenv("LIBSQL_TEST_DSN"))
+	if dsn == "" {
+		t.Skip("LIBSQL_TEST_DSN is not set")
+	}
+	driver := strings.TrimSpace(os.Getenv("LIBSQL_TEST_DRIVER"))
+	if driver == "" {
+		driver = "libsql"
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := NewVectorStore(db, DefaultStoreOptions())
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return store
+}
+
+func uniqueTableName(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, time.Now().UnixNano())
+}
+
+func dropTable(t *testing.T, store *LibSQLVectorStore, name string) {
+	t.Helper()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_, _ = store.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdent(name))
+	})
+}
+
+func TestIntegrationEnsureCollection(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	name := uniqueTableName("docs")
+	dropTable(t, store, name)
+
+	// Act
+	_, createErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	_, reopenErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	_, dimErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 3})
+
+	// Assert
+	if createErr != nil {
+		t.Fatalf("EnsureCollection create: %v", createErr)
+	}
+	if reopenErr != nil {
+		t.Fatalf("EnsureCollection reopen: %v", reopenErr)
+	}
+	if !errors.Is(dimErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected dimension mismatch, got %v", dimErr)
+	}
+}
+
+func TestIntegrationEnsureCollectionPlan(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	name := uniqueTableName("docs")
+	dropTable(t, store, name)
+	if _, err := store.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s ("id" TEXT PRIMARY KEY, "vector" F32_BLOB(2) NOT NULL)`, quoteIdent(name))); err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+
+	// Act
+	strictPlan, strictErr := store.EnsureCollectionPlan(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	migratePlan, migrateErr := store.EnsureCollectionPlan(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2, Mode: vectordata.EnsureAutoMigrate})
+	_, ensureErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2, Mode: vectordata.EnsureAutoMigrate})
+	after, afterErr := store.EnsureCollectionPlan(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+
+	// Assert
+	if !errors.Is(strictErr, vectordata.ErrSchemaMismatch) || !strictPlan.NoOp() {
+		t.Fatalf("expected a strict mismatch, got %+v, %v", strictPlan, strictErr)
+	}
+	if migrateErr != nil || len(migratePlan.Steps) != 2 || migratePlan.Steps[0].Kind != vectordata.PlanAddColumn || migratePlan.Steps[1].Object != name+".content" {
+		t.Fatalf("expected metadata and content columns, got %+v, %v", migratePlan, migrateErr)
+	}
+	if ensureErr != nil || afterErr != nil || !after.NoOp() {
+		t.Fatalf("expected no steps after migrating, got %+v, %v, %v", after, ensureErr, afterErr)
+	}
+}
+
+func TestIntegrationCRUDAndSearch(t *testing.T) {
+	for _, metric := range []vectordata.DistanceMetric{vectordata.DistanceCosine, vectordata.DistanceL2} {
+		t.Run(string(metric), func(t *testing.T) {
+			// Arrange
+			store := newTestStore(t)
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+			name := uniqueTableName("search")
+			dropTable(t, store, name)
+			collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2, Metric: metric})
+			if err != nil {
+				t.Fatalf("EnsureCollection: %v", err)
+			}
+
+			content := "first"
+			err = collection.Insert(ctx, []vectordata.Record{
+				{ID: "a", Vector: []float32{1, 0}, Content: &content, Metadata: map[string]any{"kind": "a", "rank": 1}},
+				{ID: "b", Vector: []float32{0.8, 0.6}, Metadata: map[string]any{"kind": "b", "rank": 2}},
+				{ID: "c", Vector: []float32{0, 1}, Metadata: map[string]any{"kind": "c", "rank": 3}},
+			})
+			if err != nil {
+				t.Fatalf("Insert: %v", err)
+			}
+
+			// Act
+			exact, exactErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{})
+			indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{}})
+			ann, annErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{})
+			filtered, filterErr := collection.SearchByVector(ctx, []float32{1, 0}, 3, vectordata.SearchOptions{
+				Filter: vectordata.Gt(vectordata.Metadata("rank"), 1),
+			})
+			count, countErr := collection.Count(ctx, vectordata.Eq(vectordata.Metadata("kind"), "c"))
+			rec, getErr := collection.Get(ctx, "a")
+			duplicateErr := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}})
+			deleted, deleteErr := collection.Delete(ctx, []string{"a", "missing"})
+
+			// Assert
+			if exactErr != nil || len(exact) != 2 || exact[0].Record.ID != "a" || exact[1].Record.ID != "b" {
+				t.Fatalf("unexpected exact results: %#v (%v)", exact, exactErr)
+			}
+			if indexErr != nil {
+				t.Fatalf("EnsureIndexes: %v", indexErr)
+			}
+			if annErr != nil || len(ann) != 2 || ann[0].Record.ID != "a" {
+				t.Fatalf("unexpected ANN results: %#v (%v)", ann, annErr)
+			}
+			if filterErr != nil || len(filtered) != 2 || filtered[0].Record.ID != "b" {
+				t.Fatalf("unexpected filtered results: %#v (%v)", filtered, filterErr)
+			}
+			if countErr != nil || count != 1 {
+				t.Fatalf("expected count 1, got %d (%v)", count, countErr)
+			}
+			if getErr != nil || rec.Content == nil || *rec.Content != "first" || len(rec.Vector) != 2 {
+				t.Fatalf("unexpected record: %#v (%v)", rec, getErr)
+			}
+			if duplicateErr == nil {
+				t.Fatal("expected duplicate insert to fail")
+			}
+			if deleteErr != nil || deleted != 1 {
+				t.Fatalf("expected 1 deleted, got %d (%v)", deleted, deleteErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package libsql
+
+import (
+	"context"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// PlanCollection returns the statements EnsureCollection runs to create spec
+// in an empty database, without executing them.
+func (s *LibSQLVectorStore) PlanCollection(spec vectordata.CollectionSpec) ([]string, error) {
+	normalizedSpec, _, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return []string{createTableStatement(normalizedSpec.Name, normalizedSpec.Dimension)}, nil
+}
+
+// PlanIndexes returns the statements EnsureIndexes runs with opts on the
+// collection PlanCollection creates from spec, without executing them.
+func (s *LibSQLVectorStore) PlanIndexes(spec vectordata.CollectionSpec, opts vectordata.IndexOptions) ([]string, error) {
+	normalizedSpec, _, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Vector == nil {
+		return nil, nil
+	}
+	collection := s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric, "").(*LibSQLCollection)
+	query, _, err := collection.buildVectorIndexQuery(opts.Vector)
+	if err != nil {
+		return nil, err
+	}
+	return []string{query}, nil
+}
+
+// EnsureCollectionPlan returns the changes EnsureCollection would make with
+// spec against the current database, without making them: the table when it
+// is missing, or the columns auto-migrate mode adds. Strict mismatches fail
+// as they would in EnsureCollection.
+func (s *LibSQLVectorStore) EnsureCollectionPlan(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.EnsurePlan, error) {
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+	name := normalizedSpec.Name
+	plan := vectordata.EnsurePlan{Collection: name}
+	exists, err := s.tableExists(ctx, name)
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+	if !exists {
+		plan.Steps = append(plan.Steps, vectordata.PlanStep{
+			Kind:      vectordata.PlanCreateTable,
+			Object:    name,
+			Statement: createTableStatement(name, normalizedSpec.Dimension),
+		})
+		return plan, nil
+	}
+	migrations, err := s.checkCollectionSchema(ctx, name, normalizedSpec.Dimension, mode)
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+	for _, migration := range migrations {
+		plan.Steps = append(plan.Steps, vectordata.PlanStep{
+			Kind:      vectordata.PlanAddColumn,
+			Object:    name + "." + migration.column,
+			Statement: migration.statement(name),
+		})
+	}
+	return plan, nil
+}
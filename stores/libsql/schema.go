@@ -0,0 +1,167 @@
+package libsql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func (s *LibSQLVectorStore) tableExists(ctx context.Context, table string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`,
+		table,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("check table exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *LibSQLVectorStore) createCollectionTable(ctx context.Context, table string, dimension int) error {
+	if _, err := s.db.ExecContext(ctx, createTableStatement(table, dimension)); err != nil {
+		return fmt.Errorf("create collection table %q: %w", table, err)
+	}
+	return nil
+}
+
+func createTableStatement(table string, dimension int) string {
+	return fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			%s TEXT PRIMARY KEY,
+			%s F32_BLOB(%d) NOT NULL,
+			%s TEXT NOT NULL DEFAULT '{}',
+			%s TEXT
+		)
+	`,
+		quoteIdent(table),
+		quoteIdent(idColumn),
+		quoteIdent(vectorColumn),
+		dimension,
+		quoteIdent(metadataColumn),
+		quoteIdent(contentColumn),
+	)
+}
+
+// columnMigration is a column auto-migrate mode adds to an existing table.
+type columnMigration struct {
+	column     string
+	definition string
+}
+
+func (m columnMigration) statement(table string) string {
+	return fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, quoteIdent(table), quoteIdent(m.column), m.definition)
+}
+
+func (s *LibSQLVectorStore) validateCollectionSchema(ctx context.Context, table string, expectedDimension int, mode vectordata.EnsureMode) error {
+	migrations, err := s.checkCollectionSchema(ctx, table, expectedDimension, mode)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		if _, err := s.db.ExecContext(ctx, migration.statement(table)); err != nil {
+			return fmt.Errorf("auto-migrate %s column: %w", migration.column, err)
+		}
+	}
+	return nil
+}
+
+// checkCollectionSchema validates an existing table and returns the columns
+// auto-migrate mode adds to it.
+func (s *LibSQLVectorStore) checkCollectionSchema(ctx context.Context, table string, expectedDimension int, mode vectordata.EnsureMode) ([]columnMigration, error) {
+	type columnInfo struct {
+		dataType string
+		pk       int
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT name, type, pk FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("read schema columns: %w", err)
+	}
+	defer rows.Close()
+
+	cols := map[string]columnInfo{}
+	for rows.Next() {
+		var name string
+		var info columnInfo
+		if err := rows.Scan(&name, &info.dataType, &info.pk); err != nil {
+			return nil, fmt.Errorf("scan schema columns: %w", err)
+		}
+		cols[name] = info
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schema columns: %w", err)
+	}
+
+	id, ok := cols[idColumn]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, idColumn)
+	}
+	vector, ok := cols[vectorColumn]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, vectorColumn)
+	}
+
+	if !strings.EqualFold(id.dataType, "text") {
+		return nil, fmt.Errorf("%w: expected %q data type TEXT, got %q", vectordata.ErrSchemaMismatch, idColumn, id.dataType)
+	}
+	if id.pk == 0 {
+		return nil, fmt.Errorf("%w: primary key on %q is required", vectordata.ErrSchemaMismatch, idColumn)
+	}
+
+	dimension, err := parseVectorDimension(vector.dataType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vectordata.ErrSchemaMismatch, err)
+	}
+	if dimension != expectedDimension {
+		return nil, fmt.Errorf("%w: expected vector dimension %d, got %d", vectordata.ErrSchemaMismatch, expectedDimension, dimension)
+	}
+
+	var migrations []columnMigration
+	if metadata, ok := cols[metadataColumn]; !ok {
+		if mode == vectordata.EnsureStrict {
+			return nil, fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, metadataColumn)
+		}
+		migrations = append(migrations, columnMigration{column: metadataColumn, definition: `TEXT NOT NULL DEFAULT '{}'`})
+	} else if !strings.EqualFold(metadata.dataType, "text") {
+		return nil, fmt.Errorf("%w: expected %q data type TEXT, got %q", vectordata.ErrSchemaMismatch, metadataColumn, metadata.dataType)
+	}
+
+	if content, ok := cols[contentColumn]; !ok {
+		if mode == vectordata.EnsureStrict {
+			return nil, fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, contentColumn)
+		}
+		migrations = append(migrations, columnMigration{column: contentColumn, definition: `TEXT`})
+	} else if !strings.EqualFold(content.dataType, "text") {
+		return nil, fmt.Errorf("%w: expected %q data type TEXT, got %q", vectordata.ErrSchemaMismatch, contentColumn, content.dataType)
+	}
+
+	return migrations, nil
+}
+
+// findVectorIndex returns the name of a libsql_vector_idx index on the table, if any.
+func (s *LibSQLVectorStore) findVectorIndex(ctx context.Context, table string) (string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT name, sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL`,
+		table,
+	)
+	if err != nil {
+		return "", fmt.Errorf("read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			return "", fmt.Errorf("scan indexes: %w", err)
+		}
+		if strings.Contains(strings.ToLower(ddl), "libsql_vector_idx") {
+			return name, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate indexes: %w", err)
+	}
+	return "", nil
+}
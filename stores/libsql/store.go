@@ -0,0 +1,151 @@
+package libsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// StoreOptions configures LibSQLVectorStore behavior.
+type StoreOptions struct {
+	// Sync pulls changes from the primary into an embedded replica.
+	// With go-libsql, pass a function that calls (*libsql.Connector).Sync.
+	Sync func(ctx context.Context) error
+	// SyncAfterWrite calls Sync after every successful write so reads served
+	// by an embedded replica observe the caller's own writes.
+	SyncAfterWrite  bool
+	StrictByDefault bool
+}
+
+// DefaultStoreOptions returns production-safe defaults.
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		StrictByDefault: true,
+	}
+}
+
+// LibSQLVectorStore implements vectordata.VectorStore using database/sql.
+type LibSQLVectorStore struct {
+	db   *sql.DB
+	opts StoreOptions
+}
+
+// NewVectorStore creates a libSQL-backed vector store.
+func NewVectorStore(db *sql.DB, opts StoreOptions) (*LibSQLVectorStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("nil sql db")
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &LibSQLVectorStore{db: db, opts: opts}, nil
+}
+
+// Collection returns a handle to a collection without schema checks.
+func (s *LibSQLVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.newCollectionHandle(name, dimension, metric, "")
+}
+
+// EnsureCollection creates or validates a collection schema and returns its handle.
+func (s *LibSQLVectorStore) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureTableWithValidation(ctx, normalizedSpec.Name, normalizedSpec.Dimension, mode); err != nil {
+		return nil, err
+	}
+
+	vectorIndex, err := s.findVectorIndex(ctx, normalizedSpec.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric, vectorIndex), nil
+}
+
+// Sync pulls the latest changes into an embedded replica. It is a no-op when
+// StoreOptions.Sync is not configured.
+func (s *LibSQLVectorStore) Sync(ctx context.Context) error {
+	if s.opts.Sync == nil {
+		return nil
+	}
+	if err := s.opts.Sync(ctx); err != nil {
+		return fmt.Errorf("sync embedded replica: %w", err)
+	}
+	return nil
+}
+
+func (s *LibSQLVectorStore) syncAfterWrite(ctx context.Context) error {
+	if !s.opts.SyncAfterWrite {
+		return nil
+	}
+	return s.Sync(ctx)
+}
+
+func (s *LibSQLVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, vectordata.EnsureMode, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
+	}
+	if spec.Dimension <= 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	spec.Metric = defaultMetric(spec.Metric)
+	if _, err := distanceFunction(spec.Metric); err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
+	if spec.NormalizeVectors {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: NormalizeVectors is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.ElementType != "" && spec.ElementType != vectordata.ElementFloat32 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: vector element type %q is not supported", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension != 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.MaxRecords != 0 || spec.EvictBy != "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: MaxRecords is not supported", vectordata.ErrSchemaMismatch)
+	}
+
+	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
+	}
+	return spec, mode, nil
+}
+
+func (s *LibSQLVectorStore) ensureTableWithValidation(ctx context.Context, tableName string, dimension int, mode vectordata.EnsureMode) error {
+	exists, err := s.tableExists(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return s.createCollectionTable(ctx, tableName, dimension)
+	}
+	return s.validateCollectionSchema(ctx, tableName, dimension, mode)
+}
+
+func (s *LibSQLVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric, vectorIndex string) vectordata.Collection {
+	c := &LibSQLCollection{
+		store:     s,
+		name:      name,
+		dimension: dimension,
+		metric:    defaultMetric(metric),
+	}
+	if vectorIndex != "" {
+		c.vectorIndex.Store(&vectorIndex)
+	}
+	return c
+}
+
+func (o StoreOptions) validate() error {
+	if o.SyncAfterWrite && o.Sync == nil {
+		return fmt.Errorf("%w: SyncAfterWrite requires Sync", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
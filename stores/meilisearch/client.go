@@ -0,0 +1,175 @@
+package meilisearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type client struct {
+	endpoint     string
+	apiKey       string
+	http         *http.Client
+	pollInterval time.Duration
+}
+
+// apiError is returned for non-2xx Meilisearch responses and failed tasks.
+type apiError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("meilisearch: http %d: %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("meilisearch: http %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the vectordata error matching the status, if any.
+func (e *apiError) Unwrap() error {
+	return statusCategory(e.StatusCode)
+}
+
+func statusCategory(status int) error {
+	switch status {
+	case http.StatusConflict:
+		return vectordata.ErrConflict
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return vectordata.ErrTimeout
+	case http.StatusRequestEntityTooLarge:
+		return vectordata.ErrTooLarge
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return vectordata.ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// transportError categorizes a request that got no response.
+func transportError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", vectordata.ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", vectordata.ErrUnavailable, err)
+	}
+}
+
+// task is the summary returned for every asynchronous Meilisearch operation.
+type task struct {
+	TaskUID int64           `json:"taskUid"`
+	UID     int64           `json:"uid"`
+	Status  string          `json:"status"`
+	Details json.RawMessage `json:"details"`
+	Error   *struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	} `json:"error"`
+}
+
+func newClient(endpoint, apiKey string, httpClient *http.Client, pollInterval time.Duration) *client {
+	return &client{endpoint: endpoint, apiKey: apiKey, http: httpClient, pollInterval: pollInterval}
+}
+
+// do sends a JSON request and decodes a JSON response into out when out is non-nil.
+func (c *client) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request body: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return transportError(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &apiError{StatusCode: resp.StatusCode}
+		var payload struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		}
+		if err := json.Unmarshal(raw, &payload); err == nil && payload.Message != "" {
+			apiErr.Message, apiErr.Code = payload.Message, payload.Code
+		} else {
+			apiErr.Message = strings.TrimSpace(string(raw))
+		}
+		return apiErr
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
+}
+
+// doTask sends a request that enqueues a task and waits for it to finish.
+func (c *client) doTask(ctx context.Context, method, path string, body any) (task, error) {
+	var enqueued task
+	if err := c.do(ctx, method, path, body, &enqueued); err != nil {
+		return task{}, err
+	}
+	return c.waitForTask(ctx, enqueued.TaskUID)
+}
+
+func (c *client) waitForTask(ctx context.Context, uid int64) (task, error) {
+	path := "/tasks/" + strconv.FormatInt(uid, 10)
+	for {
+		var current task
+		if err := c.do(ctx, http.MethodGet, path, nil, &current); err != nil {
+			return task{}, err
+		}
+		switch current.Status {
+		case "succeeded":
+			return current, nil
+		case "failed", "canceled":
+			apiErr := &apiError{StatusCode: http.StatusUnprocessableEntity, Message: "task " + current.Status}
+			if current.Error != nil {
+				apiErr.Code, apiErr.Message = current.Error.Code, current.Error.Message
+			}
+			return current, apiErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return task{}, fmt.Errorf("wait for task %d: %w", uid, ctx.Err())
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/gabisonia/go-vectorstore/vectordata/vectormath"
+)
+
+type writeMode int
+
+const (
+	writeModeInsert writeMode = iota
+	writeModeUpsert
+)
+
+type storedDocument struct {
+	RecordID string                     `json:"record_id"`
+	Metadata map[string]any             `json:"metadata"`
+	Content  *string                    `json:"content"`
+	Vectors  map[string]json.RawMessage `json:"_vectors"`
+}
+
+type searchPlan struct {
+	body       map[string]any
+	vector     []float32
+	threshold  *float64
+	projection vectordata.Projection
+}
+
+// MeilisearchCollection is a Meilisearch-backed vector collection.
+type MeilisearchCollection struct {
+	store     *MeilisearchVectorStore
+	name      string
+	dimension int
+	metric    vectordata.DistanceMetric
+}
+
+func (c *MeilisearchCollection) Name() string {
+	return c.name
+}
+
+func (c *MeilisearchCollection) Dimension() int {
+	return c.dimension
+}
+
+func (c *MeilisearchCollection) Metric() vectordata.DistanceMetric {
+	return c.metric
+}
+
+// Insert writes records whose IDs are new, and fails with
+// vectordata.ErrConflict if an ID repeats within records or is already
+// stored. Meilisearch has no conditional writes, so the check for stored IDs
+// is a lookup before the write and is best-effort: concurrent Inserts of the
+// same ID can both succeed, the later overwriting the earlier.
+func (c *MeilisearchCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeInsert)
+}
+
+func (c *MeilisearchCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeUpsert)
+}
+
+func (c *MeilisearchCollection) Get(ctx context.Context, id string) (vectordata.Record, error) {
+	var doc storedDocument
+	path := indexPath(c.name, "documents", documentKey(id)) + "?retrieveVectors=true"
+	if err := c.store.client.do(ctx, http.MethodGet, path, nil, &doc); err != nil {
+		if isNotFound(err) {
+			return vectordata.Record{}, vectordata.ErrNotFound
+		}
+		return vectordata.Record{}, err
+	}
+	vector, err := decodeVector(doc.Vectors[embedderName])
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	return vectordata.Record{
+		ID:       doc.RecordID,
+		Vector:   vector,
+		Metadata: normalizeMetadata(doc.Metadata),
+		Content:  doc.Content,
+	}, nil
+}
+
+func (c *MeilisearchCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(ids); start += maxDocumentsPerRequest {
+		end := start + maxDocumentsPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+		keys := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, documentKey(id))
+		}
+
+		done, err := c.store.client.doTask(ctx, http.MethodPost, indexPath(c.name, "documents", "delete-batch"), keys)
+		if err != nil {
+			return deleted, err
+		}
+		var details struct {
+			DeletedDocuments int64 `json:"deletedDocuments"`
+		}
+		if err := json.Unmarshal(done.Details, &details); err != nil {
+			return deleted, fmt.Errorf("decode task details: %w", err)
+		}
+		deleted += details.DeletedDocuments
+	}
+	return deleted, nil
+}
+
+func (c *MeilisearchCollection) Count(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	expr, err := compileFilterExpression(filter)
+	if err != nil {
+		return 0, err
+	}
+	body := map[string]any{
+		"limit":  1,
+		"fields": []string{primaryKeyField},
+	}
+	if expr != "" {
+		body["filter"] = expr
+	}
+
+	var resp struct {
+		Total int64 `json:"total"`
+	}
+	if err := c.store.client.do(ctx, http.MethodPost, indexPath(c.name, "documents", "fetch"), body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Total, nil
+}
+
+func (c *MeilisearchCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	if opts.Boosted() {
+		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: Meilisearch cannot page search results with a cursor", errors.ErrUnsupported)
+	}
+	vector, err := vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.buildSearchPlan(vector, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.executeSearchPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	results = vectordata.NormalizeScores(c.metric, results, opts.ScoreNormalization)
+	// Ties in distance are ordered in process.
+	if len(opts.OrderBy) > 0 {
+		if err := vectordata.OrderResults(results, opts.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// EnsureIndexes validates index options. Meilisearch maintains its own vector
+// index for the embedder and metadata filterability is configured by
+// EnsureCollection, so there is nothing to build here.
+func (c *MeilisearchCollection) EnsureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
+	if opts.Vector != nil {
+		method := opts.Vector.Method
+		if method == "" {
+			method = vectordata.IndexMethodHNSW
+		}
+		if method != vectordata.IndexMethodHNSW {
+			return fmt.Errorf("%w: unsupported index method %q", vectordata.ErrSchemaMismatch, method)
+		}
+		if opts.Vector.Metric != "" && opts.Vector.Metric != c.metric {
+			return fmt.Errorf("%w: index metric %q differs from collection metric %q", vectordata.ErrSchemaMismatch, opts.Vector.Metric, c.metric)
+		}
+	}
+	return nil
+}
+
+func (c *MeilisearchCollection) buildSearchPlan(vector []float32, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := validateMetric(c.metric); err != nil {
+		return searchPlan{}, err
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+
+	projection := resolveProjection(opts.Projection)
+	attributes := []string{idField}
+	if projection.IncludeMetadata {
+		attributes = append(attributes, metadataField)
+	}
+	if projection.IncludeContent {
+		attributes = append(attributes, contentField)
+	}
+
+	body := map[string]any{
+		"vector": vector,
+		"hybrid": map[string]any{
+			"embedder":      embedderName,
+			"semanticRatio": 1.0,
+		},
+		"limit": topK,
+		// Vectors are always retrieved so that distances can be computed exactly.
+		"retrieveVectors":      true,
+		"attributesToRetrieve": attributes,
+	}
+
+	expr, err := compileFilterExpression(opts.Filter)
+	if err != nil {
+		return searchPlan{}, err
+	}
+	if expr != "" {
+		body["filter"] = expr
+	}
+
+	return searchPlan{body: body, vector: vector, threshold: opts.Threshold, projection: projection}, nil
+}
+
+func (c *MeilisearchCollection) executeSearchPlan(ctx context.Context, plan searchPlan) ([]vectordata.SearchResult, error) {
+	var resp struct {
+		Hits []storedDocument `json:"hits"`
+	}
+	if err := c.store.client.do(ctx, http.MethodPost, indexPath(c.name, "search"), plan.body, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]vectordata.SearchResult, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		stored, err := decodeVector(hit.Vectors[embedderName])
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) != len(plan.vector) {
+			return nil, fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, len(plan.vector), len(stored))
+		}
+		// Meilisearch does not expose a raw distance, so it is recomputed
+		// from the stored vector.
+		distance := vectormath.CosineDistance(plan.vector, stored)
+		if plan.threshold != nil && distance > *plan.threshold {
+			continue
+		}
+
+		rec := vectordata.Record{ID: hit.RecordID}
+		if plan.projection.IncludeVector {
+			rec.Vector = stored
+		}
+		if plan.projection.IncludeMetadata {
+			rec.Metadata = normalizeMetadata(hit.Metadata)
+		}
+		if plan.projection.IncludeContent {
+			rec.Content = hit.Content
+		}
+		results = append(results, vectordata.SearchResult{
+			Record:   plan.projection.Redact.Record(rec),
+			Distance: distance,
+			Score:    vectordata.ScoreFromDistance(c.metric, distance),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	return results, nil
+}
+
+func (c *MeilisearchCollection) writeRecords(ctx context.Context, records []vectordata.Record, mode writeMode) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if mode == writeModeInsert {
+		seen := make(map[string]struct{}, len(records))
+		for _, record := range records {
+			if _, ok := seen[record.ID]; ok {
+				return fmt.Errorf("%w: record %q appears twice in the batch", vectordata.ErrConflict, record.ID)
+			}
+			seen[record.ID] = struct{}{}
+		}
+	}
+
+	for start := 0; start < len(records); start += maxDocumentsPerRequest {
+		end := start + maxDocumentsPerRequest
+		if end > len(records) {
+			end = len(records)
+		}
+		chunk := records[start:end]
+
+		documents := make([]map[string]any, 0, len(chunk))
+		for _, record := range chunk {
+			doc, err := c.buildDocument(record)
+			if err != nil {
+				return err
+			}
+			documents = append(documents, doc)
+		}
+
+		if mode == writeModeInsert {
+			if err := c.ensureNotExisting(ctx, chunk); err != nil {
+				return err
+			}
+		}
+
+		path := indexPath(c.name, "documents") + "?primaryKey=" + url.QueryEscape(primaryKeyField)
+		if _, err := c.store.client.doTask(ctx, http.MethodPost, path, documents); err != nil {
+			return fmt.Errorf("write documents: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureNotExisting emulates insert-only semantics, which Meilisearch lacks,
+// by looking the IDs up before the write; see Insert.
+func (c *MeilisearchCollection) ensureNotExisting(ctx context.Context, records []vectordata.Record) error {
+	values := make([]any, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.ID)
+	}
+	expr, err := compileFilterExpression(vectordata.In(vectordata.Column("id"), values...))
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Results []storedDocument `json:"results"`
+	}
+	if err := c.store.client.do(ctx, http.MethodPost, indexPath(c.name, "documents", "fetch"), map[string]any{
+		"filter": expr,
+		"limit":  1,
+		"fields": []string{idField},
+	}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Results) > 0 {
+		return fmt.Errorf("%w: record %q already exists", vectordata.ErrConflict, resp.Results[0].RecordID)
+	}
+	return nil
+}
+
+func (c *MeilisearchCollection) buildDocument(record vectordata.Record) (map[string]any, error) {
+	if strings.TrimSpace(record.ID) == "" {
+		return nil, fmt.Errorf("record id is empty")
+	}
+	if err := c.validateVectorDimension(record.Vector); err != nil {
+		return nil, err
+	}
+
+	doc := map[string]any{
+		primaryKeyField: documentKey(record.ID),
+		idField:         record.ID,
+		metadataField:   normalizeMetadata(record.Metadata),
+		vectorsField:    map[string]any{embedderName: record.Vector},
+	}
+	if record.Content != nil {
+		doc[contentField] = *record.Content
+	}
+	return doc, nil
+}
+
+func (c *MeilisearchCollection) validateVectorDimension(vector []float32) error {
+	if len(vector) != c.dimension {
+		return fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, c.dimension, len(vector))
+	}
+	return nil
+}
+
+// decodeVector reads a retrieved _vectors entry, which is either a bare array
+// or an object whose embeddings hold one or more vectors.
+func decodeVector(raw json.RawMessage) ([]float32, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var vector []float32
+	if err := json.Unmarshal(raw, &vector); err == nil {
+		return vector, nil
+	}
+
+	var wrapped struct {
+		Embeddings json.RawMessage `json:"embeddings"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, fmt.Errorf("decode vector: %w", err)
+	}
+	var many [][]float32
+	if err := json.Unmarshal(wrapped.Embeddings, &many); err == nil {
+		if len(many) == 0 {
+			return nil, nil
+		}
+		return many[0], nil
+	}
+	if err := json.Unmarshal(wrapped.Embeddings, &vector); err != nil {
+		return nil, fmt.Errorf("decode vector: %w", err)
+	}
+	return vector, nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
+	if projection == nil {
+		return vectordata.DefaultProjection()
+	}
+	return *projection
+}
@@ -0,0 +1,333 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type fakeMeilisearch struct {
+	mu        sync.Mutex
+	documents map[string]map[string]any
+	settings  map[string]any
+	searches  []map[string]any
+	fetches   []map[string]any
+	tasks     []map[string]any
+	authz     []string
+}
+
+func newFakeMeilisearch(t *testing.T) (*fakeMeilisearch, *MeilisearchVectorStore) {
+	t.Helper()
+	fake := &fakeMeilisearch{documents: map[string]map[string]any{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+
+	opts := DefaultStoreOptions()
+	opts.Endpoint = server.URL
+	opts.APIKey = "secret"
+	opts.TaskPollInterval = time.Millisecond
+	store, err := NewVectorStore(opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return fake, store
+}
+
+func (f *fakeMeilisearch) enqueue(w http.ResponseWriter, details map[string]any) {
+	f.tasks = append(f.tasks, map[string]any{"uid": len(f.tasks), "status": "succeeded", "details": details})
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{"taskUid": len(f.tasks) - 1, "status": "enqueued"})
+}
+
+func (f *fakeMeilisearch) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.authz = append(f.authz, r.Header.Get("Authorization"))
+
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/tasks/"):
+		var uid int
+		_ = json.Unmarshal([]byte(strings.TrimPrefix(path, "/tasks/")), &uid)
+		_ = json.NewEncoder(w).Encode(f.tasks[uid])
+	case path == "/indexes/docs" && r.Method == http.MethodGet:
+		if f.settings == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Index docs not found.","code":"index_not_found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"uid": "docs", "primaryKey": primaryKeyField})
+	case path == "/indexes" && r.Method == http.MethodPost:
+		f.settings = map[string]any{}
+		f.enqueue(w, nil)
+	case path == "/indexes/docs/settings" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.settings)
+	case path == "/indexes/docs/settings" && r.Method == http.MethodPatch:
+		var patch map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		for k, v := range patch {
+			f.settings[k] = v
+		}
+		f.enqueue(w, nil)
+	case path == "/indexes/docs/search":
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.searches = append(f.searches, body)
+		hits := make([]map[string]any, 0, len(f.documents))
+		for _, doc := range f.documents {
+			vector := doc[vectorsField].(map[string]any)[embedderName]
+			hits = append(hits, map[string]any{
+				idField:       doc[idField],
+				metadataField: doc[metadataField],
+				vectorsField:  map[string]any{embedderName: map[string]any{"embeddings": []any{vector}, "regenerate": false}},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"hits": hits})
+	case path == "/indexes/docs/documents/fetch":
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.fetches = append(f.fetches, body)
+		results := []map[string]any{}
+		filter, _ := body["filter"].(string)
+		for _, doc := range f.documents {
+			if strings.Contains(filter, `"`+doc[idField].(string)+`"`) {
+				results = append(results, map[string]any{idField: doc[idField]})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": results, "total": len(f.documents)})
+	case path == "/indexes/docs/documents/delete-batch":
+		var keys []string
+		_ = json.NewDecoder(r.Body).Decode(&keys)
+		deleted := 0
+		for _, key := range keys {
+			if _, ok := f.documents[key]; ok {
+				delete(f.documents, key)
+				deleted++
+			}
+		}
+		f.enqueue(w, map[string]any{"providedIds": len(keys), "deletedDocuments": deleted})
+	case path == "/indexes/docs/documents" && r.Method == http.MethodPost:
+		var docs []map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&docs)
+		for _, doc := range docs {
+			f.documents[doc[primaryKeyField].(string)] = doc
+		}
+		f.enqueue(w, map[string]any{"receivedDocuments": len(docs)})
+	case strings.HasPrefix(path, "/indexes/docs/documents/"):
+		doc, ok := f.documents[strings.TrimPrefix(path, "/indexes/docs/documents/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Document not found.","code":"document_not_found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(doc)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMeilisearchCollection_UpsertGetDelete(t *testing.T) {
+	// Arrange
+	fake, store := newFakeMeilisearch(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	content := "hello"
+
+	// Act
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "doc/1", Vector: []float32{1, 0}, Content: &content, Metadata: map[string]any{"category": "news"}},
+	})
+	rec, getErr := collection.Get(ctx, "doc/1")
+	deleted, deleteErr := collection.Delete(ctx, []string{"doc/1", "missing"})
+	_, missingErr := collection.Get(ctx, "doc/1")
+
+	// Assert
+	if upsertErr != nil {
+		t.Fatalf("Upsert: %v", upsertErr)
+	}
+	if getErr != nil {
+		t.Fatalf("Get: %v", getErr)
+	}
+	if rec.ID != "doc/1" || len(rec.Vector) != 2 || rec.Vector[0] != 1 {
+		t.Fatalf("unexpected record: %#v", rec)
+	}
+	if rec.Content == nil || *rec.Content != "hello" || rec.Metadata["category"] != "news" {
+		t.Fatalf("unexpected record payload: %#v", rec)
+	}
+	if deleteErr != nil || deleted != 1 {
+		t.Fatalf("expected 1 deleted, got %d (%v)", deleted, deleteErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+	for _, header := range fake.authz {
+		if header != "Bearer secret" {
+			t.Fatalf("expected bearer token on every request, got %q", header)
+		}
+	}
+}
+
+func TestMeilisearchCollection_InsertRejectsExisting(t *testing.T) {
+	// Arrange
+	_, store := newFakeMeilisearch(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	record := vectordata.Record{ID: "r1", Vector: []float32{1, 0}}
+	if err := collection.Insert(ctx, []vectordata.Record{record}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	err := collection.Insert(ctx, []vectordata.Record{record})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestMeilisearchCollection_InsertRejectsDuplicatesInBatch(t *testing.T) {
+	// Arrange
+	fake, store := newFakeMeilisearch(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	record := vectordata.Record{ID: "r1", Vector: []float32{1, 0}}
+
+	// Act
+	err := collection.Insert(context.Background(), []vectordata.Record{record, record})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if len(fake.documents) != 0 || len(fake.fetches) != 0 {
+		t.Fatalf("expected no requests, got %d documents and %d fetches", len(fake.documents), len(fake.fetches))
+	}
+}
+
+func TestMeilisearchCollection_SearchByVector(t *testing.T) {
+	// Arrange
+	fake, store := newFakeMeilisearch(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "a"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"kind": "b"}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	threshold := 0.5
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "a"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(results) != 1 || results[0].Record.ID != "a" || results[0].Distance != 0 || results[0].Score != 1 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results[0].Record.Vector != nil {
+		t.Fatalf("expected vector to be omitted by default projection")
+	}
+	search := fake.searches[0]
+	hybrid, _ := search["hybrid"].(map[string]any)
+	if search["filter"] != `metadata.kind = "a"` || hybrid["embedder"] != embedderName || hybrid["semanticRatio"] != float64(1) {
+		t.Fatalf("unexpected search body: %#v", search)
+	}
+}
+
+func TestMeilisearchVectorStore_EnsureCollection(t *testing.T) {
+	// Arrange
+	fake, store := newFakeMeilisearch(t)
+	ctx := context.Background()
+
+	// Act
+	_, createErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+	_, reopenErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+	_, dimErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 4})
+	_, metricErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceL2})
+	_, nameErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "bad name", Dimension: 3})
+
+	// Assert
+	if createErr != nil {
+		t.Fatalf("EnsureCollection create: %v", createErr)
+	}
+	if reopenErr != nil {
+		t.Fatalf("EnsureCollection reopen: %v", reopenErr)
+	}
+	if !errors.Is(dimErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected dimension mismatch, got %v", dimErr)
+	}
+	if !errors.Is(metricErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected metric mismatch, got %v", metricErr)
+	}
+	if !errors.Is(nameErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected invalid name error, got %v", nameErr)
+	}
+	filterable, _ := fake.settings["filterableAttributes"].([]any)
+	if len(filterable) != len(requiredFilterableAttributes) {
+		t.Fatalf("unexpected filterable attributes: %#v", fake.settings)
+	}
+}
+
+func TestMeilisearchVectorStore_EnsureCollectionAutoMigratesFilterable(t *testing.T) {
+	// Arrange
+	fake, store := newFakeMeilisearch(t)
+	fake.settings = map[string]any{
+		"filterableAttributes": []any{"tenant"},
+		"embedders":            map[string]any{embedderName: map[string]any{"source": "userProvided", "dimensions": 3}},
+	}
+	ctx := context.Background()
+
+	// Act
+	_, strictErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+	_, migrateErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3, Mode: vectordata.EnsureAutoMigrate})
+
+	// Assert
+	if !errors.Is(strictErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected strict mode to reject missing filterable attributes, got %v", strictErr)
+	}
+	if migrateErr != nil {
+		t.Fatalf("EnsureCollection auto-migrate: %v", migrateErr)
+	}
+	filterable, _ := fake.settings["filterableAttributes"].([]any)
+	if len(filterable) != 4 || filterable[0] != "tenant" {
+		t.Fatalf("expected existing attributes to be preserved: %#v", filterable)
+	}
+}
+
+func TestMeilisearchCollection_ValidatesInput(t *testing.T) {
+	// Arrange
+	_, store := newFakeMeilisearch(t)
+	collection := store.Collection("docs", 3, vectordata.DistanceCosine)
+	ctx := context.Background()
+
+	// Act
+	_, dimErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{})
+	writeErr := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1}}})
+	indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodIVFFlat}})
+
+	// Assert
+	if !errors.Is(dimErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from search, got %v", dimErr)
+	}
+	if !errors.Is(writeErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from write, got %v", writeErr)
+	}
+	if !errors.Is(indexErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for IVFFlat, got %v", indexErr)
+	}
+}
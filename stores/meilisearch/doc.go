@@ -0,0 +1,2 @@
+// Package meilisearch provides a Meilisearch-backed vectordata implementation.
+package meilisearch
@@ -0,0 +1,149 @@
+package meilisearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// compileFilterExpression translates a filter AST into a Meilisearch filter expression.
+func compileFilterExpression(filter vectordata.Filter) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	return compileFilterNode(filter)
+}
+
+func compileFilterNode(f vectordata.Filter) (string, error) {
+	switch node := f.(type) {
+	case vectordata.EqFilter:
+		field, err := filterField(node.Field)
+		if err != nil {
+			return "", err
+		}
+		value, err := filterValue(node.Value)
+		if err != nil {
+			return "", err
+		}
+		return field + " = " + value, nil
+	case vectordata.InFilter:
+		if len(node.Values) == 0 {
+			return "", fmt.Errorf("%w: IN requires at least one value", vectordata.ErrInvalidFilter)
+		}
+		field, err := filterField(node.Field)
+		if err != nil {
+			return "", err
+		}
+		values := make([]string, 0, len(node.Values))
+		for _, v := range node.Values {
+			value, err := filterValue(v)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		return field + " IN [" + strings.Join(values, ", ") + "]", nil
+	case vectordata.GtFilter:
+		return compileFilterCompare(node.Field, ">", node.Value)
+	case vectordata.LtFilter:
+		return compileFilterCompare(node.Field, "<", node.Value)
+	case vectordata.ExistsFilter:
+		field, err := filterField(node.Field)
+		if err != nil {
+			return "", err
+		}
+		return field + " EXISTS", nil
+	case vectordata.AndFilter:
+		return compileFilterLogical("AND", node.Children)
+	case vectordata.OrFilter:
+		return compileFilterLogical("OR", node.Children)
+	case vectordata.NotFilter:
+		if node.Child == nil {
+			return "", fmt.Errorf("%w: NOT requires a child", vectordata.ErrInvalidFilter)
+		}
+		child, err := compileFilterNode(node.Child)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + child + ")", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported node type %T", vectordata.ErrInvalidFilter, f)
+	}
+}
+
+func compileFilterCompare(ref vectordata.FieldRef, op string, value any) (string, error) {
+	field, err := filterField(ref)
+	if err != nil {
+		return "", err
+	}
+	num, ok := toFloat64(value)
+	if !ok {
+		return "", fmt.Errorf("%w: range comparison requires a numeric value, got %T", vectordata.ErrInvalidFilter, value)
+	}
+	return field + " " + op + " " + strconv.FormatFloat(num, 'f', -1, 64), nil
+}
+
+func compileFilterLogical(op string, children []vectordata.Filter) (string, error) {
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: logical filter requires at least one child", vectordata.ErrInvalidFilter)
+	}
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		if child == nil {
+			return "", fmt.Errorf("%w: logical filter contains nil child", vectordata.ErrInvalidFilter)
+		}
+		part, err := compileFilterNode(child)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "("+part+")")
+	}
+	return strings.Join(parts, " "+op+" "), nil
+}
+
+func filterField(ref vectordata.FieldRef) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		switch field.Name {
+		case "id":
+			return idField, nil
+		case contentField:
+			return contentField, nil
+		default:
+			return "", fmt.Errorf("%w: unknown column %q", vectordata.ErrInvalidFilter, field.Name)
+		}
+	}
+	for _, segment := range field.Path {
+		if !fieldSegmentPattern.MatchString(segment) {
+			return "", fmt.Errorf("%w: metadata path segment %q contains unsupported characters", vectordata.ErrInvalidFilter, segment)
+		}
+	}
+	return metadataField + "." + strings.Join(field.Path, "."), nil
+}
+
+func filterValue(v any) (string, error) {
+	switch typed := v.(type) {
+	case nil:
+		return "", fmt.Errorf("%w: nil values are not supported", vectordata.ErrInvalidFilter)
+	case string:
+		return quoteString(typed), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	default:
+		num, ok := toFloat64(v)
+		if !ok {
+			return "", fmt.Errorf("%w: unsupported value type %T", vectordata.ErrInvalidFilter, v)
+		}
+		return strconv.FormatFloat(num, 'f', -1, 64), nil
+	}
+}
+
+func quoteString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(s) + `"`
+}
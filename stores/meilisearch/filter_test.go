@@ -0,0 +1,70 @@
+package meilisearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestCompileFilterExpression_Complex(t *testing.T) {
+	// Arrange
+	filter := vectordata.And(
+		vectordata.Eq(vectordata.Column("id"), "r1"),
+		vectordata.Or(
+			vectordata.Gt(vectordata.Metadata("rank"), 10),
+			vectordata.Exists(vectordata.Metadata("flags", "pinned")),
+		),
+		vectordata.Not(vectordata.In(vectordata.Metadata("category"), "a", true, 3)),
+	)
+
+	// Act
+	expr, err := compileFilterExpression(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterExpression error: %v", err)
+	}
+	expected := `(record_id = "r1") AND ((metadata.rank > 10) OR (metadata.flags.pinned EXISTS)) AND (NOT (metadata.category IN ["a", true, 3]))`
+	if expr != expected {
+		t.Fatalf("unexpected filter\nwant: %s\n got: %s", expected, expr)
+	}
+}
+
+func TestCompileFilterExpression_EscapesStrings(t *testing.T) {
+	// Arrange
+	filter := vectordata.Eq(vectordata.Metadata("title"), `say "hi" \ bye`)
+
+	// Act
+	expr, err := compileFilterExpression(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterExpression error: %v", err)
+	}
+	expected := `metadata.title = "say \"hi\" \\ bye"`
+	if expr != expected {
+		t.Fatalf("unexpected filter\nwant: %s\n got: %s", expected, expr)
+	}
+}
+
+func TestCompileFilterExpression_RejectsUnsupported(t *testing.T) {
+	cases := map[string]vectordata.Filter{
+		"segment":   vectordata.Eq(vectordata.Metadata("a b"), "x"),
+		"range":     vectordata.Lt(vectordata.Metadata("a"), "x"),
+		"column":    vectordata.Eq(vectordata.Column("vector"), "x"),
+		"empty in":  vectordata.In(vectordata.Metadata("a")),
+		"nil value": vectordata.Eq(vectordata.Metadata("a"), nil),
+	}
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := compileFilterExpression(filter)
+
+			// Assert
+			if !errors.Is(err, vectordata.ErrInvalidFilter) {
+				t.Fatalf("expected ErrInvalidFilter, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package meilisearch
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	// primaryKeyField holds an encoded form of the record ID, because Meilisearch
+	// document IDs are limited to [A-Za-z0-9_-].
+	primaryKeyField = "key"
+	idField         = "record_id"
+	metadataField   = "metadata"
+	contentField    = "content"
+	vectorsField    = "_vectors"
+
+	// embedderName is the userProvided embedder that stores collection vectors.
+	embedderName = "default"
+
+	// maxDocumentsPerRequest bounds the payload of a single document request.
+	maxDocumentsPerRequest = 1000
+)
+
+var (
+	indexUIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_-]{1,400}$`)
+	fieldSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+func defaultMetric(metric vectordata.DistanceMetric) vectordata.DistanceMetric {
+	if metric == "" {
+		return vectordata.DistanceCosine
+	}
+	return metric
+}
+
+func defaultMode(mode vectordata.EnsureMode, strictByDefault bool) vectordata.EnsureMode {
+	if mode != "" {
+		return mode
+	}
+	if strictByDefault {
+		return vectordata.EnsureStrict
+	}
+	return vectordata.EnsureAutoMigrate
+}
+
+// validateMetric rejects metrics other than cosine, the only similarity
+// Meilisearch uses for nearest-neighbor retrieval.
+func validateMetric(metric vectordata.DistanceMetric) error {
+	if metric != vectordata.DistanceCosine {
+		return fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+	return nil
+}
+
+func documentKey(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func indexPath(uid string, parts ...string) string {
+	path := "/indexes/" + url.PathEscape(uid)
+	for _, part := range parts {
+		path += "/" + part
+	}
+	return path
+}
+
+func normalizeMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return map[string]any{}
+	}
+	return metadata
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
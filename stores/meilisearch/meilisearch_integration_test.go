@@ -0,0 +1,203 @@
+//go:build integration
+
+package meilisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const defaultIntegrationAPIKey = "vectorstore-test-master-key"
+
+var (
+	integrationEndpoint  string
+	integrationAPIKey    string
+	integrationContainer testcontainers.Container
+)
+
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	integrationEndpoint = strings.TrimSpace(os.Getenv("MEILISEARCH_TEST_ENDPOINT"))
+	integrationAPIKey = strings.TrimSpace(os.Getenv("MEILISEARCH_TEST_API_KEY"))
+	if integrationAPIKey == "" {
+		integrationAPIKey = defaultIntegrationAPIKey
+	}
+	if integrationEndpoint == "" {
+		container, endpoint, err := startMeilisearchContainer(ctx, integrationAPIKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start integration container: %v\n", err)
+			os.Exit(1)
+		}
+		integrationContainer = container
+		integrationEndpoint = endpoint
+	}
+
+	exitCode := m.Run()
+
+	if integrationContainer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		if err := integrationContainer.Terminate(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to terminate integration container: %v\n", err)
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+func startMeilisearchContainer(ctx context.Context, apiKey string) (testcontainers.Container, string, error) {
+	request := testcontainers.ContainerRequest{
+		Image:        "getmeili/meilisearch:v1.13",
+		ExposedPorts: []string{"7700/tcp"},
+		Env: map[string]string{
+			"MEILI_MASTER_KEY":   apiKey,
+			"MEILI_NO_ANALYTICS": "true",
+		},
+		WaitingFor: wait.ForHTTP("/health").
+			WithPort("7700/tcp").
+			WithStartupTimeout(2 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: request,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("start meilisearch container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", fmt.Errorf("resolve container host: %w", err)
+	}
+	port, err := container.MappedPort(ctx, "7700/tcp")
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", fmt.Errorf("resolve container port: %w", err)
+	}
+
+	return container, fmt.Sprintf("http://%s:%s", host, port.Port()), nil
+}
+
+func newTestStore(t *testing.T) *MeilisearchVectorStore {
+	t.Helper()
+	opts := DefaultStoreOptions()
+	opts.Endpoint = integrationEndpoint
+	opts.APIKey = integrationAPIKey
+	store, err := NewVectorStore(opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return store
+}
+
+func uniqueIndexName(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, time.Now().UnixNano())
+}
+
+func dropIndex(t *testing.T, store *MeilisearchVectorStore, name string) {
+	t.Helper()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_, _ = store.client.doTask(ctx, "DELETE", indexPath(name), nil)
+	})
+}
+
+func TestIntegrationEnsureCollection(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	name := uniqueIndexName("docs")
+	dropIndex(t, store, name)
+
+	// Act
+	_, createErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	_, reopenErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	_, dimErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 3})
+	_, metricErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2, Metric: vectordata.DistanceL2})
+
+	// Assert
+	if createErr != nil {
+		t.Fatalf("EnsureCollection create: %v", createErr)
+	}
+	if reopenErr != nil {
+		t.Fatalf("EnsureCollection reopen: %v", reopenErr)
+	}
+	if !errors.Is(dimErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected dimension mismatch, got %v", dimErr)
+	}
+	if !errors.Is(metricErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected metric mismatch, got %v", metricErr)
+	}
+}
+
+func TestIntegrationCRUDAndSearch(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	name := uniqueIndexName("search")
+	dropIndex(t, store, name)
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+
+	err = collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "a", "rank": 1}},
+		{ID: "b", Vector: []float32{0.8, 0.6}, Metadata: map[string]any{"kind": "b", "rank": 2}},
+		{ID: "c", Vector: []float32{0, 1}, Metadata: map[string]any{"kind": "c", "rank": 3}},
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	results, searchErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{})
+	filtered, filterErr := collection.SearchByVector(ctx, []float32{1, 0}, 3, vectordata.SearchOptions{
+		Filter: vectordata.Not(vectordata.Eq(vectordata.Metadata("kind"), "a")),
+	})
+	count, countErr := collection.Count(ctx, vectordata.Gt(vectordata.Metadata("rank"), 1))
+	deleted, deleteErr := collection.Delete(ctx, []string{"a", "missing"})
+	_, getErr := collection.Get(ctx, "a")
+
+	// Assert
+	if searchErr != nil {
+		t.Fatalf("SearchByVector: %v", searchErr)
+	}
+	if len(results) != 2 || results[0].Record.ID != "a" || results[1].Record.ID != "b" {
+		t.Fatalf("unexpected ordering: %#v", results)
+	}
+	if filterErr != nil {
+		t.Fatalf("SearchByVector with filter: %v", filterErr)
+	}
+	if len(filtered) != 2 || filtered[0].Record.ID != "b" {
+		t.Fatalf("unexpected filtered results: %#v", filtered)
+	}
+	if countErr != nil || count != 2 {
+		t.Fatalf("expected count 2, got %d (%v)", count, countErr)
+	}
+	if deleteErr != nil || deleted != 1 {
+		t.Fatalf("expected 1 deleted, got %d (%v)", deleted, deleteErr)
+	}
+	if !errors.Is(getErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", getErr)
+	}
+}
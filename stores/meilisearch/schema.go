@@ -0,0 +1,137 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// requiredFilterableAttributes lists the attributes the filter compiler relies on.
+// Declaring metadata as filterable makes every nested metadata key filterable.
+var requiredFilterableAttributes = []string{idField, metadataField, contentField}
+
+type embedderSettings struct {
+	Source     string `json:"source"`
+	Dimensions int    `json:"dimensions"`
+}
+
+type indexSettings struct {
+	FilterableAttributes []json.RawMessage           `json:"filterableAttributes"`
+	Embedders            map[string]embedderSettings `json:"embedders"`
+}
+
+// filterablePatterns returns the declared filterable attributes, accepting
+// both plain attribute names and granular attributePatterns objects.
+func (s indexSettings) filterablePatterns() []string {
+	patterns := make([]string, 0, len(s.FilterableAttributes))
+	for _, raw := range s.FilterableAttributes {
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			patterns = append(patterns, name)
+			continue
+		}
+		var granular struct {
+			AttributePatterns []string `json:"attributePatterns"`
+		}
+		if err := json.Unmarshal(raw, &granular); err == nil {
+			patterns = append(patterns, granular.AttributePatterns...)
+		}
+	}
+	return patterns
+}
+
+func (s *MeilisearchVectorStore) indexExists(ctx context.Context, uid string) (bool, error) {
+	var index struct {
+		PrimaryKey string `json:"primaryKey"`
+	}
+	err := s.client.do(ctx, http.MethodGet, indexPath(uid), nil, &index)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("read index: %w", err)
+	}
+	if index.PrimaryKey != "" && index.PrimaryKey != primaryKeyField {
+		return false, fmt.Errorf("%w: expected primary key %q, got %q", vectordata.ErrSchemaMismatch, primaryKeyField, index.PrimaryKey)
+	}
+	return true, nil
+}
+
+func (s *MeilisearchVectorStore) createIndex(ctx context.Context, spec vectordata.CollectionSpec) error {
+	if _, err := s.client.doTask(ctx, http.MethodPost, "/indexes", map[string]any{
+		"uid":        spec.Name,
+		"primaryKey": primaryKeyField,
+	}); err != nil {
+		return fmt.Errorf("create index %q: %w", spec.Name, err)
+	}
+	return s.updateSettings(ctx, spec.Name, map[string]any{
+		"filterableAttributes": requiredFilterableAttributes,
+		"embedders": map[string]embedderSettings{
+			embedderName: {Source: "userProvided", Dimensions: spec.Dimension},
+		},
+	})
+}
+
+func (s *MeilisearchVectorStore) validateIndexSettings(ctx context.Context, spec vectordata.CollectionSpec, mode vectordata.EnsureMode) error {
+	var settings indexSettings
+	if err := s.client.do(ctx, http.MethodGet, indexPath(spec.Name, "settings"), nil, &settings); err != nil {
+		return fmt.Errorf("read index settings: %w", err)
+	}
+
+	patch := map[string]any{}
+	embedder, ok := settings.Embedders[embedderName]
+	switch {
+	case !ok:
+		if mode == vectordata.EnsureStrict {
+			return fmt.Errorf("%w: missing embedder %q", vectordata.ErrSchemaMismatch, embedderName)
+		}
+		patch["embedders"] = map[string]embedderSettings{
+			embedderName: {Source: "userProvided", Dimensions: spec.Dimension},
+		}
+	case embedder.Source != "userProvided":
+		return fmt.Errorf("%w: expected embedder source userProvided, got %q", vectordata.ErrSchemaMismatch, embedder.Source)
+	case embedder.Dimensions != spec.Dimension:
+		return fmt.Errorf("%w: expected vector dimension %d, got %d", vectordata.ErrSchemaMismatch, spec.Dimension, embedder.Dimensions)
+	}
+
+	existing := settings.filterablePatterns()
+	declared := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		declared[name] = true
+	}
+	filterable := make([]any, 0, len(settings.FilterableAttributes)+len(requiredFilterableAttributes))
+	for _, raw := range settings.FilterableAttributes {
+		filterable = append(filterable, raw)
+	}
+	missing := false
+	for _, name := range requiredFilterableAttributes {
+		if declared[name] {
+			continue
+		}
+		if mode == vectordata.EnsureStrict {
+			return fmt.Errorf("%w: attribute %q is not filterable", vectordata.ErrSchemaMismatch, name)
+		}
+		filterable = append(filterable, name)
+		missing = true
+	}
+	if missing {
+		patch["filterableAttributes"] = filterable
+	}
+
+	if len(patch) == 0 {
+		return nil
+	}
+	return s.updateSettings(ctx, spec.Name, patch)
+}
+
+func (s *MeilisearchVectorStore) updateSettings(ctx context.Context, uid string, settings map[string]any) error {
+	if _, err := s.client.doTask(ctx, http.MethodPatch, indexPath(uid, "settings"), settings); err != nil {
+		return fmt.Errorf("update index settings: %w", err)
+	}
+	return nil
+}
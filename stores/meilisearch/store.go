@@ -0,0 +1,144 @@
+package meilisearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// StoreOptions configures MeilisearchVectorStore behavior.
+type StoreOptions struct {
+	// Endpoint is the base URL of the Meilisearch server.
+	Endpoint string
+	// APIKey is sent as a bearer token on every request.
+	APIKey string
+	// HTTPClient is used for all requests. A client with a 30s timeout is used when nil.
+	HTTPClient *http.Client
+	// TaskPollInterval controls how often asynchronous tasks are polled until they finish.
+	TaskPollInterval time.Duration
+	StrictByDefault  bool
+}
+
+// DefaultStoreOptions returns production-safe defaults.
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		Endpoint:         "http://localhost:7700",
+		TaskPollInterval: 50 * time.Millisecond,
+		StrictByDefault:  true,
+	}
+}
+
+// MeilisearchVectorStore implements vectordata.VectorStore on top of the Meilisearch HTTP API.
+type MeilisearchVectorStore struct {
+	client *client
+	opts   StoreOptions
+}
+
+// NewVectorStore creates a Meilisearch-backed vector store.
+func NewVectorStore(opts StoreOptions) (*MeilisearchVectorStore, error) {
+	normalized := opts.withDefaults()
+	if err := normalized.validate(); err != nil {
+		return nil, err
+	}
+	return &MeilisearchVectorStore{
+		client: newClient(normalized.Endpoint, normalized.APIKey, normalized.HTTPClient, normalized.TaskPollInterval),
+		opts:   normalized,
+	}, nil
+}
+
+// Collection returns a handle to an index without schema checks.
+func (s *MeilisearchVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.newCollectionHandle(name, dimension, metric)
+}
+
+// EnsureCollection creates or validates an index and its settings and returns its handle.
+func (s *MeilisearchVectorStore) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureIndexWithValidation(ctx, normalizedSpec, mode); err != nil {
+		return nil, err
+	}
+
+	return s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric), nil
+}
+
+func (s *MeilisearchVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, vectordata.EnsureMode, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
+	}
+	if !indexUIDPattern.MatchString(spec.Name) {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name %q is not a valid index uid", vectordata.ErrSchemaMismatch, spec.Name)
+	}
+	if spec.Dimension <= 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	spec.Metric = defaultMetric(spec.Metric)
+	if err := validateMetric(spec.Metric); err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
+	if spec.NormalizeVectors {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: NormalizeVectors is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.ElementType != "" && spec.ElementType != vectordata.ElementFloat32 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: vector element type %q is not supported", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension != 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.MaxRecords != 0 || spec.EvictBy != "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: MaxRecords is not supported", vectordata.ErrSchemaMismatch)
+	}
+
+	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
+	}
+	return spec, mode, nil
+}
+
+func (s *MeilisearchVectorStore) ensureIndexWithValidation(ctx context.Context, spec vectordata.CollectionSpec, mode vectordata.EnsureMode) error {
+	exists, err := s.indexExists(ctx, spec.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return s.createIndex(ctx, spec)
+	}
+	return s.validateIndexSettings(ctx, spec, mode)
+}
+
+func (s *MeilisearchVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return &MeilisearchCollection{
+		store:     s,
+		name:      name,
+		dimension: dimension,
+		metric:    defaultMetric(metric),
+	}
+}
+
+func (o StoreOptions) withDefaults() StoreOptions {
+	defaults := DefaultStoreOptions()
+	o.Endpoint = strings.TrimRight(strings.TrimSpace(o.Endpoint), "/")
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if o.TaskPollInterval <= 0 {
+		o.TaskPollInterval = defaults.TaskPollInterval
+	}
+	return o
+}
+
+func (o StoreOptions) validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("%w: endpoint is empty", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.Archiver = (*PostgresCollection)(nil)
+
+// archiveSuffix names the cold table of a collection: <collection>_archive.
+const archiveSuffix = "_archive"
+
+func (c *PostgresCollection) archiveTableName() string {
+	return qualifiedTable(c.store.opts.Schema, c.name+archiveSuffix)
+}
+
+// Archive moves the records matching filter into the collection's cold
+// table in one statement, creating the table on first use. The cold table
+// has the collection's columns and primary key but no other indexes, so
+// archived records cost no index maintenance and are never searched.
+func (c *PostgresCollection) Archive(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	if filter == nil {
+		return 0, fmt.Errorf("%w: Archive requires a filter", vectordata.ErrInvalidFilter)
+	}
+	if err := c.ensureArchiveTable(ctx); err != nil {
+		return 0, err
+	}
+	return c.moveRecords(ctx, "Archive", c.tableName(), c.archiveTableName(), filter)
+}
+
+// Restore moves the archived records matching filter back into the
+// collection, replacing records with the same ID.
+func (c *PostgresCollection) Restore(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	if filter == nil {
+		return 0, fmt.Errorf("%w: Restore requires a filter", vectordata.ErrInvalidFilter)
+	}
+	moved, err := c.moveRecords(ctx, "Restore", c.archiveTableName(), c.tableName(), filter)
+	if isUndefinedTable(err) {
+		return 0, nil
+	}
+	return moved, err
+}
+
+// GetArchived returns an archived record, or vectordata.ErrNotFound.
+func (c *PostgresCollection) GetArchived(ctx context.Context, id string) (vectordata.Record, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	projection := vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1`,
+		strings.Join(c.projectedColumns(projection), ", "), c.archiveTableName(), quoteIdent(idColumn))
+	plan := searchPlan{query: query, args: []any{id}, projection: projection, rank: rankNone}
+	results, err := c.executeSearchPlan(ctx, queryStats{op: "GetArchived", started: started}, plan)
+	if isUndefinedTable(err) || (err == nil && len(results) == 0) {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	return results[0].Record, nil
+}
+
+// ensureArchiveTable creates the cold table like the collection table, with
+// its primary key and, when the store uses RLS, its tenant policy.
+func (c *PostgresCollection) ensureArchiveTable(ctx context.Context) error {
+	table := c.name + archiveSuffix
+	key := quoteIdent(idColumn)
+	if c.partition != nil {
+		key += ", " + quoteIdent(c.partition.key)
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS, PRIMARY KEY (%s))`,
+		c.archiveTableName(), c.tableName(), key)
+	return c.withSchemaLock(ctx, advisoryLockKey(c.store.opts.Schema, c.name), func(db schemaExecutor) error {
+		if err := c.store.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("create archive table %q: %w", table, err)
+		}
+		if c.store.opts.RowLevelSecurity != nil {
+			return c.store.enableRowLevelSecurity(ctx, db, table, c.partition)
+		}
+		return nil
+	})
+}
+
+func (c *PostgresCollection) moveRecords(ctx context.Context, op, from, to string, filter vectordata.Filter) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	started := time.Now()
+	whereSQL, args, _, err := vectordata.CompileFilterSQL(filter, c.filterConfig(), 1)
+	if err != nil {
+		return 0, err
+	}
+	query := c.buildMoveQuery(from, to, whereSQL)
+
+	var moved int64
+	stats := queryStats{op: op, collection: c.name, query: query, args: args, filter: filter, started: started, executed: time.Now()}
+	err = c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, args...)
+		moved = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = moved, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// buildMoveQuery deletes the matching rows of from and inserts them into to
+// in one statement, so a record is never in neither table nor in both.
+func (c *PostgresCollection) buildMoveQuery(from, to, whereSQL string) string {
+	columns := []string{quoteIdent(idColumn), quoteIdent(vectorColumn), quoteIdent(metadataColumn), quoteIdent(contentColumn)}
+	conflict := quoteIdent(idColumn)
+	if c.partition != nil {
+		columns = append(columns, quoteIdent(c.partition.key))
+		conflict += ", " + quoteIdent(c.partition.key)
+	}
+	for _, column := range extraVectorColumns(c.elementType, c.fastDimension) {
+		columns = append(columns, quoteIdent(column.name))
+	}
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns[1:] {
+		if c.partition != nil && column == quoteIdent(c.partition.key) {
+			continue
+		}
+		updates = append(updates, column+" = EXCLUDED."+column)
+	}
+	list := strings.Join(columns, ", ")
+	return fmt.Sprintf(`WITH moved AS (DELETE FROM %s WHERE %s RETURNING %s) INSERT INTO %s (%s) SELECT %s FROM moved ON CONFLICT (%s) DO UPDATE SET %s`,
+		from, whereSQL, list, to, list, list, conflict, strings.Join(updates, ", "))
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_MoveQueryDeletesAndInsertsInOneStatement(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	query := collection.buildMoveQuery(collection.tableName(), collection.archiveTableName(), `"id" = $1`)
+
+	// Assert
+	expected := `WITH moved AS (DELETE FROM "public"."docs" WHERE "id" = $1 RETURNING "id", "vector", "metadata", "content") ` +
+		`INSERT INTO "public"."docs_archive" ("id", "vector", "metadata", "content") SELECT "id", "vector", "metadata", "content" FROM moved ` +
+		`ON CONFLICT ("id") DO UPDATE SET "vector" = EXCLUDED."vector", "metadata" = EXCLUDED."metadata", "content" = EXCLUDED."content"`
+	if query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, query)
+	}
+}
+
+func TestPostgresCollection_ArchiveRequiresFilter(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, archiveErr := collection.Archive(context.Background(), nil)
+	_, restoreErr := collection.Restore(context.Background(), nil)
+
+	// Assert
+	if !errors.Is(archiveErr, vectordata.ErrInvalidFilter) || !errors.Is(restoreErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v and %v", archiveErr, restoreErr)
+	}
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultAuditTable        = "__vector_audit"
+	defaultAuditActorSetting = "app.actor_id"
+	defaultAuditQueryLimit   = 100
+	auditTriggerName         = "__vector_audit"
+)
+
+// AuditOptions enables the audit log: every row inserted, updated or
+// deleted in a collection table is recorded in the audit table by a trigger,
+// in the same transaction as the change, with the actor set by WithActor.
+type AuditOptions struct {
+	// Table is the audit table in the store's schema (default
+	// __vector_audit). No collection may have this name.
+	Table string
+	// ActorSetting is the runtime parameter that carries the actor from
+	// WithActor to the trigger (default app.actor_id).
+	ActorSetting string
+}
+
+// AuditOperation is the kind of change an AuditEntry records. An Upsert is
+// recorded as an insert or an update, depending on whether the record
+// existed.
+type AuditOperation string
+
+const (
+	AuditInsert AuditOperation = "insert"
+	AuditUpdate AuditOperation = "update"
+	AuditDelete AuditOperation = "delete"
+)
+
+// AuditEntry is one recorded change.
+type AuditEntry struct {
+	// Seq orders the entries of a store; it increases with every entry but
+	// may have gaps.
+	Seq        int64
+	Collection string
+	Operation  AuditOperation
+	RecordID   string
+	// Actor is the actor set by WithActor, or empty for changes made
+	// without one, including those made outside the store.
+	Actor string
+	At    time.Time
+}
+
+// AuditQuery selects audit entries. Zero fields do not filter.
+type AuditQuery struct {
+	Collection string
+	RecordID   string
+	Actor      string
+	Operation  AuditOperation
+	// Since and Until bound At, inclusive and exclusive.
+	Since time.Time
+	Until time.Time
+	// After resumes after this Seq, typically the last Seq of the previous
+	// page.
+	After int64
+	// Limit is the page size (default 100).
+	Limit int
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context whose writes are attributed to actor in the
+// audit log, e.g. a user or service ID. Writes run in a transaction with the
+// store's ActorSetting set locally to actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorContextKey{}).(string)
+	return actor, ok
+}
+
+func (s *PostgresVectorStore) auditTableName() string {
+	return qualifiedTable(s.opts.Schema, s.opts.Audit.Table)
+}
+
+func (s *PostgresVectorStore) auditFuncName() string {
+	return qualifiedTable(s.opts.Schema, s.opts.Audit.Table+"_record")
+}
+
+// ensureAudit creates the audit table and the trigger function that
+// collection triggers call with the collection name.
+func (s *PostgresVectorStore) ensureAudit(ctx context.Context, db schemaExecutor) error {
+	table := s.auditTableName()
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			seq bigserial PRIMARY KEY,
+			collection text NOT NULL,
+			operation text NOT NULL,
+			record_id text NOT NULL,
+			actor text,
+			at timestamptz NOT NULL DEFAULT now()
+		)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (collection, record_id)`,
+			quoteIdent(s.opts.Audit.Table+"_record_idx"), table),
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			INSERT INTO %s (collection, operation, record_id, actor)
+			VALUES (TG_ARGV[0], lower(TG_OP), CASE WHEN TG_OP = 'DELETE' THEN OLD.%s ELSE NEW.%s END,
+				nullif(current_setting(%s, true), ''));
+			RETURN NULL;
+		END $$`, s.auditFuncName(), table, quoteIdent(idColumn), quoteIdent(idColumn), quoteLiteral(s.opts.Audit.ActorSetting)),
+	}
+	for _, query := range statements {
+		if err := s.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("ensure audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureAuditTrigger attaches the audit trigger to a collection table unless
+// it is already there. On a partitioned table the trigger is cloned to every
+// partition.
+func (s *PostgresVectorStore) ensureAuditTrigger(ctx context.Context, db schemaExecutor, table string) error {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgrelid = to_regclass($1) AND tgname = $2)`,
+		qualifiedTable(s.opts.Schema, table), auditTriggerName,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check audit trigger: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	query := fmt.Sprintf(`CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s(%s)`,
+		quoteIdent(auditTriggerName), qualifiedTable(s.opts.Schema, table), s.auditFuncName(), quoteLiteral(table))
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("create audit trigger on %q: %w", table, err)
+	}
+	return nil
+}
+
+// AuditLog returns the audit entries matching query, oldest first.
+func (s *PostgresVectorStore) AuditLog(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	if s.opts.Audit == nil {
+		return nil, errors.New("audit log: StoreOptions.Audit is not set")
+	}
+	if query.Limit < 0 {
+		return nil, fmt.Errorf("audit log: limit must be >= 0, got %d", query.Limit)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultAuditQueryLimit
+	}
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Search)
+	defer cancel()
+
+	sql, args := s.buildAuditQuery(query)
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (AuditEntry, error) {
+		var entry AuditEntry
+		var operation string
+		var actor *string
+		if err := row.Scan(&entry.Seq, &entry.Collection, &operation, &entry.RecordID, &actor, &entry.At); err != nil {
+			return AuditEntry{}, err
+		}
+		entry.Operation = AuditOperation(operation)
+		if actor != nil {
+			entry.Actor = *actor
+		}
+		return entry, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *PostgresVectorStore) buildAuditQuery(query AuditQuery) (string, []any) {
+	conditions := []string{"seq > $1"}
+	args := []any{query.After}
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.Collection != "" {
+		add("collection = $%d", query.Collection)
+	}
+	if query.RecordID != "" {
+		add("record_id = $%d", query.RecordID)
+	}
+	if query.Actor != "" {
+		add("actor = $%d", query.Actor)
+	}
+	if query.Operation != "" {
+		add("operation = $%d", string(query.Operation))
+	}
+	if !query.Since.IsZero() {
+		add("at >= $%d", query.Since)
+	}
+	if !query.Until.IsZero() {
+		add("at < $%d", query.Until)
+	}
+	args = append(args, query.Limit)
+	sql := fmt.Sprintf(`SELECT seq, collection, operation, record_id, actor, at FROM %s WHERE %s ORDER BY seq LIMIT $%d`,
+		s.auditTableName(), strings.Join(conditions, " AND "), len(args))
+	return sql, args
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newAuditTestStore() *PostgresVectorStore {
+	opts := DefaultStoreOptions()
+	opts.Audit = &AuditOptions{}
+	return &PostgresVectorStore{opts: opts.withDefaults()}
+}
+
+func TestPlanCollection_AuditTrigger(t *testing.T) {
+	// Arrange
+	store := newAuditTestStore()
+
+	// Act
+	statements, err := store.PlanCollection(vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlanCollection: %v", err)
+	}
+	all := strings.Join(statements, ";\n")
+	for _, want := range []string{
+		`CREATE TABLE IF NOT EXISTS "public"."__vector_audit"`,
+		`nullif(current_setting('app.actor_id', true), '')`,
+		`CREATE TRIGGER "__vector_audit" AFTER INSERT OR UPDATE OR DELETE ON "public"."docs" FOR EACH ROW EXECUTE FUNCTION "public"."__vector_audit_record"('docs')`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected %q in plan:\n%s", want, all)
+		}
+	}
+}
+
+func TestEnsureCollection_RejectsAuditTableName(t *testing.T) {
+	// Arrange
+	store := newAuditTestStore()
+
+	// Act
+	_, err := store.PlanCollection(vectordata.CollectionSpec{Name: defaultAuditTable, Dimension: 3})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestLocalSettings_ActorOnlyForAuditedWrites(t *testing.T) {
+	// Arrange
+	audited := newAuditTestStore()
+	plain := &PostgresVectorStore{opts: DefaultStoreOptions()}
+	ctx := WithActor(WithTenant(context.Background(), "acme"), "ana")
+
+	// Act
+	write := audited.localSettings(ctx, true)
+	read := audited.localSettings(ctx, false)
+	unaudited := plain.localSettings(ctx, true)
+
+	// Assert
+	if !reflect.DeepEqual(write, map[string]string{"app.tenant_id": "acme", "app.actor_id": "ana"}) {
+		t.Fatalf("unexpected write settings %v", write)
+	}
+	if !reflect.DeepEqual(read, map[string]string{"app.tenant_id": "acme"}) || !reflect.DeepEqual(unaudited, read) {
+		t.Fatalf("unexpected settings %v and %v", read, unaudited)
+	}
+}
+
+func TestBuildAuditQuery_FiltersAndPages(t *testing.T) {
+	// Arrange
+	store := newAuditTestStore()
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	sql, args := store.buildAuditQuery(AuditQuery{Collection: "docs", Actor: "ana", Operation: AuditDelete, Since: since, After: 7, Limit: 50})
+
+	// Assert
+	want := `SELECT seq, collection, operation, record_id, actor, at FROM "public"."__vector_audit" WHERE seq > $1 AND collection = $2 AND actor = $3 AND operation = $4 AND at >= $5 ORDER BY seq LIMIT $6`
+	if sql != want {
+		t.Fatalf("unexpected query:\n%s", sql)
+	}
+	if !reflect.DeepEqual(args, []any{int64(7), "docs", "ana", "delete", since, 50}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestAuditLog_RequiresAudit(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+
+	// Act
+	_, err := store.AuditLog(t.Context(), AuditQuery{})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error without StoreOptions.Audit")
+	}
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import "github.com/gabisonia/go-vectorstore/vectordata"
+
+// capabilities describes the backend for the store and its collections.
+func capabilities() vectordata.Capabilities {
+	return vectordata.Capabilities{
+		Metrics:          []vectordata.DistanceMetric{vectordata.DistanceCosine, vectordata.DistanceL2, vectordata.DistanceInnerProduct},
+		TextSearch:       true,
+		HybridSearch:     true,
+		IndexManagement:  true,
+		IndexMethods:     []vectordata.IndexMethod{vectordata.IndexMethodHNSW, vectordata.IndexMethodIVFFlat},
+		MetadataIndexes:  true,
+		TextIndexes:      true,
+		TrigramIndexes:   true,
+		Partitioning:     true,
+		NormalizeVectors: true,
+		Float64Vectors:   true,
+		Int8Vectors:      true,
+		FastVectors:      true,
+		SessionSettings:  true,
+		SearchCursors:    true,
+	}
+}
+
+// Capabilities reports the features of the backend.
+func (s *PostgresVectorStore) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// Capabilities reports the features of the backend.
+func (c *PostgresCollection) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// SupportsFilter returns the error a query with filter would fail with, if
+// any.
+func (c *PostgresCollection) SupportsFilter(filter vectordata.Filter) error {
+	if filter == nil {
+		return nil
+	}
+	_, _, _, err := vectordata.CompileFilterSQL(filter, c.filterConfig(), 1)
+	return err
+}
@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// catalogTable records every collection ensured in a schema, with the metric
+// vector normalization, element type and fast dimension the table itself
+// cannot express.
+const catalogTable = "__vector_collections"
+
+// CollectionInfo is a collection as recorded in the catalog.
+type CollectionInfo struct {
+	Name      string
+	Dimension int
+	Metric    vectordata.DistanceMetric
+	// NormalizeVectors mirrors CollectionSpec.NormalizeVectors.
+	NormalizeVectors bool
+	ElementType      vectordata.VectorElementType
+	FastDimension    int
+	CreatedAt        time.Time
+}
+
+func (s *PostgresVectorStore) catalogTableName() string {
+	return qualifiedTable(s.opts.Schema, catalogTable)
+}
+
+func (s *PostgresVectorStore) ensureCatalogTable(ctx context.Context, db schemaExecutor) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name text PRIMARY KEY,
+		dimension integer NOT NULL,
+		metric text NOT NULL,
+		created_at timestamptz NOT NULL DEFAULT now(),
+		normalize_vectors boolean NOT NULL DEFAULT false,
+		element_type text NOT NULL DEFAULT 'float32',
+		fast_dimension integer NOT NULL DEFAULT 0
+	)`, s.catalogTableName())
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("ensure collection catalog: %w", err)
+	}
+	// Catalogs created by earlier versions gain the newer columns.
+	query = fmt.Sprintf(`ALTER TABLE %s
+		ADD COLUMN IF NOT EXISTS normalize_vectors boolean NOT NULL DEFAULT false,
+		ADD COLUMN IF NOT EXISTS element_type text NOT NULL DEFAULT 'float32',
+		ADD COLUMN IF NOT EXISTS fast_dimension integer NOT NULL DEFAULT 0`, s.catalogTableName())
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("ensure collection catalog: %w", err)
+	}
+	return nil
+}
+
+// ensureCatalogEntry records spec in the catalog, or checks it against the
+// recorded entry. Collections created before the catalog existed are recorded
+// on their next EnsureCollection.
+func (s *PostgresVectorStore) ensureCatalogEntry(ctx context.Context, db schemaExecutor, spec vectordata.CollectionSpec) error {
+	var dimension int
+	var metric string
+	var normalize bool
+	var elementType string
+	var fastDimension int
+	err := db.QueryRow(ctx,
+		fmt.Sprintf(`SELECT dimension, metric, normalize_vectors, element_type, fast_dimension FROM %s WHERE name = $1`, s.catalogTableName()),
+		spec.Name,
+	).Scan(&dimension, &metric, &normalize, &elementType, &fastDimension)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err := db.Exec(ctx,
+			fmt.Sprintf(`INSERT INTO %s (name, dimension, metric, normalize_vectors, element_type, fast_dimension) VALUES ($1, $2, $3, $4, $5, $6)`, s.catalogTableName()),
+			spec.Name,
+			spec.Dimension,
+			string(spec.Metric),
+			spec.NormalizeVectors,
+			string(spec.ElementType),
+			spec.FastDimension,
+		)
+		if err != nil {
+			return fmt.Errorf("record collection %q in catalog: %w", spec.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read collection catalog: %w", err)
+	}
+
+	if dimension != spec.Dimension {
+		return fmt.Errorf("%w: expected vector dimension %d, catalog records %d", vectordata.ErrSchemaMismatch, spec.Dimension, dimension)
+	}
+	if vectordata.DistanceMetric(metric) != spec.Metric {
+		return fmt.Errorf("%w: expected metric %q, catalog records %q", vectordata.ErrSchemaMismatch, spec.Metric, metric)
+	}
+	if normalize != spec.NormalizeVectors {
+		return fmt.Errorf("%w: expected NormalizeVectors %t, catalog records %t", vectordata.ErrSchemaMismatch, spec.NormalizeVectors, normalize)
+	}
+	if vectordata.VectorElementType(elementType) != spec.ElementType {
+		return fmt.Errorf("%w: expected element type %q, catalog records %q", vectordata.ErrSchemaMismatch, spec.ElementType, elementType)
+	}
+	if fastDimension != spec.FastDimension {
+		return fmt.Errorf("%w: expected FastDimension %d, catalog records %d", vectordata.ErrSchemaMismatch, spec.FastDimension, fastDimension)
+	}
+	return nil
+}
+
+// ListCollections returns the collections recorded in the store schema,
+// ordered by name. A schema that was never ensured has none.
+func (s *PostgresVectorStore) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Search)
+	defer cancel()
+	var out []CollectionInfo
+	err := s.withReadTenant(ctx, func(q queryExecutor) error {
+		rows, err := q.Query(ctx, fmt.Sprintf(`SELECT name, dimension, metric, normalize_vectors, element_type, fast_dimension, created_at FROM %s ORDER BY name`, s.catalogTableName()))
+		if err != nil {
+			return err
+		}
+		out, err = pgx.CollectRows(rows, scanCollectionInfo)
+		return err
+	})
+	if isUndefinedTable(err) {
+		return []CollectionInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	return out, nil
+}
+
+// DescribeCollection returns the catalog entry of a collection, or
+// vectordata.ErrNotFound.
+func (s *PostgresVectorStore) DescribeCollection(ctx context.Context, name string) (CollectionInfo, error) {
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Search)
+	defer cancel()
+	var out CollectionInfo
+	err := s.withReadTenant(ctx, func(q queryExecutor) error {
+		rows, err := q.Query(ctx, fmt.Sprintf(`SELECT name, dimension, metric, normalize_vectors, element_type, fast_dimension, created_at FROM %s WHERE name = $1`, s.catalogTableName()), name)
+		if err != nil {
+			return err
+		}
+		out, err = pgx.CollectExactlyOneRow(rows, scanCollectionInfo)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) || isUndefinedTable(err) {
+		return CollectionInfo{}, vectordata.ErrNotFound
+	}
+	if err != nil {
+		return CollectionInfo{}, fmt.Errorf("describe collection %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// DropCollection drops the table of a collection, its partitions and indexes,
+// its archive table and its catalog entry. A collection with neither a table
+// nor a catalog entry fails with vectordata.ErrNotFound.
+func (s *PostgresVectorStore) DropCollection(ctx context.Context, name string) error {
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Schema)
+	defer cancel()
+	err := s.withSchemaLock(ctx, advisoryLockKey(s.opts.Schema, name), func(tx pgx.Tx) error {
+		found, err := s.dropCollectionObjects(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return vectordata.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, vectordata.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("drop collection %q: %w", name, err)
+	}
+	s.forgetPartitions(name)
+	return nil
+}
+
+// dropCollectionObjects drops the table of a collection, its archive table
+// and its catalog entry. It reports whether the table or the catalog entry
+// existed.
+func (s *PostgresVectorStore) dropCollectionObjects(ctx context.Context, db schemaExecutor, name string) (bool, error) {
+	exists, err := s.tableExists(ctx, db, name)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		if err := s.execSchema(ctx, db, fmt.Sprintf(`DROP TABLE %s`, qualifiedTable(s.opts.Schema, name))); err != nil {
+			return false, fmt.Errorf("drop collection table: %w", err)
+		}
+	}
+	if err := s.execSchema(ctx, db, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, qualifiedTable(s.opts.Schema, name+archiveSuffix))); err != nil {
+		return false, fmt.Errorf("drop archive table: %w", err)
+	}
+	// A failed statement aborts the transaction, so the catalog is
+	// checked for instead of deleting from a table that may not exist.
+	var catalogExists bool
+	if err := db.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, s.catalogTableName()).Scan(&catalogExists); err != nil {
+		return false, fmt.Errorf("check collection catalog: %w", err)
+	}
+	var removed int64
+	if catalogExists {
+		tag, err := db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE name = $1`, s.catalogTableName()), name)
+		if err != nil {
+			return false, fmt.Errorf("remove collection from catalog: %w", err)
+		}
+		removed = tag.RowsAffected()
+	}
+	return exists || removed > 0, nil
+}
+
+// CollectionStats is a catalog entry with the size of its table and indexes.
+type CollectionStats struct {
+	CollectionInfo
+	// EstimatedRows is the planner's row estimate, as of the last ANALYZE.
+	EstimatedRows int64
+	// TotalBytes is the on-disk size of the table, its partitions, TOAST
+	// and indexes.
+	TotalBytes int64
+	Indexes    []IndexInfo
+}
+
+// IndexInfo describes an index of a collection table.
+type IndexInfo struct {
+	Name       string
+	Definition string
+	// Bytes sums the index over the partitions of a partitioned table.
+	Bytes int64
+	// Valid is false while a concurrent build runs or after it failed.
+	Valid bool
+}
+
+// CollectionStats returns the catalog entry of a collection with its sizes
+// and indexes, or vectordata.ErrNotFound.
+func (s *PostgresVectorStore) CollectionStats(ctx context.Context, name string) (CollectionStats, error) {
+	info, err := s.DescribeCollection(ctx, name)
+	if err != nil {
+		return CollectionStats{}, err
+	}
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Search)
+	defer cancel()
+	stats := CollectionStats{CollectionInfo: info}
+	table := qualifiedTable(s.opts.Schema, name)
+	err = s.withReadTenant(ctx, func(q queryExecutor) error {
+		err := q.QueryRow(ctx, `
+			SELECT coalesce(sum(greatest(c.reltuples, 0)), 0)::bigint, coalesce(sum(pg_total_relation_size(t.relid)), 0)::bigint
+			FROM pg_partition_tree($1::regclass) t
+			JOIN pg_class c ON c.oid = t.relid
+		`, table).Scan(&stats.EstimatedRows, &stats.TotalBytes)
+		if err != nil {
+			return err
+		}
+		rows, err := q.Query(ctx, `
+			SELECT ic.relname, pg_get_indexdef(i.indexrelid), (
+				SELECT coalesce(sum(pg_relation_size(p.relid)), 0)::bigint FROM pg_partition_tree(i.indexrelid) p
+			), i.indisvalid
+			FROM pg_index i
+			JOIN pg_class ic ON ic.oid = i.indexrelid
+			WHERE i.indrelid = $1::regclass
+			ORDER BY ic.relname
+		`, table)
+		if err != nil {
+			return err
+		}
+		stats.Indexes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (IndexInfo, error) {
+			var index IndexInfo
+			err := row.Scan(&index.Name, &index.Definition, &index.Bytes, &index.Valid)
+			return index, err
+		})
+		return err
+	})
+	if isUndefinedTable(err) {
+		return CollectionStats{}, vectordata.ErrNotFound
+	}
+	if err != nil {
+		return CollectionStats{}, fmt.Errorf("collection stats of %q: %w", name, err)
+	}
+	return stats, nil
+}
+
+func scanCollectionInfo(row pgx.CollectableRow) (CollectionInfo, error) {
+	var info CollectionInfo
+	var metric, elementType string
+	if err := row.Scan(&info.Name, &info.Dimension, &metric, &info.NormalizeVectors, &elementType, &info.FastDimension, &info.CreatedAt); err != nil {
+		return CollectionInfo{}, err
+	}
+	info.Metric = vectordata.DistanceMetric(metric)
+	info.ElementType = vectordata.VectorElementType(elementType)
+	return info, nil
+}
+
+func isUndefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "42P01"
+}
@@ -0,0 +1,191 @@
+//go:build integration
+
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/stores/postgres"
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgxpool"
+	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var integrationDSN string
+
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	var container testcontainers.Container
+	dsn := strings.TrimSpace(os.Getenv("PGVECTOR_CDC_TEST_DSN"))
+	if dsn == "" {
+		started, generatedDSN, err := startLogicalContainer(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start integration container: %v\n", err)
+			os.Exit(1)
+		}
+		container = started
+		dsn = generatedDSN
+	}
+	integrationDSN = dsn
+
+	exitCode := m.Run()
+
+	if container != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		if err := container.Terminate(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to terminate integration container: %v\n", err)
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+// startLogicalContainer starts pgvector with wal_level=logical, which the
+// default integration container does not enable.
+func startLogicalContainer(ctx context.Context) (testcontainers.Container, string, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "pgvector/pgvector:pg16",
+			ExposedPorts: []string{"5432/tcp"},
+			Cmd:          []string{"postgres", "-c", "wal_level=logical"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "postgres",
+				"POSTGRES_PASSWORD": "postgres",
+				"POSTGRES_DB":       "vectorstore_test",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("start pgvector container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", fmt.Errorf("resolve container host: %w", err)
+	}
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", fmt.Errorf("resolve container port: %w", err)
+	}
+
+	dsn := fmt.Sprintf("postgres://postgres:postgres@%s:%s/vectorstore_test?sslmode=disable", host, port.Port())
+	return container, dsn, nil
+}
+
+func integrationPool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, integrationDSN)
+	if err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	for {
+		if err := pool.Ping(ctx); err == nil {
+			return pool
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("wait for integration database: %v", ctx.Err())
+		case <-time.After(300 * time.Millisecond):
+		}
+	}
+}
+
+func TestIntegrationConsumer_SnapshotThenStream(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	pool := integrationPool(t)
+	store, err := postgres.NewVectorStore(pool, postgres.DefaultStoreOptions())
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	collectionName := fmt.Sprintf("cdc_docs_%d", time.Now().UnixNano())
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: collectionName, Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "seed"}}}); err != nil {
+		t.Fatalf("seed insert: %v", err)
+	}
+
+	consumer, err := NewConsumer(pool, DefaultOptions(collectionName, collectionName))
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := consumer.Drop(context.Background()); err != nil {
+			t.Errorf("Drop: %v", err)
+		}
+	})
+
+	var events []RecordChange
+	handler := func(_ context.Context, changes []RecordChange) error {
+		events = append(events, changes...)
+		return nil
+	}
+
+	// Act
+	created, err := consumer.Setup(ctx)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := consumer.snapshot(ctx, handler); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "b", Vector: []float32{0, 1}}, {ID: "a", Vector: []float32{1, 1}}}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if _, err := collection.Delete(ctx, []string{"b"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	handled, err := consumer.Poll(ctx, handler)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	redelivered, err := consumer.Poll(ctx, handler)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second Poll: %v", err)
+	}
+	if !created {
+		t.Fatalf("expected Setup to create the slot")
+	}
+	if handled == 0 || redelivered != 0 {
+		t.Fatalf("expected one delivery per transaction, got %d then %d", handled, redelivered)
+	}
+
+	var got []string
+	for _, event := range events {
+		got = append(got, string(event.Operation)+":"+event.Record.ID)
+		if event.Operation != OperationSnapshot && event.LSN == 0 {
+			t.Fatalf("expected streamed event to carry an LSN: %#v", event)
+		}
+	}
+	want := "snapshot:a insert:b update:a delete:b"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("unexpected events %q, want %q", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,591 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	idColumn       = "id"
+	vectorColumn   = "vector"
+	metadataColumn = "metadata"
+	contentColumn  = "content"
+)
+
+// Options configures a Consumer.
+type Options struct {
+	// Schema holds the collection tables.
+	Schema string
+	// Collections lists the collection tables to capture.
+	Collections []string
+	// SlotName is the logical replication slot. It must be unique per consumer.
+	SlotName string
+	// PublicationName defaults to SlotName.
+	PublicationName string
+	// Snapshot emits every existing row before streaming when the consumer
+	// creates its slot.
+	Snapshot bool
+	// PollInterval is the wait between polls that return no changes.
+	PollInterval time.Duration
+	// MaxChanges bounds the changes read per poll. Transactions are never
+	// split, so a poll can return more.
+	MaxChanges int
+	// SnapshotBatchSize is the number of rows per snapshot handler call.
+	SnapshotBatchSize int
+}
+
+// DefaultOptions returns defaults for the given slot and collections.
+func DefaultOptions(slotName string, collections ...string) Options {
+	return Options{
+		Schema:            "public",
+		Collections:       collections,
+		SlotName:          slotName,
+		Snapshot:          true,
+		PollInterval:      time.Second,
+		MaxChanges:        1000,
+		SnapshotBatchSize: 1000,
+	}
+}
+
+// Consumer reads collection changes from a pgoutput logical replication slot
+// through the SQL replication functions.
+type Consumer struct {
+	pool        *pgxpool.Pool
+	opts        Options
+	collections map[string]bool
+	relations   map[uint32]relationMessage
+}
+
+// NewConsumer creates a change consumer. Call Run to start streaming.
+func NewConsumer(pool *pgxpool.Pool, opts Options) (*Consumer, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("nil pgx pool")
+	}
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	collections := make(map[string]bool, len(opts.Collections))
+	for _, name := range opts.Collections {
+		collections[name] = true
+	}
+	return &Consumer{
+		pool:        pool,
+		opts:        opts,
+		collections: collections,
+		relations:   make(map[uint32]relationMessage),
+	}, nil
+}
+
+// Run ensures the publication and slot exist, emits the initial snapshot when
+// the slot is new, then polls for changes until ctx is cancelled.
+func (c *Consumer) Run(ctx context.Context, handler Handler) error {
+	if handler == nil {
+		return fmt.Errorf("nil cdc handler")
+	}
+
+	created, err := c.Setup(ctx)
+	if err != nil {
+		return err
+	}
+	if created && c.opts.Snapshot {
+		if err := c.snapshot(ctx, handler); err != nil {
+			// Without the slot the next run starts over with a fresh snapshot.
+			dropCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			if dropErr := c.dropSlot(dropCtx); dropErr != nil {
+				return errors.Join(err, dropErr)
+			}
+			return err
+		}
+	}
+
+	for {
+		handled, err := c.Poll(ctx, handler)
+		if err != nil {
+			return err
+		}
+		if handled > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.opts.PollInterval):
+		}
+	}
+}
+
+// Setup creates the publication and replication slot when missing. It
+// reports whether the slot was created by this call.
+func (c *Consumer) Setup(ctx context.Context) (bool, error) {
+	if err := c.ensurePublication(ctx); err != nil {
+		return false, err
+	}
+	return c.ensureSlot(ctx)
+}
+
+// Poll reads one batch of committed transactions, passes each transaction
+// with collection changes to handler and advances the slot past every
+// transaction handled. It returns the number of transactions read.
+func (c *Consumer) Poll(ctx context.Context, handler Handler) (int, error) {
+	messages, err := c.peek(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		pending    []RecordChange
+		commitTime time.Time
+		confirmed  LSN
+		handled    int
+	)
+	for _, data := range messages {
+		msg, err := parsePgoutput(data)
+		if err != nil {
+			return handled, c.advanceAfter(ctx, confirmed, err)
+		}
+
+		switch m := msg.(type) {
+		case relationMessage:
+			c.relations[m.oid] = m
+		case beginMessage:
+			pending = pending[:0]
+			commitTime = m.commitTime
+		case insertMessage, updateMessage, deleteMessage, truncateMessage:
+			changes, err := c.decodeChange(ctx, m)
+			if err != nil {
+				return handled, c.advanceAfter(ctx, confirmed, err)
+			}
+			pending = append(pending, changes...)
+		case commitMessage:
+			if len(pending) > 0 {
+				for i := range pending {
+					pending[i].LSN = m.endLSN
+					pending[i].CommitTime = commitTime
+				}
+				if err := handler(ctx, pending); err != nil {
+					return handled, c.advanceAfter(ctx, confirmed, err)
+				}
+			}
+			pending = nil
+			confirmed = m.endLSN
+			handled++
+		}
+	}
+
+	return handled, c.advanceAfter(ctx, confirmed, nil)
+}
+
+// Drop removes the replication slot and publication. A slot that is not
+// consumed retains WAL on the server, so drop consumers that are retired.
+func (c *Consumer) Drop(ctx context.Context) error {
+	if err := c.dropSlot(ctx); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`DROP PUBLICATION IF EXISTS %s`, quoteIdent(c.opts.PublicationName))
+	if _, err := c.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("drop publication %q: %w", c.opts.PublicationName, err)
+	}
+	return nil
+}
+
+func (c *Consumer) ensurePublication(ctx context.Context) error {
+	var exists bool
+	if err := c.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = $1)`,
+		c.opts.PublicationName,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("check publication: %w", err)
+	}
+
+	if !exists {
+		tables := make([]string, 0, len(c.opts.Collections))
+		for _, name := range c.opts.Collections {
+			tables = append(tables, qualifiedTable(c.opts.Schema, name))
+		}
+		query := fmt.Sprintf(`CREATE PUBLICATION %s FOR TABLE %s`, quoteIdent(c.opts.PublicationName), strings.Join(tables, ", "))
+		if _, err := c.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("create publication %q: %w", c.opts.PublicationName, err)
+		}
+		return nil
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT tablename FROM pg_publication_tables WHERE pubname = $1 AND schemaname = $2`,
+		c.opts.PublicationName,
+		c.opts.Schema,
+	)
+	if err != nil {
+		return fmt.Errorf("read publication tables: %w", err)
+	}
+	published, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("read publication tables: %w", err)
+	}
+	present := make(map[string]bool, len(published))
+	for _, name := range published {
+		present[name] = true
+	}
+	for _, name := range c.opts.Collections {
+		if present[name] {
+			continue
+		}
+		query := fmt.Sprintf(`ALTER PUBLICATION %s ADD TABLE %s`, quoteIdent(c.opts.PublicationName), qualifiedTable(c.opts.Schema, name))
+		if _, err := c.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("add %q to publication: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Consumer) ensureSlot(ctx context.Context) (bool, error) {
+	var plugin string
+	err := c.pool.QueryRow(ctx,
+		`SELECT plugin FROM pg_replication_slots WHERE slot_name = $1`,
+		c.opts.SlotName,
+	).Scan(&plugin)
+	if err == nil {
+		if plugin != "pgoutput" {
+			return false, fmt.Errorf("replication slot %q uses plugin %q, expected pgoutput", c.opts.SlotName, plugin)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("check replication slot: %w", err)
+	}
+
+	if _, err := c.pool.Exec(ctx, `SELECT pg_create_logical_replication_slot($1, 'pgoutput')`, c.opts.SlotName); err != nil {
+		return false, fmt.Errorf("create replication slot %q: %w", c.opts.SlotName, err)
+	}
+	return true, nil
+}
+
+func (c *Consumer) dropSlot(ctx context.Context) error {
+	_, err := c.pool.Exec(ctx,
+		`SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1`,
+		c.opts.SlotName,
+	)
+	if err != nil {
+		return fmt.Errorf("drop replication slot %q: %w", c.opts.SlotName, err)
+	}
+	return nil
+}
+
+// peek reads pending pgoutput messages without consuming them; the slot only
+// moves when advance confirms a handled position.
+func (c *Consumer) peek(ctx context.Context) ([][]byte, error) {
+	rows, err := c.pool.Query(ctx,
+		`SELECT data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2, 'proto_version', '1', 'publication_names', $3)`,
+		c.opts.SlotName,
+		c.opts.MaxChanges,
+		quoteIdent(c.opts.PublicationName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read replication slot %q: %w", c.opts.SlotName, err)
+	}
+	messages, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("read replication slot %q: %w", c.opts.SlotName, err)
+	}
+	return messages, nil
+}
+
+// advanceAfter checkpoints the slot at lsn (if any) and returns cause joined
+// with any checkpoint error.
+func (c *Consumer) advanceAfter(ctx context.Context, lsn LSN, cause error) error {
+	if lsn == 0 {
+		return cause
+	}
+	_, err := c.pool.Exec(ctx, `SELECT pg_replication_slot_advance($1, $2::pg_lsn)`, c.opts.SlotName, lsn.String())
+	if err != nil {
+		err = fmt.Errorf("advance replication slot %q to %s: %w", c.opts.SlotName, lsn, err)
+	}
+	return errors.Join(cause, err)
+}
+
+func (c *Consumer) decodeChange(ctx context.Context, msg any) ([]RecordChange, error) {
+	switch m := msg.(type) {
+	case insertMessage:
+		rel, ok := c.trackedRelation(m.relation)
+		if !ok {
+			return nil, nil
+		}
+		record, err := c.decodeRecord(ctx, rel, m.tuple)
+		if err != nil {
+			return nil, err
+		}
+		return []RecordChange{{Collection: rel.name, Operation: OperationInsert, Record: record}}, nil
+	case updateMessage:
+		rel, ok := c.trackedRelation(m.relation)
+		if !ok {
+			return nil, nil
+		}
+		record, err := c.decodeRecord(ctx, rel, m.tuple)
+		if err != nil {
+			return nil, err
+		}
+		changes := make([]RecordChange, 0, 2)
+		// A primary key change arrives as an update carrying the old key.
+		if m.old != nil {
+			if oldID, ok := tupleText(rel, m.old, idColumn); ok && oldID != record.ID {
+				changes = append(changes, RecordChange{Collection: rel.name, Operation: OperationDelete, Record: vectordata.Record{ID: oldID}})
+			}
+		}
+		return append(changes, RecordChange{Collection: rel.name, Operation: OperationUpdate, Record: record}), nil
+	case deleteMessage:
+		rel, ok := c.trackedRelation(m.relation)
+		if !ok {
+			return nil, nil
+		}
+		id, ok := tupleText(rel, m.old, idColumn)
+		if !ok {
+			return nil, fmt.Errorf("cdc: delete on %q without id; check the table replica identity", rel.name)
+		}
+		return []RecordChange{{Collection: rel.name, Operation: OperationDelete, Record: vectordata.Record{ID: id}}}, nil
+	case truncateMessage:
+		var changes []RecordChange
+		for _, oid := range m.relations {
+			if rel, ok := c.trackedRelation(oid); ok {
+				changes = append(changes, RecordChange{Collection: rel.name, Operation: OperationTruncate})
+			}
+		}
+		return changes, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (c *Consumer) trackedRelation(oid uint32) (relationMessage, bool) {
+	rel, ok := c.relations[oid]
+	if !ok || rel.namespace != c.opts.Schema || !c.collections[rel.name] {
+		return relationMessage{}, false
+	}
+	return rel, true
+}
+
+// decodeRecord converts a new-row tuple into a record. Large metadata or
+// content values that were not modified are sent as unchanged TOAST markers,
+// so those rows are re-read from the table.
+func (c *Consumer) decodeRecord(ctx context.Context, rel relationMessage, tuple []tupleColumn) (vectordata.Record, error) {
+	var record vectordata.Record
+	unchanged := false
+	for i, column := range tuple {
+		if i >= len(rel.columns) {
+			break
+		}
+		if column.kind == 'u' {
+			unchanged = true
+			continue
+		}
+		if column.kind == 'n' {
+			continue
+		}
+		text := string(column.data)
+		switch rel.columns[i].name {
+		case idColumn:
+			record.ID = text
+		case vectorColumn:
+			vector, err := parseVectorText(text)
+			if err != nil {
+				return vectordata.Record{}, fmt.Errorf("decode vector: %w", err)
+			}
+			record.Vector = vector
+		case metadataColumn:
+			metadata, err := parseMetadata([]byte(text))
+			if err != nil {
+				return vectordata.Record{}, fmt.Errorf("decode metadata: %w", err)
+			}
+			record.Metadata = metadata
+		case contentColumn:
+			record.Content = &text
+		}
+	}
+	if record.Metadata == nil {
+		record.Metadata = map[string]any{}
+	}
+	if !unchanged {
+		return record, nil
+	}
+
+	current, err := c.fetchRecord(ctx, rel.name, record.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deleted by a later transaction, which the consumer will see next.
+		return record, nil
+	}
+	return current, err
+}
+
+func (c *Consumer) fetchRecord(ctx context.Context, collection, id string) (vectordata.Record, error) {
+	query := fmt.Sprintf(`SELECT %s, %s::text, %s, %s FROM %s WHERE %s = $1`,
+		quoteIdent(idColumn),
+		quoteIdent(vectorColumn),
+		quoteIdent(metadataColumn),
+		quoteIdent(contentColumn),
+		qualifiedTable(c.opts.Schema, collection),
+		quoteIdent(idColumn),
+	)
+	rows, err := c.pool.Query(ctx, query, id)
+	if err != nil {
+		return vectordata.Record{}, err
+	}
+	return pgx.CollectExactlyOneRow(rows, scanRecord)
+}
+
+// snapshot emits every existing row, paging by id. It runs after the slot is
+// created, so rows written concurrently may also arrive as streamed changes.
+func (c *Consumer) snapshot(ctx context.Context, handler Handler) error {
+	for _, collection := range c.opts.Collections {
+		query := fmt.Sprintf(`SELECT %s, %s::text, %s, %s FROM %s WHERE %s > $1 ORDER BY %s LIMIT $2`,
+			quoteIdent(idColumn),
+			quoteIdent(vectorColumn),
+			quoteIdent(metadataColumn),
+			quoteIdent(contentColumn),
+			qualifiedTable(c.opts.Schema, collection),
+			quoteIdent(idColumn),
+			quoteIdent(idColumn),
+		)
+
+		after := ""
+		for {
+			rows, err := c.pool.Query(ctx, query, after, c.opts.SnapshotBatchSize)
+			if err != nil {
+				return fmt.Errorf("snapshot %q: %w", collection, err)
+			}
+			records, err := pgx.CollectRows(rows, scanRecord)
+			if err != nil {
+				return fmt.Errorf("snapshot %q: %w", collection, err)
+			}
+			if len(records) == 0 {
+				break
+			}
+
+			changes := make([]RecordChange, 0, len(records))
+			for _, record := range records {
+				changes = append(changes, RecordChange{Collection: collection, Operation: OperationSnapshot, Record: record})
+			}
+			if err := handler(ctx, changes); err != nil {
+				return err
+			}
+			after = records[len(records)-1].ID
+		}
+	}
+	return nil
+}
+
+func scanRecord(row pgx.CollectableRow) (vectordata.Record, error) {
+	var record vectordata.Record
+	var vectorText string
+	var metadataRaw []byte
+	if err := row.Scan(&record.ID, &vectorText, &metadataRaw, &record.Content); err != nil {
+		return vectordata.Record{}, err
+	}
+	vector, err := parseVectorText(vectorText)
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("decode vector: %w", err)
+	}
+	metadata, err := parseMetadata(metadataRaw)
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("decode metadata: %w", err)
+	}
+	record.Vector = vector
+	record.Metadata = metadata
+	return record, nil
+}
+
+func tupleText(rel relationMessage, tuple []tupleColumn, name string) (string, bool) {
+	for i, column := range tuple {
+		if i < len(rel.columns) && rel.columns[i].name == name && column.kind == 't' {
+			return string(column.data), true
+		}
+	}
+	return "", false
+}
+
+func (o Options) withDefaults() Options {
+	defaults := DefaultOptions("")
+	if strings.TrimSpace(o.Schema) == "" {
+		o.Schema = defaults.Schema
+	}
+	if strings.TrimSpace(o.PublicationName) == "" {
+		o.PublicationName = o.SlotName
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaults.PollInterval
+	}
+	if o.MaxChanges <= 0 {
+		o.MaxChanges = defaults.MaxChanges
+	}
+	if o.SnapshotBatchSize <= 0 {
+		o.SnapshotBatchSize = defaults.SnapshotBatchSize
+	}
+	return o
+}
+
+func (o Options) validate() error {
+	if strings.TrimSpace(o.SlotName) == "" {
+		return fmt.Errorf("cdc: slot name is empty")
+	}
+	if len(o.Collections) == 0 {
+		return fmt.Errorf("cdc: no collections configured")
+	}
+	for _, name := range o.Collections {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("cdc: collection name is empty")
+		}
+	}
+	return nil
+}
+
+func quoteIdent(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func qualifiedTable(schema, table string) string {
+	return quoteIdent(schema) + "." + quoteIdent(table)
+}
+
+func parseVectorText(raw string) ([]float32, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 2 || raw[0] != '[' || raw[len(raw)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector value %q", raw)
+	}
+	body := strings.TrimSpace(raw[1 : len(raw)-1])
+	if body == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(body, ",")
+	out := make([]float32, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse vector element %q: %w", part, err)
+		}
+		out = append(out, float32(f))
+	}
+	return out, nil
+}
+
+func parseMetadata(raw []byte) (map[string]any, error) {
+	if len(raw) == 0 {
+		return map[string]any{}, nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return map[string]any{}, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,12 @@
+// Package cdc streams collection changes out of Postgres through logical
+// replication, for rebuilding search indexes in other systems.
+//
+// A Consumer owns a publication and a pgoutput replication slot for the
+// collection tables. When it creates the slot it first emits a snapshot of
+// the existing rows, then polls the slot and emits committed inserts, updates
+// and deletes as RecordChange events. The slot position is advanced only after
+// the handler accepts a transaction, so delivery is at-least-once.
+//
+// The server must run with wal_level=logical and the connecting role needs
+// the REPLICATION attribute (or superuser) plus ownership of the tables.
+package cdc
@@ -0,0 +1,237 @@
+package cdc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// pgEpoch is the origin of pgoutput timestamps (microseconds since 2000-01-01 UTC).
+var pgEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var errShortMessage = errors.New("cdc: truncated pgoutput message")
+
+// tupleColumn is one column of a pgoutput TupleData block.
+type tupleColumn struct {
+	kind byte // 'n' null, 'u' unchanged TOAST value, 't' text
+	data []byte
+}
+
+type relationColumn struct {
+	name    string
+	typeOID uint32
+}
+
+type relationMessage struct {
+	oid       uint32
+	namespace string
+	name      string
+	columns   []relationColumn
+}
+
+type beginMessage struct {
+	finalLSN   LSN
+	commitTime time.Time
+	xid        uint32
+}
+
+type commitMessage struct {
+	commitLSN  LSN
+	endLSN     LSN
+	commitTime time.Time
+}
+
+type insertMessage struct {
+	relation uint32
+	tuple    []tupleColumn
+}
+
+type updateMessage struct {
+	relation uint32
+	// old holds the key ('K') or full ('O') old tuple when the replica
+	// identity requires it.
+	old   []tupleColumn
+	tuple []tupleColumn
+}
+
+type deleteMessage struct {
+	relation uint32
+	old      []tupleColumn
+}
+
+type truncateMessage struct {
+	relations []uint32
+}
+
+// parsePgoutput decodes one pgoutput protocol version 1 message. Message
+// types the consumer does not use (Origin, Type, Message) return nil.
+func parsePgoutput(data []byte) (any, error) {
+	if len(data) == 0 {
+		return nil, errShortMessage
+	}
+	r := &messageReader{buf: data[1:]}
+
+	switch data[0] {
+	case 'B':
+		msg := beginMessage{
+			finalLSN:   LSN(r.uint64()),
+			commitTime: pgTime(r.int64()),
+			xid:        r.uint32(),
+		}
+		return msg, r.err
+	case 'C':
+		r.byte() // flags, currently unused
+		msg := commitMessage{
+			commitLSN:  LSN(r.uint64()),
+			endLSN:     LSN(r.uint64()),
+			commitTime: pgTime(r.int64()),
+		}
+		return msg, r.err
+	case 'R':
+		msg := relationMessage{
+			oid:       r.uint32(),
+			namespace: r.string(),
+			name:      r.string(),
+		}
+		r.byte() // replica identity setting
+		count := int(r.uint16())
+		for i := 0; i < count && r.err == nil; i++ {
+			r.byte() // flags: 1 marks key columns
+			column := relationColumn{name: r.string(), typeOID: r.uint32()}
+			r.uint32() // type modifier
+			msg.columns = append(msg.columns, column)
+		}
+		return msg, r.err
+	case 'I':
+		msg := insertMessage{relation: r.uint32()}
+		if kind := r.byte(); r.err == nil && kind != 'N' {
+			return nil, fmt.Errorf("cdc: unexpected insert tuple marker %q", kind)
+		}
+		msg.tuple = r.tuple()
+		return msg, r.err
+	case 'U':
+		msg := updateMessage{relation: r.uint32()}
+		kind := r.byte()
+		if kind == 'K' || kind == 'O' {
+			msg.old = r.tuple()
+			kind = r.byte()
+		}
+		if r.err == nil && kind != 'N' {
+			return nil, fmt.Errorf("cdc: unexpected update tuple marker %q", kind)
+		}
+		msg.tuple = r.tuple()
+		return msg, r.err
+	case 'D':
+		msg := deleteMessage{relation: r.uint32()}
+		if kind := r.byte(); r.err == nil && kind != 'K' && kind != 'O' {
+			return nil, fmt.Errorf("cdc: unexpected delete tuple marker %q", kind)
+		}
+		msg.old = r.tuple()
+		return msg, r.err
+	case 'T':
+		count := int(r.uint32())
+		r.byte() // options: CASCADE / RESTART IDENTITY
+		msg := truncateMessage{}
+		for i := 0; i < count && r.err == nil; i++ {
+			msg.relations = append(msg.relations, r.uint32())
+		}
+		return msg, r.err
+	default:
+		return nil, nil
+	}
+}
+
+func pgTime(micros int64) time.Time {
+	return pgEpoch.Add(time.Duration(micros) * time.Microsecond)
+}
+
+// messageReader reads big-endian protocol fields, recording the first error.
+type messageReader struct {
+	buf []byte
+	err error
+}
+
+func (r *messageReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = errShortMessage
+		return nil
+	}
+	out := r.buf[:n]
+	r.buf = r.buf[n:]
+	return out
+}
+
+func (r *messageReader) byte() byte {
+	b := r.take(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *messageReader) uint16() uint16 {
+	b := r.take(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *messageReader) uint32() uint32 {
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *messageReader) uint64() uint64 {
+	b := r.take(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *messageReader) int64() int64 {
+	return int64(r.uint64())
+}
+
+func (r *messageReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	for i, b := range r.buf {
+		if b == 0 {
+			s := string(r.buf[:i])
+			r.buf = r.buf[i+1:]
+			return s
+		}
+	}
+	r.err = errShortMessage
+	return ""
+}
+
+func (r *messageReader) tuple() []tupleColumn {
+	count := int(r.uint16())
+	columns := make([]tupleColumn, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		column := tupleColumn{kind: r.byte()}
+		switch column.kind {
+		case 'n', 'u':
+		case 't':
+			length := int(r.uint32())
+			column.data = r.take(length)
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("cdc: unsupported tuple column kind %q", column.kind)
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
@@ -0,0 +1,185 @@
+package cdc
+
+import (
+	"context"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// messageWriter builds pgoutput messages for tests.
+type messageWriter struct {
+	buf []byte
+}
+
+func newMessage(kind byte) *messageWriter {
+	return &messageWriter{buf: []byte{kind}}
+}
+
+func (w *messageWriter) byte(b byte) *messageWriter {
+	w.buf = append(w.buf, b)
+	return w
+}
+
+func (w *messageWriter) uint16(v uint16) *messageWriter {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, v)
+	return w
+}
+
+func (w *messageWriter) uint32(v uint32) *messageWriter {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+	return w
+}
+
+func (w *messageWriter) uint64(v uint64) *messageWriter {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, v)
+	return w
+}
+
+func (w *messageWriter) string(s string) *messageWriter {
+	w.buf = append(append(w.buf, s...), 0)
+	return w
+}
+
+// tuple writes text columns; nil values are written as NULL.
+func (w *messageWriter) tuple(values ...*string) *messageWriter {
+	w.uint16(uint16(len(values)))
+	for _, v := range values {
+		if v == nil {
+			w.byte('n')
+			continue
+		}
+		w.byte('t').uint32(uint32(len(*v)))
+		w.buf = append(w.buf, *v...)
+	}
+	return w
+}
+
+func text(s string) *string {
+	return &s
+}
+
+func relationFixture() []byte {
+	w := newMessage('R').uint32(42).string("public").string("docs").byte('d').uint16(4)
+	for _, name := range []string{"id", "vector", "metadata", "content"} {
+		w.byte(0).string(name).uint32(25).uint32(0xFFFFFFFF)
+	}
+	return w.buf
+}
+
+func TestParsePgoutput_Messages(t *testing.T) {
+	// Arrange
+	commitMicros := int64(3 * time.Second / time.Microsecond)
+	begin := newMessage('B').uint64(0x10).uint64(uint64(commitMicros)).uint32(7).buf
+	commit := newMessage('C').byte(0).uint64(0x10).uint64(0x18).uint64(uint64(commitMicros)).buf
+	insert := newMessage('I').uint32(42).byte('N').tuple(text("a"), text("[1,2]"), text(`{"k":1}`), nil).buf
+	update := newMessage('U').uint32(42).byte('K').tuple(text("old"), nil, nil, nil).byte('N').tuple(text("a"), text("[1,2]"), text("{}"), nil).buf
+	truncate := newMessage('T').uint32(1).byte(0).uint32(42).buf
+
+	// Act
+	relation, relationErr := parsePgoutput(relationFixture())
+	parsedBegin, beginErr := parsePgoutput(begin)
+	parsedCommit, commitErr := parsePgoutput(commit)
+	parsedInsert, insertErr := parsePgoutput(insert)
+	parsedUpdate, updateErr := parsePgoutput(update)
+	parsedTruncate, truncateErr := parsePgoutput(truncate)
+	_, shortErr := parsePgoutput(insert[:len(insert)-3])
+
+	// Assert
+	for name, err := range map[string]error{"relation": relationErr, "begin": beginErr, "commit": commitErr, "insert": insertErr, "update": updateErr, "truncate": truncateErr} {
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	rel := relation.(relationMessage)
+	if rel.oid != 42 || rel.namespace != "public" || rel.name != "docs" || len(rel.columns) != 4 || rel.columns[3].name != "content" {
+		t.Fatalf("unexpected relation: %#v", rel)
+	}
+	if b := parsedBegin.(beginMessage); b.xid != 7 || !b.commitTime.Equal(pgEpoch.Add(3*time.Second)) {
+		t.Fatalf("unexpected begin: %#v", b)
+	}
+	if c := parsedCommit.(commitMessage); c.endLSN != 0x18 {
+		t.Fatalf("unexpected commit: %#v", c)
+	}
+	if i := parsedInsert.(insertMessage); i.relation != 42 || len(i.tuple) != 4 || i.tuple[3].kind != 'n' || string(i.tuple[1].data) != "[1,2]" {
+		t.Fatalf("unexpected insert: %#v", i)
+	}
+	if u := parsedUpdate.(updateMessage); string(u.old[0].data) != "old" || string(u.tuple[0].data) != "a" {
+		t.Fatalf("unexpected update: %#v", u)
+	}
+	if tr := parsedTruncate.(truncateMessage); !reflect.DeepEqual(tr.relations, []uint32{42}) {
+		t.Fatalf("unexpected truncate: %#v", tr)
+	}
+	if shortErr == nil {
+		t.Fatalf("expected truncated message error")
+	}
+}
+
+func TestConsumer_DecodeChanges(t *testing.T) {
+	// Arrange
+	consumer, err := newTestConsumer(t)
+	if err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+	relation, _ := parsePgoutput(relationFixture())
+	consumer.relations[42] = relation.(relationMessage)
+	other := relation.(relationMessage)
+	other.oid, other.name = 43, "untracked"
+	consumer.relations[43] = other
+
+	insert, _ := parsePgoutput(newMessage('I').uint32(42).byte('N').tuple(text("a"), text("[1,2]"), text(`{"k":1}`), text("body")).buf)
+	rename, _ := parsePgoutput(newMessage('U').uint32(42).byte('K').tuple(text("old"), nil, nil, nil).byte('N').tuple(text("new"), text("[0,1]"), text("{}"), nil).buf)
+	deletion, _ := parsePgoutput(newMessage('D').uint32(42).byte('K').tuple(text("a"), nil, nil, nil).buf)
+	untracked, _ := parsePgoutput(newMessage('I').uint32(43).byte('N').tuple(text("x"), text("[1]"), text("{}"), nil).buf)
+
+	// Act
+	insertChanges, insertErr := consumer.decodeChange(context.Background(), insert)
+	renameChanges, renameErr := consumer.decodeChange(context.Background(), rename)
+	deleteChanges, deleteErr := consumer.decodeChange(context.Background(), deletion)
+	untrackedChanges, untrackedErr := consumer.decodeChange(context.Background(), untracked)
+
+	// Assert
+	if insertErr != nil || renameErr != nil || deleteErr != nil || untrackedErr != nil {
+		t.Fatalf("decodeChange: %v %v %v %v", insertErr, renameErr, deleteErr, untrackedErr)
+	}
+	got := insertChanges[0]
+	if got.Operation != OperationInsert || got.Record.ID != "a" || got.Record.Metadata["k"] != float64(1) || *got.Record.Content != "body" || len(got.Record.Vector) != 2 {
+		t.Fatalf("unexpected insert change: %#v", got)
+	}
+	if len(renameChanges) != 2 || renameChanges[0].Operation != OperationDelete || renameChanges[0].Record.ID != "old" || renameChanges[1].Record.ID != "new" {
+		t.Fatalf("unexpected rename changes: %#v", renameChanges)
+	}
+	if len(deleteChanges) != 1 || deleteChanges[0].Operation != OperationDelete || deleteChanges[0].Record.ID != "a" {
+		t.Fatalf("unexpected delete changes: %#v", deleteChanges)
+	}
+	if len(untrackedChanges) != 0 {
+		t.Fatalf("expected untracked relation to be skipped, got %#v", untrackedChanges)
+	}
+}
+
+func TestLSN_RoundTrip(t *testing.T) {
+	// Act
+	lsn, err := ParseLSN("16/B374D848")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseLSN: %v", err)
+	}
+	if lsn != LSN(0x16B374D848) || lsn.String() != "16/B374D848" {
+		t.Fatalf("unexpected lsn %d (%s)", uint64(lsn), lsn)
+	}
+}
+
+func newTestConsumer(t *testing.T) (*Consumer, error) {
+	t.Helper()
+	opts := DefaultOptions("slot", "docs").withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &Consumer{
+		opts:        opts,
+		collections: map[string]bool{"docs": true},
+		relations:   make(map[uint32]relationMessage),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// LSN is a Postgres write-ahead log position.
+type LSN uint64
+
+// String formats the LSN the way Postgres prints pg_lsn values, e.g. "16/B374D848".
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// ParseLSN parses a pg_lsn text value.
+func ParseLSN(s string) (LSN, error) {
+	var hi, lo uint32
+	if _, err := fmt.Sscanf(s, "%X/%X", &hi, &lo); err != nil {
+		return 0, fmt.Errorf("parse lsn %q: %w", s, err)
+	}
+	return LSN(uint64(hi)<<32 | uint64(lo)), nil
+}
+
+// Operation describes what happened to a record.
+type Operation string
+
+const (
+	// OperationSnapshot is emitted for every row read by the initial snapshot.
+	OperationSnapshot Operation = "snapshot"
+	OperationInsert   Operation = "insert"
+	OperationUpdate   Operation = "update"
+	// OperationDelete carries only Record.ID.
+	OperationDelete Operation = "delete"
+	// OperationTruncate removes every record of the collection; Record is empty.
+	OperationTruncate Operation = "truncate"
+)
+
+// RecordChange is one change to a collection record.
+type RecordChange struct {
+	Collection string
+	Operation  Operation
+	Record     vectordata.Record
+	// LSN is the end of the commit record that contains the change. It is zero
+	// for snapshot events.
+	LSN        LSN
+	CommitTime time.Time
+}
+
+// Handler receives the changes of one committed transaction, in commit order.
+// Returning an error stops the consumer without advancing the slot, so the
+// transaction is delivered again on the next run.
+type Handler func(ctx context.Context, changes []RecordChange) error
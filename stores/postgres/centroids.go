@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.CentroidCalculator = (*PostgresCollection)(nil)
+
+// Centroids averages the vectors of each group with pgvector's avg
+// aggregate and normalizes the means in Go. Use vectordata.Centroids, which
+// normalizes groupBy and sorts the result.
+func (c *PostgresCollection) Centroids(ctx context.Context, groupBy vectordata.FieldRef, filter vectordata.Filter) ([]vectordata.Centroid, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	query, args, err := c.buildCentroidsQuery(groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var centroids []vectordata.Centroid
+	stats := queryStats{op: "Centroids", collection: c.name, query: query, args: args, filter: filter, started: time.Now(), executed: time.Now()}
+	err = c.store.withReadTenant(ctx, func(q queryExecutor) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var groupRaw []byte
+			var meanText string
+			var centroid vectordata.Centroid
+			if err := rows.Scan(&groupRaw, &meanText, &centroid.Count); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(groupRaw, &centroid.Group); err != nil {
+				return fmt.Errorf("decode group: %w", err)
+			}
+			mean, err := parseVectorText(meanText)
+			if err != nil {
+				return fmt.Errorf("decode centroid: %w", err)
+			}
+			centroid.Vector = vectordata.NormalizeVector(mean)
+			centroids = append(centroids, centroid)
+		}
+		return rows.Err()
+	})
+	stats.rows, stats.err = int64(len(centroids)), err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return nil, err
+	}
+	return centroids, nil
+}
+
+// buildCentroidsQuery groups by the JSON value of groupBy, so metadata
+// groups keep their types, and skips rows where it is SQL NULL.
+func (c *PostgresCollection) buildCentroidsQuery(groupBy vectordata.FieldRef, filter vectordata.Filter) (string, []any, error) {
+	cfg := c.filterConfig()
+	var groupExpr string
+	var args []any
+	switch groupBy.Kind {
+	case vectordata.FieldMetadata:
+		groupExpr = fmt.Sprintf("(%s #> $1::text[])", cfg.MetadataExpr)
+		args = append(args, groupBy.Path)
+	case vectordata.FieldColumn:
+		column, ok := cfg.ColumnExpr[groupBy.Name]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown column %q", vectordata.ErrInvalidFilter, groupBy.Name)
+		}
+		groupExpr = fmt.Sprintf("to_jsonb(%s)", column)
+	default:
+		return "", nil, fmt.Errorf("%w: unsupported field kind %q", vectordata.ErrInvalidFilter, groupBy.Kind)
+	}
+	whereSQL, filterArgs, _, err := vectordata.CompileFilterSQL(filter, cfg, len(args)+1)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, filterArgs...)
+
+	where := groupExpr + " IS NOT NULL"
+	if whereSQL != "" {
+		where = whereSQL + " AND " + where
+	}
+	query := fmt.Sprintf(`SELECT %s, avg(%s)::text, COUNT(*) FROM %s WHERE %s GROUP BY 1`,
+		groupExpr,
+		quoteIdent(vectorColumn),
+		c.tableName(),
+		where,
+	)
+	return query, args, nil
+}
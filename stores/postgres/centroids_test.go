@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_CentroidsQueryAveragesPerGroup(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	filter := vectordata.Eq(vectordata.Metadata("lang"), "en")
+
+	// Act
+	metadataQuery, metadataArgs, metadataErr := collection.buildCentroidsQuery(vectordata.Metadata("topic", "name"), filter)
+	columnQuery, _, columnErr := collection.buildCentroidsQuery(vectordata.Column("content"), nil)
+	_, _, unknownErr := collection.buildCentroidsQuery(vectordata.Column("vector"), nil)
+
+	// Assert
+	if metadataErr != nil || columnErr != nil {
+		t.Fatalf("buildCentroidsQuery: %v, %v", metadataErr, columnErr)
+	}
+	expected := `SELECT ("metadata" #> $1::text[]), avg("vector")::text, COUNT(*) FROM "public"."docs" ` +
+		`WHERE (("metadata" #> ARRAY['lang']) = $2::jsonb) AND ("metadata" #> $1::text[]) IS NOT NULL GROUP BY 1`
+	if metadataQuery != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, metadataQuery)
+	}
+	if !reflect.DeepEqual(metadataArgs, []any{[]string{"topic", "name"}, []byte(`"en"`)}) {
+		t.Fatalf("unexpected args: %#v", metadataArgs)
+	}
+	if columnQuery != `SELECT to_jsonb("content"), avg("vector")::text, COUNT(*) FROM "public"."docs" WHERE to_jsonb("content") IS NOT NULL GROUP BY 1` {
+		t.Fatalf("unexpected query %s", columnQuery)
+	}
+	if !errors.Is(unknownErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for an unknown column, got %v", unknownErr)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gabisonia/go-vectorstore/vectordata"
 	"github.com/jackc/pgx/v5"
@@ -17,12 +18,46 @@ type writeMode int
 const (
 	writeModeInsert writeMode = iota
 	writeModeUpsert
+	// writeModeSkip inserts records whose ID is absent and ignores the rest.
+	writeModeSkip
+	// writeModeMergeMetadata upserts, concatenating the existing and new
+	// metadata with jsonb ||.
+	writeModeMergeMetadata
+)
+
+// rankColumns describes the ranking columns that follow the projected fields
+// in a search result row.
+type rankColumns int
+
+const (
+	// rankDistance is a vector distance; the score is derived from the metric.
+	rankDistance rankColumns = iota
+	// rankScore is a text relevance score with no vector distance.
+	rankScore
+	// rankDistanceAndScore is a vector distance followed by a combined score.
+	rankDistanceAndScore
+	// rankNone has no ranking columns, for listings.
+	rankNone
 )
 
 type searchPlan struct {
 	query      string
 	args       []any
 	projection vectordata.Projection
+	settings   sessionSettings
+	rank       rankColumns
+	// highlight selects a snippet after the ranking columns.
+	highlight bool
+	// group selects, last, the ID of the query record each row belongs to.
+	group bool
+	// orderKeys is the number of OrderBy sort keys selected, last, by
+	// searches that rescore candidates, so the outer query can order by them.
+	orderKeys int
+}
+
+// rowQuerier is satisfied by *pgxpool.Pool and pgx.Tx.
+type rowQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }
 
 // PostgresCollection is a PostgreSQL-backed vector collection.
@@ -31,6 +66,24 @@ type PostgresCollection struct {
 	name      string
 	dimension int
 	metric    vectordata.DistanceMetric
+	// partition is set for handles returned by EnsureCollection with a
+	// CollectionSpec.Partition.
+	partition *partitioning
+	// normalize is set for handles returned by EnsureCollection with
+	// CollectionSpec.NormalizeVectors.
+	normalize bool
+	// elementType is set for handles returned by EnsureCollection. Handles
+	// whose element type has a native column read and write it as well.
+	elementType vectordata.VectorElementType
+	// fastDimension is set for handles returned by EnsureCollection with
+	// CollectionSpec.FastDimension.
+	fastDimension int
+	// maxRecords and evictBy are set for handles returned by EnsureCollection
+	// with CollectionSpec.MaxRecords.
+	maxRecords int64
+	evictBy    string
+	// plan, when set, records index DDL instead of running it.
+	plan *planExecutor
 }
 
 func (c *PostgresCollection) Name() string {
@@ -46,34 +99,77 @@ func (c *PostgresCollection) Metric() vectordata.DistanceMetric {
 }
 
 func (c *PostgresCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
 	return c.writeRecords(ctx, records, writeModeInsert)
 }
 
 func (c *PostgresCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
 	return c.writeRecords(ctx, records, writeModeUpsert)
 }
 
+// UpsertWithOptions upserts records with ON CONFLICT DO NOTHING for
+// ConflictSkip, and for ConflictMergeMetadata with a DO UPDATE that sets the
+// metadata to the existing value || the new one.
+func (c *PostgresCollection) UpsertWithOptions(ctx context.Context, records []vectordata.Record, opts vectordata.UpsertOptions) error {
+	if err := opts.OnConflict.Validate(); err != nil {
+		return err
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	mode := writeModeUpsert
+	switch opts.OnConflict {
+	case vectordata.ConflictSkip:
+		mode = writeModeSkip
+	case vectordata.ConflictMergeMetadata:
+		mode = writeModeMergeMetadata
+	}
+	return c.writeRecords(ctx, records, mode)
+}
+
 func (c *PostgresCollection) Get(ctx context.Context, id string) (vectordata.Record, error) {
-	query := fmt.Sprintf(`
-		SELECT %s, %s::text, %s, %s
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	query := c.statement(statementKey{kind: statementGet}, func() string {
+		return fmt.Sprintf(`
+		SELECT %s
 		FROM %s
 		WHERE %s = $1
 	`,
-		quoteIdent(idColumn),
-		quoteIdent(vectorColumn),
-		quoteIdent(metadataColumn),
-		quoteIdent(contentColumn),
-		c.tableName(),
-		quoteIdent(idColumn),
-	)
+			strings.Join(c.projectedColumns(vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}), ", "),
+			c.tableName(),
+			quoteIdent(idColumn),
+		)
+	})
 
 	var out vectordata.Record
 	var vectorText string
 	var metadataRaw []byte
-	if err := c.store.pool.QueryRow(ctx, query, id).Scan(&out.ID, &vectorText, &metadataRaw, &out.Content); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return vectordata.Record{}, vectordata.ErrNotFound
-		}
+	targets := []any{&out.ID, &vectorText, &metadataRaw, &out.Content}
+	assignNative := func() {}
+	if _, ok := nativeVectorColumn(c.elementType); ok {
+		var target any
+		target, assignNative = c.nativeScanTarget(&out)
+		targets = []any{&out.ID, &vectorText, target, &metadataRaw, &out.Content}
+	}
+	stats := queryStats{op: "Get", collection: c.name, query: query, args: []any{id}, started: started, executed: time.Now()}
+	err := c.store.withReadTenant(ctx, func(q queryExecutor) error {
+		return q.QueryRow(ctx, query, id).Scan(targets...)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		stats.rows = 0
+		c.store.finishQuery(ctx, stats)
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	if err == nil {
+		stats.rows = 1
+	}
+	stats.err = err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
 		return vectordata.Record{}, err
 	}
 
@@ -87,49 +183,148 @@ func (c *PostgresCollection) Get(ctx context.Context, id string) (vectordata.Rec
 	}
 	out.Vector = vector
 	out.Metadata = metadata
+	assignNative()
 
 	return out, nil
 }
 
 func (c *PostgresCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
 	if len(ids) == 0 {
 		return 0, nil
 	}
 
-	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = ANY($1)`, c.tableName(), quoteIdent(idColumn))
-	cmd, err := c.store.pool.Exec(ctx, query, ids)
+	started := time.Now()
+	query := c.statement(statementKey{kind: statementDelete}, func() string {
+		return fmt.Sprintf(`DELETE FROM %s WHERE %s = ANY($1)`, c.tableName(), quoteIdent(idColumn))
+	})
+	var deleted int64
+	stats := queryStats{op: "Delete", collection: c.name, query: query, args: []any{ids}, started: started, executed: time.Now()}
+	err := c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, ids)
+		deleted = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = deleted, err
+	c.store.finishQuery(ctx, stats)
 	if err != nil {
 		return 0, err
 	}
-	return cmd.RowsAffected(), nil
+	return deleted, nil
+}
+
+// DeleteReturning deletes ids and returns the ones that existed, read from
+// the DELETE's RETURNING clause.
+func (c *PostgresCollection) DeleteReturning(ctx context.Context, ids []string) ([]string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	started := time.Now()
+	query := c.statement(statementKey{kind: statementDeleteReturning}, func() string {
+		return fmt.Sprintf(`DELETE FROM %s WHERE %s = ANY($1) RETURNING %s`, c.tableName(), quoteIdent(idColumn), quoteIdent(idColumn))
+	})
+	var deleted []string
+	stats := queryStats{op: "DeleteReturning", collection: c.name, query: query, args: []any{ids}, started: started, executed: time.Now()}
+	err := c.store.withTenant(ctx, func(q queryExecutor) error {
+		rows, err := q.Query(ctx, query, ids)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			deleted = append(deleted, id)
+		}
+		return rows.Err()
+	})
+	stats.rows, stats.err = int64(len(deleted)), err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
 }
 
 func (c *PostgresCollection) Count(ctx context.Context, filter vectordata.Filter) (int64, error) {
-	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, c.tableName())
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
 	whereSQL, args, _, err := vectordata.CompileFilterSQL(filter, c.filterConfig(), 1)
 	if err != nil {
 		return 0, err
 	}
-	if whereSQL != "" {
-		query += " WHERE " + whereSQL
-	}
+	query := c.statement(statementKey{kind: statementCount, filter: whereSQL}, func() string {
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, c.tableName())
+		if whereSQL != "" {
+			query += " WHERE " + whereSQL
+		}
+		return query
+	})
 
 	var count int64
-	if err := c.store.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+	stats := queryStats{op: "Count", collection: c.name, query: query, args: args, filter: filter, started: started, executed: time.Now()}
+	err = c.store.withReadTenant(ctx, func(q queryExecutor) error {
+		return q.QueryRow(ctx, query, args...).Scan(&count)
+	})
+	stats.rows, stats.err = count, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
 func (c *PostgresCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
-	plan, err := c.buildSearchPlan(vector, topK, opts)
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	cursor, paged, err := vectordata.DecodeCursor(vector, opts)
+	if err != nil {
+		return nil, err
+	}
+	vector, err = vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	if c.normalize {
+		vector = vectordata.NormalizeVector(vector)
+	}
+	var after *vectordata.SearchCursor
+	if paged {
+		after = &cursor
+	}
+	plan, err := c.buildSearchPlanAfter(vector, topK, opts, after)
 	if err != nil {
 		return nil, err
 	}
-	return c.executeSearchPlan(ctx, plan)
+	results, err := c.executeSearchPlan(ctx, queryStats{op: "SearchByVector", filter: opts.Filter, started: started}, plan)
+	if err != nil {
+		return nil, err
+	}
+	return vectordata.NormalizeScores(defaultMetric(c.metric), results, opts.ScoreNormalization), nil
 }
 
+// EnsureIndexes creates the requested indexes. Every statement is idempotent,
+// so transient failures retry the whole call under StoreOptions.Retry.
 func (c *PostgresCollection) EnsureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Schema)
+	defer cancel()
+	return c.store.withRetry(ctx, func() error {
+		return c.ensureIndexes(ctx, opts)
+	})
+}
+
+func (c *PostgresCollection) ensureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
 	if opts.Vector != nil {
 		if err := c.ensureVectorIndex(ctx, opts.Vector); err != nil {
 			return err
@@ -140,16 +335,39 @@ func (c *PostgresCollection) EnsureIndexes(ctx context.Context, opts vectordata.
 			return err
 		}
 	}
+	if opts.Text != nil {
+		if err := c.ensureTextIndex(ctx, opts.Text); err != nil {
+			return err
+		}
+	}
+	if opts.Trigram != nil {
+		if err := c.ensureTrigramIndexes(ctx, opts.Trigram); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (c *PostgresCollection) buildSearchPlan(vector []float32, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	return c.buildSearchPlanAfter(vector, topK, opts, nil)
+}
+
+// buildSearchPlanAfter is buildSearchPlan for the page of results after
+// cursor, when it is not nil. Ties in distance are ordered by ID either way,
+// so the first page ends where the next one starts.
+func (c *PostgresCollection) buildSearchPlanAfter(vector []float32, topK int, opts vectordata.SearchOptions, cursor *vectordata.SearchCursor) (searchPlan, error) {
 	if topK <= 0 {
 		return searchPlan{}, fmt.Errorf("topK must be > 0")
 	}
 	if err := c.validateVectorDimension(vector); err != nil {
 		return searchPlan{}, err
 	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+	if err := vectordata.ValidateBoosts(opts); err != nil {
+		return searchPlan{}, err
+	}
 
 	operator, err := metricOperator(defaultMetric(c.metric))
 	if err != nil {
@@ -157,92 +375,312 @@ func (c *PostgresCollection) buildSearchPlan(vector []float32, topK int, opts ve
 	}
 	distanceExpr := fmt.Sprintf(`%s %s $1::vector`, quoteIdent(vectorColumn), operator)
 	projection := resolveProjection(opts.Projection)
-
-	selectCols := []string{quoteIdent(idColumn)}
-	if projection.IncludeVector {
-		selectCols = append(selectCols, quoteIdent(vectorColumn)+"::text")
-	}
-	if projection.IncludeMetadata {
-		selectCols = append(selectCols, quoteIdent(metadataColumn))
-	}
-	if projection.IncludeContent {
-		selectCols = append(selectCols, quoteIdent(contentColumn))
+	settings, err := buildSessionSettings(opts.SessionSettings)
+	if err != nil {
+		return searchPlan{}, err
 	}
-	selectCols = append(selectCols, distanceExpr+" AS distance")
 
 	args := []any{vectorLiteral(vector)}
 	nextArg := 2
-	whereParts := make([]string, 0, 2)
+	whereSQL := ""
 
 	if opts.Filter != nil {
-		whereSQL, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), nextArg)
+		compiled, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), nextArg)
 		if err != nil {
 			return searchPlan{}, err
 		}
-		if whereSQL != "" {
-			whereParts = append(whereParts, whereSQL)
-		}
+		whereSQL = compiled
 		args = append(args, filterArgs...)
 		nextArg = next
 	}
 
+	thresholdArg := 0
 	if opts.Threshold != nil {
-		whereParts = append(whereParts, fmt.Sprintf("(%s <= $%d)", distanceExpr, nextArg))
+		thresholdArg = nextArg
 		args = append(args, *opts.Threshold)
 		nextArg++
 	}
-
-	var b strings.Builder
-	b.WriteString("SELECT ")
-	b.WriteString(strings.Join(selectCols, ", "))
-	b.WriteString(" FROM ")
-	b.WriteString(c.tableName())
-	if len(whereParts) > 0 {
-		b.WriteString(" WHERE ")
-		b.WriteString(strings.Join(whereParts, " AND "))
+	cursorArg := 0
+	if cursor != nil {
+		cursorArg = nextArg
+		args = append(args, cursor.Distance, cursor.ID)
+		nextArg += 2
 	}
-	b.WriteString(" ORDER BY distance ASC")
-	b.WriteString(fmt.Sprintf(" LIMIT $%d", nextArg))
+	limitArg := nextArg
 	args = append(args, topK)
+	nextArg++
+
+	// With boosts, the boosted score is selected after the distance and
+	// ranks the results.
+	scoreExpr, rankOrder, rank := "", "distance ASC", rankDistance
+	if opts.Boosted() {
+		var scoreArgs []any
+		scoreExpr, scoreArgs, nextArg, err = c.boostedScoreExpr(distanceExpr, opts, nextArg)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		args = append(args, scoreArgs...)
+		rankOrder, rank = "score DESC", rankDistanceAndScore
+	}
+	// OrderBy orders ties in rank; searches ranked by distance finally
+	// order by ID, which cursors rely on.
+	orderExprs := make([]string, len(opts.OrderBy))
+	orderDirections := make([]string, len(opts.OrderBy))
+	for i, spec := range opts.OrderBy {
+		orderExprs[i], orderDirections[i], err = vectordata.OrderBySQL(spec, c.filterConfig())
+		if err != nil {
+			return searchPlan{}, err
+		}
+	}
+	orderBy := func(keys []string) string {
+		terms := []string{rankOrder}
+		for i, key := range keys {
+			terms = append(terms, key+" "+orderDirections[i])
+		}
+		if !opts.Boosted() {
+			terms = append(terms, quoteIdent(idColumn)+" ASC")
+		}
+		return strings.Join(terms, ", ")
+	}
+
+	rescore := c.rescoresCandidates()
+	candidateArg, fastArg := 0, 0
+	if rescore {
+		candidateArg = nextArg
+		args = append(args, c.candidateLimit(topK))
+		nextArg++
+	}
+	if c.fastDimension > 0 {
+		fastQuery, err := c.fastQueryLiteral(vector)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		fastArg = nextArg
+		args = append(args, fastQuery)
+	}
 
-	return searchPlan{
-		query:      b.String(),
+	key := statementKey{
+		kind:       statementSearch,
+		projection: projection,
+		filter:     whereSQL,
+		threshold:  opts.Threshold != nil,
+		score:      scoreExpr,
+		cursor:     cursor != nil,
+		orderBy:    orderBy(orderExprs),
+	}
+	query := c.statement(key, func() string {
+		cursorSQL := ""
+		if cursorArg > 0 {
+			cursorSQL = fmt.Sprintf("((%s, %s) > ($%d::float8, $%d))", distanceExpr, quoteIdent(idColumn), cursorArg, cursorArg+1)
+		}
+
+		selectCols := append(c.projectedColumns(projection), distanceExpr+" AS distance")
+		if scoreExpr != "" {
+			selectCols = append(selectCols, scoreExpr+" AS score")
+		}
+
+		if rescore {
+			// The index is on the halfvec cast or the reduced vector, so
+			// candidates are ranked by it and then rescored with the exact
+			// distance. Sort keys are selected for the outer ORDER BY.
+			orderKeys := make([]string, len(orderExprs))
+			for i, expr := range orderExprs {
+				orderKeys[i] = quoteIdent(fmt.Sprintf("__order_%d", i))
+				selectCols = append(selectCols, expr+" AS "+orderKeys[i])
+			}
+			var b strings.Builder
+			b.WriteString("SELECT * FROM (SELECT ")
+			b.WriteString(strings.Join(selectCols, ", "))
+			b.WriteString(" FROM ")
+			b.WriteString(c.tableName())
+			inner := make([]string, 0, 2)
+			for _, part := range []string{whereSQL, cursorSQL} {
+				if part != "" {
+					inner = append(inner, part)
+				}
+			}
+			if len(inner) > 0 {
+				b.WriteString(" WHERE ")
+				b.WriteString(strings.Join(inner, " AND "))
+			}
+			b.WriteString(" ORDER BY ")
+			b.WriteString(c.indexedDistanceExpr(operator, fastArg))
+			b.WriteString(fmt.Sprintf(" LIMIT $%d) AS candidates", candidateArg))
+			if thresholdArg > 0 {
+				b.WriteString(fmt.Sprintf(" WHERE distance <= $%d", thresholdArg))
+			}
+			b.WriteString(" ORDER BY " + orderBy(orderKeys))
+			b.WriteString(fmt.Sprintf(" LIMIT $%d", limitArg))
+			return b.String()
+		}
+
+		whereParts := make([]string, 0, 3)
+		if whereSQL != "" {
+			whereParts = append(whereParts, whereSQL)
+		}
+		if thresholdArg > 0 {
+			whereParts = append(whereParts, fmt.Sprintf("(%s <= $%d)", distanceExpr, thresholdArg))
+		}
+		if cursorSQL != "" {
+			whereParts = append(whereParts, cursorSQL)
+		}
+
+		var b strings.Builder
+		b.WriteString("SELECT ")
+		b.WriteString(strings.Join(selectCols, ", "))
+		b.WriteString(" FROM ")
+		b.WriteString(c.tableName())
+		if len(whereParts) > 0 {
+			b.WriteString(" WHERE ")
+			b.WriteString(strings.Join(whereParts, " AND "))
+		}
+		b.WriteString(" ORDER BY " + orderBy(orderExprs))
+		b.WriteString(fmt.Sprintf(" LIMIT $%d", limitArg))
+		return b.String()
+	})
+
+	plan := searchPlan{
+		query:      query,
 		args:       args,
 		projection: projection,
-	}, nil
+		settings:   settings,
+		rank:       rank,
+	}
+	if rescore {
+		plan.orderKeys = len(orderExprs)
+	}
+	return plan, nil
 }
 
-func (c *PostgresCollection) executeSearchPlan(ctx context.Context, plan searchPlan) ([]vectordata.SearchResult, error) {
-	rows, err := c.store.pool.Query(ctx, plan.query, plan.args...)
+// boostedScoreExpr returns the score of a search with TimeDecay or Boosts, as
+// vectordata.BoostedScore computes it: the score of the distance plus a
+// boost halving every half-life of age, times the multipliers of matching
+// boosts, plus their additions. Its arguments are bound from $nextArg on.
+// Rows without a valid timestamp get no time boost, and boost filters that
+// are NULL for a row do not match it.
+func (c *PostgresCollection) boostedScoreExpr(distanceExpr string, opts vectordata.SearchOptions, nextArg int) (string, []any, int, error) {
+	scoreExpr, err := sqlScoreFromDistance(defaultMetric(c.metric), distanceExpr)
 	if err != nil {
-		return nil, err
+		return "", nil, 0, err
+	}
+	var args []any
+	if decay := opts.TimeDecay; decay != nil {
+		field, err := vectordata.NormalizeFieldRef(decay.Field)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		timestamp := vectordata.MetadataPathTimestampSQL(quoteIdent(metadataColumn), field.Path, c.store.timestampFunc())
+		age := fmt.Sprintf("GREATEST(EXTRACT(EPOCH FROM $%d::timestamptz - %s)::float8, 0)", nextArg+1, timestamp)
+		scoreExpr = fmt.Sprintf("(%s) + COALESCE($%d::float8 * power(0.5, %s / $%d::float8), 0)", scoreExpr, nextArg, age, nextArg+2)
+		args = append(args, decay.EffectiveWeight(), decay.EffectiveNow(), decay.HalfLife.Seconds())
+		nextArg += 3
+	}
+	if len(opts.Boosts) == 0 {
+		return scoreExpr, args, nextArg, nil
+	}
+
+	factors := []string{"(" + scoreExpr + ")"}
+	var additions []string
+	for i, boost := range opts.Boosts {
+		whenSQL, whenArgs, next, err := vectordata.CompileFilterSQL(boost.When, c.filterConfig(), nextArg)
+		if err != nil {
+			return "", nil, 0, fmt.Errorf("boost %d: %w", i, err)
+		}
+		args = append(args, whenArgs...)
+		nextArg = next
+		if boost.EffectiveMultiply() != 1 {
+			factors = append(factors, fmt.Sprintf("(CASE WHEN %s THEN $%d::float8 ELSE 1 END)", whenSQL, nextArg))
+			args = append(args, boost.EffectiveMultiply())
+			nextArg++
+		}
+		if boost.Add != 0 {
+			additions = append(additions, fmt.Sprintf("(CASE WHEN %s THEN $%d::float8 ELSE 0 END)", whenSQL, nextArg))
+			args = append(args, boost.Add)
+			nextArg++
+		}
+	}
+	return strings.Join(append([]string{strings.Join(factors, " * ")}, additions...), " + "), args, nextArg, nil
+}
+
+// executeSearchPlan runs plan and reports it with stats, which carries the
+// operation, its filter and start time.
+func (c *PostgresCollection) executeSearchPlan(ctx context.Context, stats queryStats, plan searchPlan) ([]vectordata.SearchResult, error) {
+	results, _, err := c.executeGroupedSearchPlan(ctx, stats, plan)
+	return results, err
+}
+
+// executeGroupedSearchPlan runs a plan that may select groups and also
+// returns the group of each result.
+func (c *PostgresCollection) executeGroupedSearchPlan(ctx context.Context, stats queryStats, plan searchPlan) ([]vectordata.SearchResult, []string, error) {
+	var results []vectordata.SearchResult
+	var groups []string
+	var err error
+	stats.collection, stats.query, stats.args, stats.executed = c.name, plan.query, plan.args, time.Now()
+	defer func() {
+		stats.rows, stats.err = int64(len(results)), err
+		c.store.finishQuery(ctx, stats)
+	}()
+	if plan.settings.query == "" {
+		err = c.store.withReadTenant(ctx, func(q queryExecutor) error {
+			var err error
+			results, groups, err = c.querySearchPlan(ctx, q, plan)
+			return err
+		})
+	} else {
+		err = c.store.withSessionSettings(ctx, plan.settings, func(tx pgx.Tx) error {
+			var err error
+			results, groups, err = c.querySearchPlan(ctx, tx, plan)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, groups, nil
+}
+
+func (c *PostgresCollection) querySearchPlan(ctx context.Context, q rowQuerier, plan searchPlan) ([]vectordata.SearchResult, []string, error) {
+	rows, err := q.Query(ctx, plan.query, plan.args...)
+	if err != nil {
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	results := make([]vectordata.SearchResult, 0)
+	var groups []string
 	for rows.Next() {
-		result, err := c.scanSearchResult(rows, plan.projection)
+		result, group, err := c.scanSearchResult(rows, plan)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		results = append(results, result)
+		if plan.group {
+			groups = append(groups, group)
+		}
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return results, nil
+	return results, groups, nil
 }
 
-func (c *PostgresCollection) scanSearchResult(rows pgx.Rows, projection vectordata.Projection) (vectordata.SearchResult, error) {
+func (c *PostgresCollection) scanSearchResult(rows pgx.Rows, plan searchPlan) (vectordata.SearchResult, string, error) {
 	var rec vectordata.Record
 	var vectorText string
 	var metadataRaw []byte
 	var content *string
-	var distance float64
+	var distance, score float64
+	projection := plan.projection
 
 	scanTargets := []any{&rec.ID}
+	assignNative := func() {}
 	if projection.IncludeVector {
 		scanTargets = append(scanTargets, &vectorText)
+		if _, ok := nativeVectorColumn(c.elementType); ok {
+			var target any
+			target, assignNative = c.nativeScanTarget(&rec)
+			scanTargets = append(scanTargets, target)
+		}
 	}
 	if projection.IncludeMetadata {
 		scanTargets = append(scanTargets, &metadataRaw)
@@ -250,23 +688,43 @@ func (c *PostgresCollection) scanSearchResult(rows pgx.Rows, projection vectorda
 	if projection.IncludeContent {
 		scanTargets = append(scanTargets, &content)
 	}
-	scanTargets = append(scanTargets, &distance)
+	switch plan.rank {
+	case rankScore:
+		scanTargets = append(scanTargets, &score)
+	case rankDistanceAndScore:
+		scanTargets = append(scanTargets, &distance, &score)
+	case rankNone:
+	default:
+		scanTargets = append(scanTargets, &distance)
+	}
+	var snippet *string
+	if plan.highlight {
+		scanTargets = append(scanTargets, &snippet)
+	}
+	var group string
+	if plan.group {
+		scanTargets = append(scanTargets, &group)
+	}
+	for range plan.orderKeys {
+		scanTargets = append(scanTargets, new(any))
+	}
 
 	if err := rows.Scan(scanTargets...); err != nil {
-		return vectordata.SearchResult{}, err
+		return vectordata.SearchResult{}, "", err
 	}
 
 	if projection.IncludeVector {
 		parsed, err := parseVectorText(vectorText)
 		if err != nil {
-			return vectordata.SearchResult{}, fmt.Errorf("decode vector: %w", err)
+			return vectordata.SearchResult{}, "", fmt.Errorf("decode vector: %w", err)
 		}
 		rec.Vector = parsed
+		assignNative()
 	}
 	if projection.IncludeMetadata {
 		parsed, err := parseMetadata(metadataRaw)
 		if err != nil {
-			return vectordata.SearchResult{}, fmt.Errorf("decode metadata: %w", err)
+			return vectordata.SearchResult{}, "", fmt.Errorf("decode metadata: %w", err)
 		}
 		rec.Metadata = parsed
 	}
@@ -274,18 +732,100 @@ func (c *PostgresCollection) scanSearchResult(rows pgx.Rows, projection vectorda
 		rec.Content = content
 	}
 
-	return vectordata.SearchResult{
+	if plan.rank == rankDistance {
+		score = vectordata.ScoreFromDistance(defaultMetric(c.metric), distance)
+	}
+	result := vectordata.SearchResult{
 		Record:   rec,
 		Distance: distance,
-		Score:    vectordata.ScoreFromDistance(defaultMetric(c.metric), distance),
-	}, nil
+		Score:    score,
+	}
+	if snippet != nil {
+		result.Snippets = []string{*snippet}
+	}
+	return projection.Redact.Result(result), group, nil
 }
 
+// writeRecords sends every chunk in a single pgx.Batch. The batch is
+// pipelined with one Sync, so Postgres runs it as an implicit transaction:
+// either all chunks are written or none are.
 func (c *PostgresCollection) writeRecords(ctx context.Context, records []vectordata.Record, mode writeMode) error {
 	if len(records) == 0 {
 		return nil
 	}
+	if _, ok := nativeVectorColumn(c.elementType); ok {
+		derived, err := c.deriveFloat32Vectors(records)
+		if err != nil {
+			return err
+		}
+		records = derived
+	} else if err := rejectNativeVectors(records); err != nil {
+		return err
+	}
+	if err := c.store.opts.RecordLimits.Check(records); err != nil {
+		return err
+	}
+	if c.normalize {
+		records = vectordata.NormalizeRecordVectors(records)
+	}
+	started := time.Now()
+	if c.partition != nil && c.partition.method == vectordata.PartitionList {
+		values := make([]string, 0, len(records))
+		for _, record := range records {
+			value, err := c.partition.partitionValue(record)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if err := c.ensureListPartitions(ctx, values); err != nil {
+			return err
+		}
+	}
 
+	batch, err := c.queueWriteBatches(records, mode)
+	if err != nil {
+		return err
+	}
+
+	stats := queryStats{
+		op:         "Insert",
+		collection: c.name,
+		query:      batch.QueuedQueries[0].SQL,
+		args:       batch.QueuedQueries[0].Arguments,
+		started:    started,
+		executed:   time.Now(),
+	}
+	if mode != writeModeInsert {
+		stats.op = "Upsert"
+	}
+	var written int64
+	err = c.store.withTenant(ctx, func(q queryExecutor) error {
+		written = 0
+		results := q.SendBatch(ctx, batch)
+		for i := 0; i < batch.Len(); i++ {
+			cmd, err := results.Exec()
+			if err != nil {
+				_ = results.Close()
+				return err
+			}
+			written += cmd.RowsAffected()
+		}
+		return results.Close()
+	})
+	stats.rows, stats.err = written, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil || c.maxRecords == 0 || written == 0 {
+		return err
+	}
+	if _, err := c.Evict(ctx); err != nil {
+		return fmt.Errorf("records were written but eviction failed: %w", err)
+	}
+	return nil
+}
+
+func (c *PostgresCollection) queueWriteBatches(records []vectordata.Record, mode writeMode) (*pgx.Batch, error) {
+	batch := &pgx.Batch{}
 	for start := 0; start < len(records); start += maxRowsPerStatement {
 		end := start + maxRowsPerStatement
 		if end > len(records) {
@@ -294,20 +834,32 @@ func (c *PostgresCollection) writeRecords(ctx context.Context, records []vectord
 
 		query, args, err := c.buildWriteBatch(records[start:end], mode)
 		if err != nil {
-			return err
-		}
-		if _, err := c.store.pool.Exec(ctx, query, args...); err != nil {
-			return err
+			return nil, err
 		}
+		batch.Queue(query, args...)
 	}
-	return nil
+	return batch, nil
 }
 
+// buildWriteBatch binds each column as an array and expands them with unnest,
+// so the statement text is the same for every batch size and can be reused
+// from the statement cache.
 func (c *PostgresCollection) buildWriteBatch(records []vectordata.Record, mode writeMode) (string, []any, error) {
-	args := make([]any, 0, len(records)*4)
-	values := make([]string, 0, len(records))
+	ids := make([]string, 0, len(records))
+	vectors := make([]string, 0, len(records))
+	metadata := make([]string, 0, len(records))
+	contents := make([]*string, 0, len(records))
+	var keys []string
+	if c.partition != nil {
+		keys = make([]string, 0, len(records))
+	}
+	extras := extraVectorColumns(c.elementType, c.fastDimension)
+	extraValues := make([][]string, len(extras))
+	for i := range extraValues {
+		extraValues[i] = make([]string, 0, len(records))
+	}
 
-	for i, record := range records {
+	for _, record := range records {
 		if strings.TrimSpace(record.ID) == "" {
 			return "", nil, fmt.Errorf("record id is empty")
 		}
@@ -320,37 +872,136 @@ func (c *PostgresCollection) buildWriteBatch(records []vectordata.Record, mode w
 			return "", nil, fmt.Errorf("encode metadata for record %q: %w", record.ID, err)
 		}
 
-		base := i*4 + 1
-		values = append(values, fmt.Sprintf("($%d, $%d::vector, $%d::jsonb, $%d)", base, base+1, base+2, base+3))
-		args = append(args, record.ID, vectorLiteral(record.Vector), metadataPayload, record.Content)
+		ids = append(ids, record.ID)
+		vectors = append(vectors, vectorLiteral(record.Vector))
+		metadata = append(metadata, string(metadataPayload))
+		contents = append(contents, record.Content)
+		for i, column := range extras {
+			literal, err := c.extraVectorLiteral(column, record)
+			if err != nil {
+				return "", nil, err
+			}
+			extraValues[i] = append(extraValues[i], literal)
+		}
+		if c.partition != nil {
+			key, err := c.partition.partitionValue(record)
+			if err != nil {
+				return "", nil, err
+			}
+			keys = append(keys, key)
+		}
 	}
 
-	var b strings.Builder
-	b.WriteString("INSERT INTO ")
-	b.WriteString(c.tableName())
-	b.WriteString(" (")
-	b.WriteString(strings.Join([]string{
-		quoteIdent(idColumn),
-		quoteIdent(vectorColumn),
-		quoteIdent(metadataColumn),
-		quoteIdent(contentColumn),
-	}, ", "))
-	b.WriteString(") VALUES ")
-	b.WriteString(strings.Join(values, ", "))
-
-	if mode == writeModeUpsert {
-		b.WriteString(" ON CONFLICT (")
-		b.WriteString(quoteIdent(idColumn))
-		b.WriteString(") DO UPDATE SET ")
-		b.WriteString(quoteIdent(vectorColumn) + " = EXCLUDED." + quoteIdent(vectorColumn) + ", ")
-		b.WriteString(quoteIdent(metadataColumn) + " = EXCLUDED." + quoteIdent(metadataColumn) + ", ")
-		b.WriteString(quoteIdent(contentColumn) + " = EXCLUDED." + quoteIdent(contentColumn))
+	kind := statementInsert
+	switch mode {
+	case writeModeUpsert:
+		kind = statementUpsert
+	case writeModeSkip:
+		kind = statementUpsertSkip
+	case writeModeMergeMetadata:
+		kind = statementUpsertMergeMetadata
 	}
+	query := c.statement(statementKey{kind: kind}, func() string {
+		columns := []string{
+			quoteIdent(idColumn),
+			quoteIdent(vectorColumn),
+			quoteIdent(metadataColumn),
+			quoteIdent(contentColumn),
+		}
+		values := "r.id, r.vector::vector, r.metadata::jsonb, r.content"
+		sourceColumns := []string{"id", "vector", "metadata", "content"}
+		conflict := quoteIdent(idColumn)
+		if c.partition != nil {
+			columns = append(columns, quoteIdent(c.partition.key))
+			values += ", r.partition_key"
+			sourceColumns = append(sourceColumns, "partition_key")
+			conflict += ", " + quoteIdent(c.partition.key)
+		}
+		for _, column := range extras {
+			columns = append(columns, quoteIdent(column.name))
+			values += ", " + column.decode
+			sourceColumns = append(sourceColumns, column.source)
+		}
+		params := make([]string, len(sourceColumns))
+		for i := range params {
+			params[i] = fmt.Sprintf("$%d::text[]", i+1)
+		}
+		source := fmt.Sprintf("unnest(%s) AS r(%s)", strings.Join(params, ", "), strings.Join(sourceColumns, ", "))
+
+		var b strings.Builder
+		b.WriteString("INSERT INTO ")
+		b.WriteString(c.tableName())
+		b.WriteString(" (")
+		b.WriteString(strings.Join(columns, ", "))
+		b.WriteString(") SELECT ")
+		b.WriteString(values)
+		b.WriteString(" FROM ")
+		b.WriteString(source)
+
+		switch mode {
+		case writeModeSkip:
+			b.WriteString(" ON CONFLICT (")
+			b.WriteString(conflict)
+			b.WriteString(") DO NOTHING")
+		case writeModeUpsert, writeModeMergeMetadata:
+			metadataValue := "EXCLUDED." + quoteIdent(metadataColumn)
+			if mode == writeModeMergeMetadata {
+				metadataValue = c.tableName() + "." + quoteIdent(metadataColumn) + " || " + metadataValue
+			}
+			b.WriteString(" ON CONFLICT (")
+			b.WriteString(conflict)
+			b.WriteString(") DO UPDATE SET ")
+			b.WriteString(quoteIdent(vectorColumn) + " = EXCLUDED." + quoteIdent(vectorColumn) + ", ")
+			b.WriteString(quoteIdent(metadataColumn) + " = " + metadataValue + ", ")
+			b.WriteString(quoteIdent(contentColumn) + " = EXCLUDED." + quoteIdent(contentColumn))
+			for _, column := range extras {
+				b.WriteString(", " + quoteIdent(column.name) + " = EXCLUDED." + quoteIdent(column.name))
+			}
+		}
+		return b.String()
+	})
 
-	return b.String(), args, nil
+	args := []any{ids, vectors, metadata, contents}
+	if c.partition != nil {
+		args = append(args, keys)
+	}
+	for _, values := range extraValues {
+		args = append(args, values)
+	}
+	return query, args, nil
 }
 
 func (c *PostgresCollection) ensureVectorIndex(ctx context.Context, opts *vectordata.VectorIndexOptions) error {
+	indexName, definition, err := c.vectorIndexDefinition(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Concurrent && c.partition != nil {
+		if err := c.createPartitionedIndexConcurrently(ctx, indexName, definition); err != nil {
+			return fmt.Errorf("ensure vector index: %w", err)
+		}
+		return nil
+	}
+
+	if opts.Concurrent {
+		query := fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s %s", quoteIdent(indexName), c.tableName(), definition)
+		if err := c.createIndexConcurrently(ctx, indexName, query); err != nil {
+			return fmt.Errorf("ensure vector index: %w", err)
+		}
+		return nil
+	}
+
+	query := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s", quoteIdent(indexName), c.tableName(), definition)
+	if err := c.store.execSchema(ctx, c.schemaDB(), query); err != nil {
+		return fmt.Errorf("ensure vector index: %w", err)
+	}
+	return nil
+}
+
+// vectorIndexDefinition returns the index name, defaulting to
+// idx_<collection>_vector_<method>, and the definition after ON <table>.
+func (c *PostgresCollection) vectorIndexDefinition(opts *vectordata.VectorIndexOptions) (string, string, error) {
 	method := vectordata.IndexMethodHNSW
 	if opts.Method != "" {
 		method = opts.Method
@@ -361,9 +1012,9 @@ func (c *PostgresCollection) ensureVectorIndex(ctx context.Context, opts *vector
 		metric = opts.Metric
 	}
 
-	opClass, err := metricOpClass(metric)
+	keyExpr, err := c.vectorIndexKey(metric)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 
 	indexName := opts.Name
@@ -373,22 +1024,9 @@ func (c *PostgresCollection) ensureVectorIndex(ctx context.Context, opts *vector
 
 	withClause, err := buildVectorIndexWithClause(method, opts)
 	if err != nil {
-		return err
+		return "", "", err
 	}
-
-	query := fmt.Sprintf(
-		"CREATE INDEX IF NOT EXISTS %s ON %s USING %s (%s %s)%s",
-		quoteIdent(indexName),
-		c.tableName(),
-		method,
-		quoteIdent(vectorColumn),
-		opClass,
-		withClause,
-	)
-	if _, err := c.store.pool.Exec(ctx, query); err != nil {
-		return fmt.Errorf("ensure vector index: %w", err)
-	}
-	return nil
+	return indexName, fmt.Sprintf("USING %s (%s)%s", method, keyExpr, withClause), nil
 }
 
 func (c *PostgresCollection) ensureMetadataIndex(ctx context.Context, opts *vectordata.MetadataIndexOptions) error {
@@ -408,20 +1046,26 @@ func (c *PostgresCollection) ensureMetadataIndex(ctx context.Context, opts *vect
 		c.tableName(),
 		metadataExpr,
 	)
-	if _, err := c.store.pool.Exec(ctx, query); err != nil {
+	if err := c.store.execSchema(ctx, c.schemaDB(), query); err != nil {
 		return fmt.Errorf("ensure metadata index: %w", err)
 	}
-	return nil
+	return c.ensureMetadataKeyIndexes(ctx, opts.Keys)
 }
 
 func (c *PostgresCollection) filterConfig() vectordata.FilterSQLConfig {
-	return vectordata.FilterSQLConfig{
+	cfg := vectordata.FilterSQLConfig{
 		ColumnExpr: map[string]string{
 			idColumn:      quoteIdent(idColumn),
 			contentColumn: quoteIdent(contentColumn),
 		},
-		MetadataExpr: quoteIdent(metadataColumn),
+		MetadataExpr:  quoteIdent(metadataColumn),
+		TimestampFunc: c.store.timestampFunc(),
+	}
+	if c.partition != nil {
+		// Filtering on the promoted column lets the planner prune partitions.
+		cfg.ColumnExpr[c.partition.key] = quoteIdent(c.partition.key)
 	}
+	return cfg
 }
 
 func (c *PostgresCollection) validateVectorDimension(vector []float32) error {
@@ -431,10 +1075,44 @@ func (c *PostgresCollection) validateVectorDimension(vector []float32) error {
 	return nil
 }
 
+// statement returns cached SQL for this collection, building it on first use.
+func (c *PostgresCollection) statement(key statementKey, build func() string) string {
+	key.collection = c.tableName()
+	key.metric = defaultMetric(c.metric)
+	key.dimension = c.dimension
+	if c.partition != nil {
+		key.partitionKey = c.partition.key
+	}
+	key.elementType = c.elementType
+	key.fastDimension = c.fastDimension
+	// Redaction is applied to scanned rows and does not change the SQL.
+	key.projection.Redact = nil
+	return c.store.statements.get(key, build)
+}
+
 func (c *PostgresCollection) tableName() string {
 	return qualifiedTable(c.store.opts.Schema, c.name)
 }
 
+// projectedColumns lists the record columns selected for projection, in the
+// order scanSearchResult reads them.
+func (c *PostgresCollection) projectedColumns(projection vectordata.Projection) []string {
+	cols := []string{quoteIdent(idColumn)}
+	if projection.IncludeVector {
+		cols = append(cols, quoteIdent(vectorColumn)+"::text")
+		if native, ok := nativeVectorColumn(c.elementType); ok {
+			cols = append(cols, quoteIdent(native.name))
+		}
+	}
+	if projection.IncludeMetadata {
+		cols = append(cols, quoteIdent(metadataColumn))
+	}
+	if projection.IncludeContent {
+		cols = append(cols, quoteIdent(contentColumn))
+	}
+	return cols
+}
+
 func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
 	if projection == nil {
 		return vectordata.DefaultProjection()
@@ -0,0 +1,316 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_SearchPlanRejectsUnknownScoreNormalization(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, err := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		ScoreNormalization: vectordata.ScoreNormalization{Method: "zscore"},
+	})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestPostgresCollection_SupportsFilter(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	supported := collection.SupportsFilter(vectordata.And(
+		vectordata.Eq(vectordata.Metadata("category"), "news"),
+		vectordata.Contains(vectordata.Column("content"), "go"),
+	))
+	unknownColumn := collection.SupportsFilter(vectordata.Eq(vectordata.Column("title"), "x"))
+
+	// Assert
+	if supported != nil {
+		t.Fatalf("expected the filter to be supported, got %v", supported)
+	}
+	if !errors.Is(unknownColumn, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for an unknown column, got %v", unknownColumn)
+	}
+}
+
+func TestPostgresCollection_RecordLimitsRejectBeforeQuerying(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	collection.store.opts.RecordLimits = vectordata.RecordLimits{MaxMetadataBytes: 16}
+
+	// Act
+	err := collection.Insert(context.Background(), []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"blob": strings.Repeat("x", 32)}},
+	})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrInvalidRecord) || !errors.Is(err, vectordata.ErrTooLarge) {
+		t.Fatalf("expected oversized metadata to be rejected, got %v", err)
+	}
+}
+
+func TestPostgresCollection_WriteBatchConflictStrategies(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	records := []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"lang": "en"}}}
+
+	// Act
+	skip, _, skipErr := collection.buildWriteBatch(records, writeModeSkip)
+	merge, _, mergeErr := collection.buildWriteBatch(records, writeModeMergeMetadata)
+	invalidErr := collection.UpsertWithOptions(context.Background(), records, vectordata.UpsertOptions{OnConflict: "newest"})
+
+	// Assert
+	if skipErr != nil || mergeErr != nil {
+		t.Fatalf("buildWriteBatch: %v, %v", skipErr, mergeErr)
+	}
+	if !strings.HasSuffix(skip, `ON CONFLICT ("id") DO NOTHING`) {
+		t.Fatalf("expected DO NOTHING, got %s", skip)
+	}
+	if !strings.Contains(merge, `"metadata" = "public"."docs"."metadata" || EXCLUDED."metadata"`) || !strings.Contains(merge, `"vector" = EXCLUDED."vector"`) {
+		t.Fatalf("expected merged metadata and a replaced vector, got %s", merge)
+	}
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for an unknown strategy, got %v", invalidErr)
+	}
+}
+
+func TestPostgresCollection_Float64WriteBatchBindsFullPrecision(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	collection.elementType = vectordata.ElementFloat64
+	records, err := collection.deriveFloat32Vectors([]vectordata.Record{{ID: "a", Vector64: []float64{0.1, 1.0000000001}}})
+	if err != nil {
+		t.Fatalf("deriveFloat32Vectors: %v", err)
+	}
+
+	// Act
+	query, args, err := collection.buildWriteBatch(records, writeModeUpsert)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildWriteBatch: %v", err)
+	}
+	if !strings.Contains(query, `r.native::float8[]`) || !strings.Contains(query, `"vector64" = EXCLUDED."vector64"`) {
+		t.Fatalf("expected the float8[] column to be written, got %s", query)
+	}
+	if got := args[len(args)-1].([]string); len(got) != 1 || got[0] != "{0.1,1.0000000001}" {
+		t.Fatalf("expected an exact float8[] literal, got %v", got)
+	}
+	if got := args[1].([]string); got[0] != "[0.1,1]" {
+		t.Fatalf("expected the derived float32 vector, got %v", got)
+	}
+}
+
+func TestPostgresCollection_Int8WriteBatchBindsBytea(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceInnerProduct)
+	collection.elementType = vectordata.ElementInt8
+	records, err := collection.deriveFloat32Vectors([]vectordata.Record{{ID: "a", VectorInt8: []int8{-128, 127}}})
+	if err != nil {
+		t.Fatalf("deriveFloat32Vectors: %v", err)
+	}
+
+	// Act
+	query, args, err := collection.buildWriteBatch(records, writeModeUpsert)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildWriteBatch: %v", err)
+	}
+	if !strings.Contains(query, `decode(r.native, 'hex')`) || !strings.Contains(query, `"vector_int8" = EXCLUDED."vector_int8"`) {
+		t.Fatalf("expected the bytea column to be written, got %s", query)
+	}
+	if got := args[len(args)-1].([]string); len(got) != 1 || got[0] != "807f" {
+		t.Fatalf("expected a hex bytea literal, got %v", got)
+	}
+	if got := args[1].([]string); got[0] != "[-128,127]" {
+		t.Fatalf("expected the derived float32 vector, got %v", got)
+	}
+}
+
+func TestPostgresCollection_Float64RequiresVector64(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	collection.elementType = vectordata.ElementFloat64
+
+	// Act
+	_, err := collection.deriveFloat32Vectors([]vectordata.Record{{ID: "a", Vector: []float32{1, 0}}})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func TestPostgresCollection_Float32HandleRejectsVectorInt8(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, VectorInt8: []int8{1, 0}}})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestPostgresCollection_Float32HandleRejectsVector64(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Vector64: []float64{1, 0}}})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestPostgresCollection_SearchPlanRanksByTimeDecay(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		TimeDecay: &vectordata.TimeDecay{Field: vectordata.Metadata("published_at"), HalfLife: time.Hour, Now: now},
+	})
+	_, normalizedErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		TimeDecay:          &vectordata.TimeDecay{Field: vectordata.Metadata("published_at"), HalfLife: time.Hour},
+		ScoreNormalization: vectordata.ScoreNormalization{Method: vectordata.ScoreNormalizationMinMax},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSearchPlan: %v", err)
+	}
+	if !strings.Contains(plan.query, "power(0.5,") || !strings.HasSuffix(plan.query, "ORDER BY score DESC LIMIT $2") {
+		t.Fatalf("expected the query to rank by the boosted score, got %s", plan.query)
+	}
+	if plan.rank != rankDistanceAndScore || len(plan.args) != 5 || plan.args[2] != 1.0 || plan.args[3] != now || plan.args[4] != 3600.0 {
+		t.Fatalf("unexpected rank %v and args %v", plan.rank, plan.args)
+	}
+	if !errors.Is(normalizedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch with score normalization, got %v", normalizedErr)
+	}
+}
+
+func TestPostgresCollection_SearchPlanPushesBoostsIntoSQL(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		Boosts: []vectordata.Boost{{When: vectordata.Eq(vectordata.Metadata("source"), "official"), Multiply: 1.2, Add: 0.1}},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSearchPlan: %v", err)
+	}
+	if !strings.Contains(plan.query, "THEN $4::float8 ELSE 1 END") || !strings.Contains(plan.query, "THEN $5::float8 ELSE 0 END") || !strings.HasSuffix(plan.query, "ORDER BY score DESC LIMIT $2") {
+		t.Fatalf("expected the boost in the ranking score, got %s", plan.query)
+	}
+	if len(plan.args) != 5 || plan.args[3] != 1.2 || plan.args[4] != 0.1 {
+		t.Fatalf("unexpected args %v", plan.args)
+	}
+}
+
+func TestPostgresCollection_SearchPlanAfterCursor(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceL2)
+	cursor := &vectordata.SearchCursor{Distance: 0.25, ID: "b"}
+
+	// Act
+	plan, err := collection.buildSearchPlanAfter([]float32{1, 0}, 3, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("kind"), "a"),
+	}, cursor)
+	first, firstErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("kind"), "a"),
+	})
+
+	// Assert
+	if err != nil || firstErr != nil {
+		t.Fatalf("unexpected errors: %v %v", err, firstErr)
+	}
+	expected := `SELECT "id", "metadata", "content", "vector" <-> $1::vector AS distance FROM "public"."docs" WHERE (("metadata" #> ARRAY['kind']) = $2::jsonb) AND (("vector" <-> $1::vector, "id") > ($3::float8, $4)) ORDER BY distance ASC, "id" ASC LIMIT $5`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if !reflect.DeepEqual(plan.args[2:], []any{0.25, "b", 3}) {
+		t.Fatalf("unexpected args: %#v", plan.args)
+	}
+	if first.query == plan.query {
+		t.Fatal("expected the first page to use its own statement")
+	}
+}
+
+func TestPostgresCollection_SearchCursorRequiresDistanceRanking(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	opts := vectordata.SearchOptions{TimeDecay: &vectordata.TimeDecay{Field: vectordata.Metadata("updated_at"), HalfLife: time.Hour}}
+	opts.Cursor = vectordata.NextCursor([]float32{1, 0}, opts, []vectordata.SearchResult{{Record: vectordata.Record{ID: "a"}}})
+
+	// Act
+	_, err := collection.SearchByVector(context.Background(), []float32{1, 0}, 3, opts)
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestPostgresCollection_SearchPlanOrdersTies(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	halfvec := newHalfvecUnitTestCollection(3072)
+	rescoreVector := make([]float32, 3072)
+	rescoreVector[0] = 1
+	projection := vectordata.Projection{}
+	opts := vectordata.SearchOptions{
+		Projection: &projection,
+		OrderBy:    []vectordata.OrderSpec{vectordata.Desc(vectordata.Metadata("published_at")), vectordata.Asc(vectordata.Column("content"))},
+	}
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 3, opts)
+	rescored, rescoredErr := halfvec.buildSearchPlan(rescoreVector, 3, opts)
+	_, invalidErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		OrderBy: []vectordata.OrderSpec{vectordata.Asc(vectordata.Column("vector"))},
+	})
+
+	// Assert
+	if err != nil || rescoredErr != nil {
+		t.Fatalf("unexpected errors: %v %v", err, rescoredErr)
+	}
+	expected := `SELECT "id", "vector" <=> $1::vector AS distance FROM "public"."docs" ORDER BY distance ASC, ("metadata" #> ARRAY['published_at']) DESC NULLS LAST, "content" ASC NULLS LAST, "id" ASC LIMIT $2`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	expected = `SELECT * FROM (SELECT "id", "vector" <=> $1::vector AS distance, ("metadata" #> ARRAY['published_at']) AS "__order_0", "content" AS "__order_1" FROM "public"."docs"` +
+		` ORDER BY ("vector"::halfvec(3072)) <=> $1::halfvec(3072) LIMIT $3) AS candidates ORDER BY distance ASC, "__order_0" DESC NULLS LAST, "__order_1" ASC NULLS LAST, "id" ASC LIMIT $2`
+	if rescored.query != expected {
+		t.Fatalf("unexpected rescoring query\nwant: %s\n got: %s", expected, rescored.query)
+	}
+	if plan.orderKeys != 0 || rescored.orderKeys != 2 {
+		t.Fatalf("unexpected selected sort keys: %d %d", plan.orderKeys, rescored.orderKeys)
+	}
+	if !errors.Is(invalidErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", invalidErr)
+	}
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// extraVectorColumn describes a column kept next to the pgvector column: the
+// native vectors of a collection whose element type is not float32, or the
+// reduced vectors of a collection with a FastDimension.
+type extraVectorColumn struct {
+	name    string
+	sqlType string
+	// udtName is the type as reported by information_schema.columns.
+	udtName string
+	// source names the column in write batches, and decode converts its
+	// text-encoded value to sqlType.
+	source string
+	decode string
+}
+
+// extraVectorColumns lists the columns a collection keeps next to the
+// pgvector column, in write batch order.
+func extraVectorColumns(elementType vectordata.VectorElementType, fastDimension int) []extraVectorColumn {
+	var columns []extraVectorColumn
+	if native, ok := nativeVectorColumn(elementType); ok {
+		columns = append(columns, native)
+	}
+	if fastDimension > 0 {
+		columns = append(columns, fastVectorColumn(fastDimension))
+	}
+	return columns
+}
+
+func nativeVectorColumn(elementType vectordata.VectorElementType) (extraVectorColumn, bool) {
+	switch elementType {
+	case vectordata.ElementFloat64:
+		return extraVectorColumn{name: vector64Column, sqlType: "float8[]", udtName: "_float8", source: "native", decode: "r.native::float8[]"}, true
+	case vectordata.ElementInt8:
+		return extraVectorColumn{name: vectorInt8Column, sqlType: "bytea", udtName: "bytea", source: "native", decode: "decode(r.native, 'hex')"}, true
+	default:
+		return extraVectorColumn{}, false
+	}
+}
+
+// deriveFloat32Vectors returns a copy of records whose Vector is derived from
+// the native vector of the collection's element type, which every record
+// must carry.
+func (c *PostgresCollection) deriveFloat32Vectors(records []vectordata.Record) ([]vectordata.Record, error) {
+	out := make([]vectordata.Record, len(records))
+	for i, record := range records {
+		switch c.elementType {
+		case vectordata.ElementFloat64:
+			if len(record.Vector64) != c.dimension {
+				return nil, fmt.Errorf("%w: record %q: expected Vector64 of dimension %d, got %d", vectordata.ErrDimensionMismatch, record.ID, c.dimension, len(record.Vector64))
+			}
+			record.Vector = vectordata.Float32Vector(record.Vector64)
+		case vectordata.ElementInt8:
+			if len(record.VectorInt8) != c.dimension {
+				return nil, fmt.Errorf("%w: record %q: expected VectorInt8 of dimension %d, got %d", vectordata.ErrDimensionMismatch, record.ID, c.dimension, len(record.VectorInt8))
+			}
+			record.Vector = vectordata.Float32VectorFromInt8(record.VectorInt8)
+		}
+		out[i] = record
+	}
+	return out, nil
+}
+
+// rejectNativeVectors fails writes of native vectors through a float32
+// handle, which would drop them.
+func rejectNativeVectors(records []vectordata.Record) error {
+	for _, record := range records {
+		if record.Vector64 != nil || record.VectorInt8 != nil {
+			return fmt.Errorf("%w: record %q has a Vector64 or VectorInt8, which requires a handle returned by EnsureCollection with the matching ElementType", vectordata.ErrSchemaMismatch, record.ID)
+		}
+	}
+	return nil
+}
+
+// extraVectorLiteral renders the value of column for record in a write
+// batch.
+func (c *PostgresCollection) extraVectorLiteral(column extraVectorColumn, record vectordata.Record) (string, error) {
+	switch column.name {
+	case vectorFastColumn:
+		fast, err := vectordata.TruncateVector(record.Vector, c.fastDimension)
+		if err != nil {
+			return "", err
+		}
+		return vectorLiteral(fast), nil
+	case vectorInt8Column:
+		return hex.EncodeToString(vectordata.Int8Bytes(record.VectorInt8)), nil
+	default:
+		return float64ArrayLiteral(record.Vector64), nil
+	}
+}
+
+// nativeScanTarget returns the scan destination of the native vector column
+// and a function that moves the scanned value into record.
+func (c *PostgresCollection) nativeScanTarget(record *vectordata.Record) (any, func()) {
+	if c.elementType == vectordata.ElementInt8 {
+		var raw []byte
+		return &raw, func() { record.VectorInt8 = vectordata.Int8sFromBytes(raw) }
+	}
+	return &record.Vector64, func() {}
+}
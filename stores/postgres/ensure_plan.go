@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ vectordata.EnsurePlanner = (*PostgresVectorStore)(nil)
+
+var (
+	createExtensionPattern = regexp.MustCompile(`^CREATE EXTENSION IF NOT EXISTS (\S+)`)
+	createSchemaPattern    = regexp.MustCompile(`^CREATE SCHEMA IF NOT EXISTS (\S+)`)
+	createRelationPattern  = regexp.MustCompile(`^CREATE (TABLE|INDEX|INDEX CONCURRENTLY) IF NOT EXISTS (\S+)`)
+	createIndexPattern     = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (?:CONCURRENTLY )?(?:IF NOT EXISTS )?(\S+)`)
+	createTablePattern     = regexp.MustCompile(`^CREATE TABLE (?:IF NOT EXISTS )?(\S+)`)
+	dropTablePattern       = regexp.MustCompile(`^DROP TABLE (?:IF EXISTS )?(\S+)`)
+	alterTablePattern      = regexp.MustCompile(`^ALTER TABLE (\S+)`)
+	addColumnPattern       = regexp.MustCompile(`ADD COLUMN IF NOT EXISTS ("(?:[^"]|"")+"|\w+)`)
+	replaceFunctionPattern = regexp.MustCompile(`^CREATE OR REPLACE FUNCTION ([^(]+)\(`)
+)
+
+// EnsureCollectionPlan returns the changes EnsureCollection would make with
+// spec, read from the live schema in a read-only transaction. It runs the
+// same checks as EnsureCollection, so strict mismatches fail the same way,
+// but records DDL instead of running it. Statements guarded by IF NOT EXISTS
+// whose objects already exist are left out, so an up-to-date collection
+// plans no steps. With EnsureRecreate, a mismatching collection plans its
+// drop followed by its creation.
+func (s *PostgresVectorStore) EnsureCollectionPlan(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.EnsurePlan, error) {
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Schema)
+	defer cancel()
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+	partition, err := normalizePartition(normalizedSpec.Partition)
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+
+	plan := vectordata.EnsurePlan{Collection: normalizedSpec.Name}
+	err = pgx.BeginTxFunc(ctx, s.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		recorder := &planExecutor{db: tx}
+		if err := s.ensureBaseSchema(ctx, recorder); err != nil {
+			return err
+		}
+		tableMode := mode
+		if mode == vectordata.EnsureRecreate {
+			tableMode = vectordata.EnsureStrict
+		}
+		collection := &planExecutor{db: tx}
+		err := s.planCollectionTable(ctx, tx, collection, normalizedSpec, partition, tableMode)
+		var recreate []string
+		if mode == vectordata.EnsureRecreate && errors.Is(err, vectordata.ErrSchemaMismatch) {
+			// The recreated collection starts from an empty table, so its
+			// statements are planned as if nothing existed and kept as is.
+			collection = &planExecutor{db: tx}
+			if _, err := s.dropCollectionObjects(ctx, collection, normalizedSpec.Name); err != nil {
+				return err
+			}
+			fresh := &planExecutor{}
+			if err := s.ensureCollectionTable(ctx, fresh, normalizedSpec, partition, vectordata.EnsureStrict); err != nil {
+				return err
+			}
+			recreate = append(collection.statements, fresh.statements...)
+			collection.statements = nil
+		} else if err != nil {
+			return err
+		}
+
+		for _, statement := range append(recorder.statements, collection.statements...) {
+			done, err := s.statementSatisfied(ctx, tx, statement)
+			if err != nil {
+				return err
+			}
+			if !done {
+				plan.Steps = append(plan.Steps, planStep(statement))
+			}
+		}
+		for _, statement := range recreate {
+			plan.Steps = append(plan.Steps, planStep(statement))
+		}
+		return nil
+	})
+	if err != nil {
+		return vectordata.EnsurePlan{}, err
+	}
+	return plan, nil
+}
+
+// planCollectionTable records the table and catalog statements of a
+// collection into recorder, reading the live schema through tx.
+func (s *PostgresVectorStore) planCollectionTable(ctx context.Context, tx pgx.Tx, recorder *planExecutor, spec vectordata.CollectionSpec, partition *partitioning, mode vectordata.EnsureMode) error {
+	if err := s.ensureTableWithValidation(ctx, recorder, spec, partition, mode); err != nil {
+		return err
+	}
+	// A missing catalog would fail the lookup and abort the transaction,
+	// so it is answered as empty.
+	var catalogExists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, s.catalogTableName()).Scan(&catalogExists); err != nil {
+		return fmt.Errorf("check collection catalog: %w", err)
+	}
+	catalog := recorder
+	if !catalogExists {
+		catalog = &planExecutor{}
+	}
+	if err := s.ensureCatalogEntry(ctx, catalog, spec); err != nil {
+		return err
+	}
+	if catalog != recorder {
+		recorder.statements = append(recorder.statements, catalog.statements...)
+	}
+	return nil
+}
+
+// statementSatisfied reports whether an idempotent statement would change
+// nothing because what it creates already exists.
+func (s *PostgresVectorStore) statementSatisfied(ctx context.Context, db schemaExecutor, statement string) (bool, error) {
+	var query string
+	var args []any
+	if m := createExtensionPattern.FindStringSubmatch(statement); m != nil {
+		query, args = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = $1)`, []any{unquoteIdent(m[1])}
+	} else if m := createSchemaPattern.FindStringSubmatch(statement); m != nil {
+		query, args = `SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)`, []any{unquoteIdent(m[1])}
+	} else if m := createRelationPattern.FindStringSubmatch(statement); m != nil {
+		query, args = `SELECT to_regclass($1) IS NOT NULL`, []any{s.qualify(m[2])}
+	} else if m := replaceFunctionPattern.FindStringSubmatch(statement); m != nil {
+		query, args = `SELECT to_regproc($1) IS NOT NULL`, []any{m[1]}
+	} else if columns := addColumnPattern.FindAllStringSubmatch(statement, -1); columns != nil && strings.HasPrefix(statement, "ALTER TABLE") {
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = unquoteIdent(column[1])
+		}
+		table := alterTablePattern.FindStringSubmatch(statement)[1]
+		query = `SELECT count(*) = cardinality($2::text[]) FROM pg_attribute WHERE attrelid = to_regclass($1) AND attname = ANY($2) AND NOT attisdropped`
+		args = []any{table, names}
+	} else {
+		return false, nil
+	}
+
+	var done bool
+	if err := db.QueryRow(ctx, query, args...).Scan(&done); err != nil {
+		return false, fmt.Errorf("plan: check %q: %w", statement, err)
+	}
+	return done, nil
+}
+
+// qualify prefixes an unqualified relation name with the store's schema.
+func (s *PostgresVectorStore) qualify(name string) string {
+	if strings.Contains(name, `"."`) {
+		return name
+	}
+	return quoteIdent(s.opts.Schema) + "." + name
+}
+
+func planStep(statement string) vectordata.PlanStep {
+	step := vectordata.PlanStep{Kind: vectordata.PlanOther, Statement: statement}
+	switch {
+	case createExtensionPattern.MatchString(statement):
+		step.Kind, step.Object = vectordata.PlanCreateExtension, createExtensionPattern.FindStringSubmatch(statement)[1]
+	case createSchemaPattern.MatchString(statement):
+		step.Kind, step.Object = vectordata.PlanCreateSchema, unquoteIdent(createSchemaPattern.FindStringSubmatch(statement)[1])
+	case createIndexPattern.MatchString(statement):
+		step.Kind, step.Object = vectordata.PlanCreateIndex, unquoteIdent(createIndexPattern.FindStringSubmatch(statement)[1])
+	case createTablePattern.MatchString(statement):
+		step.Kind, step.Object = vectordata.PlanCreateTable, relationName(createTablePattern.FindStringSubmatch(statement)[1])
+	case dropTablePattern.MatchString(statement):
+		step.Kind, step.Object = vectordata.PlanDropTable, relationName(dropTablePattern.FindStringSubmatch(statement)[1])
+	case strings.HasPrefix(statement, "ALTER TABLE") && strings.Contains(statement, "ADD COLUMN"):
+		step.Kind = vectordata.PlanAddColumn
+		table := relationName(alterTablePattern.FindStringSubmatch(statement)[1])
+		var columns []string
+		for _, column := range addColumnPattern.FindAllStringSubmatch(statement, -1) {
+			columns = append(columns, table+"."+unquoteIdent(column[1]))
+		}
+		step.Object = strings.Join(columns, ", ")
+	case strings.HasPrefix(statement, "INSERT INTO ") && strings.Contains(statement, catalogTable):
+		step.Kind = vectordata.PlanRecordCatalog
+	}
+	return step
+}
+
+// relationName returns the unquoted name of a possibly schema-qualified
+// relation.
+func relationName(qualified string) string {
+	if i := strings.LastIndex(qualified, `"."`); i >= 0 {
+		qualified = qualified[i+2:]
+	}
+	return unquoteIdent(qualified)
+}
+
+func unquoteIdent(ident string) string {
+	if len(ident) >= 2 && strings.HasPrefix(ident, `"`) && strings.HasSuffix(ident, `"`) {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return ident
+}
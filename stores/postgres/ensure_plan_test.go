@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPlanStep_ClassifiesStatements(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		statement string
+		kind      vectordata.PlanStepKind
+		object    string
+	}{
+		{`CREATE EXTENSION IF NOT EXISTS vector`, vectordata.PlanCreateExtension, "vector"},
+		{`CREATE SCHEMA IF NOT EXISTS "app"`, vectordata.PlanCreateSchema, "app"},
+		{`CREATE TABLE IF NOT EXISTS "app"."docs" ("id" text PRIMARY KEY)`, vectordata.PlanCreateTable, "docs"},
+		{`CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_docs_vector_hnsw" ON "app"."docs" USING hnsw`, vectordata.PlanCreateIndex, "idx_docs_vector_hnsw"},
+		{`ALTER TABLE "app"."docs" ADD COLUMN IF NOT EXISTS "content" text`, vectordata.PlanAddColumn, "docs.content"},
+		{"ALTER TABLE \"app\".\"__vector_collections\"\n\t\tADD COLUMN IF NOT EXISTS normalize_vectors boolean,\n\t\tADD COLUMN IF NOT EXISTS fast_dimension integer", vectordata.PlanAddColumn, "__vector_collections.normalize_vectors, __vector_collections.fast_dimension"},
+		{`DROP TABLE IF EXISTS "app"."docs_archive"`, vectordata.PlanDropTable, "docs_archive"},
+		{`INSERT INTO "app"."__vector_collections" (name) VALUES ('docs')`, vectordata.PlanRecordCatalog, ""},
+		{`ALTER TABLE "app"."docs" ENABLE ROW LEVEL SECURITY`, vectordata.PlanOther, ""},
+	}
+
+	for _, tc := range cases {
+		// Act
+		step := planStep(tc.statement)
+
+		// Assert
+		if step.Kind != tc.kind || step.Object != tc.object || step.Statement != tc.statement {
+			t.Fatalf("%s: got %+v, expected %s %q", tc.statement, step, tc.kind, tc.object)
+		}
+	}
+}
+
+func TestPostgresVectorStore_Qualify(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: StoreOptions{Schema: "app"}}
+
+	// Act
+	index := store.qualify(`"idx_docs"`)
+	table := store.qualify(`"other"."docs"`)
+
+	// Assert
+	if index != `"app"."idx_docs"` || table != `"other"."docs"` {
+		t.Fatalf("unexpected names %s, %s", index, table)
+	}
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// sqlStateCategories maps SQLSTATE codes and two-character classes to
+// vectordata errors. Exact codes are listed before their class.
+var sqlStateCategories = []struct {
+	state    string
+	category error
+}{
+	{"23505", vectordata.ErrConflict},  // unique_violation
+	{"23P01", vectordata.ErrConflict},  // exclusion_violation
+	{"40", vectordata.ErrConflict},     // serialization failures, deadlocks
+	{"57014", vectordata.ErrTimeout},   // query_canceled, e.g. statement_timeout
+	{"55P03", vectordata.ErrTimeout},   // lock_not_available, e.g. lock_timeout
+	{"54", vectordata.ErrTooLarge},     // program_limit_exceeded
+	{"08", vectordata.ErrUnavailable},  // connection exceptions
+	{"53", vectordata.ErrUnavailable},  // insufficient resources
+	{"57P", vectordata.ErrUnavailable}, // shutdowns and recovery
+}
+
+// transientError marks a categorized error as retryable for
+// vectordata.IsRetryable.
+type transientError struct {
+	error
+}
+
+func (e transientError) Unwrap() error   { return e.error }
+func (e transientError) Temporary() bool { return true }
+
+// classifyError wraps err in the vectordata error matching its cause, so
+// callers can tell conflicts, timeouts, oversized requests and unreachable
+// servers apart without inspecting driver errors. Errors already wrapping a
+// vectordata error, and cancellations, are returned unchanged.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || isCategorized(err) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		for _, entry := range sqlStateCategories {
+			if !strings.HasPrefix(pgErr.Code, entry.state) {
+				continue
+			}
+			categorized := fmt.Errorf("%w: %w", entry.category, err)
+			if strings.HasPrefix(pgErr.Code, "40") {
+				return transientError{categorized}
+			}
+			return categorized
+		}
+		return err
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", vectordata.ErrTimeout, err)
+	case pgconn.SafeToRetry(err), netErr != nil, errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: %w", vectordata.ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+func isCategorized(err error) bool {
+	for _, sentinel := range []error{
+		vectordata.ErrNotFound,
+		vectordata.ErrDimensionMismatch,
+		vectordata.ErrSchemaMismatch,
+		vectordata.ErrInvalidFilter,
+		vectordata.ErrInvalidRecord,
+		vectordata.ErrUnavailable,
+		vectordata.ErrNotReady,
+		vectordata.ErrConflict,
+		vectordata.ErrTimeout,
+		vectordata.ErrTooLarge,
+	} {
+		if errors.Is(err, sentinel) {
+			return true
+		}
+	}
+	return false
+}
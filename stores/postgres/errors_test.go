@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		want      error
+		retryable bool
+	}{
+		{"unique violation", &pgconn.PgError{Code: "23505"}, vectordata.ErrConflict, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, vectordata.ErrConflict, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, vectordata.ErrConflict, true},
+		{"statement timeout", &pgconn.PgError{Code: "57014"}, vectordata.ErrTimeout, true},
+		{"lock timeout", &pgconn.PgError{Code: "55P03"}, vectordata.ErrTimeout, true},
+		{"row too big", &pgconn.PgError{Code: "54000"}, vectordata.ErrTooLarge, false},
+		{"too many connections", &pgconn.PgError{Code: "53300"}, vectordata.ErrUnavailable, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, vectordata.ErrUnavailable, true},
+		{"connection lost", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), vectordata.ErrUnavailable, true},
+		{"deadline", context.DeadlineExceeded, vectordata.ErrTimeout, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := classifyError(tc.err)
+
+			// Assert
+			if !errors.Is(err, tc.want) || !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v wrapping %v, got %v", tc.want, tc.err, err)
+			}
+			if got := vectordata.IsRetryable(err); got != tc.retryable {
+				t.Fatalf("IsRetryable = %v, want %v", got, tc.retryable)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorKeepsOtherErrors(t *testing.T) {
+	cases := []error{
+		nil,
+		context.Canceled,
+		&pgconn.PgError{Code: "42P01"},
+		fmt.Errorf("%w: expected 3, got 2", vectordata.ErrDimensionMismatch),
+		errors.New("boom"),
+	}
+	for _, err := range cases {
+		// Act
+		got := classifyError(err)
+
+		// Assert
+		if got != err {
+			t.Fatalf("expected %v unchanged, got %v", err, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.CountEstimator = (*PostgresCollection)(nil)
+
+// estimateSampleRows is the number of rows EstimateCount samples to
+// extrapolate filtered counts.
+const estimateSampleRows = 10000
+
+// EstimateCount returns an approximate count of the records matching filter.
+// Without a filter it sums pg_class.reltuples, the row estimate VACUUM and
+// ANALYZE maintain, which is free but lags recent writes. With a filter, or
+// with a tenant in ctx, whose rows reltuples cannot tell apart, it counts the
+// matching rows in a TABLESAMPLE SYSTEM sample of about 10,000 rows and
+// scales the result up; the error grows as matches get rarer. Collections
+// that were never analyzed, or hold fewer rows than the sample, are counted
+// exactly.
+func (c *PostgresCollection) EstimateCount(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	whereSQL, filterArgs, _, err := vectordata.CompileFilterSQL(filter, c.filterConfig(), 2)
+	if err != nil {
+		return 0, err
+	}
+
+	reltuples, analyzed, err := c.estimatedRows(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, tenant := TenantFromContext(ctx)
+	switch {
+	case !analyzed || reltuples <= estimateSampleRows:
+		return c.Count(ctx, filter)
+	case whereSQL == "" && !tenant:
+		return int64(reltuples), nil
+	}
+
+	percent := min(100, 100*estimateSampleRows/reltuples)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s TABLESAMPLE SYSTEM ($1)`, c.tableName())
+	if whereSQL != "" {
+		query += " WHERE " + whereSQL
+	}
+	args := append([]any{percent}, filterArgs...)
+	var sampled int64
+	stats := queryStats{op: "EstimateCount", collection: c.name, query: query, args: args, filter: filter, started: time.Now(), executed: time.Now()}
+	err = c.store.withReadTenant(ctx, func(q queryExecutor) error {
+		return q.QueryRow(ctx, query, args...).Scan(&sampled)
+	})
+	stats.rows, stats.err = sampled, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(float64(sampled) * 100 / percent)), nil
+}
+
+// estimatedRows returns the planner's row estimate of the collection, summed
+// over its partitions, and whether every partition has been analyzed.
+func (c *PostgresCollection) estimatedRows(ctx context.Context) (float64, bool, error) {
+	var reltuples float64
+	var analyzed bool
+	err := c.store.withReadTenant(ctx, func(q queryExecutor) error {
+		return q.QueryRow(ctx, `
+			SELECT coalesce(sum(greatest(c.reltuples, 0)), 0), coalesce(bool_and(c.reltuples >= 0), false)
+			FROM pg_partition_tree($1::regclass) t
+			JOIN pg_class c ON c.oid = t.relid
+			WHERE t.isleaf
+		`, c.tableName()).Scan(&reltuples, &analyzed)
+	})
+	if isUndefinedTable(err) {
+		return 0, false, fmt.Errorf("%w: collection %q", vectordata.ErrNotFound, c.name)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("estimate rows: %w", err)
+	}
+	return reltuples, analyzed, nil
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.Evictor = (*PostgresCollection)(nil)
+
+// Evict deletes the records past the handle's MaxRecords in one statement,
+// ordering by the EvictBy timestamp with invalid or missing timestamps
+// first. It counts the table on every call, so keep bounded collections to
+// cache-like sizes or evict from a maintenance job instead.
+func (c *PostgresCollection) Evict(ctx context.Context) (int64, error) {
+	if c.maxRecords == 0 {
+		return 0, nil
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	started := time.Now()
+	query := c.buildEvictQuery()
+	args := []any{c.maxRecords}
+
+	var evicted int64
+	stats := queryStats{op: "Evict", collection: c.name, query: query, args: args, started: started, executed: time.Now()}
+	err := c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, args...)
+		evicted = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = evicted, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return 0, err
+	}
+	return evicted, nil
+}
+
+func (c *PostgresCollection) buildEvictQuery() string {
+	order := quoteIdent(idColumn) + " ASC"
+	if c.evictBy != "" {
+		timestamp := vectordata.MetadataPathTimestampSQL(quoteIdent(metadataColumn), []string{c.evictBy}, c.store.timestampFunc())
+		order = timestamp + " ASC NULLS FIRST, " + order
+	}
+	return fmt.Sprintf(`DELETE FROM %s WHERE %s IN (SELECT %s FROM %s ORDER BY %s LIMIT GREATEST((SELECT COUNT(*) FROM %s) - $1, 0))`,
+		c.tableName(), quoteIdent(idColumn), quoteIdent(idColumn), c.tableName(), order, c.tableName())
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_EvictQueryDeletesOldestPastTheBound(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	collection.maxRecords, collection.evictBy = 100, "last_used_at"
+
+	// Act
+	query := collection.buildEvictQuery()
+
+	// Assert
+	expected := `DELETE FROM "public"."docs" WHERE "id" IN (SELECT "id" FROM "public"."docs" ORDER BY ` +
+		collection.store.timestampFunc() + `(jsonb_extract_path_text("metadata", 'last_used_at')) ASC NULLS FIRST, "id" ASC ` +
+		`LIMIT GREATEST((SELECT COUNT(*) FROM "public"."docs") - $1, 0))`
+	if query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, query)
+	}
+}
+
+func TestPostgresVectorStore_EvictionSpecValidation(t *testing.T) {
+	// Arrange
+	store := newUnitTestCollection(vectordata.DistanceCosine).store
+	unbounded := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, _, negativeErr := store.normalizeCollectionSpec(vectordata.CollectionSpec{Name: "docs", Dimension: 2, MaxRecords: -1})
+	_, _, unboundedErr := store.normalizeCollectionSpec(vectordata.CollectionSpec{Name: "docs", Dimension: 2, EvictBy: "created_at"})
+	spec, _, err := store.normalizeCollectionSpec(vectordata.CollectionSpec{Name: "docs", Dimension: 2, MaxRecords: 10, EvictBy: " created_at "})
+	evicted, evictErr := unbounded.Evict(context.Background())
+
+	// Assert
+	if !errors.Is(negativeErr, vectordata.ErrSchemaMismatch) || !errors.Is(unboundedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v and %v", negativeErr, unboundedErr)
+	}
+	if err != nil || spec.EvictBy != "created_at" {
+		t.Fatalf("expected a trimmed EvictBy, got %q, %v", spec.EvictBy, err)
+	}
+	if evicted != 0 || evictErr != nil {
+		t.Fatalf("expected an unbounded handle to evict nothing, got %d, %v", evicted, evictErr)
+	}
+}
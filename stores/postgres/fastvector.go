@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const defaultFastVectorOversampling = 4
+
+// fastVectorColumn keeps the vectors of a collection with a FastDimension
+// truncated and renormalized. Vector indexes are built over it instead of
+// the full vector column.
+func fastVectorColumn(dimension int) extraVectorColumn {
+	return extraVectorColumn{
+		name:    vectorFastColumn,
+		sqlType: fmt.Sprintf("vector(%d)", dimension),
+		udtName: "vector",
+		source:  "fast",
+		decode:  "r.fast::vector",
+	}
+}
+
+// rescoresCandidates reports whether searches rank candidates by an
+// approximation of the vector, through a halfvec or reduced vector index,
+// and then rescore them with the exact distance.
+func (c *PostgresCollection) rescoresCandidates() bool {
+	return c.fastDimension > 0 || c.usesHalfvecIndex()
+}
+
+// candidateLimit is the number of candidates fetched for exact rescoring.
+func (c *PostgresCollection) candidateLimit(topK int) int {
+	if c.fastDimension > 0 {
+		return topK * c.store.opts.FastVectorOversampling
+	}
+	return c.halfvecCandidateLimit(topK)
+}
+
+// fastQueryLiteral truncates the query vector like the stored reduced
+// vectors.
+func (c *PostgresCollection) fastQueryLiteral(vector []float32) (string, error) {
+	fast, err := vectordata.TruncateVector(vector, c.fastDimension)
+	if err != nil {
+		return "", err
+	}
+	return vectorLiteral(fast), nil
+}
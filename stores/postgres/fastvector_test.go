@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newFastUnitTestCollection(dimension, fastDimension int) *PostgresCollection {
+	collection := newHalfvecUnitTestCollection(dimension)
+	collection.fastDimension = fastDimension
+	return collection
+}
+
+func TestPostgresCollection_FastVectorSearchPlanRescores(t *testing.T) {
+	// Arrange
+	collection := newFastUnitTestCollection(3072, 2)
+	vector := make([]float32, 3072)
+	vector[0], vector[1] = 3, 4
+
+	// Act
+	plan, err := collection.buildSearchPlan(vector, 5, vectordata.SearchOptions{})
+	key, keyErr := collection.vectorIndexKey(vectordata.DistanceCosine)
+
+	// Assert
+	if err != nil || keyErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", err, keyErr)
+	}
+	expected := `SELECT * FROM (SELECT "id", "metadata", "content", "vector" <=> $1::vector AS distance FROM "public"."docs"` +
+		` ORDER BY "vector_fast" <=> $4::vector LIMIT $3) AS candidates ORDER BY distance ASC, "id" ASC LIMIT $2`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if len(plan.args) != 4 || plan.args[2] != 5*defaultFastVectorOversampling || plan.args[3] != "[0.6,0.8]" {
+		t.Fatalf("unexpected arguments %#v", plan.args[1:])
+	}
+	if key != `"vector_fast" vector_cosine_ops` {
+		t.Fatalf("expected the index on the reduced vector, got %q", key)
+	}
+}
+
+func TestPostgresCollection_FastVectorWriteBatch(t *testing.T) {
+	// Arrange
+	collection := newFastUnitTestCollection(3, 2)
+
+	// Act
+	query, args, err := collection.buildWriteBatch([]vectordata.Record{{ID: "a", Vector: []float32{3, 4, 12}}}, writeModeUpsert)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildWriteBatch: %v", err)
+	}
+	if !strings.Contains(query, `r.fast::vector`) || !strings.Contains(query, `"vector_fast" = EXCLUDED."vector_fast"`) {
+		t.Fatalf("expected the reduced vector column to be written, got %s", query)
+	}
+	if got := args[len(args)-1].([]string); len(got) != 1 || got[0] != "[0.6,0.8]" {
+		t.Fatalf("expected a truncated and renormalized vector, got %v", got)
+	}
+}
+
+func TestNormalizeCollectionSpecRejectsInvalidFastDimension(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+
+	for _, fastDimension := range []int{-1, 8, 16} {
+		// Act
+		_, _, err := store.normalizeCollectionSpec(vectordata.CollectionSpec{Name: "docs", Dimension: 8, FastDimension: fastDimension})
+
+		// Assert
+		if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+			t.Fatalf("FastDimension %d: expected ErrSchemaMismatch, got %v", fastDimension, err)
+		}
+	}
+}
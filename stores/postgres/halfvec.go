@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	// maxVectorIndexDimensions is pgvector's HNSW/IVFFlat limit for vector.
+	maxVectorIndexDimensions = 2000
+	// maxHalfvecIndexDimensions is pgvector's HNSW/IVFFlat limit for halfvec.
+	maxHalfvecIndexDimensions = 4000
+
+	defaultHalfvecOversampling = 4
+)
+
+// usesHalfvecIndex reports whether vector indexes are built over a halfvec
+// cast because the dimension is above the limit for indexing vector directly.
+// Collections with a FastDimension index the reduced vector instead.
+func (c *PostgresCollection) usesHalfvecIndex() bool {
+	return c.fastDimension == 0 && c.dimension > maxVectorIndexDimensions
+}
+
+// vectorIndexKey is the index key and operator class. Above
+// maxVectorIndexDimensions it indexes the (vector::halfvec(n)) expression,
+// and with a FastDimension the reduced vector column, which search matches
+// via indexedDistanceExpr.
+func (c *PostgresCollection) vectorIndexKey(metric vectordata.DistanceMetric) (string, error) {
+	opClass, err := metricOpClass(metric)
+	if err != nil {
+		return "", err
+	}
+	if c.fastDimension > 0 {
+		return quoteIdent(vectorFastColumn) + " " + opClass, nil
+	}
+	if !c.usesHalfvecIndex() {
+		return quoteIdent(vectorColumn) + " " + opClass, nil
+	}
+	if c.dimension > maxHalfvecIndexDimensions {
+		return "", fmt.Errorf("%w: vector indexes support at most %d dimensions, got %d", vectordata.ErrSchemaMismatch, maxHalfvecIndexDimensions, c.dimension)
+	}
+	return fmt.Sprintf("(%s) %s", c.halfvecColumnExpr(), strings.Replace(opClass, "vector_", "halfvec_", 1)), nil
+}
+
+// indexedDistanceExpr orders by the same expression the vector index is built
+// on, so the planner can use the index. fastArg is the parameter holding the
+// reduced query vector of collections with a FastDimension.
+func (c *PostgresCollection) indexedDistanceExpr(operator string, fastArg int) string {
+	if c.fastDimension > 0 {
+		return fmt.Sprintf(`%s %s $%d::vector`, quoteIdent(vectorFastColumn), operator, fastArg)
+	}
+	if !c.usesHalfvecIndex() {
+		return fmt.Sprintf(`%s %s $1::vector`, quoteIdent(vectorColumn), operator)
+	}
+	return fmt.Sprintf(`(%s) %s $1::halfvec(%d)`, c.halfvecColumnExpr(), operator, c.dimension)
+}
+
+func (c *PostgresCollection) halfvecColumnExpr() string {
+	return fmt.Sprintf("%s::halfvec(%d)", quoteIdent(vectorColumn), c.dimension)
+}
+
+// halfvecCandidateLimit is the number of half-precision candidates fetched
+// for exact rescoring.
+func (c *PostgresCollection) halfvecCandidateLimit(topK int) int {
+	return topK * c.store.opts.HalfvecOversampling
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newHalfvecUnitTestCollection(dimension int) *PostgresCollection {
+	store := &PostgresVectorStore{opts: DefaultStoreOptions(), statements: newStatementCache()}
+	return store.Collection("docs", dimension, vectordata.DistanceCosine).(*PostgresCollection)
+}
+
+func TestPostgresCollection_VectorIndexKey(t *testing.T) {
+	// Arrange
+	small := newUnitTestCollection(vectordata.DistanceCosine)
+	large := newHalfvecUnitTestCollection(3072)
+	tooLarge := newHalfvecUnitTestCollection(4096)
+
+	// Act
+	smallKey, smallErr := small.vectorIndexKey(vectordata.DistanceCosine)
+	largeKey, largeErr := large.vectorIndexKey(vectordata.DistanceL2)
+	_, tooLargeErr := tooLarge.vectorIndexKey(vectordata.DistanceCosine)
+
+	// Assert
+	if smallErr != nil || largeErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", smallErr, largeErr)
+	}
+	if smallKey != `"vector" vector_cosine_ops` {
+		t.Fatalf("unexpected index key %q", smallKey)
+	}
+	if largeKey != `("vector"::halfvec(3072)) halfvec_l2_ops` {
+		t.Fatalf("unexpected halfvec index key %q", largeKey)
+	}
+	if !errors.Is(tooLargeErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch above the halfvec limit, got %v", tooLargeErr)
+	}
+}
+
+func TestPostgresCollection_HalfvecSearchPlanRescores(t *testing.T) {
+	// Arrange
+	collection := newHalfvecUnitTestCollection(3072)
+	vector := make([]float32, 3072)
+	vector[0] = 1
+	threshold := 0.5
+
+	// Act
+	plan, err := collection.buildSearchPlan(vector, 5, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "a"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSearchPlan: %v", err)
+	}
+	expected := `SELECT * FROM (SELECT "id", "metadata", "content", "vector" <=> $1::vector AS distance FROM "public"."docs" WHERE ` +
+		`(("metadata" #> ARRAY['kind']) = $2::jsonb) ORDER BY ("vector"::halfvec(3072)) <=> $1::halfvec(3072) LIMIT $5) AS candidates` +
+		` WHERE distance <= $3 ORDER BY distance ASC, "id" ASC LIMIT $4`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if len(plan.args) != 5 || plan.args[3] != 5 || plan.args[4] != 5*defaultHalfvecOversampling {
+		t.Fatalf("unexpected limit arguments %#v", plan.args[1:])
+	}
+}
+
+func TestPostgresCollection_SearchPlanWithoutHalfvec(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 5, vectordata.SearchOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSearchPlan: %v", err)
+	}
+	if strings.Contains(plan.query, "halfvec") || len(plan.args) != 2 {
+		t.Fatalf("expected a plain vector search, got %q %#v", plan.query, plan.args)
+	}
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// HealthCheck names a check run by Ping or Ready.
+type HealthCheck string
+
+const (
+	HealthCheckConnectivity HealthCheck = "connectivity"
+	HealthCheckExtension    HealthCheck = "extension"
+	HealthCheckSchema       HealthCheck = "schema"
+)
+
+// HealthError is returned by Ping and Ready. It wraps
+// vectordata.ErrUnavailable for connectivity failures and
+// vectordata.ErrNotReady for missing prerequisites, as well as the
+// underlying error if any.
+type HealthError struct {
+	Check  HealthCheck
+	Reason string
+	Err    error
+}
+
+func (e *HealthError) Error() string {
+	msg := fmt.Sprintf("postgres health check %s failed", e.Check)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *HealthError) Unwrap() []error {
+	sentinel := vectordata.ErrNotReady
+	if e.Check == HealthCheckConnectivity {
+		sentinel = vectordata.ErrUnavailable
+	}
+	if e.Err == nil {
+		return []error{sentinel}
+	}
+	return []error{sentinel, e.Err}
+}
+
+var _ vectordata.HealthChecker = (*PostgresVectorStore)(nil)
+
+// Ping verifies that the primary pool, and ReadPool when set, can reach the
+// server. It is cheap enough for liveness probes.
+func (s *PostgresVectorStore) Ping(ctx context.Context) error {
+	if err := s.pool.Ping(ctx); err != nil {
+		return &HealthError{Check: HealthCheckConnectivity, Reason: "primary pool", Err: err}
+	}
+	if s.opts.ReadPool != nil {
+		if err := s.opts.ReadPool.Ping(ctx); err != nil {
+			return &HealthError{Check: HealthCheckConnectivity, Reason: "read pool", Err: err}
+		}
+	}
+	return nil
+}
+
+// Ready runs Ping, then verifies that pgvector is installed (or, with
+// EnsureExtension, available to install) and that the store's schema is
+// usable: it exists with USAGE privilege, or can be created in the current
+// database. It runs a handful of catalog queries, suitable for readiness
+// probes.
+func (s *PostgresVectorStore) Ready(ctx context.Context) error {
+	if err := s.Ping(ctx); err != nil {
+		return err
+	}
+	if err := s.checkExtension(ctx); err != nil {
+		return err
+	}
+	return s.checkSchemaAccess(ctx)
+}
+
+func (s *PostgresVectorStore) checkExtension(ctx context.Context) error {
+	var installed, available bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector'),
+			EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector')`,
+	).Scan(&installed, &available)
+	if err != nil {
+		return healthQueryError(HealthCheckExtension, err)
+	}
+	switch {
+	case installed:
+		return nil
+	case !s.opts.EnsureExtension:
+		return &HealthError{Check: HealthCheckExtension, Reason: "pgvector is not installed and EnsureExtension is off"}
+	case !available:
+		return &HealthError{Check: HealthCheckExtension, Reason: "pgvector is not available on the server"}
+	default:
+		return nil
+	}
+}
+
+func (s *PostgresVectorStore) checkSchemaAccess(ctx context.Context) error {
+	var exists, usable, creatable bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1),
+			EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1 AND has_schema_privilege(oid, 'USAGE')),
+			has_database_privilege(current_database(), 'CREATE')`,
+		s.opts.Schema,
+	).Scan(&exists, &usable, &creatable)
+	if err != nil {
+		return healthQueryError(HealthCheckSchema, err)
+	}
+	switch {
+	case usable:
+		return nil
+	case exists:
+		return &HealthError{Check: HealthCheckSchema, Reason: fmt.Sprintf("no USAGE privilege on schema %q", s.opts.Schema)}
+	case !creatable:
+		return &HealthError{Check: HealthCheckSchema, Reason: fmt.Sprintf("schema %q does not exist and cannot be created", s.opts.Schema)}
+	default:
+		return nil
+	}
+}
+
+// healthQueryError reports a failed check query as a connectivity failure
+// when the connection itself failed.
+func healthQueryError(check HealthCheck, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || (RetryPolicy{}).withDefaults().retryable(err) {
+		return &HealthError{Check: HealthCheckConnectivity, Reason: string(check) + " query", Err: err}
+	}
+	return &HealthError{Check: check, Err: err}
+}
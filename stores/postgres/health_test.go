@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestHealthError_WrapsSentinels(t *testing.T) {
+	// Arrange
+	unreachable := &HealthError{Check: HealthCheckConnectivity, Reason: "primary pool", Err: io.ErrUnexpectedEOF}
+	missing := &HealthError{Check: HealthCheckExtension, Reason: "pgvector is not available on the server"}
+
+	// Act
+	var healthErr *HealthError
+	asHealthError := errors.As(error(missing), &healthErr)
+
+	// Assert
+	if !errors.Is(unreachable, vectordata.ErrUnavailable) || !errors.Is(unreachable, io.ErrUnexpectedEOF) || errors.Is(unreachable, vectordata.ErrNotReady) {
+		t.Fatalf("unexpected wrapping for %v", unreachable)
+	}
+	if !errors.Is(missing, vectordata.ErrNotReady) || errors.Is(missing, vectordata.ErrUnavailable) {
+		t.Fatalf("unexpected wrapping for %v", missing)
+	}
+	if !asHealthError || healthErr.Check != HealthCheckExtension {
+		t.Fatalf("expected a HealthError for the extension check, got %#v", healthErr)
+	}
+	if missing.Error() != "postgres health check extension failed: pgvector is not available on the server" {
+		t.Fatalf("unexpected message %q", missing.Error())
+	}
+}
+
+func TestHealthQueryError_ClassifiesConnectionFailures(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected HealthCheck
+	}{
+		"connection failure": {&pgconn.PgError{Code: "08006"}, HealthCheckConnectivity},
+		"deadline":           {context.DeadlineExceeded, HealthCheckConnectivity},
+		"permission denied":  {&pgconn.PgError{Code: "42501"}, HealthCheckSchema},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := healthQueryError(HealthCheckSchema, tc.err)
+
+			// Assert
+			var healthErr *HealthError
+			if !errors.As(err, &healthErr) || healthErr.Check != tc.expected {
+				t.Fatalf("expected check %s, got %v", tc.expected, err)
+			}
+		})
+	}
+}
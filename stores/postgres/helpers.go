@@ -14,6 +14,17 @@ const (
 	vectorColumn   = "vector"
 	metadataColumn = "metadata"
 	contentColumn  = "content"
+	// vector64Column and vectorInt8Column hold the native vectors of
+	// ElementFloat64 and ElementInt8 collections.
+	vector64Column   = "vector64"
+	vectorInt8Column = "vector_int8"
+	// vectorFastColumn holds the reduced vectors of collections with a
+	// FastDimension.
+	vectorFastColumn = "vector_fast"
+
+	// textSearchColumn is the optional generated tsvector over content.
+	textSearchColumn        = "content_tsv"
+	defaultTextSearchConfig = "english"
 )
 
 func quoteIdent(ident string) string {
@@ -81,6 +92,22 @@ func vectorLiteral(v []float32) string {
 	return b.String()
 }
 
+// float64ArrayLiteral renders v as a float8[] literal that round-trips
+// exactly.
+func float64ArrayLiteral(v []float64) string {
+	var b strings.Builder
+	b.Grow(len(v) * 12)
+	b.WriteByte('{')
+	for i, n := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(n, 'g', -1, 64))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
 func parseVectorText(raw string) ([]float32, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
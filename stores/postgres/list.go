@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// GetMany returns the records with the given IDs in the order of ids,
+// skipping IDs that do not exist.
+func (c *PostgresCollection) GetMany(ctx context.Context, ids []string) ([]vectordata.Record, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	started := time.Now()
+	projection := vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}
+	query := c.statement(statementKey{kind: statementGetMany, projection: projection}, func() string {
+		return fmt.Sprintf(`SELECT %s FROM %s WHERE %s = ANY($1) ORDER BY array_position($1, %s)`,
+			strings.Join(c.projectedColumns(projection), ", "),
+			c.tableName(),
+			quoteIdent(idColumn),
+			quoteIdent(idColumn),
+		)
+	})
+	plan := searchPlan{query: query, args: []any{ids}, projection: projection, rank: rankNone}
+	results, err := c.executeSearchPlan(ctx, queryStats{op: "GetMany", started: started}, plan)
+	if err != nil {
+		return nil, err
+	}
+	return resultRecords(results), nil
+}
+
+// List returns up to opts.Limit records matching opts.Filter with IDs after
+// opts.After, in ascending ID order.
+func (c *PostgresCollection) List(ctx context.Context, opts vectordata.ListOptions) ([]vectordata.Record, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	if opts.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be > 0")
+	}
+	started := time.Now()
+	projection := resolveProjection(opts.Projection)
+	whereSQL, args, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), 1)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, opts.After, opts.Limit)
+
+	query := c.statement(statementKey{kind: statementList, projection: projection, filter: whereSQL}, func() string {
+		// Every record has a non-empty ID, so the first page lists after "".
+		where := fmt.Sprintf("%s > $%d", quoteIdent(idColumn), next)
+		if whereSQL != "" {
+			where = whereSQL + " AND " + where
+		}
+		return fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d`,
+			strings.Join(c.projectedColumns(projection), ", "),
+			c.tableName(),
+			where,
+			quoteIdent(idColumn),
+			next+1,
+		)
+	})
+	plan := searchPlan{query: query, args: args, projection: projection, rank: rankNone}
+	results, err := c.executeSearchPlan(ctx, queryStats{op: "List", filter: opts.Filter, started: started}, plan)
+	if err != nil {
+		return nil, err
+	}
+	return resultRecords(results), nil
+}
+
+// DeleteByFilter deletes every record matching filter and returns how many
+// were deleted.
+func (c *PostgresCollection) DeleteByFilter(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	if filter == nil {
+		return 0, fmt.Errorf("%w: DeleteByFilter requires a filter", vectordata.ErrInvalidFilter)
+	}
+	started := time.Now()
+	whereSQL, args, _, err := vectordata.CompileFilterSQL(filter, c.filterConfig(), 1)
+	if err != nil {
+		return 0, err
+	}
+	query := c.statement(statementKey{kind: statementDeleteByFilter, filter: whereSQL}, func() string {
+		return fmt.Sprintf(`DELETE FROM %s WHERE %s`, c.tableName(), whereSQL)
+	})
+
+	var deleted int64
+	stats := queryStats{op: "DeleteByFilter", collection: c.name, query: query, args: args, filter: filter, started: started, executed: time.Now()}
+	err = c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, args...)
+		deleted = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = deleted, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func resultRecords(results []vectordata.SearchResult) []vectordata.Record {
+	records := make([]vectordata.Record, len(results))
+	for i, result := range results {
+		records[i] = result.Record
+	}
+	return records
+}
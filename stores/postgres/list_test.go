@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_DeleteByFilterRequiresFilter(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, err := collection.DeleteByFilter(context.Background(), nil)
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestPostgresCollection_ListRequiresLimit(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, err := collection.List(context.Background(), vectordata.ListOptions{})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error for a zero limit")
+	}
+}
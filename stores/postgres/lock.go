@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// advisoryLockNamespace prefixes lock keys so they do not collide with
+// advisory locks taken by the application for unrelated purposes.
+const advisoryLockNamespace = "go-vectorstore"
+
+// advisoryLockKey maps a lock scope to the bigint key of pg_advisory_xact_lock.
+// Keys are derived in Go so every instance computes the same value regardless
+// of server version.
+func advisoryLockKey(parts ...string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(advisoryLockNamespace))
+	for _, part := range parts {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(part))
+	}
+	return int64(h.Sum64())
+}
+
+// withSchemaLock runs fn in a transaction that holds a transaction-scoped
+// advisory lock for key. Concurrent callers with the same key run one after
+// another, and the lock is released on commit or rollback. Transient failures
+// retry the whole transaction under StoreOptions.Retry. A store bound to a
+// transaction runs fn in a savepoint of it, and the lock is held until that
+// transaction ends.
+func (s *PostgresVectorStore) withSchemaLock(ctx context.Context, key int64, fn func(pgx.Tx) error) error {
+	if s.tx != nil {
+		return classifyError(pgx.BeginFunc(ctx, s.tx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, key); err != nil {
+				return fmt.Errorf("acquire schema lock: %w", err)
+			}
+			return fn(tx)
+		}))
+	}
+	return s.withRetry(ctx, func() error {
+		return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, key); err != nil {
+				return fmt.Errorf("acquire schema lock: %w", err)
+			}
+			return fn(tx)
+		})
+	})
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import "testing"
+
+func TestAdvisoryLockKey_StableAndScoped(t *testing.T) {
+	// Act
+	first := advisoryLockKey("public", "docs")
+	second := advisoryLockKey("public", "docs")
+	otherCollection := advisoryLockKey("public", "docs2")
+	otherSplit := advisoryLockKey("publicdocs")
+	schemaOnly := advisoryLockKey("public")
+
+	// Assert
+	if first != second {
+		t.Fatalf("expected stable key, got %d and %d", first, second)
+	}
+	for name, key := range map[string]int64{"other collection": otherCollection, "other split": otherSplit, "schema only": schemaOnly} {
+		if key == first {
+			t.Fatalf("expected %s key to differ from %d", name, first)
+		}
+	}
+}
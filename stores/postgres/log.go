@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sqlLiteralPattern matches single-quoted SQL literals, which DDL uses for
+// values such as LIST partition bounds.
+var sqlLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// sanitizeSQL prepares a statement for logging: whitespace is collapsed and
+// literals are masked. Statement arguments are never logged.
+func sanitizeSQL(query string) string {
+	return sqlLiteralPattern.ReplaceAllString(strings.Join(strings.Fields(query), " "), "'?'")
+}
+
+// finishQuery logs a collection operation at debug level and reports it when
+// it is slow.
+func (s *PostgresVectorStore) finishQuery(ctx context.Context, stats queryStats) {
+	finished := time.Now()
+	s.reportSlowQuery(ctx, stats, finished)
+
+	logger := s.opts.Logger
+	if logger == nil || !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", stats.op),
+		slog.String("collection", stats.collection),
+		slog.String("sql", sanitizeSQL(stats.query)),
+		slog.Int("args", len(stats.args)),
+		slog.Duration("duration", finished.Sub(stats.started)),
+	}
+	if stats.err != nil {
+		attrs = append(attrs, slog.Any("error", stats.err))
+	} else {
+		attrs = append(attrs, slog.Int64("rows", stats.rows))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "vectorstore query", attrs...)
+}
+
+// execSchema runs a schema change and logs it at debug level. Plans record
+// the statement without logging it.
+func (s *PostgresVectorStore) execSchema(ctx context.Context, db schemaExecutor, query string) error {
+	if _, planned := db.(*planExecutor); planned {
+		_, err := db.Exec(ctx, query)
+		return err
+	}
+	start := time.Now()
+	_, err := db.Exec(ctx, query)
+
+	logger := s.opts.Logger
+	if logger != nil && logger.Enabled(ctx, slog.LevelDebug) {
+		attrs := []slog.Attr{
+			slog.String("schema", s.opts.Schema),
+			slog.String("sql", sanitizeSQL(query)),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "vectorstore schema change", attrs...)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type recordingSchemaExecutor struct {
+	schemaExecutor
+	queries []string
+	err     error
+}
+
+func (r *recordingSchemaExecutor) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	r.queries = append(r.queries, sql)
+	return pgconn.CommandTag{}, r.err
+}
+
+func TestSanitizeSQL_MasksLiteralsAndCollapsesWhitespace(t *testing.T) {
+	// Arrange
+	query := "CREATE TABLE IF NOT EXISTS \"docs_p_acme\"\n\t\tPARTITION OF \"docs\" FOR VALUES IN ('acme', 'o''brien')"
+
+	// Act
+	sanitized := sanitizeSQL(query)
+
+	// Assert
+	expected := `CREATE TABLE IF NOT EXISTS "docs_p_acme" PARTITION OF "docs" FOR VALUES IN ('?', '?')`
+	if sanitized != expected {
+		t.Fatalf("expected %q, got %q", expected, sanitized)
+	}
+}
+
+func TestPostgresVectorStore_ExecSchemaLogsAtDebug(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := &PostgresVectorStore{opts: StoreOptions{Schema: "public", Logger: logger}}
+	failure := errors.New("boom")
+	db := &recordingSchemaExecutor{err: failure}
+
+	// Act
+	err := store.execSchema(context.Background(), db, `CREATE INDEX IF NOT EXISTS "idx" ON "public"."docs" (id)`)
+
+	// Assert
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the exec error, got %v", err)
+	}
+	if len(db.queries) != 1 {
+		t.Fatalf("expected one statement, got %d", len(db.queries))
+	}
+	out := buf.String()
+	for _, want := range []string{"level=DEBUG", `msg="vectorstore schema change"`, "schema=public", `CREATE INDEX IF NOT EXISTS \"idx\"`, "error=boom"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected log to contain %s, got %s", want, out)
+		}
+	}
+}
+
+func TestPostgresVectorStore_FinishQueryLogsAtDebug(t *testing.T) {
+	// Arrange
+	var debug, info bytes.Buffer
+	debugStore := &PostgresVectorStore{opts: StoreOptions{Logger: slog.New(slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}))}}
+	infoStore := &PostgresVectorStore{opts: StoreOptions{Logger: slog.New(slog.NewTextHandler(&info, nil))}}
+	silent := &PostgresVectorStore{}
+	query := "SELECT COUNT(*) FROM \"public\".\"docs\"\n\tWHERE (\"metadata\" ->> 'category') = $1"
+
+	stats := queryStats{op: "Count", collection: "docs", query: query, args: []any{"news"}, started: time.Now(), executed: time.Now(), rows: 4}
+
+	// Act
+	debugStore.finishQuery(context.Background(), stats)
+	infoStore.finishQuery(context.Background(), stats)
+	silent.finishQuery(context.Background(), stats)
+
+	// Assert
+	out := debug.String()
+	for _, want := range []string{`msg="vectorstore query"`, "operation=Count", "collection=docs", `WHERE (\"metadata\" ->> '?') = $1`, "args=1", "rows=4", "duration="} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected log to contain %s, got %s", want, out)
+		}
+	}
+	if info.Len() != 0 {
+		t.Fatalf("expected nothing logged above debug level, got %s", info.String())
+	}
+}
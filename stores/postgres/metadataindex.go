@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+// timestampFuncName is the text -> timestamptz function created in the store
+// schema for time comparisons on metadata.
+const timestampFuncName = "vectorstore_timestamptz"
+
+func (s *PostgresVectorStore) timestampFunc() string {
+	return qualifiedTable(s.opts.Schema, timestampFuncName)
+}
+
+// ensureTimestampFunc creates the timestamp function unless it exists. The
+// text -> timestamptz cast is only STABLE, because it depends on the session
+// time zone; the function accepts only timestamps with an explicit offset,
+// which is what makes declaring it IMMUTABLE, and indexing it, safe.
+func (s *PostgresVectorStore) ensureTimestampFunc(ctx context.Context, db schemaExecutor) error {
+	var exists bool
+	err := db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2
+		)
+	`, s.opts.Schema, timestampFuncName).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check timestamp function: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		`CREATE FUNCTION %s(value text) RETURNS timestamptz LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$ SELECT CASE WHEN value ~ %s THEN value::timestamptz END $$`,
+		s.timestampFunc(),
+		quoteLiteral(vectordata.TimestampTextPattern),
+	)
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("create timestamp function: %w", err)
+	}
+	return nil
+}
+
+// ensureMetadataKeyIndexes creates btree expression indexes over the same
+// expressions the filter compiler emits for typed comparisons, so the planner
+// can match them.
+func (c *PostgresCollection) ensureMetadataKeyIndexes(ctx context.Context, keys []vectordata.MetadataKeyIndex) error {
+	statements, needsTimestampFunc, err := c.metadataKeyIndexStatements(keys)
+	if err != nil {
+		return err
+	}
+
+	// A plan leaves the function out: PlanCollection always creates it.
+	if needsTimestampFunc && c.plan == nil {
+		err := c.store.withSchemaLock(ctx, advisoryLockKey(c.store.opts.Schema), func(tx pgx.Tx) error {
+			return c.store.ensureTimestampFunc(ctx, tx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, query := range statements {
+		if err := c.store.execSchema(ctx, c.schemaDB(), query); err != nil {
+			return fmt.Errorf("ensure metadata key index: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *PostgresCollection) metadataKeyIndexStatements(keys []vectordata.MetadataKeyIndex) ([]string, bool, error) {
+	statements := make([]string, 0, len(keys))
+	needsTimestampFunc := false
+	metadataExpr := quoteIdent(metadataColumn)
+
+	for _, key := range keys {
+		field, err := vectordata.NormalizeFieldRef(vectordata.Metadata(key.Path...))
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: metadata key index path: %v", vectordata.ErrSchemaMismatch, err)
+		}
+
+		keyType := key.Type
+		if keyType == "" {
+			keyType = vectordata.MetadataKeyText
+		}
+		var expr string
+		switch keyType {
+		case vectordata.MetadataKeyText:
+			expr = vectordata.MetadataPathTextSQL(metadataExpr, field.Path)
+		case vectordata.MetadataKeyNumeric:
+			expr = vectordata.MetadataPathNumericSQL(metadataExpr, field.Path)
+		case vectordata.MetadataKeyTimestamp:
+			expr = vectordata.MetadataPathTimestampSQL(metadataExpr, field.Path, c.store.timestampFunc())
+			needsTimestampFunc = true
+		default:
+			return nil, false, fmt.Errorf("%w: unsupported metadata key type %q", vectordata.ErrSchemaMismatch, key.Type)
+		}
+
+		indexName := key.Name
+		if indexName == "" {
+			indexName = fmt.Sprintf("idx_%s_metadata_%s_%s", c.name, strings.Join(field.Path, "_"), keyType)
+		}
+		statements = append(statements, fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON %s USING btree ((%s))",
+			quoteIdent(indexName),
+			c.tableName(),
+			expr,
+		))
+	}
+	return statements, needsTimestampFunc, nil
+}
@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_MetadataKeyIndexStatements(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	statements, needsTimestampFunc, err := collection.metadataKeyIndexStatements([]vectordata.MetadataKeyIndex{
+		{Path: []string{"author"}},
+		{Path: []string{"rank"}, Type: vectordata.MetadataKeyNumeric},
+		{Name: "docs_created", Path: []string{"audit", "created"}, Type: vectordata.MetadataKeyTimestamp},
+	})
+	_, _, invalidErr := collection.metadataKeyIndexStatements([]vectordata.MetadataKeyIndex{{Path: []string{"rank"}, Type: "money"}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("metadataKeyIndexStatements: %v", err)
+	}
+	expected := []string{
+		`CREATE INDEX IF NOT EXISTS "idx_docs_metadata_author_text" ON "public"."docs" USING btree ((jsonb_extract_path_text("metadata", 'author')))`,
+		`CREATE INDEX IF NOT EXISTS "idx_docs_metadata_rank_numeric" ON "public"."docs" USING btree (((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN (jsonb_extract_path_text("metadata", 'rank'))::double precision END)))`,
+		`CREATE INDEX IF NOT EXISTS "docs_created" ON "public"."docs" USING btree (("public"."vectorstore_timestamptz"(jsonb_extract_path_text("metadata", 'audit', 'created'))))`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("unexpected statements\nwant: %#v\n got: %#v", expected, statements)
+	}
+	if !needsTimestampFunc {
+		t.Fatal("expected the timestamp function to be required")
+	}
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for an unknown key type, got %v", invalidErr)
+	}
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.NeighborFinder = (*PostgresCollection)(nil)
+
+// NeighborsFor finds the topK nearest neighbors of every record in ids with
+// one LATERAL join, which runs the index scan once per record on the server.
+// Searches with Negatives or OrderBy, and handles that rescore candidates
+// from a halfvec or reduced-vector index, fall back to one search per record.
+func (c *PostgresCollection) NeighborsFor(ctx context.Context, ids []string, topK int, opts vectordata.SearchOptions) (map[string][]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 || len(opts.Negatives) > 0 || opts.Cursor != "" || len(opts.OrderBy) > 0 || c.rescoresCandidates() {
+		return vectordata.SearchNeighbors(ctx, c, ids, topK, opts)
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	plan, err := c.buildNeighborsPlan(ids, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	results, groups, err := c.executeGroupedSearchPlan(ctx, queryStats{op: "NeighborsFor", filter: opts.Filter, started: started}, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]vectordata.SearchResult)
+	for i, result := range results {
+		out[groups[i]] = append(out[groups[i]], result)
+	}
+	for id, neighbors := range out {
+		out[id] = vectordata.NormalizeScores(defaultMetric(c.metric), neighbors, opts.ScoreNormalization)
+	}
+	return out, nil
+}
+
+// buildNeighborsPlan joins the records in ids to a LATERAL search from each
+// of their vectors. Unqualified columns in the subquery, including those of
+// the compiled filter, refer to the neighbor rather than the query record.
+// Records without neighbors produce no rows and so are left out.
+func (c *PostgresCollection) buildNeighborsPlan(ids []string, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+	if err := vectordata.ValidateBoosts(opts); err != nil {
+		return searchPlan{}, err
+	}
+	operator, err := metricOperator(defaultMetric(c.metric))
+	if err != nil {
+		return searchPlan{}, err
+	}
+	distanceExpr := fmt.Sprintf(`%s %s q.%s`, quoteIdent(vectorColumn), operator, quoteIdent(vectorColumn))
+	projection := resolveProjection(opts.Projection)
+	settings, err := buildSessionSettings(opts.SessionSettings)
+	if err != nil {
+		return searchPlan{}, err
+	}
+
+	args := []any{ids}
+	nextArg := 2
+	whereParts := []string{fmt.Sprintf("%s <> q.%s", quoteIdent(idColumn), quoteIdent(idColumn))}
+	whereSQL := ""
+	if opts.Filter != nil {
+		compiled, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), nextArg)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		whereSQL = compiled
+		whereParts = append(whereParts, compiled)
+		args = append(args, filterArgs...)
+		nextArg = next
+	}
+	if opts.Threshold != nil {
+		whereParts = append(whereParts, fmt.Sprintf("(%s <= $%d)", distanceExpr, nextArg))
+		args = append(args, *opts.Threshold)
+		nextArg++
+	}
+	limitArg := nextArg
+	args = append(args, topK)
+	nextArg++
+
+	scoreExpr, orderBy, rank := "", "distance ASC", rankDistance
+	if opts.Boosted() {
+		var scoreArgs []any
+		scoreExpr, scoreArgs, _, err = c.boostedScoreExpr(distanceExpr, opts, nextArg)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		args = append(args, scoreArgs...)
+		orderBy, rank = "score DESC", rankDistanceAndScore
+	}
+
+	key := statementKey{
+		kind:       statementNeighbors,
+		projection: projection,
+		filter:     whereSQL,
+		threshold:  opts.Threshold != nil,
+		score:      scoreExpr,
+	}
+	query := c.statement(key, func() string {
+		selectCols := append(c.projectedColumns(projection), distanceExpr+" AS distance")
+		if scoreExpr != "" {
+			selectCols = append(selectCols, scoreExpr+" AS score")
+		}
+		return fmt.Sprintf(
+			`SELECT n.*, q.%s FROM %s AS q CROSS JOIN LATERAL (SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d) AS n WHERE q.%s = ANY($1) ORDER BY q.%s, n.%s`,
+			quoteIdent(idColumn),
+			c.tableName(),
+			strings.Join(selectCols, ", "),
+			c.tableName(),
+			strings.Join(whereParts, " AND "),
+			orderBy,
+			limitArg,
+			quoteIdent(idColumn),
+			quoteIdent(idColumn),
+			orderBy,
+		)
+	})
+
+	return searchPlan{
+		query:      query,
+		args:       args,
+		projection: projection,
+		settings:   settings,
+		rank:       rank,
+		group:      true,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_NeighborsPlanJoinsLaterally(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	threshold := 0.4
+	ids := []string{"a", "b"}
+
+	// Act
+	plan, err := collection.buildNeighborsPlan(ids, 3, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("lang"), "en"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildNeighborsPlan: %v", err)
+	}
+	expected := `SELECT n.*, q."id" FROM "public"."docs" AS q CROSS JOIN LATERAL (` +
+		`SELECT "id", "metadata", "content", "vector" <=> q."vector" AS distance FROM "public"."docs" ` +
+		`WHERE "id" <> q."id" AND (("metadata" #> ARRAY['lang']) = $2::jsonb) AND ("vector" <=> q."vector" <= $3) ` +
+		`ORDER BY distance ASC LIMIT $4) AS n WHERE q."id" = ANY($1) ORDER BY q."id", n.distance ASC`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if !reflect.DeepEqual(plan.args, []any{ids, []byte(`"en"`), 0.4, 3}) {
+		t.Fatalf("unexpected args: %#v", plan.args)
+	}
+	if !plan.group || plan.rank != rankDistance {
+		t.Fatalf("expected grouped rows ranked by distance, got %+v", plan)
+	}
+}
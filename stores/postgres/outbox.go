@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultOutboxTable        = "__vector_outbox"
+	defaultOutboxPollInterval = time.Second
+	outboxTriggerName         = "__vector_outbox"
+)
+
+// OutboxOptions enables the transactional outbox: every row inserted,
+// updated or deleted in a collection table is recorded in the outbox table by
+// a trigger, in the same transaction as the change.
+type OutboxOptions struct {
+	// Table is the outbox table in the store's schema (default
+	// __vector_outbox). No collection may have this name.
+	Table string
+}
+
+// OutboxOperation is the kind of change an OutboxEvent records.
+type OutboxOperation string
+
+const (
+	OutboxInsert OutboxOperation = "insert"
+	OutboxUpdate OutboxOperation = "update"
+	OutboxDelete OutboxOperation = "delete"
+)
+
+// OutboxEvent is one recorded change. Insert and update events carry the
+// record's metadata and content as written; vectors are left out to keep the
+// outbox small, so consumers that need them Get the record.
+type OutboxEvent struct {
+	// Seq orders the events of a store; it increases with every event but
+	// may have gaps.
+	Seq        int64
+	Collection string
+	Operation  OutboxOperation
+	RecordID   string
+	Metadata   map[string]any
+	Content    *string
+	CreatedAt  time.Time
+}
+
+// OutboxHandler delivers a batch of events, e.g. to a message broker or a
+// downstream index. Returning an error leaves the batch pending, so it is
+// delivered again: handlers must tolerate duplicates.
+type OutboxHandler func(ctx context.Context, events []OutboxEvent) error
+
+func (s *PostgresVectorStore) outboxTableName() string {
+	return qualifiedTable(s.opts.Schema, s.opts.Outbox.Table)
+}
+
+func (s *PostgresVectorStore) outboxFuncName() string {
+	return qualifiedTable(s.opts.Schema, s.opts.Outbox.Table+"_emit")
+}
+
+// ensureOutbox creates the outbox table and the trigger function that
+// collection triggers call with the collection name.
+func (s *PostgresVectorStore) ensureOutbox(ctx context.Context, db schemaExecutor) error {
+	table := s.outboxTableName()
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			seq bigserial PRIMARY KEY,
+			collection text NOT NULL,
+			operation text NOT NULL,
+			record_id text NOT NULL,
+			payload jsonb,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			delivered_at timestamptz
+		)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (seq) WHERE delivered_at IS NULL`,
+			quoteIdent(s.opts.Outbox.Table+"_pending_idx"), table),
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				INSERT INTO %s (collection, operation, record_id) VALUES (TG_ARGV[0], 'delete', OLD.%s);
+			ELSE
+				INSERT INTO %s (collection, operation, record_id, payload)
+				VALUES (TG_ARGV[0], lower(TG_OP), NEW.%s, jsonb_build_object('metadata', NEW.%s, 'content', NEW.%s));
+			END IF;
+			RETURN NULL;
+		END $$`, s.outboxFuncName(), table, quoteIdent(idColumn), table, quoteIdent(idColumn), quoteIdent(metadataColumn), quoteIdent(contentColumn)),
+	}
+	for _, query := range statements {
+		if err := s.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("ensure outbox: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureOutboxTrigger attaches the outbox trigger to a collection table
+// unless it is already there. On a partitioned table the trigger is cloned
+// to every partition.
+func (s *PostgresVectorStore) ensureOutboxTrigger(ctx context.Context, db schemaExecutor, table string) error {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgrelid = to_regclass($1) AND tgname = $2)`,
+		qualifiedTable(s.opts.Schema, table), outboxTriggerName,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check outbox trigger: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	query := fmt.Sprintf(`CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s(%s)`,
+		quoteIdent(outboxTriggerName), qualifiedTable(s.opts.Schema, table), s.outboxFuncName(), quoteLiteral(table))
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("create outbox trigger on %q: %w", table, err)
+	}
+	return nil
+}
+
+// PollOutbox hands up to limit pending events to handler, oldest first, and
+// marks them delivered when it returns nil. The events stay locked until then
+// with FOR UPDATE SKIP LOCKED, so concurrent pollers deliver disjoint
+// batches. It returns the number of events delivered.
+func (s *PostgresVectorStore) PollOutbox(ctx context.Context, limit int, handler OutboxHandler) (int, error) {
+	if s.opts.Outbox == nil {
+		return 0, errors.New("poll outbox: StoreOptions.Outbox is not set")
+	}
+	if limit <= 0 {
+		return 0, fmt.Errorf("poll outbox: limit must be > 0, got %d", limit)
+	}
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Write)
+	defer cancel()
+
+	var delivered int
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		events, err := s.pendingOutboxEvents(ctx, tx, limit)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		if err := handler(ctx, events); err != nil {
+			return err
+		}
+		seqs := make([]int64, len(events))
+		for i, event := range events {
+			seqs[i] = event.Seq
+		}
+		query := fmt.Sprintf(`UPDATE %s SET delivered_at = now() WHERE seq = ANY($1)`, s.outboxTableName())
+		if _, err := tx.Exec(ctx, query, seqs); err != nil {
+			return fmt.Errorf("mark outbox events delivered: %w", err)
+		}
+		delivered = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delivered, nil
+}
+
+func (s *PostgresVectorStore) pendingOutboxEvents(ctx context.Context, tx pgx.Tx, limit int) ([]OutboxEvent, error) {
+	query := fmt.Sprintf(`SELECT seq, collection, operation, record_id, payload -> 'metadata', payload ->> 'content', created_at
+		FROM %s WHERE delivered_at IS NULL ORDER BY seq LIMIT $1 FOR UPDATE SKIP LOCKED`, s.outboxTableName())
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var events []OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		var operation string
+		var metadata []byte
+		if err := rows.Scan(&event.Seq, &event.Collection, &operation, &event.RecordID, &metadata, &event.Content, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("read outbox: %w", err)
+		}
+		event.Operation = OutboxOperation(operation)
+		if event.Operation != OutboxDelete {
+			event.Metadata, err = parseMetadata(metadata)
+			if err != nil {
+				return nil, fmt.Errorf("decode outbox event %d: %w", event.Seq, err)
+			}
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	return events, nil
+}
+
+// RunOutbox polls the outbox until ctx is done or handler fails, waiting
+// interval (default one second) after polls that find nothing. It returns
+// nil when ctx is canceled.
+func (s *PostgresVectorStore) RunOutbox(ctx context.Context, interval time.Duration, limit int, handler OutboxHandler) error {
+	if interval <= 0 {
+		interval = defaultOutboxPollInterval
+	}
+	for {
+		delivered, err := s.PollOutbox(ctx, limit, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if delivered > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// PurgeOutbox deletes events delivered before cutoff and returns how many
+// were deleted.
+func (s *PostgresVectorStore) PurgeOutbox(ctx context.Context, cutoff time.Time) (int64, error) {
+	if s.opts.Outbox == nil {
+		return 0, errors.New("purge outbox: StoreOptions.Outbox is not set")
+	}
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Write)
+	defer cancel()
+	query := fmt.Sprintf(`DELETE FROM %s WHERE delivered_at < $1`, s.outboxTableName())
+	cmd, err := s.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge outbox: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
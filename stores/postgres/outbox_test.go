@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPlanCollection_OutboxTrigger(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.Outbox = &OutboxOptions{}
+	store := &PostgresVectorStore{opts: opts.withDefaults()}
+
+	// Act
+	statements, err := store.PlanCollection(vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceL2})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlanCollection: %v", err)
+	}
+	all := strings.Join(statements, ";\n")
+	for _, want := range []string{
+		`CREATE TABLE IF NOT EXISTS "public"."__vector_outbox"`,
+		`CREATE OR REPLACE FUNCTION "public"."__vector_outbox_emit"() RETURNS trigger`,
+		`CREATE TRIGGER "__vector_outbox" AFTER INSERT OR UPDATE OR DELETE ON "public"."docs" FOR EACH ROW EXECUTE FUNCTION "public"."__vector_outbox_emit"('docs')`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected %q in plan:\n%s", want, all)
+		}
+	}
+}
+
+func TestPlanCollection_NoOutboxByDefault(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+
+	// Act
+	statements, err := store.PlanCollection(vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlanCollection: %v", err)
+	}
+	if all := strings.Join(statements, ";\n"); strings.Contains(all, "outbox") {
+		t.Fatalf("expected no outbox statements:\n%s", all)
+	}
+}
+
+func TestEnsureCollection_RejectsOutboxTableName(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.Outbox = &OutboxOptions{Table: "events"}
+	store := &PostgresVectorStore{opts: opts.withDefaults()}
+
+	// Act
+	_, err := store.PlanCollection(vectordata.CollectionSpec{Name: "events", Dimension: 3})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestPollOutbox_RequiresOutbox(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+
+	// Act
+	_, err := store.PollOutbox(t.Context(), 10, nil)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error without StoreOptions.Outbox")
+	}
+}
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+// partitionKeyPattern restricts promoted partition columns to plain lower-case
+// identifiers so the metadata key and the column name are the same string.
+var partitionKeyPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// partitioning is a validated vectordata.PartitionSpec.
+type partitioning struct {
+	method  vectordata.PartitionMethod
+	key     string
+	values  []string
+	modulus int
+}
+
+func normalizePartition(spec *vectordata.PartitionSpec) (*partitioning, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	p := &partitioning{
+		method:  spec.Method,
+		key:     strings.TrimSpace(spec.Key),
+		values:  append([]string(nil), spec.Values...),
+		modulus: spec.Modulus,
+	}
+	if p.method == "" {
+		p.method = vectordata.PartitionList
+	}
+	if !partitionKeyPattern.MatchString(p.key) {
+		return nil, fmt.Errorf("%w: invalid partition key %q", vectordata.ErrSchemaMismatch, spec.Key)
+	}
+	switch p.key {
+	case idColumn, vectorColumn, metadataColumn, contentColumn, textSearchColumn:
+		return nil, fmt.Errorf("%w: partition key %q collides with a collection column", vectordata.ErrSchemaMismatch, p.key)
+	}
+
+	switch p.method {
+	case vectordata.PartitionList:
+		if p.modulus != 0 {
+			return nil, fmt.Errorf("%w: modulus applies to hash partitioning only", vectordata.ErrSchemaMismatch)
+		}
+	case vectordata.PartitionHash:
+		if p.modulus <= 0 {
+			return nil, fmt.Errorf("%w: hash partitioning requires modulus > 0", vectordata.ErrSchemaMismatch)
+		}
+		if len(p.values) > 0 {
+			return nil, fmt.Errorf("%w: values apply to list partitioning only", vectordata.ErrSchemaMismatch)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported partition method %q", vectordata.ErrSchemaMismatch, p.method)
+	}
+	return p, nil
+}
+
+// keyDef is the partition key definition as printed by pg_get_partkeydef.
+func (p *partitioning) keyDef() string {
+	return fmt.Sprintf("%s (%s)", strings.ToUpper(string(p.method)), p.key)
+}
+
+// partitionValue extracts the partition key of a record with
+// jsonb_extract_path_text semantics: strings are used as-is and other values
+// use their JSON encoding.
+func (p *partitioning) partitionValue(record vectordata.Record) (string, error) {
+	value, ok := record.Metadata[p.key]
+	if !ok || value == nil {
+		return "", fmt.Errorf("record %q is missing partition key %q", record.ID, p.key)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encode partition key for record %q: %w", record.ID, err)
+	}
+	return string(encoded), nil
+}
+
+// listPartitionName derives a stable partition table name from the value, so
+// concurrent writers agree on it and arbitrary values stay valid identifiers.
+func listPartitionName(table, value string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	return fmt.Sprintf("%s_p_%016x", table, h.Sum64())
+}
+
+func hashPartitionName(table string, remainder int) string {
+	return fmt.Sprintf("%s_p%d", table, remainder)
+}
+
+func quoteLiteral(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+func (s *PostgresVectorStore) createHashPartitions(ctx context.Context, db schemaExecutor, table string, p *partitioning) error {
+	for remainder := 0; remainder < p.modulus; remainder++ {
+		query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES WITH (MODULUS %d, REMAINDER %d)`,
+			qualifiedTable(s.opts.Schema, hashPartitionName(table, remainder)),
+			qualifiedTable(s.opts.Schema, table),
+			p.modulus,
+			remainder,
+		)
+		if err := s.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("create hash partition %d of %q: %w", remainder, table, err)
+		}
+	}
+	return nil
+}
+
+func (s *PostgresVectorStore) createListPartitions(ctx context.Context, db schemaExecutor, table string, values []string) error {
+	for _, value := range values {
+		query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES IN (%s)`,
+			qualifiedTable(s.opts.Schema, listPartitionName(table, value)),
+			qualifiedTable(s.opts.Schema, table),
+			quoteLiteral(value),
+		)
+		if err := s.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("create partition for %q of %q: %w", value, table, err)
+		}
+	}
+	return nil
+}
+
+// rememberPartitions records committed LIST partitions in the store cache.
+func (s *PostgresVectorStore) rememberPartitions(table string, values []string) {
+	for _, value := range values {
+		s.partitions.Store(partitionCacheKey(s.opts.Schema, table, value), struct{}{})
+	}
+}
+
+// forgetPartitions drops the cached LIST partitions of a dropped table.
+func (s *PostgresVectorStore) forgetPartitions(table string) {
+	prefix := partitionCacheKey(s.opts.Schema, table, "")
+	s.partitions.Range(func(key, _ any) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			s.partitions.Delete(key)
+		}
+		return true
+	})
+}
+
+// validatePartitioning checks that an existing table is partitioned exactly as
+// declared. A table cannot be converted in place, so mismatches fail in every
+// ensure mode.
+func (s *PostgresVectorStore) validatePartitioning(ctx context.Context, db schemaExecutor, table string, p *partitioning) error {
+	var keyDef string
+	err := db.QueryRow(ctx, `
+		SELECT pg_get_partkeydef(c.oid)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'p'
+	`, s.opts.Schema, table).Scan(&keyDef)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if p == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: table %q is not partitioned", vectordata.ErrSchemaMismatch, table)
+	}
+	if err != nil {
+		return fmt.Errorf("read partition key: %w", err)
+	}
+
+	if p == nil {
+		return fmt.Errorf("%w: table %q is partitioned by %s; declare CollectionSpec.Partition", vectordata.ErrSchemaMismatch, table, keyDef)
+	}
+	if strings.ReplaceAll(keyDef, `"`, "") != p.keyDef() {
+		return fmt.Errorf("%w: expected partitioning %s, got %s", vectordata.ErrSchemaMismatch, p.keyDef(), keyDef)
+	}
+	return nil
+}
+
+// listPartitions returns the partition tables of a collection.
+func (s *PostgresVectorStore) listPartitions(ctx context.Context, table string) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+		ORDER BY c.relname
+	`, qualifiedTable(s.opts.Schema, table))
+	if err != nil {
+		return nil, fmt.Errorf("list partitions of %q: %w", table, err)
+	}
+	partitions, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("list partitions of %q: %w", table, err)
+	}
+	return partitions, nil
+}
+
+func partitionCacheKey(schema, table, value string) string {
+	return schema + "\x00" + table + "\x00" + value
+}
+
+// ensureListPartitions creates missing LIST partitions for the values being
+// written. Known partitions are cached, so steady-state writes skip the DDL.
+func (c *PostgresCollection) ensureListPartitions(ctx context.Context, values []string) error {
+	missing := make([]string, 0)
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		if _, ok := c.store.partitions.Load(partitionCacheKey(c.store.opts.Schema, c.name, value)); !ok {
+			missing = append(missing, value)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+
+	err := c.store.withSchemaLock(ctx, advisoryLockKey(c.store.opts.Schema, c.name), func(tx pgx.Tx) error {
+		return c.store.createListPartitions(ctx, tx, c.name, missing)
+	})
+	if err != nil {
+		return err
+	}
+	c.store.rememberPartitions(c.name, missing)
+	return nil
+}
+
+// createPartitionedIndexConcurrently builds an index on every partition with
+// CREATE INDEX CONCURRENTLY and attaches each to an index created ON ONLY the
+// parent, because CONCURRENTLY is not supported on a partitioned table. The
+// parent index becomes valid once every partition is attached, and partitions
+// created later inherit it.
+func (c *PostgresCollection) createPartitionedIndexConcurrently(ctx context.Context, indexName string, definition string) error {
+	parentQuery := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON ONLY %s %s", quoteIdent(indexName), c.tableName(), definition)
+	if err := c.store.execSchema(ctx, c.schemaDB(), parentQuery); err != nil {
+		return err
+	}
+
+	partitions, err := c.partitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(partition))
+		partitionIndex := fmt.Sprintf("%s_%016x", indexName, h.Sum64())
+
+		query := fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s %s",
+			quoteIdent(partitionIndex),
+			qualifiedTable(c.store.opts.Schema, partition),
+			definition,
+		)
+		if err := c.createIndexConcurrently(ctx, partitionIndex, query); err != nil {
+			return err
+		}
+
+		// Attaching an index that is already attached to this parent is a no-op.
+		attach := fmt.Sprintf("ALTER INDEX %s ATTACH PARTITION %s",
+			qualifiedTable(c.store.opts.Schema, indexName),
+			qualifiedTable(c.store.opts.Schema, partitionIndex),
+		)
+		if err := c.store.execSchema(ctx, c.schemaDB(), attach); err != nil {
+			return fmt.Errorf("attach index %q: %w", partitionIndex, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newPartitionedUnitTestCollection(t *testing.T, spec vectordata.PartitionSpec) *PostgresCollection {
+	t.Helper()
+	partition, err := normalizePartition(&spec)
+	if err != nil {
+		t.Fatalf("normalizePartition: %v", err)
+	}
+	store := &PostgresVectorStore{opts: DefaultStoreOptions(), statements: newStatementCache()}
+	return store.newCollectionHandle("docs", 2, vectordata.DistanceCosine, partition).(*PostgresCollection)
+}
+
+func TestNormalizePartition(t *testing.T) {
+	// Arrange
+	invalid := []vectordata.PartitionSpec{
+		{Key: "Tenant"},
+		{Key: "metadata"},
+		{Key: "tenant_id", Modulus: 4},
+		{Key: "tenant_id", Method: vectordata.PartitionHash},
+		{Key: "tenant_id", Method: vectordata.PartitionHash, Modulus: 2, Values: []string{"a"}},
+		{Key: "tenant_id", Method: "range"},
+	}
+
+	// Act
+	list, listErr := normalizePartition(&vectordata.PartitionSpec{Key: " tenant_id "})
+	none, noneErr := normalizePartition(nil)
+
+	// Assert
+	if listErr != nil || noneErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", listErr, noneErr)
+	}
+	if list.method != vectordata.PartitionList || list.key != "tenant_id" {
+		t.Fatalf("unexpected normalized partition %+v", list)
+	}
+	if list.keyDef() != "LIST (tenant_id)" {
+		t.Fatalf("unexpected key definition %q", list.keyDef())
+	}
+	if none != nil {
+		t.Fatalf("expected nil partitioning, got %+v", none)
+	}
+	for _, spec := range invalid {
+		if _, err := normalizePartition(&spec); !errors.Is(err, vectordata.ErrSchemaMismatch) {
+			t.Fatalf("expected ErrSchemaMismatch for %+v, got %v", spec, err)
+		}
+	}
+}
+
+func TestPartitioning_PartitionValue(t *testing.T) {
+	// Arrange
+	p := &partitioning{method: vectordata.PartitionList, key: "tenant_id"}
+
+	// Act
+	text, textErr := p.partitionValue(vectordata.Record{ID: "a", Metadata: map[string]any{"tenant_id": "acme"}})
+	number, numberErr := p.partitionValue(vectordata.Record{ID: "b", Metadata: map[string]any{"tenant_id": 42}})
+	_, missingErr := p.partitionValue(vectordata.Record{ID: "c"})
+
+	// Assert
+	if textErr != nil || numberErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", textErr, numberErr)
+	}
+	if text != "acme" || number != "42" {
+		t.Fatalf("unexpected partition values %q, %q", text, number)
+	}
+	if missingErr == nil {
+		t.Fatalf("expected error for a record without the partition key")
+	}
+}
+
+func TestPostgresCollection_PartitionedWriteBatch(t *testing.T) {
+	// Arrange
+	collection := newPartitionedUnitTestCollection(t, vectordata.PartitionSpec{Key: "tenant_id"})
+	records := []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tenant_id": "acme"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"tenant_id": "globex"}},
+	}
+
+	// Act
+	query, args, err := collection.buildWriteBatch(records, writeModeUpsert)
+	plainQuery, _, plainErr := newUnitTestCollection(vectordata.DistanceCosine).buildWriteBatch(records, writeModeUpsert)
+
+	// Assert
+	if err != nil || plainErr != nil {
+		t.Fatalf("buildWriteBatch: %v, %v", err, plainErr)
+	}
+	expected := `INSERT INTO "public"."docs" ("id", "vector", "metadata", "content", "tenant_id") SELECT r.id, r.vector::vector, r.metadata::jsonb, r.content, r.partition_key` +
+		` FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[]) AS r(id, vector, metadata, content, partition_key)` +
+		` ON CONFLICT ("id", "tenant_id") DO UPDATE SET "vector" = EXCLUDED."vector", "metadata" = EXCLUDED."metadata", "content" = EXCLUDED."content"`
+	if query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, query)
+	}
+	if len(args) != 5 || !reflect.DeepEqual(args[4], []string{"acme", "globex"}) {
+		t.Fatalf("unexpected partition key argument %#v", args)
+	}
+	if plainQuery == query {
+		t.Fatalf("expected partitioned and plain handles to use distinct cached statements")
+	}
+}
+
+func TestPostgresCollection_PartitionKeyFilterUsesColumn(t *testing.T) {
+	// Arrange
+	collection := newPartitionedUnitTestCollection(t, vectordata.PartitionSpec{Key: "tenant_id"})
+
+	// Act
+	whereSQL, args, _, err := vectordata.CompileFilterSQL(
+		vectordata.Eq(vectordata.Column("tenant_id"), "acme"),
+		collection.filterConfig(),
+		1,
+	)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CompileFilterSQL: %v", err)
+	}
+	if whereSQL != `("tenant_id" = $1)` || !reflect.DeepEqual(args, []any{"acme"}) {
+		t.Fatalf("unexpected filter %q %#v", whereSQL, args)
+	}
+}
+
+func TestPartitionNames(t *testing.T) {
+	// Act
+	first := listPartitionName("docs", "acme")
+	second := listPartitionName("docs", "acme")
+	other := listPartitionName("docs", "globex")
+
+	// Assert
+	if first != second || first == other {
+		t.Fatalf("expected stable, distinct list partition names, got %q %q %q", first, second, other)
+	}
+	if got := hashPartitionName("docs", 3); got != "docs_p3" {
+		t.Fatalf("unexpected hash partition name %q", got)
+	}
+	if got := quoteLiteral("o'brien"); got != "'o''brien'" {
+		t.Fatalf("unexpected literal %q", got)
+	}
+}
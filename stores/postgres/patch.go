@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var (
+	_ vectordata.MetadataPatcher      = (*PostgresCollection)(nil)
+	_ vectordata.MetadataArrayUpdater = (*PostgresCollection)(nil)
+)
+
+// PatchMetadata applies patch to the metadata of record id as a JSON merge
+// patch in a single UPDATE, so concurrent patches of different keys never
+// overwrite each other. Collections partitioned on a metadata key patch with
+// a Get and Upsert instead, which keeps the promoted partition column in
+// step when the patch changes the key.
+func (c *PostgresCollection) PatchMetadata(ctx context.Context, id string, patch map[string]any) error {
+	if c.partition != nil {
+		record, err := c.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		record.Metadata = vectordata.MergePatch(record.Metadata, patch)
+		return c.Upsert(ctx, []vectordata.Record{record})
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	started := time.Now()
+	args := []any{id}
+	expr, err := mergePatchSQL(quoteIdent(metadataColumn), patch, &args)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s = %s WHERE %s = $1`,
+		c.tableName(), quoteIdent(metadataColumn), expr, quoteIdent(idColumn))
+
+	var updated int64
+	stats := queryStats{op: "PatchMetadata", collection: c.name, query: query, args: args, started: started, executed: time.Now()}
+	err = c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, args...)
+		updated = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = updated, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: record %q", vectordata.ErrNotFound, id)
+	}
+	return nil
+}
+
+// mergePatchSQL builds a jsonb expression applying patch to target. Removed
+// keys are dropped with -, plain values are merged with || in one argument,
+// and nested objects recurse on the existing member, which is treated as an
+// empty object when it is missing or not an object.
+func mergePatchSQL(target string, patch map[string]any, args *[]any) (string, error) {
+	expr := jsonObjectSQL(target)
+
+	var removed []string
+	values := make(map[string]any)
+	var nested []string
+	for _, key := range slices.Sorted(maps.Keys(patch)) {
+		switch value := patch[key].(type) {
+		case nil:
+			removed = append(removed, key)
+		case map[string]any:
+			nested = append(nested, key)
+		default:
+			values[key] = value
+		}
+	}
+
+	if len(removed) > 0 {
+		*args = append(*args, removed)
+		expr = fmt.Sprintf(`(%s - $%d::text[])`, expr, len(*args))
+	}
+	if len(values) > 0 {
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return "", fmt.Errorf("encode metadata patch: %w", err)
+		}
+		*args = append(*args, string(encoded))
+		expr = fmt.Sprintf(`(%s || $%d::jsonb)`, expr, len(*args))
+	}
+	for _, key := range nested {
+		*args = append(*args, key)
+		keyParam := len(*args)
+		member := fmt.Sprintf(`(%s -> $%d::text)`, target, keyParam)
+		sub, err := mergePatchSQL(member, patch[key].(map[string]any), args)
+		if err != nil {
+			return "", err
+		}
+		expr = fmt.Sprintf(`(%s || jsonb_build_object($%d::text, %s))`, expr, keyParam, sub)
+	}
+	return expr, nil
+}
+
+// UpdateMetadataArray applies update in a single UPDATE that rebuilds the
+// array with jsonb_array_elements: kept elements in their order, then the
+// appended values that were absent. Rows whose field holds a non-array are
+// left alone and reported as vectordata.ErrInvalidRecord.
+func (c *PostgresCollection) UpdateMetadataArray(ctx context.Context, id string, update vectordata.ArrayUpdate) error {
+	path := update.Field.Path
+	if c.partition != nil && path[0] == c.partition.key {
+		record, err := c.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		record.Metadata, err = vectordata.ApplyArrayUpdate(record.Metadata, update)
+		if err != nil {
+			return fmt.Errorf("record %q: %w", id, err)
+		}
+		return c.Upsert(ctx, []vectordata.Record{record})
+	}
+
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Write)
+	defer cancel()
+	started := time.Now()
+	query, args, err := c.buildArrayUpdateQuery(id, update)
+	if err != nil {
+		return err
+	}
+	var updated int64
+	stats := queryStats{op: "UpdateMetadataArray", collection: c.name, query: query, args: args, started: started, executed: time.Now()}
+	err = c.store.withTenant(ctx, func(q queryExecutor) error {
+		cmd, err := q.Exec(ctx, query, args...)
+		updated = cmd.RowsAffected()
+		return err
+	})
+	stats.rows, stats.err = updated, err
+	c.store.finishQuery(ctx, stats)
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+	if _, err := c.Get(ctx, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: record %q: metadata field %v is not an array", vectordata.ErrInvalidRecord, id, path)
+}
+
+func (c *PostgresCollection) buildArrayUpdateQuery(id string, update vectordata.ArrayUpdate) (string, []any, error) {
+	remove, err := json.Marshal(update.Remove)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode array values: %w", err)
+	}
+	appendValues, err := json.Marshal(update.Append)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode array values: %w", err)
+	}
+	args := []any{id, update.Field.Path, string(remove), string(appendValues)}
+	metadata := quoteIdent(metadataColumn)
+	current := fmt.Sprintf(`(%s #> $2::text[])`, metadata)
+	array := fmt.Sprintf(`(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s ELSE '[]'::jsonb END)`, current, current)
+	value := fmt.Sprintf(`(SELECT COALESCE(jsonb_agg(e ORDER BY g, o), '[]'::jsonb) FROM (`+
+		`SELECT 0 AS g, o, e FROM jsonb_array_elements(%s) WITH ORDINALITY AS kept(e, o) `+
+		`WHERE e NOT IN (SELECT jsonb_array_elements($3::jsonb)) `+
+		`UNION ALL SELECT 1, o, e FROM jsonb_array_elements($4::jsonb) WITH ORDINALITY AS appended(e, o) `+
+		`WHERE e NOT IN (SELECT jsonb_array_elements(%s))) AS elements)`, array, array)
+	query := fmt.Sprintf(`UPDATE %s SET %s = %s WHERE %s = $1 AND COALESCE(jsonb_typeof(%s), 'null') IN ('array', 'null')`,
+		c.tableName(), metadata, setPathSQL(metadata, update.Field.Path, value, &args), quoteIdent(idColumn), current)
+	return query, args, nil
+}
+
+// setPathSQL builds a jsonb expression setting path in target to value,
+// creating or replacing the objects along the way.
+func setPathSQL(target string, path []string, value string, args *[]any) string {
+	if len(path) == 0 {
+		return value
+	}
+	*args = append(*args, path[0])
+	keyParam := len(*args)
+	member := fmt.Sprintf(`(%s -> $%d::text)`, target, keyParam)
+	return fmt.Sprintf(`(%s || jsonb_build_object($%d::text, %s))`, jsonObjectSQL(target), keyParam, setPathSQL(member, path[1:], value, args))
+}
+
+// jsonObjectSQL yields target when it holds a JSON object and an empty
+// object otherwise, so missing or scalar members can be merged into.
+func jsonObjectSQL(target string) string {
+	return fmt.Sprintf(`(CASE WHEN jsonb_typeof(%s) = 'object' THEN %s ELSE '{}'::jsonb END)`, target, target)
+}
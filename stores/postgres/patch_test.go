@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestMergePatchSQL_RemovesMergesAndRecurses(t *testing.T) {
+	// Arrange
+	patch := map[string]any{
+		"lang":  nil,
+		"rank":  2,
+		"stats": map[string]any{"views": 5, "likes": nil},
+	}
+	args := []any{"a"}
+
+	// Act
+	expr, err := mergePatchSQL(`"metadata"`, patch, &args)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("mergePatchSQL: %v", err)
+	}
+	object := func(target string) string {
+		return `(CASE WHEN jsonb_typeof(` + target + `) = 'object' THEN ` + target + ` ELSE '{}'::jsonb END)`
+	}
+	stats := `("metadata" -> $4::text)`
+	expected := `(((` + object(`"metadata"`) + ` - $2::text[]) || $3::jsonb) || jsonb_build_object($4::text, ` +
+		`((` + object(stats) + ` - $5::text[]) || $6::jsonb)))`
+	if expr != expected {
+		t.Fatalf("unexpected expression\nwant: %s\n got: %s", expected, expr)
+	}
+	expectedArgs := []any{"a", []string{"lang"}, `{"rank":2}`, "stats", []string{"likes"}, `{"views":5}`}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestPostgresCollection_ArrayUpdateQueryRebuildsNestedArray(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	update := vectordata.ArrayUpdate{Field: vectordata.Metadata("review", "tags"), Append: []any{"reviewed"}, Remove: []any{"draft"}}
+
+	// Act
+	query, args, err := collection.buildArrayUpdateQuery("a", update)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildArrayUpdateQuery: %v", err)
+	}
+	expectedArgs := []any{"a", []string{"review", "tags"}, `["draft"]`, `["reviewed"]`, "review", "tags"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	for _, fragment := range []string{
+		`UPDATE "public"."docs" SET "metadata" = (`,
+		`jsonb_build_object($5::text, `,
+		`jsonb_build_object($6::text, (SELECT COALESCE(jsonb_agg(e ORDER BY g, o), '[]'::jsonb)`,
+		`WHERE e NOT IN (SELECT jsonb_array_elements($3::jsonb))`,
+		`FROM jsonb_array_elements($4::jsonb) WITH ORDINALITY AS appended(e, o)`,
+		`WHERE "id" = $1 AND COALESCE(jsonb_typeof(("metadata" #> $2::text[])), 'null') IN ('array', 'null')`,
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("expected %q in\n%s", fragment, query)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PlanCollection returns the statements EnsureCollection runs to create spec
+// in an empty schema, without connecting to the database. They include the
+// extension, the schema, the catalog and the timestamp function, all guarded
+// by IF NOT EXISTS except the catalog INSERT, and are meant to run in one
+// transaction, e.g. as a migration file.
+func (s *PostgresVectorStore) PlanCollection(spec vectordata.CollectionSpec) ([]string, error) {
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	partition, err := normalizePartition(normalizedSpec.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	plan := &planExecutor{}
+	if err := s.ensureBaseSchema(ctx, plan); err != nil {
+		return nil, err
+	}
+	if err := s.ensureTableWithValidation(ctx, plan, normalizedSpec, partition, mode); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCatalogEntry(ctx, plan, normalizedSpec); err != nil {
+		return nil, err
+	}
+	return plan.statements, nil
+}
+
+// PlanIndexes returns the statements EnsureIndexes runs with opts on the
+// collection PlanCollection creates from spec, without connecting to the
+// database. CONCURRENTLY statements cannot run inside a transaction, and the
+// indexes of a partitioned collection cover the HASH partitions or the
+// declared LIST values only.
+func (s *PostgresVectorStore) PlanIndexes(spec vectordata.CollectionSpec, opts vectordata.IndexOptions) ([]string, error) {
+	normalizedSpec, _, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	partition, err := normalizePartition(normalizedSpec.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := s.collectionFromSpec(normalizedSpec, partition)
+	collection.plan = &planExecutor{}
+	if err := collection.ensureIndexes(context.Background(), opts); err != nil {
+		return nil, err
+	}
+	return collection.plan.statements, nil
+}
+
+// planExecutor records the statements of a plan instead of running them.
+// Without db it answers lookups as an empty schema would: EXISTS checks scan
+// false and other rows are missing, so the Ensure code takes its create
+// branches. With db, lookups read the live schema.
+type planExecutor struct {
+	db         schemaExecutor
+	statements []string
+}
+
+func (p *planExecutor) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	statement, err := inlineArgs(sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	p.statements = append(p.statements, statement)
+	return pgconn.CommandTag{}, nil
+}
+
+func (p *planExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if p.db != nil {
+		return p.db.Query(ctx, sql, args...)
+	}
+	return nil, errors.New("plan: the schema cannot be read")
+}
+
+func (p *planExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if p.db != nil {
+		return p.db.QueryRow(ctx, sql, args...)
+	}
+	return planRow{}
+}
+
+type planRow struct{}
+
+func (planRow) Scan(dest ...any) error {
+	for _, d := range dest {
+		if _, ok := d.(*bool); !ok {
+			return pgx.ErrNoRows
+		}
+	}
+	for _, d := range dest {
+		*d.(*bool) = false
+	}
+	return nil
+}
+
+// inlineArgs replaces the $n placeholders of sql with literals, so recorded
+// statements run without arguments.
+func inlineArgs(sql string, args []any) (string, error) {
+	for i := len(args) - 1; i >= 0; i-- {
+		var literal string
+		switch v := args[i].(type) {
+		case string:
+			literal = quoteLiteral(v)
+		case int:
+			literal = strconv.Itoa(v)
+		case bool:
+			literal = strconv.FormatBool(v)
+		default:
+			return "", fmt.Errorf("plan: cannot inline argument $%d of type %T", i+1, v)
+		}
+		sql = strings.ReplaceAll(sql, "$"+strconv.Itoa(i+1), literal)
+	}
+	return sql, nil
+}
+
+// schemaDB returns the executor for index DDL: the recorder of a plan, the
+// transaction of a bound handle, or the pool.
+func (c *PostgresCollection) schemaDB() schemaExecutor {
+	if c.plan != nil {
+		return c.plan
+	}
+	if c.store.tx != nil {
+		return c.store.tx
+	}
+	return c.store.pool
+}
+
+// withSchemaLock runs fn under the store's advisory lock, or against the
+// recorder of a plan.
+func (c *PostgresCollection) withSchemaLock(ctx context.Context, key int64, fn func(schemaExecutor) error) error {
+	if c.plan != nil {
+		return fn(c.plan)
+	}
+	return c.store.withSchemaLock(ctx, key, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
+}
+
+// createIndexConcurrently runs a CREATE INDEX CONCURRENTLY statement, or
+// records it in a plan without the validity checks around it.
+func (c *PostgresCollection) createIndexConcurrently(ctx context.Context, indexName, query string) error {
+	if c.plan != nil {
+		return c.store.execSchema(ctx, c.plan, query)
+	}
+	if c.store.tx != nil {
+		return fmt.Errorf("%w: index %q is built concurrently, which cannot run in a transaction", errors.ErrUnsupported, indexName)
+	}
+	return c.store.createIndexConcurrently(ctx, indexName, query)
+}
+
+// partitions returns the partition tables of the collection. A plan lists
+// the partitions EnsureCollection creates.
+func (c *PostgresCollection) partitions(ctx context.Context) ([]string, error) {
+	if c.plan == nil {
+		return c.store.listPartitions(ctx, c.name)
+	}
+	var partitions []string
+	if c.partition.method == vectordata.PartitionHash {
+		for remainder := 0; remainder < c.partition.modulus; remainder++ {
+			partitions = append(partitions, hashPartitionName(c.name, remainder))
+		}
+	} else {
+		for _, value := range c.partition.values {
+			partitions = append(partitions, listPartitionName(c.name, value))
+		}
+	}
+	slices.Sort(partitions)
+	return partitions, nil
+}
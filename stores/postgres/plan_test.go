@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPlanCollection_CreatesSchemaTableAndCatalogEntry(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.Schema = "app"
+	store := &PostgresVectorStore{opts: opts.withDefaults()}
+
+	// Act
+	statements, err := store.PlanCollection(vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceL2})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlanCollection: %v", err)
+	}
+	all := strings.Join(statements, ";\n")
+	for _, want := range []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE SCHEMA IF NOT EXISTS "app"`,
+		`CREATE TABLE IF NOT EXISTS "app"."__vector_collections"`,
+		`CREATE FUNCTION "app"."vectorstore_timestamptz"`,
+		`CREATE TABLE IF NOT EXISTS "app"."docs" ("id" text PRIMARY KEY, "vector" vector(3) NOT NULL`,
+		`VALUES ('docs', 3, 'l2', false, 'float32', 0)`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected %q in plan:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "$1") {
+		t.Fatalf("expected inlined arguments:\n%s", all)
+	}
+}
+
+func TestPlanCollection_RejectsInvalidSpec(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+
+	// Act
+	_, err := store.PlanCollection(vectordata.CollectionSpec{Name: "docs"})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestPlanIndexes_PartitionedConcurrentIndexes(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: DefaultStoreOptions()}
+	spec := vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 3,
+		Partition: &vectordata.PartitionSpec{Key: "tenant", Method: vectordata.PartitionHash, Modulus: 2},
+	}
+
+	// Act
+	statements, err := store.PlanIndexes(spec, vectordata.IndexOptions{
+		Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW, Concurrent: true},
+		Metadata: &vectordata.MetadataIndexOptions{Keys: []vectordata.MetadataKeyIndex{
+			{Path: []string{"published"}, Type: vectordata.MetadataKeyTimestamp},
+		}},
+		Text: &vectordata.TextIndexOptions{},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlanIndexes: %v", err)
+	}
+	all := strings.Join(statements, ";\n")
+	for _, want := range []string{
+		`CREATE INDEX IF NOT EXISTS "idx_docs_vector_hnsw" ON ONLY "public"."docs" USING hnsw`,
+		`ON "public"."docs_p0" USING hnsw`,
+		`ON "public"."docs_p1" USING hnsw`,
+		`ALTER INDEX "public"."idx_docs_vector_hnsw" ATTACH PARTITION`,
+		`"public"."vectorstore_timestamptz"(`,
+		`ADD COLUMN IF NOT EXISTS "content_tsv" tsvector`,
+		`CREATE INDEX IF NOT EXISTS "idx_docs_content_tsv_gin"`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected %q in plan:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "CREATE FUNCTION") {
+		t.Fatalf("expected the timestamp function to be left to PlanCollection:\n%s", all)
+	}
+}
@@ -4,14 +4,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/gabisonia/go-vectorstore/vectordata/vectordatatest"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	testcontainers "github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -144,7 +150,12 @@ func waitForDatabase(parent context.Context, dsn string) error {
 	}
 }
 
-func integrationPool(t *testing.T) *pgxpool.Pool {
+func integrationPool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	return integrationPoolWithConfig(t, nil)
+}
+
+func integrationPoolWithConfig(t testing.TB, configure func(*pgxpool.Config)) *pgxpool.Pool {
 	t.Helper()
 
 	dsn := strings.TrimSpace(integrationDSN)
@@ -159,6 +170,9 @@ func integrationPool(t *testing.T) *pgxpool.Pool {
 	if err != nil {
 		t.Fatalf("parse DSN: %v", err)
 	}
+	if configure != nil {
+		configure(cfg)
+	}
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		t.Fatalf("connect pool: %v", err)
@@ -168,7 +182,7 @@ func integrationPool(t *testing.T) *pgxpool.Pool {
 	return pool
 }
 
-func newTestStore(t *testing.T, pool *pgxpool.Pool) *PostgresVectorStore {
+func newTestStore(t testing.TB, pool *pgxpool.Pool) *PostgresVectorStore {
 	t.Helper()
 	seq := schemaSeq.Add(1)
 	schema := fmt.Sprintf("it_%d_%d", time.Now().UnixNano(), seq)
@@ -224,7 +238,50 @@ func TestIntegrationEnsureCollection(t *testing.T) {
 		t.Fatalf("expected collection table to exist")
 	}
 
-	dim, err := store.readVectorDimension(ctx, "docs")
+	dim, err := store.readVectorDimension(ctx, store.pool, "docs")
+	if err != nil {
+		t.Fatalf("readVectorDimension: %v", err)
+	}
+	if dim != 3 {
+		t.Fatalf("expected dimension 3, got %d", dim)
+	}
+}
+
+func TestIntegrationEnsureCollectionConcurrentCallersConverge(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const callers = 8
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+				Name:      "docs",
+				Dimension: 3,
+				Metric:    vectordata.DistanceCosine,
+				Mode:      vectordata.EnsureAutoMigrate,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent EnsureCollection: %v", err)
+		}
+	}
+	dim, err := store.readVectorDimension(ctx, store.pool, "docs")
 	if err != nil {
 		t.Fatalf("readVectorDimension: %v", err)
 	}
@@ -419,3 +476,2069 @@ func TestIntegrationMetadataFilter(t *testing.T) {
 		t.Fatalf("expected count 1, got %d", count)
 	}
 }
+
+func TestIntegrationMultiChunkInsertIsAtomic(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceCosine,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "taken", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	records := make([]vectordata.Record, 0, maxRowsPerStatement+1)
+	for i := 0; i < maxRowsPerStatement; i++ {
+		records = append(records, vectordata.Record{ID: fmt.Sprintf("r%d", i), Vector: []float32{0, 1}})
+	}
+	records = append(records, vectordata.Record{ID: "taken", Vector: []float32{0, 1}})
+
+	// Act
+	insertErr := collection.Insert(ctx, records)
+	count, countErr := collection.Count(ctx, nil)
+
+	// Assert
+	if !errors.Is(insertErr, vectordata.ErrConflict) {
+		t.Fatalf("expected ErrConflict from the second chunk, got %v", insertErr)
+	}
+	if countErr != nil {
+		t.Fatalf("Count: %v", countErr)
+	}
+	if count != 1 {
+		t.Fatalf("expected the first chunk to be rolled back, got count %d", count)
+	}
+}
+
+func TestIntegrationEnsureIndexesConcurrentReplacesInvalidIndex(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceCosine,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	content := "duplicate"
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Content: &content},
+		{ID: "b", Vector: []float32{0, 1}, Content: &content},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// A failed concurrent unique build leaves an INVALID index under the target name.
+	indexName := "idx_docs_vector_hnsw"
+	_, buildErr := pool.Exec(ctx, fmt.Sprintf(
+		`CREATE UNIQUE INDEX CONCURRENTLY %s ON %s (%s)`,
+		quoteIdent(indexName), collection.(*PostgresCollection).tableName(), quoteIdent(contentColumn),
+	))
+	if buildErr == nil {
+		t.Fatalf("expected unique index build to fail")
+	}
+
+	// Act
+	ensureErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{
+		Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW, Concurrent: true},
+	})
+	valid, exists, validityErr := store.indexValidity(ctx, indexName)
+
+	// Assert
+	if ensureErr != nil {
+		t.Fatalf("EnsureIndexes: %v", ensureErr)
+	}
+	if validityErr != nil {
+		t.Fatalf("indexValidity: %v", validityErr)
+	}
+	if !exists || !valid {
+		t.Fatalf("expected a valid index, got exists=%v valid=%v", exists, valid)
+	}
+}
+
+func TestIntegrationSearchSessionSettingsDoNotLeak(t *testing.T) {
+	// Arrange
+	pool := integrationPoolWithConfig(t, func(cfg *pgxpool.Config) {
+		cfg.MaxConns = 1
+	})
+	store := newTestStore(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceCosine,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	var before string
+	if err := pool.QueryRow(ctx, `SHOW work_mem`).Scan(&before); err != nil {
+		t.Fatalf("SHOW work_mem: %v", err)
+	}
+
+	// Act
+	results, searchErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{
+		SessionSettings: map[string]string{"work_mem": "7MB", "hnsw.ef_search": "100"},
+	})
+	var after string
+	showErr := pool.QueryRow(ctx, `SHOW work_mem`).Scan(&after)
+
+	// Assert
+	if searchErr != nil {
+		t.Fatalf("SearchByVector: %v", searchErr)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if showErr != nil {
+		t.Fatalf("SHOW work_mem: %v", showErr)
+	}
+	if after != before {
+		t.Fatalf("session setting leaked: work_mem %q -> %q", before, after)
+	}
+}
+
+// BenchmarkIntegrationSearchByVectorParallel compares cached prepared
+// statements against re-preparing every query. Run with:
+//
+//	go test -tags=integration -run '^$' -bench SearchByVectorParallel ./stores/postgres
+func BenchmarkIntegrationSearchByVectorParallel(b *testing.B) {
+	modes := map[string]pgx.QueryExecMode{
+		"cache_statement": pgx.QueryExecModeCacheStatement,
+		"exec":            pgx.QueryExecModeExec,
+	}
+
+	for name, mode := range modes {
+		b.Run(name, func(b *testing.B) {
+			pool := integrationPoolWithConfig(b, func(cfg *pgxpool.Config) {
+				cfg.ConnConfig.DefaultQueryExecMode = mode
+			})
+			store := newTestStore(b, pool)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+				Name:      "bench_docs",
+				Dimension: 16,
+				Metric:    vectordata.DistanceCosine,
+			})
+			if err != nil {
+				b.Fatalf("EnsureCollection: %v", err)
+			}
+
+			records := make([]vectordata.Record, 0, 1000)
+			for i := 0; i < 1000; i++ {
+				vector := make([]float32, 16)
+				for j := range vector {
+					vector[j] = float32((i*31+j*7)%97) / 97
+				}
+				records = append(records, vectordata.Record{
+					ID:       fmt.Sprintf("doc-%d", i),
+					Vector:   vector,
+					Metadata: map[string]any{"bucket": i % 10},
+				})
+			}
+			if err := collection.Upsert(ctx, records); err != nil {
+				b.Fatalf("Upsert: %v", err)
+			}
+
+			query := records[0].Vector
+			filter := vectordata.Eq(vectordata.Metadata("bucket"), 3)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := collection.SearchByVector(ctx, query, 10, vectordata.SearchOptions{Filter: filter}); err != nil {
+						b.Errorf("SearchByVector: %v", err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestIntegrationTextAndHybridSearch(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Text: &vectordata.TextIndexOptions{}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	texts := map[string]string{
+		"a": "postgres stores vectors",
+		"b": "cats sleep all day",
+		"c": "vector search in postgres with pgvector",
+	}
+	vectors := map[string][]float32{"a": {1, 0}, "b": {0, 1}, "c": {0.9, 0.1}}
+	records := make([]vectordata.Record, 0, len(texts))
+	for id, text := range texts {
+		content := text
+		records = append(records, vectordata.Record{ID: id, Vector: vectors[id], Content: &content})
+	}
+	if err := collection.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	pgCollection := collection.(*PostgresCollection)
+
+	// Act
+	textResults, textErr := pgCollection.SearchByText(ctx, "postgres", 10, vectordata.TextSearchOptions{})
+	hybridResults, hybridErr := pgCollection.HybridSearch(ctx, []float32{0, 1}, "sleeping cats", 1, vectordata.HybridSearchOptions{})
+
+	// Assert
+	if textErr != nil || hybridErr != nil {
+		t.Fatalf("search: %v %v", textErr, hybridErr)
+	}
+	if len(textResults) != 2 {
+		t.Fatalf("expected 2 text matches, got %d", len(textResults))
+	}
+	for _, result := range textResults {
+		if result.Record.ID == "b" || result.Score <= 0 {
+			t.Fatalf("unexpected text result: %#v", result)
+		}
+	}
+	if len(hybridResults) != 1 || hybridResults[0].Record.ID != "b" {
+		t.Fatalf("expected hybrid search to rank b first, got %#v", hybridResults)
+	}
+}
+
+func TestIntegrationEnsureCollectionTextSearchColumn(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine}
+	if _, err := store.EnsureCollection(ctx, spec); err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	store.opts.EnsureTextSearch = true
+
+	// Act
+	strictErr := func() error { _, err := store.EnsureCollection(ctx, spec); return err }()
+	spec.Mode = vectordata.EnsureAutoMigrate
+	_, migrateErr := store.EnsureCollection(ctx, spec)
+
+	// Assert
+	if !errors.Is(strictErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected strict mode to require the text search column, got %v", strictErr)
+	}
+	if migrateErr != nil {
+		t.Fatalf("auto-migrate EnsureCollection: %v", migrateErr)
+	}
+	var udtName string
+	err := pool.QueryRow(ctx, `SELECT udt_name FROM information_schema.columns WHERE table_schema = $1 AND table_name = 'docs' AND column_name = $2`,
+		store.opts.Schema, textSearchColumn).Scan(&udtName)
+	if err != nil {
+		t.Fatalf("read column: %v", err)
+	}
+	if udtName != "tsvector" {
+		t.Fatalf("expected tsvector column, got %q", udtName)
+	}
+}
+
+func TestIntegrationTrigramFilters(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	err = collection.EnsureIndexes(ctx, vectordata.IndexOptions{Trigram: &vectordata.TrigramIndexOptions{
+		Content:       true,
+		MetadataPaths: [][]string{{"title"}},
+	}})
+	if err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	first, second := "Summer SALE: 50% off", "winter catalogue"
+	err = collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Content: &first, Metadata: map[string]any{"title": "postgres internals"}},
+		{ID: "b", Vector: []float32{0, 1}, Content: &second, Metadata: map[string]any{"title": "cooking basics"}},
+	})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	containsCount, containsErr := collection.Count(ctx, vectordata.Contains(vectordata.Column("content"), "50% OFF"))
+	wildcardCount, wildcardErr := collection.Count(ctx, vectordata.Contains(vectordata.Column("content"), "_"))
+	similarCount, similarErr := collection.Count(ctx, vectordata.Similar(vectordata.Metadata("title"), "postgress internal"))
+
+	// Assert
+	if containsErr != nil || wildcardErr != nil || similarErr != nil {
+		t.Fatalf("Count: %v %v %v", containsErr, wildcardErr, similarErr)
+	}
+	if containsCount != 1 {
+		t.Fatalf("expected 1 case-insensitive substring match, got %d", containsCount)
+	}
+	if wildcardCount != 0 {
+		t.Fatalf("expected LIKE wildcards to be escaped, got %d matches", wildcardCount)
+	}
+	if similarCount != 1 {
+		t.Fatalf("expected 1 similar title, got %d", similarCount)
+	}
+}
+
+func TestIntegrationListPartitionsCreatedOnWrite(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	spec := vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceCosine,
+		Partition: &vectordata.PartitionSpec{Key: "tenant_id", Values: []string{"acme"}},
+	}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+
+	// Act
+	err = collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tenant_id": "acme"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"tenant_id": "globex"}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Concurrent: true}})
+	partitions, listErr := store.listPartitions(ctx, "docs")
+	tenantCount, countErr := collection.Count(ctx, vectordata.Eq(vectordata.Column("tenant_id"), "globex"))
+	_, unpartitionedErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	missingKeyErr := collection.Insert(ctx, []vectordata.Record{{ID: "c", Vector: []float32{1, 1}}})
+
+	// Assert
+	if indexErr != nil || listErr != nil || countErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", indexErr, listErr, countErr)
+	}
+	if len(partitions) != 2 {
+		t.Fatalf("expected a pre-created and an on-demand partition, got %v", partitions)
+	}
+	if tenantCount != 1 {
+		t.Fatalf("expected 1 record for tenant globex, got %d", tenantCount)
+	}
+	if !errors.Is(unpartitionedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch without a partition spec, got %v", unpartitionedErr)
+	}
+	if missingKeyErr == nil {
+		t.Fatalf("expected an error for a record without the partition key")
+	}
+
+	var validIndexes int
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE c.relname LIKE 'idx_docs_vector_hnsw%' AND i.indisvalid
+	`).Scan(&validIndexes)
+	if err != nil {
+		t.Fatalf("read index validity: %v", err)
+	}
+	if validIndexes != 3 {
+		t.Fatalf("expected a valid parent index and one per partition, got %d", validIndexes)
+	}
+}
+
+func TestIntegrationHashPartitions(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	spec := vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceCosine,
+		Partition: &vectordata.PartitionSpec{Method: vectordata.PartitionHash, Key: "tenant_id", Modulus: 4},
+	}
+
+	// Act
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	_, againErr := store.EnsureCollection(ctx, spec)
+	spec.Partition = &vectordata.PartitionSpec{Key: "tenant_id"}
+	_, mismatchErr := store.EnsureCollection(ctx, spec)
+	insertErr := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tenant_id": "acme"}},
+		{ID: "a", Vector: []float32{0, 1}, Metadata: map[string]any{"tenant_id": "globex"}},
+	})
+	partitions, listErr := store.listPartitions(ctx, "docs")
+	results, searchErr := collection.SearchByVector(ctx, []float32{1, 0}, 5, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Column("tenant_id"), "acme"),
+	})
+
+	// Assert
+	if againErr != nil || insertErr != nil || listErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v", againErr, insertErr, listErr, searchErr)
+	}
+	if !errors.Is(mismatchErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a different partition method, got %v", mismatchErr)
+	}
+	if len(partitions) != 4 {
+		t.Fatalf("expected 4 hash partitions, got %v", partitions)
+	}
+	if len(results) != 1 || results[0].Record.Metadata["tenant_id"] != "acme" {
+		t.Fatalf("expected the acme record only, got %+v", results)
+	}
+}
+
+func TestIntegrationRowLevelSecurityIsolatesTenants(t *testing.T) {
+	// Arrange
+	adminPool := integrationPool(t)
+	adminStore := newTestStore(t, adminPool)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rlsStore, err := NewVectorStore(adminPool, StoreOptions{
+		Schema:           adminStore.opts.Schema,
+		EnsureExtension:  true,
+		StrictByDefault:  true,
+		RowLevelSecurity: &RowLevelSecurityOptions{},
+	})
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	if _, err := rlsStore.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine}); err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	_, plainErr := adminStore.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if plainErr != nil {
+		t.Fatalf("EnsureCollection without RLS options: %v", plainErr)
+	}
+
+	// Superusers bypass RLS, so the collection is used through a plain role.
+	setup := []string{
+		`DO $$ BEGIN CREATE ROLE vectorstore_tenant_app NOLOGIN; EXCEPTION WHEN duplicate_object THEN NULL; END $$`,
+		fmt.Sprintf(`GRANT USAGE ON SCHEMA %s TO vectorstore_tenant_app`, quoteIdent(rlsStore.opts.Schema)),
+		fmt.Sprintf(`GRANT SELECT, INSERT, UPDATE, DELETE ON %s TO vectorstore_tenant_app`, qualifiedTable(rlsStore.opts.Schema, "docs")),
+	}
+	for _, query := range setup {
+		if _, err := adminPool.Exec(ctx, query); err != nil {
+			t.Fatalf("setup %q: %v", query, err)
+		}
+	}
+	appPool := integrationPoolWithConfig(t, func(cfg *pgxpool.Config) {
+		cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			_, err := conn.Exec(ctx, `SET ROLE vectorstore_tenant_app`)
+			return err
+		}
+	})
+	appStore, err := NewVectorStore(appPool, StoreOptions{Schema: rlsStore.opts.Schema})
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	collection := appStore.Collection("docs", 2, vectordata.DistanceCosine)
+
+	acme := WithTenant(ctx, "acme")
+	globex := WithTenant(ctx, "globex")
+	if err := collection.Insert(acme, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tenant_id": "acme"}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	acmeCount, acmeErr := collection.Count(acme, nil)
+	globexCount, globexErr := collection.Count(globex, nil)
+	noTenantCount, noTenantErr := collection.Count(ctx, nil)
+	_, crossTenantGetErr := collection.Get(globex, "a")
+	crossTenantWriteErr := collection.Insert(globex, []vectordata.Record{{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"tenant_id": "acme"}}})
+	results, searchErr := collection.SearchByVector(acme, []float32{1, 0}, 5, vectordata.SearchOptions{
+		SessionSettings: map[string]string{"hnsw.ef_search": "40"},
+	})
+
+	// Assert
+	if acmeErr != nil || globexErr != nil || noTenantErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v", acmeErr, globexErr, noTenantErr, searchErr)
+	}
+	if acmeCount != 1 || globexCount != 0 || noTenantCount != 0 {
+		t.Fatalf("expected counts 1/0/0, got %d/%d/%d", acmeCount, globexCount, noTenantCount)
+	}
+	if !errors.Is(crossTenantGetErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound across tenants, got %v", crossTenantGetErr)
+	}
+	if crossTenantWriteErr == nil {
+		t.Fatalf("expected the policy to reject a row for another tenant")
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 search result with session settings, got %d", len(results))
+	}
+}
+
+func TestIntegrationHalfvecIndexAboveVectorLimit(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	const dimension = 2048
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: dimension, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	records := make([]vectordata.Record, 0, 3)
+	for i := 0; i < 3; i++ {
+		vector := make([]float32, dimension)
+		vector[i] = 1
+		vector[dimension-1] = 0.1
+		records = append(records, vectordata.Record{ID: fmt.Sprintf("r%d", i), Vector: vector})
+	}
+	if err := collection.Insert(ctx, records); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW}})
+	results, searchErr := collection.SearchByVector(ctx, records[1].Vector, 2, vectordata.SearchOptions{
+		Projection: &vectordata.Projection{IncludeVector: true},
+	})
+
+	// Assert
+	if indexErr != nil {
+		t.Fatalf("EnsureIndexes: %v", indexErr)
+	}
+	if searchErr != nil {
+		t.Fatalf("SearchByVector: %v", searchErr)
+	}
+	if len(results) != 2 || results[0].Record.ID != "r1" {
+		t.Fatalf("expected r1 first, got %+v", results)
+	}
+	if results[0].Distance > 1e-6 {
+		t.Fatalf("expected an exact rescored distance of 0, got %v", results[0].Distance)
+	}
+	if len(results[0].Record.Vector) != dimension {
+		t.Fatalf("expected full-precision vector of %d dims, got %d", dimension, len(results[0].Record.Vector))
+	}
+}
+
+func TestIntegrationMetadataKeyIndexesServeRangeFilters(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	err = collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"rank": 3, "created": "2024-05-01T12:00:00+02:00"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"rank": "n/a", "created": "yesterday"}},
+		{ID: "c", Vector: []float32{1, 1}, Metadata: map[string]any{"rank": 9, "created": "2024-05-02T00:00:00Z"}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Metadata: &vectordata.MetadataIndexOptions{
+		Keys: []vectordata.MetadataKeyIndex{
+			{Path: []string{"rank"}, Type: vectordata.MetadataKeyNumeric},
+			{Path: []string{"created"}, Type: vectordata.MetadataKeyTimestamp},
+		},
+	}})
+	numeric, numericErr := collection.Count(ctx, vectordata.Gt(vectordata.Metadata("rank"), 5))
+	notNumeric, notNumericErr := collection.Count(ctx, vectordata.Not(vectordata.Gt(vectordata.Metadata("rank"), 5)))
+	timestamp, timestampErr := collection.Count(ctx, vectordata.Lt(vectordata.Metadata("created"), time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)))
+
+	var plan strings.Builder
+	explainErr := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SET LOCAL enable_seqscan = off`); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, fmt.Sprintf(
+			`EXPLAIN (COSTS OFF) SELECT id FROM %s WHERE %s > 5`,
+			collection.(*PostgresCollection).tableName(),
+			vectordata.MetadataPathNumericSQL(quoteIdent(metadataColumn), []string{"rank"}),
+		))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				return err
+			}
+			plan.WriteString(line)
+		}
+		return rows.Err()
+	})
+
+	// Assert
+	if indexErr != nil {
+		t.Fatalf("EnsureIndexes: %v", indexErr)
+	}
+	if numericErr != nil || notNumericErr != nil || timestampErr != nil {
+		t.Fatalf("Count errors: %v, %v, %v", numericErr, notNumericErr, timestampErr)
+	}
+	if numeric != 1 || notNumeric != 2 || timestamp != 1 {
+		t.Fatalf("unexpected counts numeric=%d notNumeric=%d timestamp=%d", numeric, notNumeric, timestamp)
+	}
+	if explainErr != nil {
+		t.Fatalf("EXPLAIN: %v", explainErr)
+	}
+	if !strings.Contains(plan.String(), "idx_docs_metadata_rank_numeric") {
+		t.Fatalf("expected the numeric key index in the plan, got %s", plan.String())
+	}
+}
+
+func TestIntegrationReadPoolServesReads(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	readPool := integrationPoolWithConfig(t, func(cfg *pgxpool.Config) {
+		// Stand in for a replica: any write routed here would fail.
+		cfg.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	})
+	base := newTestStore(t, pool)
+	opts := base.opts
+	opts.ReadPool = readPool
+	store, err := NewVectorStore(pool, opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	readsBefore := readPool.Stat().AcquireCount()
+	results, searchErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{})
+	_, getErr := collection.Get(ctx, "a")
+	readsAfterReplica := readPool.Stat().AcquireCount()
+	count, countErr := collection.Count(WithPrimaryReads(ctx), nil)
+	readsAfterPrimary := readPool.Stat().AcquireCount()
+
+	// Assert
+	if searchErr != nil || getErr != nil || countErr != nil {
+		t.Fatalf("read errors: %v, %v, %v", searchErr, getErr, countErr)
+	}
+	if len(results) != 1 || count != 1 {
+		t.Fatalf("unexpected results %d and count %d", len(results), count)
+	}
+	if readsAfterReplica-readsBefore != 2 {
+		t.Fatalf("expected 2 reads on the read pool, got %d", readsAfterReplica-readsBefore)
+	}
+	if readsAfterPrimary != readsAfterReplica {
+		t.Fatal("expected WithPrimaryReads to bypass the read pool")
+	}
+}
+
+func TestIntegrationForSchemaIsolatesTenants(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	schemas := []string{store.opts.Schema + "_a", store.opts.Schema + "_b"}
+	t.Cleanup(func() {
+		for _, schema := range schemas {
+			_, _ = pool.Exec(context.Background(), fmt.Sprintf(`DROP SCHEMA IF EXISTS %s CASCADE`, quoteIdent(schema)))
+		}
+	})
+	collections := make([]vectordata.Collection, 0, len(schemas))
+	for _, schema := range schemas {
+		scoped, err := store.ForSchema(schema)
+		if err != nil {
+			t.Fatalf("ForSchema: %v", err)
+		}
+		collection, err := scoped.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+		if err != nil {
+			t.Fatalf("EnsureCollection in %s: %v", schema, err)
+		}
+		collections = append(collections, collection)
+	}
+
+	// Act
+	insertErr := collections[0].Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}})
+	countA, countAErr := collections[0].Count(ctx, nil)
+	countB, countBErr := collections[1].Count(ctx, nil)
+
+	// Assert
+	if insertErr != nil || countAErr != nil || countBErr != nil {
+		t.Fatalf("unexpected errors: %v, %v, %v", insertErr, countAErr, countBErr)
+	}
+	if countA != 1 || countB != 0 {
+		t.Fatalf("expected tenant schemas to be isolated, got %d and %d", countA, countB)
+	}
+}
+
+func TestIntegrationCollectionCatalog(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	emptyList, emptyErr := store.ListCollections(ctx)
+	for _, name := range []string{"docs", "articles"} {
+		if _, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: name, Dimension: 3, Metric: vectordata.DistanceL2}); err != nil {
+			t.Fatalf("EnsureCollection %s: %v", name, err)
+		}
+	}
+
+	// Act
+	_, mismatchErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceCosine})
+	list, listErr := store.ListCollections(ctx)
+	info, describeErr := store.DescribeCollection(ctx, "docs")
+	_, missingErr := store.DescribeCollection(ctx, "missing")
+
+	// Assert
+	if emptyErr != nil || len(emptyList) != 0 {
+		t.Fatalf("expected no collections before ensuring the schema, got %v %v", emptyList, emptyErr)
+	}
+	if !errors.Is(mismatchErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a different metric, got %v", mismatchErr)
+	}
+	if listErr != nil || len(list) != 2 || list[0].Name != "articles" || list[1].Name != "docs" {
+		t.Fatalf("unexpected list %+v (%v)", list, listErr)
+	}
+	if describeErr != nil || info.Dimension != 3 || info.Metric != vectordata.DistanceL2 || info.CreatedAt.IsZero() {
+		t.Fatalf("unexpected description %+v (%v)", info, describeErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestIntegrationNormalizeVectors(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceInnerProduct, NormalizeVectors: true}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{3, 4}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	_, mixedErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceInnerProduct})
+	got, getErr := collection.Get(ctx, "a")
+	results, searchErr := collection.SearchByVector(ctx, []float32{6, 8}, 1, vectordata.SearchOptions{})
+	info, describeErr := store.DescribeCollection(ctx, "docs")
+
+	// Assert
+	if !errors.Is(mixedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a spec without NormalizeVectors, got %v", mixedErr)
+	}
+	if getErr != nil || math.Abs(float64(got.Vector[0])-0.6) > 1e-6 || math.Abs(float64(got.Vector[1])-0.8) > 1e-6 {
+		t.Fatalf("expected stored unit vector, got %v (%v)", got.Vector, getErr)
+	}
+	if searchErr != nil || len(results) != 1 || math.Abs(results[0].Distance+1) > 1e-6 {
+		t.Fatalf("expected inner product -1 with the normalized query, got %#v (%v)", results, searchErr)
+	}
+	if describeErr != nil || !info.NormalizeVectors {
+		t.Fatalf("expected catalog to record NormalizeVectors, got %+v (%v)", info, describeErr)
+	}
+}
+
+func TestIntegrationFloat64Vectors(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2, ElementType: vectordata.ElementFloat64}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	precise := []float64{0.1234567890123, 1.0000000001}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector64: precise}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	_, mixedErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	got, getErr := collection.Get(ctx, "a")
+	results, searchErr := collection.SearchByVector(ctx, []float32{0.1, 1}, 1, vectordata.SearchOptions{
+		Projection: &vectordata.Projection{IncludeVector: true},
+	})
+	info, describeErr := store.DescribeCollection(ctx, "docs")
+
+	// Assert
+	if !errors.Is(mixedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a float32 spec, got %v", mixedErr)
+	}
+	if getErr != nil || len(got.Vector64) != 2 || got.Vector64[0] != precise[0] || got.Vector64[1] != precise[1] {
+		t.Fatalf("expected exact Vector64, got %v (%v)", got.Vector64, getErr)
+	}
+	if len(got.Vector) != 2 || got.Vector[0] != float32(precise[0]) {
+		t.Fatalf("expected derived float32 vector, got %v", got.Vector)
+	}
+	if searchErr != nil || len(results) != 1 || results[0].Record.Vector64[1] != precise[1] {
+		t.Fatalf("expected searches to project Vector64, got %#v (%v)", results, searchErr)
+	}
+	if describeErr != nil || info.ElementType != vectordata.ElementFloat64 {
+		t.Fatalf("expected catalog to record float64, got %+v (%v)", info, describeErr)
+	}
+}
+
+func TestIntegrationConformance(t *testing.T) {
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+
+	vectordatatest.Run(t, vectordatatest.Options{
+		NewStore: func(t *testing.T) vectordata.VectorStore { return store },
+	})
+}
+
+func TestIntegrationPingAndReady(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	// Act
+	pingErr := store.Ping(ctx)
+	readyErr := store.Ready(ctx)
+	canceledErr := store.Ready(canceled)
+
+	// Assert
+	if pingErr != nil || readyErr != nil {
+		t.Fatalf("expected a healthy store, got %v and %v", pingErr, readyErr)
+	}
+	if !errors.Is(canceledErr, vectordata.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable with a canceled context, got %v", canceledErr)
+	}
+}
+
+func TestIntegrationInt8Vectors(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceInnerProduct, ElementType: vectordata.ElementInt8}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	quantized := []int8{-128, 0, 127}
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", VectorInt8: quantized},
+		{ID: "b", VectorInt8: []int8{127, 0, -128}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	got, getErr := collection.Get(ctx, "a")
+	results, searchErr := collection.SearchByVector(ctx, []float32{-1, 0, 1}, 1, vectordata.SearchOptions{
+		Projection: &vectordata.Projection{IncludeVector: true},
+	})
+	info, describeErr := store.DescribeCollection(ctx, "docs")
+
+	// Assert
+	if getErr != nil || len(got.VectorInt8) != 3 || got.VectorInt8[0] != -128 || got.VectorInt8[2] != 127 {
+		t.Fatalf("expected exact VectorInt8, got %v (%v)", got.VectorInt8, getErr)
+	}
+	if searchErr != nil || len(results) != 1 || results[0].Record.ID != "a" || len(results[0].Record.VectorInt8) != 3 {
+		t.Fatalf("expected the closest int8 record with its vector, got %#v (%v)", results, searchErr)
+	}
+	if describeErr != nil || info.ElementType != vectordata.ElementInt8 {
+		t.Fatalf("expected catalog to record int8, got %+v (%v)", info, describeErr)
+	}
+}
+
+func TestIntegrationFastVectors(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 4, Metric: vectordata.DistanceCosine, FastDimension: 2}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	// Both records share the reduced vector, so only the full vector tells
+	// them apart.
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0, 1, 0}},
+		{ID: "b", Vector: []float32{1, 0, 0, 1}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	// Act
+	results, searchErr := collection.SearchByVector(ctx, []float32{1, 0, 0, 1}, 1, vectordata.SearchOptions{})
+	_, mismatchErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 4, Metric: vectordata.DistanceCosine, FastDimension: 3})
+	info, describeErr := store.DescribeCollection(ctx, "docs")
+
+	// Assert
+	if searchErr != nil || len(results) != 1 || results[0].Record.ID != "b" || math.Abs(results[0].Distance) > 1e-6 {
+		t.Fatalf("expected the exact rescored match, got %#v (%v)", results, searchErr)
+	}
+	if !errors.Is(mismatchErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a different FastDimension, got %v", mismatchErr)
+	}
+	if describeErr != nil || info.FastDimension != 2 {
+		t.Fatalf("expected catalog to record FastDimension, got %+v (%v)", info, describeErr)
+	}
+}
+
+func TestIntegrationGetManyListAndDeleteByFilter(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	pg := collection.(*PostgresCollection)
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "x"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"kind": "y"}},
+		{ID: "c", Vector: []float32{1, 1}, Metadata: map[string]any{"kind": "x"}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	many, manyErr := pg.GetMany(ctx, []string{"c", "missing", "a"})
+	page, pageErr := pg.List(ctx, vectordata.ListOptions{After: "a", Limit: 1})
+	filtered, filteredErr := pg.List(ctx, vectordata.ListOptions{Filter: vectordata.Eq(vectordata.Metadata("kind"), "x"), Limit: 10})
+	deleted, deleteErr := pg.DeleteByFilter(ctx, vectordata.Eq(vectordata.Metadata("kind"), "x"))
+	remaining, countErr := collection.Count(ctx, nil)
+
+	// Assert
+	if manyErr != nil || len(many) != 2 || many[0].ID != "c" || many[1].ID != "a" || len(many[0].Vector) != 2 {
+		t.Fatalf("expected c and a with vectors, got %#v (%v)", many, manyErr)
+	}
+	if pageErr != nil || len(page) != 1 || page[0].ID != "b" {
+		t.Fatalf("expected the page after a, got %#v (%v)", page, pageErr)
+	}
+	if filteredErr != nil || len(filtered) != 2 || filtered[0].ID != "a" || filtered[1].ID != "c" {
+		t.Fatalf("expected the filtered records in ID order, got %#v (%v)", filtered, filteredErr)
+	}
+	if deleteErr != nil || deleted != 2 || countErr != nil || remaining != 1 {
+		t.Fatalf("expected 2 deleted and 1 remaining, got %d, %d (%v, %v)", deleted, remaining, deleteErr, countErr)
+	}
+}
+
+func TestIntegrationDropCollection(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if _, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine}); err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+
+	// Act
+	dropErr := store.DropCollection(ctx, "docs")
+	_, describeErr := store.DescribeCollection(ctx, "docs")
+	againErr := store.DropCollection(ctx, "docs")
+	_, recreateErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceL2})
+
+	// Assert
+	if dropErr != nil {
+		t.Fatalf("DropCollection: %v", dropErr)
+	}
+	if !errors.Is(describeErr, vectordata.ErrNotFound) || !errors.Is(againErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after dropping, got %v and %v", describeErr, againErr)
+	}
+	if recreateErr != nil {
+		t.Fatalf("expected the name to be reusable with another schema, got %v", recreateErr)
+	}
+}
+
+func TestIntegrationPlannedDDLMatchesEnsure(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 3, Metric: vectordata.DistanceCosine}
+	indexes := vectordata.IndexOptions{
+		Vector:   &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW},
+		Metadata: &vectordata.MetadataIndexOptions{Keys: []vectordata.MetadataKeyIndex{{Path: []string{"published"}, Type: vectordata.MetadataKeyTimestamp}}},
+	}
+	collectionPlan, err := store.PlanCollection(spec)
+	if err != nil {
+		t.Fatalf("PlanCollection: %v", err)
+	}
+	indexPlan, err := store.PlanIndexes(spec, indexes)
+	if err != nil {
+		t.Fatalf("PlanIndexes: %v", err)
+	}
+
+	// Act
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	for _, statement := range append(collectionPlan, indexPlan...) {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			_ = tx.Rollback(ctx)
+			t.Fatalf("planned statement failed: %v\n%s", err, statement)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	collection, ensureErr := store.EnsureCollection(ctx, spec)
+
+	// Assert
+	if ensureErr != nil {
+		t.Fatalf("expected strict EnsureCollection to accept the planned schema, got %v", ensureErr)
+	}
+	if err := collection.EnsureIndexes(ctx, indexes); err != nil {
+		t.Fatalf("EnsureIndexes after the plan: %v", err)
+	}
+	info, err := store.DescribeCollection(ctx, "docs")
+	if err != nil || info.Dimension != 3 || info.Metric != vectordata.DistanceCosine {
+		t.Fatalf("unexpected catalog entry %+v (%v)", info, err)
+	}
+}
+
+func TestIntegrationCollectionStats(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}, {ID: "b", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodHNSW}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	// Act
+	stats, err := store.CollectionStats(ctx, "docs")
+	_, missingErr := store.CollectionStats(ctx, "missing")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CollectionStats: %v", err)
+	}
+	if stats.Dimension != 2 || stats.TotalBytes <= 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	var hnsw *IndexInfo
+	for i := range stats.Indexes {
+		if stats.Indexes[i].Name == "idx_docs_vector_hnsw" {
+			hnsw = &stats.Indexes[i]
+		}
+	}
+	if hnsw == nil || !hnsw.Valid || !strings.Contains(hnsw.Definition, "USING hnsw") {
+		t.Fatalf("expected the HNSW index in %+v", stats.Indexes)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestIntegrationTimeDecay(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "news", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "stale", Vector: []float32{1, 0}, Metadata: map[string]any{"published_at": "2025-10-01T00:00:00Z"}},
+		{ID: "fresh", Vector: []float32{1, 0.2}, Metadata: map[string]any{"published_at": "2026-09-30T00:00:00Z"}},
+		{ID: "undated", Vector: []float32{1, 0.1}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	decay := &vectordata.TimeDecay{Field: vectordata.Metadata("published_at"), HalfLife: 7 * 24 * time.Hour, Weight: 0.2, Now: now}
+
+	// Act
+	plain, plainErr := collection.SearchByVector(ctx, []float32{1, 0}, 3, vectordata.SearchOptions{})
+	boosted, boostedErr := collection.SearchByVector(ctx, []float32{1, 0}, 3, vectordata.SearchOptions{TimeDecay: decay})
+
+	// Assert
+	if plainErr != nil || boostedErr != nil {
+		t.Fatalf("SearchByVector: %v, %v", plainErr, boostedErr)
+	}
+	if plain[0].Record.ID != "stale" || boosted[0].Record.ID != "fresh" {
+		t.Fatalf("expected stale then fresh first, got %s then %s", plain[0].Record.ID, boosted[0].Record.ID)
+	}
+	want := vectordata.ScoreFromDistance(vectordata.DistanceCosine, boosted[0].Distance) + 0.2*math.Pow(0.5, 1.0/7)
+	if math.Abs(boosted[0].Score-want) > 1e-6 {
+		t.Fatalf("expected score %v, got %v", want, boosted[0].Score)
+	}
+}
+
+func TestIntegrationMetadataBoosts(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "blog", Vector: []float32{1, 0}, Metadata: map[string]any{"source": "blog"}},
+		{ID: "official", Vector: []float32{1, 0.3}, Metadata: map[string]any{"source": "official"}},
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	boosts := []vectordata.Boost{{When: vectordata.Eq(vectordata.Metadata("source"), "official"), Multiply: 1.2}}
+
+	// Act
+	plain, plainErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{})
+	boosted, boostedErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{Boosts: boosts})
+
+	// Assert
+	if plainErr != nil || boostedErr != nil {
+		t.Fatalf("SearchByVector: %v, %v", plainErr, boostedErr)
+	}
+	if plain[0].Record.ID != "blog" || boosted[0].Record.ID != "official" {
+		t.Fatalf("expected blog then official first, got %s then %s", plain[0].Record.ID, boosted[0].Record.ID)
+	}
+	want, _ := vectordata.BoostedScore(vectordata.DistanceCosine, boosted[0], vectordata.SearchOptions{Boosts: boosts}, time.Time{})
+	if math.Abs(boosted[0].Score-want) > 1e-6 {
+		t.Fatalf("expected score %v, got %v", want, boosted[0].Score)
+	}
+}
+
+func TestIntegrationTextSearchHighlight(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Text: &vectordata.TextIndexOptions{}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	content := "Cats sleep most of the day. Postgres stores vectors next to relational data."
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Content: &content}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	pgCollection := collection.(*PostgresCollection)
+	highlight := &vectordata.HighlightOptions{MaxWords: 6}
+
+	// Act
+	textResults, textErr := pgCollection.SearchByText(ctx, "vectors", 1, vectordata.TextSearchOptions{Highlight: highlight})
+	hybridResults, hybridErr := pgCollection.HybridSearch(ctx, []float32{1, 0}, "vectors", 1, vectordata.HybridSearchOptions{Highlight: highlight})
+
+	// Assert
+	if textErr != nil || hybridErr != nil {
+		t.Fatalf("search: %v %v", textErr, hybridErr)
+	}
+	for _, results := range [][]vectordata.SearchResult{textResults, hybridResults} {
+		if len(results) != 1 || len(results[0].Snippets) != 1 || !strings.Contains(results[0].Snippets[0], "<b>vectors</b>") {
+			t.Fatalf("expected a highlighted snippet, got %#v", results)
+		}
+	}
+}
+
+func TestIntegrationEstimateCount(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	pgCollection := collection.(*PostgresCollection)
+	small, smallErr := pgCollection.EstimateCount(ctx, nil)
+	if _, err := pool.Exec(ctx, fmt.Sprintf(
+		`INSERT INTO %s (id, vector, metadata) SELECT i::text, '[1,0]', jsonb_build_object('even', i %% 2 = 0) FROM generate_series(1, 50000) i`,
+		pgCollection.tableName(),
+	)); err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "ANALYZE "+pgCollection.tableName()); err != nil {
+		t.Fatalf("ANALYZE: %v", err)
+	}
+
+	// Act
+	total, totalErr := pgCollection.EstimateCount(ctx, nil)
+	even, evenErr := pgCollection.EstimateCount(ctx, vectordata.Eq(vectordata.Metadata("even"), true))
+
+	// Assert
+	if smallErr != nil || small != 0 {
+		t.Fatalf("expected an exact 0 before analyzing, got %d (%v)", small, smallErr)
+	}
+	if totalErr != nil || math.Abs(float64(total)-50000) > 2500 {
+		t.Fatalf("expected about 50000 records, got %d (%v)", total, totalErr)
+	}
+	if evenErr != nil || math.Abs(float64(even)-25000) > 5000 {
+		t.Fatalf("expected about 25000 even records, got %d (%v)", even, evenErr)
+	}
+}
+
+func TestIntegrationSample(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	pgCollection := collection.(*PostgresCollection)
+	if _, err := pool.Exec(ctx, fmt.Sprintf(
+		`INSERT INTO %s (id, vector, metadata) SELECT i::text, '[1,0]', jsonb_build_object('rare', i %% 1000 = 0) FROM generate_series(1, 20000) i`,
+		pgCollection.tableName(),
+	)); err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "ANALYZE "+pgCollection.tableName()); err != nil {
+		t.Fatalf("ANALYZE: %v", err)
+	}
+	seed := int64(42)
+
+	// Act
+	first, firstErr := pgCollection.Sample(ctx, 10, vectordata.SampleOptions{Seed: &seed})
+	second, secondErr := pgCollection.Sample(ctx, 10, vectordata.SampleOptions{Seed: &seed})
+	rare, rareErr := pgCollection.Sample(ctx, 50, vectordata.SampleOptions{Filter: vectordata.Eq(vectordata.Metadata("rare"), true)})
+
+	// Assert
+	if firstErr != nil || secondErr != nil || rareErr != nil {
+		t.Fatalf("Sample: %v, %v, %v", firstErr, secondErr, rareErr)
+	}
+	if len(first) != 10 || !reflect.DeepEqual(first, second) || len(first[0].Vector) != 2 {
+		t.Fatalf("expected the same 10 records with vectors, got %v and %v", first, second)
+	}
+	if len(rare) != 20 {
+		t.Fatalf("expected every one of the 20 rare records, got %d", len(rare))
+	}
+}
+
+func TestIntegrationNeighborsFor(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 0}, Metadata: map[string]any{"lang": "en"}},
+		{ID: "b", Vector: []float32{1, 0}, Metadata: map[string]any{"lang": "en"}},
+		{ID: "c", Vector: []float32{0.5, 0}, Metadata: map[string]any{"lang": "de"}},
+		{ID: "d", Vector: []float32{5, 0}, Metadata: map[string]any{"lang": "en"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	neighbors, err := vectordata.NeighborsFor(ctx, collection, []string{"a", "d", "missing"}, 2, vectordata.SearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("lang"), "en"),
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("NeighborsFor: %v", err)
+	}
+	if len(neighbors) != 2 {
+		t.Fatalf("expected neighbors for a and d only, got %v", neighbors)
+	}
+	if ids := resultIDs(neighbors["a"]); !reflect.DeepEqual(ids, []string{"b", "d"}) {
+		t.Fatalf("expected b and d next to a, got %v", ids)
+	}
+	if ids := resultIDs(neighbors["d"]); !reflect.DeepEqual(ids, []string{"b", "a"}) {
+		t.Fatalf("expected b and a next to d, got %v", ids)
+	}
+	if neighbors["a"][0].Distance != 1 {
+		t.Fatalf("expected distance 1 from a to b, got %v", neighbors["a"][0].Distance)
+	}
+}
+
+func TestIntegrationFindDuplicates(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{1, 0.001}},
+		{ID: "c", Vector: []float32{0, 1}},
+		{ID: "d", Vector: []float32{5, 5}},
+		{ID: "e", Vector: []float32{5, 5}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	var groups []vectordata.DuplicateGroup
+
+	// Act
+	err = vectordata.FindDuplicates(ctx, collection, 0.01, vectordata.DuplicateOptions{
+		BatchSize: 2,
+		OnGroup: func(group vectordata.DuplicateGroup) error {
+			groups = append(groups, group)
+			return nil
+		},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+	if len(groups) != 2 || !reflect.DeepEqual(groups[0].IDs, []string{"a", "b"}) || !reflect.DeepEqual(groups[1].IDs, []string{"d", "e"}) {
+		t.Fatalf("expected groups a,b and d,e, got %+v", groups)
+	}
+	if groups[1].Distance != 0 {
+		t.Fatalf("expected identical vectors at distance 0, got %v", groups[1].Distance)
+	}
+}
+
+func TestIntegrationCentroids(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"topic": "go", "lang": "en"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"topic": "go", "lang": "en"}},
+		{ID: "c", Vector: []float32{0, 3}, Metadata: map[string]any{"topic": 7, "lang": "en"}},
+		{ID: "d", Vector: []float32{1, 1}, Metadata: map[string]any{"lang": "en"}},
+		{ID: "e", Vector: []float32{1, 0}, Metadata: map[string]any{"topic": 7, "lang": "de"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	centroids, err := vectordata.Centroids(ctx, collection, vectordata.Metadata("topic"), vectordata.Eq(vectordata.Metadata("lang"), "en"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Centroids: %v", err)
+	}
+	if len(centroids) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", centroids)
+	}
+	if centroids[0].Group != "go" || centroids[0].Count != 2 || math.Abs(float64(centroids[0].Vector[0])-math.Sqrt2/2) > 1e-6 {
+		t.Fatalf("expected the go group first with a unit-length mean, got %+v", centroids[0])
+	}
+	if centroids[1].Group != float64(7) || centroids[1].Count != 1 || centroids[1].Vector[1] != 1 {
+		t.Fatalf("expected group 7 with a unit vector, got %+v", centroids[1])
+	}
+}
+
+func TestIntegrationUpsertWithConflictStrategies(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"lang": "en", "rank": 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	skipErr := vectordata.UpsertWithOptions(ctx, collection, []vectordata.Record{
+		{ID: "a", Vector: []float32{9, 9}},
+		{ID: "b", Vector: []float32{0, 1}},
+	}, vectordata.UpsertOptions{OnConflict: vectordata.ConflictSkip})
+	skipped, _ := collection.Get(ctx, "a")
+	mergeErr := vectordata.UpsertWithOptions(ctx, collection, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 2}, Metadata: map[string]any{"rank": 2}},
+	}, vectordata.UpsertOptions{OnConflict: vectordata.ConflictMergeMetadata})
+	merged, _ := collection.Get(ctx, "a")
+	count, _ := collection.Count(ctx, nil)
+
+	// Assert
+	if skipErr != nil || mergeErr != nil {
+		t.Fatalf("UpsertWithOptions: %v, %v", skipErr, mergeErr)
+	}
+	if skipped.Vector[0] != 1 || count != 2 {
+		t.Fatalf("expected a kept and b inserted, got %v and count %d", skipped, count)
+	}
+	if merged.Vector[1] != 2 || merged.Metadata["lang"] != "en" || merged.Metadata["rank"] != float64(2) {
+		t.Fatalf("expected a new vector and merged metadata, got %+v", merged)
+	}
+}
+
+func TestIntegrationPatchMetadata(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"lang": "en", "stats": map[string]any{"views": 1, "likes": 2}, "tags": "x"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = vectordata.PatchMetadata(ctx, collection, "a", map[string]any{fmt.Sprintf("k%d", i): i})
+		}()
+	}
+	wg.Wait()
+	patchErr := vectordata.PatchMetadata(ctx, collection, "a", map[string]any{
+		"lang":  nil,
+		"stats": map[string]any{"views": 5, "likes": nil},
+		"tags":  map[string]any{"new": true},
+	})
+	patched, _ := collection.Get(ctx, "a")
+	missingErr := vectordata.PatchMetadata(ctx, collection, "missing", map[string]any{"x": 1})
+
+	// Assert
+	if err := errors.Join(append(errs, patchErr)...); err != nil {
+		t.Fatalf("PatchMetadata: %v", err)
+	}
+	for i := range errs {
+		if patched.Metadata[fmt.Sprintf("k%d", i)] != float64(i) {
+			t.Fatalf("expected every concurrent patch to survive, got %+v", patched.Metadata)
+		}
+	}
+	if _, ok := patched.Metadata["lang"]; ok {
+		t.Fatalf("expected lang to be removed, got %+v", patched.Metadata)
+	}
+	if !reflect.DeepEqual(patched.Metadata["stats"], map[string]any{"views": float64(5)}) {
+		t.Fatalf("expected a nested patch, got %+v", patched.Metadata["stats"])
+	}
+	if !reflect.DeepEqual(patched.Metadata["tags"], map[string]any{"new": true}) {
+		t.Fatalf("expected a non-object member to be replaced, got %+v", patched.Metadata["tags"])
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestIntegrationUpdateMetadataArray(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"tags": []any{"draft", "go"}, "owner": "ana"}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = vectordata.AppendToArray(ctx, collection, "a", []string{"tags"}, fmt.Sprintf("t%d", i), "go")
+		}()
+	}
+	wg.Wait()
+	removeErr := vectordata.RemoveFromArray(ctx, collection, "a", []string{"tags"}, "draft")
+	nestedErr := vectordata.AppendToArray(ctx, collection, "a", []string{"review", "by"}, map[string]any{"name": "ana"})
+	updated, _ := collection.Get(ctx, "a")
+	scalarErr := vectordata.AppendToArray(ctx, collection, "a", []string{"owner"}, "x")
+	missingErr := vectordata.AppendToArray(ctx, collection, "missing", []string{"tags"}, "x")
+
+	// Assert
+	if err := errors.Join(append(errs, removeErr, nestedErr)...); err != nil {
+		t.Fatalf("UpdateMetadataArray: %v", err)
+	}
+	tags, _ := updated.Metadata["tags"].([]any)
+	if len(tags) != 11 || tags[0] != "go" {
+		t.Fatalf("expected go kept first and every concurrent tag appended once, got %v", tags)
+	}
+	if !reflect.DeepEqual(updated.Metadata["review"], map[string]any{"by": []any{map[string]any{"name": "ana"}}}) {
+		t.Fatalf("expected a nested array to be created, got %v", updated.Metadata["review"])
+	}
+	if !errors.Is(scalarErr, vectordata.ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", scalarErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestIntegrationDeleteWithResults(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	results, err := vectordata.DeleteWithResults(ctx, collection, []string{"b", "missing", "b"})
+	count, _ := collection.Count(ctx, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("DeleteWithResults: %v", err)
+	}
+	expected := []vectordata.DeleteResult{
+		{ID: "b", Status: vectordata.DeleteStatusDeleted},
+		{ID: "missing", Status: vectordata.DeleteStatusNotFound},
+	}
+	if !reflect.DeepEqual(results, expected) || count != 1 {
+		t.Fatalf("unexpected results %v with %d records left", results, count)
+	}
+}
+
+func TestIntegrationMaxRecordsEviction(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "cache", Dimension: 2, MaxRecords: 2, EvictBy: "used_at"})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+
+	// Act
+	insertErr := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"used_at": "2026-01-03T00:00:00Z"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"used_at": "2026-01-01T00:00:00Z"}},
+		{ID: "c", Vector: []float32{1, 1}, Metadata: map[string]any{"used_at": "2026-01-02T00:00:00Z"}},
+	})
+	_, evictedErr := collection.Get(ctx, "b")
+	unbounded := store.Collection("cache", 2, vectordata.DistanceCosine)
+	upsertErr := unbounded.Upsert(ctx, []vectordata.Record{{ID: "d", Vector: []float32{1, 0}}})
+	evicted, evictErr := vectordata.Evict(ctx, collection)
+	count, _ := collection.Count(ctx, nil)
+
+	// Assert
+	if insertErr != nil || upsertErr != nil || evictErr != nil {
+		t.Fatalf("write: %v, %v, %v", insertErr, upsertErr, evictErr)
+	}
+	if !errors.Is(evictedErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected the oldest record evicted on write, got %v", evictedErr)
+	}
+	if evicted != 1 || count != 2 {
+		t.Fatalf("expected Evict to drop d, which has no timestamp, got %d evicted and %d left", evicted, count)
+	}
+}
+
+func TestIntegrationArchiveAndRestore(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"year": 2024}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"year": 2026}},
+	}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	old := vectordata.Lt(vectordata.Metadata("year"), 2025)
+
+	// Act
+	restoredBefore, restoreBeforeErr := vectordata.Restore(ctx, collection, old)
+	archived, archiveErr := vectordata.Archive(ctx, collection, old)
+	count, _ := collection.Count(ctx, nil)
+	results, _ := collection.SearchByVector(ctx, []float32{1, 0}, 10, vectordata.SearchOptions{})
+	_, hotErr := collection.Get(ctx, "a")
+	cold, coldErr := vectordata.GetWithOptions(ctx, collection, "a", vectordata.GetOptions{IncludeArchived: true})
+	restored, restoreErr := vectordata.Restore(ctx, collection, old)
+	back, backErr := collection.Get(ctx, "a")
+
+	// Assert
+	if restoreBeforeErr != nil || restoredBefore != 0 {
+		t.Fatalf("expected restoring without an archive to do nothing, got %d, %v", restoredBefore, restoreBeforeErr)
+	}
+	if archiveErr != nil || archived != 1 || count != 1 || len(results) != 1 {
+		t.Fatalf("expected a archived out of counts and searches, got %d (%v), count %d, %d results", archived, archiveErr, count, len(results))
+	}
+	if !errors.Is(hotErr, vectordata.ErrNotFound) || coldErr != nil || cold.Vector[0] != 1 || cold.Metadata["year"] != float64(2024) {
+		t.Fatalf("expected a only among archived records, got %v, %+v, %v", hotErr, cold, coldErr)
+	}
+	if restoreErr != nil || restored != 1 || backErr != nil || back.ID != "a" {
+		t.Fatalf("expected a restored, got %d (%v), %+v (%v)", restored, restoreErr, back, backErr)
+	}
+}
+
+func TestIntegrationOutbox(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	opts := newTestStore(t, pool).opts
+	opts.Outbox = &OutboxOptions{}
+	store, err := NewVectorStore(pool, opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"v": 1}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"v": 2}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if _, err := collection.Delete(ctx, []string{"a"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Act
+	failed, failErr := store.PollOutbox(ctx, 10, func(context.Context, []OutboxEvent) error { return errors.New("broker down") })
+	var events []OutboxEvent
+	delivered, pollErr := store.PollOutbox(ctx, 10, func(_ context.Context, batch []OutboxEvent) error {
+		events = append(events, batch...)
+		return nil
+	})
+	again, againErr := store.PollOutbox(ctx, 10, func(context.Context, []OutboxEvent) error { return nil })
+
+	// Assert
+	if failErr == nil || failed != 0 {
+		t.Fatalf("expected the failed delivery to report its error, got %d, %v", failed, failErr)
+	}
+	if pollErr != nil || delivered != 3 || len(events) != 3 {
+		t.Fatalf("expected the three events to be redelivered, got %d, %+v, %v", delivered, events, pollErr)
+	}
+	var operations []OutboxOperation
+	for _, event := range events {
+		if event.Collection != "docs" || event.RecordID != "a" {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		operations = append(operations, event.Operation)
+	}
+	if !reflect.DeepEqual(operations, []OutboxOperation{OutboxInsert, OutboxUpdate, OutboxDelete}) || events[1].Metadata["v"] != float64(2) {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if againErr != nil || again != 0 {
+		t.Fatalf("expected delivered events to be marked, got %d, %v", again, againErr)
+	}
+}
+
+func TestIntegrationReindexSwapsIndex(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceCosine})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}, {ID: "b", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 8}}}); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	// Act
+	reindexErr := vectordata.Reindex(ctx, collection, vectordata.VectorIndexOptions{HNSW: vectordata.HNSWOptions{M: 24}})
+	var definition string
+	defErr := pool.QueryRow(ctx, `SELECT pg_get_indexdef(to_regclass($1))`, store.opts.Schema+".idx_docs_vector_hnsw").Scan(&definition)
+	var leftovers int
+	leftErr := pool.QueryRow(ctx, `SELECT count(*) FROM pg_indexes WHERE schemaname = $1 AND indexname LIKE '%_reindex'`, store.opts.Schema).Scan(&leftovers)
+	results, searchErr := collection.SearchByVector(ctx, []float32{0, 1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if reindexErr != nil || defErr != nil || leftErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v", reindexErr, defErr, leftErr, searchErr)
+	}
+	if !strings.Contains(definition, "m='24'") {
+		t.Fatalf("expected the rebuilt index under the old name, got %q", definition)
+	}
+	if leftovers != 0 || len(results) != 1 || results[0].Record.ID != "b" {
+		t.Fatalf("expected no temporary index and a working search, got %d, %+v", leftovers, results)
+	}
+}
+
+func TestIntegrationEnsureCollectionPlan(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Metric: vectordata.DistanceL2}
+
+	// Act
+	before, beforeErr := vectordata.EnsureCollectionPlan(ctx, store, spec)
+	_, tableErr := pool.Exec(ctx, fmt.Sprintf(`SELECT 1 FROM %s LIMIT 1`, qualifiedTable(store.opts.Schema, "docs")))
+	_, ensureErr := store.EnsureCollection(ctx, spec)
+	after, afterErr := vectordata.EnsureCollectionPlan(ctx, store, spec)
+	_, mismatchErr := vectordata.EnsureCollectionPlan(ctx, store, vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+
+	// Assert
+	if beforeErr != nil || ensureErr != nil || afterErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", beforeErr, ensureErr, afterErr)
+	}
+	kinds := map[vectordata.PlanStepKind]bool{}
+	for _, step := range before.Steps {
+		kinds[step.Kind] = true
+	}
+	if !kinds[vectordata.PlanCreateSchema] || !kinds[vectordata.PlanCreateTable] || !kinds[vectordata.PlanRecordCatalog] {
+		t.Fatalf("expected schema, table and catalog steps, got %+v", before.Steps)
+	}
+	if tableErr == nil {
+		t.Fatal("expected the plan to leave the table uncreated")
+	}
+	if !after.NoOp() {
+		t.Fatalf("expected no steps for an ensured collection, got %+v", after.Steps)
+	}
+	if !errors.Is(mismatchErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", mismatchErr)
+	}
+}
+
+func TestIntegrationEnsureRecreate(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	store.opts.AllowRecreate = true
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	if err := collection.Insert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	same := vectordata.CollectionSpec{Name: "docs", Dimension: 2, Mode: vectordata.EnsureRecreate}
+	changed := vectordata.CollectionSpec{Name: "docs", Dimension: 3, Mode: vectordata.EnsureRecreate}
+
+	// Act
+	kept, keptErr := store.EnsureCollection(ctx, same)
+	keptCount, keptCountErr := kept.Count(ctx, nil)
+	plan, planErr := vectordata.EnsureCollectionPlan(ctx, store, changed)
+	recreated, recreateErr := store.EnsureCollection(ctx, changed)
+	count, countErr := recreated.Count(ctx, nil)
+	_, strictErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 3})
+
+	// Assert
+	if keptErr != nil || keptCountErr != nil || planErr != nil || recreateErr != nil || countErr != nil || strictErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v %v %v", keptErr, keptCountErr, planErr, recreateErr, countErr, strictErr)
+	}
+	if keptCount != 1 {
+		t.Fatalf("expected a matching collection to keep its record, got %d", keptCount)
+	}
+	if len(plan.Steps) == 0 || plan.Steps[0].Kind != vectordata.PlanDropTable || plan.Steps[0].Object != "docs" {
+		t.Fatalf("expected the plan to start with dropping docs, got %+v", plan.Steps)
+	}
+	if count != 0 {
+		t.Fatalf("expected an empty recreated collection, got %d records", count)
+	}
+}
+
+func TestIntegrationRunInTx(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	chunks, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "chunks", Dimension: 2})
+	if err != nil {
+		t.Fatalf("EnsureCollection chunks: %v", err)
+	}
+	summaries, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "summaries", Dimension: 2})
+	if err != nil {
+		t.Fatalf("EnsureCollection summaries: %v", err)
+	}
+	write := func(id string, fail error) error {
+		return store.RunInTx(ctx, func(ctx context.Context, tx *Tx) error {
+			txChunks, err := tx.Collection(chunks)
+			if err != nil {
+				return err
+			}
+			txSummaries, err := tx.Collection(summaries)
+			if err != nil {
+				return err
+			}
+			if err := txChunks.Insert(ctx, []vectordata.Record{{ID: id, Vector: []float32{1, 0}}}); err != nil {
+				return err
+			}
+			if err := txSummaries.Insert(ctx, []vectordata.Record{{ID: id, Vector: []float32{0, 1}}}); err != nil {
+				return err
+			}
+			return fail
+		})
+	}
+	errRollback := errors.New("rollback")
+
+	// Act
+	rolledBackErr := write("a", errRollback)
+	committedErr := write("b", nil)
+	_, missingErr := summaries.Get(ctx, "a")
+	chunkCount, chunkErr := chunks.Count(ctx, nil)
+	summaryCount, summaryErr := summaries.Count(ctx, nil)
+
+	// Assert
+	if !errors.Is(rolledBackErr, errRollback) {
+		t.Fatalf("expected the callback's error, got %v", rolledBackErr)
+	}
+	if committedErr != nil || chunkErr != nil || summaryErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", committedErr, chunkErr, summaryErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected the rolled back summary to be missing, got %v", missingErr)
+	}
+	if chunkCount != 1 || summaryCount != 1 {
+		t.Fatalf("expected one committed record per collection, got %d and %d", chunkCount, summaryCount)
+	}
+}
+
+func TestIntegrationAuditLog(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	opts := newTestStore(t, pool).opts
+	opts.Audit = &AuditOptions{}
+	store, err := NewVectorStore(pool, opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs", Dimension: 2})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	ana := WithActor(ctx, "ana")
+
+	// Act
+	insertErr := collection.Insert(ana, []vectordata.Record{{ID: "a", Vector: []float32{1, 0}}})
+	upsertErr := collection.Upsert(WithActor(ctx, "bob"), []vectordata.Record{{ID: "a", Vector: []float32{0, 1}}})
+	_, deleteErr := collection.Delete(ctx, []string{"a"})
+	entries, logErr := store.AuditLog(ctx, AuditQuery{Collection: "docs"})
+	byAna, anaErr := store.AuditLog(ctx, AuditQuery{Actor: "ana"})
+
+	// Assert
+	if insertErr != nil || upsertErr != nil || deleteErr != nil || logErr != nil || anaErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v %v", insertErr, upsertErr, deleteErr, logErr, anaErr)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected three entries, got %+v", entries)
+	}
+	want := []struct {
+		operation AuditOperation
+		actor     string
+	}{{AuditInsert, "ana"}, {AuditUpdate, "bob"}, {AuditDelete, ""}}
+	for i, entry := range entries {
+		if entry.Operation != want[i].operation || entry.Actor != want[i].actor || entry.RecordID != "a" || entry.At.IsZero() {
+			t.Fatalf("unexpected entry %d: %+v", i, entry)
+		}
+	}
+	if len(byAna) != 1 || byAna[0].Seq != entries[0].Seq {
+		t.Fatalf("expected ana's insert only, got %+v", byAna)
+	}
+}
+
+func TestIntegrationSearchCursorPages(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      "cursor_docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceL2,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	// c, a, e and b are tied at distance 1, so pages must break the tie by ID.
+	err = collection.Upsert(ctx, []vectordata.Record{
+		{ID: "c", Vector: []float32{1, 0}},
+		{ID: "a", Vector: []float32{0, 1}},
+		{ID: "e", Vector: []float32{-1, 0}},
+		{ID: "b", Vector: []float32{0, -1}},
+		{ID: "d", Vector: []float32{0, 0}},
+		{ID: "f", Vector: []float32{3, 0}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	query := []float32{0, 0}
+	opts := vectordata.SearchOptions{}
+
+	// Act
+	var ids []string
+	for range 3 {
+		page, err := collection.SearchByVector(ctx, query, 2, opts)
+		if err != nil {
+			t.Fatalf("SearchByVector: %v", err)
+		}
+		for _, result := range page {
+			ids = append(ids, result.Record.ID)
+		}
+		opts.Cursor = vectordata.NextCursor(query, opts, page)
+	}
+	last, lastErr := collection.SearchByVector(ctx, query, 2, opts)
+
+	// Assert
+	if strings.Join(ids, ",") != "d,a,b,c,e,f" {
+		t.Fatalf("unexpected pages: %v", ids)
+	}
+	if lastErr != nil || len(last) != 0 {
+		t.Fatalf("expected an empty page after the last result, got %v (%v)", last, lastErr)
+	}
+}
+
+func TestIntegrationSearchOrderBy(t *testing.T) {
+	// Arrange
+	pool := integrationPool(t)
+	store := newTestStore(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	collection, err := store.EnsureCollection(ctx, vectordata.CollectionSpec{
+		Name:      "order_docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceL2,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	err = collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"published_at": "2024-01-01T00:00:00Z"}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"published_at": "2024-06-01T00:00:00Z"}},
+		{ID: "c", Vector: []float32{-1, 0}},
+		{ID: "d", Vector: []float32{0, 0}, Metadata: map[string]any{"published_at": "2020-01-01T00:00:00Z"}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	projection := vectordata.Projection{}
+
+	// Act
+	results, err := collection.SearchByVector(ctx, []float32{0, 0}, 4, vectordata.SearchOptions{
+		Projection: &projection,
+		OrderBy:    []vectordata.OrderSpec{vectordata.Desc(vectordata.Metadata("published_at"))},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if got := strings.Join(resultIDs(results), ","); got != "d,b,a,c" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+func resultIDs(results []vectordata.SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Record.ID)
+	}
+	return ids
+}
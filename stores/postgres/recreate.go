@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+// recreateOnMismatch ensures a collection in strict mode and, when its
+// existing table or catalog entry does not match spec, drops it and creates
+// it again. The strict attempt runs in a savepoint, so its failure leaves the
+// transaction usable. It reports whether the collection was recreated.
+func (s *PostgresVectorStore) recreateOnMismatch(ctx context.Context, tx pgx.Tx, spec vectordata.CollectionSpec, partition *partitioning) (bool, error) {
+	err := pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+		return s.ensureCollectionTable(ctx, savepoint, spec, partition, vectordata.EnsureStrict)
+	})
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		return false, err
+	}
+	if s.opts.Logger != nil {
+		s.opts.Logger.LogAttrs(ctx, slog.LevelWarn, "vectorstore recreating collection",
+			slog.String("collection", spec.Name), slog.Any("reason", err))
+	}
+	if _, err := s.dropCollectionObjects(ctx, tx, spec.Name); err != nil {
+		return false, err
+	}
+	if err := s.ensureCollectionTable(ctx, tx, spec, partition, vectordata.EnsureStrict); err != nil {
+		return false, err
+	}
+	return true, nil
+}
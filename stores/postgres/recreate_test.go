@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestNormalizeCollectionSpec_RecreateRequiresOptIn(t *testing.T) {
+	// Arrange
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 3, Mode: vectordata.EnsureRecreate}
+	guarded := &PostgresVectorStore{opts: DefaultStoreOptions()}
+	allowed := DefaultStoreOptions()
+	allowed.AllowRecreate = true
+	optedIn := &PostgresVectorStore{opts: allowed}
+
+	// Act
+	_, _, guardedErr := guarded.normalizeCollectionSpec(spec)
+	_, mode, err := optedIn.normalizeCollectionSpec(spec)
+
+	// Assert
+	if !errors.Is(guardedErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch without AllowRecreate, got %v", guardedErr)
+	}
+	if err != nil {
+		t.Fatalf("normalize with AllowRecreate: %v", err)
+	}
+	if mode != vectordata.EnsureRecreate {
+		t.Fatalf("expected mode %q, got %q", vectordata.EnsureRecreate, mode)
+	}
+}
+
+func TestDropCollectionObjects_PlansDrops(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: StoreOptions{Schema: "app"}}
+	plan := &planExecutor{}
+
+	// Act
+	found, err := store.dropCollectionObjects(t.Context(), plan, "docs")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if found {
+		t.Fatal("expected nothing to be found in an empty schema")
+	}
+	if len(plan.statements) != 1 || plan.statements[0] != `DROP TABLE IF EXISTS "app"."docs_archive"` {
+		t.Fatalf("unexpected statements %q", plan.statements)
+	}
+}
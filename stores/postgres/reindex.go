@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ vectordata.Reindexer = (*PostgresCollection)(nil)
+
+// reindexSuffix names the index a Reindex builds before the swap.
+const reindexSuffix = "_reindex"
+
+// Reindex builds the index described by opts with CREATE INDEX CONCURRENTLY
+// under a temporary name, checks that Postgres marks it valid, and then drops
+// the old index and renames the new one in one transaction, so searches
+// always have an index. opts.Concurrent is implied. The swap briefly takes
+// an exclusive lock on the table. Partitioned collections are not supported,
+// because their indexes are attached per partition.
+func (c *PostgresCollection) Reindex(ctx context.Context, opts vectordata.VectorIndexOptions) error {
+	if c.partition != nil {
+		return fmt.Errorf("%w: Reindex of partitioned collection %q", errors.ErrUnsupported, c.name)
+	}
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Schema)
+	defer cancel()
+	indexName, definition, err := c.vectorIndexDefinition(&opts)
+	if err != nil {
+		return err
+	}
+	tempName := indexName + reindexSuffix
+
+	// A leftover from an interrupted Reindex may have other parameters.
+	if err := c.store.dropIndexConcurrently(ctx, tempName); err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+	query := fmt.Sprintf("CREATE INDEX CONCURRENTLY %s ON %s %s", quoteIdent(tempName), c.tableName(), definition)
+	if err := c.createIndexConcurrently(ctx, tempName, query); err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+
+	err = c.store.withSchemaLock(ctx, advisoryLockKey(c.store.opts.Schema, c.name), func(tx pgx.Tx) error {
+		for _, query := range buildSwapIndexQueries(c.store.opts.Schema, indexName, tempName) {
+			if err := c.store.execSchema(ctx, tx, query); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reindex: swap %q for %q: %w", tempName, indexName, err)
+	}
+	return nil
+}
+
+func buildSwapIndexQueries(schema, indexName, tempName string) []string {
+	return []string{
+		fmt.Sprintf("DROP INDEX IF EXISTS %s", qualifiedTable(schema, indexName)),
+		fmt.Sprintf("ALTER INDEX %s RENAME TO %s", qualifiedTable(schema, tempName), quoteIdent(indexName)),
+	}
+}
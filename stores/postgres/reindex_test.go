@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestBuildSwapIndexQueries(t *testing.T) {
+	// Act
+	queries := buildSwapIndexQueries("app", "idx_docs_vector_hnsw", "idx_docs_vector_hnsw_reindex")
+
+	// Assert
+	want := []string{
+		`DROP INDEX IF EXISTS "app"."idx_docs_vector_hnsw"`,
+		`ALTER INDEX "app"."idx_docs_vector_hnsw_reindex" RENAME TO "idx_docs_vector_hnsw"`,
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Fatalf("unexpected swap:\n%q", queries)
+	}
+}
+
+func TestPostgresCollection_ReindexRejectsPartitionedCollection(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	collection.partition = &partitioning{method: vectordata.PartitionList, key: "tenant_id"}
+
+	// Act
+	err := collection.Reindex(context.Background(), vectordata.VectorIndexOptions{})
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+)
+
+// defaultRetryableSQLStates are transaction rollbacks (serialization failures,
+// deadlocks), connection exceptions, and shutdowns or recovery during a
+// failover.
+var defaultRetryableSQLStates = []string{"40", "08", "57P01", "57P02", "57P03"}
+
+// RetryPolicy retries collection operations that fail with transient errors.
+// Every write is a single statement or an atomic batch, so a failed attempt
+// leaves nothing behind; an Insert whose commit succeeded but whose reply was
+// lost can still fail its retry with a duplicate key.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// (default 3).
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; each further retry
+	// doubles it up to MaxBackoff (defaults 50ms and 2s).
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RetryableSQLStates lists SQLSTATE codes or two-character classes to
+	// retry (default classes 40 and 08 plus 57P01, 57P02, 57P03). Connection
+	// errors raised before a reply from the server are always retried.
+	RetryableSQLStates []string
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.RetryableSQLStates == nil {
+		p.RetryableSQLStates = defaultRetryableSQLStates
+	}
+	return p
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("%w: retry max attempts must be >= 1", vectordata.ErrSchemaMismatch)
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("%w: retry backoff must satisfy 0 <= initial <= max", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// retryable reports whether err is transient under the policy.
+func (p RetryPolicy) retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		for _, state := range p.RetryableSQLStates {
+			if strings.HasPrefix(pgErr.Code, state) {
+				return true
+			}
+		}
+		return false
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// withRetry runs fn under the store's retry policy. It stops when the context
+// is done or its deadline would pass before the next attempt, returning the
+// last error, categorized by classifyError.
+func (s *PostgresVectorStore) withRetry(ctx context.Context, fn func() error) error {
+	return classifyError(s.retry(ctx, fn))
+}
+
+func (s *PostgresVectorStore) retry(ctx context.Context, fn func() error) error {
+	if s.opts.Retry == nil {
+		return fn()
+	}
+	policy := *s.opts.Retry
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
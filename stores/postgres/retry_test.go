@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	// Arrange
+	policy := RetryPolicy{}.withDefaults()
+	cases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"serialization failure": {&pgconn.PgError{Code: "40001"}, true},
+		"deadlock":              {&pgconn.PgError{Code: "40P01"}, true},
+		"connection failure":    {&pgconn.PgError{Code: "08006"}, true},
+		"admin shutdown":        {&pgconn.PgError{Code: "57P01"}, true},
+		"unique violation":      {&pgconn.PgError{Code: "23505"}, false},
+		"query canceled":        {&pgconn.PgError{Code: "57014"}, false},
+		"unexpected eof":        {io.ErrUnexpectedEOF, true},
+		"no rows":               {pgx.ErrNoRows, false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			retryable := policy.retryable(tc.err)
+
+			// Assert
+			if retryable != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, retryable)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	// Arrange
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond}.withDefaults()
+
+	// Act
+	delays := []time.Duration{policy.backoff(1), policy.backoff(2), policy.backoff(3), policy.backoff(10)}
+
+	// Assert
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond, 35 * time.Millisecond}
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Fatalf("unexpected delays %v", delays)
+		}
+	}
+}
+
+func TestPostgresVectorStore_WithRetry(t *testing.T) {
+	// Arrange
+	transient := &pgconn.PgError{Code: "40001"}
+	store := &PostgresVectorStore{opts: StoreOptions{Retry: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}}.withDefaults()}
+
+	// Act
+	recovered := 0
+	recoveredErr := store.withRetry(context.Background(), func() error {
+		recovered++
+		if recovered < 3 {
+			return transient
+		}
+		return nil
+	})
+	exhausted := 0
+	exhaustedErr := store.withRetry(context.Background(), func() error {
+		exhausted++
+		return transient
+	})
+	permanent := 0
+	permanentErr := store.withRetry(context.Background(), func() error {
+		permanent++
+		return &pgconn.PgError{Code: "23505"}
+	})
+
+	// Assert
+	if recoveredErr != nil || recovered != 3 {
+		t.Fatalf("expected success on the third attempt, got %v after %d", recoveredErr, recovered)
+	}
+	if !errors.Is(exhaustedErr, transient) || exhausted != 3 {
+		t.Fatalf("expected the last error after 3 attempts, got %v after %d", exhaustedErr, exhausted)
+	}
+	if permanentErr == nil || permanent != 1 {
+		t.Fatalf("expected no retry for a permanent error, got %d attempts", permanent)
+	}
+}
+
+func TestPostgresVectorStore_WithRetryRespectsDeadline(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: StoreOptions{Retry: &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Second}}.withDefaults()}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	attempts := 0
+	started := time.Now()
+	err := store.withRetry(ctx, func() error {
+		attempts++
+		return io.ErrUnexpectedEOF
+	})
+
+	// Assert
+	if !errors.Is(err, io.ErrUnexpectedEOF) || attempts != 1 {
+		t.Fatalf("expected a single attempt, got %v after %d", err, attempts)
+	}
+	if time.Since(started) > 500*time.Millisecond {
+		t.Fatal("expected no wait past the context deadline")
+	}
+}
+
+func TestStoreOptions_RetryValidation(t *testing.T) {
+	// Act
+	defaults := StoreOptions{Retry: &RetryPolicy{}}.withDefaults()
+	invalidErr := StoreOptions{Retry: &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Millisecond}}.withDefaults().validate()
+
+	// Assert
+	if defaults.Retry.MaxAttempts != defaultRetryMaxAttempts || len(defaults.Retry.RetryableSQLStates) == 0 {
+		t.Fatalf("unexpected retry defaults %+v", defaults.Retry)
+	}
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", invalidErr)
+	}
+}
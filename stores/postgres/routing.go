@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type primaryReadsContextKey struct{}
+
+// WithPrimaryReads returns a context whose reads go to the primary pool even
+// when StoreOptions.ReadPool is set, for reads that must observe the caller's
+// own writes.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsContextKey{}, true)
+}
+
+func primaryReadsFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsContextKey{}).(bool)
+	return primary
+}
+
+// readPool returns the pool for searches, gets and counts. Writes and DDL
+// always use the primary pool.
+func (s *PostgresVectorStore) readPool(ctx context.Context) *pgxpool.Pool {
+	if s.opts.ReadPool == nil || primaryReadsFromContext(ctx) {
+		return s.pool
+	}
+	return s.opts.ReadPool
+}
+
+// withReadTenant is withTenant on the read pool.
+func (s *PostgresVectorStore) withReadTenant(ctx context.Context, fn func(queryExecutor) error) error {
+	return s.withTenantOn(ctx, s.readPool(ctx), fn)
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestPostgresVectorStore_ReadPoolRouting(t *testing.T) {
+	// Arrange
+	primary, err := pgxpool.New(context.Background(), "postgres://primary.invalid/db")
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer primary.Close()
+	replica, err := pgxpool.New(context.Background(), "postgres://replica.invalid/db")
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer replica.Close()
+	routed := &PostgresVectorStore{pool: primary, opts: StoreOptions{ReadPool: replica}}
+	single := &PostgresVectorStore{pool: primary}
+
+	// Act
+	replicaRead := routed.readPool(context.Background())
+	primaryRead := routed.readPool(WithPrimaryReads(context.Background()))
+	singleRead := single.readPool(context.Background())
+
+	// Assert
+	if replicaRead != replica {
+		t.Fatal("expected reads to use the read pool")
+	}
+	if primaryRead != primary {
+		t.Fatal("expected WithPrimaryReads to use the primary pool")
+	}
+	if singleRead != primary {
+		t.Fatal("expected reads to use the primary pool without a read pool")
+	}
+}
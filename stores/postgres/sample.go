@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var _ vectordata.Sampler = (*PostgresCollection)(nil)
+
+// sampleOversample is how many times n rows Sample draws with TABLESAMPLE, so
+// that enough rows remain after filtering in the common case.
+const sampleOversample = 4
+
+// Sample returns up to n random records matching opts.Filter. On large
+// collections it draws about 4n rows with TABLESAMPLE BERNOULLI and orders
+// them randomly; when the filter leaves fewer than n of them, or the
+// collection was never analyzed, it orders every matching row instead. With
+// opts.Seed, the sample is drawn with REPEATABLE and ordered by a hash of the
+// ID and seed, so it repeats while the collection does not change.
+func (c *PostgresCollection) Sample(ctx context.Context, n int, opts vectordata.SampleOptions) ([]vectordata.Record, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be > 0")
+	}
+	rows, analyzed, err := c.estimatedRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	percent := 100.0
+	if analyzed && rows > 0 {
+		percent = min(100, 100*sampleOversample*float64(n)/rows)
+	}
+	records, err := c.sample(ctx, n, opts, percent)
+	if err != nil || len(records) == n || percent == 100 {
+		return records, err
+	}
+	return c.sample(ctx, n, opts, 100)
+}
+
+func (c *PostgresCollection) sample(ctx context.Context, n int, opts vectordata.SampleOptions, percent float64) ([]vectordata.Record, error) {
+	started := time.Now()
+	plan, err := c.buildSamplePlan(n, opts, percent)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.executeSearchPlan(ctx, queryStats{op: "Sample", filter: opts.Filter, started: started}, plan)
+	if err != nil {
+		return nil, err
+	}
+	return resultRecords(results), nil
+}
+
+// buildSamplePlan draws percent of the rows, all of them at 100, and selects
+// n of those matching the filter in random order. Sampling is rare, so its
+// SQL is not cached.
+func (c *PostgresCollection) buildSamplePlan(n int, opts vectordata.SampleOptions, percent float64) (searchPlan, error) {
+	projection := vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}
+	var b strings.Builder
+	var args []any
+	fmt.Fprintf(&b, "SELECT %s FROM %s", strings.Join(c.projectedColumns(projection), ", "), c.tableName())
+	if percent < 100 {
+		args = append(args, percent)
+		fmt.Fprintf(&b, " TABLESAMPLE BERNOULLI ($%d)", len(args))
+		if opts.Seed != nil {
+			args = append(args, float64(*opts.Seed))
+			fmt.Fprintf(&b, " REPEATABLE ($%d)", len(args))
+		}
+	}
+	whereSQL, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), len(args)+1)
+	if err != nil {
+		return searchPlan{}, err
+	}
+	if whereSQL != "" {
+		b.WriteString(" WHERE " + whereSQL)
+	}
+	args = append(args, filterArgs...)
+	if opts.Seed != nil {
+		args = append(args, strconv.FormatInt(*opts.Seed, 10))
+		fmt.Fprintf(&b, " ORDER BY md5(%s || $%d), %s", quoteIdent(idColumn), next, quoteIdent(idColumn))
+		next++
+	} else {
+		b.WriteString(" ORDER BY random()")
+	}
+	args = append(args, n)
+	fmt.Fprintf(&b, " LIMIT $%d", next)
+
+	return searchPlan{query: b.String(), args: args, projection: projection, rank: rankNone}, nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_SamplePlan(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	seed := int64(7)
+	filter := vectordata.Eq(vectordata.Metadata("lang"), "en")
+
+	// Act
+	sampled, sampledErr := collection.buildSamplePlan(5, vectordata.SampleOptions{Filter: filter, Seed: &seed}, 2.5)
+	full, fullErr := collection.buildSamplePlan(5, vectordata.SampleOptions{}, 100)
+
+	// Assert
+	if sampledErr != nil || fullErr != nil {
+		t.Fatalf("buildSamplePlan: %v, %v", sampledErr, fullErr)
+	}
+	expected := `SELECT "id", "vector"::text, "metadata", "content" FROM "public"."docs" TABLESAMPLE BERNOULLI ($1) REPEATABLE ($2) ` +
+		`WHERE (("metadata" #> ARRAY['lang']) = $3::jsonb) ORDER BY md5("id" || $4), "id" LIMIT $5`
+	if sampled.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, sampled.query)
+	}
+	if !reflect.DeepEqual(sampled.args, []any{2.5, 7.0, []byte(`"en"`), "7", 5}) {
+		t.Fatalf("unexpected args: %#v", sampled.args)
+	}
+	if full.query != `SELECT "id", "vector"::text, "metadata", "content" FROM "public"."docs" ORDER BY random() LIMIT $1` {
+		t.Fatalf("unexpected query %s", full.query)
+	}
+}
+
+func TestPostgresCollection_SampleRequiresSize(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, err := collection.Sample(context.Background(), 0, vectordata.SampleOptions{})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error for an empty sample")
+	}
+}
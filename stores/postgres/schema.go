@@ -2,30 +2,54 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
-func (s *PostgresVectorStore) ensureBaseSchema(ctx context.Context) error {
+// schemaExecutor is satisfied by *pgxpool.Pool and pgx.Tx, so schema checks
+// can run inside the advisory-locked EnsureCollection transaction.
+type schemaExecutor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (s *PostgresVectorStore) ensureBaseSchema(ctx context.Context, db schemaExecutor) error {
 	if s.opts.EnsureExtension {
-		if _, err := s.pool.Exec(ctx, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+		if err := s.execSchema(ctx, db, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
 			return fmt.Errorf("ensure pgvector extension: %w", err)
 		}
 	}
 
 	query := fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %s`, quoteIdent(s.opts.Schema))
-	if _, err := s.pool.Exec(ctx, query); err != nil {
+	if err := s.execSchema(ctx, db, query); err != nil {
 		return fmt.Errorf("ensure schema %q: %w", s.opts.Schema, err)
 	}
-	return nil
+	if err := s.ensureCatalogTable(ctx, db); err != nil {
+		return err
+	}
+	if s.opts.Outbox != nil {
+		if err := s.ensureOutbox(ctx, db); err != nil {
+			return err
+		}
+	}
+	if s.opts.Audit != nil {
+		if err := s.ensureAudit(ctx, db); err != nil {
+			return err
+		}
+	}
+	return s.ensureTimestampFunc(ctx, db)
 }
 
-func (s *PostgresVectorStore) tableExists(ctx context.Context, table string) (bool, error) {
+func (s *PostgresVectorStore) tableExists(ctx context.Context, db schemaExecutor, table string) (bool, error) {
 	var exists bool
-	if err := s.pool.QueryRow(ctx,
+	if err := db.QueryRow(ctx,
 		`SELECT EXISTS (
 			SELECT 1 FROM information_schema.tables
 			WHERE table_schema = $1 AND table_name = $2
@@ -38,35 +62,44 @@ func (s *PostgresVectorStore) tableExists(ctx context.Context, table string) (bo
 	return exists, nil
 }
 
-func (s *PostgresVectorStore) createCollectionTable(ctx context.Context, table string, dimension int) error {
-	query := fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			%s text PRIMARY KEY,
-			%s vector(%d) NOT NULL,
-			%s jsonb NOT NULL DEFAULT '{}'::jsonb,
-			%s text
+// createCollectionTable creates the collection table. A partitioned table
+// promotes the partition key to a column and includes it in the primary key,
+// as Postgres requires for unique constraints on partitioned tables.
+func (s *PostgresVectorStore) createCollectionTable(ctx context.Context, db schemaExecutor, table string, dimension int, p *partitioning) error {
+	columns := []string{
+		quoteIdent(idColumn) + " text PRIMARY KEY",
+		fmt.Sprintf("%s vector(%d) NOT NULL", quoteIdent(vectorColumn), dimension),
+		quoteIdent(metadataColumn) + " jsonb NOT NULL DEFAULT '{}'::jsonb",
+		quoteIdent(contentColumn) + " text",
+	}
+	suffix := ""
+	if p != nil {
+		columns[0] = quoteIdent(idColumn) + " text NOT NULL"
+		columns = append(columns,
+			quoteIdent(p.key)+" text NOT NULL",
+			fmt.Sprintf("PRIMARY KEY (%s, %s)", quoteIdent(idColumn), quoteIdent(p.key)),
 		)
-	`,
+		suffix = fmt.Sprintf(" PARTITION BY %s (%s)", strings.ToUpper(string(p.method)), quoteIdent(p.key))
+	}
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)%s",
 		qualifiedTable(s.opts.Schema, table),
-		quoteIdent(idColumn),
-		quoteIdent(vectorColumn),
-		dimension,
-		quoteIdent(metadataColumn),
-		quoteIdent(contentColumn),
+		strings.Join(columns, ", "),
+		suffix,
 	)
-	if _, err := s.pool.Exec(ctx, query); err != nil {
+	if err := s.execSchema(ctx, db, query); err != nil {
 		return fmt.Errorf("create collection table %q: %w", table, err)
 	}
 	return nil
 }
 
-func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, table string, expectedDimension int, mode vectordata.EnsureMode) error {
+func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, db schemaExecutor, table string, expectedDimension int, mode vectordata.EnsureMode) error {
 	type columnInfo struct {
 		dataType string
 		udtName  string
 	}
 
-	rows, err := s.pool.Query(ctx,
+	rows, err := db.Query(ctx,
 		`SELECT column_name, data_type, udt_name
 		 FROM information_schema.columns
 		 WHERE table_schema = $1 AND table_name = $2`,
@@ -105,7 +138,7 @@ func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, tabl
 		return fmt.Errorf("%w: expected %q type vector, got %q", vectordata.ErrSchemaMismatch, vectorColumn, cols[vectorColumn].udtName)
 	}
 
-	if err := s.ensurePrimaryKeyOnID(ctx, table); err != nil {
+	if err := s.ensurePrimaryKeyOnID(ctx, db, table); err != nil {
 		return err
 	}
 
@@ -113,7 +146,7 @@ func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, tabl
 		if mode == vectordata.EnsureStrict {
 			return fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, metadataColumn)
 		}
-		if err := s.addMetadataColumn(ctx, table); err != nil {
+		if err := s.addMetadataColumn(ctx, db, table); err != nil {
 			return err
 		}
 	} else if cols[metadataColumn].udtName != "jsonb" {
@@ -124,14 +157,27 @@ func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, tabl
 		if mode == vectordata.EnsureStrict {
 			return fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, contentColumn)
 		}
-		if err := s.addContentColumn(ctx, table); err != nil {
+		if err := s.addContentColumn(ctx, db, table); err != nil {
 			return err
 		}
 	} else if cols[contentColumn].dataType != "text" {
 		return fmt.Errorf("%w: expected %q data type text, got %q", vectordata.ErrSchemaMismatch, contentColumn, cols[contentColumn].dataType)
 	}
 
-	dimension, err := s.readVectorDimension(ctx, table)
+	if s.opts.EnsureTextSearch {
+		if _, ok := cols[textSearchColumn]; !ok {
+			if mode == vectordata.EnsureStrict {
+				return fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, textSearchColumn)
+			}
+			if err := s.addTextSearchColumn(ctx, db, table); err != nil {
+				return err
+			}
+		} else if cols[textSearchColumn].udtName != "tsvector" {
+			return fmt.Errorf("%w: expected %q type tsvector, got %q", vectordata.ErrSchemaMismatch, textSearchColumn, cols[textSearchColumn].udtName)
+		}
+	}
+
+	dimension, err := s.readVectorDimension(ctx, db, table)
 	if err != nil {
 		return err
 	}
@@ -142,9 +188,9 @@ func (s *PostgresVectorStore) validateCollectionSchema(ctx context.Context, tabl
 	return nil
 }
 
-func (s *PostgresVectorStore) ensurePrimaryKeyOnID(ctx context.Context, table string) error {
+func (s *PostgresVectorStore) ensurePrimaryKeyOnID(ctx context.Context, db schemaExecutor, table string) error {
 	var hasPK bool
-	err := s.pool.QueryRow(ctx, `
+	err := db.QueryRow(ctx, `
 		SELECT EXISTS (
 			SELECT 1
 			FROM information_schema.table_constraints tc
@@ -167,31 +213,85 @@ func (s *PostgresVectorStore) ensurePrimaryKeyOnID(ctx context.Context, table st
 	return nil
 }
 
-func (s *PostgresVectorStore) addMetadataColumn(ctx context.Context, table string) error {
+// validateExtraVectorColumn checks a column kept next to the pgvector column
+// of an existing collection, adding it in auto-migrate mode.
+func (s *PostgresVectorStore) validateExtraVectorColumn(ctx context.Context, db schemaExecutor, table string, column extraVectorColumn, mode vectordata.EnsureMode) error {
+	var udtName string
+	err := db.QueryRow(ctx,
+		`SELECT udt_name FROM information_schema.columns
+		 WHERE table_schema = $1 AND table_name = $2 AND column_name = $3`,
+		s.opts.Schema,
+		table,
+		column.name,
+	).Scan(&udtName)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if mode == vectordata.EnsureStrict {
+			return fmt.Errorf("%w: missing column %q", vectordata.ErrSchemaMismatch, column.name)
+		}
+		return s.addExtraVectorColumn(ctx, db, table, column)
+	case err != nil:
+		return fmt.Errorf("read schema columns: %w", err)
+	case udtName != column.udtName:
+		return fmt.Errorf("%w: expected %q type %s, got %q", vectordata.ErrSchemaMismatch, column.name, column.sqlType, udtName)
+	default:
+		return nil
+	}
+}
+
+func (s *PostgresVectorStore) addExtraVectorColumn(ctx context.Context, db schemaExecutor, table string, column extraVectorColumn) error {
+	query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s`,
+		qualifiedTable(s.opts.Schema, table),
+		quoteIdent(column.name),
+		column.sqlType,
+	)
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("add %s column: %w", column.name, err)
+	}
+	return nil
+}
+
+func (s *PostgresVectorStore) addMetadataColumn(ctx context.Context, db schemaExecutor, table string) error {
 	query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s jsonb NOT NULL DEFAULT '{}'::jsonb`,
 		qualifiedTable(s.opts.Schema, table),
 		quoteIdent(metadataColumn),
 	)
-	if _, err := s.pool.Exec(ctx, query); err != nil {
+	if err := s.execSchema(ctx, db, query); err != nil {
 		return fmt.Errorf("auto-migrate metadata column: %w", err)
 	}
 	return nil
 }
 
-func (s *PostgresVectorStore) addContentColumn(ctx context.Context, table string) error {
+func (s *PostgresVectorStore) addContentColumn(ctx context.Context, db schemaExecutor, table string) error {
 	query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s text`,
 		qualifiedTable(s.opts.Schema, table),
 		quoteIdent(contentColumn),
 	)
-	if _, err := s.pool.Exec(ctx, query); err != nil {
+	if err := s.execSchema(ctx, db, query); err != nil {
 		return fmt.Errorf("auto-migrate content column: %w", err)
 	}
 	return nil
 }
 
-func (s *PostgresVectorStore) readVectorDimension(ctx context.Context, table string) (int, error) {
+// addTextSearchColumn adds the generated tsvector column. The text search
+// configuration is fixed in the generation expression, so changing
+// TextSearchConfig later requires dropping the column.
+func (s *PostgresVectorStore) addTextSearchColumn(ctx context.Context, db schemaExecutor, table string) error {
+	query := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (to_tsvector('%s'::regconfig, coalesce(%s, ''))) STORED`,
+		qualifiedTable(s.opts.Schema, table),
+		quoteIdent(textSearchColumn),
+		s.opts.TextSearchConfig,
+		quoteIdent(contentColumn),
+	)
+	if err := s.execSchema(ctx, db, query); err != nil {
+		return fmt.Errorf("add text search column: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresVectorStore) readVectorDimension(ctx context.Context, db schemaExecutor, table string) (int, error) {
 	var typeName string
-	err := s.pool.QueryRow(ctx, `
+	err := db.QueryRow(ctx, `
 		SELECT format_type(a.atttypid, a.atttypmod)
 		FROM pg_attribute a
 		JOIN pg_class c ON c.oid = a.attrelid
@@ -227,3 +327,83 @@ func parseVectorDimension(typeName string) (int, error) {
 	}
 	return dim, nil
 }
+
+// maxConcurrentIndexAttempts bounds retries when a concurrent build leaves an
+// invalid index behind (e.g. after a deadlock or a cancelled build).
+const maxConcurrentIndexAttempts = 3
+
+// createIndexConcurrently runs a CREATE INDEX CONCURRENTLY statement. A failed
+// concurrent build leaves an INVALID index that IF NOT EXISTS would silently
+// accept, so invalid leftovers are dropped before each attempt and the result
+// is verified afterwards.
+func (s *PostgresVectorStore) createIndexConcurrently(ctx context.Context, indexName, query string) error {
+	var lastErr error
+	for attempt := 0; attempt < maxConcurrentIndexAttempts; attempt++ {
+		valid, exists, err := s.indexValidity(ctx, indexName)
+		if err != nil {
+			return err
+		}
+		if exists && valid {
+			return nil
+		}
+		if exists {
+			if err := s.dropIndexConcurrently(ctx, indexName); err != nil {
+				return err
+			}
+		}
+
+		// CONCURRENTLY cannot run inside a transaction block; pool.Exec sends a
+		// single statement outside any explicit transaction.
+		if err := s.execSchema(ctx, s.pool, query); err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return err
+			}
+			continue
+		}
+
+		valid, exists, err = s.indexValidity(ctx, indexName)
+		if err != nil {
+			return err
+		}
+		if exists && valid {
+			return nil
+		}
+		lastErr = fmt.Errorf("index %q is invalid after concurrent build", indexName)
+	}
+
+	// Leave no invalid index behind for the next caller.
+	if valid, exists, err := s.indexValidity(ctx, indexName); err == nil && exists && !valid {
+		_ = s.dropIndexConcurrently(ctx, indexName)
+	}
+	return fmt.Errorf("create index %q concurrently after %d attempts: %w", indexName, maxConcurrentIndexAttempts, lastErr)
+}
+
+// indexValidity reports whether an index exists in the store schema and
+// whether pg_index marks it valid.
+func (s *PostgresVectorStore) indexValidity(ctx context.Context, indexName string) (valid bool, exists bool, err error) {
+	err = s.pool.QueryRow(ctx,
+		`SELECT i.indisvalid
+		 FROM pg_index i
+		 JOIN pg_class c ON c.oid = i.indexrelid
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = $1 AND c.relname = $2`,
+		s.opts.Schema,
+		indexName,
+	).Scan(&valid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("check index %q: %w", indexName, err)
+	}
+	return valid, true, nil
+}
+
+func (s *PostgresVectorStore) dropIndexConcurrently(ctx context.Context, indexName string) error {
+	query := fmt.Sprintf(`DROP INDEX CONCURRENTLY IF EXISTS %s`, qualifiedTable(s.opts.Schema, indexName))
+	if err := s.execSchema(ctx, s.pool, query); err != nil {
+		return fmt.Errorf("drop invalid index %q: %w", indexName, err)
+	}
+	return nil
+}
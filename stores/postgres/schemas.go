@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// maxIdentifierLength is Postgres' NAMEDATALEN - 1; longer names are
+// silently truncated, which could map two tenants to one schema.
+const maxIdentifierLength = 63
+
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// schemaScopes caches the stores returned by ForSchema. It is shared by a
+// store and every store scoped from it.
+type schemaScopes struct {
+	mu     sync.Mutex
+	stores map[string]*PostgresVectorStore
+}
+
+func newSchemaScopes() *schemaScopes {
+	return &schemaScopes{stores: make(map[string]*PostgresVectorStore)}
+}
+
+// ForSchema returns a store whose collections live in schema, e.g. one schema
+// per tenant. It shares the pool, options and statement cache with s, and the
+// schema and extension are created by its first EnsureCollection. Stores are
+// cached, so repeated calls for a schema return the same store.
+func (s *PostgresVectorStore) ForSchema(schema string) (*PostgresVectorStore, error) {
+	if err := validateSchemaName(schema); err != nil {
+		return nil, err
+	}
+	if schema == s.opts.Schema {
+		return s, nil
+	}
+
+	s.scopes.mu.Lock()
+	defer s.scopes.mu.Unlock()
+	if scoped, ok := s.scopes.stores[schema]; ok {
+		return scoped, nil
+	}
+	opts := s.opts
+	opts.Schema = schema
+	scoped := &PostgresVectorStore{
+		pool:       s.pool,
+		opts:       opts,
+		statements: s.statements,
+		scopes:     s.scopes,
+	}
+	s.scopes.stores[schema] = scoped
+	return scoped, nil
+}
+
+// validateSchemaName accepts unquoted-style identifiers that Postgres keeps
+// as-is and rejects the reserved pg_ prefix.
+func validateSchemaName(schema string) error {
+	if !schemaNamePattern.MatchString(schema) || len(schema) > maxIdentifierLength {
+		return fmt.Errorf("%w: invalid schema name %q", vectordata.ErrSchemaMismatch, schema)
+	}
+	if strings.HasPrefix(strings.ToLower(schema), "pg_") || strings.EqualFold(schema, "information_schema") {
+		return fmt.Errorf("%w: schema name %q is reserved", vectordata.ErrSchemaMismatch, schema)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestPostgresVectorStore_ForSchema(t *testing.T) {
+	// Arrange
+	pool, err := pgxpool.New(context.Background(), "postgres://db.invalid/db")
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer pool.Close()
+	store, err := NewVectorStore(pool, DefaultStoreOptions())
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+
+	// Act
+	tenant, tenantErr := store.ForSchema("tenant_acme")
+	again, _ := store.ForSchema("tenant_acme")
+	nested, _ := tenant.ForSchema("tenant_acme")
+	same, _ := store.ForSchema("public")
+	collection := tenant.Collection("docs", 2, vectordata.DistanceCosine).(*PostgresCollection)
+
+	// Assert
+	if tenantErr != nil {
+		t.Fatalf("ForSchema: %v", tenantErr)
+	}
+	if again != tenant || nested != tenant {
+		t.Fatal("expected the scoped store to be cached")
+	}
+	if same != store {
+		t.Fatal("expected the store itself for its own schema")
+	}
+	if tenant.statements != store.statements || tenant.opts.TextSearchConfig != store.opts.TextSearchConfig {
+		t.Fatal("expected the scoped store to share options and statement cache")
+	}
+	if collection.tableName() != `"tenant_acme"."docs"` {
+		t.Fatalf("unexpected table %s", collection.tableName())
+	}
+}
+
+func TestValidateSchemaName(t *testing.T) {
+	cases := map[string]bool{
+		"tenant_1":           true,
+		"Tenant":             true,
+		"":                   false,
+		"1tenant":            false,
+		"tenant-1":           false,
+		`tenant"; DROP`:      false,
+		"pg_tenant":          false,
+		"information_schema": false,
+		"t234567890123456789012345678901234567890123456789012345678901234": false,
+	}
+
+	for name, valid := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := validateSchemaName(name)
+
+			// Assert
+			if valid && err != nil {
+				t.Fatalf("expected %q to be valid, got %v", name, err)
+			}
+			if !valid && !errors.Is(err, vectordata.ErrSchemaMismatch) {
+				t.Fatalf("expected ErrSchemaMismatch for %q, got %v", name, err)
+			}
+		})
+	}
+}
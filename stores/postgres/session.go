@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// qualifiedNamePattern matches plain and qualified names such as runtime
+// parameters (work_mem, hnsw.ef_search) and text search configurations
+// (english, pg_catalog.simple).
+var qualifiedNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// sessionSettings is a validated set_config statement for per-query settings.
+type sessionSettings struct {
+	query string
+	args  []any
+}
+
+// buildSessionSettings compiles settings into a single set_config call per
+// parameter with is_local = true, which behaves like SET LOCAL but binds values
+// as arguments. Names are sorted so the statement text is stable.
+func buildSessionSettings(settings map[string]string) (sessionSettings, error) {
+	if len(settings) == 0 {
+		return sessionSettings{}, nil
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if !qualifiedNamePattern.MatchString(name) {
+			return sessionSettings{}, fmt.Errorf("invalid session setting name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	calls := make([]string, 0, len(names))
+	args := make([]any, 0, len(names)*2)
+	for i, name := range names {
+		calls = append(calls, fmt.Sprintf("set_config($%d, $%d, true)", i*2+1, i*2+2))
+		args = append(args, name, settings[name])
+	}
+	return sessionSettings{
+		query: "SELECT " + strings.Join(calls, ", "),
+		args:  args,
+	}, nil
+}
+
+// withSessionSettings runs fn in a transaction with settings applied locally,
+// so they are discarded at commit and never leak to other users of the pooled
+// connection. The tenant from WithTenant, if any, is applied first. Settings
+// are only used by searches, so the transaction runs on the read pool.
+// Transient failures retry the whole transaction under StoreOptions.Retry.
+func (s *PostgresVectorStore) withSessionSettings(ctx context.Context, settings sessionSettings, fn func(pgx.Tx) error) error {
+	return s.withRetry(ctx, func() error {
+		return s.runWithSessionSettings(ctx, settings, fn)
+	})
+}
+
+// In a store bound to a transaction, the settings are applied in a savepoint
+// that is rolled back afterwards, which discards them before the
+// transaction's next statement.
+func (s *PostgresVectorStore) runWithSessionSettings(ctx context.Context, settings sessionSettings, fn func(pgx.Tx) error) error {
+	var tx pgx.Tx
+	var err error
+	if s.tx != nil {
+		tx, err = s.tx.Begin(ctx)
+	} else {
+		tx, err = s.readPool(ctx).Begin(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("begin session settings transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if tenant, ok := TenantFromContext(ctx); ok {
+		if _, err := tx.Exec(ctx, `SELECT set_config($1, $2, true)`, s.opts.TenantSetting, tenant); err != nil {
+			return fmt.Errorf("apply tenant setting: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, settings.query, settings.args...); err != nil {
+		return fmt.Errorf("apply session settings: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if s.tx != nil {
+		return tx.Rollback(ctx)
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestBuildSessionSettings_SortedAndBound(t *testing.T) {
+	// Act
+	settings, err := buildSessionSettings(map[string]string{
+		"work_mem":       "64MB",
+		"hnsw.ef_search": "200",
+		"enable_seqscan": "off",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildSessionSettings: %v", err)
+	}
+	expected := "SELECT set_config($1, $2, true), set_config($3, $4, true), set_config($5, $6, true)"
+	if settings.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, settings.query)
+	}
+	expectedArgs := []any{"enable_seqscan", "off", "hnsw.ef_search", "200", "work_mem", "64MB"}
+	if !reflect.DeepEqual(settings.args, expectedArgs) {
+		t.Fatalf("unexpected args: %#v", settings.args)
+	}
+}
+
+func TestBuildSessionSettings_RejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{"", "work_mem; RESET ALL", "a.b.c", "1abc"} {
+		// Act
+		_, err := buildSessionSettings(map[string]string{name: "1"})
+
+		// Assert
+		if err == nil {
+			t.Fatalf("expected error for setting name %q", name)
+		}
+	}
+}
+
+func TestPostgresCollection_SearchPlanCarriesSessionSettings(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	plan, err := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{
+		SessionSettings: map[string]string{"hnsw.ef_search": "100"},
+	})
+	plain, plainErr := collection.buildSearchPlan([]float32{1, 0}, 3, vectordata.SearchOptions{})
+
+	// Assert
+	if err != nil || plainErr != nil {
+		t.Fatalf("buildSearchPlan: %v %v", err, plainErr)
+	}
+	if plan.settings.query == "" || plain.settings.query != "" {
+		t.Fatalf("unexpected settings: %#v / %#v", plan.settings, plain.settings)
+	}
+	if plan.query != plain.query {
+		t.Fatalf("session settings must not change the search statement")
+	}
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// SlowQueryOptions reports collection operations that take longer than
+// Threshold.
+type SlowQueryOptions struct {
+	Threshold time.Duration
+	// Handler receives each slow operation. When nil, slow operations are
+	// logged at warn level to StoreOptions.Logger.
+	Handler func(ctx context.Context, query SlowQuery)
+}
+
+func (o SlowQueryOptions) validate() error {
+	if o.Threshold <= 0 {
+		return fmt.Errorf("%w: slow query threshold must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// SlowQuery describes a slow collection operation. It carries the statement
+// and the shape of its arguments and filter, never their values.
+type SlowQuery struct {
+	Operation  string
+	Collection string
+	// SQL is the statement with whitespace collapsed and literals masked; a
+	// batched write reports its first statement.
+	SQL string
+	// ArgShapes lists the type of each argument, with the length of arrays,
+	// e.g. "text", "int", "text[500]".
+	ArgShapes []string
+	// Filter summarizes the filter AST, e.g. "and(eq(metadata.category))".
+	Filter string
+	// Duration is the total time, split into Plan (validating the request
+	// and building the statement) and Execute (running it, including
+	// retries, and decoding rows).
+	Duration time.Duration
+	Plan     time.Duration
+	Execute  time.Duration
+	Rows     int64
+	Err      error
+}
+
+// queryStats is what a collection operation reports when it finishes.
+type queryStats struct {
+	op         string
+	collection string
+	query      string
+	args       []any
+	filter     vectordata.Filter
+	// started is when the operation began and executed is when its
+	// statement was sent.
+	started  time.Time
+	executed time.Time
+	rows     int64
+	err      error
+}
+
+func argShapes(args []any) []string {
+	shapes := make([]string, 0, len(args))
+	for _, arg := range args {
+		shapes = append(shapes, argShape(arg))
+	}
+	return shapes
+}
+
+func argShape(arg any) string {
+	switch v := arg.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "text"
+	case bool:
+		return "bool"
+	case int, int32, int64:
+		return "int"
+	case float32, float64:
+		return "float"
+	case []string:
+		return fmt.Sprintf("text[%d]", len(v))
+	case []*string:
+		return fmt.Sprintf("text[%d]", len(v))
+	case []any:
+		return fmt.Sprintf("any[%d]", len(v))
+	default:
+		return fmt.Sprintf("%T", arg)
+	}
+}
+
+// reportSlowQuery hands an operation over the threshold to the handler, or
+// logs it.
+func (s *PostgresVectorStore) reportSlowQuery(ctx context.Context, stats queryStats, finished time.Time) {
+	opts := s.opts.SlowQuery
+	if opts == nil {
+		return
+	}
+	duration := finished.Sub(stats.started)
+	if duration < opts.Threshold {
+		return
+	}
+
+	query := SlowQuery{
+		Operation:  stats.op,
+		Collection: stats.collection,
+		SQL:        sanitizeSQL(stats.query),
+		ArgShapes:  argShapes(stats.args),
+		Filter:     vectordata.DescribeFilter(stats.filter),
+		Duration:   duration,
+		Plan:       stats.executed.Sub(stats.started),
+		Execute:    finished.Sub(stats.executed),
+		Rows:       stats.rows,
+		Err:        stats.err,
+	}
+	if opts.Handler != nil {
+		opts.Handler(ctx, query)
+		return
+	}
+	if s.opts.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", query.Operation),
+		slog.String("collection", query.Collection),
+		slog.String("sql", query.SQL),
+		slog.Any("arg_shapes", query.ArgShapes),
+		slog.String("filter", query.Filter),
+		slog.Duration("duration", query.Duration),
+		slog.Duration("plan", query.Plan),
+		slog.Duration("execute", query.Execute),
+		slog.Int64("rows", query.Rows),
+	}
+	if query.Err != nil {
+		attrs = append(attrs, slog.Any("error", query.Err))
+	}
+	s.opts.Logger.LogAttrs(ctx, slog.LevelWarn, "vectorstore slow query", attrs...)
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresVectorStore_SlowQueryHandler(t *testing.T) {
+	// Arrange
+	var reported []SlowQuery
+	store := &PostgresVectorStore{opts: StoreOptions{SlowQuery: &SlowQueryOptions{
+		Threshold: 50 * time.Millisecond,
+		Handler:   func(_ context.Context, query SlowQuery) { reported = append(reported, query) },
+	}}}
+	now := time.Now()
+	slow := queryStats{
+		op:         "SearchByVector",
+		collection: "docs",
+		query:      "SELECT \"id\" FROM \"public\".\"docs\"\n WHERE (\"metadata\" ->> 'category') = $2 ORDER BY distance ASC LIMIT $3",
+		args:       []any{"[1,0,0]", "secret", 10},
+		filter:     vectordata.Eq(vectordata.Metadata("category"), "secret"),
+		started:    now.Add(-200 * time.Millisecond),
+		executed:   now.Add(-150 * time.Millisecond),
+		rows:       3,
+	}
+	fast := slow
+	fast.started, fast.executed = now, now
+
+	// Act
+	store.finishQuery(context.Background(), slow)
+	store.finishQuery(context.Background(), fast)
+
+	// Assert
+	if len(reported) != 1 {
+		t.Fatalf("expected one slow query, got %d", len(reported))
+	}
+	query := reported[0]
+	if query.Operation != "SearchByVector" || query.Collection != "docs" || query.Rows != 3 || query.Err != nil {
+		t.Fatalf("unexpected slow query %+v", query)
+	}
+	if query.SQL != `SELECT "id" FROM "public"."docs" WHERE ("metadata" ->> '?') = $2 ORDER BY distance ASC LIMIT $3` {
+		t.Fatalf("unexpected sql %q", query.SQL)
+	}
+	if !reflect.DeepEqual(query.ArgShapes, []string{"text", "text", "int"}) {
+		t.Fatalf("unexpected arg shapes %v", query.ArgShapes)
+	}
+	if query.Filter != "eq(metadata.category)" {
+		t.Fatalf("unexpected filter summary %q", query.Filter)
+	}
+	if query.Plan != 50*time.Millisecond || query.Execute < 150*time.Millisecond || query.Duration != query.Plan+query.Execute {
+		t.Fatalf("unexpected timing plan=%v execute=%v duration=%v", query.Plan, query.Execute, query.Duration)
+	}
+}
+
+func TestPostgresVectorStore_SlowQueryLogsWithoutHandler(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	store := &PostgresVectorStore{opts: StoreOptions{
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		SlowQuery: &SlowQueryOptions{Threshold: time.Millisecond},
+	}}
+	started := time.Now().Add(-time.Second)
+
+	// Act
+	store.finishQuery(context.Background(), queryStats{
+		op:         "Upsert",
+		collection: "docs",
+		query:      "INSERT INTO docs SELECT * FROM unnest($1::text[])",
+		args:       []any{[]string{"a", "b"}},
+		started:    started,
+		executed:   started,
+		err:        errors.New("boom"),
+	})
+
+	// Assert
+	out := buf.String()
+	for _, want := range []string{"level=WARN", `msg="vectorstore slow query"`, "operation=Upsert", "arg_shapes=[text[2]]", "error=boom"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected log to contain %s, got %s", want, out)
+		}
+	}
+	if strings.Contains(out, `"a"`) {
+		t.Fatalf("expected no argument values in the log, got %s", out)
+	}
+}
+
+func TestStoreOptions_ValidateSlowQuery(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.SlowQuery = &SlowQueryOptions{}
+
+	// Act
+	err := opts.validate()
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for a zero threshold, got %v", err)
+	}
+}
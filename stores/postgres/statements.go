@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// maxCachedStatements bounds the statement cache. Filters with variable
+// shapes (e.g. IN lists of different lengths) produce distinct SQL, so the
+// cache is cleared instead of growing without limit.
+const maxCachedStatements = 1024
+
+type statementKind int
+
+const (
+	statementGet statementKind = iota
+	statementDelete
+	statementDeleteReturning
+	statementCount
+	statementSearch
+	statementInsert
+	statementUpsert
+	statementUpsertSkip
+	statementUpsertMergeMetadata
+	statementTextSearch
+	statementHybridSearch
+	statementGetMany
+	statementList
+	statementDeleteByFilter
+	statementNeighbors
+)
+
+// statementKey identifies generated SQL. Filter values are always bound as
+// arguments, so the compiled WHERE clause captures the filter shape only.
+type statementKey struct {
+	kind       statementKind
+	collection string
+	metric     vectordata.DistanceMetric
+	dimension  int
+	projection vectordata.Projection
+	filter     string
+	threshold  bool
+	// cursor distinguishes searches resuming after a SearchCursor.
+	cursor bool
+	// orderBy is the ORDER BY clause of searches.
+	orderBy string
+	// score is the ranking score expression of searches that rank by a
+	// boosted score rather than by distance.
+	score string
+	// highlight distinguishes text searches that also select a snippet.
+	highlight bool
+	// partitionKey distinguishes partitioned handles, whose writes also bind
+	// the promoted partition column.
+	partitionKey string
+	// elementType distinguishes handles with a native vector column, which
+	// they also bind and select.
+	elementType vectordata.VectorElementType
+	// fastDimension distinguishes handles that also write, index and search
+	// the reduced vector column.
+	fastDimension int
+}
+
+// statementCache memoizes generated SQL so hot paths skip query building and
+// send byte-identical text on every call. With pgx's default
+// QueryExecModeCacheStatement, identical text is prepared once per connection
+// and reused, avoiding a parse/plan round trip per query.
+type statementCache struct {
+	mu      sync.RWMutex
+	entries map[statementKey]string
+}
+
+func newStatementCache() *statementCache {
+	return &statementCache{entries: make(map[statementKey]string)}
+}
+
+// get returns cached SQL for key, building and storing it on a miss.
+func (c *statementCache) get(key statementKey, build func() string) string {
+	c.mu.RLock()
+	query, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return query
+	}
+
+	query = build()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedStatements {
+		clear(c.entries)
+	}
+	c.entries[key] = query
+	return query
+}
+
+func (c *statementCache) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
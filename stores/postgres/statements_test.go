@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func newUnitTestCollection(metric vectordata.DistanceMetric) *PostgresCollection {
+	store := &PostgresVectorStore{opts: DefaultStoreOptions(), statements: newStatementCache()}
+	return store.Collection("docs", 2, metric).(*PostgresCollection)
+}
+
+func TestPostgresCollection_SearchPlanReusesStatement(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	threshold := 0.5
+
+	// Act
+	first, firstErr := collection.buildSearchPlan([]float32{1, 0}, 5, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "a"),
+		Threshold: &threshold,
+	})
+	second, secondErr := collection.buildSearchPlan([]float32{0, 1}, 7, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "b"),
+		Threshold: &threshold,
+	})
+	unfiltered, unfilteredErr := collection.buildSearchPlan([]float32{0, 1}, 7, vectordata.SearchOptions{})
+
+	// Assert
+	if firstErr != nil || secondErr != nil || unfilteredErr != nil {
+		t.Fatalf("buildSearchPlan: %v %v %v", firstErr, secondErr, unfilteredErr)
+	}
+	expected := `SELECT "id", "metadata", "content", "vector" <=> $1::vector AS distance FROM "public"."docs" WHERE (("metadata" #> ARRAY['kind']) = $2::jsonb) AND ("vector" <=> $1::vector <= $3) ORDER BY distance ASC, "id" ASC LIMIT $4`
+	if first.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, first.query)
+	}
+	if second.query != first.query {
+		t.Fatalf("expected identical statement text for the same filter shape")
+	}
+	if !reflect.DeepEqual(second.args[1:], []any{[]byte(`"b"`), 0.5, 7}) {
+		t.Fatalf("unexpected args: %#v", second.args)
+	}
+	if unfiltered.query == first.query {
+		t.Fatalf("expected a distinct statement for a different filter shape")
+	}
+	if size := collection.store.statements.len(); size != 2 {
+		t.Fatalf("expected 2 cached statements, got %d", size)
+	}
+}
+
+func TestPostgresCollection_WriteBatchTextIsSizeIndependent(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceL2)
+	content := "body"
+
+	// Act
+	single, singleArgs, singleErr := collection.buildWriteBatch([]vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+	}, writeModeUpsert)
+	batch, batchArgs, batchErr := collection.buildWriteBatch([]vectordata.Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{0, 1}, Metadata: map[string]any{"k": 1}, Content: &content},
+	}, writeModeUpsert)
+
+	// Assert
+	if singleErr != nil || batchErr != nil {
+		t.Fatalf("buildWriteBatch: %v %v", singleErr, batchErr)
+	}
+	if single != batch {
+		t.Fatalf("expected identical statement text\n%s\n%s", single, batch)
+	}
+	if len(singleArgs) != 4 || len(batchArgs) != 4 {
+		t.Fatalf("expected 4 array args, got %d and %d", len(singleArgs), len(batchArgs))
+	}
+	if !reflect.DeepEqual(batchArgs[0], []string{"a", "b"}) || !reflect.DeepEqual(batchArgs[2], []string{"{}", `{"k":1}`}) {
+		t.Fatalf("unexpected args: %#v", batchArgs)
+	}
+}
+
+func TestPostgresCollection_QueueWriteBatchesChunksRecords(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	records := make([]vectordata.Record, 0, 2*maxRowsPerStatement+1)
+	for i := 0; i < cap(records); i++ {
+		records = append(records, vectordata.Record{ID: fmt.Sprintf("r%d", i), Vector: []float32{1, 0}})
+	}
+
+	// Act
+	batch, err := collection.queueWriteBatches(records, writeModeInsert)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("queueWriteBatches: %v", err)
+	}
+	if batch.Len() != 3 {
+		t.Fatalf("expected 3 queued chunks, got %d", batch.Len())
+	}
+	last := batch.QueuedQueries[2]
+	if last.SQL != batch.QueuedQueries[0].SQL {
+		t.Fatalf("expected chunks to share statement text")
+	}
+	if ids := last.Arguments[0].([]string); len(ids) != 1 || ids[0] != "r1000" {
+		t.Fatalf("unexpected last chunk ids: %v", ids)
+	}
+}
+
+func TestStatementCache_Bounded(t *testing.T) {
+	// Arrange
+	cache := newStatementCache()
+
+	// Act
+	for i := 0; i <= maxCachedStatements; i++ {
+		filter := fmt.Sprintf("f%d", i)
+		cache.get(statementKey{kind: statementCount, filter: filter}, func() string { return filter })
+	}
+
+	// Assert
+	if size := cache.len(); size != 1 {
+		t.Fatalf("expected cache reset at capacity, got %d entries", size)
+	}
+}
+
+func TestPostgresCollection_RedactionSharesStatement(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	projection := vectordata.DefaultProjection()
+	projection.Redact = &vectordata.Redaction{MetadataKeys: []string{"owner"}}
+
+	// Act
+	plain, plainErr := collection.buildSearchPlan([]float32{1, 0}, 5, vectordata.SearchOptions{})
+	redacted, redactedErr := collection.buildSearchPlan([]float32{1, 0}, 5, vectordata.SearchOptions{Projection: &projection})
+
+	// Assert
+	if plainErr != nil || redactedErr != nil {
+		t.Fatalf("unexpected errors: %v %v", plainErr, redactedErr)
+	}
+	if plain.query != redacted.query || collection.store.statements.len() != 1 {
+		t.Fatalf("expected one cached statement, got %d", collection.store.statements.len())
+	}
+	if redacted.projection.Redact == nil {
+		t.Fatal("expected the plan to keep the redaction")
+	}
+}
@@ -3,9 +3,13 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,21 +18,86 @@ type StoreOptions struct {
 	Schema          string
 	EnsureExtension bool
 	StrictByDefault bool
+	// TextSearchConfig is the text search configuration (e.g. "english",
+	// "simple") used by the generated tsvector column and by text queries.
+	TextSearchConfig string
+	// EnsureTextSearch makes EnsureCollection maintain the generated tsvector
+	// column over content. Strict mode requires it to exist on existing tables.
+	EnsureTextSearch bool
+	// RowLevelSecurity makes EnsureCollection enable RLS with a tenant policy
+	// on collection tables. Strict mode requires it on existing tables.
+	RowLevelSecurity *RowLevelSecurityOptions
+	// HalfvecOversampling is how many candidates per requested result a search
+	// fetches through a halfvec index before exact rescoring. It applies to
+	// collections above 2000 dimensions.
+	HalfvecOversampling int
+	// TenantSetting is the runtime parameter that carries the tenant set with
+	// WithTenant and read by the RLS policy.
+	TenantSetting string
+	// ReadPool, when set, serves searches, gets and counts, typically from
+	// read replicas. Writes and schema changes always use the pool passed to
+	// NewVectorStore; WithPrimaryReads routes a read there too.
+	ReadPool *pgxpool.Pool
+	// Retry retries collection operations and schema changes that fail with
+	// transient errors. Nil disables retries.
+	Retry *RetryPolicy
+	// Logger, when set, receives debug logs of executed statements with
+	// their durations and row counts, and of schema changes. Statement
+	// arguments are never logged and literals are masked.
+	Logger *slog.Logger
+	// SlowQuery reports collection operations slower than its threshold to
+	// a handler, or to Logger at warn level. Nil disables it.
+	SlowQuery *SlowQueryOptions
+	// FastVectorOversampling is how many candidates per requested result a
+	// search of a collection with a FastDimension ranks by the reduced vector
+	// before rescoring with the full one.
+	FastVectorOversampling int
+	// Timeouts bound searches, writes and schema changes whose context has
+	// no deadline.
+	Timeouts Timeouts
+	// RecordLimits validates records in Insert and Upsert before they are
+	// sent to the server.
+	RecordLimits vectordata.RecordLimits
+	// Outbox, when set, records every change to collections ensured by the
+	// store in an outbox table, for delivery with PollOutbox. Nil disables it.
+	Outbox *OutboxOptions
+	// Audit, when set, records every change to collections ensured by the
+	// store, with the actor from WithActor, in an audit table queried with
+	// AuditLog. Nil disables it.
+	Audit *AuditOptions
+	// AllowRecreate accepts EnsureRecreate, which drops a collection whose
+	// schema does not match its spec, together with its records. Leave it
+	// unset outside test environments.
+	AllowRecreate bool
 }
 
 // DefaultStoreOptions returns production-safe defaults.
 func DefaultStoreOptions() StoreOptions {
 	return StoreOptions{
-		Schema:          "public",
-		EnsureExtension: true,
-		StrictByDefault: true,
+		Schema:                 "public",
+		EnsureExtension:        true,
+		StrictByDefault:        true,
+		TextSearchConfig:       defaultTextSearchConfig,
+		TenantSetting:          defaultTenantSetting,
+		HalfvecOversampling:    defaultHalfvecOversampling,
+		FastVectorOversampling: defaultFastVectorOversampling,
 	}
 }
 
 // PostgresVectorStore implements vectordata.VectorStore using pgxpool.
 type PostgresVectorStore struct {
-	pool *pgxpool.Pool
-	opts StoreOptions
+	pool       *pgxpool.Pool
+	opts       StoreOptions
+	statements *statementCache
+	// partitions caches LIST partitions known to exist, keyed by
+	// partitionCacheKey.
+	partitions sync.Map
+	// scopes caches stores returned by ForSchema.
+	scopes *schemaScopes
+	// schemaReady is set once the schema and extension have been ensured.
+	schemaReady atomic.Bool
+	// tx is set on copies bound to a transaction by Tx.Collection.
+	tx pgx.Tx
 }
 
 // NewVectorStore creates a Postgres-backed vector store.
@@ -40,30 +109,80 @@ func NewVectorStore(pool *pgxpool.Pool, opts StoreOptions) (*PostgresVectorStore
 	if err := normalized.validate(); err != nil {
 		return nil, err
 	}
-	return &PostgresVectorStore{pool: pool, opts: normalized}, nil
+	return &PostgresVectorStore{pool: pool, opts: normalized, statements: newStatementCache(), scopes: newSchemaScopes()}, nil
 }
 
 // Collection returns a handle to a collection without schema checks.
 func (s *PostgresVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
-	return s.newCollectionHandle(name, dimension, metric)
+	return s.newCollectionHandle(name, dimension, metric, nil)
 }
 
 // EnsureCollection creates or validates a collection schema and returns its handle.
 func (s *PostgresVectorStore) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	ctx, cancel := withDefaultTimeout(ctx, s.opts.Timeouts.Schema)
+	defer cancel()
 	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := s.ensureBaseSchema(ctx); err != nil {
+	partition, err := normalizePartition(normalizedSpec.Partition)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.ensureTableWithValidation(ctx, normalizedSpec.Name, normalizedSpec.Dimension, mode); err != nil {
+	// Concurrent callers serialize on advisory locks: one per schema for the
+	// extension and schema, one per collection for its table. The schema lock
+	// is only taken until the schema has been ensured once.
+	if !s.schemaReady.Load() {
+		err = s.withSchemaLock(ctx, advisoryLockKey(s.opts.Schema), func(tx pgx.Tx) error {
+			return s.ensureBaseSchema(ctx, tx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.schemaReady.Store(true)
+	}
+
+	var recreated bool
+	err = s.withSchemaLock(ctx, advisoryLockKey(s.opts.Schema, normalizedSpec.Name), func(tx pgx.Tx) error {
+		if mode == vectordata.EnsureRecreate {
+			var err error
+			recreated, err = s.recreateOnMismatch(ctx, tx, normalizedSpec, partition)
+			return err
+		}
+		return s.ensureCollectionTable(ctx, tx, normalizedSpec, partition, mode)
+	})
+	if err != nil {
 		return nil, err
 	}
+	if recreated {
+		s.forgetPartitions(normalizedSpec.Name)
+	}
+	if partition != nil && partition.method == vectordata.PartitionList {
+		s.rememberPartitions(normalizedSpec.Name, partition.values)
+	}
+
+	return s.collectionFromSpec(normalizedSpec, partition), nil
+}
 
-	return s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric), nil
+// ensureCollectionTable creates or validates the table of a collection and
+// its catalog entry.
+func (s *PostgresVectorStore) ensureCollectionTable(ctx context.Context, db schemaExecutor, spec vectordata.CollectionSpec, partition *partitioning, mode vectordata.EnsureMode) error {
+	if err := s.ensureTableWithValidation(ctx, db, spec, partition, mode); err != nil {
+		return err
+	}
+	return s.ensureCatalogEntry(ctx, db, spec)
+}
+
+// collectionFromSpec returns the handle of a normalized spec.
+func (s *PostgresVectorStore) collectionFromSpec(spec vectordata.CollectionSpec, partition *partitioning) *PostgresCollection {
+	collection := s.newCollectionHandle(spec.Name, spec.Dimension, spec.Metric, partition).(*PostgresCollection)
+	collection.normalize = spec.NormalizeVectors
+	collection.elementType = spec.ElementType
+	collection.fastDimension = spec.FastDimension
+	collection.maxRecords = spec.MaxRecords
+	collection.evictBy = spec.EvictBy
+	return collection
 }
 
 func (s *PostgresVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, vectordata.EnsureMode, error) {
@@ -71,6 +190,9 @@ func (s *PostgresVectorStore) normalizeCollectionSpec(spec vectordata.Collection
 	if spec.Name == "" {
 		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
 	}
+	if spec.Name == catalogTable || (s.opts.Outbox != nil && spec.Name == s.opts.Outbox.Table) || (s.opts.Audit != nil && spec.Name == s.opts.Audit.Table) {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name %q is reserved", vectordata.ErrSchemaMismatch, spec.Name)
+	}
 	if spec.Dimension <= 0 {
 		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
 	}
@@ -78,34 +200,114 @@ func (s *PostgresVectorStore) normalizeCollectionSpec(spec vectordata.Collection
 	if _, err := metricOperator(spec.Metric); err != nil {
 		return vectordata.CollectionSpec{}, "", err
 	}
+	if err := spec.ElementType.Validate(); err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
+	if spec.ElementType == "" {
+		spec.ElementType = vectordata.ElementFloat32
+	}
+	if spec.ElementType != vectordata.ElementFloat32 && spec.NormalizeVectors {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: NormalizeVectors is not supported with %s vectors", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension < 0 || spec.FastDimension >= spec.Dimension {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension must be >= 0 and below the dimension %d, got %d", vectordata.ErrSchemaMismatch, spec.Dimension, spec.FastDimension)
+	}
+	if spec.FastDimension > maxVectorIndexDimensions {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension supports at most %d dimensions, got %d", vectordata.ErrSchemaMismatch, maxVectorIndexDimensions, spec.FastDimension)
+	}
+
+	spec, err := vectordata.ValidateEviction(spec)
+	if err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
 
 	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
-	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+	if mode == vectordata.EnsureRecreate && !s.opts.AllowRecreate {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: ensure mode %q requires StoreOptions.AllowRecreate", vectordata.ErrSchemaMismatch, mode)
+	}
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate && mode != vectordata.EnsureRecreate {
 		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
 	}
 	return spec, mode, nil
 }
 
-func (s *PostgresVectorStore) ensureTableWithValidation(ctx context.Context, tableName string, dimension int, mode vectordata.EnsureMode) error {
-	exists, err := s.tableExists(ctx, tableName)
+func (s *PostgresVectorStore) ensureTableWithValidation(ctx context.Context, db schemaExecutor, spec vectordata.CollectionSpec, partition *partitioning, mode vectordata.EnsureMode) error {
+	tableName, dimension := spec.Name, spec.Dimension
+	extraColumns := extraVectorColumns(spec.ElementType, spec.FastDimension)
+	exists, err := s.tableExists(ctx, db, tableName)
 	if err != nil {
 		return err
 	}
 	if !exists {
-		if err := s.createCollectionTable(ctx, tableName, dimension); err != nil {
+		if err := s.createCollectionTable(ctx, db, tableName, dimension, partition); err != nil {
+			return err
+		}
+		for _, column := range extraColumns {
+			if err := s.addExtraVectorColumn(ctx, db, tableName, column); err != nil {
+				return err
+			}
+		}
+		if s.opts.EnsureTextSearch {
+			if err := s.addTextSearchColumn(ctx, db, tableName); err != nil {
+				return err
+			}
+		}
+		if s.opts.RowLevelSecurity != nil {
+			if err := s.enableRowLevelSecurity(ctx, db, tableName, partition); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := s.validatePartitioning(ctx, db, tableName, partition); err != nil {
+			return err
+		}
+		if err := s.validateCollectionSchema(ctx, db, tableName, dimension, mode); err != nil {
+			return err
+		}
+		for _, column := range extraColumns {
+			if err := s.validateExtraVectorColumn(ctx, db, tableName, column, mode); err != nil {
+				return err
+			}
+		}
+		if s.opts.RowLevelSecurity != nil {
+			if err := s.validateRowLevelSecurity(ctx, db, tableName, partition, mode); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.ensurePartitions(ctx, db, tableName, partition); err != nil {
+		return err
+	}
+	if s.opts.Outbox != nil {
+		if err := s.ensureOutboxTrigger(ctx, db, tableName); err != nil {
 			return err
 		}
+	}
+	if s.opts.Audit != nil {
+		return s.ensureAuditTrigger(ctx, db, tableName)
+	}
+	return nil
+}
+
+// ensurePartitions creates every HASH partition, or the declared LIST values.
+// Further LIST partitions are created on demand by writes.
+func (s *PostgresVectorStore) ensurePartitions(ctx context.Context, db schemaExecutor, tableName string, partition *partitioning) error {
+	if partition == nil {
 		return nil
 	}
-	return s.validateCollectionSchema(ctx, tableName, dimension, mode)
+	if partition.method == vectordata.PartitionHash {
+		return s.createHashPartitions(ctx, db, tableName, partition)
+	}
+	return s.createListPartitions(ctx, db, tableName, partition.values)
 }
 
-func (s *PostgresVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+func (s *PostgresVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric, partition *partitioning) vectordata.Collection {
 	return &PostgresCollection{
 		store:     s,
 		name:      name,
 		dimension: dimension,
 		metric:    defaultMetric(metric),
+		partition: partition,
 	}
 }
 
@@ -113,6 +315,46 @@ func (o StoreOptions) withDefaults() StoreOptions {
 	if strings.TrimSpace(o.Schema) == "" {
 		o.Schema = "public"
 	}
+	if strings.TrimSpace(o.TextSearchConfig) == "" {
+		o.TextSearchConfig = defaultTextSearchConfig
+	}
+	if o.HalfvecOversampling == 0 {
+		o.HalfvecOversampling = defaultHalfvecOversampling
+	}
+	if o.FastVectorOversampling == 0 {
+		o.FastVectorOversampling = defaultFastVectorOversampling
+	}
+	if strings.TrimSpace(o.TenantSetting) == "" {
+		o.TenantSetting = defaultTenantSetting
+	}
+	if o.Retry != nil {
+		retry := o.Retry.withDefaults()
+		o.Retry = &retry
+	}
+	if o.RowLevelSecurity != nil {
+		rls := *o.RowLevelSecurity
+		if strings.TrimSpace(rls.TenantKey) == "" {
+			rls.TenantKey = defaultTenantKey
+		}
+		o.RowLevelSecurity = &rls
+	}
+	if o.Outbox != nil {
+		outbox := *o.Outbox
+		if strings.TrimSpace(outbox.Table) == "" {
+			outbox.Table = defaultOutboxTable
+		}
+		o.Outbox = &outbox
+	}
+	if o.Audit != nil {
+		audit := *o.Audit
+		if strings.TrimSpace(audit.Table) == "" {
+			audit.Table = defaultAuditTable
+		}
+		if strings.TrimSpace(audit.ActorSetting) == "" {
+			audit.ActorSetting = defaultAuditActorSetting
+		}
+		o.Audit = &audit
+	}
 	return o
 }
 
@@ -120,5 +362,30 @@ func (o StoreOptions) validate() error {
 	if strings.TrimSpace(o.Schema) == "" {
 		return fmt.Errorf("%w: schema is empty", vectordata.ErrSchemaMismatch)
 	}
-	return nil
+	if !qualifiedNamePattern.MatchString(o.TextSearchConfig) {
+		return fmt.Errorf("%w: invalid text search config %q", vectordata.ErrSchemaMismatch, o.TextSearchConfig)
+	}
+	if o.HalfvecOversampling < 1 {
+		return fmt.Errorf("%w: halfvec oversampling must be >= 1", vectordata.ErrSchemaMismatch)
+	}
+	if o.FastVectorOversampling < 1 {
+		return fmt.Errorf("%w: fast vector oversampling must be >= 1", vectordata.ErrSchemaMismatch)
+	}
+	if !qualifiedNamePattern.MatchString(o.TenantSetting) {
+		return fmt.Errorf("%w: invalid tenant setting %q", vectordata.ErrSchemaMismatch, o.TenantSetting)
+	}
+	if o.Audit != nil && !qualifiedNamePattern.MatchString(o.Audit.ActorSetting) {
+		return fmt.Errorf("%w: invalid audit actor setting %q", vectordata.ErrSchemaMismatch, o.Audit.ActorSetting)
+	}
+	if o.Retry != nil {
+		if err := o.Retry.validate(); err != nil {
+			return err
+		}
+	}
+	if o.SlowQuery != nil {
+		if err := o.SlowQuery.validate(); err != nil {
+			return err
+		}
+	}
+	return o.Timeouts.validate()
 }
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultTenantSetting = "app.tenant_id"
+	defaultTenantKey     = "tenant_id"
+
+	// tenantPolicyName names the RLS policy created on collection tables.
+	tenantPolicyName = "tenant_isolation"
+)
+
+// RowLevelSecurityOptions configures the tenant policy EnsureCollection
+// creates on collection tables.
+type RowLevelSecurityOptions struct {
+	// TenantKey is the top-level metadata key holding the tenant (default
+	// tenant_id). When it is also the partition key, the policy compares the
+	// promoted column instead.
+	TenantKey string
+	// Force applies the policy to the table owner too. Without it, a role that
+	// owns the table bypasses RLS.
+	Force bool
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose operations run in a transaction with the
+// store's TenantSetting set locally to tenant, so RLS policies see it.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok
+}
+
+// queryExecutor is satisfied by *pgxpool.Pool and pgx.Tx.
+type queryExecutor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// withTenant runs fn on the primary pool, or in a transaction with the tenant
+// setting applied locally when the context carries a tenant. is_local = true
+// keeps the setting from leaking to other users of the pooled connection.
+// Writes to an audited store apply the actor from WithActor the same way.
+func (s *PostgresVectorStore) withTenant(ctx context.Context, fn func(queryExecutor) error) error {
+	return s.withLocalSettings(ctx, s.pool, s.localSettings(ctx, true), fn)
+}
+
+// withTenantOn is withTenant for reads on pool, which may be a replica. It
+// applies the tenant setting but never the audit actor. Transient failures
+// are retried under StoreOptions.Retry.
+func (s *PostgresVectorStore) withTenantOn(ctx context.Context, pool *pgxpool.Pool, fn func(queryExecutor) error) error {
+	return s.withLocalSettings(ctx, pool, s.localSettings(ctx, false), fn)
+}
+
+// localSettings returns the tenant setting and, for writes to an audited
+// store, the actor setting carried by ctx.
+func (s *PostgresVectorStore) localSettings(ctx context.Context, write bool) map[string]string {
+	settings := make(map[string]string, 2)
+	if tenant, ok := TenantFromContext(ctx); ok {
+		settings[s.opts.TenantSetting] = tenant
+	}
+	if actor, ok := ActorFromContext(ctx); ok && write && s.opts.Audit != nil {
+		settings[s.opts.Audit.ActorSetting] = actor
+	}
+	return settings
+}
+
+// withLocalSettings runs fn on pool, or in a transaction with settings
+// applied locally when there are any. A store bound to a transaction runs fn
+// in it instead.
+func (s *PostgresVectorStore) withLocalSettings(ctx context.Context, pool *pgxpool.Pool, settings map[string]string, fn func(queryExecutor) error) error {
+	apply := func(tx pgx.Tx) error {
+		if len(settings) == 0 {
+			return nil
+		}
+		compiled, err := buildSessionSettings(settings)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, compiled.query, compiled.args...); err != nil {
+			return fmt.Errorf("apply local settings: %w", err)
+		}
+		return nil
+	}
+	if s.tx != nil {
+		if err := apply(s.tx); err != nil {
+			return err
+		}
+		return classifyError(fn(s.tx))
+	}
+	if len(settings) == 0 {
+		return s.withRetry(ctx, func() error { return fn(pool) })
+	}
+
+	return s.withRetry(ctx, func() error {
+		return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			if err := apply(tx); err != nil {
+				return err
+			}
+			return fn(tx)
+		})
+	})
+}
+
+// tenantExpr is the SQL compared against the tenant setting by the policy.
+func (s *PostgresVectorStore) tenantExpr(partition *partitioning) string {
+	key := s.opts.RowLevelSecurity.TenantKey
+	if partition != nil && partition.key == key {
+		return quoteIdent(key)
+	}
+	return fmt.Sprintf("(%s ->> %s)", quoteIdent(metadataColumn), quoteLiteral(key))
+}
+
+// enableRowLevelSecurity enables RLS and creates the tenant policy. Rows are
+// visible and writable only when the tenant expression equals the setting;
+// an unset setting reads as NULL, so connections without a tenant see nothing.
+func (s *PostgresVectorStore) enableRowLevelSecurity(ctx context.Context, db schemaExecutor, table string, partition *partitioning) error {
+	statements := []string{fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", qualifiedTable(s.opts.Schema, table))}
+	if s.opts.RowLevelSecurity.Force {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", qualifiedTable(s.opts.Schema, table)))
+	}
+
+	hasPolicy, err := s.tenantPolicyExists(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if !hasPolicy {
+		check := fmt.Sprintf("%s = current_setting(%s, true)", s.tenantExpr(partition), quoteLiteral(s.opts.TenantSetting))
+		statements = append(statements, fmt.Sprintf("CREATE POLICY %s ON %s USING (%s) WITH CHECK (%s)",
+			quoteIdent(tenantPolicyName),
+			qualifiedTable(s.opts.Schema, table),
+			check,
+			check,
+		))
+	}
+
+	for _, query := range statements {
+		if err := s.execSchema(ctx, db, query); err != nil {
+			return fmt.Errorf("enable row level security on %q: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// validateRowLevelSecurity checks RLS on an existing table. Strict mode
+// reports a missing policy; auto-migrate creates it.
+func (s *PostgresVectorStore) validateRowLevelSecurity(ctx context.Context, db schemaExecutor, table string, partition *partitioning, mode vectordata.EnsureMode) error {
+	var enabled, forced bool
+	err := db.QueryRow(ctx, `
+		SELECT c.relrowsecurity, c.relforcerowsecurity
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+	`, s.opts.Schema, table).Scan(&enabled, &forced)
+	if err != nil {
+		return fmt.Errorf("read row level security: %w", err)
+	}
+	hasPolicy, err := s.tenantPolicyExists(ctx, db, table)
+	if err != nil {
+		return err
+	}
+
+	if enabled && hasPolicy && (forced || !s.opts.RowLevelSecurity.Force) {
+		return nil
+	}
+	if mode == vectordata.EnsureStrict {
+		return fmt.Errorf("%w: row level security with policy %q is not enabled on %q", vectordata.ErrSchemaMismatch, tenantPolicyName, table)
+	}
+	return s.enableRowLevelSecurity(ctx, db, table, partition)
+}
+
+func (s *PostgresVectorStore) tenantPolicyExists(ctx context.Context, db schemaExecutor, table string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_policies
+			WHERE schemaname = $1 AND tablename = $2 AND policyname = $3
+		)
+	`, s.opts.Schema, table, tenantPolicyName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check tenant policy: %w", err)
+	}
+	return exists, nil
+}
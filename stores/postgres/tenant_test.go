@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestWithTenant(t *testing.T) {
+	// Act
+	tenant, ok := TenantFromContext(WithTenant(context.Background(), "acme"))
+	_, missing := TenantFromContext(context.Background())
+
+	// Assert
+	if !ok || tenant != "acme" {
+		t.Fatalf("expected tenant acme, got %q (%v)", tenant, ok)
+	}
+	if missing {
+		t.Fatalf("expected no tenant on a bare context")
+	}
+}
+
+func TestStoreOptions_RowLevelSecurityDefaults(t *testing.T) {
+	// Arrange
+	rls := &RowLevelSecurityOptions{}
+
+	// Act
+	opts := StoreOptions{RowLevelSecurity: rls}.withDefaults()
+	invalidErr := StoreOptions{TenantSetting: "app.tenant; DROP"}.withDefaults().validate()
+
+	// Assert
+	if opts.TenantSetting != defaultTenantSetting || opts.RowLevelSecurity.TenantKey != defaultTenantKey {
+		t.Fatalf("unexpected defaults %+v %+v", opts, opts.RowLevelSecurity)
+	}
+	if rls.TenantKey != "" {
+		t.Fatalf("expected caller options to be left unchanged")
+	}
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for an invalid tenant setting, got %v", invalidErr)
+	}
+}
+
+func TestPostgresVectorStore_TenantExpr(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{opts: StoreOptions{RowLevelSecurity: &RowLevelSecurityOptions{}}.withDefaults()}
+	partition := &partitioning{method: vectordata.PartitionList, key: "tenant_id"}
+	other := &partitioning{method: vectordata.PartitionList, key: "region"}
+
+	// Act
+	metadataExpr := store.tenantExpr(nil)
+	columnExpr := store.tenantExpr(partition)
+	otherExpr := store.tenantExpr(other)
+
+	// Assert
+	if metadataExpr != `("metadata" ->> 'tenant_id')` || otherExpr != metadataExpr {
+		t.Fatalf("unexpected metadata tenant expression %q / %q", metadataExpr, otherExpr)
+	}
+	if columnExpr != `"tenant_id"` {
+		t.Fatalf("unexpected partition column tenant expression %q", columnExpr)
+	}
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var (
+	_ vectordata.TextSearcher   = (*PostgresCollection)(nil)
+	_ vectordata.HybridSearcher = (*PostgresCollection)(nil)
+)
+
+// SearchByText ranks records by ts_rank of the generated tsvector column
+// against a websearch_to_tsquery of text. Score carries the rank and Distance
+// is zero. The column must exist; see StoreOptions.EnsureTextSearch and
+// IndexOptions.Text.
+func (c *PostgresCollection) SearchByText(ctx context.Context, text string, topK int, opts vectordata.TextSearchOptions) ([]vectordata.SearchResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	plan, err := c.buildTextSearchPlan(text, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.executeSearchPlan(ctx, queryStats{op: "SearchByText", filter: opts.Filter, started: started}, plan)
+}
+
+// HybridSearch merges the topK nearest vectors with the topK best text
+// matches and orders the candidates by
+// VectorWeight*vectorScore + TextWeight*ts_rank, where vectorScore follows
+// vectordata.ScoreFromDistance. Score carries the combined value and Distance
+// the vector distance. RankProfile is ignored.
+func (c *PostgresCollection) HybridSearch(ctx context.Context, vector []float32, text string, topK int, opts vectordata.HybridSearchOptions) ([]vectordata.SearchResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, c.store.opts.Timeouts.Search)
+	defer cancel()
+	started := time.Now()
+	if c.normalize {
+		vector = vectordata.NormalizeVector(vector)
+	}
+	plan, err := c.buildHybridSearchPlan(vector, text, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.executeSearchPlan(ctx, queryStats{op: "HybridSearch", filter: opts.Filter, started: started}, plan)
+}
+
+func (c *PostgresCollection) buildTextSearchPlan(text string, topK int, opts vectordata.TextSearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if strings.TrimSpace(text) == "" {
+		return searchPlan{}, fmt.Errorf("text search query is empty")
+	}
+
+	projection := resolveProjection(opts.Projection)
+	args := []any{c.store.opts.TextSearchConfig, text}
+	nextArg := 3
+	whereSQL := ""
+	if opts.Filter != nil {
+		compiled, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), nextArg)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		whereSQL = compiled
+		args = append(args, filterArgs...)
+		nextArg = next
+	}
+	args = append(args, topK)
+	headlineArg := 0
+	if opts.Highlight != nil {
+		options, err := headlineOptions(*opts.Highlight)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		headlineArg = nextArg + 1
+		args = append(args, options)
+	}
+
+	key := statementKey{kind: statementTextSearch, projection: projection, filter: whereSQL, highlight: headlineArg > 0}
+	query := c.statement(key, func() string {
+		tsv := quoteIdent(textSearchColumn)
+		selectCols := append(c.projectedColumns(projection), fmt.Sprintf("ts_rank(%s, tsq) AS score", tsv))
+		if headlineArg > 0 {
+			selectCols = append(selectCols, headlineExpr(1, headlineArg))
+		}
+
+		var b strings.Builder
+		b.WriteString("SELECT ")
+		b.WriteString(strings.Join(selectCols, ", "))
+		b.WriteString(" FROM ")
+		b.WriteString(c.tableName())
+		b.WriteString(", websearch_to_tsquery($1::regconfig, $2) AS tsq")
+		b.WriteString(fmt.Sprintf(" WHERE %s @@ tsq", tsv))
+		if whereSQL != "" {
+			b.WriteString(" AND ")
+			b.WriteString(whereSQL)
+		}
+		b.WriteString(" ORDER BY score DESC")
+		b.WriteString(fmt.Sprintf(" LIMIT $%d", nextArg))
+		return b.String()
+	})
+
+	return searchPlan{
+		query:      query,
+		args:       args,
+		projection: projection,
+		rank:       rankScore,
+		highlight:  headlineArg > 0,
+	}, nil
+}
+
+func (c *PostgresCollection) buildHybridSearchPlan(vector []float32, text string, topK int, opts vectordata.HybridSearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return searchPlan{}, fmt.Errorf("hybrid search text is empty")
+	}
+
+	metric := defaultMetric(c.metric)
+	operator, err := metricOperator(metric)
+	if err != nil {
+		return searchPlan{}, err
+	}
+	distanceExpr := fmt.Sprintf(`%s %s $1::vector`, quoteIdent(vectorColumn), operator)
+	vectorScoreExpr, err := sqlScoreFromDistance(metric, distanceExpr)
+	if err != nil {
+		return searchPlan{}, err
+	}
+
+	vectorWeight := opts.VectorWeight
+	if vectorWeight == 0 {
+		vectorWeight = 1
+	}
+	textWeight := opts.TextWeight
+	if textWeight == 0 {
+		textWeight = 1
+	}
+
+	projection := resolveProjection(opts.Projection)
+	args := []any{vectorLiteral(vector), c.store.opts.TextSearchConfig, text, vectorWeight, textWeight}
+	nextArg := 6
+	whereSQL := ""
+	if opts.Filter != nil {
+		compiled, filterArgs, next, err := vectordata.CompileFilterSQL(opts.Filter, c.filterConfig(), nextArg)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		whereSQL = compiled
+		args = append(args, filterArgs...)
+		nextArg = next
+	}
+	args = append(args, topK)
+	fastArg := 0
+	if c.fastDimension > 0 {
+		fastQuery, err := c.fastQueryLiteral(vector)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		fastArg = nextArg + 1
+		args = append(args, fastQuery)
+	}
+	headlineArg := 0
+	if opts.Highlight != nil {
+		options, err := headlineOptions(*opts.Highlight)
+		if err != nil {
+			return searchPlan{}, err
+		}
+		headlineArg = len(args) + 1
+		args = append(args, options)
+	}
+
+	key := statementKey{kind: statementHybridSearch, projection: projection, filter: whereSQL, highlight: headlineArg > 0}
+	query := c.statement(key, func() string {
+		id := quoteIdent(idColumn)
+		tsv := quoteIdent(textSearchColumn)
+		table := c.tableName()
+		limit := fmt.Sprintf("$%d", nextArg)
+		vectorWhere, textFilter := "", ""
+		if whereSQL != "" {
+			vectorWhere = " WHERE " + whereSQL
+			textFilter = " AND " + whereSQL
+		}
+
+		selectCols := append(c.projectedColumns(projection),
+			distanceExpr+" AS distance",
+			fmt.Sprintf("$4::float8 * (%s) + $5::float8 * ts_rank(%s, tsq) AS score", vectorScoreExpr, tsv),
+		)
+		if headlineArg > 0 {
+			selectCols = append(selectCols, headlineExpr(2, headlineArg))
+		}
+
+		var b strings.Builder
+		b.WriteString("WITH vector_hits AS (")
+		b.WriteString(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %s", id, table, vectorWhere, c.indexedDistanceExpr(operator, fastArg), limit))
+		b.WriteString("), text_hits AS (")
+		b.WriteString(fmt.Sprintf("SELECT %s FROM %s, websearch_to_tsquery($2::regconfig, $3) AS tsq WHERE %s @@ tsq%s ORDER BY ts_rank(%s, tsq) DESC LIMIT %s", id, table, tsv, textFilter, tsv, limit))
+		b.WriteString(") SELECT ")
+		b.WriteString(strings.Join(selectCols, ", "))
+		b.WriteString(" FROM ")
+		b.WriteString(table)
+		b.WriteString(", websearch_to_tsquery($2::regconfig, $3) AS tsq")
+		b.WriteString(fmt.Sprintf(" WHERE %s IN (SELECT %s FROM vector_hits UNION SELECT %s FROM text_hits)", id, id, id))
+		b.WriteString(" ORDER BY score DESC")
+		b.WriteString(" LIMIT " + limit)
+		return b.String()
+	})
+
+	return searchPlan{
+		query:      query,
+		args:       args,
+		projection: projection,
+		rank:       rankDistanceAndScore,
+		highlight:  headlineArg > 0,
+	}, nil
+}
+
+// ensureTextIndex adds the generated tsvector column when missing and builds
+// a GIN index over it. The column is added under the collection's schema
+// lock so it does not race with EnsureCollection.
+func (c *PostgresCollection) ensureTextIndex(ctx context.Context, opts *vectordata.TextIndexOptions) error {
+	err := c.withSchemaLock(ctx, advisoryLockKey(c.store.opts.Schema, c.name), func(db schemaExecutor) error {
+		return c.store.addTextSearchColumn(ctx, db, c.name)
+	})
+	if err != nil {
+		return fmt.Errorf("ensure text index: %w", err)
+	}
+
+	indexName := opts.Name
+	if indexName == "" {
+		indexName = fmt.Sprintf("idx_%s_%s_gin", c.name, textSearchColumn)
+	}
+	query := fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s)",
+		quoteIdent(indexName),
+		c.tableName(),
+		quoteIdent(textSearchColumn),
+	)
+	if err := c.store.execSchema(ctx, c.schemaDB(), query); err != nil {
+		return fmt.Errorf("ensure text index: %w", err)
+	}
+	return nil
+}
+
+// sqlScoreFromDistance mirrors vectordata.ScoreFromDistance in SQL.
+// headlineOptions renders opts as ts_headline options. MinWords is half of
+// MaxWords, as it must be lower.
+func headlineOptions(opts vectordata.HighlightOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	opts = opts.WithDefaults()
+	if strings.Contains(opts.StartSel+opts.StopSel, `"`) {
+		return "", fmt.Errorf("%w: highlight StartSel and StopSel cannot contain double quotes", vectordata.ErrSchemaMismatch)
+	}
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d`, opts.StartSel, opts.StopSel, opts.MaxWords, max(opts.MaxWords/2, 1))
+	if opts.MaxFragments > 0 {
+		options += fmt.Sprintf(", MaxFragments=%d", opts.MaxFragments)
+	}
+	return options, nil
+}
+
+// headlineExpr selects the ts_headline of the content against tsq, with the
+// text search configuration and options bound as $configArg and
+// $optionsArg.
+func headlineExpr(configArg, optionsArg int) string {
+	return fmt.Sprintf("ts_headline($%d::regconfig, %s, tsq, $%d) AS snippet", configArg, quoteIdent(contentColumn), optionsArg)
+}
+
+func sqlScoreFromDistance(metric vectordata.DistanceMetric, distanceExpr string) (string, error) {
+	switch metric {
+	case vectordata.DistanceCosine:
+		return fmt.Sprintf("1 - (%s)", distanceExpr), nil
+	case vectordata.DistanceL2:
+		return fmt.Sprintf("1 / (1 + (%s))", distanceExpr), nil
+	case vectordata.DistanceInnerProduct:
+		return fmt.Sprintf("-(%s)", distanceExpr), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+}
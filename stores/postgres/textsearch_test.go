@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_TextSearchPlan(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	plan, err := collection.buildTextSearchPlan("vector databases", 3, vectordata.TextSearchOptions{
+		Filter: vectordata.Eq(vectordata.Metadata("kind"), "a"),
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildTextSearchPlan: %v", err)
+	}
+	expected := `SELECT "id", "metadata", "content", ts_rank("content_tsv", tsq) AS score FROM "public"."docs", websearch_to_tsquery($1::regconfig, $2) AS tsq WHERE "content_tsv" @@ tsq AND (("metadata" #> ARRAY['kind']) = $3::jsonb) ORDER BY score DESC LIMIT $4`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if !reflect.DeepEqual(plan.args, []any{"english", "vector databases", []byte(`"a"`), 3}) {
+		t.Fatalf("unexpected args: %#v", plan.args)
+	}
+	if plan.rank != rankScore {
+		t.Fatalf("expected score-only ranking, got %v", plan.rank)
+	}
+}
+
+func TestPostgresCollection_HybridSearchPlan(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceL2)
+
+	// Act
+	plan, err := collection.buildHybridSearchPlan([]float32{1, 0}, "hello", 2, vectordata.HybridSearchOptions{TextWeight: 0.5})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("buildHybridSearchPlan: %v", err)
+	}
+	expected := `WITH vector_hits AS (SELECT "id" FROM "public"."docs" ORDER BY "vector" <-> $1::vector LIMIT $6), ` +
+		`text_hits AS (SELECT "id" FROM "public"."docs", websearch_to_tsquery($2::regconfig, $3) AS tsq WHERE "content_tsv" @@ tsq ORDER BY ts_rank("content_tsv", tsq) DESC LIMIT $6) ` +
+		`SELECT "id", "metadata", "content", "vector" <-> $1::vector AS distance, $4::float8 * (1 / (1 + ("vector" <-> $1::vector))) + $5::float8 * ts_rank("content_tsv", tsq) AS score ` +
+		`FROM "public"."docs", websearch_to_tsquery($2::regconfig, $3) AS tsq WHERE "id" IN (SELECT "id" FROM vector_hits UNION SELECT "id" FROM text_hits) ORDER BY score DESC LIMIT $6`
+	if plan.query != expected {
+		t.Fatalf("unexpected query\nwant: %s\n got: %s", expected, plan.query)
+	}
+	if !reflect.DeepEqual(plan.args, []any{"[1,0]", "english", "hello", 1.0, 0.5, 2}) {
+		t.Fatalf("unexpected args: %#v", plan.args)
+	}
+}
+
+func TestPostgresCollection_TextSearchPlanSelectsHeadline(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	plan, err := collection.buildTextSearchPlan("vector databases", 3, vectordata.TextSearchOptions{
+		Highlight: &vectordata.HighlightOptions{MaxWords: 10, MaxFragments: 2},
+	})
+	hybrid, hybridErr := collection.buildHybridSearchPlan([]float32{1, 0}, "hello", 2, vectordata.HybridSearchOptions{
+		Highlight: &vectordata.HighlightOptions{StartSel: "[", StopSel: "]"},
+	})
+	_, quoteErr := collection.buildTextSearchPlan("x", 3, vectordata.TextSearchOptions{
+		Highlight: &vectordata.HighlightOptions{StartSel: `"`, StopSel: `"`},
+	})
+
+	// Assert
+	if err != nil || hybridErr != nil {
+		t.Fatalf("build plans: %v, %v", err, hybridErr)
+	}
+	if !strings.Contains(plan.query, `ts_rank("content_tsv", tsq) AS score, ts_headline($1::regconfig, "content", tsq, $4) AS snippet FROM`) || !plan.highlight {
+		t.Fatalf("expected the text search to select a headline, got %s", plan.query)
+	}
+	if plan.args[3] != `StartSel="<b>", StopSel="</b>", MaxWords=10, MinWords=5, MaxFragments=2` {
+		t.Fatalf("unexpected headline options %v", plan.args[3])
+	}
+	if !strings.Contains(hybrid.query, `ts_headline($2::regconfig, "content", tsq, $7) AS snippet`) || hybrid.args[6] != `StartSel="[", StopSel="]", MaxWords=35, MinWords=17` {
+		t.Fatalf("expected the hybrid search to select a headline, got %s with %v", hybrid.query, hybrid.args)
+	}
+	if !errors.Is(quoteErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for quoted selectors, got %v", quoteErr)
+	}
+}
+
+func TestPostgresCollection_TextSearchRejectsEmptyText(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	_, textErr := collection.buildTextSearchPlan("  ", 3, vectordata.TextSearchOptions{})
+	_, hybridErr := collection.buildHybridSearchPlan([]float32{1, 0}, "", 3, vectordata.HybridSearchOptions{})
+
+	// Assert
+	if textErr == nil || hybridErr == nil {
+		t.Fatalf("expected errors for empty text, got %v and %v", textErr, hybridErr)
+	}
+}
+
+func TestStoreOptions_RejectsInvalidTextSearchConfig(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.TextSearchConfig = "english'); DROP TABLE docs; --"
+
+	// Act
+	err := opts.withDefaults().validate()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected invalid text search config error")
+	}
+}
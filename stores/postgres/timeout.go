@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// Timeouts bound operations whose context has no deadline, so a caller that
+// forgets one cannot hold a connection indefinitely. Each covers the whole
+// call, retries included. Zero disables a timeout; deadlines set by callers
+// always win.
+type Timeouts struct {
+	// Search bounds searches, gets, counts and catalog reads.
+	Search time.Duration
+	// Write bounds Insert, Upsert and Delete.
+	Write time.Duration
+	// Schema bounds EnsureCollection and EnsureIndexes.
+	Schema time.Duration
+}
+
+func (t Timeouts) validate() error {
+	if t.Search < 0 || t.Write < 0 || t.Schema < 0 {
+		return fmt.Errorf("%w: timeouts must be >= 0", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// withDefaultTimeout applies timeout to ctx unless it is zero or ctx already
+// has a deadline.
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestWithDefaultTimeoutAppliesWithoutDeadline(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	got, cancel := withDefaultTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// Assert
+	deadline, ok := got.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Fatalf("unexpected remaining time %v", remaining)
+	}
+}
+
+func TestWithDefaultTimeoutKeepsCallerDeadline(t *testing.T) {
+	// Arrange
+	ctx, cancelCaller := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelCaller()
+	want, _ := ctx.Deadline()
+
+	// Act
+	got, cancel := withDefaultTimeout(ctx, time.Second)
+	defer cancel()
+
+	// Assert
+	deadline, _ := got.Deadline()
+	if !deadline.Equal(want) {
+		t.Fatalf("expected caller deadline %v, got %v", want, deadline)
+	}
+}
+
+func TestWithDefaultTimeoutZeroDisables(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	got, cancel := withDefaultTimeout(ctx, 0)
+	defer cancel()
+
+	// Assert
+	if _, ok := got.Deadline(); ok {
+		t.Fatal("expected no deadline")
+	}
+}
+
+func TestStoreOptionsRejectNegativeTimeouts(t *testing.T) {
+	// Arrange
+	opts := DefaultStoreOptions()
+	opts.Timeouts.Write = -time.Second
+
+	// Act
+	err := opts.validate()
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// ensureTrigramIndexes creates pg_trgm GIN indexes for Contains and Similar
+// filters. Metadata indexes are expression indexes over the same
+// jsonb_extract_path_text expression the filter compiler emits, so the planner
+// can match them.
+func (c *PostgresCollection) ensureTrigramIndexes(ctx context.Context, opts *vectordata.TrigramIndexOptions) error {
+	statements, err := c.trigramIndexStatements(opts)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+
+	if c.store.opts.EnsureExtension {
+		if err := c.store.execSchema(ctx, c.schemaDB(), `CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+			return fmt.Errorf("ensure pg_trgm extension: %w", err)
+		}
+	}
+	for _, query := range statements {
+		if err := c.store.execSchema(ctx, c.schemaDB(), query); err != nil {
+			return fmt.Errorf("ensure trigram index: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *PostgresCollection) trigramIndexStatements(opts *vectordata.TrigramIndexOptions) ([]string, error) {
+	var statements []string
+	if opts.Content {
+		statements = append(statements, c.trigramIndexStatement(
+			fmt.Sprintf("idx_%s_content_trgm", c.name),
+			quoteIdent(contentColumn),
+		))
+	}
+	for _, path := range opts.MetadataPaths {
+		field, err := vectordata.NormalizeFieldRef(vectordata.Metadata(path...))
+		if err != nil {
+			return nil, fmt.Errorf("%w: trigram metadata path: %v", vectordata.ErrSchemaMismatch, err)
+		}
+		statements = append(statements, c.trigramIndexStatement(
+			fmt.Sprintf("idx_%s_metadata_%s_trgm", c.name, strings.Join(field.Path, "_")),
+			"("+vectordata.MetadataPathTextSQL(quoteIdent(metadataColumn), field.Path)+")",
+		))
+	}
+	return statements, nil
+}
+
+func (c *PostgresCollection) trigramIndexStatement(indexName, expr string) string {
+	return fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s gin_trgm_ops)",
+		quoteIdent(indexName),
+		c.tableName(),
+		expr,
+	)
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestPostgresCollection_TrigramIndexStatements(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+
+	// Act
+	statements, err := collection.trigramIndexStatements(&vectordata.TrigramIndexOptions{
+		Content:       true,
+		MetadataPaths: [][]string{{"title", "en"}},
+	})
+	_, invalidErr := collection.trigramIndexStatements(&vectordata.TrigramIndexOptions{MetadataPaths: [][]string{{" "}}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("trigramIndexStatements: %v", err)
+	}
+	expected := []string{
+		`CREATE INDEX IF NOT EXISTS "idx_docs_content_trgm" ON "public"."docs" USING gin ("content" gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS "idx_docs_metadata_title_en_trgm" ON "public"."docs" USING gin ((jsonb_extract_path_text("metadata", 'title', 'en')) gin_trgm_ops)`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("unexpected statements\nwant: %#v\n got: %#v", expected, statements)
+	}
+	if !errors.Is(invalidErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for an empty path segment, got %v", invalidErr)
+	}
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tx is a transaction of a store. Collection handles bound to it read and
+// write through the transaction, so writes to several collections commit or
+// roll back together.
+type Tx struct {
+	store *PostgresVectorStore
+	tx    pgx.Tx
+	// bound caches the transaction-bound copy of each store whose handles
+	// were bound, e.g. stores returned by ForSchema.
+	bound map[*PostgresVectorStore]*PostgresVectorStore
+}
+
+// RunInTx runs fn in a transaction and commits it once fn returns nil. An
+// error from fn or from any bound handle rolls it back. The tenant from
+// WithTenant applies to the whole transaction. With StoreOptions.Retry set,
+// transient failures rerun fn in a new transaction, so fn should have no
+// effects outside it.
+func (s *PostgresVectorStore) RunInTx(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	return s.withRetry(ctx, func() error {
+		return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+			return fn(ctx, &Tx{store: s, tx: tx, bound: make(map[*PostgresVectorStore]*PostgresVectorStore)})
+		})
+	})
+}
+
+// Collection returns a copy of collection bound to the transaction. The
+// handle must come from this store or a store returned by its ForSchema.
+// Everything the copy does runs in the transaction, including the LIST
+// partitions and archive tables its writes create, and statement retries are
+// left to RunInTx. Index builds that use CREATE INDEX CONCURRENTLY fail with
+// errors.ErrUnsupported. The copy must not be used after fn returns.
+func (t *Tx) Collection(collection vectordata.Collection) (vectordata.Collection, error) {
+	c, ok := collection.(*PostgresCollection)
+	if !ok {
+		return nil, fmt.Errorf("bind collection: %T is not a Postgres collection handle", collection)
+	}
+	if c.store.pool != t.store.pool {
+		return nil, fmt.Errorf("bind collection %q: handle belongs to a store on another pool", c.name)
+	}
+	store, ok := t.bound[c.store]
+	if !ok {
+		store = c.store.boundTo(t.tx)
+		t.bound[c.store] = store
+	}
+	bound := *c
+	bound.store = store
+	return &bound, nil
+}
+
+// boundTo returns a copy of the store whose collection operations run in tx.
+// The copy has its own LIST partition cache, because partitions created in tx
+// disappear if it rolls back.
+func (s *PostgresVectorStore) boundTo(tx pgx.Tx) *PostgresVectorStore {
+	opts := s.opts
+	opts.Retry = nil
+	opts.ReadPool = nil
+	return &PostgresVectorStore{pool: s.pool, opts: opts, statements: s.statements, scopes: s.scopes, tx: tx}
+}
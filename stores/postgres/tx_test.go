@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unusedTx is a transaction for tests that never reach the database.
+type unusedTx struct {
+	pgx.Tx
+}
+
+func TestTx_CollectionBindsHandlesOfTheStore(t *testing.T) {
+	// Arrange
+	docs := newUnitTestCollection(vectordata.DistanceCosine)
+	docs.normalize = true
+	summaries := docs.store.Collection("summaries", 2, vectordata.DistanceL2)
+	tx := &Tx{store: docs.store, bound: make(map[*PostgresVectorStore]*PostgresVectorStore)}
+
+	// Act
+	boundDocs, docsErr := tx.Collection(docs)
+	boundSummaries, summariesErr := tx.Collection(summaries)
+
+	// Assert
+	if docsErr != nil || summariesErr != nil {
+		t.Fatalf("unexpected errors: %v %v", docsErr, summariesErr)
+	}
+	first, second := boundDocs.(*PostgresCollection), boundSummaries.(*PostgresCollection)
+	if first == docs || first.store == docs.store {
+		t.Fatal("expected a copy of the handle on a bound store")
+	}
+	if first.store != second.store {
+		t.Fatal("expected handles of one store to share the bound store")
+	}
+	if first.name != "docs" || !first.normalize || first.store.opts.Retry != nil {
+		t.Fatalf("unexpected bound handle %+v", first)
+	}
+}
+
+func TestTx_CollectionRejectsForeignHandles(t *testing.T) {
+	// Arrange
+	store := &PostgresVectorStore{pool: &pgxpool.Pool{}, opts: DefaultStoreOptions()}
+	other := &PostgresVectorStore{pool: &pgxpool.Pool{}, opts: DefaultStoreOptions()}
+	tx := &Tx{store: store, bound: make(map[*PostgresVectorStore]*PostgresVectorStore)}
+
+	// Act
+	_, poolErr := tx.Collection(other.Collection("docs", 2, vectordata.DistanceCosine))
+	_, typeErr := tx.Collection(struct{ vectordata.Collection }{store.Collection("docs", 2, vectordata.DistanceCosine)})
+
+	// Assert
+	if poolErr == nil || typeErr == nil {
+		t.Fatalf("expected both binds to fail, got %v and %v", poolErr, typeErr)
+	}
+}
+
+func TestBoundCollection_RejectsConcurrentIndexBuilds(t *testing.T) {
+	// Arrange
+	collection := newUnitTestCollection(vectordata.DistanceCosine)
+	tx := &Tx{store: collection.store, tx: unusedTx{}, bound: make(map[*PostgresVectorStore]*PostgresVectorStore)}
+	bound, err := tx.Collection(collection)
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+
+	// Act
+	err = bound.(*PostgresCollection).createIndexConcurrently(t.Context(), "idx_docs", `CREATE INDEX CONCURRENTLY "idx_docs" ON "docs" (id)`)
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
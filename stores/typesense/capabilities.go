@@ -0,0 +1,31 @@
+package typesense
+
+import "github.com/gabisonia/go-vectorstore/vectordata"
+
+// capabilities describes the backend for the store and its collections.
+func capabilities() vectordata.Capabilities {
+	return vectordata.Capabilities{
+		Metrics:      []vectordata.DistanceMetric{vectordata.DistanceCosine, vectordata.DistanceInnerProduct},
+		IndexMethods: []vectordata.IndexMethod{vectordata.IndexMethodHNSW},
+	}
+}
+
+// Capabilities reports the features of the backend.
+func (s *TypesenseVectorStore) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// Capabilities reports the features of the backend.
+func (c *TypesenseCollection) Capabilities() vectordata.Capabilities {
+	return capabilities()
+}
+
+// SupportsFilter returns the error a query with filter would fail with, if
+// any.
+func (c *TypesenseCollection) SupportsFilter(filter vectordata.Filter) error {
+	if filter == nil {
+		return nil
+	}
+	_, err := compileFilterBy(filter)
+	return err
+}
@@ -0,0 +1,133 @@
+package typesense
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type client struct {
+	endpoint string
+	apiKey   string
+	http     *http.Client
+}
+
+// apiError is returned for non-2xx Typesense responses.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("typesense: http %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the vectordata error matching the status, if any.
+func (e *apiError) Unwrap() error {
+	return statusCategory(e.StatusCode)
+}
+
+func statusCategory(status int) error {
+	switch status {
+	case http.StatusConflict:
+		return vectordata.ErrConflict
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return vectordata.ErrTimeout
+	case http.StatusRequestEntityTooLarge:
+		return vectordata.ErrTooLarge
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return vectordata.ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// transportError categorizes a request that got no response.
+func transportError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", vectordata.ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", vectordata.ErrUnavailable, err)
+	}
+}
+
+func newClient(endpoint, apiKey string, httpClient *http.Client) *client {
+	return &client{endpoint: endpoint, apiKey: apiKey, http: httpClient}
+}
+
+// do sends a JSON request and decodes a JSON response into out when out is non-nil.
+func (c *client) do(ctx context.Context, method, path string, body any, out any) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request body: %w", err)
+		}
+		payload = encoded
+	}
+	raw, err := c.doRaw(ctx, method, path, "application/json", payload)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
+}
+
+func (c *client) doRaw(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-TYPESENSE-API-KEY", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &apiError{StatusCode: resp.StatusCode, Message: errorMessage(raw)}
+	}
+	return raw, nil
+}
+
+func errorMessage(raw []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &payload); err == nil && payload.Message != "" {
+		return payload.Message
+	}
+	return strings.TrimSpace(string(raw))
+}
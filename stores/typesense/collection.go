@@ -0,0 +1,434 @@
+package typesense
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type writeMode int
+
+const (
+	writeModeInsert writeMode = iota
+	writeModeUpsert
+)
+
+type document struct {
+	ID       string         `json:"id"`
+	Vector   []float32      `json:"vector,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Content  *string        `json:"content,omitempty"`
+}
+
+type searchHit struct {
+	Document       document `json:"document"`
+	VectorDistance float64  `json:"vector_distance"`
+}
+
+type searchResult struct {
+	Found int64       `json:"found"`
+	Hits  []searchHit `json:"hits"`
+	Code  int         `json:"code"`
+	Error string      `json:"error"`
+}
+
+type multiSearchResponse struct {
+	Results []searchResult `json:"results"`
+}
+
+type searchPlan struct {
+	search     map[string]any
+	projection vectordata.Projection
+}
+
+// TypesenseCollection is a Typesense-backed vector collection.
+type TypesenseCollection struct {
+	store     *TypesenseVectorStore
+	name      string
+	dimension int
+	metric    vectordata.DistanceMetric
+}
+
+func (c *TypesenseCollection) Name() string {
+	return c.name
+}
+
+func (c *TypesenseCollection) Dimension() int {
+	return c.dimension
+}
+
+func (c *TypesenseCollection) Metric() vectordata.DistanceMetric {
+	return c.metric
+}
+
+func (c *TypesenseCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeInsert)
+}
+
+func (c *TypesenseCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeUpsert)
+}
+
+func (c *TypesenseCollection) Get(ctx context.Context, id string) (vectordata.Record, error) {
+	var doc document
+	if err := c.store.client.do(ctx, http.MethodGet, collectionPath(c.name, "documents", url.PathEscape(id)), nil, &doc); err != nil {
+		if isNotFound(err) {
+			return vectordata.Record{}, vectordata.ErrNotFound
+		}
+		return vectordata.Record{}, err
+	}
+	return vectordata.Record{
+		ID:       doc.ID,
+		Vector:   doc.Vector,
+		Metadata: normalizeMetadata(doc.Metadata),
+		Content:  doc.Content,
+	}, nil
+}
+
+func (c *TypesenseCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	batch := make([]string, 0, len(ids))
+	for _, id := range ids {
+		// Backticks cannot be escaped inside filter_by, so such IDs are deleted one by one.
+		if strings.Contains(id, "`") {
+			err := c.store.client.do(ctx, http.MethodDelete, collectionPath(c.name, "documents", url.PathEscape(id)), nil, nil)
+			if err != nil && !isNotFound(err) {
+				return deleted, err
+			}
+			if err == nil {
+				deleted++
+			}
+			continue
+		}
+		batch = append(batch, "`"+id+"`")
+	}
+
+	for start := 0; start < len(batch); start += maxDocumentsPerImport {
+		end := start + maxDocumentsPerImport
+		if end > len(batch) {
+			end = len(batch)
+		}
+		query := url.Values{}
+		query.Set("filter_by", idField+":=["+strings.Join(batch[start:end], ",")+"]")
+
+		var resp struct {
+			NumDeleted int64 `json:"num_deleted"`
+		}
+		if err := c.store.client.do(ctx, http.MethodDelete, collectionPath(c.name, "documents")+"?"+query.Encode(), nil, &resp); err != nil {
+			return deleted, err
+		}
+		deleted += resp.NumDeleted
+	}
+	return deleted, nil
+}
+
+func (c *TypesenseCollection) Count(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	filterBy, err := compileFilterBy(filter)
+	if err != nil {
+		return 0, err
+	}
+	search := map[string]any{
+		"collection": c.name,
+		"q":          "*",
+		"per_page":   0,
+	}
+	if filterBy != "" {
+		search["filter_by"] = filterBy
+	}
+	result, err := c.multiSearch(ctx, search)
+	if err != nil {
+		return 0, err
+	}
+	return result.Found, nil
+}
+
+func (c *TypesenseCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	if opts.Boosted() {
+		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: Typesense cannot page search results with a cursor", errors.ErrUnsupported)
+	}
+	vector, err := vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.buildSearchPlan(vector, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.executeSearchPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	results = vectordata.NormalizeScores(c.metric, results, opts.ScoreNormalization)
+	// Ties in distance are ordered in process.
+	if len(opts.OrderBy) > 0 {
+		if err := vectordata.OrderResults(results, opts.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// EnsureIndexes validates index options. Typesense always builds an HNSW index
+// for vector fields and indexes nested metadata automatically, so only HNSW
+// requests are accepted and they require no work.
+func (c *TypesenseCollection) EnsureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
+	if opts.Vector != nil {
+		method := opts.Vector.Method
+		if method == "" {
+			method = vectordata.IndexMethodHNSW
+		}
+		if method != vectordata.IndexMethodHNSW {
+			return fmt.Errorf("%w: unsupported index method %q", vectordata.ErrSchemaMismatch, method)
+		}
+		if opts.Vector.Metric != "" && opts.Vector.Metric != c.metric {
+			return fmt.Errorf("%w: index metric %q differs from collection metric %q", vectordata.ErrSchemaMismatch, opts.Vector.Metric, c.metric)
+		}
+	}
+	return nil
+}
+
+func (c *TypesenseCollection) buildSearchPlan(vector []float32, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if topK > maxHitsPerPage {
+		return searchPlan{}, fmt.Errorf("topK must be <= %d", maxHitsPerPage)
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+
+	params := []string{"k:" + strconv.Itoa(topK)}
+	if opts.Threshold != nil {
+		params = append(params, "distance_threshold:"+strconv.FormatFloat(nativeDistance(c.metric, *opts.Threshold), 'f', -1, 64))
+	}
+
+	projection := resolveProjection(opts.Projection)
+	search := map[string]any{
+		"collection":   c.name,
+		"q":            "*",
+		"vector_query": fmt.Sprintf("%s:(%s, %s)", vectorField, vectorQueryLiteral(vector), strings.Join(params, ", ")),
+		"per_page":     topK,
+	}
+	if excluded := excludedFields(projection); excluded != "" {
+		search["exclude_fields"] = excluded
+	}
+
+	filterBy, err := compileFilterBy(opts.Filter)
+	if err != nil {
+		return searchPlan{}, err
+	}
+	if filterBy != "" {
+		search["filter_by"] = filterBy
+	}
+
+	return searchPlan{search: search, projection: projection}, nil
+}
+
+func (c *TypesenseCollection) executeSearchPlan(ctx context.Context, plan searchPlan) ([]vectordata.SearchResult, error) {
+	result, err := c.multiSearch(ctx, plan.search)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]vectordata.SearchResult, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		rec := vectordata.Record{ID: hit.Document.ID}
+		if plan.projection.IncludeVector {
+			rec.Vector = hit.Document.Vector
+		}
+		if plan.projection.IncludeMetadata {
+			rec.Metadata = normalizeMetadata(hit.Document.Metadata)
+		}
+		if plan.projection.IncludeContent {
+			rec.Content = hit.Document.Content
+		}
+		distance := normalizeDistance(c.metric, hit.VectorDistance)
+		results = append(results, vectordata.SearchResult{
+			Record:   plan.projection.Redact.Record(rec),
+			Distance: distance,
+			Score:    vectordata.ScoreFromDistance(c.metric, distance),
+		})
+	}
+	return results, nil
+}
+
+// multiSearch runs a single search through /multi_search, which accepts the
+// query in the request body and so avoids URL length limits on vector queries.
+func (c *TypesenseCollection) multiSearch(ctx context.Context, search map[string]any) (searchResult, error) {
+	var resp multiSearchResponse
+	if err := c.store.client.do(ctx, http.MethodPost, "/multi_search", map[string]any{
+		"searches": []map[string]any{search},
+	}, &resp); err != nil {
+		return searchResult{}, err
+	}
+	if len(resp.Results) != 1 {
+		return searchResult{}, fmt.Errorf("typesense: expected 1 search result, got %d", len(resp.Results))
+	}
+	result := resp.Results[0]
+	if result.Error != "" {
+		return searchResult{}, &apiError{StatusCode: result.Code, Message: result.Error}
+	}
+	return result, nil
+}
+
+func (c *TypesenseCollection) writeRecords(ctx context.Context, records []vectordata.Record, mode writeMode) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	for start := 0; start < len(records); start += maxDocumentsPerImport {
+		end := start + maxDocumentsPerImport
+		if end > len(records) {
+			end = len(records)
+		}
+
+		payload, err := c.buildImportBatch(records[start:end])
+		if err != nil {
+			return err
+		}
+		if err := c.importBatch(ctx, records[start:end], payload, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *TypesenseCollection) buildImportBatch(records []vectordata.Record) ([]byte, error) {
+	var b bytes.Buffer
+	for _, record := range records {
+		if strings.TrimSpace(record.ID) == "" {
+			return nil, fmt.Errorf("record id is empty")
+		}
+		if err := c.validateVectorDimension(record.Vector); err != nil {
+			return nil, err
+		}
+
+		line, err := json.Marshal(document{
+			ID:       record.ID,
+			Vector:   record.Vector,
+			Metadata: normalizeMetadata(record.Metadata),
+			Content:  record.Content,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encode metadata for record %q: %w", record.ID, err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+func (c *TypesenseCollection) importBatch(ctx context.Context, records []vectordata.Record, payload []byte, mode writeMode) error {
+	action := "upsert"
+	if mode == writeModeInsert {
+		action = "create"
+	}
+	path := collectionPath(c.name, "documents", "import") + "?action=" + action
+
+	raw, err := c.store.client.doRaw(ctx, http.MethodPost, path, "text/plain", payload)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(raw)+1)
+	for i := 0; scanner.Scan(); i++ {
+		var line struct {
+			Success bool   `json:"success"`
+			Code    int    `json:"code"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("decode import result: %w", err)
+		}
+		if !line.Success {
+			id := ""
+			if i < len(records) {
+				id = records[i].ID
+			}
+			if category := statusCategory(line.Code); category != nil {
+				return fmt.Errorf("%w: write record %q: %s", category, id, line.Error)
+			}
+			return fmt.Errorf("write record %q: %s", id, line.Error)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *TypesenseCollection) validateVectorDimension(vector []float32) error {
+	if len(vector) != c.dimension {
+		return fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, c.dimension, len(vector))
+	}
+	return nil
+}
+
+// nativeDistance converts a pgvector-compatible distance into Typesense units.
+func nativeDistance(metric vectordata.DistanceMetric, distance float64) float64 {
+	if metric == vectordata.DistanceInnerProduct {
+		return distance + 1
+	}
+	return distance
+}
+
+func vectorQueryLiteral(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 8)
+	b.WriteByte('[')
+	for i, n := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(n), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func excludedFields(projection vectordata.Projection) string {
+	excluded := make([]string, 0, 3)
+	if !projection.IncludeVector {
+		excluded = append(excluded, vectorField)
+	}
+	if !projection.IncludeMetadata {
+		excluded = append(excluded, metadataField)
+	}
+	if !projection.IncludeContent {
+		excluded = append(excluded, contentField)
+	}
+	return strings.Join(excluded, ",")
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
+	if projection == nil {
+		return vectordata.DefaultProjection()
+	}
+	return *projection
+}
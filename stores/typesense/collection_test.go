@@ -0,0 +1,276 @@
+package typesense
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type fakeTypesense struct {
+	mu        sync.Mutex
+	documents map[string]map[string]any
+	searches  []map[string]any
+	deletes   []string
+	hits      []map[string]any
+	apiKeys   []string
+}
+
+func newFakeTypesense(t *testing.T) (*fakeTypesense, *TypesenseVectorStore) {
+	t.Helper()
+	fake := &fakeTypesense{documents: map[string]map[string]any{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+
+	opts := DefaultStoreOptions()
+	opts.Endpoint = server.URL
+	opts.APIKey = "secret"
+	store, err := NewVectorStore(opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return fake, store
+}
+
+func (f *fakeTypesense) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.apiKeys = append(f.apiKeys, r.Header.Get("X-TYPESENSE-API-KEY"))
+
+	switch {
+	case r.URL.Path == "/multi_search":
+		var body struct {
+			Searches []map[string]any `json:"searches"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.searches = append(f.searches, body.Searches...)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"results": []map[string]any{{"found": len(f.documents), "hits": f.hits}},
+		})
+	case strings.HasSuffix(r.URL.Path, "/documents/import"):
+		action := r.URL.Query().Get("action")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var doc map[string]any
+			_ = json.Unmarshal(scanner.Bytes(), &doc)
+			id, _ := doc["id"].(string)
+			if _, exists := f.documents[id]; exists && action == "create" {
+				_, _ = w.Write([]byte(`{"success":false,"code":409,"error":"A document with id ` + id + ` already exists."}` + "\n"))
+				continue
+			}
+			f.documents[id] = doc
+			_, _ = w.Write([]byte(`{"success":true}` + "\n"))
+		}
+	case strings.HasSuffix(r.URL.Path, "/documents") && r.Method == http.MethodDelete:
+		filterBy := r.URL.Query().Get("filter_by")
+		f.deletes = append(f.deletes, filterBy)
+		deleted := 0
+		for id := range f.documents {
+			if strings.Contains(filterBy, "`"+id+"`") {
+				delete(f.documents, id)
+				deleted++
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"num_deleted": deleted})
+	default:
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		doc, ok := f.documents[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Could not find a document with id: ` + id + `"}`))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.documents, id)
+		}
+		_ = json.NewEncoder(w).Encode(doc)
+	}
+}
+
+func TestTypesenseCollection_UpsertGetDelete(t *testing.T) {
+	// Arrange
+	fake, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	content := "hello"
+
+	// Act
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "r1", Vector: []float32{1, 0}, Content: &content, Metadata: map[string]any{"category": "news"}},
+		{ID: "odd`id", Vector: []float32{0, 1}},
+	})
+	rec, getErr := collection.Get(ctx, "r1")
+	deleted, deleteErr := collection.Delete(ctx, []string{"r1", "missing", "odd`id"})
+	_, missingErr := collection.Get(ctx, "r1")
+
+	// Assert
+	if upsertErr != nil {
+		t.Fatalf("Upsert: %v", upsertErr)
+	}
+	if getErr != nil {
+		t.Fatalf("Get: %v", getErr)
+	}
+	if rec.ID != "r1" || len(rec.Vector) != 2 || rec.Vector[0] != 1 {
+		t.Fatalf("unexpected record: %#v", rec)
+	}
+	if rec.Content == nil || *rec.Content != "hello" || rec.Metadata["category"] != "news" {
+		t.Fatalf("unexpected record payload: %#v", rec)
+	}
+	if deleteErr != nil || deleted != 2 {
+		t.Fatalf("expected 2 deleted, got %d (%v)", deleted, deleteErr)
+	}
+	if len(fake.deletes) != 1 || fake.deletes[0] != "id:=[`r1`,`missing`]" {
+		t.Fatalf("unexpected delete filters: %#v", fake.deletes)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+	for _, key := range fake.apiKeys {
+		if key != "secret" {
+			t.Fatalf("expected API key header on every request, got %q", key)
+		}
+	}
+}
+
+func TestTypesenseCollection_InsertRejectsExisting(t *testing.T) {
+	// Arrange
+	_, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	record := vectordata.Record{ID: "r1", Vector: []float32{1, 0}}
+	if err := collection.Insert(ctx, []vectordata.Record{record}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	err := collection.Insert(ctx, []vectordata.Record{record})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrConflict) || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected duplicate insert to fail with ErrConflict, got %v", err)
+	}
+}
+
+func TestTypesenseCollection_SearchByVector(t *testing.T) {
+	// Arrange
+	fake, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceInnerProduct)
+	fake.hits = []map[string]any{
+		{"document": map[string]any{"id": "a", "metadata": map[string]any{"kind": "a"}}, "vector_distance": 0.0},
+	}
+	threshold := -0.5
+
+	// Act
+	results, err := collection.SearchByVector(context.Background(), []float32{1, 0.5}, 3, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "a"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(results) != 1 || results[0].Record.ID != "a" || results[0].Record.Metadata["kind"] != "a" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results[0].Distance != -1 || results[0].Score != 1 {
+		t.Fatalf("expected pgvector-compatible inner product distance, got %#v", results[0])
+	}
+	search := fake.searches[0]
+	if search["vector_query"] != "vector:([1,0.5], k:3, distance_threshold:0.5)" {
+		t.Fatalf("unexpected vector_query: %v", search["vector_query"])
+	}
+	if search["filter_by"] != "metadata.kind:=`a`" || search["exclude_fields"] != "vector" {
+		t.Fatalf("unexpected search: %#v", search)
+	}
+}
+
+func TestTypesenseCollection_Count(t *testing.T) {
+	// Arrange
+	fake, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	fake.documents["a"] = map[string]any{"id": "a"}
+	fake.documents["b"] = map[string]any{"id": "b"}
+
+	// Act
+	count, err := collection.Count(context.Background(), vectordata.Gt(vectordata.Metadata("rank"), 1))
+
+	// Assert
+	if err != nil || count != 2 {
+		t.Fatalf("expected count 2, got %d (%v)", count, err)
+	}
+	search := fake.searches[0]
+	if search["per_page"] != float64(0) || search["filter_by"] != "metadata.rank:>1" {
+		t.Fatalf("unexpected count search: %#v", search)
+	}
+}
+
+func TestTypesenseCollection_ValidatesInput(t *testing.T) {
+	// Arrange
+	_, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 3, vectordata.DistanceCosine)
+	ctx := context.Background()
+
+	// Act
+	_, dimErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{})
+	writeErr := collection.Upsert(ctx, []vectordata.Record{{ID: "a", Vector: []float32{1}}})
+	_, topKErr := collection.SearchByVector(ctx, []float32{1, 0, 0}, maxHitsPerPage+1, vectordata.SearchOptions{})
+	indexErr := collection.EnsureIndexes(ctx, vectordata.IndexOptions{Vector: &vectordata.VectorIndexOptions{Method: vectordata.IndexMethodIVFFlat}})
+
+	// Assert
+	if !errors.Is(dimErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from search, got %v", dimErr)
+	}
+	if !errors.Is(writeErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from write, got %v", writeErr)
+	}
+	if topKErr == nil {
+		t.Fatal("expected topK above per_page limit to fail")
+	}
+	if !errors.Is(indexErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for IVFFlat, got %v", indexErr)
+	}
+}
+
+func TestNewVectorStore_RejectsL2Collections(t *testing.T) {
+	// Arrange
+	_, store := newFakeTypesense(t)
+
+	// Act
+	_, err := store.EnsureCollection(context.Background(), vectordata.CollectionSpec{
+		Name:      "docs",
+		Dimension: 2,
+		Metric:    vectordata.DistanceL2,
+	})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func TestTypesenseCollection_Capabilities(t *testing.T) {
+	// Arrange
+	_, store := newFakeTypesense(t)
+	collection := store.Collection("docs", 3, vectordata.DistanceCosine)
+
+	// Act
+	capabilities, reported := vectordata.CapabilitiesOf(collection)
+	existsErr := vectordata.SupportsFilter(collection, vectordata.Exists(vectordata.Metadata("author")))
+	eqErr := vectordata.SupportsFilter(collection, vectordata.Eq(vectordata.Metadata("category"), "news"))
+
+	// Assert
+	if !reported || capabilities.IndexManagement || capabilities.SupportsMetric(vectordata.DistanceL2) {
+		t.Fatalf("unexpected capabilities %+v", capabilities)
+	}
+	if !errors.Is(existsErr, vectordata.ErrInvalidFilter) || eqErr != nil {
+		t.Fatalf("unexpected filter support: %v, %v", existsErr, eqErr)
+	}
+}
@@ -0,0 +1,2 @@
+// Package typesense provides a Typesense-backed vectordata implementation.
+package typesense
@@ -0,0 +1,194 @@
+package typesense
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// compileFilterBy translates a filter AST into a Typesense filter_by expression.
+//
+// Typesense has no general negation, so NOT is pushed down to the leaves with
+// De Morgan's laws and negated comparison operators. A negated operator such
+// as :!= never matches documents without the field, whereas NOT in the other
+// stores does, so NOT over a comparison is rejected unless the field is id,
+// which every document has. Exists has no Typesense equivalent and is
+// rejected.
+func compileFilterBy(filter vectordata.Filter) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	return compileFilterByNode(filter, false)
+}
+
+func compileFilterByNode(f vectordata.Filter, negate bool) (string, error) {
+	switch node := f.(type) {
+	case vectordata.EqFilter:
+		if err := checkNegatable(node.Field, negate); err != nil {
+			return "", err
+		}
+		field, err := filterField(node.Field)
+		if err != nil {
+			return "", err
+		}
+		value, err := filterValue(node.Value)
+		if err != nil {
+			return "", err
+		}
+		op := ":="
+		if negate {
+			op = ":!="
+		}
+		return field + op + value, nil
+	case vectordata.InFilter:
+		if len(node.Values) == 0 {
+			return "", fmt.Errorf("%w: IN requires at least one value", vectordata.ErrInvalidFilter)
+		}
+		if err := checkNegatable(node.Field, negate); err != nil {
+			return "", err
+		}
+		field, err := filterField(node.Field)
+		if err != nil {
+			return "", err
+		}
+		values := make([]string, 0, len(node.Values))
+		for _, v := range node.Values {
+			value, err := filterValue(v)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		op := ":="
+		if negate {
+			op = ":!="
+		}
+		return field + op + "[" + strings.Join(values, ",") + "]", nil
+	case vectordata.GtFilter:
+		if err := checkNegatable(node.Field, negate); err != nil {
+			return "", err
+		}
+		op := ":>"
+		if negate {
+			op = ":<="
+		}
+		return compileFilterByCompare(node.Field, op, node.Value)
+	case vectordata.LtFilter:
+		if err := checkNegatable(node.Field, negate); err != nil {
+			return "", err
+		}
+		op := ":<"
+		if negate {
+			op = ":>="
+		}
+		return compileFilterByCompare(node.Field, op, node.Value)
+	case vectordata.ExistsFilter:
+		return "", fmt.Errorf("%w: EXISTS is not supported by typesense", vectordata.ErrInvalidFilter)
+	case vectordata.AndFilter:
+		if negate {
+			return compileFilterByLogical("||", node.Children, true)
+		}
+		return compileFilterByLogical("&&", node.Children, false)
+	case vectordata.OrFilter:
+		if negate {
+			return compileFilterByLogical("&&", node.Children, true)
+		}
+		return compileFilterByLogical("||", node.Children, false)
+	case vectordata.NotFilter:
+		if node.Child == nil {
+			return "", fmt.Errorf("%w: NOT requires a child", vectordata.ErrInvalidFilter)
+		}
+		return compileFilterByNode(node.Child, !negate)
+	default:
+		return "", fmt.Errorf("%w: unsupported node type %T", vectordata.ErrInvalidFilter, f)
+	}
+}
+
+// checkNegatable rejects a negated comparison on any field but id, since the
+// negated Typesense operators skip documents that lack the field.
+func checkNegatable(ref vectordata.FieldRef, negate bool) error {
+	if !negate {
+		return nil
+	}
+	field, err := filterField(ref)
+	if err != nil {
+		return err
+	}
+	if field == idField {
+		return nil
+	}
+	return fmt.Errorf("%w: NOT over a comparison on %s is not supported by typesense, which would skip documents without the field", vectordata.ErrInvalidFilter, field)
+}
+
+func compileFilterByCompare(ref vectordata.FieldRef, op string, value any) (string, error) {
+	field, err := filterField(ref)
+	if err != nil {
+		return "", err
+	}
+	num, ok := toFloat64(value)
+	if !ok {
+		return "", fmt.Errorf("%w: range comparison requires a numeric value, got %T", vectordata.ErrInvalidFilter, value)
+	}
+	return field + op + strconv.FormatFloat(num, 'f', -1, 64), nil
+}
+
+func compileFilterByLogical(op string, children []vectordata.Filter, negate bool) (string, error) {
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: logical filter requires at least one child", vectordata.ErrInvalidFilter)
+	}
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		if child == nil {
+			return "", fmt.Errorf("%w: logical filter contains nil child", vectordata.ErrInvalidFilter)
+		}
+		part, err := compileFilterByNode(child, negate)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, " "+op+" ") + ")", nil
+}
+
+func filterField(ref vectordata.FieldRef) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		switch field.Name {
+		case idField, contentField:
+			return field.Name, nil
+		default:
+			return "", fmt.Errorf("%w: unknown column %q", vectordata.ErrInvalidFilter, field.Name)
+		}
+	}
+	for _, segment := range field.Path {
+		if !fieldSegmentPattern.MatchString(segment) {
+			return "", fmt.Errorf("%w: metadata path segment %q contains unsupported characters", vectordata.ErrInvalidFilter, segment)
+		}
+	}
+	return metadataField + "." + strings.Join(field.Path, "."), nil
+}
+
+func filterValue(v any) (string, error) {
+	switch typed := v.(type) {
+	case nil:
+		return "", fmt.Errorf("%w: nil values are not supported", vectordata.ErrInvalidFilter)
+	case string:
+		if strings.Contains(typed, "`") {
+			return "", fmt.Errorf("%w: string values cannot contain backticks", vectordata.ErrInvalidFilter)
+		}
+		return "`" + typed + "`", nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	default:
+		num, ok := toFloat64(v)
+		if !ok {
+			return "", fmt.Errorf("%w: unsupported value type %T", vectordata.ErrInvalidFilter, v)
+		}
+		return strconv.FormatFloat(num, 'f', -1, 64), nil
+	}
+}
@@ -0,0 +1,82 @@
+package typesense
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestCompileFilterBy_Complex(t *testing.T) {
+	// Arrange
+	filter := vectordata.And(
+		vectordata.Eq(vectordata.Column("id"), "r1"),
+		vectordata.Or(
+			vectordata.Gt(vectordata.Metadata("rank"), 10),
+			vectordata.In(vectordata.Metadata("flags", "label"), "a", "b"),
+		),
+	)
+
+	// Act
+	filterBy, err := compileFilterBy(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterBy error: %v", err)
+	}
+	expected := "(id:=`r1` && (metadata.rank:>10 || metadata.flags.label:=[`a`,`b`]))"
+	if filterBy != expected {
+		t.Fatalf("unexpected filter_by\nwant: %s\n got: %s", expected, filterBy)
+	}
+}
+
+func TestCompileFilterBy_NotPushesDown(t *testing.T) {
+	// Arrange
+	filter := vectordata.Not(vectordata.And(
+		vectordata.In(vectordata.Column("id"), "a", "b"),
+		vectordata.Not(vectordata.Eq(vectordata.Metadata("pinned"), true)),
+		vectordata.Not(vectordata.Or(
+			vectordata.Lt(vectordata.Metadata("rank"), 5),
+			vectordata.Eq(vectordata.Column("content"), "x"),
+		)),
+	))
+
+	// Act
+	filterBy, err := compileFilterBy(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterBy error: %v", err)
+	}
+	expected := "(id:!=[`a`,`b`] || metadata.pinned:=true || (metadata.rank:<5 || content:=`x`))"
+	if filterBy != expected {
+		t.Fatalf("unexpected filter_by\nwant: %s\n got: %s", expected, filterBy)
+	}
+}
+
+func TestCompileFilterBy_RejectsUnsupported(t *testing.T) {
+	cases := map[string]vectordata.Filter{
+		"exists":    vectordata.Exists(vectordata.Metadata("a")),
+		"backtick":  vectordata.Eq(vectordata.Metadata("a"), "x`y"),
+		"segment":   vectordata.Eq(vectordata.Metadata("a b"), "x"),
+		"range":     vectordata.Gt(vectordata.Metadata("a"), "x"),
+		"column":    vectordata.Eq(vectordata.Column("vector"), "x"),
+		"empty in":  vectordata.In(vectordata.Metadata("a")),
+		"nil value": vectordata.Eq(vectordata.Metadata("a"), nil),
+		"not eq":    vectordata.Not(vectordata.Eq(vectordata.Metadata("a"), "x")),
+		"not in":    vectordata.Not(vectordata.In(vectordata.Column("content"), "x")),
+		"not gt":    vectordata.Not(vectordata.Gt(vectordata.Metadata("a"), 1)),
+		"not lt":    vectordata.Not(vectordata.Or(vectordata.Eq(vectordata.Column("id"), "r1"), vectordata.Lt(vectordata.Metadata("a"), 1))),
+	}
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := compileFilterBy(filter)
+
+			// Assert
+			if !errors.Is(err, vectordata.ErrInvalidFilter) {
+				t.Fatalf("expected ErrInvalidFilter, got %v", err)
+			}
+		})
+	}
+}
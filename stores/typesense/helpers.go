@@ -0,0 +1,109 @@
+package typesense
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	idField       = "id"
+	vectorField   = "vector"
+	metadataField = "metadata"
+	contentField  = "content"
+
+	// maxDocumentsPerImport bounds the JSONL payload of a single import request.
+	maxDocumentsPerImport = 500
+	// maxHitsPerPage is the Typesense per_page ceiling.
+	maxHitsPerPage = 250
+)
+
+var fieldSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func defaultMetric(metric vectordata.DistanceMetric) vectordata.DistanceMetric {
+	if metric == "" {
+		return vectordata.DistanceCosine
+	}
+	return metric
+}
+
+func defaultMode(mode vectordata.EnsureMode, strictByDefault bool) vectordata.EnsureMode {
+	if mode != "" {
+		return mode
+	}
+	if strictByDefault {
+		return vectordata.EnsureStrict
+	}
+	return vectordata.EnsureAutoMigrate
+}
+
+// vectorDistance maps a vectordata metric to a Typesense vec_dist value.
+// Typesense has no euclidean distance.
+func vectorDistance(metric vectordata.DistanceMetric) (string, error) {
+	switch metric {
+	case vectordata.DistanceCosine:
+		return "cosine", nil
+	case vectordata.DistanceInnerProduct:
+		return "ip", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+}
+
+// normalizeDistance converts a Typesense vector_distance into the pgvector-compatible
+// distance used by vectordata.ScoreFromDistance. Typesense reports inner product as
+// 1 - dot, while the shared contract uses -dot.
+func normalizeDistance(metric vectordata.DistanceMetric, distance float64) float64 {
+	if metric == vectordata.DistanceInnerProduct {
+		return distance - 1
+	}
+	return distance
+}
+
+func collectionPath(name string, parts ...string) string {
+	path := "/collections/" + url.PathEscape(name)
+	for _, part := range parts {
+		path += "/" + part
+	}
+	return path
+}
+
+func normalizeMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return map[string]any{}
+	}
+	return metadata
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,128 @@
+package typesense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type fieldSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+	NumDim   int    `json:"num_dim,omitempty"`
+	VecDist  string `json:"vec_dist,omitempty"`
+}
+
+type collectionSchema struct {
+	Name               string        `json:"name"`
+	Fields             []fieldSchema `json:"fields"`
+	EnableNestedFields bool          `json:"enable_nested_fields"`
+}
+
+func (s collectionSchema) field(name string) (fieldSchema, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return fieldSchema{}, false
+}
+
+func (s *TypesenseVectorStore) readCollectionSchema(ctx context.Context, name string) (collectionSchema, bool, error) {
+	var schema collectionSchema
+	err := s.client.do(ctx, http.MethodGet, collectionPath(name), nil, &schema)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return collectionSchema{}, false, nil
+		}
+		return collectionSchema{}, false, fmt.Errorf("read collection schema: %w", err)
+	}
+	return schema, true, nil
+}
+
+func (s *TypesenseVectorStore) createCollection(ctx context.Context, spec vectordata.CollectionSpec) error {
+	dist, err := vectorDistance(spec.Metric)
+	if err != nil {
+		return err
+	}
+	schema := collectionSchema{
+		Name:               spec.Name,
+		EnableNestedFields: true,
+		Fields: []fieldSchema{
+			{Name: vectorField, Type: "float[]", NumDim: spec.Dimension, VecDist: dist},
+			metadataFieldSchema(),
+			contentFieldSchema(),
+		},
+	}
+	if err := s.client.do(ctx, http.MethodPost, "/collections", schema, nil); err != nil {
+		return fmt.Errorf("create collection %q: %w", spec.Name, err)
+	}
+	return nil
+}
+
+func (s *TypesenseVectorStore) validateCollectionSchema(ctx context.Context, schema collectionSchema, spec vectordata.CollectionSpec, mode vectordata.EnsureMode) error {
+	vector, ok := schema.field(vectorField)
+	if !ok {
+		return fmt.Errorf("%w: missing field %q", vectordata.ErrSchemaMismatch, vectorField)
+	}
+	if vector.Type != "float[]" {
+		return fmt.Errorf("%w: expected %q type float[], got %q", vectordata.ErrSchemaMismatch, vectorField, vector.Type)
+	}
+	if vector.NumDim != spec.Dimension {
+		return fmt.Errorf("%w: expected vector dimension %d, got %d", vectordata.ErrSchemaMismatch, spec.Dimension, vector.NumDim)
+	}
+	expectedDist, err := vectorDistance(spec.Metric)
+	if err != nil {
+		return err
+	}
+	actualDist := vector.VecDist
+	if actualDist == "" {
+		actualDist = "cosine"
+	}
+	if actualDist != expectedDist {
+		return fmt.Errorf("%w: expected vec_dist %q, got %q", vectordata.ErrSchemaMismatch, expectedDist, actualDist)
+	}
+
+	missing := make([]fieldSchema, 0, 2)
+	if field, ok := schema.field(metadataField); !ok {
+		if !schema.EnableNestedFields {
+			return fmt.Errorf("%w: collection %q has nested fields disabled", vectordata.ErrSchemaMismatch, spec.Name)
+		}
+		missing = append(missing, metadataFieldSchema())
+	} else if field.Type != "object" {
+		return fmt.Errorf("%w: expected %q type object, got %q", vectordata.ErrSchemaMismatch, metadataField, field.Type)
+	}
+	if field, ok := schema.field(contentField); !ok {
+		missing = append(missing, contentFieldSchema())
+	} else if field.Type != "string" {
+		return fmt.Errorf("%w: expected %q type string, got %q", vectordata.ErrSchemaMismatch, contentField, field.Type)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	if mode == vectordata.EnsureStrict {
+		return fmt.Errorf("%w: missing field %q", vectordata.ErrSchemaMismatch, missing[0].Name)
+	}
+	return s.addFields(ctx, spec.Name, missing)
+}
+
+func (s *TypesenseVectorStore) addFields(ctx context.Context, name string, fields []fieldSchema) error {
+	if err := s.client.do(ctx, http.MethodPatch, collectionPath(name), map[string]any{"fields": fields}, nil); err != nil {
+		return fmt.Errorf("auto-migrate fields: %w", err)
+	}
+	return nil
+}
+
+func metadataFieldSchema() fieldSchema {
+	return fieldSchema{Name: metadataField, Type: "object", Optional: true}
+}
+
+func contentFieldSchema() fieldSchema {
+	return fieldSchema{Name: contentField, Type: "string", Optional: true}
+}
@@ -0,0 +1,134 @@
+package typesense
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// StoreOptions configures TypesenseVectorStore behavior.
+type StoreOptions struct {
+	// Endpoint is the base URL of the Typesense server.
+	Endpoint string
+	// APIKey is sent as X-TYPESENSE-API-KEY on every request.
+	APIKey string
+	// HTTPClient is used for all requests. A client with a 30s timeout is used when nil.
+	HTTPClient      *http.Client
+	StrictByDefault bool
+}
+
+// DefaultStoreOptions returns production-safe defaults.
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		Endpoint:        "http://localhost:8108",
+		StrictByDefault: true,
+	}
+}
+
+// TypesenseVectorStore implements vectordata.VectorStore on top of the Typesense HTTP API.
+type TypesenseVectorStore struct {
+	client *client
+	opts   StoreOptions
+}
+
+// NewVectorStore creates a Typesense-backed vector store.
+func NewVectorStore(opts StoreOptions) (*TypesenseVectorStore, error) {
+	normalized := opts.withDefaults()
+	if err := normalized.validate(); err != nil {
+		return nil, err
+	}
+	return &TypesenseVectorStore{
+		client: newClient(normalized.Endpoint, normalized.APIKey, normalized.HTTPClient),
+		opts:   normalized,
+	}, nil
+}
+
+// Collection returns a handle to a collection without schema checks.
+func (s *TypesenseVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.newCollectionHandle(name, dimension, metric)
+}
+
+// EnsureCollection creates or validates a collection schema and returns its handle.
+func (s *TypesenseVectorStore) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	normalizedSpec, mode, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureCollectionWithValidation(ctx, normalizedSpec, mode); err != nil {
+		return nil, err
+	}
+
+	return s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric), nil
+}
+
+func (s *TypesenseVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, vectordata.EnsureMode, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
+	}
+	if spec.Dimension <= 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	spec.Metric = defaultMetric(spec.Metric)
+	if _, err := vectorDistance(spec.Metric); err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
+	if spec.NormalizeVectors {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: NormalizeVectors is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.ElementType != "" && spec.ElementType != vectordata.ElementFloat32 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: vector element type %q is not supported", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension != 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.MaxRecords != 0 || spec.EvictBy != "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: MaxRecords is not supported", vectordata.ErrSchemaMismatch)
+	}
+
+	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
+	}
+	return spec, mode, nil
+}
+
+func (s *TypesenseVectorStore) ensureCollectionWithValidation(ctx context.Context, spec vectordata.CollectionSpec, mode vectordata.EnsureMode) error {
+	schema, exists, err := s.readCollectionSchema(ctx, spec.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return s.createCollection(ctx, spec)
+	}
+	return s.validateCollectionSchema(ctx, schema, spec, mode)
+}
+
+func (s *TypesenseVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return &TypesenseCollection{
+		store:     s,
+		name:      name,
+		dimension: dimension,
+		metric:    defaultMetric(metric),
+	}
+}
+
+func (o StoreOptions) withDefaults() StoreOptions {
+	o.Endpoint = strings.TrimRight(strings.TrimSpace(o.Endpoint), "/")
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return o
+}
+
+func (o StoreOptions) validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("%w: endpoint is empty", vectordata.ErrSchemaMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package vespa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type client struct {
+	endpoint string
+	http     *http.Client
+}
+
+// apiError is returned for non-2xx Vespa responses.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("vespa: http %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the vectordata error matching the status, if any.
+func (e *apiError) Unwrap() error {
+	return statusCategory(e.StatusCode)
+}
+
+func statusCategory(status int) error {
+	switch status {
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return vectordata.ErrConflict
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return vectordata.ErrTimeout
+	case http.StatusRequestEntityTooLarge:
+		return vectordata.ErrTooLarge
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return vectordata.ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// transportError categorizes a request that got no response.
+func transportError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", vectordata.ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", vectordata.ErrUnavailable, err)
+	}
+}
+
+func newClient(endpoint string, httpClient *http.Client) *client {
+	return &client{endpoint: endpoint, http: httpClient}
+}
+
+// do sends a JSON request and decodes a JSON response into out when out is non-nil.
+func (c *client) do(ctx context.Context, method, path string, body any, out any) error {
+	return c.doRaw(ctx, method, c.endpoint+path, "application/json", body, out)
+}
+
+func (c *client) doRaw(ctx context.Context, method, url, contentType string, body any, out any) error {
+	var reader io.Reader
+	switch payload := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(payload)
+	default:
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode request body: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return transportError(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &apiError{StatusCode: resp.StatusCode, Message: errorMessage(raw)}
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
+}
+
+// errorMessage extracts the most useful message from a Vespa error payload.
+func errorMessage(raw []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+		Root    struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"root"`
+	}
+	if err := json.Unmarshal(raw, &payload); err == nil {
+		if payload.Message != "" {
+			return payload.Message
+		}
+		if len(payload.Root.Errors) > 0 {
+			msgs := make([]string, 0, len(payload.Root.Errors))
+			for _, e := range payload.Root.Errors {
+				msgs = append(msgs, e.Message)
+			}
+			return strings.Join(msgs, "; ")
+		}
+	}
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,506 @@
+package vespa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type writeMode int
+
+const (
+	writeModeInsert writeMode = iota
+	writeModeUpsert
+)
+
+type documentFields struct {
+	RecordID      string             `json:"record_id"`
+	Vector        json.RawMessage    `json:"vector,omitempty"`
+	Metadata      *string            `json:"metadata,omitempty"`
+	Content       *string            `json:"content,omitempty"`
+	MatchFeatures map[string]float64 `json:"matchfeatures,omitempty"`
+}
+
+type documentResponse struct {
+	Fields documentFields `json:"fields"`
+}
+
+type queryHit struct {
+	Relevance float64        `json:"relevance"`
+	Fields    documentFields `json:"fields"`
+}
+
+type queryResponse struct {
+	Root struct {
+		Fields struct {
+			TotalCount int64 `json:"totalCount"`
+		} `json:"fields"`
+		Children []queryHit `json:"children"`
+	} `json:"root"`
+}
+
+type searchPlan struct {
+	body       map[string]any
+	projection vectordata.Projection
+	threshold  *float64
+	hybrid     bool
+}
+
+// VespaCollection is a Vespa-backed vector collection.
+type VespaCollection struct {
+	store     *VespaVectorStore
+	name      string
+	dimension int
+	metric    vectordata.DistanceMetric
+}
+
+var _ vectordata.HybridSearcher = (*VespaCollection)(nil)
+
+func (c *VespaCollection) Name() string {
+	return c.name
+}
+
+func (c *VespaCollection) Dimension() int {
+	return c.dimension
+}
+
+func (c *VespaCollection) Metric() vectordata.DistanceMetric {
+	return c.metric
+}
+
+// Insert writes new records and fails for IDs that already exist.
+// The existence check and put are separate requests, so concurrent writers
+// of the same ID are not serialized.
+func (c *VespaCollection) Insert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeInsert)
+}
+
+func (c *VespaCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	return c.writeRecords(ctx, records, writeModeUpsert)
+}
+
+func (c *VespaCollection) Get(ctx context.Context, id string) (vectordata.Record, error) {
+	var resp documentResponse
+	if err := c.store.client.do(ctx, http.MethodGet, c.documentPath(id), nil, &resp); err != nil {
+		if isNotFound(err) {
+			return vectordata.Record{}, vectordata.ErrNotFound
+		}
+		return vectordata.Record{}, err
+	}
+	return decodeRecord(id, resp.Fields, vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true})
+}
+
+func (c *VespaCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		exists, err := c.exists(ctx, id)
+		if err != nil {
+			return deleted, err
+		}
+		if !exists {
+			continue
+		}
+		if err := c.store.client.do(ctx, http.MethodDelete, c.documentPath(id), nil, nil); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (c *VespaCollection) Count(ctx context.Context, filter vectordata.Filter) (int64, error) {
+	where, err := compileFilterYQL(filter)
+	if err != nil {
+		return 0, err
+	}
+	if where == "" {
+		where = "true"
+	}
+
+	var resp queryResponse
+	if err := c.store.client.do(ctx, http.MethodPost, "/search/", map[string]any{
+		"yql":  fmt.Sprintf("select * from sources %s where %s", c.name, where),
+		"hits": 0,
+	}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Root.Fields.TotalCount, nil
+}
+
+func (c *VespaCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+	}
+	if opts.Boosted() {
+		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: Vespa cannot page search results with a cursor", errors.ErrUnsupported)
+	}
+	vector, err := vectordata.AdjustQueryVector(vector, opts.Negatives)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := c.buildSearchPlan(vector, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.executeSearchPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	results = vectordata.NormalizeScores(c.metric, results, opts.ScoreNormalization)
+	// Ties in distance are ordered in process.
+	if len(opts.OrderBy) > 0 {
+		if err := vectordata.OrderResults(results, opts.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// HybridSearch ranks documents by vector closeness and BM25 text relevance
+// using a Vespa rank profile. The default "hybrid" profile generated by
+// SchemaDefinition is used unless opts.RankProfile is set. Score carries the
+// Vespa relevance, while Distance reports the vector distance.
+func (c *VespaCollection) HybridSearch(ctx context.Context, vector []float32, text string, topK int, opts vectordata.HybridSearchOptions) ([]vectordata.SearchResult, error) {
+	plan, err := c.buildHybridSearchPlan(vector, text, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.executeSearchPlan(ctx, plan)
+}
+
+// EnsureIndexes validates index options. Vespa indexes are declared in the
+// schema, so HNSW and metadata requests are satisfied by SchemaDefinition and
+// IVFFlat is rejected.
+func (c *VespaCollection) EnsureIndexes(ctx context.Context, opts vectordata.IndexOptions) error {
+	if opts.Vector != nil {
+		method := opts.Vector.Method
+		if method == "" {
+			method = vectordata.IndexMethodHNSW
+		}
+		if method != vectordata.IndexMethodHNSW {
+			return fmt.Errorf("%w: unsupported index method %q", vectordata.ErrSchemaMismatch, method)
+		}
+		if opts.Vector.Metric != "" && opts.Vector.Metric != c.metric {
+			return fmt.Errorf("%w: index metric %q differs from collection metric %q", vectordata.ErrSchemaMismatch, opts.Vector.Metric, c.metric)
+		}
+	}
+	return nil
+}
+
+func (c *VespaCollection) buildSearchPlan(vector []float32, topK int, opts vectordata.SearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if err := opts.ScoreNormalization.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+
+	where, err := c.nearestNeighborWhere(topK, opts.Filter, false)
+	if err != nil {
+		return searchPlan{}, err
+	}
+
+	return searchPlan{
+		body: map[string]any{
+			"yql":                                  fmt.Sprintf("select * from sources %s where %s", c.name, where),
+			"hits":                                 topK,
+			"ranking.profile":                      closenessProfile,
+			"input.query(" + queryTensorName + ")": vector,
+			"presentation.format.tensors":          "short-value",
+		},
+		projection: resolveProjection(opts.Projection),
+		threshold:  opts.Threshold,
+	}, nil
+}
+
+func (c *VespaCollection) buildHybridSearchPlan(vector []float32, text string, topK int, opts vectordata.HybridSearchOptions) (searchPlan, error) {
+	if topK <= 0 {
+		return searchPlan{}, fmt.Errorf("topK must be > 0")
+	}
+	if err := c.validateVectorDimension(vector); err != nil {
+		return searchPlan{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return searchPlan{}, fmt.Errorf("hybrid search text is empty")
+	}
+
+	where, err := c.nearestNeighborWhere(topK, opts.Filter, true)
+	if err != nil {
+		return searchPlan{}, err
+	}
+
+	profile := opts.RankProfile
+	if profile == "" {
+		profile = hybridProfile
+	}
+	vectorWeight := opts.VectorWeight
+	if vectorWeight == 0 {
+		vectorWeight = 1
+	}
+	textWeight := opts.TextWeight
+	if textWeight == 0 {
+		textWeight = 1
+	}
+
+	return searchPlan{
+		body: map[string]any{
+			"yql":                                    fmt.Sprintf("select * from sources %s where %s", c.name, where),
+			"hits":                                   topK,
+			"query":                                  text,
+			"ranking.profile":                        profile,
+			"input.query(" + queryTensorName + ")":   vector,
+			"input.query(" + vectorWeightInput + ")": vectorWeight,
+			"input.query(" + textWeightInput + ")":   textWeight,
+			"presentation.format.tensors":            "short-value",
+		},
+		projection: resolveProjection(opts.Projection),
+		hybrid:     true,
+	}, nil
+}
+
+func (c *VespaCollection) nearestNeighborWhere(topK int, filter vectordata.Filter, withText bool) (string, error) {
+	retrieval := fmt.Sprintf("({targetHits:%d}nearestNeighbor(%s, %s))", topK, vectorField, queryTensorName)
+	if withText {
+		retrieval = fmt.Sprintf("(%s or userQuery())", retrieval)
+	}
+	filterYQL, err := compileFilterYQL(filter)
+	if err != nil {
+		return "", err
+	}
+	if filterYQL == "" {
+		return retrieval, nil
+	}
+	return retrieval + " and " + filterYQL, nil
+}
+
+func (c *VespaCollection) executeSearchPlan(ctx context.Context, plan searchPlan) ([]vectordata.SearchResult, error) {
+	var resp queryResponse
+	if err := c.store.client.do(ctx, http.MethodPost, "/search/", plan.body, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]vectordata.SearchResult, 0, len(resp.Root.Children))
+	for _, hit := range resp.Root.Children {
+		rec, err := decodeRecord(hit.Fields.RecordID, hit.Fields, plan.projection)
+		if err != nil {
+			return nil, err
+		}
+		distance := normalizeDistance(c.metric, matchDistance(hit.Fields.MatchFeatures))
+		if plan.threshold != nil && distance > *plan.threshold {
+			continue
+		}
+
+		score := vectordata.ScoreFromDistance(c.metric, distance)
+		if plan.hybrid {
+			score = hit.Relevance
+		}
+		results = append(results, vectordata.SearchResult{
+			Record:   rec,
+			Distance: distance,
+			Score:    score,
+		})
+	}
+	return results, nil
+}
+
+func (c *VespaCollection) writeRecords(ctx context.Context, records []vectordata.Record, mode writeMode) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	payloads := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		payload, err := c.buildDocument(record)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, c.store.opts.MaxConcurrentWrites)
+	for i := range records {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(record vectordata.Record, payload map[string]any) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := c.putDocument(ctx, record.ID, payload, mode); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(records[i], payloads[i])
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (c *VespaCollection) putDocument(ctx context.Context, id string, payload map[string]any, mode writeMode) error {
+	path := c.documentPath(id)
+	if mode == writeModeInsert {
+		path += c.createOnlyQuery()
+	}
+	err := c.store.client.do(ctx, http.MethodPost, path, payload, nil)
+	if mode == writeModeInsert && errors.Is(err, vectordata.ErrConflict) {
+		return fmt.Errorf("record %q already exists: %w", id, err)
+	}
+	if err != nil {
+		return fmt.Errorf("put record %q: %w", id, err)
+	}
+	return nil
+}
+
+func (c *VespaCollection) buildDocument(record vectordata.Record) (map[string]any, error) {
+	if strings.TrimSpace(record.ID) == "" {
+		return nil, fmt.Errorf("record id is empty")
+	}
+	if err := c.validateVectorDimension(record.Vector); err != nil {
+		return nil, err
+	}
+
+	metadata := normalizeMetadata(record.Metadata)
+	metadataPayload, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("encode metadata for record %q: %w", record.ID, err)
+	}
+	kv, num := flattenMetadata(metadata)
+
+	fields := map[string]any{
+		idField:          record.ID,
+		vectorField:      map[string]any{"values": record.Vector},
+		metadataField:    string(metadataPayload),
+		metadataKVField:  kv,
+		metadataNumField: num,
+	}
+	if record.Content != nil {
+		fields[contentField] = *record.Content
+	}
+	return map[string]any{"fields": fields}, nil
+}
+
+func (c *VespaCollection) exists(ctx context.Context, id string) (bool, error) {
+	err := c.store.client.do(ctx, http.MethodGet, c.documentPath(id)+c.fieldSetQuery("[id]"), nil, nil)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (c *VespaCollection) documentPath(id string) string {
+	path := fmt.Sprintf("/document/v1/%s/%s/docid/%s", c.store.opts.Namespace, c.name, url.PathEscape(id))
+	if c.store.opts.Cluster != "" {
+		path += "?cluster=" + url.QueryEscape(c.store.opts.Cluster)
+	}
+	return path
+}
+
+// fieldSetQuery returns the query-string suffix selecting a field set for documentPath.
+func (c *VespaCollection) fieldSetQuery(fieldSet string) string {
+	sep := "?"
+	if c.store.opts.Cluster != "" {
+		sep = "&"
+	}
+	return sep + "fieldSet=" + url.QueryEscape(fieldSet)
+}
+
+// createOnlyQuery returns the query-string suffix for documentPath that makes
+// a put create the document only if it is absent. A conditional put with
+// create=true ignores the condition for a missing document and evaluates it
+// for an existing one, where "false" fails the put with 412, atomically.
+func (c *VespaCollection) createOnlyQuery() string {
+	sep := "?"
+	if c.store.opts.Cluster != "" {
+		sep = "&"
+	}
+	return sep + "condition=false&create=true"
+}
+
+func (c *VespaCollection) validateVectorDimension(vector []float32) error {
+	if len(vector) != c.dimension {
+		return fmt.Errorf("%w: expected %d, got %d", vectordata.ErrDimensionMismatch, c.dimension, len(vector))
+	}
+	return nil
+}
+
+func decodeRecord(id string, fields documentFields, projection vectordata.Projection) (vectordata.Record, error) {
+	rec := vectordata.Record{ID: id}
+	if fields.RecordID != "" {
+		rec.ID = fields.RecordID
+	}
+	if projection.IncludeVector {
+		vector, err := parseTensor(fields.Vector)
+		if err != nil {
+			return vectordata.Record{}, fmt.Errorf("decode vector: %w", err)
+		}
+		rec.Vector = vector
+	}
+	if projection.IncludeMetadata {
+		raw := ""
+		if fields.Metadata != nil {
+			raw = *fields.Metadata
+		}
+		metadata, err := parseMetadata(raw)
+		if err != nil {
+			return vectordata.Record{}, fmt.Errorf("decode metadata: %w", err)
+		}
+		rec.Metadata = metadata
+	}
+	if projection.IncludeContent {
+		rec.Content = fields.Content
+	}
+	return projection.Redact.Record(rec), nil
+}
+
+// matchDistance reads the distance match-feature. Vespa may render the
+// feature name with or without whitespace inside the parentheses.
+func matchDistance(features map[string]float64) float64 {
+	if d, ok := features[distanceFeature]; ok {
+		return d
+	}
+	for name, value := range features {
+		if strings.ReplaceAll(name, " ", "") == distanceFeature {
+			return value
+		}
+	}
+	return 0
+}
+
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func resolveProjection(projection *vectordata.Projection) vectordata.Projection {
+	if projection == nil {
+		return vectordata.DefaultProjection()
+	}
+	return *projection
+}
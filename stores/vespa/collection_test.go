@@ -0,0 +1,281 @@
+package vespa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type fakeVespa struct {
+	mu        sync.Mutex
+	documents map[string]map[string]any
+	queries   []map[string]any
+	hits      []map[string]any
+}
+
+func newFakeVespa(t *testing.T) (*fakeVespa, *VespaVectorStore) {
+	t.Helper()
+	fake := &fakeVespa{documents: map[string]map[string]any{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+
+	opts := DefaultStoreOptions()
+	opts.Endpoint = server.URL
+	store, err := NewVectorStore(opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return fake, store
+}
+
+func (f *fakeVespa) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/search/" {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.queries = append(f.queries, body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"root": map[string]any{
+				"fields":   map[string]any{"totalCount": len(f.documents)},
+				"children": f.hits,
+			},
+		})
+		return
+	}
+
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	switch r.Method {
+	case http.MethodGet:
+		doc, ok := f.documents[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"fields": doc})
+	case http.MethodPost:
+		var body struct {
+			Fields map[string]any `json:"fields"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := f.documents[id]; ok && r.URL.Query().Get("condition") == "false" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte(`{"message":"condition not met"}`))
+			return
+		}
+		f.documents[id] = body.Fields
+		_, _ = w.Write([]byte(`{}`))
+	case http.MethodDelete:
+		delete(f.documents, id)
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+func TestVespaCollection_UpsertGetDelete(t *testing.T) {
+	// Arrange
+	_, store := newFakeVespa(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	content := "hello"
+
+	// Act
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{{
+		ID:       "r1",
+		Vector:   []float32{1, 0},
+		Content:  &content,
+		Metadata: map[string]any{"category": "news", "rank": 3, "flags": map[string]any{"pinned": true}},
+	}})
+	rec, getErr := collection.Get(ctx, "r1")
+	deleted, deleteErr := collection.Delete(ctx, []string{"r1", "missing"})
+	_, missingErr := collection.Get(ctx, "r1")
+
+	// Assert
+	if upsertErr != nil {
+		t.Fatalf("Upsert: %v", upsertErr)
+	}
+	if getErr != nil {
+		t.Fatalf("Get: %v", getErr)
+	}
+	if rec.ID != "r1" || len(rec.Vector) != 2 || rec.Vector[0] != 1 {
+		t.Fatalf("unexpected record: %#v", rec)
+	}
+	if rec.Content == nil || *rec.Content != "hello" {
+		t.Fatalf("unexpected content: %#v", rec.Content)
+	}
+	if rec.Metadata["category"] != "news" {
+		t.Fatalf("unexpected metadata: %#v", rec.Metadata)
+	}
+	if deleteErr != nil || deleted != 1 {
+		t.Fatalf("expected 1 deleted, got %d (%v)", deleted, deleteErr)
+	}
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestVespaCollection_WritesFilterAttributes(t *testing.T) {
+	// Arrange
+	fake, store := newFakeVespa(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{
+		ID:       "r1",
+		Vector:   []float32{1, 0},
+		Metadata: map[string]any{"category": "news", "rank": 3, "flags": map[string]any{"pinned": true}},
+	}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	doc := fake.documents["r1"]
+	kv, _ := doc[metadataKVField].(map[string]any)
+	num, _ := doc[metadataNumField].(map[string]any)
+	if kv["category"] != "news" || kv["flags.pinned"] != "true" || kv["rank"] != "3" {
+		t.Fatalf("unexpected metadata_kv: %#v", kv)
+	}
+	if num["rank"] != float64(3) {
+		t.Fatalf("unexpected metadata_num: %#v", num)
+	}
+}
+
+func TestVespaCollection_InsertRejectsExisting(t *testing.T) {
+	// Arrange
+	_, store := newFakeVespa(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	ctx := context.Background()
+	record := vectordata.Record{ID: "r1", Vector: []float32{1, 0}}
+	if err := collection.Insert(ctx, []vectordata.Record{record}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// Act
+	err := collection.Insert(ctx, []vectordata.Record{record})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestVespaCollection_SearchByVector(t *testing.T) {
+	// Arrange
+	fake, store := newFakeVespa(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceCosine)
+	fake.hits = []map[string]any{
+		{"relevance": 0.9, "fields": map[string]any{"record_id": "a", "metadata": `{"kind":"a"}`, "matchfeatures": map[string]any{"distance(field,vector)": 0.0}}},
+		{"relevance": 0.5, "fields": map[string]any{"record_id": "b", "metadata": `{"kind":"b"}`, "matchfeatures": map[string]any{"distance(field,vector)": math.Pi / 2}}},
+	}
+	threshold := 0.5
+
+	// Act
+	results, err := collection.SearchByVector(context.Background(), []float32{1, 0}, 2, vectordata.SearchOptions{
+		Filter:    vectordata.Eq(vectordata.Metadata("kind"), "a"),
+		Threshold: &threshold,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(results) != 1 || results[0].Record.ID != "a" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results[0].Score != 1 || results[0].Record.Metadata["kind"] != "a" {
+		t.Fatalf("unexpected result values: %#v", results[0])
+	}
+	query := fake.queries[0]
+	expectedYQL := `select * from sources docs where ({targetHits:2}nearestNeighbor(vector, q)) and (metadata_kv contains sameElement(key contains "kind", value contains "a"))`
+	if query["yql"] != expectedYQL {
+		t.Fatalf("unexpected YQL\nwant: %s\n got: %s", expectedYQL, query["yql"])
+	}
+	if query["ranking.profile"] != closenessProfile {
+		t.Fatalf("unexpected rank profile: %v", query["ranking.profile"])
+	}
+}
+
+func TestVespaCollection_HybridSearchUsesRankProfile(t *testing.T) {
+	// Arrange
+	fake, store := newFakeVespa(t)
+	collection := store.Collection("docs", 2, vectordata.DistanceL2).(*VespaCollection)
+	fake.hits = []map[string]any{
+		{"relevance": 7.5, "fields": map[string]any{"record_id": "a", "matchfeatures": map[string]any{"distance(field,vector)": 1.0}}},
+	}
+
+	// Act
+	results, err := collection.HybridSearch(context.Background(), []float32{1, 0}, "cloud costs", 5, vectordata.HybridSearchOptions{
+		VectorWeight: 0.3,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("HybridSearch: %v", err)
+	}
+	if len(results) != 1 || results[0].Score != 7.5 || results[0].Distance != 1 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	query := fake.queries[0]
+	if query["ranking.profile"] != hybridProfile || query["query"] != "cloud costs" {
+		t.Fatalf("unexpected query: %#v", query)
+	}
+	if query["input.query(vector_weight)"] != 0.3 || query["input.query(text_weight)"] != float64(1) {
+		t.Fatalf("unexpected weights: %#v", query)
+	}
+	if !strings.Contains(query["yql"].(string), "or userQuery()") {
+		t.Fatalf("expected userQuery in YQL: %s", query["yql"])
+	}
+}
+
+func TestVespaCollection_DimensionMismatch(t *testing.T) {
+	// Arrange
+	_, store := newFakeVespa(t)
+	collection := store.Collection("docs", 3, vectordata.DistanceCosine)
+
+	// Act
+	_, searchErr := collection.SearchByVector(context.Background(), []float32{1, 0}, 1, vectordata.SearchOptions{})
+	writeErr := collection.Upsert(context.Background(), []vectordata.Record{{ID: "a", Vector: []float32{1}}})
+
+	// Assert
+	if !errors.Is(searchErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from search, got %v", searchErr)
+	}
+	if !errors.Is(writeErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch from write, got %v", writeErr)
+	}
+}
+
+func TestValidateSchemaDefinition(t *testing.T) {
+	// Arrange
+	spec := vectordata.CollectionSpec{Name: "docs", Dimension: 4, Metric: vectordata.DistanceL2}
+	sd, err := SchemaDefinition(spec)
+	if err != nil {
+		t.Fatalf("SchemaDefinition: %v", err)
+	}
+
+	// Act
+	okErr := validateSchemaDefinition(sd, spec)
+	dimErr := validateSchemaDefinition(sd, vectordata.CollectionSpec{Name: "docs", Dimension: 8, Metric: vectordata.DistanceL2})
+	metricErr := validateSchemaDefinition(sd, vectordata.CollectionSpec{Name: "docs", Dimension: 4, Metric: vectordata.DistanceCosine})
+
+	// Assert
+	if okErr != nil {
+		t.Fatalf("expected generated schema to validate, got %v", okErr)
+	}
+	if !errors.Is(dimErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected dimension mismatch, got %v", dimErr)
+	}
+	if !errors.Is(metricErr, vectordata.ErrSchemaMismatch) {
+		t.Fatalf("expected metric mismatch, got %v", metricErr)
+	}
+}
@@ -0,0 +1,2 @@
+// Package vespa provides a Vespa-backed vectordata implementation.
+package vespa
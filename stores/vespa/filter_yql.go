@@ -0,0 +1,161 @@
+package vespa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// compileFilterYQL translates a filter AST into a YQL boolean expression.
+//
+// Metadata is matched through the metadata_kv (string) and metadata_num
+// (numeric) map attributes written alongside each document, keyed by the
+// "."-joined metadata path.
+func compileFilterYQL(filter vectordata.Filter) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	return compileYQLNode(filter)
+}
+
+func compileYQLNode(f vectordata.Filter) (string, error) {
+	switch node := f.(type) {
+	case vectordata.EqFilter:
+		return compileYQLEq(node.Field, node.Value)
+	case vectordata.InFilter:
+		if len(node.Values) == 0 {
+			return "", fmt.Errorf("%w: IN requires at least one value", vectordata.ErrInvalidFilter)
+		}
+		parts := make([]string, 0, len(node.Values))
+		for _, v := range node.Values {
+			part, err := compileYQLEq(node.Field, v)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "(" + strings.Join(parts, " or ") + ")", nil
+	case vectordata.GtFilter:
+		return compileYQLCompare(node.Field, ">", node.Value)
+	case vectordata.LtFilter:
+		return compileYQLCompare(node.Field, "<", node.Value)
+	case vectordata.ExistsFilter:
+		return compileYQLExists(node.Field)
+	case vectordata.AndFilter:
+		return compileYQLLogical("and", node.Children)
+	case vectordata.OrFilter:
+		return compileYQLLogical("or", node.Children)
+	case vectordata.NotFilter:
+		if node.Child == nil {
+			return "", fmt.Errorf("%w: NOT requires a child", vectordata.ErrInvalidFilter)
+		}
+		child, err := compileYQLNode(node.Child)
+		if err != nil {
+			return "", err
+		}
+		// YQL only supports negation as "and !", so anchor it on a match-all term.
+		return fmt.Sprintf("(true and !%s)", child), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported node type %T", vectordata.ErrInvalidFilter, f)
+	}
+}
+
+func compileYQLEq(ref vectordata.FieldRef, value any) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if field.Kind == vectordata.FieldColumn {
+		name, err := columnField(field.Name)
+		if err != nil {
+			return "", err
+		}
+		text, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: column %q requires a string value", vectordata.ErrInvalidFilter, field.Name)
+		}
+		return fmt.Sprintf("(%s contains %s)", name, yqlString(text)), nil
+	}
+
+	key := yqlString(metadataKey(field.Path))
+	switch typed := value.(type) {
+	case nil:
+		return "", fmt.Errorf("%w: nil metadata values are not supported", vectordata.ErrInvalidFilter)
+	case string:
+		return fmt.Sprintf("(%s contains sameElement(key contains %s, value contains %s))", metadataKVField, key, yqlString(typed)), nil
+	case bool:
+		return fmt.Sprintf("(%s contains sameElement(key contains %s, value contains %s))", metadataKVField, key, yqlString(strconv.FormatBool(typed))), nil
+	default:
+		num, ok := toFloat64(value)
+		if !ok {
+			return "", fmt.Errorf("%w: unsupported metadata value type %T", vectordata.ErrInvalidFilter, value)
+		}
+		return fmt.Sprintf("(%s contains sameElement(key contains %s, value = %s))", metadataNumField, key, formatNumber(num)), nil
+	}
+}
+
+func compileYQLCompare(ref vectordata.FieldRef, op string, value any) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		return "", fmt.Errorf("%w: range comparison on column %q is not supported", vectordata.ErrInvalidFilter, field.Name)
+	}
+	num, ok := toFloat64(value)
+	if !ok {
+		return "", fmt.Errorf("%w: range comparison requires a numeric value, got %T", vectordata.ErrInvalidFilter, value)
+	}
+	return fmt.Sprintf("(%s contains sameElement(key contains %s, value %s %s))", metadataNumField, yqlString(metadataKey(field.Path)), op, formatNumber(num)), nil
+}
+
+func compileYQLExists(ref vectordata.FieldRef) (string, error) {
+	field, err := vectordata.NormalizeFieldRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind == vectordata.FieldColumn {
+		if field.Name == "id" {
+			return "true", nil
+		}
+		return "", fmt.Errorf("%w: exists on column %q is not supported", vectordata.ErrInvalidFilter, field.Name)
+	}
+	return fmt.Sprintf("(%s contains sameElement(key contains %s))", metadataKVField, yqlString(metadataKey(field.Path))), nil
+}
+
+func compileYQLLogical(op string, children []vectordata.Filter) (string, error) {
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: %s requires at least one child", vectordata.ErrInvalidFilter, strings.ToUpper(op))
+	}
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		if child == nil {
+			return "", fmt.Errorf("%w: %s contains nil child", vectordata.ErrInvalidFilter, strings.ToUpper(op))
+		}
+		part, err := compileYQLNode(child)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, " "+op+" ") + ")", nil
+}
+
+// columnField maps logical column names to Vespa document fields.
+func columnField(name string) (string, error) {
+	switch name {
+	case "id":
+		return idField, nil
+	case contentField:
+		return contentField, nil
+	default:
+		return "", fmt.Errorf("%w: unknown column %q", vectordata.ErrInvalidFilter, name)
+	}
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
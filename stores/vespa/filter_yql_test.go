@@ -0,0 +1,89 @@
+package vespa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+func TestCompileFilterYQL_Complex(t *testing.T) {
+	// Arrange
+	filter := vectordata.And(
+		vectordata.Eq(vectordata.Column("id"), "r1"),
+		vectordata.Or(
+			vectordata.Gt(vectordata.Metadata("rank"), 10),
+			vectordata.Exists(vectordata.Metadata("flags", "pinned")),
+		),
+	)
+
+	// Act
+	yql, err := compileFilterYQL(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterYQL error: %v", err)
+	}
+	expected := `((record_id contains "r1") and ((metadata_num contains sameElement(key contains "rank", value > 10)) or (metadata_kv contains sameElement(key contains "flags.pinned"))))`
+	if yql != expected {
+		t.Fatalf("unexpected YQL\nwant: %s\n got: %s", expected, yql)
+	}
+}
+
+func TestCompileFilterYQL_InAndNot(t *testing.T) {
+	// Arrange
+	filter := vectordata.Not(vectordata.In(vectordata.Metadata("category"), "a", true, 3))
+
+	// Act
+	yql, err := compileFilterYQL(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterYQL error: %v", err)
+	}
+	expected := `(true and !((metadata_kv contains sameElement(key contains "category", value contains "a")) or (metadata_kv contains sameElement(key contains "category", value contains "true")) or (metadata_num contains sameElement(key contains "category", value = 3))))`
+	if yql != expected {
+		t.Fatalf("unexpected YQL\nwant: %s\n got: %s", expected, yql)
+	}
+}
+
+func TestCompileFilterYQL_EscapesStrings(t *testing.T) {
+	// Arrange
+	filter := vectordata.Eq(vectordata.Metadata("title"), `say "hi" \ bye`)
+
+	// Act
+	yql, err := compileFilterYQL(filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("compileFilterYQL error: %v", err)
+	}
+	expected := `(metadata_kv contains sameElement(key contains "title", value contains "say \"hi\" \\ bye"))`
+	if yql != expected {
+		t.Fatalf("unexpected YQL\nwant: %s\n got: %s", expected, yql)
+	}
+}
+
+func TestCompileFilterYQL_InvalidFilters(t *testing.T) {
+	cases := map[string]vectordata.Filter{
+		"unknown column":     vectordata.Eq(vectordata.Column("unknown"), "x"),
+		"range on column":    vectordata.Gt(vectordata.Column("id"), "x"),
+		"non-numeric range":  vectordata.Lt(vectordata.Metadata("rank"), "ten"),
+		"nil value":          vectordata.Eq(vectordata.Metadata("rank"), nil),
+		"empty in":           vectordata.In(vectordata.Metadata("rank")),
+		"empty and":          vectordata.And(),
+		"whitespace segment": vectordata.Exists(vectordata.Metadata("a", "  ")),
+	}
+
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := compileFilterYQL(filter)
+
+			// Assert
+			if !errors.Is(err, vectordata.ErrInvalidFilter) {
+				t.Fatalf("expected ErrInvalidFilter, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	idField          = "record_id"
+	vectorField      = "vector"
+	metadataField    = "metadata"
+	contentField     = "content"
+	metadataKVField  = "metadata_kv"
+	metadataNumField = "metadata_num"
+
+	queryTensorName   = "q"
+	closenessProfile  = "closeness"
+	hybridProfile     = "hybrid"
+	vectorWeightInput = "vector_weight"
+	textWeightInput   = "text_weight"
+	distanceFeature   = "distance(field,vector)"
+)
+
+var documentTypePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func isValidDocumentType(name string) bool {
+	return documentTypePattern.MatchString(name)
+}
+
+func defaultMetric(metric vectordata.DistanceMetric) vectordata.DistanceMetric {
+	if metric == "" {
+		return vectordata.DistanceCosine
+	}
+	return metric
+}
+
+func defaultMode(mode vectordata.EnsureMode, strictByDefault bool) vectordata.EnsureMode {
+	if mode != "" {
+		return mode
+	}
+	if strictByDefault {
+		return vectordata.EnsureStrict
+	}
+	return vectordata.EnsureAutoMigrate
+}
+
+// distanceMetric maps a vectordata metric to a Vespa tensor distance-metric.
+func distanceMetric(metric vectordata.DistanceMetric) (string, error) {
+	switch metric {
+	case vectordata.DistanceCosine:
+		return "angular", nil
+	case vectordata.DistanceL2:
+		return "euclidean", nil
+	case vectordata.DistanceInnerProduct:
+		return "dotproduct", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported distance metric %q", vectordata.ErrSchemaMismatch, metric)
+	}
+}
+
+// normalizeDistance converts a Vespa distance into the pgvector-compatible distance
+// used by vectordata.ScoreFromDistance. Vespa reports angular distance as the angle
+// in radians, while the shared contract uses 1 - cosine similarity.
+func normalizeDistance(metric vectordata.DistanceMetric, distance float64) float64 {
+	if metric == vectordata.DistanceCosine {
+		return 1 - math.Cos(distance)
+	}
+	return distance
+}
+
+func yqlString(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(v) + `"`
+}
+
+// flattenMetadata produces the string and numeric attribute maps used for filtering.
+// Nested objects are flattened with "." separators; arrays are not indexed.
+func flattenMetadata(metadata map[string]any) (map[string]string, map[string]float64) {
+	kv := map[string]string{}
+	num := map[string]float64{}
+	flattenInto("", metadata, kv, num)
+	return kv, num
+}
+
+func flattenInto(prefix string, value map[string]any, kv map[string]string, num map[string]float64) {
+	for key, v := range value {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch typed := v.(type) {
+		case map[string]any:
+			flattenInto(name, typed, kv, num)
+		case string:
+			kv[name] = typed
+		case bool:
+			kv[name] = strconv.FormatBool(typed)
+		case json.Number:
+			if f, err := typed.Float64(); err == nil {
+				kv[name] = typed.String()
+				num[name] = f
+			}
+		default:
+			if f, ok := toFloat64(typed); ok {
+				kv[name] = strconv.FormatFloat(f, 'g', -1, 64)
+				num[name] = f
+			}
+		}
+	}
+}
+
+func metadataKey(path []string) string {
+	return strings.Join(path, ".")
+}
+
+func normalizeMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return map[string]any{}
+	}
+	return metadata
+}
+
+func parseMetadata(raw string) (map[string]any, error) {
+	if strings.TrimSpace(raw) == "" {
+		return map[string]any{}, nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return map[string]any{}, nil
+	}
+	return out, nil
+}
+
+// parseTensor decodes an indexed tensor in either short ("values") or
+// verbose ("cells") JSON form.
+func parseTensor(raw json.RawMessage) ([]float32, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var values []float64
+	if err := json.Unmarshal(raw, &values); err == nil {
+		return toFloat32s(values), nil
+	}
+
+	var tensor struct {
+		Values []float64 `json:"values"`
+		Cells  []struct {
+			Address map[string]string `json:"address"`
+			Value   float64           `json:"value"`
+		} `json:"cells"`
+	}
+	if err := json.Unmarshal(raw, &tensor); err != nil {
+		return nil, fmt.Errorf("invalid tensor value: %w", err)
+	}
+	if tensor.Values != nil {
+		return toFloat32s(tensor.Values), nil
+	}
+
+	type cell struct {
+		index int
+		value float64
+	}
+	cells := make([]cell, 0, len(tensor.Cells))
+	for _, c := range tensor.Cells {
+		idx, err := strconv.Atoi(c.Address["x"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tensor cell address %v", c.Address)
+		}
+		cells = append(cells, cell{index: idx, value: c.Value})
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i].index < cells[j].index })
+	out := make([]float32, len(cells))
+	for i, c := range cells {
+		out[i] = float32(c.value)
+	}
+	return out, nil
+}
+
+func toFloat32s(values []float64) []float32 {
+	out := make([]float32, len(values))
+	for i, v := range values {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,243 @@
+package vespa
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var (
+	schemaTensorPattern = regexp.MustCompile(`field\s+vector\s+type\s+tensor<float>\(x\[(\d+)\]\)`)
+	schemaMetricPattern = regexp.MustCompile(`distance-metric:\s*([a-z_-]+)`)
+)
+
+// SchemaDefinition renders the Vespa schema (.sd) for a collection spec.
+//
+// The generated document type stores the record ID, an HNSW-indexed dense
+// tensor, the metadata JSON blob, and content with BM25 enabled. Top-level and
+// nested scalar metadata values are mirrored into map attributes so filters can
+// be evaluated by Vespa. Two rank profiles are defined: "closeness" for pure
+// vector search and "hybrid" for vector + BM25 fusion.
+func SchemaDefinition(spec vectordata.CollectionSpec) (string, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if !isValidDocumentType(spec.Name) {
+		return "", fmt.Errorf("%w: collection name %q is not a valid Vespa document type", vectordata.ErrSchemaMismatch, spec.Name)
+	}
+	if spec.Dimension <= 0 {
+		return "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	metric, err := distanceMetric(defaultMetric(spec.Metric))
+	if err != nil {
+		return "", err
+	}
+
+	tensorType := fmt.Sprintf("tensor<float>(x[%d])", spec.Dimension)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema %s {\n", spec.Name)
+	fmt.Fprintf(&b, "    document %s {\n", spec.Name)
+	fmt.Fprintf(&b, "        field %s type string {\n", idField)
+	b.WriteString("            indexing: summary | attribute\n")
+	b.WriteString("            attribute: fast-search\n")
+	b.WriteString("        }\n")
+	fmt.Fprintf(&b, "        field %s type %s {\n", vectorField, tensorType)
+	b.WriteString("            indexing: summary | attribute | index\n")
+	b.WriteString("            attribute {\n")
+	fmt.Fprintf(&b, "                distance-metric: %s\n", metric)
+	b.WriteString("            }\n")
+	b.WriteString("            index {\n")
+	b.WriteString("                hnsw {\n")
+	b.WriteString("                    max-links-per-node: 16\n")
+	b.WriteString("                    neighbors-to-explore-at-insert: 200\n")
+	b.WriteString("                }\n")
+	b.WriteString("            }\n")
+	b.WriteString("        }\n")
+	fmt.Fprintf(&b, "        field %s type string {\n", metadataField)
+	b.WriteString("            indexing: summary\n")
+	b.WriteString("        }\n")
+	for _, mapField := range []struct{ name, valueType string }{
+		{metadataKVField, "string"},
+		{metadataNumField, "double"},
+	} {
+		fmt.Fprintf(&b, "        field %s type map<string, %s> {\n", mapField.name, mapField.valueType)
+		b.WriteString("            indexing: summary\n")
+		b.WriteString("            struct-field key { indexing: attribute }\n")
+		b.WriteString("            struct-field value { indexing: attribute }\n")
+		b.WriteString("        }\n")
+	}
+	fmt.Fprintf(&b, "        field %s type string {\n", contentField)
+	b.WriteString("            indexing: summary | index\n")
+	b.WriteString("            index: enable-bm25\n")
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+	b.WriteString("    fieldset default {\n")
+	fmt.Fprintf(&b, "        fields: %s\n", contentField)
+	b.WriteString("    }\n")
+	fmt.Fprintf(&b, "    rank-profile %s {\n", closenessProfile)
+	b.WriteString("        inputs {\n")
+	fmt.Fprintf(&b, "            query(%s) %s\n", queryTensorName, tensorType)
+	b.WriteString("        }\n")
+	b.WriteString("        first-phase {\n")
+	fmt.Fprintf(&b, "            expression: closeness(field, %s)\n", vectorField)
+	b.WriteString("        }\n")
+	fmt.Fprintf(&b, "        match-features: distance(field, %s)\n", vectorField)
+	b.WriteString("    }\n")
+	fmt.Fprintf(&b, "    rank-profile %s inherits %s {\n", hybridProfile, closenessProfile)
+	b.WriteString("        inputs {\n")
+	fmt.Fprintf(&b, "            query(%s) %s\n", queryTensorName, tensorType)
+	fmt.Fprintf(&b, "            query(%s): 1.0\n", vectorWeightInput)
+	fmt.Fprintf(&b, "            query(%s): 1.0\n", textWeightInput)
+	b.WriteString("        }\n")
+	b.WriteString("        first-phase {\n")
+	fmt.Fprintf(&b, "            expression: query(%s) * closeness(field, %s) + query(%s) * bm25(%s)\n", vectorWeightInput, vectorField, textWeightInput, contentField)
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// ApplicationPackage builds a deployable single-node application package (zip)
+// containing a schema for each spec. It is intended for development and tests;
+// production applications usually merge SchemaDefinition output into their own package.
+func ApplicationPackage(specs ...vectordata.CollectionSpec) ([]byte, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%w: at least one collection spec is required", vectordata.ErrSchemaMismatch)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	var documents strings.Builder
+	for _, spec := range specs {
+		sd, err := SchemaDefinition(spec)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(spec.Name)
+		if err := writeZipFile(zw, "schemas/"+name+".sd", sd); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&documents, "      <document type=\"%s\" mode=\"index\"/>\n", name)
+	}
+
+	services := `<?xml version="1.0" encoding="utf-8" ?>
+<services version="1.0">
+  <container id="default" version="1.0">
+    <document-api/>
+    <search/>
+  </container>
+  <content id="content" version="1.0">
+    <min-redundancy>1</min-redundancy>
+    <documents>
+` + documents.String() + `    </documents>
+    <nodes>
+      <node hostalias="node1" distribution-key="0"/>
+    </nodes>
+  </content>
+</services>
+`
+	if err := writeZipFile(zw, "services.xml", services); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("finalize application package: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name, content string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("add %s to application package: %w", name, err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		return fmt.Errorf("write %s to application package: %w", name, err)
+	}
+	return nil
+}
+
+// ensureDocumentType verifies that the collection's document type is deployed.
+func (s *VespaVectorStore) ensureDocumentType(ctx context.Context, spec vectordata.CollectionSpec) error {
+	if s.opts.ConfigEndpoint != "" {
+		return s.validateDeployedSchema(ctx, spec)
+	}
+
+	var resp queryResponse
+	err := s.client.do(ctx, http.MethodPost, "/search/", map[string]any{
+		"yql":  fmt.Sprintf("select * from sources %s where true", spec.Name),
+		"hits": 0,
+	}, &resp)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			return fmt.Errorf("%w: document type %q is not deployed: %s", vectordata.ErrSchemaMismatch, spec.Name, apiErr.Message)
+		}
+		return fmt.Errorf("probe document type %q: %w", spec.Name, err)
+	}
+	return nil
+}
+
+func (s *VespaVectorStore) validateDeployedSchema(ctx context.Context, spec vectordata.CollectionSpec) error {
+	url := fmt.Sprintf(
+		"%s/application/v2/tenant/default/application/default/environment/prod/region/default/instance/default/content/schemas/%s.sd",
+		s.opts.ConfigEndpoint,
+		spec.Name,
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("read deployed schema %q: %w", spec.Name, transportError(err))
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read deployed schema %q: %w", spec.Name, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: schema %q is not deployed", vectordata.ErrSchemaMismatch, spec.Name)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("read deployed schema %q: %w", spec.Name, &apiError{StatusCode: resp.StatusCode, Message: errorMessage(raw)})
+	}
+	return validateSchemaDefinition(string(raw), spec)
+}
+
+func validateSchemaDefinition(sd string, spec vectordata.CollectionSpec) error {
+	match := schemaTensorPattern.FindStringSubmatch(sd)
+	if match == nil {
+		return fmt.Errorf("%w: schema %q has no float tensor field %q", vectordata.ErrSchemaMismatch, spec.Name, vectorField)
+	}
+	dimension, err := strconv.Atoi(match[1])
+	if err != nil {
+		return fmt.Errorf("%w: invalid tensor dimension %q", vectordata.ErrSchemaMismatch, match[1])
+	}
+	if dimension != spec.Dimension {
+		return fmt.Errorf("%w: expected vector dimension %d, got %d", vectordata.ErrSchemaMismatch, spec.Dimension, dimension)
+	}
+
+	expectedMetric, err := distanceMetric(spec.Metric)
+	if err != nil {
+		return err
+	}
+	metricMatch := schemaMetricPattern.FindStringSubmatch(sd)
+	if metricMatch == nil {
+		return fmt.Errorf("%w: schema %q does not declare a distance-metric", vectordata.ErrSchemaMismatch, spec.Name)
+	}
+	if metricMatch[1] != expectedMetric {
+		return fmt.Errorf("%w: expected distance-metric %q, got %q", vectordata.ErrSchemaMismatch, expectedMetric, metricMatch[1])
+	}
+	return nil
+}
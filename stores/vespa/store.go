@@ -0,0 +1,150 @@
+package vespa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// StoreOptions configures VespaVectorStore behavior.
+type StoreOptions struct {
+	// Endpoint is the base URL of the Vespa container cluster (document and query APIs).
+	Endpoint string
+	// ConfigEndpoint is the optional base URL of the Vespa config server.
+	// When set, EnsureCollection validates the deployed schema definition.
+	ConfigEndpoint string
+	// Namespace is the document/v1 namespace used for document IDs.
+	Namespace string
+	// Cluster selects the content cluster when the application has more than one.
+	Cluster string
+	// HTTPClient is used for all requests. A client with a 30s timeout is used when nil.
+	HTTPClient      *http.Client
+	StrictByDefault bool
+	// MaxConcurrentWrites bounds parallel document puts per Insert/Upsert call.
+	MaxConcurrentWrites int
+}
+
+// DefaultStoreOptions returns production-safe defaults.
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		Endpoint:            "http://localhost:8080",
+		Namespace:           "vectorstore",
+		StrictByDefault:     true,
+		MaxConcurrentWrites: 8,
+	}
+}
+
+// VespaVectorStore implements vectordata.VectorStore on top of Vespa's HTTP APIs.
+type VespaVectorStore struct {
+	client *client
+	opts   StoreOptions
+}
+
+// NewVectorStore creates a Vespa-backed vector store.
+func NewVectorStore(opts StoreOptions) (*VespaVectorStore, error) {
+	normalized := opts.withDefaults()
+	if err := normalized.validate(); err != nil {
+		return nil, err
+	}
+	return &VespaVectorStore{
+		client: newClient(normalized.Endpoint, normalized.HTTPClient),
+		opts:   normalized,
+	}, nil
+}
+
+// Collection returns a handle to a collection without schema checks.
+func (s *VespaVectorStore) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.newCollectionHandle(name, dimension, metric)
+}
+
+// EnsureCollection validates that the collection's document type is deployed and returns its handle.
+//
+// Vespa schemas are part of the application package, so they cannot be created
+// at runtime. Use SchemaDefinition or ApplicationPackage to produce a deployable
+// schema for the spec. Vespa cannot add fields at runtime, so auto-migrate mode
+// validates the same way strict mode does.
+func (s *VespaVectorStore) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	normalizedSpec, _, err := s.normalizeCollectionSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureDocumentType(ctx, normalizedSpec); err != nil {
+		return nil, err
+	}
+
+	return s.newCollectionHandle(normalizedSpec.Name, normalizedSpec.Dimension, normalizedSpec.Metric), nil
+}
+
+func (s *VespaVectorStore) normalizeCollectionSpec(spec vectordata.CollectionSpec) (vectordata.CollectionSpec, vectordata.EnsureMode, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name is empty", vectordata.ErrSchemaMismatch)
+	}
+	if !isValidDocumentType(spec.Name) {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: collection name %q is not a valid Vespa document type", vectordata.ErrSchemaMismatch, spec.Name)
+	}
+	if spec.Dimension <= 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: dimension must be > 0", vectordata.ErrSchemaMismatch)
+	}
+	spec.Metric = defaultMetric(spec.Metric)
+	if _, err := distanceMetric(spec.Metric); err != nil {
+		return vectordata.CollectionSpec{}, "", err
+	}
+	if spec.NormalizeVectors {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: NormalizeVectors is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.ElementType != "" && spec.ElementType != vectordata.ElementFloat32 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: vector element type %q is not supported", vectordata.ErrSchemaMismatch, spec.ElementType)
+	}
+	if spec.FastDimension != 0 {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: FastDimension is not supported", vectordata.ErrSchemaMismatch)
+	}
+	if spec.MaxRecords != 0 || spec.EvictBy != "" {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: MaxRecords is not supported", vectordata.ErrSchemaMismatch)
+	}
+
+	mode := defaultMode(spec.Mode, s.opts.StrictByDefault)
+	if mode != vectordata.EnsureStrict && mode != vectordata.EnsureAutoMigrate {
+		return vectordata.CollectionSpec{}, "", fmt.Errorf("%w: unsupported ensure mode %q", vectordata.ErrSchemaMismatch, mode)
+	}
+	return spec, mode, nil
+}
+
+func (s *VespaVectorStore) newCollectionHandle(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return &VespaCollection{
+		store:     s,
+		name:      name,
+		dimension: dimension,
+		metric:    defaultMetric(metric),
+	}
+}
+
+func (o StoreOptions) withDefaults() StoreOptions {
+	o.Endpoint = strings.TrimRight(strings.TrimSpace(o.Endpoint), "/")
+	o.ConfigEndpoint = strings.TrimRight(strings.TrimSpace(o.ConfigEndpoint), "/")
+	if strings.TrimSpace(o.Namespace) == "" {
+		o.Namespace = "vectorstore"
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if o.MaxConcurrentWrites <= 0 {
+		o.MaxConcurrentWrites = 8
+	}
+	return o
+}
+
+func (o StoreOptions) validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("%w: endpoint is empty", vectordata.ErrSchemaMismatch)
+	}
+	if !isValidDocumentType(o.Namespace) {
+		return fmt.Errorf("%w: invalid namespace %q", vectordata.ErrSchemaMismatch, o.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+//go:build integration
+
+package vespa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	integrationEndpoint       string
+	integrationConfigEndpoint string
+	integrationContainer      testcontainers.Container
+)
+
+var integrationSpecs = []vectordata.CollectionSpec{
+	{Name: "docs_cosine", Dimension: 2, Metric: vectordata.DistanceCosine},
+	{Name: "docs_l2", Dimension: 2, Metric: vectordata.DistanceL2},
+	{Name: "docs_ip", Dimension: 2, Metric: vectordata.DistanceInnerProduct},
+}
+
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	endpoint := strings.TrimSpace(os.Getenv("VESPA_TEST_ENDPOINT"))
+	configEndpoint := strings.TrimSpace(os.Getenv("VESPA_TEST_CONFIG_ENDPOINT"))
+	if endpoint == "" || configEndpoint == "" {
+		container, queryURL, configURL, err := startVespaContainer(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start integration container: %v\n", err)
+			os.Exit(1)
+		}
+		integrationContainer = container
+		endpoint, configEndpoint = queryURL, configURL
+	}
+	integrationEndpoint = endpoint
+	integrationConfigEndpoint = configEndpoint
+
+	if err := deployApplication(ctx, integrationConfigEndpoint, integrationEndpoint); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to deploy application package: %v\n", err)
+		os.Exit(1)
+	}
+
+	exitCode := m.Run()
+
+	if integrationContainer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		if err := integrationContainer.Terminate(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to terminate integration container: %v\n", err)
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+func startVespaContainer(ctx context.Context) (testcontainers.Container, string, string, error) {
+	request := testcontainers.ContainerRequest{
+		Image:        "vespaengine/vespa:8",
+		ExposedPorts: []string{"8080/tcp", "19071/tcp"},
+		Hostname:     "vespa-container",
+		WaitingFor: wait.ForHTTP("/state/v1/health").
+			WithPort("19071/tcp").
+			WithStartupTimeout(3 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: request,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("start vespa container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", "", fmt.Errorf("resolve container host: %w", err)
+	}
+	queryPort, err := container.MappedPort(ctx, "8080/tcp")
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", "", fmt.Errorf("resolve container query port: %w", err)
+	}
+	configPort, err := container.MappedPort(ctx, "19071/tcp")
+	if err != nil {
+		_ = container.Terminate(context.Background())
+		return nil, "", "", fmt.Errorf("resolve container config port: %w", err)
+	}
+
+	return container,
+		fmt.Sprintf("http://%s:%s", host, queryPort.Port()),
+		fmt.Sprintf("http://%s:%s", host, configPort.Port()),
+		nil
+}
+
+func deployApplication(ctx context.Context, configEndpoint, endpoint string) error {
+	pkg, err := ApplicationPackage(integrationSpecs...)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		configEndpoint+"/application/v2/tenant/default/prepareandactivate",
+		bytes.NewReader(pkg),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("deploy: %w", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("deploy: http %d: %s", resp.StatusCode, body)
+	}
+
+	return waitForApplication(ctx, endpoint)
+}
+
+func waitForApplication(parent context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(parent, 3*time.Minute)
+	defer cancel()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/ApplicationStatus", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for vespa application: %w", ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func newTestStore(t *testing.T) *VespaVectorStore {
+	t.Helper()
+	opts := DefaultStoreOptions()
+	opts.Endpoint = integrationEndpoint
+	opts.ConfigEndpoint = integrationConfigEndpoint
+	store, err := NewVectorStore(opts)
+	if err != nil {
+		t.Fatalf("NewVectorStore: %v", err)
+	}
+	return store
+}
+
+func ensureCleanCollection(t *testing.T, ctx context.Context, store *VespaVectorStore, spec vectordata.CollectionSpec) vectordata.Collection {
+	t.Helper()
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCollection: %v", err)
+	}
+	t.Cleanup(func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_, _ = collection.Delete(cleanupCtx, []string{"a", "b", "c"})
+	})
+	return collection
+}
+
+func TestIntegrationEnsureCollection(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Act
+	_, okErr := store.EnsureCollection(ctx, integrationSpecs[0])
+	_, dimErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "docs_cosine", Dimension: 3, Metric: vectordata.DistanceCosine})
+	_, missingErr := store.EnsureCollection(ctx, vectordata.CollectionSpec{Name: "missing_docs", Dimension: 2})
+
+	// Assert
+	if okErr != nil {
+		t.Fatalf("EnsureCollection: %v", okErr)
+	}
+	if dimErr == nil {
+		t.Fatal("expected dimension mismatch")
+	}
+	if missingErr == nil {
+		t.Fatal("expected missing schema error")
+	}
+}
+
+func TestIntegrationSearchByMetric(t *testing.T) {
+	for _, spec := range integrationSpecs {
+		t.Run(string(spec.Metric), func(t *testing.T) {
+			// Arrange
+			store := newTestStore(t)
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+			collection := ensureCleanCollection(t, ctx, store, spec)
+
+			err := collection.Upsert(ctx, []vectordata.Record{
+				{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "a", "rank": 1}},
+				{ID: "b", Vector: []float32{0.8, 0.2}, Metadata: map[string]any{"kind": "b", "rank": 2}},
+				{ID: "c", Vector: []float32{0, 1}, Metadata: map[string]any{"kind": "c", "rank": 3}},
+			})
+			if err != nil {
+				t.Fatalf("Upsert: %v", err)
+			}
+
+			// Act
+			results, searchErr := collection.SearchByVector(ctx, []float32{1, 0}, 2, vectordata.SearchOptions{})
+			filtered, filterErr := collection.SearchByVector(ctx, []float32{1, 0}, 3, vectordata.SearchOptions{
+				Filter: vectordata.Gt(vectordata.Metadata("rank"), 1),
+			})
+			count, countErr := collection.Count(ctx, vectordata.Eq(vectordata.Metadata("kind"), "c"))
+
+			// Assert
+			if searchErr != nil {
+				t.Fatalf("SearchByVector: %v", searchErr)
+			}
+			if len(results) != 2 || results[0].Record.ID != "a" || results[1].Record.ID != "b" {
+				t.Fatalf("unexpected ordering: %#v", results)
+			}
+			if filterErr != nil {
+				t.Fatalf("SearchByVector with filter: %v", filterErr)
+			}
+			if len(filtered) != 2 || filtered[0].Record.ID != "b" {
+				t.Fatalf("unexpected filtered results: %#v", filtered)
+			}
+			if countErr != nil || count != 1 {
+				t.Fatalf("expected count 1, got %d (%v)", count, countErr)
+			}
+		})
+	}
+}
+
+func TestIntegrationHybridSearch(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	collection := ensureCleanCollection(t, ctx, store, integrationSpecs[0])
+
+	first, second := "reduce cloud costs with autoscaling", "gardening tips for spring"
+	err := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "a", Vector: []float32{0, 1}, Content: &first},
+		{ID: "b", Vector: []float32{1, 0}, Content: &second},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	// Act
+	results, err := collection.(vectordata.HybridSearcher).HybridSearch(ctx, []float32{1, 0}, "cloud costs", 2, vectordata.HybridSearchOptions{
+		VectorWeight: 0.1,
+		TextWeight:   1,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("HybridSearch: %v", err)
+	}
+	if len(results) == 0 || results[0].Record.ID != "a" {
+		t.Fatalf("expected text match to rank first: %#v", results)
+	}
+}
@@ -0,0 +1,68 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Archiver is implemented by collections that move records to a cold tier,
+// where they are kept out of searches and counts but can still be read and
+// restored. Archive and Restore take a filter and fail with ErrInvalidFilter
+// on a nil one, like DeleteByFilter.
+type Archiver interface {
+	Archive(ctx context.Context, filter Filter) (int64, error)
+	Restore(ctx context.Context, filter Filter) (int64, error)
+	GetArchived(ctx context.Context, id string) (Record, error)
+}
+
+// GetOptions configures GetWithOptions.
+type GetOptions struct {
+	// IncludeArchived falls back to the cold tier of an Archiver when the
+	// record is not in the collection.
+	IncludeArchived bool
+}
+
+// GetWithOptions returns record id like Get, looking among archived records
+// too when opts.IncludeArchived is set and collection is an Archiver.
+func GetWithOptions(ctx context.Context, collection Collection, id string, opts GetOptions) (Record, error) {
+	record, err := collection.Get(ctx, id)
+	if !opts.IncludeArchived || !errors.Is(err, ErrNotFound) {
+		return record, err
+	}
+	archiver, ok := collection.(Archiver)
+	if !ok {
+		return record, err
+	}
+	return archiver.GetArchived(ctx, id)
+}
+
+// Archive moves the records matching filter to the cold tier of collection
+// and returns how many it moved. Collections that are not Archivers fail
+// with errors.ErrUnsupported.
+func Archive(ctx context.Context, collection Collection, filter Filter) (int64, error) {
+	archiver, err := asArchiver(collection)
+	if err != nil {
+		return 0, err
+	}
+	return archiver.Archive(ctx, filter)
+}
+
+// Restore moves the archived records matching filter back into collection,
+// replacing records written with the same ID since, and returns how many it
+// moved.
+func Restore(ctx context.Context, collection Collection, filter Filter) (int64, error) {
+	archiver, err := asArchiver(collection)
+	if err != nil {
+		return 0, err
+	}
+	return archiver.Restore(ctx, filter)
+}
+
+func asArchiver(collection Collection) (Archiver, error) {
+	archiver, ok := collection.(Archiver)
+	if !ok {
+		return nil, fmt.Errorf("%w: collection %q does not support archiving", errors.ErrUnsupported, collection.Name())
+	}
+	return archiver, nil
+}
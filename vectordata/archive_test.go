@@ -0,0 +1,50 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type archivingCollection struct {
+	mapCollection
+	archived map[string]Record
+}
+
+func (c *archivingCollection) Archive(context.Context, Filter) (int64, error) { return 0, nil }
+func (c *archivingCollection) Restore(context.Context, Filter) (int64, error) { return 0, nil }
+
+func (c *archivingCollection) GetArchived(_ context.Context, id string) (Record, error) {
+	record, ok := c.archived[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func TestGetWithOptionsFallsBackToArchivedRecords(t *testing.T) {
+	// Arrange
+	collection := &archivingCollection{
+		mapCollection: mapCollection{records: map[string]Record{"hot": {ID: "hot"}}},
+		archived:      map[string]Record{"cold": {ID: "cold"}},
+	}
+	ctx := context.Background()
+
+	// Act
+	hot, hotErr := GetWithOptions(ctx, collection, "hot", GetOptions{IncludeArchived: true})
+	cold, coldErr := GetWithOptions(ctx, collection, "cold", GetOptions{IncludeArchived: true})
+	_, hiddenErr := GetWithOptions(ctx, collection, "cold", GetOptions{})
+	_, missingErr := GetWithOptions(ctx, collection, "missing", GetOptions{IncludeArchived: true})
+	_, unsupportedErr := Archive(ctx, &collection.mapCollection, Eq(Column("id"), "hot"))
+
+	// Assert
+	if hotErr != nil || coldErr != nil || hot.ID != "hot" || cold.ID != "cold" {
+		t.Fatalf("unexpected records %v, %v (%v, %v)", hot, cold, hotErr, coldErr)
+	}
+	if !errors.Is(hiddenErr, ErrNotFound) || !errors.Is(missingErr, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v and %v", hiddenErr, missingErr)
+	}
+	if !errors.Is(unsupportedErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", unsupportedErr)
+	}
+}
@@ -0,0 +1,180 @@
+package vectordata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ArrayUpdate adds and removes elements of a metadata array, treating it as
+// a set: values are compared by their JSON encoding.
+type ArrayUpdate struct {
+	// Field is the metadata array to update. A missing or null field counts
+	// as an empty array; other non-array values are rejected.
+	Field FieldRef
+	// Append lists values added to the end of the array when absent.
+	Append []any
+	// Remove lists values removed wherever they occur.
+	Remove []any
+}
+
+// MetadataArrayUpdater is implemented by collections that update metadata
+// arrays atomically on the server. UpdateMetadataArray normalizes the update
+// before passing it on.
+type MetadataArrayUpdater interface {
+	UpdateMetadataArray(ctx context.Context, id string, update ArrayUpdate) error
+}
+
+// UpdateMetadataArray applies update to the metadata of record id, e.g. to
+// tag a record "reviewed" unless it already is. It fails with ErrNotFound
+// when the record does not exist, and with ErrInvalidRecord when the field
+// holds something other than an array.
+//
+// MetadataArrayUpdaters, such as the Postgres and FAISS stores, update
+// atomically, so concurrent updates of the same array are all kept. Other
+// collections Get the record, update it in Go and Upsert it.
+func UpdateMetadataArray(ctx context.Context, collection Collection, id string, update ArrayUpdate) error {
+	update, err := normalizeArrayUpdate(update)
+	if err != nil {
+		return err
+	}
+	if updater, ok := collection.(MetadataArrayUpdater); ok {
+		return updater.UpdateMetadataArray(ctx, id, update)
+	}
+	record, err := collection.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	record.Metadata, err = ApplyArrayUpdate(record.Metadata, update)
+	if err != nil {
+		return fmt.Errorf("record %q: %w", id, err)
+	}
+	return collection.Upsert(ctx, []Record{record})
+}
+
+// AppendToArray adds values to the metadata array at path unless they are
+// already present.
+func AppendToArray(ctx context.Context, collection Collection, id string, path []string, values ...any) error {
+	return UpdateMetadataArray(ctx, collection, id, ArrayUpdate{Field: Metadata(path...), Append: values})
+}
+
+// RemoveFromArray removes values from the metadata array at path.
+func RemoveFromArray(ctx context.Context, collection Collection, id string, path []string, values ...any) error {
+	return UpdateMetadataArray(ctx, collection, id, ArrayUpdate{Field: Metadata(path...), Remove: values})
+}
+
+// ApplyArrayUpdate returns metadata with update applied. Objects along the
+// field's path are created or replaced as needed; metadata is not modified.
+func ApplyArrayUpdate(metadata map[string]any, update ArrayUpdate) (map[string]any, error) {
+	update, err := normalizeArrayUpdate(update)
+	if err != nil {
+		return nil, err
+	}
+	return setArray(metadata, update.Field.Path, update)
+}
+
+func setArray(object map[string]any, path []string, update ArrayUpdate) (map[string]any, error) {
+	out := maps.Clone(object)
+	if out == nil {
+		out = make(map[string]any, 1)
+	}
+	key := path[0]
+	if len(path) > 1 {
+		child, _ := out[key].(map[string]any)
+		updated, err := setArray(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = updated
+		return out, nil
+	}
+
+	var current []any
+	switch value := out[key].(type) {
+	case nil:
+	case []any:
+		current = value
+	default:
+		return nil, fmt.Errorf("%w: metadata field %q is %T, not an array", ErrInvalidRecord, key, value)
+	}
+	removed := make(map[string]bool, len(update.Remove))
+	for _, value := range update.Remove {
+		removed[jsonKey(value)] = true
+	}
+	next := make([]any, 0, len(current)+len(update.Append))
+	present := make(map[string]bool, len(current))
+	for _, value := range current {
+		encoded := jsonKey(value)
+		if removed[encoded] {
+			continue
+		}
+		present[encoded] = true
+		next = append(next, value)
+	}
+	for _, value := range update.Append {
+		if !present[jsonKey(value)] {
+			next = append(next, value)
+		}
+	}
+	out[key] = next
+	return out, nil
+}
+
+// normalizeArrayUpdate checks the field, round-trips the values through JSON
+// so they compare the way stored metadata does, and drops repeated values.
+func normalizeArrayUpdate(update ArrayUpdate) (ArrayUpdate, error) {
+	field, err := NormalizeFieldRef(update.Field)
+	if err != nil {
+		return ArrayUpdate{}, err
+	}
+	if field.Kind != FieldMetadata {
+		return ArrayUpdate{}, fmt.Errorf("%w: array updates need a metadata field, got column %q", ErrInvalidFilter, field.Name)
+	}
+	if len(update.Append) == 0 && len(update.Remove) == 0 {
+		return ArrayUpdate{}, fmt.Errorf("array update of %v has no values", field.Path)
+	}
+	appendValues, appendKeys, err := uniqueJSONValues(update.Append)
+	if err != nil {
+		return ArrayUpdate{}, err
+	}
+	removeValues, removeKeys, err := uniqueJSONValues(update.Remove)
+	if err != nil {
+		return ArrayUpdate{}, err
+	}
+	for _, key := range appendKeys {
+		if slices.Contains(removeKeys, key) {
+			return ArrayUpdate{}, fmt.Errorf("array update of %v both appends and removes %s", field.Path, key)
+		}
+	}
+	return ArrayUpdate{Field: field, Append: appendValues, Remove: removeValues}, nil
+}
+
+func uniqueJSONValues(values []any) ([]any, []string, error) {
+	out := make([]any, 0, len(values))
+	keys := make([]string, 0, len(values))
+	for _, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode array value: %w", err)
+		}
+		if slices.Contains(keys, string(encoded)) {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			return nil, nil, fmt.Errorf("decode array value: %w", err)
+		}
+		out = append(out, decoded)
+		keys = append(keys, string(encoded))
+	}
+	return out, keys, nil
+}
+
+// jsonKey encodes a value decoded from JSON; map keys are sorted, so equal
+// values encode alike.
+func jsonKey(value any) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
@@ -0,0 +1,73 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyArrayUpdateTreatsArraysAsSets(t *testing.T) {
+	// Arrange
+	metadata := map[string]any{
+		"tags":  []any{"draft", "go", float64(1)},
+		"owner": "ana",
+	}
+
+	// Act
+	updated, err := ApplyArrayUpdate(metadata, ArrayUpdate{
+		Field:  Metadata("tags"),
+		Append: []any{"go", "reviewed", "reviewed", 1},
+		Remove: []any{"draft"},
+	})
+	nested, nestedErr := ApplyArrayUpdate(metadata, ArrayUpdate{Field: Metadata("owner", "teams"), Append: []any{"search"}})
+	_, scalarErr := ApplyArrayUpdate(metadata, ArrayUpdate{Field: Metadata("owner"), Append: []any{"x"}})
+	_, overlapErr := ApplyArrayUpdate(metadata, ArrayUpdate{Field: Metadata("tags"), Append: []any{"x"}, Remove: []any{"x"}})
+	_, columnErr := ApplyArrayUpdate(metadata, ArrayUpdate{Field: Column("content"), Append: []any{"x"}})
+
+	// Assert
+	if err != nil || nestedErr != nil {
+		t.Fatalf("ApplyArrayUpdate: %v, %v", err, nestedErr)
+	}
+	if !reflect.DeepEqual(updated["tags"], []any{"go", float64(1), "reviewed"}) {
+		t.Fatalf("unexpected tags %#v", updated["tags"])
+	}
+	if !reflect.DeepEqual(nested["owner"], map[string]any{"teams": []any{"search"}}) {
+		t.Fatalf("expected the path to be created, got %#v", nested["owner"])
+	}
+	if len(metadata["tags"].([]any)) != 3 || metadata["owner"] != "ana" {
+		t.Fatalf("expected metadata to be left alone, got %v", metadata)
+	}
+	if !errors.Is(scalarErr, ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord for a scalar field, got %v", scalarErr)
+	}
+	if overlapErr == nil {
+		t.Fatal("expected an error when a value is both appended and removed")
+	}
+	if !errors.Is(columnErr, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for a column field, got %v", columnErr)
+	}
+}
+
+func TestAppendToArrayFallsBackToGetAndUpsert(t *testing.T) {
+	// Arrange
+	collection := &mapCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{1}, Metadata: map[string]any{"tags": []any{"go"}}},
+	}}
+
+	// Act
+	appendErr := AppendToArray(context.Background(), collection, "a", []string{"tags"}, "reviewed", "go")
+	removeErr := RemoveFromArray(context.Background(), collection, "a", []string{"tags"}, "go")
+	missingErr := AppendToArray(context.Background(), collection, "missing", []string{"tags"}, "go")
+
+	// Assert
+	if appendErr != nil || removeErr != nil {
+		t.Fatalf("array update: %v, %v", appendErr, removeErr)
+	}
+	if !reflect.DeepEqual(collection.records["a"].Metadata["tags"], []any{"reviewed"}) {
+		t.Fatalf("unexpected tags %#v", collection.records["a"].Metadata["tags"])
+	}
+	if !errors.Is(missingErr, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
@@ -0,0 +1,92 @@
+package vectordata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BatchOptions configures WriteBatch.
+type BatchOptions struct {
+	// Upsert writes with Upsert instead of Insert.
+	Upsert bool
+	// SkipInvalid writes the valid records and reports the invalid ones,
+	// instead of writing nothing when any record is invalid.
+	SkipInvalid bool
+	// Limits adds RecordLimits checks to the built-in ones. Stores still
+	// apply their own limits when writing.
+	Limits RecordLimits
+}
+
+// BatchReport describes the outcome of WriteBatch.
+type BatchReport struct {
+	// Written is how many records were sent to the collection.
+	Written int
+	// Invalid lists the records that failed validation, in batch order.
+	Invalid []*RecordError
+}
+
+// WriteBatch validates every record before writing any of them and reports
+// each invalid one as a *RecordError carrying its index and ID. Records are
+// invalid when the ID is empty or repeats an earlier record's, when the
+// vector does not match the collection's dimension, when the metadata cannot
+// be encoded as JSON, or when opts.Limits rejects them.
+//
+// By default any invalid record fails the batch with the invalid records
+// joined into the error, and nothing is written. With SkipInvalid the valid
+// records are written in one call and the error is the write's, if any.
+func WriteBatch(ctx context.Context, collection Collection, records []Record, opts BatchOptions) (BatchReport, error) {
+	var report BatchReport
+	valid := make([]Record, 0, len(records))
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		recordErr := validateBatchRecord(collection.Dimension(), record, seen, opts.Limits)
+		if recordErr != nil {
+			recordErr.Index, recordErr.ID = i, record.ID
+			report.Invalid = append(report.Invalid, recordErr)
+			continue
+		}
+		seen[record.ID] = i
+		valid = append(valid, record)
+	}
+
+	if len(report.Invalid) > 0 && !opts.SkipInvalid {
+		errs := make([]error, len(report.Invalid))
+		for i, err := range report.Invalid {
+			errs[i] = err
+		}
+		return report, errors.Join(errs...)
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+	var err error
+	if opts.Upsert {
+		err = collection.Upsert(ctx, valid)
+	} else {
+		err = collection.Insert(ctx, valid)
+	}
+	if err != nil {
+		return report, err
+	}
+	report.Written = len(valid)
+	return report, nil
+}
+
+func validateBatchRecord(dimension int, record Record, seen map[string]int, limits RecordLimits) *RecordError {
+	if strings.TrimSpace(record.ID) == "" {
+		return &RecordError{Field: "id", Reason: "is empty"}
+	}
+	if first, ok := seen[record.ID]; ok {
+		return &RecordError{Field: "id", Reason: fmt.Sprintf("repeats record %d", first)}
+	}
+	if dimension > 0 && len(record.Vector) != dimension {
+		return &RecordError{Field: "vector", Reason: fmt.Sprintf("has %d components, expected %d", len(record.Vector), dimension), Err: ErrDimensionMismatch}
+	}
+	if _, err := json.Marshal(record.Metadata); err != nil {
+		return &RecordError{Field: "metadata", Reason: "cannot be encoded", Err: err}
+	}
+	return limits.check(record)
+}
@@ -0,0 +1,50 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWriteBatchReportsEveryInvalidRecord(t *testing.T) {
+	// Arrange
+	records := []Record{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: " ", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{1}},
+		{ID: "a", Vector: []float32{0, 1}},
+		{ID: "c", Vector: []float32{0, 1}, Metadata: map[string]any{"bad": math.NaN()}},
+		{ID: "d", Vector: []float32{0, 1}},
+	}
+	strict := &mapCollection{dimension: 2, records: map[string]Record{}}
+	lenient := &mapCollection{dimension: 2, records: map[string]Record{}}
+
+	// Act
+	strictReport, strictErr := WriteBatch(context.Background(), strict, records, BatchOptions{})
+	lenientReport, lenientErr := WriteBatch(context.Background(), lenient, records, BatchOptions{SkipInvalid: true, Upsert: true})
+
+	// Assert
+	if !errors.Is(strictErr, ErrInvalidRecord) || !errors.Is(strictErr, ErrDimensionMismatch) {
+		t.Fatalf("expected the invalid records joined into the error, got %v", strictErr)
+	}
+	if strictReport.Written != 0 || len(strict.writes) != 0 {
+		t.Fatalf("expected nothing written, got %+v and writes %v", strictReport, strict.writes)
+	}
+	if lenientErr != nil {
+		t.Fatalf("WriteBatch: %v", lenientErr)
+	}
+	if lenientReport.Written != 2 || len(lenient.records) != 2 || lenient.writes[0] != "upsert" {
+		t.Fatalf("expected a and d upserted, got %+v and %v", lenientReport, lenient.records)
+	}
+	var fields []string
+	var indexes []int
+	for _, invalid := range lenientReport.Invalid {
+		fields = append(fields, invalid.Field)
+		indexes = append(indexes, invalid.Index)
+	}
+	if !slices.Equal(indexes, []int{1, 2, 3, 4}) || !slices.Equal(fields, []string{"id", "vector", "id", "metadata"}) {
+		t.Fatalf("unexpected invalid records %v", lenientReport.Invalid)
+	}
+}
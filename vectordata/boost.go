@@ -0,0 +1,233 @@
+package vectordata
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const defaultBoostCandidateFactor = 4
+
+// Boost adjusts the score of the records matching When, e.g. to rank
+// official sources higher:
+//
+//	vectordata.Boost{When: vectordata.Eq(vectordata.Metadata("source"), "official"), Multiply: 1.2}
+//
+// A record's score is its score from distance plus the TimeDecay boost,
+// times the Multiply of every matching boost, plus the Add of every matching
+// boost. Multipliers scale negative scores, such as inner product ones,
+// towards lower ranks; use Add for those metrics.
+type Boost struct {
+	When Filter
+	// Multiply scales the score of matching records (default 1).
+	Multiply float64
+	// Add is added to the score of matching records.
+	Add float64
+}
+
+// EffectiveMultiply returns Multiply, or 1 when it is zero.
+func (b Boost) EffectiveMultiply() float64 {
+	if b.Multiply == 0 {
+		return 1
+	}
+	return b.Multiply
+}
+
+// TimeDecay boosts fresh records in SearchByVector, so they outrank stale
+// ones at similar similarity. It adds
+//
+//	Weight * 0.5^(age / HalfLife)
+//
+// to the score from distance, where age is Now minus the RFC 3339 timestamp
+// at Field, counted as 0 for future timestamps. Records without a valid
+// timestamp get no boost.
+type TimeDecay struct {
+	// Field is the metadata path of the timestamp, e.g.
+	// Metadata("published_at").
+	Field FieldRef
+	// HalfLife is the age at which the boost halves. Required.
+	HalfLife time.Duration
+	// Weight is the boost of a record of age 0 (default 1).
+	Weight float64
+	// Now is the time ages are measured from (default time.Now()).
+	Now time.Time
+}
+
+// Validate reports a missing or non-metadata field, a non-positive half-life
+// and negative weights.
+func (d TimeDecay) Validate() error {
+	field, err := NormalizeFieldRef(d.Field)
+	if err != nil {
+		return fmt.Errorf("time decay field: %w", err)
+	}
+	if field.Kind != FieldMetadata {
+		return fmt.Errorf("%w: time decay field must be a metadata path", ErrSchemaMismatch)
+	}
+	if d.HalfLife <= 0 {
+		return fmt.Errorf("%w: time decay half-life must be > 0", ErrSchemaMismatch)
+	}
+	if d.Weight < 0 {
+		return fmt.Errorf("%w: time decay weight must be >= 0", ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// EffectiveWeight returns Weight, or 1 when it is zero.
+func (d TimeDecay) EffectiveWeight() float64 {
+	if d.Weight == 0 {
+		return 1
+	}
+	return d.Weight
+}
+
+// EffectiveNow returns Now, or the current time when it is zero.
+func (d TimeDecay) EffectiveNow() time.Time {
+	if d.Now.IsZero() {
+		return time.Now()
+	}
+	return d.Now
+}
+
+// Boost returns the boost of record at now, 0 when its timestamp is missing
+// or not RFC 3339 with an explicit offset.
+func (d TimeDecay) Boost(record Record, now time.Time) float64 {
+	field, err := NormalizeFieldRef(d.Field)
+	if err != nil {
+		return 0
+	}
+	value, present, err := resolveMatchField(field, record)
+	if err != nil || !present {
+		return 0
+	}
+	text, ok := metadataText(value)
+	if !ok || !timestampTextRegexp.MatchString(text) {
+		return 0
+	}
+	stamp, err := time.Parse(time.RFC3339Nano, strings.Replace(text, " ", "T", 1))
+	if err != nil {
+		return 0
+	}
+	age := max(now.Sub(stamp), 0)
+	return d.EffectiveWeight() * math.Pow(0.5, float64(age)/float64(d.HalfLife))
+}
+
+// Boosted reports whether a search ranks by a boosted score rather than by
+// distance, i.e. whether TimeDecay or Boosts are set.
+func (o SearchOptions) Boosted() bool {
+	return o.TimeDecay != nil || len(o.Boosts) > 0
+}
+
+// ValidateBoosts checks the TimeDecay and Boosts of a search. Boosted scores
+// cannot be normalized, so it rejects ScoreNormalization alongside them.
+func ValidateBoosts(opts SearchOptions) error {
+	if !opts.Boosted() {
+		return nil
+	}
+	if opts.ScoreNormalization.Method != ScoreNormalizationNone {
+		return fmt.Errorf("%w: score boosts cannot be combined with score normalization", ErrSchemaMismatch)
+	}
+	if opts.BoostCandidates < 0 {
+		return fmt.Errorf("%w: boost candidates must be >= 0", ErrSchemaMismatch)
+	}
+	if opts.TimeDecay != nil {
+		if err := opts.TimeDecay.Validate(); err != nil {
+			return err
+		}
+	}
+	for i, boost := range opts.Boosts {
+		if boost.When == nil {
+			return fmt.Errorf("%w: boost %d has no When filter", ErrSchemaMismatch, i)
+		}
+		if boost.Multiply < 0 {
+			return fmt.Errorf("%w: boost %d has multiplier %v, must be >= 0", ErrSchemaMismatch, i, boost.Multiply)
+		}
+	}
+	return nil
+}
+
+// BoostedScore returns the score of result under the TimeDecay and Boosts of
+// opts, with ages measured from now. Boost filters are evaluated with
+// MatchFilter against the record, so it needs the record's metadata.
+func BoostedScore(metric DistanceMetric, result SearchResult, opts SearchOptions, now time.Time) (float64, error) {
+	score := ScoreFromDistance(normalizeMetric(metric), result.Distance)
+	if opts.TimeDecay != nil {
+		score += opts.TimeDecay.Boost(result.Record, now)
+	}
+	var add float64
+	for i, boost := range opts.Boosts {
+		matched, err := MatchFilter(boost.When, result.Record)
+		if err != nil {
+			return 0, fmt.Errorf("boost %d: %w", i, err)
+		}
+		if matched {
+			score *= boost.EffectiveMultiply()
+			add += boost.Add
+		}
+	}
+	return score + add, nil
+}
+
+// SearchBoosted runs a search whose opts set TimeDecay or Boosts, on behalf
+// of collection's SearchByVector when the store cannot rank by them in the
+// query:
+//
+//	if opts.Boosted() {
+//		return vectordata.SearchBoosted(ctx, c, vector, topK, opts)
+//	}
+//
+// It fetches the BoostCandidates nearest records with their metadata, boosts
+// their scores, and returns the topK best under the requested projection.
+func SearchBoosted(ctx context.Context, collection Collection, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	if err := cursorRanking(opts); err != nil {
+		return nil, err
+	}
+	if err := ValidateBoosts(opts); err != nil {
+		return nil, err
+	}
+	candidates := opts.BoostCandidates
+	if candidates == 0 {
+		candidates = topK * defaultBoostCandidateFactor
+	}
+	candidates = max(candidates, topK)
+
+	projection := DefaultProjection()
+	if opts.Projection != nil {
+		projection = *opts.Projection
+	}
+	inner := opts
+	inner.TimeDecay, inner.Boosts, inner.OrderBy = nil, nil, nil
+	// Boosts and OrderBy read the metadata as stored, so redaction waits
+	// until they are applied.
+	withMetadata := projection
+	withMetadata.IncludeMetadata = true
+	withMetadata.Redact = nil
+	inner.Projection = &withMetadata
+
+	results, err := collection.SearchByVector(ctx, vector, candidates, inner)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if opts.TimeDecay != nil {
+		now = opts.TimeDecay.EffectiveNow()
+	}
+	for i := range results {
+		if results[i].Score, err = BoostedScore(collection.Metric(), results[i], opts, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := sortByScore(results, opts.OrderBy); err != nil {
+		return nil, err
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	if !projection.IncludeMetadata {
+		for i := range results {
+			results[i].Record.Metadata = nil
+		}
+	}
+	return RedactResults(&projection, results), nil
+}
@@ -0,0 +1,125 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type decayCollection struct {
+	Collection
+	results []SearchResult
+	topK    int
+	opts    SearchOptions
+}
+
+func (c *decayCollection) Metric() DistanceMetric { return DistanceL2 }
+
+func (c *decayCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	if opts.Boosted() {
+		return SearchBoosted(ctx, c, vector, topK, opts)
+	}
+	c.topK, c.opts = topK, opts
+	return append([]SearchResult(nil), c.results...), nil
+}
+
+func TestTimeDecayBoost(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	decay := TimeDecay{Field: Metadata("published_at"), HalfLife: 24 * time.Hour, Weight: 0.5}
+	record := func(published any) Record {
+		return Record{Metadata: map[string]any{"published_at": published}}
+	}
+
+	// Act
+	dayOld := decay.Boost(record("2026-09-30T12:00:00Z"), now)
+	future := decay.Boost(record("2026-10-02T00:00:00+02:00"), now)
+	invalid := decay.Boost(record("yesterday"), now)
+	missing := decay.Boost(Record{}, now)
+
+	// Assert
+	if math.Abs(dayOld-0.25) > 1e-9 || future != 0.5 {
+		t.Fatalf("expected 0.25 and 0.5, got %v and %v", dayOld, future)
+	}
+	if invalid != 0 || missing != 0 {
+		t.Fatalf("expected no boost without a valid timestamp, got %v and %v", invalid, missing)
+	}
+}
+
+func TestSearchBoostedReranksCandidates(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	collection := &decayCollection{results: []SearchResult{
+		{Record: Record{ID: "stale", Metadata: map[string]any{"published_at": "2025-10-01T00:00:00Z"}}, Distance: 0},
+		{Record: Record{ID: "fresh", Metadata: map[string]any{"published_at": "2026-10-01T00:00:00Z"}}, Distance: 0.5},
+		{Record: Record{ID: "far", Metadata: map[string]any{"published_at": "2026-10-01T00:00:00Z"}}, Distance: 4},
+	}}
+	projection := Projection{IncludeContent: true}
+
+	// Act
+	results, err := collection.SearchByVector(context.Background(), []float32{1}, 2, SearchOptions{
+		Projection: &projection,
+		TimeDecay:  &TimeDecay{Field: Metadata("published_at"), HalfLife: 24 * time.Hour, Now: now},
+	})
+
+	// Assert
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected 2 results, got %v (%v)", results, err)
+	}
+	if results[0].Record.ID != "fresh" || math.Abs(results[0].Score-(1/1.5+1)) > 1e-9 || results[0].Record.Metadata != nil {
+		t.Fatalf("expected fresh first with a boosted score and no metadata, got %+v", results[0])
+	}
+	if collection.topK != 8 || !collection.opts.Projection.IncludeMetadata || collection.opts.Boosted() {
+		t.Fatalf("expected 8 candidates with metadata, got %d and %+v", collection.topK, collection.opts)
+	}
+}
+
+func TestValidateBoosts(t *testing.T) {
+	// Arrange
+	valid := TimeDecay{Field: Metadata("published_at"), HalfLife: time.Hour}
+	column := TimeDecay{Field: Column("id"), HalfLife: time.Hour}
+	noHalfLife := TimeDecay{Field: Metadata("published_at")}
+	noMatcher := []Boost{{Multiply: 2}}
+
+	// Act
+	validErr := ValidateBoosts(SearchOptions{TimeDecay: &valid})
+	columnErr := ValidateBoosts(SearchOptions{TimeDecay: &column})
+	halfLifeErr := ValidateBoosts(SearchOptions{TimeDecay: &noHalfLife})
+	normalizedErr := ValidateBoosts(SearchOptions{TimeDecay: &valid, ScoreNormalization: ScoreNormalization{Method: ScoreNormalizationSigmoid}})
+	matcherErr := ValidateBoosts(SearchOptions{Boosts: noMatcher})
+
+	// Assert
+	if validErr != nil {
+		t.Fatalf("expected a valid time decay, got %v", validErr)
+	}
+	for _, err := range []error{columnErr, halfLifeErr, normalizedErr, matcherErr} {
+		if !errors.Is(err, ErrSchemaMismatch) {
+			t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+		}
+	}
+}
+
+func TestBoostedScoreAppliesMatchingBoosts(t *testing.T) {
+	// Arrange
+	official := SearchResult{Record: Record{Metadata: map[string]any{"source": "official", "pinned": true}}, Distance: 1}
+	forum := SearchResult{Record: Record{Metadata: map[string]any{"source": "forum"}}, Distance: 1}
+	opts := SearchOptions{Boosts: []Boost{
+		{When: Eq(Metadata("source"), "official"), Multiply: 1.5},
+		{When: Eq(Metadata("pinned"), true), Add: 0.25},
+		{When: Eq(Metadata("source"), "forum"), Multiply: 0.5},
+	}}
+
+	// Act
+	officialScore, officialErr := BoostedScore(DistanceL2, official, opts, time.Time{})
+	forumScore, forumErr := BoostedScore(DistanceL2, forum, opts, time.Time{})
+
+	// Assert
+	if officialErr != nil || forumErr != nil {
+		t.Fatalf("BoostedScore: %v, %v", officialErr, forumErr)
+	}
+	if officialScore != 0.5*1.5+0.25 || forumScore != 0.25 {
+		t.Fatalf("expected 1 and 0.25, got %v and %v", officialScore, forumScore)
+	}
+}
@@ -0,0 +1,293 @@
+// Package breaker fails vectordata collection calls fast while a backend is
+// degraded.
+//
+// A Breaker keeps one circuit per collection name. A closed circuit counts
+// calls over a window and opens when the share of failed or slow calls
+// crosses its threshold; an open circuit rejects calls with ErrOpen until
+// OpenDuration has passed, then lets a single probe through and closes again
+// if it succeeds:
+//
+//	circuits := breaker.New(breaker.Options{ErrorRate: 0.5, SlowCallDuration: time.Second})
+//	store = vectordata.WrapStore(store, circuits.Middleware())
+//
+// With a Fallback, reads rejected by an open circuit are served from it
+// instead, e.g. stale results of a cache.Cache:
+//
+//	results := cache.New(cache.Options{})
+//	circuits := breaker.New(breaker.Options{Fallback: results.Stale()})
+//	store = vectordata.WrapStore(store, circuits.Middleware(), results.Middleware())
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultWindow       = 10 * time.Second
+	defaultMinRequests  = 20
+	defaultErrorRate    = 0.5
+	defaultSlowCallRate = 0.5
+	defaultOpenDuration = 30 * time.Second
+)
+
+// ErrOpen is returned for calls rejected by an open circuit.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of a circuit.
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+// Options configures a Breaker.
+type Options struct {
+	// Window is the period over which a closed circuit counts calls before
+	// starting over (default 10s).
+	Window time.Duration
+	// MinRequests is the number of calls in a window below which a circuit
+	// never opens (default 20).
+	MinRequests int
+	// ErrorRate is the share of failed calls in a window that opens the
+	// circuit (default 0.5).
+	ErrorRate float64
+	// SlowCallDuration marks calls taking at least this long as slow. Zero
+	// disables latency-based opening.
+	SlowCallDuration time.Duration
+	// SlowCallRate is the share of slow calls in a window that opens the
+	// circuit (default 0.5).
+	SlowCallRate float64
+	// OpenDuration is how long a circuit rejects calls before probing
+	// (default 30s).
+	OpenDuration time.Duration
+	// IsFailure classifies errors as backend failures (default
+	// DefaultIsFailure).
+	IsFailure func(err error) bool
+	// Fallback, when set, serves Get, Count and search calls rejected by a
+	// circuit. If it fails too, the call fails with ErrOpen.
+	Fallback vectordata.CallHandler
+	// OnStateChange, when set, is called after a circuit changes state.
+	OnStateChange func(collection string, from, to State)
+}
+
+// DefaultIsFailure counts every error as a failure except cancellation and
+// the vectordata errors that describe the request rather than the backend,
+// including conflicts such as duplicate IDs, requests beyond a size limit,
+// invalid cursors and errors.ErrUnsupported. ErrNotReady counts as a
+// failure: the store lacks a prerequisite, so every call fails until an
+// operator fixes it, and the circuit sheds them.
+func DefaultIsFailure(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, vectordata.ErrNotFound),
+		errors.Is(err, vectordata.ErrDimensionMismatch),
+		errors.Is(err, vectordata.ErrSchemaMismatch),
+		errors.Is(err, vectordata.ErrInvalidFilter),
+		errors.Is(err, vectordata.ErrInvalidRecord),
+		errors.Is(err, vectordata.ErrConflict),
+		errors.Is(err, vectordata.ErrTooLarge),
+		errors.Is(err, vectordata.ErrInvalidCursor),
+		errors.Is(err, errors.ErrUnsupported):
+		return false
+	default:
+		return true
+	}
+}
+
+// Breaker holds the circuits of a store's collections. It is safe for
+// concurrent use.
+type Breaker struct {
+	opts Options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state       State
+	windowStart time.Time
+	calls       int
+	failures    int
+	slow        int
+	openedAt    time.Time
+	// probing is set while a half-open circuit waits for its probe.
+	probing bool
+}
+
+// New returns a breaker with every circuit closed. Zero or negative fields
+// take their defaults.
+func New(opts Options) *Breaker {
+	if opts.Window <= 0 {
+		opts.Window = defaultWindow
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = defaultMinRequests
+	}
+	if opts.ErrorRate <= 0 {
+		opts.ErrorRate = defaultErrorRate
+	}
+	if opts.SlowCallRate <= 0 {
+		opts.SlowCallRate = defaultSlowCallRate
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = defaultOpenDuration
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = DefaultIsFailure
+	}
+	return &Breaker{opts: opts, now: time.Now, circuits: make(map[string]*circuit)}
+}
+
+// Collection returns base behind a breaker of its own.
+func Collection(base vectordata.Collection, opts Options) vectordata.Collection {
+	return vectordata.WrapCollection(base, New(opts).Middleware())
+}
+
+// Middleware returns the collection middleware guarding calls with the
+// breaker's circuits.
+func (b *Breaker) Middleware() vectordata.CollectionMiddleware {
+	return b.middleware
+}
+
+// State returns the state of a collection's circuit.
+func (b *Breaker) State(collection string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[collection]; ok {
+		return c.state
+	}
+	return StateClosed
+}
+
+func (b *Breaker) middleware(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+	name := call.Collection.Name()
+	if !b.allow(name) {
+		return b.reject(ctx, call)
+	}
+
+	started := b.now()
+	err := next(ctx, call)
+	b.record(name, err, b.now().Sub(started))
+	return err
+}
+
+func (b *Breaker) reject(ctx context.Context, call *vectordata.Call) error {
+	if b.opts.Fallback == nil || !readOnly(call.Operation) {
+		return ErrOpen
+	}
+	if err := b.opts.Fallback(ctx, call); err != nil {
+		return ErrOpen
+	}
+	return nil
+}
+
+func readOnly(op vectordata.Operation) bool {
+	switch op {
+	case vectordata.OpGet, vectordata.OpCount, vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+// allow reports whether a call may proceed. Once OpenDuration has passed, an
+// open circuit turns half-open and the call becomes its probe.
+func (b *Breaker) allow(name string) bool {
+	b.mu.Lock()
+	c := b.circuit(name)
+	from := c.state
+	allowed := true
+	switch c.state {
+	case StateOpen:
+		if b.now().Before(c.openedAt.Add(b.opts.OpenDuration)) {
+			allowed = false
+			break
+		}
+		c.state = StateHalfOpen
+		c.probing = true
+	case StateHalfOpen:
+		allowed = !c.probing
+		c.probing = true
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(name, from, to)
+	return allowed
+}
+
+// record counts a finished call, opening or closing the circuit.
+func (b *Breaker) record(name string, err error, elapsed time.Duration) {
+	failed := err != nil && b.opts.IsFailure(err)
+	slow := b.opts.SlowCallDuration > 0 && elapsed >= b.opts.SlowCallDuration
+
+	b.mu.Lock()
+	now := b.now()
+	c := b.circuit(name)
+	from := c.state
+	switch c.state {
+	case StateHalfOpen:
+		c.probing = false
+		if failed || slow {
+			c.open(now)
+		} else {
+			c.reset(now)
+		}
+	case StateClosed:
+		if now.Sub(c.windowStart) >= b.opts.Window {
+			c.reset(now)
+		}
+		c.calls++
+		if failed {
+			c.failures++
+		}
+		if slow {
+			c.slow++
+		}
+		if c.calls >= b.opts.MinRequests &&
+			(float64(c.failures)/float64(c.calls) >= b.opts.ErrorRate || float64(c.slow)/float64(c.calls) >= b.opts.SlowCallRate) {
+			c.open(now)
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(name, from, to)
+}
+
+func (b *Breaker) notify(name string, from, to State) {
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(name, from, to)
+	}
+}
+
+// circuit returns the circuit of a collection, creating it closed. b.mu
+// must be held.
+func (b *Breaker) circuit(name string) *circuit {
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{state: StateClosed, windowStart: b.now()}
+		b.circuits[name] = c
+	}
+	return c
+}
+
+func (c *circuit) open(now time.Time) {
+	c.state = StateOpen
+	c.openedAt = now
+}
+
+// reset closes the circuit with an empty window.
+func (c *circuit) reset(now time.Time) {
+	c.state = StateClosed
+	c.windowStart = now
+	c.calls, c.failures, c.slow = 0, 0, 0
+}
@@ -0,0 +1,208 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/gabisonia/go-vectorstore/vectordata/cache"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+// scriptedCollection fails with err and takes latency on the fake clock.
+type scriptedCollection struct {
+	vectordata.Collection
+	clock   *fakeClock
+	err     error
+	latency time.Duration
+	calls   int
+}
+
+func (c *scriptedCollection) Name() string { return "docs" }
+
+func (c *scriptedCollection) SearchByVector(context.Context, []float32, int, vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.calls++
+	c.clock.now = c.clock.now.Add(c.latency)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []vectordata.SearchResult{{Record: vectordata.Record{ID: "r1"}}}, nil
+}
+
+func (c *scriptedCollection) Upsert(context.Context, []vectordata.Record) error {
+	c.calls++
+	return c.err
+}
+
+func newTestBreaker(opts Options) (*Breaker, *scriptedCollection) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New(opts)
+	b.now = clock.Now
+	return b, &scriptedCollection{clock: clock}
+}
+
+func searchN(collection vectordata.Collection, n int) (err error) {
+	for i := 0; i < n; i++ {
+		_, err = collection.SearchByVector(context.Background(), []float32{1}, 1, vectordata.SearchOptions{})
+	}
+	return err
+}
+
+func TestBreaker_OpensOnErrorRate(t *testing.T) {
+	// Arrange
+	b, base := newTestBreaker(Options{MinRequests: 4, ErrorRate: 0.5})
+	collection := vectordata.WrapCollection(base, b.Middleware())
+	_ = searchN(collection, 2)
+	base.err = errUnavailable
+
+	// Act
+	_ = searchN(collection, 2)
+	err := searchN(collection, 1)
+
+	// Assert
+	if !errors.Is(err, ErrOpen) || base.calls != 4 || b.State("docs") != StateOpen {
+		t.Fatalf("expected an open circuit after 2 of 4 failures, got %v after %d calls (%s)", err, base.calls, b.State("docs"))
+	}
+}
+
+func TestBreaker_OpensOnSlowCalls(t *testing.T) {
+	// Arrange
+	b, base := newTestBreaker(Options{MinRequests: 2, SlowCallDuration: time.Second})
+	collection := vectordata.WrapCollection(base, b.Middleware())
+	base.latency = 2 * time.Second
+
+	// Act
+	first := searchN(collection, 2)
+	second := searchN(collection, 1)
+
+	// Assert
+	if first != nil || !errors.Is(second, ErrOpen) {
+		t.Fatalf("expected slow calls to succeed and then open the circuit, got %v and %v", first, second)
+	}
+}
+
+func TestBreaker_IgnoresRequestErrors(t *testing.T) {
+	// Arrange
+	b, base := newTestBreaker(Options{MinRequests: 2})
+	collection := vectordata.WrapCollection(base, b.Middleware())
+	base.err = vectordata.ErrInvalidFilter
+
+	// Act
+	_ = searchN(collection, 5)
+
+	// Assert
+	if b.State("docs") != StateClosed || base.calls != 5 {
+		t.Fatalf("expected request errors not to open the circuit, got %s after %d calls", b.State("docs"), base.calls)
+	}
+}
+
+func TestBreaker_ProbesAfterOpenDuration(t *testing.T) {
+	// Arrange
+	var transitions []State
+	b, base := newTestBreaker(Options{MinRequests: 1, OpenDuration: time.Minute, OnStateChange: func(_ string, _, to State) {
+		transitions = append(transitions, to)
+	}})
+	collection := vectordata.WrapCollection(base, b.Middleware())
+	base.err = errUnavailable
+	_ = searchN(collection, 1)
+
+	// Act
+	base.clock.now = base.clock.now.Add(time.Minute)
+	probe := searchN(collection, 1) // fails: reopens
+	rejected := searchN(collection, 1)
+	base.clock.now = base.clock.now.Add(time.Minute)
+	base.err = nil
+	recovered := searchN(collection, 1)
+
+	// Assert
+	if !errors.Is(probe, errUnavailable) || !errors.Is(rejected, ErrOpen) || recovered != nil {
+		t.Fatalf("unexpected results: probe %v, rejected %v, recovered %v", probe, rejected, recovered)
+	}
+	want := []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("expected transitions %v, got %v", want, transitions)
+		}
+	}
+}
+
+func TestBreaker_CircuitsArePerCollection(t *testing.T) {
+	// Arrange
+	b, base := newTestBreaker(Options{MinRequests: 1})
+	collection := vectordata.WrapCollection(base, b.Middleware())
+	base.err = errUnavailable
+
+	// Act
+	_ = searchN(collection, 1)
+
+	// Assert
+	if b.State("docs") != StateOpen || b.State("other") != StateClosed {
+		t.Fatalf("expected only docs to open, got %s and %s", b.State("docs"), b.State("other"))
+	}
+}
+
+func TestBreaker_ServesFallbackWhileOpen(t *testing.T) {
+	// Arrange
+	results := cache.New(cache.Options{TTL: time.Millisecond})
+	b, base := newTestBreaker(Options{MinRequests: 1, Fallback: results.Stale()})
+	collection := vectordata.WrapCollection(base, b.Middleware(), results.Middleware())
+	_ = searchN(collection, 1)
+	base.clock.now = base.clock.now.Add(time.Second)
+	time.Sleep(2 * time.Millisecond) // expire the cached entry
+	base.err = errUnavailable
+	_ = searchN(collection, 1)
+
+	// Act
+	stale, searchErr := collection.SearchByVector(context.Background(), []float32{1}, 1, vectordata.SearchOptions{})
+	_, missErr := collection.SearchByVector(context.Background(), []float32{2}, 1, vectordata.SearchOptions{})
+	writeErr := collection.Upsert(context.Background(), []vectordata.Record{{ID: "r2"}})
+
+	// Assert
+	if searchErr != nil || len(stale) != 1 || stale[0].Record.ID != "r1" {
+		t.Fatalf("expected the stale result, got %+v, %v", stale, searchErr)
+	}
+	if !errors.Is(missErr, ErrOpen) || !errors.Is(writeErr, ErrOpen) {
+		t.Fatalf("expected ErrOpen without a fallback result, got %v and %v", missErr, writeErr)
+	}
+	if base.calls != 2 {
+		t.Fatalf("expected the open circuit to shield the collection, got %d calls", base.calls)
+	}
+}
+
+func TestDefaultIsFailure(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errUnavailable, true},
+		{fmt.Errorf("%w: connection reset", vectordata.ErrUnavailable), true},
+		{vectordata.ErrTimeout, true},
+		{fmt.Errorf("%w: extension vector is missing", vectordata.ErrNotReady), true},
+		{fmt.Errorf("get: %w", vectordata.ErrNotFound), false},
+		{vectordata.ErrInvalidFilter, false},
+		{vectordata.ErrDimensionMismatch, false},
+		{vectordata.ErrSchemaMismatch, false},
+		{fmt.Errorf("%w: empty ID", vectordata.ErrInvalidRecord), false},
+		{fmt.Errorf("%w: duplicate key", vectordata.ErrConflict), false},
+		{fmt.Errorf("%w: batch of 10 MB", vectordata.ErrTooLarge), false},
+		{fmt.Errorf("%w: malformed cursor", vectordata.ErrInvalidCursor), false},
+		{fmt.Errorf("%w: cursors cannot be combined with OrderBy", errors.ErrUnsupported), false},
+		{context.Canceled, false},
+	}
+	for _, tc := range cases {
+		if got := DefaultIsFailure(tc.err); got != tc.want {
+			t.Fatalf("DefaultIsFailure(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
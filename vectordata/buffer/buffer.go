@@ -0,0 +1,321 @@
+// Package buffer batches vectordata writes, e.g. from a consumer that
+// receives one record per message:
+//
+//	writer := buffer.New(buffer.Options{
+//		MaxRecords:    500,
+//		FlushInterval: time.Second,
+//		OnError:       func(err *buffer.FlushError) { log.Printf("flush: %v", err) },
+//	})
+//	defer writer.Close(ctx)
+//
+//	err := writer.Write(ctx, docs, record)
+//
+// Records are buffered per collection and upserted in the background once
+// MaxRecords are buffered or FlushInterval has passed, whichever comes first.
+// A record buffered twice before a flush is upserted once, with its last
+// value.
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultMaxRecords    = 500
+	defaultFlushInterval = time.Second
+)
+
+// ErrClosed is returned by writes to a closed BufferedWriter.
+var ErrClosed = errors.New("buffer: writer is closed")
+
+// Options configures a BufferedWriter.
+type Options struct {
+	// MaxRecords is the number of buffered records of a collection that
+	// triggers a flush, and the most records upserted at a time
+	// (default 500).
+	MaxRecords int
+	// FlushInterval is the longest a record waits in the buffer
+	// (default one second).
+	FlushInterval time.Duration
+	// MaxPending bounds the records of a collection that are buffered or
+	// being flushed. Write blocks while a collection is at the bound, so a
+	// slow backend slows producers down instead of growing the buffer
+	// (default four times MaxRecords).
+	MaxPending int
+	// OnError, when set, is called from the flushing goroutine for every
+	// failed flush. The records of a failed flush are dropped; OnError may
+	// write them again.
+	OnError func(err *FlushError)
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = defaultMaxRecords
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaultFlushInterval
+	}
+	if o.MaxPending < o.MaxRecords {
+		o.MaxPending = 4 * o.MaxRecords
+	}
+	return o
+}
+
+// FlushError is a failed upsert of buffered records.
+type FlushError struct {
+	Collection string
+	Records    []vectordata.Record
+	Err        error
+}
+
+func (e *FlushError) Error() string {
+	return fmt.Sprintf("buffer: flush %d records to %q: %v", len(e.Records), e.Collection, e.Err)
+}
+
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// BufferedWriter buffers records per collection name and upserts them in the
+// background, one flushing goroutine per collection. It is safe for
+// concurrent use. Close it to flush what is left.
+type BufferedWriter struct {
+	opts    Options
+	closing chan struct{}
+	// ctx is the context of background upserts, cancelled when Close gives
+	// up waiting or returns.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	queues map[string]*queue
+}
+
+type queue struct {
+	collection vectordata.Collection
+	full       chan struct{}
+	flushes    chan chan error
+	stopped    chan struct{}
+	// drainErr is the error of the final flush, set before stopped closes.
+	drainErr error
+
+	mu      sync.Mutex
+	records []vectordata.Record
+	index   map[string]int
+	// pending counts buffered records and records being flushed.
+	pending int
+	// space is closed and replaced whenever pending drops.
+	space chan struct{}
+}
+
+// New returns a BufferedWriter with opts.
+func New(opts Options) *BufferedWriter {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BufferedWriter{
+		opts:    opts.withDefaults(),
+		closing: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		queues:  make(map[string]*queue),
+	}
+}
+
+// Write buffers records for collection, waiting until ctx is done while the
+// collection has MaxPending records pending. Buffers are keyed by collection
+// name, so write each name through one collection handle. Write returns
+// before the records are flushed; failed flushes are reported to OnError,
+// and to Flush and Close.
+func (w *BufferedWriter) Write(ctx context.Context, collection vectordata.Collection, records ...vectordata.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for {
+		w.mu.RLock()
+		if w.closed {
+			w.mu.RUnlock()
+			return ErrClosed
+		}
+		q, ok := w.queues[collection.Name()]
+		if !ok {
+			w.mu.RUnlock()
+			w.addQueue(collection)
+			continue
+		}
+
+		q.mu.Lock()
+		if q.pending == 0 || q.pending+len(records) <= w.opts.MaxPending {
+			q.add(records)
+			full := len(q.records) >= w.opts.MaxRecords
+			q.mu.Unlock()
+			w.mu.RUnlock()
+			if full {
+				select {
+				case q.full <- struct{}{}:
+				default:
+				}
+			}
+			return nil
+		}
+		space := q.space
+		q.mu.Unlock()
+		w.mu.RUnlock()
+
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *BufferedWriter) addQueue(collection vectordata.Collection) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.queues[collection.Name()]; ok || w.closed {
+		return
+	}
+	q := &queue{
+		collection: collection,
+		full:       make(chan struct{}, 1),
+		flushes:    make(chan chan error),
+		stopped:    make(chan struct{}),
+		index:      make(map[string]int),
+		space:      make(chan struct{}),
+	}
+	w.queues[collection.Name()] = q
+	go w.run(q)
+}
+
+// add buffers records, replacing buffered records with the same ID. The
+// queue lock must be held.
+func (q *queue) add(records []vectordata.Record) {
+	for _, record := range records {
+		if i, ok := q.index[record.ID]; ok {
+			q.records[i] = record
+			continue
+		}
+		q.index[record.ID] = len(q.records)
+		q.records = append(q.records, record)
+		q.pending++
+	}
+}
+
+func (w *BufferedWriter) run(q *queue) {
+	defer close(q.stopped)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.full:
+			w.flush(q)
+		case <-ticker.C:
+			w.flush(q)
+		case done := <-q.flushes:
+			done <- w.flush(q)
+		case <-w.closing:
+			q.drainErr = w.flush(q)
+			return
+		}
+	}
+}
+
+// flush upserts every buffered record in chunks of MaxRecords and returns
+// the failures joined.
+func (w *BufferedWriter) flush(q *queue) error {
+	q.mu.Lock()
+	records := q.records
+	q.records = nil
+	clear(q.index)
+	q.mu.Unlock()
+
+	var errs []error
+	for start := 0; start < len(records); start += w.opts.MaxRecords {
+		chunk := records[start:min(start+w.opts.MaxRecords, len(records))]
+		if err := q.collection.Upsert(w.ctx, chunk); err != nil {
+			flushErr := &FlushError{Collection: q.collection.Name(), Records: chunk, Err: err}
+			if w.opts.OnError != nil {
+				w.opts.OnError(flushErr)
+			}
+			errs = append(errs, flushErr)
+		}
+		q.mu.Lock()
+		q.pending -= len(chunk)
+		close(q.space)
+		q.space = make(chan struct{})
+		q.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Flush upserts every buffered record now and waits until ctx is done for
+// the flushes to finish. It returns the failed flushes joined.
+func (w *BufferedWriter) Flush(ctx context.Context) error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return ErrClosed
+	}
+	queues := make([]*queue, 0, len(w.queues))
+	for _, q := range w.queues {
+		queues = append(queues, q)
+	}
+	w.mu.RUnlock()
+
+	var errs []error
+	for _, q := range queues {
+		done := make(chan error, 1)
+		select {
+		case q.flushes <- done:
+		case <-q.stopped:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case err := <-done:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops accepting writes, flushes what is buffered and waits until ctx
+// is done for the flushes to finish. It returns the failed final flushes
+// joined, or ctx's error when they did not finish in time, in which case the
+// upserts still running are cancelled. Writers blocked on a full collection
+// fail with ErrClosed.
+func (w *BufferedWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	queues := make([]*queue, 0, len(w.queues))
+	for _, q := range w.queues {
+		queues = append(queues, q)
+	}
+	w.mu.Unlock()
+	close(w.closing)
+	defer w.cancel()
+
+	var errs []error
+	for _, q := range queues {
+		select {
+		case <-q.stopped:
+			errs = append(errs, q.drainErr)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,180 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// recordingCollection records the batches upserted into it. Upserts wait
+// for release when it is set, or fail with the error of ctx if it is done
+// first, and fail with err when it is set.
+type recordingCollection struct {
+	vectordata.Collection
+	release chan struct{}
+	err     error
+
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (c *recordingCollection) Name() string { return "docs" }
+
+func (c *recordingCollection) Upsert(ctx context.Context, records []vectordata.Record) error {
+	if c.release != nil {
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	c.mu.Lock()
+	c.batches = append(c.batches, ids)
+	c.mu.Unlock()
+	return c.err
+}
+
+func (c *recordingCollection) flushed() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]string(nil), c.batches...)
+}
+
+func TestBufferedWriter_FlushesFullBuffers(t *testing.T) {
+	// Arrange
+	base := &recordingCollection{}
+	writer := New(Options{MaxRecords: 2, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	// Act
+	err := writer.Write(ctx, base, vectordata.Record{ID: "a"}, vectordata.Record{ID: "b"})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(base.flushed()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if batches := base.flushed(); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("expected one flush of two records, got %v", batches)
+	}
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestBufferedWriter_CloseDrainsAndKeepsLastValue(t *testing.T) {
+	// Arrange
+	base := &recordingCollection{}
+	writer := New(Options{MaxRecords: 10, FlushInterval: time.Hour})
+	ctx := context.Background()
+	_ = writer.Write(ctx, base, vectordata.Record{ID: "a"}, vectordata.Record{ID: "b"})
+	_ = writer.Write(ctx, base, vectordata.Record{ID: "a", Metadata: map[string]any{"v": 2}})
+
+	// Act
+	closeErr := writer.Close(ctx)
+	writeErr := writer.Write(ctx, base, vectordata.Record{ID: "c"})
+
+	// Assert
+	if closeErr != nil {
+		t.Fatalf("Close: %v", closeErr)
+	}
+	if batches := base.flushed(); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("expected a and b flushed once, got %v", batches)
+	}
+	if !errors.Is(writeErr, ErrClosed) {
+		t.Fatalf("expected ErrClosed after Close, got %v", writeErr)
+	}
+}
+
+func TestBufferedWriter_BackpressureWaitsForFlush(t *testing.T) {
+	// Arrange
+	base := &recordingCollection{release: make(chan struct{})}
+	writer := New(Options{MaxRecords: 1, MaxPending: 1, FlushInterval: time.Hour})
+	ctx := context.Background()
+	_ = writer.Write(ctx, base, vectordata.Record{ID: "a"})
+	blocked, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	blockedErr := writer.Write(blocked, base, vectordata.Record{ID: "b"})
+	close(base.release)
+	afterErr := writer.Write(ctx, base, vectordata.Record{ID: "b"})
+
+	// Assert
+	if !errors.Is(blockedErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the write to wait for the pending flush, got %v", blockedErr)
+	}
+	if afterErr != nil {
+		t.Fatalf("expected the write to succeed once the flush finished, got %v", afterErr)
+	}
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestBufferedWriter_ReportsFailedFlushes(t *testing.T) {
+	// Arrange
+	base := &recordingCollection{err: vectordata.ErrConflict}
+	var reported []*FlushError
+	writer := New(Options{FlushInterval: time.Hour, OnError: func(err *FlushError) { reported = append(reported, err) }})
+	ctx := context.Background()
+	_ = writer.Write(ctx, base, vectordata.Record{ID: "a"})
+
+	// Act
+	err := writer.Flush(ctx)
+
+	// Assert
+	var flushErr *FlushError
+	if !errors.As(err, &flushErr) || !errors.Is(err, vectordata.ErrConflict) || flushErr.Collection != "docs" || len(flushErr.Records) != 1 {
+		t.Fatalf("expected a FlushError for a, got %v", err)
+	}
+	if len(reported) != 1 || reported[0] != flushErr {
+		t.Fatalf("expected OnError to see the same error, got %v", reported)
+	}
+	if err := writer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestBufferedWriter_CloseCancelsStuckFlushes(t *testing.T) {
+	// Arrange
+	base := &recordingCollection{release: make(chan struct{})}
+	failed := make(chan error, 1)
+	writer := New(Options{FlushInterval: time.Millisecond, OnError: func(err *FlushError) {
+		select {
+		case failed <- err:
+		default:
+		}
+	}})
+	_ = writer.Write(context.Background(), base, vectordata.Record{ID: "a"})
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := writer.Close(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Close to give up, got %v", err)
+	}
+	select {
+	case err := <-failed:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the stuck flush to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stuck flush to be cancelled")
+	}
+}
@@ -0,0 +1,328 @@
+// Package cache caches search and count results of vectordata collections.
+//
+// A Cache is a collection middleware with LRU eviction and a TTL:
+//
+//	results := cache.New(cache.Options{MaxEntries: 4096, TTL: time.Minute})
+//	store = vectordata.WrapStore(store, results.Middleware())
+//
+// Entries are keyed by collection name, operation, query vector or text,
+// topK and options, so one Cache should serve one store. Insert, Upsert,
+// Delete and EnsureIndexes through the middleware invalidate every entry of
+// their collection; writes made elsewhere (another process, or a collection
+// handle without the middleware) are only seen once entries expire.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultMaxEntries = 1024
+	defaultTTL        = time.Minute
+)
+
+// ErrMiss is returned by the Stale handler when no entry matches a call.
+var ErrMiss = errors.New("cache: no cached result")
+
+// Options configures a Cache.
+type Options struct {
+	// MaxEntries bounds the cache; the least recently used entry is evicted
+	// first (default 1024).
+	MaxEntries int
+	// TTL is how long an entry is served (default 1 minute).
+	TTL time.Duration
+	// Scope, when set, adds a per-request value to every key, for results
+	// that depend on the context, e.g. a tenant set with postgres.WithTenant:
+	//
+	//	Scope: func(ctx context.Context) string { tenant, _ := postgres.TenantFromContext(ctx); return tenant }
+	Scope func(ctx context.Context) string
+}
+
+type key [sha256.Size]byte
+
+type entry struct {
+	key     key
+	results []vectordata.SearchResult
+	count   int64
+	expires time.Time
+}
+
+// Cache is an LRU cache of search and count results, safe for concurrent
+// use.
+type Cache struct {
+	maxEntries int
+	ttl        time.Duration
+	scope      func(ctx context.Context) string
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[key]*list.Element
+	order   *list.List // front is most recently used
+	// generations counts invalidations per collection. Keys include the
+	// generation, so a write makes older entries unreachable, including
+	// those stored by searches that raced with it.
+	generations map[string]uint64
+}
+
+// New returns an empty cache.
+func New(opts Options) *Cache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultMaxEntries
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+	return &Cache{
+		maxEntries:  opts.MaxEntries,
+		ttl:         opts.TTL,
+		scope:       opts.Scope,
+		now:         time.Now,
+		entries:     make(map[key]*list.Element),
+		order:       list.New(),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Collection returns base with a cache of its own.
+func Collection(base vectordata.Collection, opts Options) vectordata.Collection {
+	return vectordata.WrapCollection(base, New(opts).Middleware())
+}
+
+// Middleware returns the collection middleware serving and filling the
+// cache.
+func (c *Cache) Middleware() vectordata.CollectionMiddleware {
+	return c.middleware
+}
+
+// Invalidate drops every entry of a collection.
+func (c *Cache) Invalidate(collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[collection]++
+}
+
+// Len returns the number of entries, including expired and invalidated ones
+// not yet evicted or replaced.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stale returns a handler serving Count and search calls from the cache,
+// expired entries included, and failing with ErrMiss otherwise. It is meant
+// as a fallback while the collection is unavailable, e.g. for
+// breaker.Options.Fallback; entries invalidated by writes are never served.
+func (c *Cache) Stale() vectordata.CallHandler {
+	return func(ctx context.Context, call *vectordata.Call) error {
+		if !cacheable(call.Operation) {
+			return ErrMiss
+		}
+		cached, ok := c.get(c.key(ctx, call.Collection.Name(), call), true)
+		if !ok {
+			return ErrMiss
+		}
+		call.Results = cloneResults(cached.results)
+		call.Count = cached.count
+		return nil
+	}
+}
+
+func cacheable(op vectordata.Operation) bool {
+	switch op {
+	case vectordata.OpCount, vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Cache) middleware(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+	name := call.Collection.Name()
+	switch call.Operation {
+	case vectordata.OpInsert, vectordata.OpUpsert, vectordata.OpDelete, vectordata.OpEnsureIndexes:
+		// Invalidate after the write, failed or not, so entries cached by
+		// searches that raced with it are dropped too.
+		err := next(ctx, call)
+		c.Invalidate(name)
+		return err
+	}
+	if !cacheable(call.Operation) {
+		return next(ctx, call)
+	}
+
+	k := c.key(ctx, name, call)
+	if cached, ok := c.get(k, false); ok {
+		call.Results = cloneResults(cached.results)
+		call.Count = cached.count
+		return nil
+	}
+	if err := next(ctx, call); err != nil {
+		return err
+	}
+	c.put(k, call.Results, call.Count)
+	return nil
+}
+
+func (c *Cache) get(k key, stale bool) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	// Expired entries stay until evicted or replaced, for Stale.
+	if !stale && !c.now().Before(cached.expires) {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return cached, true
+}
+
+func (c *Cache) put(k key, results []vectordata.SearchResult, count int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached := &entry{key: k, results: cloneResults(results), count: count, expires: c.now().Add(c.ttl)}
+	if element, ok := c.entries[k]; ok {
+		element.Value = cached
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[k] = c.order.PushFront(cached)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// key hashes everything that determines a call's result.
+func (c *Cache) key(ctx context.Context, collection string, call *vectordata.Call) key {
+	c.mu.Lock()
+	generation := c.generations[collection]
+	c.mu.Unlock()
+
+	h := sha256.New()
+	if c.scope != nil {
+		fmt.Fprintf(h, "scope %q\n", c.scope(ctx))
+	}
+	fmt.Fprintf(h, "%q %d %s %d %q\n", collection, generation, call.Operation, call.TopK, call.Text)
+	for _, v := range call.Vector {
+		_ = binary.Write(h, binary.LittleEndian, math.Float32bits(v))
+	}
+	fmt.Fprintln(h)
+	writeFilter(h, "filter", call.ActiveFilter())
+	switch call.Operation {
+	case vectordata.OpSearchByVector:
+		opts := call.SearchOptions
+		writeProjection(h, opts.Projection)
+		if opts.Threshold != nil {
+			fmt.Fprintf(h, "threshold %v\n", *opts.Threshold)
+		}
+		fmt.Fprintf(h, "%#v\n", opts.SessionSettings)
+		fmt.Fprintf(h, "norm %#v\n", opts.ScoreNormalization)
+		for _, negative := range opts.Negatives {
+			writeWeightedVector(h, "negative", negative.Vector, negative.Weight)
+		}
+		for _, query := range opts.Queries {
+			writeWeightedVector(h, "query", query.Vector, query.Weight)
+		}
+		fmt.Fprintf(h, "fusion %q\n", opts.QueryFusion)
+		if decay := opts.TimeDecay; decay != nil {
+			fmt.Fprintf(h, "decay %#v %d %v %d\n", decay.Field, decay.HalfLife, decay.Weight, decay.Now.UnixNano())
+		}
+		for _, boost := range opts.Boosts {
+			fmt.Fprintf(h, "boost %v %v ", boost.Multiply, boost.Add)
+			writeFilter(h, "when", boost.When)
+		}
+		fmt.Fprintf(h, "boost candidates %d\n", opts.BoostCandidates)
+		fmt.Fprintf(h, "cursor %q\n", opts.Cursor)
+		fmt.Fprintf(h, "order %#v\n", opts.OrderBy)
+	case vectordata.OpSearchByText:
+		writeProjection(h, call.TextSearchOptions.Projection)
+		writeHighlight(h, call.TextSearchOptions.Highlight)
+	case vectordata.OpHybridSearch:
+		opts := call.HybridSearchOptions
+		writeProjection(h, opts.Projection)
+		writeHighlight(h, opts.Highlight)
+		fmt.Fprintf(h, "%q %v %v\n", opts.RankProfile, opts.VectorWeight, opts.TextWeight)
+	}
+
+	var k key
+	h.Sum(k[:0])
+	return k
+}
+
+func writeWeightedVector(h io.Writer, kind string, vector []float32, weight float64) {
+	fmt.Fprintf(h, "%s %v", kind, weight)
+	for _, v := range vector {
+		_ = binary.Write(h, binary.LittleEndian, math.Float32bits(v))
+	}
+	fmt.Fprintln(h)
+}
+
+// writeFilter hashes filter by its JSON form, so equal filters share a key
+// whatever pointers they hold. Filters the JSON DSL cannot express fall back
+// to their Go syntax.
+func writeFilter(h io.Writer, label string, filter vectordata.Filter) {
+	if encoded, err := vectordata.MarshalFilterJSON(filter); err == nil {
+		fmt.Fprintf(h, "%s %s\n", label, encoded)
+		return
+	}
+	fmt.Fprintf(h, "%s %#v\n", label, filter)
+}
+
+func writeProjection(h io.Writer, projection *vectordata.Projection) {
+	if projection == nil {
+		fmt.Fprintln(h, "projection default")
+		return
+	}
+	fmt.Fprintf(h, "projection %t %t %t\n", projection.IncludeVector, projection.IncludeMetadata, projection.IncludeContent)
+	writeRedaction(h, projection.Redact)
+}
+
+// writeRedaction hashes the settings of redaction rather than its address,
+// so redactions built per request share cache entries.
+func writeRedaction(h io.Writer, redaction *vectordata.Redaction) {
+	if redaction == nil {
+		fmt.Fprintln(h, "redact none")
+		return
+	}
+	keys := slices.Sorted(slices.Values(redaction.MetadataKeys))
+	patterns := make([]string, len(redaction.ContentPatterns))
+	for i, pattern := range redaction.ContentPatterns {
+		patterns[i] = pattern.String()
+	}
+	fmt.Fprintf(h, "redact %q %q %q\n", keys, patterns, redaction.Replacement)
+}
+
+// cloneResults copies the result slice, so callers appending to or
+// reordering it do not change the cache. Records are shared and must not be
+// modified.
+func cloneResults(results []vectordata.SearchResult) []vectordata.SearchResult {
+	if results == nil {
+		return nil
+	}
+	return append([]vectordata.SearchResult(nil), results...)
+}
+
+func writeHighlight(h io.Writer, highlight *vectordata.HighlightOptions) {
+	if highlight == nil {
+		fmt.Fprintln(h, "highlight none")
+		return
+	}
+	fmt.Fprintf(h, "highlight %q %q %d %d\n", highlight.StartSel, highlight.StopSel, highlight.MaxWords, highlight.MaxFragments)
+}
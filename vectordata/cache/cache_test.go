@@ -0,0 +1,310 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+type countingCollection struct {
+	vectordata.Collection
+	searches int
+	counts   int
+	version  string
+}
+
+func (c *countingCollection) Name() string { return "docs" }
+
+func (c *countingCollection) SearchByVector(context.Context, []float32, int, vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	c.searches++
+	return []vectordata.SearchResult{{Record: vectordata.Record{ID: c.version}}}, nil
+}
+
+func (c *countingCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	c.counts++
+	return 42, nil
+}
+
+func (c *countingCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	c.version = records[0].ID
+	return nil
+}
+
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func newTestCache(opts Options) (*Cache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := New(opts)
+	c.now = clock.Now
+	return c, clock
+}
+
+func search(t *testing.T, collection vectordata.Collection, ctx context.Context, vector []float32, topK int, opts vectordata.SearchOptions) string {
+	t.Helper()
+	results, err := collection.SearchByVector(ctx, vector, topK, opts)
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	return results[0].Record.ID
+}
+
+func TestCache_ServesIdenticalQueries(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	filter := vectordata.Eq(vectordata.Metadata("category"), "news")
+	ctx := context.Background()
+
+	// Act
+	search(t, collection, ctx, []float32{1, 0}, 5, vectordata.SearchOptions{Filter: filter})
+	search(t, collection, ctx, []float32{1, 0}, 5, vectordata.SearchOptions{Filter: vectordata.Eq(vectordata.Metadata("category"), "news")})
+	search(t, collection, ctx, []float32{1, 0}, 6, vectordata.SearchOptions{Filter: filter})
+	search(t, collection, ctx, []float32{0, 1}, 5, vectordata.SearchOptions{Filter: filter})
+	search(t, collection, ctx, []float32{1, 0}, 5, vectordata.SearchOptions{Filter: vectordata.Eq(vectordata.Metadata("category"), "blog")})
+	search(t, collection, ctx, []float32{1, 0}, 5, vectordata.SearchOptions{Filter: filter, Negatives: []vectordata.NegativeVector{{Vector: []float32{0, 1}}}})
+	search(t, collection, ctx, nil, 5, vectordata.SearchOptions{Filter: filter, Queries: []vectordata.QueryVector{{Vector: []float32{1, 0}}, {Vector: []float32{0, 1}}}})
+	first, _ := collection.Count(ctx, filter)
+	second, _ := collection.Count(ctx, filter)
+
+	// Assert
+	if base.searches != 6 {
+		t.Fatalf("expected 6 distinct searches to reach the collection, got %d", base.searches)
+	}
+	if base.counts != 1 || first != 42 || second != 42 {
+		t.Fatalf("expected one count to reach the collection, got %d (%d, %d)", base.counts, first, second)
+	}
+}
+
+func TestCache_WritesInvalidateTheCollection(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	before := search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Act
+	if err := collection.Upsert(ctx, []vectordata.Record{{ID: "v2", Vector: []float32{1}}}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	after := search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if before != "v1" || after != "v2" || base.searches != 2 {
+		t.Fatalf("expected the upsert to invalidate the cached search, got %s then %s after %d searches", before, after, base.searches)
+	}
+}
+
+func TestCache_ExpiresEntriesAfterTTL(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, clock := newTestCache(Options{TTL: time.Second})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Act
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+	clock.now = clock.now.Add(time.Second)
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if base.searches != 2 {
+		t.Fatalf("expected the entry to be served until it expired, got %d searches", base.searches)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{MaxEntries: 2})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+	search(t, collection, ctx, []float32{2}, 1, vectordata.SearchOptions{})
+
+	// Act
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{}) // touch {1}
+	search(t, collection, ctx, []float32{3}, 1, vectordata.SearchOptions{}) // evicts {2}
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+	search(t, collection, ctx, []float32{2}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if base.searches != 4 || cache.Len() != 2 {
+		t.Fatalf("expected {2} to be evicted and refetched, got %d searches and %d entries", base.searches, cache.Len())
+	}
+}
+
+func TestCache_ScopeSeparatesContexts(t *testing.T) {
+	// Arrange
+	type tenantKey struct{}
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{Scope: func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	}})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+
+	// Act
+	search(t, collection, context.WithValue(context.Background(), tenantKey{}, "acme"), []float32{1}, 1, vectordata.SearchOptions{})
+	search(t, collection, context.WithValue(context.Background(), tenantKey{}, "globex"), []float32{1}, 1, vectordata.SearchOptions{})
+	search(t, collection, context.WithValue(context.Background(), tenantKey{}, "acme"), []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if base.searches != 2 {
+		t.Fatalf("expected one search per tenant, got %d", base.searches)
+	}
+}
+
+func TestCache_ReturnsCopiesOfCachedResults(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	collection := Collection(base, Options{})
+	ctx := context.Background()
+	first, _ := collection.SearchByVector(ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Act
+	first[0] = vectordata.SearchResult{Record: vectordata.Record{ID: "mutated"}}
+	second := search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if second != "v1" {
+		t.Fatalf("expected the cached result to be unaffected, got %s", second)
+	}
+}
+
+func TestCache_StaleServesExpiredButNotInvalidatedEntries(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, clock := newTestCache(Options{TTL: time.Second})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	search(t, collection, ctx, []float32{1}, 1, vectordata.SearchOptions{})
+	clock.now = clock.now.Add(time.Hour)
+	call := func() *vectordata.Call {
+		return &vectordata.Call{Operation: vectordata.OpSearchByVector, Collection: base, Vector: []float32{1}, TopK: 1}
+	}
+
+	// Act
+	expired := call()
+	expiredErr := cache.Stale()(ctx, expired)
+	cache.Invalidate("docs")
+	invalidatedErr := cache.Stale()(ctx, call())
+
+	// Assert
+	if expiredErr != nil || len(expired.Results) != 1 {
+		t.Fatalf("expected the expired entry to be served, got %v", expiredErr)
+	}
+	if !errors.Is(invalidatedErr, ErrMiss) {
+		t.Fatalf("expected ErrMiss after invalidation, got %v", invalidatedErr)
+	}
+}
+
+func TestCache_ScoreNormalizationSeparatesEntries(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	normalized := vectordata.SearchOptions{ScoreNormalization: vectordata.ScoreNormalization{Method: vectordata.ScoreNormalizationMinMax}}
+
+	// Act
+	search(t, collection, ctx, []float32{1, 0}, 5, vectordata.SearchOptions{})
+	search(t, collection, ctx, []float32{1, 0}, 5, normalized)
+	search(t, collection, ctx, []float32{1, 0}, 5, normalized)
+
+	// Assert
+	if base.searches != 2 {
+		t.Fatalf("expected raw and normalized searches to reach the collection separately, got %d", base.searches)
+	}
+}
+
+func TestCache_KeysByValueNotPointer(t *testing.T) {
+	// Arrange
+	base := &countingCollection{version: "v1"}
+	cache, _ := newTestCache(Options{})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	ctx := context.Background()
+	options := func(replacement string) vectordata.SearchOptions {
+		projection := vectordata.DefaultProjection()
+		projection.Redact = &vectordata.Redaction{
+			MetadataKeys:    []string{"email", "name"},
+			ContentPatterns: []*regexp.Regexp{regexp.MustCompile(`\d+`)},
+			Replacement:     replacement,
+		}
+		return vectordata.SearchOptions{
+			Projection: &projection,
+			TimeDecay:  &vectordata.TimeDecay{Field: vectordata.Metadata("at"), HalfLife: time.Hour, Now: time.Unix(100, 0).In(time.FixedZone("x", 3600))},
+		}
+	}
+
+	// Act
+	search(t, collection, ctx, []float32{1, 0}, 5, options("***"))
+	search(t, collection, ctx, []float32{1, 0}, 5, options("***"))
+	search(t, collection, ctx, []float32{1, 0}, 5, options("[hidden]"))
+
+	// Assert
+	if base.searches != 2 {
+		t.Fatalf("expected equal redactions to share an entry and others not to, got %d searches", base.searches)
+	}
+}
+
+// textCollection adds text and hybrid search to countingCollection, with
+// snippets when a highlight is requested.
+type textCollection struct {
+	*countingCollection
+}
+
+func (c textCollection) SearchByText(_ context.Context, _ string, _ int, opts vectordata.TextSearchOptions) ([]vectordata.SearchResult, error) {
+	c.searches++
+	return highlighted(c.version, opts.Highlight), nil
+}
+
+func (c textCollection) HybridSearch(_ context.Context, _ []float32, _ string, _ int, opts vectordata.HybridSearchOptions) ([]vectordata.SearchResult, error) {
+	c.searches++
+	return highlighted(c.version, opts.Highlight), nil
+}
+
+func highlighted(id string, highlight *vectordata.HighlightOptions) []vectordata.SearchResult {
+	result := vectordata.SearchResult{Record: vectordata.Record{ID: id}}
+	if highlight != nil {
+		result.Snippets = []string{"<b>match</b>"}
+	}
+	return []vectordata.SearchResult{result}
+}
+
+func TestCache_HighlightSeparatesEntries(t *testing.T) {
+	// Arrange
+	base := textCollection{&countingCollection{version: "v1"}}
+	cache, _ := newTestCache(Options{})
+	collection := vectordata.WrapCollection(base, cache.Middleware())
+	text := collection.(vectordata.TextSearcher)
+	hybrid := collection.(vectordata.HybridSearcher)
+	ctx := context.Background()
+	highlight := &vectordata.HighlightOptions{MaxWords: 10}
+
+	// Act
+	plainText, _ := text.SearchByText(ctx, "match", 5, vectordata.TextSearchOptions{})
+	highlightedText, _ := text.SearchByText(ctx, "match", 5, vectordata.TextSearchOptions{Highlight: highlight})
+	plainHybrid, _ := hybrid.HybridSearch(ctx, []float32{1, 0}, "match", 5, vectordata.HybridSearchOptions{})
+	highlightedHybrid, _ := hybrid.HybridSearch(ctx, []float32{1, 0}, "match", 5, vectordata.HybridSearchOptions{Highlight: highlight})
+
+	// Assert
+	if base.searches != 4 {
+		t.Fatalf("expected every search to reach the collection, got %d", base.searches)
+	}
+	if len(plainText[0].Snippets) != 0 || len(plainHybrid[0].Snippets) != 0 {
+		t.Fatalf("expected no snippets without a highlight, got %v and %v", plainText[0].Snippets, plainHybrid[0].Snippets)
+	}
+	if len(highlightedText[0].Snippets) != 1 || len(highlightedHybrid[0].Snippets) != 1 {
+		t.Fatalf("expected snippets with a highlight, got %v and %v", highlightedText[0].Snippets, highlightedHybrid[0].Snippets)
+	}
+}
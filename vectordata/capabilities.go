@@ -0,0 +1,89 @@
+package vectordata
+
+import "slices"
+
+// Capabilities describes the optional features of a backend, so callers
+// can degrade gracefully instead of probing with requests that fail.
+type Capabilities struct {
+	// Metrics lists the supported distance metrics.
+	Metrics []DistanceMetric
+	// TextSearch and HybridSearch report whether collections implement
+	// TextSearcher and HybridSearcher.
+	TextSearch   bool
+	HybridSearch bool
+	// IndexManagement reports whether EnsureIndexes builds indexes. Backends
+	// that maintain indexes themselves only validate the options.
+	IndexManagement bool
+	// IndexMethods lists the vector index methods EnsureIndexes accepts.
+	IndexMethods []IndexMethod
+	// MetadataIndexes, TextIndexes and TrigramIndexes report whether
+	// EnsureIndexes honors IndexOptions.Metadata, Text and Trigram.
+	MetadataIndexes bool
+	TextIndexes     bool
+	TrigramIndexes  bool
+	// Partitioning reports whether CollectionSpec.Partition is supported.
+	Partitioning bool
+	// NormalizeVectors reports whether CollectionSpec.NormalizeVectors is
+	// supported.
+	NormalizeVectors bool
+	// Float64Vectors reports whether CollectionSpec.ElementType may be
+	// ElementFloat64.
+	Float64Vectors bool
+	// Int8Vectors reports whether CollectionSpec.ElementType may be
+	// ElementInt8.
+	Int8Vectors bool
+	// FastVectors reports whether CollectionSpec.FastDimension is supported.
+	FastVectors bool
+	// SessionSettings reports whether SearchOptions.SessionSettings apply.
+	SessionSettings bool
+	// SearchCursors reports whether SearchByVector accepts
+	// SearchOptions.Cursor.
+	SearchCursors bool
+	// TTL reports whether records can expire on their own.
+	TTL bool
+}
+
+// SupportsMetric reports whether metric is in Metrics.
+func (c Capabilities) SupportsMetric(metric DistanceMetric) bool {
+	return slices.Contains(c.Metrics, normalizeMetric(metric))
+}
+
+// SupportsIndexMethod reports whether method is in IndexMethods.
+func (c Capabilities) SupportsIndexMethod(method IndexMethod) bool {
+	return slices.Contains(c.IndexMethods, method)
+}
+
+// CapabilityReporter is implemented by stores and collections that describe
+// their features.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// FilterChecker is implemented by collections that can tell, without
+// querying, whether they can execute a filter. SupportsFilter returns the
+// error a query with the filter would fail with, typically wrapping
+// ErrInvalidFilter, or nil.
+type FilterChecker interface {
+	SupportsFilter(filter Filter) error
+}
+
+// CapabilitiesOf returns the capabilities reported by v, a store or
+// collection. For a collection that does not report them, it returns the
+// TextSearch and HybridSearch flags its methods imply, and false.
+func CapabilitiesOf(v any) (Capabilities, bool) {
+	if reporter, ok := v.(CapabilityReporter); ok {
+		return reporter.Capabilities(), true
+	}
+	_, text := v.(TextSearcher)
+	_, hybrid := v.(HybridSearcher)
+	return Capabilities{TextSearch: text, HybridSearch: hybrid}, false
+}
+
+// SupportsFilter reports whether collection can execute filter, or nil when
+// the collection cannot tell.
+func SupportsFilter(collection Collection, filter Filter) error {
+	if checker, ok := collection.(FilterChecker); ok {
+		return checker.SupportsFilter(filter)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type capableCollection struct {
+	stubCollection
+}
+
+func (c *capableCollection) Capabilities() Capabilities {
+	return Capabilities{Metrics: []DistanceMetric{DistanceCosine}, IndexMethods: []IndexMethod{IndexMethodHNSW}}
+}
+
+func (c *capableCollection) SupportsFilter(filter Filter) error {
+	if _, ok := filter.(ExistsFilter); ok {
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+func TestCapabilitiesOf_ReportedAndDerived(t *testing.T) {
+	// Arrange
+	capable := &capableCollection{}
+	plain := &stubTextCollection{}
+
+	// Act
+	reported, reportedOK := CapabilitiesOf(capable)
+	derived, derivedOK := CapabilitiesOf(plain)
+
+	// Assert
+	if !reportedOK || !reported.SupportsMetric("") || reported.SupportsMetric(DistanceL2) || !reported.SupportsIndexMethod(IndexMethodHNSW) {
+		t.Fatalf("unexpected reported capabilities %+v", reported)
+	}
+	if derivedOK || !derived.TextSearch || derived.HybridSearch {
+		t.Fatalf("expected derived text search only, got %+v (%v)", derived, derivedOK)
+	}
+}
+
+func TestSupportsFilter_DelegatesThroughMiddleware(t *testing.T) {
+	// Arrange
+	passThrough := func(ctx context.Context, call *Call, next CallHandler) error { return next(ctx, call) }
+	wrapped := WrapCollection(&capableCollection{}, passThrough)
+	plain := WrapCollection(&stubCollection{}, passThrough)
+
+	// Act
+	unsupported := SupportsFilter(wrapped, Exists(Metadata("a")))
+	supported := SupportsFilter(wrapped, Eq(Metadata("a"), 1))
+	unknown := SupportsFilter(plain, Exists(Metadata("a")))
+	capabilities, _ := CapabilitiesOf(wrapped)
+
+	// Assert
+	if !errors.Is(unsupported, ErrInvalidFilter) || supported != nil || unknown != nil {
+		t.Fatalf("unexpected results: %v, %v, %v", unsupported, supported, unknown)
+	}
+	if !capabilities.SupportsIndexMethod(IndexMethodHNSW) {
+		t.Fatalf("expected the wrapped collection to report base capabilities, got %+v", capabilities)
+	}
+}
@@ -0,0 +1,151 @@
+package vectordata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// centroidPageSize is how many records Centroids lists at a time.
+const centroidPageSize = 1000
+
+// Centroid is the mean vector of the records in one group.
+type Centroid struct {
+	// Group is the value of the groupBy field: a string for columns, the
+	// decoded JSON value for metadata.
+	Group any
+	// Vector is the mean of the group's vectors scaled to unit length.
+	Vector []float32
+	// Count is how many records the group holds.
+	Count int64
+}
+
+// Centroids averages the vectors of the records matching filter per value
+// of groupBy and returns the unit-length centroid of each group, largest
+// groups first, e.g. to route queries or summarize topics. Records without
+// the field are left out.
+//
+// CentroidCalculators, such as the Postgres store, average in SQL. Other
+// collections must be RecordListers; their records are listed page by page
+// and summed as they stream in.
+func Centroids(ctx context.Context, collection Collection, groupBy FieldRef, filter Filter) ([]Centroid, error) {
+	groupBy, err := NormalizeFieldRef(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	var centroids []Centroid
+	if calculator, ok := collection.(CentroidCalculator); ok {
+		centroids, err = calculator.Centroids(ctx, groupBy, filter)
+	} else {
+		centroids, err = accumulateCentroids(ctx, collection, groupBy, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := sortCentroids(centroids); err != nil {
+		return nil, err
+	}
+	return centroids, nil
+}
+
+type centroidSum struct {
+	group any
+	sum   []float64
+	count int64
+}
+
+func accumulateCentroids(ctx context.Context, collection Collection, groupBy FieldRef, filter Filter) ([]Centroid, error) {
+	lister, ok := collection.(RecordLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: collection %q does not support List", errors.ErrUnsupported, collection.Name())
+	}
+	projection := &Projection{IncludeVector: true, IncludeMetadata: groupBy.Kind == FieldMetadata, IncludeContent: groupBy.Kind == FieldColumn}
+
+	sums := make(map[string]*centroidSum)
+	var order []string
+	after := ""
+	for {
+		page, err := lister.List(ctx, ListOptions{Filter: filter, After: after, Limit: centroidPageSize, Projection: projection})
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			group, present, err := resolveMatchField(groupBy, record)
+			if err != nil {
+				return nil, err
+			}
+			if !present {
+				continue
+			}
+			key, err := json.Marshal(group)
+			if err != nil {
+				return nil, err
+			}
+			acc, ok := sums[string(key)]
+			if !ok {
+				acc = &centroidSum{group: group, sum: make([]float64, len(record.Vector))}
+				sums[string(key)] = acc
+				order = append(order, string(key))
+			}
+			if len(record.Vector) != len(acc.sum) {
+				return nil, fmt.Errorf("%w: record %q has dimension %d, expected %d", ErrDimensionMismatch, record.ID, len(record.Vector), len(acc.sum))
+			}
+			for i, component := range record.Vector {
+				acc.sum[i] += float64(component)
+			}
+			acc.count++
+		}
+		if len(page) < centroidPageSize {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+
+	centroids := make([]Centroid, 0, len(order))
+	for _, key := range order {
+		acc := sums[key]
+		mean := make([]float32, len(acc.sum))
+		for i, sum := range acc.sum {
+			mean[i] = float32(sum / float64(acc.count))
+		}
+		centroids = append(centroids, Centroid{Group: acc.group, Vector: NormalizeVector(mean), Count: acc.count})
+	}
+	return centroids, nil
+}
+
+// sortCentroids orders centroids by descending Count, then by the JSON
+// encoding of their groups.
+func sortCentroids(centroids []Centroid) error {
+	keys := make([][]byte, len(centroids))
+	for i, centroid := range centroids {
+		key, err := json.Marshal(centroid.Group)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+	sort.Sort(centroidOrder{centroids: centroids, keys: keys})
+	return nil
+}
+
+type centroidOrder struct {
+	centroids []Centroid
+	keys      [][]byte
+}
+
+func (o centroidOrder) Len() int { return len(o.centroids) }
+
+func (o centroidOrder) Less(i, j int) bool {
+	if o.centroids[i].Count != o.centroids[j].Count {
+		return o.centroids[i].Count > o.centroids[j].Count
+	}
+	return bytes.Compare(o.keys[i], o.keys[j]) < 0
+}
+
+func (o centroidOrder) Swap(i, j int) {
+	o.centroids[i], o.centroids[j] = o.centroids[j], o.centroids[i]
+	o.keys[i], o.keys[j] = o.keys[j], o.keys[i]
+}
@@ -1,6 +1,10 @@
 package vectordata
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // Codec maps between an application type and the Record model.
 type Codec[T any] interface {
@@ -8,6 +12,26 @@ type Codec[T any] interface {
 	Decode(record Record) (T, error)
 }
 
+// ContextEncoder is implemented by codecs whose encoding does I/O, such as
+// computing embeddings. TypedCollection writes call EncodeContext with the
+// caller's context instead of Encode.
+type ContextEncoder[T any] interface {
+	EncodeContext(ctx context.Context, value T) (Record, error)
+}
+
+func encode[T any](ctx context.Context, codec Codec[T], value T) (Record, error) {
+	if encoder, ok := codec.(ContextEncoder[T]); ok {
+		return encoder.EncodeContext(ctx, value)
+	}
+	return codec.Encode(value)
+}
+
+// DimensionReporter is implemented by codecs that know the vector dimension
+// of the values they encode, letting EnsureTypedCollection derive it.
+type DimensionReporter interface {
+	Dimension() int
+}
+
 // TypedSearchResult wraps a typed item with ranking metrics.
 type TypedSearchResult[T any] struct {
 	Item     T
@@ -26,8 +50,28 @@ func NewTypedCollection[T any](base Collection, codec Codec[T]) *TypedCollection
 	return &TypedCollection[T]{base: base, codec: codec}
 }
 
+// EnsureTypedCollection ensures the collection described by spec and wraps
+// it with codec. A zero spec.Dimension is taken from the codec when it is a
+// DimensionReporter; a codec reporting a different dimension than spec fails
+// with ErrDimensionMismatch.
+func EnsureTypedCollection[T any](ctx context.Context, store VectorStore, spec CollectionSpec, codec Codec[T]) (*TypedCollection[T], error) {
+	if reporter, ok := codec.(DimensionReporter); ok && reporter.Dimension() > 0 {
+		switch {
+		case spec.Dimension == 0:
+			spec.Dimension = reporter.Dimension()
+		case spec.Dimension != reporter.Dimension():
+			return nil, fmt.Errorf("%w: collection %q has dimension %d, codec encodes %d", ErrDimensionMismatch, spec.Name, spec.Dimension, reporter.Dimension())
+		}
+	}
+	collection, err := store.EnsureCollection(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewTypedCollection(collection, codec), nil
+}
+
 func (c *TypedCollection[T]) Insert(ctx context.Context, values []T) error {
-	records, err := c.encodeMany(values)
+	records, err := c.encodeMany(ctx, values)
 	if err != nil {
 		return err
 	}
@@ -35,7 +79,7 @@ func (c *TypedCollection[T]) Insert(ctx context.Context, values []T) error {
 }
 
 func (c *TypedCollection[T]) Upsert(ctx context.Context, values []T) error {
-	records, err := c.encodeMany(values)
+	records, err := c.encodeMany(ctx, values)
 	if err != nil {
 		return err
 	}
@@ -71,10 +115,88 @@ func (c *TypedCollection[T]) SearchByVector(ctx context.Context, vector []float3
 	return out, nil
 }
 
-func (c *TypedCollection[T]) encodeMany(values []T) ([]Record, error) {
+// GetMany returns the values with the given IDs in the order of ids,
+// skipping IDs that do not exist. Collections that are not BatchGetters are
+// read with one Get per ID.
+func (c *TypedCollection[T]) GetMany(ctx context.Context, ids []string) ([]T, error) {
+	if getter, ok := c.base.(BatchGetter); ok {
+		records, err := getter.GetMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		return c.decodeMany(records)
+	}
+	records := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		record, err := c.base.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return c.decodeMany(records)
+}
+
+// List returns a page of values in ID order. The collection must be a
+// RecordLister.
+func (c *TypedCollection[T]) List(ctx context.Context, opts ListOptions) ([]T, error) {
+	lister, ok := c.base.(RecordLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: collection %q does not support List", errors.ErrUnsupported, c.base.Name())
+	}
+	records, err := lister.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeMany(records)
+}
+
+func (c *TypedCollection[T]) Delete(ctx context.Context, ids []string) (int64, error) {
+	return c.base.Delete(ctx, ids)
+}
+
+// DeleteByFilter deletes the values matching filter. The collection must be
+// a FilterDeleter.
+func (c *TypedCollection[T]) DeleteByFilter(ctx context.Context, filter Filter) (int64, error) {
+	deleter, ok := c.base.(FilterDeleter)
+	if !ok {
+		return 0, fmt.Errorf("%w: collection %q does not support DeleteByFilter", errors.ErrUnsupported, c.base.Name())
+	}
+	return deleter.DeleteByFilter(ctx, filter)
+}
+
+func (c *TypedCollection[T]) Count(ctx context.Context, filter Filter) (int64, error) {
+	return c.base.Count(ctx, filter)
+}
+
+func (c *TypedCollection[T]) EnsureIndexes(ctx context.Context, opts IndexOptions) error {
+	return c.base.EnsureIndexes(ctx, opts)
+}
+
+// Collection returns the underlying record collection.
+func (c *TypedCollection[T]) Collection() Collection {
+	return c.base
+}
+
+func (c *TypedCollection[T]) decodeMany(records []Record) ([]T, error) {
+	values := make([]T, 0, len(records))
+	for _, record := range records {
+		value, err := c.codec.Decode(record)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (c *TypedCollection[T]) encodeMany(ctx context.Context, values []T) ([]Record, error) {
 	records := make([]Record, 0, len(values))
 	for _, value := range values {
-		record, err := c.codec.Encode(value)
+		record, err := encode(ctx, c.codec, value)
 		if err != nil {
 			return nil, err
 		}
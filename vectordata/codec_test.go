@@ -0,0 +1,106 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type idCodec struct{}
+
+func (idCodec) Encode(id string) (Record, error)     { return Record{ID: id}, nil }
+func (idCodec) Decode(record Record) (string, error) { return record.ID, nil }
+
+type stubListCollection struct {
+	*stubCollection
+	listed ListOptions
+}
+
+func (s *stubListCollection) List(_ context.Context, opts ListOptions) ([]Record, error) {
+	s.listed = opts
+	return []Record{{ID: "b"}, {ID: "c"}}, nil
+}
+
+func TestTypedCollection_GetManyFallsBackToGet(t *testing.T) {
+	// Arrange
+	base := &stubCollection{}
+	typed := NewTypedCollection[string](base, idCodec{})
+
+	// Act
+	values, err := typed.GetMany(context.Background(), []string{"a", "missing", "b"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+	if len(values) != 2 || values[0] != "a" || values[1] != "b" || len(base.gets) != 3 {
+		t.Fatalf("expected existing values in order, got %v after gets %v", values, base.gets)
+	}
+}
+
+func TestTypedCollection_ListDecodesPage(t *testing.T) {
+	// Arrange
+	base := &stubListCollection{stubCollection: &stubCollection{}}
+	typed := NewTypedCollection[string](base, idCodec{})
+
+	// Act
+	values, err := typed.List(context.Background(), ListOptions{After: "a", Limit: 2})
+
+	// Assert
+	if err != nil || len(values) != 2 || values[0] != "b" {
+		t.Fatalf("unexpected page %v (%v)", values, err)
+	}
+	if base.listed.After != "a" || base.listed.Limit != 2 {
+		t.Fatalf("expected options to be passed through, got %+v", base.listed)
+	}
+}
+
+func TestTypedCollection_UnsupportedOperations(t *testing.T) {
+	// Arrange
+	typed := NewTypedCollection[string](&stubCollection{}, idCodec{})
+
+	// Act
+	_, listErr := typed.List(context.Background(), ListOptions{Limit: 1})
+	_, deleteErr := typed.DeleteByFilter(context.Background(), Eq(Metadata("kind"), "a"))
+
+	// Assert
+	if !errors.Is(listErr, errors.ErrUnsupported) || !errors.Is(deleteErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v and %v", listErr, deleteErr)
+	}
+}
+
+type specRecordingStore struct {
+	VectorStore
+	spec CollectionSpec
+}
+
+func (s *specRecordingStore) EnsureCollection(_ context.Context, spec CollectionSpec) (Collection, error) {
+	s.spec = spec
+	return &stubCollection{}, nil
+}
+
+type embeddedDoc struct {
+	ID        string    `vector:"id"`
+	Embedding []float32 `vector:"embedding,dim=3"`
+}
+
+func TestEnsureTypedCollection_DerivesDimensionFromCodec(t *testing.T) {
+	// Arrange
+	store := &specRecordingStore{}
+	codec, err := NewStructCodec[embeddedDoc]()
+	if err != nil {
+		t.Fatalf("NewStructCodec: %v", err)
+	}
+
+	// Act
+	typed, ensureErr := EnsureTypedCollection(context.Background(), store, CollectionSpec{Name: "docs"}, codec)
+	_, mismatchErr := EnsureTypedCollection(context.Background(), store, CollectionSpec{Name: "docs", Dimension: 4}, codec)
+
+	// Assert
+	if ensureErr != nil || typed == nil || store.spec.Dimension != 3 {
+		t.Fatalf("expected dimension 3 from the codec, got %+v (%v)", store.spec, ensureErr)
+	}
+	if !errors.Is(mismatchErr, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", mismatchErr)
+	}
+}
@@ -0,0 +1,71 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const defaultCopyBatchSize = 500
+
+// CopyOptions configures CopyTo.
+type CopyOptions struct {
+	// BatchSize is the number of records listed and upserted at a time
+	// (default 500).
+	BatchSize int
+	// After resumes a copy after this ID, typically CopyResult.LastID of an
+	// interrupted run.
+	After string
+	// Progress, when set, is called after every batch.
+	Progress func(CopyResult)
+}
+
+// CopyResult reports how far CopyTo got.
+type CopyResult struct {
+	// Copied is the number of records upserted into the destination.
+	Copied int64
+	// LastID is the ID of the last record copied.
+	LastID string
+}
+
+// CopyTo streams the records of src matching filter, vectors included, into
+// dst in ascending ID order, e.g. to promote curated records from a staging
+// collection. Records are upserted, so a copy resumed with opts.After may
+// rewrite the batch that was in flight without duplicating it. src must be a
+// RecordLister, and both collections must have the same dimension.
+func CopyTo(ctx context.Context, src, dst Collection, filter Filter, opts CopyOptions) (CopyResult, error) {
+	result := CopyResult{LastID: opts.After}
+	lister, ok := src.(RecordLister)
+	if !ok {
+		return result, fmt.Errorf("%w: collection %q does not support List", errors.ErrUnsupported, src.Name())
+	}
+	if src.Dimension() != dst.Dimension() {
+		return result, fmt.Errorf("%w: cannot copy %d-dimensional vectors from %q into %q, which holds %d", ErrDimensionMismatch, src.Dimension(), src.Name(), dst.Name(), dst.Dimension())
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	projection := &Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true}
+	for {
+		page, err := lister.List(ctx, ListOptions{Filter: filter, After: result.LastID, Limit: batchSize, Projection: projection})
+		if err != nil {
+			return result, err
+		}
+		if len(page) == 0 {
+			return result, nil
+		}
+		if err := dst.Upsert(ctx, page); err != nil {
+			return result, fmt.Errorf("copy records after %q: %w", result.LastID, err)
+		}
+		result.Copied += int64(len(page))
+		result.LastID = page[len(page)-1].ID
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+		if len(page) < batchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+type listedMapCollection struct {
+	mapCollection
+	pages int
+}
+
+func (c *listedMapCollection) List(_ context.Context, opts ListOptions) ([]Record, error) {
+	c.pages++
+	var page []Record
+	for _, id := range slices.Sorted(maps.Keys(c.records)) {
+		record := c.records[id]
+		matched, err := MatchFilter(opts.Filter, record)
+		if err != nil {
+			return nil, err
+		}
+		if id > opts.After && matched && len(page) < opts.Limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func TestCopyToUpsertsMatchingRecordsInBatches(t *testing.T) {
+	// Arrange
+	src := &listedMapCollection{mapCollection: mapCollection{dimension: 1, records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{1}, Metadata: map[string]any{"approved": true}},
+		"b": {ID: "b", Vector: []float32{2}, Metadata: map[string]any{"approved": false}},
+		"c": {ID: "c", Vector: []float32{3}, Metadata: map[string]any{"approved": true}},
+		"d": {ID: "d", Vector: []float32{4}, Metadata: map[string]any{"approved": true}},
+	}}}
+	dst := &mapCollection{dimension: 1, records: map[string]Record{}}
+	var progress []CopyResult
+
+	// Act
+	result, err := CopyTo(context.Background(), src, dst, Eq(Metadata("approved"), true), CopyOptions{
+		BatchSize: 2,
+		Progress:  func(r CopyResult) { progress = append(progress, r) },
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if result != (CopyResult{Copied: 3, LastID: "d"}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !slices.Equal(slices.Sorted(maps.Keys(dst.records)), []string{"a", "c", "d"}) || dst.records["c"].Vector[0] != 3 {
+		t.Fatalf("unexpected destination records %v", dst.records)
+	}
+	if !slices.Equal(progress, []CopyResult{{Copied: 2, LastID: "c"}, {Copied: 3, LastID: "d"}}) || src.pages != 2 {
+		t.Fatalf("unexpected progress %v after %d pages", progress, src.pages)
+	}
+}
+
+func TestCopyToRejectsMismatchedDimensions(t *testing.T) {
+	// Arrange
+	src := &listedMapCollection{mapCollection: mapCollection{dimension: 2, records: map[string]Record{}}}
+	dst := &mapCollection{dimension: 3, records: map[string]Record{}}
+
+	// Act
+	_, err := CopyTo(context.Background(), src, dst, nil, CopyOptions{})
+	_, unlistedErr := CopyTo(context.Background(), dst, src, nil, CopyOptions{})
+
+	// Assert
+	if !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", err)
+	}
+	if !errors.Is(unlistedErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for a source without List, got %v", unlistedErr)
+	}
+}
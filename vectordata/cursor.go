@@ -0,0 +1,132 @@
+package vectordata
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const cursorVersion = 1
+
+// cursorHeaderSize is the version byte, the query hash and the distance.
+const cursorHeaderSize = 1 + 8 + 8
+
+// SearchCursor is the position of the last result of a page: searches with
+// SearchOptions.Cursor return the results after it in (Distance, ID) order.
+type SearchCursor struct {
+	Distance float64
+	ID       string
+}
+
+// After reports whether a result at distance with id comes after c.
+func (c SearchCursor) After(distance float64, id string) bool {
+	return distance > c.Distance || (distance == c.Distance && id > c.ID)
+}
+
+// NextCursor returns the SearchOptions.Cursor of the page after results, a
+// page of the search with vector and opts, or "" if results is empty. The
+// cursor is opaque: it holds the distance and ID of the last result and a
+// hash of the query, so it is only accepted by the same search. Projection,
+// SessionSettings and ScoreNormalization may change between pages.
+//
+//	for {
+//		results, err := collection.SearchByVector(ctx, vector, 100, opts)
+//		...
+//		if len(results) < 100 {
+//			break
+//		}
+//		opts.Cursor = vectordata.NextCursor(vector, opts, results)
+//	}
+func NextCursor(vector []float32, opts SearchOptions, results []SearchResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+	last := results[len(results)-1]
+	buf := make([]byte, 0, cursorHeaderSize+len(last.Record.ID))
+	buf = append(buf, cursorVersion)
+	buf = binary.BigEndian.AppendUint64(buf, cursorQueryHash(vector, opts))
+	buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(last.Distance))
+	buf = append(buf, last.Record.ID...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeCursor returns the position of SearchOptions.Cursor for the search
+// with vector and opts, and false if no cursor is set. Stores call it with
+// the query vector as passed to SearchByVector. Cursors are rejected with
+// ErrInvalidCursor if malformed or issued for another query, and with
+// errors.ErrUnsupported for searches that do not rank by distance, i.e. with
+// Queries, TimeDecay or Boosts, or that set OrderBy.
+func DecodeCursor(vector []float32, opts SearchOptions) (SearchCursor, bool, error) {
+	if opts.Cursor == "" {
+		return SearchCursor{}, false, nil
+	}
+	if err := cursorRanking(opts); err != nil {
+		return SearchCursor{}, false, err
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+	if err != nil || len(buf) < cursorHeaderSize || buf[0] != cursorVersion {
+		return SearchCursor{}, false, fmt.Errorf("%w: malformed cursor", ErrInvalidCursor)
+	}
+	if binary.BigEndian.Uint64(buf[1:9]) != cursorQueryHash(vector, opts) {
+		return SearchCursor{}, false, fmt.Errorf("%w: cursor belongs to a different query", ErrInvalidCursor)
+	}
+	return SearchCursor{
+		Distance: math.Float64frombits(binary.BigEndian.Uint64(buf[9:17])),
+		ID:       string(buf[cursorHeaderSize:]),
+	}, true, nil
+}
+
+// cursorRanking rejects cursors on searches that do not order by distance
+// and ID.
+func cursorRanking(opts SearchOptions) error {
+	if opts.Cursor == "" {
+		return nil
+	}
+	if len(opts.Queries) > 0 || opts.Boosted() {
+		return fmt.Errorf("%w: cursors page through searches ranked by distance, not with Queries, TimeDecay or Boosts", errors.ErrUnsupported)
+	}
+	if len(opts.OrderBy) > 0 {
+		return fmt.Errorf("%w: cursors page in distance and ID order and cannot be combined with OrderBy", errors.ErrUnsupported)
+	}
+	return nil
+}
+
+// SortResults sorts results by distance, then by ID, the order cursors page
+// through.
+func SortResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].Record.ID < results[j].Record.ID
+	})
+}
+
+// cursorQueryHash hashes what selects and orders the results of a search.
+func cursorQueryHash(vector []float32, opts SearchOptions) uint64 {
+	h := sha256.New()
+	writeVector := func(vector []float32) {
+		_ = binary.Write(h, binary.LittleEndian, uint32(len(vector)))
+		for _, v := range vector {
+			_ = binary.Write(h, binary.LittleEndian, math.Float32bits(v))
+		}
+	}
+	writeVector(vector)
+	if filter, err := MarshalFilterJSON(opts.Filter); err == nil {
+		h.Write(filter)
+	} else {
+		fmt.Fprintf(h, "%#v", opts.Filter)
+	}
+	if opts.Threshold != nil {
+		fmt.Fprintf(h, "\nthreshold %v", *opts.Threshold)
+	}
+	for _, negative := range opts.Negatives {
+		fmt.Fprintf(h, "\nnegative %v ", negative.Weight)
+		writeVector(negative.Vector)
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package vectordata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNextCursorRoundTrips(t *testing.T) {
+	// Arrange
+	query := []float32{1, 0}
+	opts := SearchOptions{Filter: Eq(Metadata("kind"), "doc")}
+	results := []SearchResult{
+		{Record: Record{ID: "a"}, Distance: 0.1},
+		{Record: Record{ID: "b:2"}, Distance: 0.30000000000000004},
+	}
+
+	// Act
+	opts.Cursor = NextCursor(query, opts, results)
+	opts.Projection = &Projection{IncludeMetadata: true}
+	cursor, ok, err := DecodeCursor(query, opts)
+
+	// Assert
+	if err != nil || !ok {
+		t.Fatalf("DecodeCursor: %v %v", ok, err)
+	}
+	if want := (SearchCursor{Distance: 0.30000000000000004, ID: "b:2"}); cursor != want {
+		t.Fatalf("expected %+v, got %+v", want, cursor)
+	}
+}
+
+func TestDecodeCursorRejectsOtherQueries(t *testing.T) {
+	// Arrange
+	query := []float32{1, 0}
+	threshold := 0.5
+	opts := SearchOptions{Filter: Eq(Metadata("kind"), "doc")}
+	opts.Cursor = NextCursor(query, opts, []SearchResult{{Record: Record{ID: "a"}}})
+	otherFilter := opts
+	otherFilter.Filter = Eq(Metadata("kind"), "faq")
+	otherThreshold := opts
+	otherThreshold.Threshold = &threshold
+	malformed := opts
+	malformed.Cursor = "not a cursor"
+
+	// Act
+	_, _, vectorErr := DecodeCursor([]float32{0, 1}, opts)
+	_, _, filterErr := DecodeCursor(query, otherFilter)
+	_, _, thresholdErr := DecodeCursor(query, otherThreshold)
+	_, _, malformedErr := DecodeCursor(query, malformed)
+
+	// Assert
+	for name, err := range map[string]error{"vector": vectorErr, "filter": filterErr, "threshold": thresholdErr, "malformed": malformedErr} {
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("%s: expected ErrInvalidCursor, got %v", name, err)
+		}
+	}
+}
+
+func TestDecodeCursorRequiresDistanceRanking(t *testing.T) {
+	// Arrange
+	query := []float32{1, 0}
+	opts := SearchOptions{Boosts: []Boost{{When: Eq(Metadata("pinned"), true), Add: 1}}}
+	opts.Cursor = NextCursor(query, opts, []SearchResult{{Record: Record{ID: "a"}}})
+
+	// Act
+	_, _, decodeErr := DecodeCursor(query, opts)
+	_, boostedErr := SearchBoosted(t.Context(), nil, query, 1, opts)
+
+	// Assert
+	if !errors.Is(decodeErr, errors.ErrUnsupported) || !errors.Is(boostedErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v and %v", decodeErr, boostedErr)
+	}
+}
+
+func TestSortResultsBreaksTiesByID(t *testing.T) {
+	// Arrange
+	results := []SearchResult{
+		{Record: Record{ID: "c"}, Distance: 1},
+		{Record: Record{ID: "b"}, Distance: 0.5},
+		{Record: Record{ID: "a"}, Distance: 1},
+	}
+
+	// Act
+	SortResults(results)
+
+	// Assert
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Record.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"b", "a", "c"}) {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+}
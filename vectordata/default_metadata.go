@@ -0,0 +1,31 @@
+package vectordata
+
+import (
+	"context"
+	"maps"
+)
+
+// DefaultMetadata returns middleware that merges defaults into the metadata
+// of every record written with Insert or Upsert, e.g. to stamp a source and
+// pipeline version on everything a pipeline writes. Keys the record sets
+// itself, even to nil, take precedence; the merge is shallow. The caller's
+// records are not modified.
+//
+//	collection = vectordata.WrapCollection(collection, vectordata.DefaultMetadata(map[string]any{
+//		"source":           "crawler",
+//		"pipeline_version": 3,
+//	}))
+func DefaultMetadata(defaults map[string]any) CollectionMiddleware {
+	defaults = maps.Clone(defaults)
+	return func(ctx context.Context, call *Call, next CallHandler) error {
+		if (call.Operation == OpInsert || call.Operation == OpUpsert) && len(defaults) > 0 {
+			records := make([]Record, len(call.Records))
+			for i, record := range call.Records {
+				record.Metadata = MergeMetadata(defaults, record.Metadata)
+				records[i] = record
+			}
+			call.Records = records
+		}
+		return next(ctx, call)
+	}
+}
@@ -0,0 +1,42 @@
+package vectordata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultMetadataFillsMissingKeysOnWrites(t *testing.T) {
+	// Arrange
+	base := &mapCollection{records: map[string]Record{}}
+	defaults := map[string]any{"source": "crawler", "pipeline_version": 3}
+	collection := WrapCollection(base, DefaultMetadata(defaults))
+	defaults["source"] = "changed"
+	records := []Record{
+		{ID: "a", Metadata: map[string]any{"lang": "en"}},
+		{ID: "b", Metadata: map[string]any{"source": "manual", "pipeline_version": nil}},
+		{ID: "c"},
+	}
+
+	// Act
+	insertErr := collection.Insert(context.Background(), records[:2])
+	upsertErr := collection.Upsert(context.Background(), records[2:])
+
+	// Assert
+	if insertErr != nil || upsertErr != nil {
+		t.Fatalf("write: %v, %v", insertErr, upsertErr)
+	}
+	expected := map[string]map[string]any{
+		"a": {"lang": "en", "source": "crawler", "pipeline_version": 3},
+		"b": {"source": "manual", "pipeline_version": nil},
+		"c": {"source": "crawler", "pipeline_version": 3},
+	}
+	for id, metadata := range expected {
+		if !reflect.DeepEqual(base.records[id].Metadata, metadata) {
+			t.Fatalf("record %s: unexpected metadata %v", id, base.records[id].Metadata)
+		}
+	}
+	if len(records[0].Metadata) != 1 || records[2].Metadata != nil {
+		t.Fatalf("expected the caller's records to be left alone, got %v", records)
+	}
+}
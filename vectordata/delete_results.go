@@ -0,0 +1,62 @@
+package vectordata
+
+import "context"
+
+// DeleteStatus is the outcome of deleting one ID.
+type DeleteStatus string
+
+const (
+	DeleteStatusDeleted  DeleteStatus = "deleted"
+	DeleteStatusNotFound DeleteStatus = "not_found"
+)
+
+// DeleteResult reports the outcome of deleting one ID.
+type DeleteResult struct {
+	ID     string
+	Status DeleteStatus
+}
+
+// DeleteWithResults deletes ids and reports per ID whether it was deleted or
+// did not exist, one result per distinct ID in the order of ids.
+//
+// ReturningDeleters, such as the Postgres and FAISS stores, delete in one
+// call. Other collections are sent one Delete per ID; on error the results
+// gathered so far are returned with it.
+func DeleteWithResults(ctx context.Context, collection Collection, ids []string) ([]DeleteResult, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if deleter, ok := collection.(ReturningDeleter); ok {
+		deleted, err := deleter.DeleteReturning(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		removed := make(map[string]bool, len(deleted))
+		for _, id := range deleted {
+			removed[id] = true
+		}
+		results := make([]DeleteResult, len(ids))
+		for i, id := range ids {
+			results[i] = DeleteResult{ID: id, Status: deleteStatus(removed[id])}
+		}
+		return results, nil
+	}
+
+	results := make([]DeleteResult, 0, len(ids))
+	for _, id := range ids {
+		deleted, err := collection.Delete(ctx, []string{id})
+		if err != nil {
+			return results, err
+		}
+		results = append(results, DeleteResult{ID: id, Status: deleteStatus(deleted > 0)})
+	}
+	return results, nil
+}
+
+func deleteStatus(deleted bool) DeleteStatus {
+	if deleted {
+		return DeleteStatusDeleted
+	}
+	return DeleteStatusNotFound
+}
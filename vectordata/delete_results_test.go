@@ -0,0 +1,55 @@
+package vectordata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type returningCollection struct {
+	mapCollection
+}
+
+func (c *returningCollection) DeleteReturning(_ context.Context, ids []string) ([]string, error) {
+	var deleted []string
+	for _, id := range ids {
+		if _, ok := c.records[id]; ok {
+			delete(c.records, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func TestDeleteWithResultsReportsEachID(t *testing.T) {
+	// Arrange
+	records := func() map[string]Record {
+		return map[string]Record{"a": {ID: "a"}, "c": {ID: "c"}}
+	}
+	fallback := &mapCollection{records: records()}
+	returning := &returningCollection{mapCollection{records: records()}}
+	ids := []string{"c", "b", "a", "c"}
+
+	// Act
+	fallbackResults, fallbackErr := DeleteWithResults(context.Background(), fallback, ids)
+	returningResults, returningErr := DeleteWithResults(context.Background(), returning, ids)
+
+	// Assert
+	if fallbackErr != nil || returningErr != nil {
+		t.Fatalf("DeleteWithResults: %v, %v", fallbackErr, returningErr)
+	}
+	expected := []DeleteResult{
+		{ID: "c", Status: DeleteStatusDeleted},
+		{ID: "b", Status: DeleteStatusNotFound},
+		{ID: "a", Status: DeleteStatusDeleted},
+	}
+	if !reflect.DeepEqual(fallbackResults, expected) || !reflect.DeepEqual(returningResults, expected) {
+		t.Fatalf("unexpected results\nfallback:  %v\nreturning: %v", fallbackResults, returningResults)
+	}
+	if len(fallback.writes) != 3 || len(returning.writes) != 0 {
+		t.Fatalf("expected one Delete per ID only without DeleteReturning, got %v and %v", fallback.writes, returning.writes)
+	}
+	if len(fallback.records) != 0 || len(returning.records) != 0 {
+		t.Fatalf("expected every record deleted, got %v and %v", fallback.records, returning.records)
+	}
+}
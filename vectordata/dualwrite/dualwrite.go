@@ -0,0 +1,205 @@
+// Package dualwrite mirrors writes of a vectordata collection to a second
+// collection, e.g. to move between backends without downtime:
+//
+//	docs := dualwrite.Collection(oldDocs, newDocs, dualwrite.Options{
+//		OnDrift: func(ctx context.Context, drift dualwrite.Drift) { log.Printf("drift: %v", drift) },
+//	})
+//
+// Reads are served by the primary alone. Writes go to the primary first and,
+// if it succeeds, to the secondary. Compare reports how far the two have
+// drifted apart, e.g. before switching reads over.
+package dualwrite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// Mode selects what a failed secondary write does to the call.
+type Mode string
+
+const (
+	// BestEffort reports failed secondary writes to OnDrift and returns the
+	// primary's result.
+	BestEffort Mode = "best_effort"
+	// Strict fails the call when the secondary write fails. The primary
+	// write has already been applied and is not undone.
+	Strict Mode = "strict"
+)
+
+// Options configures a dual-write collection.
+type Options struct {
+	// Mode defaults to BestEffort.
+	Mode Mode
+	// OnDrift, when set, is called for every failed secondary write, in both
+	// modes.
+	OnDrift func(ctx context.Context, drift Drift)
+}
+
+// Drift describes a write applied to the primary but not to the secondary.
+type Drift struct {
+	Operation vectordata.Operation
+	// IDs are the records written or deleted; empty for EnsureIndexes.
+	IDs []string
+	Err error
+}
+
+// Collection returns primary with Insert, Upsert, Delete and EnsureIndexes
+// mirrored to secondary. The result implements TextSearcher and
+// HybridSearcher exactly when primary does.
+func Collection(primary, secondary vectordata.Collection, opts Options) vectordata.Collection {
+	if opts.Mode == "" {
+		opts.Mode = BestEffort
+	}
+	mirror := func(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+		if err := next(ctx, call); err != nil || !isWrite(call.Operation) {
+			return err
+		}
+		err := mirrorCall(ctx, secondary, call)
+		if err == nil {
+			return nil
+		}
+		if opts.OnDrift != nil {
+			opts.OnDrift(ctx, Drift{Operation: call.Operation, IDs: callIDs(call), Err: err})
+		}
+		if opts.Mode == Strict {
+			return fmt.Errorf("dualwrite: secondary %q: %w", secondary.Name(), err)
+		}
+		return nil
+	}
+	return vectordata.WrapCollection(primary, mirror)
+}
+
+func isWrite(op vectordata.Operation) bool {
+	switch op {
+	case vectordata.OpInsert, vectordata.OpUpsert, vectordata.OpDelete, vectordata.OpEnsureIndexes:
+		return true
+	default:
+		return false
+	}
+}
+
+func mirrorCall(ctx context.Context, secondary vectordata.Collection, call *vectordata.Call) error {
+	switch call.Operation {
+	case vectordata.OpInsert:
+		return secondary.Insert(ctx, call.Records)
+	case vectordata.OpUpsert:
+		return secondary.Upsert(ctx, call.Records)
+	case vectordata.OpDelete:
+		_, err := secondary.Delete(ctx, call.IDs)
+		return err
+	default:
+		return secondary.EnsureIndexes(ctx, call.IndexOptions)
+	}
+}
+
+func callIDs(call *vectordata.Call) []string {
+	if call.Operation == vectordata.OpDelete {
+		return append([]string(nil), call.IDs...)
+	}
+	ids := make([]string, len(call.Records))
+	for i, record := range call.Records {
+		ids[i] = record.ID
+	}
+	return ids
+}
+
+// Report is the result of Compare.
+type Report struct {
+	PrimaryCount   int64
+	SecondaryCount int64
+	// Checked is the number of IDs compared.
+	Checked int
+	// Missing are IDs found in the primary only, Extra in the secondary
+	// only, and Mismatched in both with different vectors, metadata or
+	// content.
+	Missing    []string
+	Extra      []string
+	Mismatched []string
+}
+
+// InSync reports whether the counts match and every checked ID agrees.
+func (r Report) InSync() bool {
+	return r.PrimaryCount == r.SecondaryCount && len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+// Compare counts both collections and compares the records of ids, e.g. a
+// sample of recently written IDs. Metadata is compared after a JSON round
+// trip, so backends returning 5 and 5.0 agree.
+func Compare(ctx context.Context, primary, secondary vectordata.Collection, ids []string) (Report, error) {
+	var report Report
+	var err error
+	if report.PrimaryCount, err = primary.Count(ctx, nil); err != nil {
+		return Report{}, fmt.Errorf("count primary: %w", err)
+	}
+	if report.SecondaryCount, err = secondary.Count(ctx, nil); err != nil {
+		return Report{}, fmt.Errorf("count secondary: %w", err)
+	}
+
+	for _, id := range ids {
+		want, wantErr := getRecord(ctx, primary, id)
+		got, gotErr := getRecord(ctx, secondary, id)
+		if wantErr != nil {
+			return Report{}, fmt.Errorf("get %q from primary: %w", id, wantErr)
+		}
+		if gotErr != nil {
+			return Report{}, fmt.Errorf("get %q from secondary: %w", id, gotErr)
+		}
+		report.Checked++
+		switch {
+		case want == nil && got == nil:
+		case got == nil:
+			report.Missing = append(report.Missing, id)
+		case want == nil:
+			report.Extra = append(report.Extra, id)
+		case !sameRecord(*want, *got):
+			report.Mismatched = append(report.Mismatched, id)
+		}
+	}
+	return report, nil
+}
+
+// getRecord returns nil for a missing record.
+func getRecord(ctx context.Context, collection vectordata.Collection, id string) (*vectordata.Record, error) {
+	record, err := collection.Get(ctx, id)
+	if errors.Is(err, vectordata.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func sameRecord(a, b vectordata.Record) bool {
+	if !slices.Equal(a.Vector, b.Vector) {
+		return false
+	}
+	if (a.Content == nil) != (b.Content == nil) || (a.Content != nil && *a.Content != *b.Content) {
+		return false
+	}
+	return reflect.DeepEqual(normalizeMetadata(a.Metadata), normalizeMetadata(b.Metadata))
+}
+
+// normalizeMetadata round-trips metadata through JSON, treating nil and
+// empty maps alike.
+func normalizeMetadata(metadata map[string]any) any {
+	if len(metadata) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return metadata
+	}
+	var decoded any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return metadata
+	}
+	return decoded
+}
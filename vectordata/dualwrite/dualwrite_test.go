@@ -0,0 +1,170 @@
+package dualwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var errDown = errors.New("down")
+
+// mapCollection keeps records in memory and fails writes with err.
+type mapCollection struct {
+	vectordata.Collection
+	name    string
+	records map[string]vectordata.Record
+	err     error
+	reads   int
+}
+
+func newMapCollection(name string) *mapCollection {
+	return &mapCollection{name: name, records: map[string]vectordata.Record{}}
+}
+
+func (c *mapCollection) Name() string { return c.name }
+
+func (c *mapCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	if c.err != nil {
+		return c.err
+	}
+	for _, record := range records {
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *mapCollection) Delete(_ context.Context, ids []string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := c.records[id]; ok {
+			delete(c.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *mapCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	c.reads++
+	record, ok := c.records[id]
+	if !ok {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *mapCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	return int64(len(c.records)), nil
+}
+
+func TestCollection_MirrorsWritesAndReadsPrimary(t *testing.T) {
+	// Arrange
+	primary, secondary := newMapCollection("old"), newMapCollection("new")
+	collection := Collection(primary, secondary, Options{})
+	ctx := context.Background()
+
+	// Act
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{{ID: "r1", Vector: []float32{1}}, {ID: "r2", Vector: []float32{2}}})
+	deleted, deleteErr := collection.Delete(ctx, []string{"r2"})
+	_, getErr := collection.Get(ctx, "r1")
+
+	// Assert
+	if upsertErr != nil || deleteErr != nil || getErr != nil || deleted != 1 {
+		t.Fatalf("unexpected errors: %v, %v, %v (deleted %d)", upsertErr, deleteErr, getErr, deleted)
+	}
+	if len(primary.records) != 1 || len(secondary.records) != 1 {
+		t.Fatalf("expected both collections to hold r1, got %d and %d records", len(primary.records), len(secondary.records))
+	}
+	if primary.reads != 1 || secondary.reads != 0 {
+		t.Fatalf("expected reads from the primary only, got %d and %d", primary.reads, secondary.reads)
+	}
+}
+
+func TestCollection_BestEffortReportsDrift(t *testing.T) {
+	// Arrange
+	primary, secondary := newMapCollection("old"), newMapCollection("new")
+	secondary.err = errDown
+	var drifts []Drift
+	collection := Collection(primary, secondary, Options{OnDrift: func(_ context.Context, drift Drift) {
+		drifts = append(drifts, drift)
+	}})
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "r1"}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected best effort to succeed, got %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Operation != vectordata.OpUpsert || drifts[0].IDs[0] != "r1" || !errors.Is(drifts[0].Err, errDown) {
+		t.Fatalf("unexpected drift reports %+v", drifts)
+	}
+}
+
+func TestCollection_StrictFailsOnSecondaryError(t *testing.T) {
+	// Arrange
+	primary, secondary := newMapCollection("old"), newMapCollection("new")
+	secondary.err = errDown
+	collection := Collection(primary, secondary, Options{Mode: Strict})
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "r1"}})
+
+	// Assert
+	if !errors.Is(err, errDown) || len(primary.records) != 1 {
+		t.Fatalf("expected the secondary error after the primary write, got %v", err)
+	}
+}
+
+func TestCollection_SkipsSecondaryWhenPrimaryFails(t *testing.T) {
+	// Arrange
+	primary, secondary := newMapCollection("old"), newMapCollection("new")
+	primary.err = errDown
+	collection := Collection(primary, secondary, Options{Mode: Strict})
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "r1"}})
+
+	// Assert
+	if !errors.Is(err, errDown) || len(secondary.records) != 0 {
+		t.Fatalf("expected the primary error and no mirrored write, got %v", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	content := "text"
+	primary, secondary := newMapCollection("old"), newMapCollection("new")
+	_ = primary.Upsert(ctx, []vectordata.Record{
+		{ID: "same", Vector: []float32{1}, Metadata: map[string]any{"rank": 5}, Content: &content},
+		{ID: "missing", Vector: []float32{2}},
+		{ID: "changed", Vector: []float32{3}, Metadata: map[string]any{"rank": 1}},
+	})
+	_ = secondary.Upsert(ctx, []vectordata.Record{
+		{ID: "same", Vector: []float32{1}, Metadata: map[string]any{"rank": 5.0}, Content: &content},
+		{ID: "extra", Vector: []float32{4}},
+		{ID: "changed", Vector: []float32{3}, Metadata: map[string]any{"rank": 2}},
+	})
+
+	// Act
+	report, err := Compare(ctx, primary, secondary, []string{"same", "missing", "extra", "changed", "absent"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if report.InSync() || report.Checked != 5 || report.PrimaryCount != 3 || report.SecondaryCount != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != "missing" ||
+		len(report.Extra) != 1 || report.Extra[0] != "extra" ||
+		len(report.Mismatched) != 1 || report.Mismatched[0] != "changed" {
+		t.Fatalf("unexpected drift %+v", report)
+	}
+}
@@ -0,0 +1,162 @@
+package vectordata
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DuplicateOptions configures FindDuplicates.
+type DuplicateOptions struct {
+	// Filter restricts both the scanned records and their duplicates.
+	Filter Filter
+	// Neighbors is how many nearest neighbors of each record are checked
+	// (default 10). Larger groups are still found when their members link
+	// up through each other.
+	Neighbors int
+	// BatchSize is how many records are listed and searched at a time
+	// (default 500).
+	BatchSize int
+	// OnGroup receives each duplicate group once the scan completes, in
+	// order of the groups' smallest IDs. An error stops the scan and is
+	// returned by FindDuplicates.
+	OnGroup func(DuplicateGroup) error
+}
+
+// DuplicateGroup is a set of records linked by distances of at most the
+// maximum passed to FindDuplicates.
+type DuplicateGroup struct {
+	// IDs holds the members of the group in ascending order.
+	IDs []string
+	// Distance is the largest distance of a link in the group.
+	Distance float64
+}
+
+// FindDuplicates reports groups of records whose vectors are within
+// maxDistance of each other, e.g. to dedupe a corpus. It lists the
+// collection in batches and looks up the neighbors of each batch with
+// NeighborsFor, so the Postgres store self-joins every batch with one
+// pgvector LATERAL query. Records linked by a chain of close pairs share a
+// group. Only pairs are held in memory; records without duplicates are not.
+//
+// The collection must be a RecordLister.
+func FindDuplicates(ctx context.Context, collection Collection, maxDistance float64, opts DuplicateOptions) error {
+	if maxDistance < 0 {
+		return fmt.Errorf("%w: maxDistance must be >= 0", ErrSchemaMismatch)
+	}
+	if opts.OnGroup == nil {
+		return fmt.Errorf("%w: DuplicateOptions.OnGroup is required", ErrSchemaMismatch)
+	}
+	if opts.Neighbors < 0 || opts.BatchSize < 0 {
+		return fmt.Errorf("%w: Neighbors and BatchSize must be >= 0", ErrSchemaMismatch)
+	}
+	groups := newDuplicateSets()
+	search := SearchOptions{Filter: opts.Filter, Threshold: &maxDistance, Projection: &Projection{}}
+	err := scanNeighbors(ctx, collection, opts.BatchSize, cmp.Or(opts.Neighbors, 10), search, func(page []Record, found map[string][]SearchResult) error {
+		for id, results := range found {
+			for _, result := range results {
+				groups.link(id, result.Record.ID, result.Distance)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups.groups() {
+		if err := opts.OnGroup(group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// duplicateSets is a union-find over the IDs of linked records.
+type duplicateSets struct {
+	parent   map[string]string
+	distance map[string]float64
+}
+
+func newDuplicateSets() *duplicateSets {
+	return &duplicateSets{parent: make(map[string]string), distance: make(map[string]float64)}
+}
+
+func (s *duplicateSets) find(id string) string {
+	parent, ok := s.parent[id]
+	if !ok {
+		s.parent[id] = id
+		return id
+	}
+	if parent == id {
+		return id
+	}
+	root := s.find(parent)
+	s.parent[id] = root
+	return root
+}
+
+// link merges the sets of a and b and records the link distance on the
+// merged root.
+func (s *duplicateSets) link(a, b string, distance float64) {
+	rootA, rootB := s.find(a), s.find(b)
+	if rootA != rootB {
+		s.parent[rootB] = rootA
+		distance = max(distance, s.distance[rootB])
+		delete(s.distance, rootB)
+	}
+	s.distance[rootA] = max(distance, s.distance[rootA])
+}
+
+func (s *duplicateSets) groups() []DuplicateGroup {
+	members := make(map[string][]string)
+	for id := range s.parent {
+		root := s.find(id)
+		members[root] = append(members[root], id)
+	}
+	out := make([]DuplicateGroup, 0, len(members))
+	for root, ids := range members {
+		sort.Strings(ids)
+		out = append(out, DuplicateGroup{IDs: ids, Distance: s.distance[root]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].IDs[0] < out[j].IDs[0] })
+	return out
+}
+
+// scanNeighbors lists the records matching search.Filter, batchSize at a time
+// (default 500), and passes each page with the topK neighbors of its records
+// to fn.
+func scanNeighbors(ctx context.Context, collection Collection, batchSize, topK int, search SearchOptions, fn func(page []Record, neighbors map[string][]SearchResult) error) error {
+	lister, ok := collection.(RecordLister)
+	if !ok {
+		return fmt.Errorf("%w: collection %q does not support List", errors.ErrUnsupported, collection.Name())
+	}
+	batchSize = cmp.Or(batchSize, 500)
+	after := ""
+	for {
+		page, err := lister.List(ctx, ListOptions{Filter: search.Filter, After: after, Limit: batchSize, Projection: &Projection{}})
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		ids := make([]string, len(page))
+		for i, record := range page {
+			ids[i] = record.ID
+		}
+		neighbors, err := NeighborsFor(ctx, collection, ids, topK, search)
+		if err != nil {
+			return err
+		}
+		if err := fn(page, neighbors); err != nil {
+			return err
+		}
+		if len(page) < batchSize {
+			return nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
@@ -0,0 +1,75 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type listedNeighborCollection struct {
+	*neighborCollection
+}
+
+func (c listedNeighborCollection) List(_ context.Context, opts ListOptions) ([]Record, error) {
+	ids := make([]string, 0, len(c.records))
+	for id := range c.records {
+		if id > opts.After {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	page := make([]Record, 0, opts.Limit)
+	for _, id := range ids[:min(opts.Limit, len(ids))] {
+		page = append(page, Record{ID: id})
+	}
+	return page, nil
+}
+
+func TestFindDuplicatesGroupsLinkedRecords(t *testing.T) {
+	// Arrange
+	collection := listedNeighborCollection{&neighborCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{0}},
+		"b": {ID: "b", Vector: []float32{0.1}},
+		"c": {ID: "c", Vector: []float32{0.2}},
+		"d": {ID: "d", Vector: []float32{5}},
+		"e": {ID: "e", Vector: []float32{9}},
+		"f": {ID: "f", Vector: []float32{9}},
+	}}}
+	var groups []DuplicateGroup
+
+	// Act
+	err := FindDuplicates(context.Background(), collection, 0.02, DuplicateOptions{
+		Neighbors: 1,
+		BatchSize: 4,
+		OnGroup: func(group DuplicateGroup) error {
+			groups = append(groups, group)
+			return nil
+		},
+	})
+	stop := errors.New("stop")
+	stopErr := FindDuplicates(context.Background(), collection, 0.02, DuplicateOptions{
+		OnGroup: func(DuplicateGroup) error { return stop },
+	})
+	unsupportedErr := FindDuplicates(context.Background(), collection.neighborCollection, 0.02, DuplicateOptions{
+		OnGroup: func(DuplicateGroup) error { return nil },
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+	if len(groups) != 2 || !reflect.DeepEqual(groups[0].IDs, []string{"a", "b", "c"}) || !reflect.DeepEqual(groups[1].IDs, []string{"e", "f"}) {
+		t.Fatalf("expected groups a-c and e-f, got %+v", groups)
+	}
+	if groups[0].Distance < 0.0099 || groups[0].Distance > 0.0101 || groups[1].Distance != 0 {
+		t.Fatalf("unexpected group distances: %+v", groups)
+	}
+	if !errors.Is(stopErr, stop) {
+		t.Fatalf("expected the OnGroup error, got %v", stopErr)
+	}
+	if !errors.Is(unsupportedErr, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported without List, got %v", unsupportedErr)
+	}
+}
@@ -0,0 +1,76 @@
+package vectordata
+
+import (
+	"context"
+	"fmt"
+)
+
+// Embedder computes the embedding of a text.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderFunc adapts a function to Embedder.
+type EmbedderFunc func(ctx context.Context, text string) ([]float32, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, text string) ([]float32, error) {
+	return f(ctx, text)
+}
+
+// EmbeddingCodec decorates a codec to compute Record.Vector on Encode. See
+// WithEmbedding.
+type EmbeddingCodec[T any] struct {
+	codec    Codec[T]
+	embedder Embedder
+	text     func(T) string
+}
+
+// WithEmbedding returns codec with embedding on Encode: records encoded
+// without a Vector get the embedding of text(value). Values that already
+// carry a vector are not re-embedded. TypedCollection writes embed with the
+// caller's context through EncodeContext.
+func WithEmbedding[T any](codec Codec[T], embedder Embedder, text func(T) string) *EmbeddingCodec[T] {
+	return &EmbeddingCodec[T]{codec: codec, embedder: embedder, text: text}
+}
+
+// Encode encodes value, embedding it with a background context if needed.
+func (c *EmbeddingCodec[T]) Encode(value T) (Record, error) {
+	return c.EncodeContext(context.Background(), value)
+}
+
+// EncodeContext encodes value, embedding it if needed.
+func (c *EmbeddingCodec[T]) EncodeContext(ctx context.Context, value T) (Record, error) {
+	record, err := encode(ctx, c.codec, value)
+	if err != nil {
+		return Record{}, err
+	}
+	if len(record.Vector) > 0 {
+		return record, nil
+	}
+	text := c.text(value)
+	if text == "" {
+		return Record{}, fmt.Errorf("%w: record %q has no vector and no text to embed", ErrInvalidRecord, record.ID)
+	}
+	vector, err := c.embedder.Embed(ctx, text)
+	if err != nil {
+		return Record{}, fmt.Errorf("embed record %q: %w", record.ID, err)
+	}
+	record.Vector = vector
+	return record, nil
+}
+
+func (c *EmbeddingCodec[T]) Decode(record Record) (T, error) {
+	return c.codec.Decode(record)
+}
+
+// Dimension reports the dimension of the embedder, or else of the wrapped
+// codec, when they are DimensionReporters.
+func (c *EmbeddingCodec[T]) Dimension() int {
+	if reporter, ok := c.embedder.(DimensionReporter); ok && reporter.Dimension() > 0 {
+		return reporter.Dimension()
+	}
+	if reporter, ok := c.codec.(DimensionReporter); ok {
+		return reporter.Dimension()
+	}
+	return 0
+}
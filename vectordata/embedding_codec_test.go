@@ -0,0 +1,68 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type ctxKey struct{}
+
+func TestWithEmbeddingEmbedsMissingVectors(t *testing.T) {
+	// Arrange
+	var embedded []string
+	var sawContext bool
+	embedder := EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+		embedded = append(embedded, text)
+		sawContext = ctx.Value(ctxKey{}) != nil
+		return []float32{1, 0}, nil
+	})
+	base, _ := NewStructCodec[structCodecDoc]()
+	codec := WithEmbedding[structCodecDoc](base, embedder, func(doc structCodecDoc) string { return doc.Category })
+	collection := &recordingCollection{stubCollection: &stubCollection{}}
+	typed := NewTypedCollection[structCodecDoc](collection, codec)
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+
+	// Act
+	err := typed.Upsert(ctx, []structCodecDoc{
+		{ID: "a", Category: "news"},
+		{ID: "b", Category: "blog", Embedding: []float32{0, 1}},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if len(embedded) != 1 || embedded[0] != "news" || !sawContext {
+		t.Fatalf("expected only the record without a vector to be embedded with the caller's context, got %v", embedded)
+	}
+	if collection.upserted[0].Vector[0] != 1 || collection.upserted[1].Vector[1] != 1 {
+		t.Fatalf("unexpected vectors %v", collection.upserted)
+	}
+}
+
+func TestWithEmbeddingRequiresText(t *testing.T) {
+	// Arrange
+	base, _ := NewStructCodec[structCodecDoc]()
+	codec := WithEmbedding[structCodecDoc](base, EmbedderFunc(func(context.Context, string) ([]float32, error) {
+		return nil, errors.New("unexpected call")
+	}), func(structCodecDoc) string { return "" })
+
+	// Act
+	_, err := codec.Encode(structCodecDoc{ID: "a"})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+}
+
+type recordingCollection struct {
+	*stubCollection
+	upserted []Record
+}
+
+func (c *recordingCollection) Upsert(_ context.Context, records []Record) error {
+	c.upserted = records
+	return nil
+}
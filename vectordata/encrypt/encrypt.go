@@ -0,0 +1,232 @@
+// Package encrypt encrypts record content at rest with AES-GCM.
+//
+// An Encryptor is a collection middleware that encrypts Content on Insert and
+// Upsert and decrypts it in Get and search results. IDs, vectors and metadata
+// stay in the clear, so searches and metadata filters work as before:
+//
+//	keys := &encrypt.StaticKeys{Current: "2024-06", Keys: map[string][]byte{"2024-06": key}}
+//	store = vectordata.WrapStore(store, encrypt.New(keys).Middleware())
+//
+// Stored content has the form "enc:v1:<key id>:<base64 nonce and
+// ciphertext>", and the collection name and record ID are authenticated with
+// it, so content copied to another record fails to decrypt. Keys are looked
+// up by the ID stored with the content, so a KeyProvider can rotate the key
+// for new writes while older records still decrypt. Content without the
+// prefix, e.g. written before encryption was enabled, is returned as is.
+//
+// The store only sees ciphertext, so text and hybrid searches, which match
+// content, fail with errors.ErrUnsupported.
+//
+// Redaction must see plaintext. The middleware applies the Projection.Redact
+// of a search itself, after decrypting, instead of leaving it to the store.
+// Put a Redaction middleware before the Encryptor, so that it runs outside it:
+//
+//	vectordata.WrapCollection(docs, redaction.Middleware(), encryptor.Middleware())
+package encrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const prefix = "enc:v1:"
+
+// ErrUnknownKey is returned by StaticKeys for a key ID it does not hold.
+var ErrUnknownKey = errors.New("encrypt: unknown key")
+
+// KeyProvider supplies AES keys of 16, 24 or 32 bytes. It is the hook for a
+// key management service: CurrentKey may generate or unwrap a data key, and
+// Key may unwrap the data key of an ID. Both are called for every record, so
+// providers that call out to a KMS should cache keys.
+type KeyProvider interface {
+	// CurrentKey returns the key for new writes and its ID. IDs must not
+	// contain ':'.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the given ID.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider over keys held in memory. Keep retired keys
+// in Keys until no content encrypted with them remains.
+type StaticKeys struct {
+	// Current is the ID of the key used for new writes.
+	Current string
+	Keys    map[string][]byte
+}
+
+func (k *StaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := k.Key(ctx, k.Current)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.Current, key, nil
+}
+
+func (k *StaticKeys) Key(_ context.Context, id string) ([]byte, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	return key, nil
+}
+
+// Encryptor encrypts and decrypts record content with keys from a
+// KeyProvider. It is safe for concurrent use.
+type Encryptor struct {
+	keys KeyProvider
+}
+
+// New returns an Encryptor using keys.
+func New(keys KeyProvider) *Encryptor {
+	return &Encryptor{keys: keys}
+}
+
+// Encrypt returns the stored form of the content of a record, e.g. to
+// encrypt existing records in a backfill.
+func (e *Encryptor) Encrypt(ctx context.Context, collection, id, content string) (string, error) {
+	keyID, key, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: current key: %w", err)
+	}
+	if strings.Contains(keyID, ":") {
+		return "", fmt.Errorf("encrypt: key ID %q contains ':'", keyID)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(content)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("encrypt: nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(content), additionalData(collection, id))
+	return prefix + keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the content of a record from its stored form. Content
+// that is not encrypted is returned as is.
+func (e *Encryptor) Decrypt(ctx context.Context, collection, id, stored string) (string, error) {
+	rest, ok := strings.CutPrefix(stored, prefix)
+	if !ok {
+		return stored, nil
+	}
+	keyID, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", fmt.Errorf("encrypt: content of %q is malformed", id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: content of %q is malformed: %w", id, err)
+	}
+	key, err := e.keys.Key(ctx, keyID)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: key for %q: %w", id, err)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypt: content of %q is malformed", id)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(collection, id))
+	if err != nil {
+		return "", fmt.Errorf("encrypt: decrypt content of %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// Middleware returns the collection middleware that encrypts content on
+// writes and decrypts it on reads. Written records are copied, so the
+// caller's records keep their plaintext.
+func (e *Encryptor) Middleware() vectordata.CollectionMiddleware {
+	return func(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+		collection := call.Collection.Name()
+		switch call.Operation {
+		case vectordata.OpInsert, vectordata.OpUpsert:
+			records := make([]vectordata.Record, len(call.Records))
+			for i, record := range call.Records {
+				if record.Content != nil {
+					stored, err := e.Encrypt(ctx, collection, record.ID, *record.Content)
+					if err != nil {
+						return err
+					}
+					record.Content = &stored
+				}
+				records[i] = record
+			}
+			call.Records = records
+			return next(ctx, call)
+		case vectordata.OpSearchByText, vectordata.OpHybridSearch:
+			return fmt.Errorf("%w: %s matches content, which is encrypted", errors.ErrUnsupported, call.Operation)
+		case vectordata.OpGet:
+			if err := next(ctx, call); err != nil {
+				return err
+			}
+			return e.decryptRecord(ctx, collection, &call.Record)
+		case vectordata.OpSearchByVector:
+			// The store would redact ciphertext, so Projection.Redact is
+			// held back and applied to the decrypted results.
+			projection := call.SearchOptions.Projection
+			var redaction *vectordata.Redaction
+			if projection != nil && projection.Redact != nil {
+				redaction = projection.Redact
+				withoutRedact := *projection
+				withoutRedact.Redact = nil
+				call.SearchOptions.Projection = &withoutRedact
+			}
+			err := next(ctx, call)
+			call.SearchOptions.Projection = projection
+			if err != nil {
+				return err
+			}
+			for i := range call.Results {
+				if err := e.decryptRecord(ctx, collection, &call.Results[i].Record); err != nil {
+					return err
+				}
+				call.Results[i] = redaction.Result(call.Results[i])
+			}
+			return nil
+		default:
+			return next(ctx, call)
+		}
+	}
+}
+
+func (e *Encryptor) decryptRecord(ctx context.Context, collection string, record *vectordata.Record) error {
+	if record.Content == nil {
+		return nil
+	}
+	content, err := e.Decrypt(ctx, collection, record.ID, *record.Content)
+	if err != nil {
+		return err
+	}
+	record.Content = &content
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return aead, nil
+}
+
+// additionalData binds ciphertext to its collection and record.
+func additionalData(collection, id string) []byte {
+	return []byte(collection + "\x00" + id)
+}
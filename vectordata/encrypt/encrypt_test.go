@@ -0,0 +1,176 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// storedCollection keeps records as written, to observe what a store sees.
+type storedCollection struct {
+	vectordata.Collection
+	records map[string]vectordata.Record
+}
+
+func (c *storedCollection) Name() string { return "docs" }
+
+func (c *storedCollection) Upsert(_ context.Context, records []vectordata.Record) error {
+	for _, record := range records {
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *storedCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	record, ok := c.records[id]
+	if !ok {
+		return vectordata.Record{}, vectordata.ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *storedCollection) SearchByVector(_ context.Context, _ []float32, _ int, opts vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	results := make([]vectordata.SearchResult, 0, len(c.records))
+	for _, record := range c.records {
+		results = append(results, vectordata.SearchResult{Record: record})
+	}
+	return vectordata.RedactResults(opts.Projection, results), nil
+}
+
+func newTestKeys() *StaticKeys {
+	return &StaticKeys{Current: "k1", Keys: map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}}
+}
+
+func TestEncryptor_StoresCiphertextAndReadsPlaintext(t *testing.T) {
+	// Arrange
+	base := &storedCollection{records: map[string]vectordata.Record{}}
+	collection := vectordata.WrapCollection(base, New(newTestKeys()).Middleware())
+	content := "patient notes"
+	record := vectordata.Record{ID: "a", Vector: []float32{1, 0}, Metadata: map[string]any{"kind": "note"}, Content: &content}
+
+	// Act
+	upsertErr := collection.Upsert(context.Background(), []vectordata.Record{record})
+	got, getErr := collection.Get(context.Background(), "a")
+	results, searchErr := collection.SearchByVector(context.Background(), []float32{1, 0}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if upsertErr != nil || getErr != nil || searchErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", upsertErr, getErr, searchErr)
+	}
+	stored := *base.records["a"].Content
+	if !strings.HasPrefix(stored, "enc:v1:k1:") || strings.Contains(stored, content) {
+		t.Fatalf("expected ciphertext to be stored, got %q", stored)
+	}
+	if base.records["a"].Metadata["kind"] != "note" {
+		t.Fatal("expected metadata to be stored in the clear")
+	}
+	if *record.Content != content {
+		t.Fatal("expected the caller's record to keep its plaintext")
+	}
+	if *got.Content != content || *results[0].Record.Content != content {
+		t.Fatalf("expected decrypted content, got %q and %q", *got.Content, *results[0].Record.Content)
+	}
+}
+
+func TestEncryptor_DecryptsWithRotatedKeys(t *testing.T) {
+	// Arrange
+	keys := newTestKeys()
+	encryptor := New(keys)
+	old, err := encryptor.Encrypt(context.Background(), "docs", "a", "first")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	keys.Current = "k2"
+
+	// Act
+	current, encryptErr := encryptor.Encrypt(context.Background(), "docs", "a", "second")
+	first, firstErr := encryptor.Decrypt(context.Background(), "docs", "a", old)
+	second, secondErr := encryptor.Decrypt(context.Background(), "docs", "a", current)
+	plain, plainErr := encryptor.Decrypt(context.Background(), "docs", "a", "legacy")
+
+	// Assert
+	if encryptErr != nil || firstErr != nil || secondErr != nil || plainErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v %v", encryptErr, firstErr, secondErr, plainErr)
+	}
+	if !strings.HasPrefix(current, "enc:v1:k2:") {
+		t.Fatalf("expected the current key to be used, got %q", current)
+	}
+	if first != "first" || second != "second" || plain != "legacy" {
+		t.Fatalf("unexpected plaintexts %q %q %q", first, second, plain)
+	}
+}
+
+func TestEncryptor_RejectsContentOfAnotherRecord(t *testing.T) {
+	// Arrange
+	keys := newTestKeys()
+	encryptor := New(keys)
+	stored, err := encryptor.Encrypt(context.Background(), "docs", "a", "secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	delete(keys.Keys, "k1")
+
+	// Act
+	_, otherRecordErr := New(newTestKeys()).Decrypt(context.Background(), "docs", "b", stored)
+	_, missingKeyErr := encryptor.Decrypt(context.Background(), "docs", "a", stored)
+
+	// Assert
+	if otherRecordErr == nil {
+		t.Fatal("expected content moved to another record to fail")
+	}
+	if !errors.Is(missingKeyErr, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", missingKeyErr)
+	}
+}
+
+func TestEncryptor_RejectsTextSearch(t *testing.T) {
+	// Arrange
+	middleware := New(newTestKeys()).Middleware()
+	call := &vectordata.Call{Operation: vectordata.OpSearchByText, Collection: &storedCollection{}}
+
+	// Act
+	err := middleware(context.Background(), call, func(context.Context, *vectordata.Call) error { return nil })
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestEncryptor_RedactsDecryptedContent(t *testing.T) {
+	// Arrange
+	base := &storedCollection{records: map[string]vectordata.Record{}}
+	redaction := &vectordata.Redaction{ContentPatterns: []*regexp.Regexp{vectordata.EmailPattern}}
+	encryptor := New(newTestKeys())
+	collection := vectordata.WrapCollection(base, encryptor.Middleware())
+	redacted := vectordata.WrapCollection(base, redaction.Middleware(), encryptor.Middleware())
+	content := "contact jane@example.com"
+	record := vectordata.Record{ID: "a", Vector: []float32{1, 0}, Content: &content}
+	projection := vectordata.DefaultProjection()
+	projection.Redact = redaction
+	opts := vectordata.SearchOptions{Projection: &projection}
+
+	// Act
+	upsertErr := collection.Upsert(context.Background(), []vectordata.Record{record})
+	results, searchErr := collection.SearchByVector(context.Background(), []float32{1, 0}, 1, opts)
+	got, getErr := redacted.Get(context.Background(), "a")
+
+	// Assert
+	if upsertErr != nil || searchErr != nil || getErr != nil {
+		t.Fatalf("unexpected errors: %v %v %v", upsertErr, searchErr, getErr)
+	}
+	if want := "contact [REDACTED]"; *results[0].Record.Content != want || *got.Content != want {
+		t.Fatalf("expected %q, got %q and %q", want, *results[0].Record.Content, *got.Content)
+	}
+	if opts.Projection.Redact != redaction {
+		t.Fatal("expected the caller's projection to keep its redaction")
+	}
+}
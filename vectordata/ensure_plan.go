@@ -0,0 +1,65 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PlanStepKind classifies a step of an EnsurePlan.
+type PlanStepKind string
+
+const (
+	PlanCreateExtension PlanStepKind = "create_extension"
+	PlanCreateSchema    PlanStepKind = "create_schema"
+	PlanCreateTable     PlanStepKind = "create_table"
+	PlanAddColumn       PlanStepKind = "add_column"
+	PlanCreateIndex     PlanStepKind = "create_index"
+	// PlanDropTable drops a table, e.g. a collection recreated by
+	// EnsureRecreate.
+	PlanDropTable PlanStepKind = "drop_table"
+	// PlanRecordCatalog records the collection in the store's catalog.
+	PlanRecordCatalog PlanStepKind = "record_catalog"
+	// PlanOther covers the remaining DDL, such as functions, triggers and
+	// row level security policies.
+	PlanOther PlanStepKind = "other"
+)
+
+// PlanStep is one change EnsureCollection would make.
+type PlanStep struct {
+	Kind PlanStepKind
+	// Object names what the step creates or changes, e.g. a table, an index
+	// or a column, when the store can tell.
+	Object string
+	// Statement is the statement the store would run.
+	Statement string
+}
+
+// EnsurePlan lists the changes EnsureCollection would make, in order.
+type EnsurePlan struct {
+	Collection string
+	Steps      []PlanStep
+}
+
+// NoOp reports whether EnsureCollection would change nothing.
+func (p EnsurePlan) NoOp() bool {
+	return len(p.Steps) == 0
+}
+
+// EnsurePlanner is implemented by stores that can dry-run EnsureCollection.
+type EnsurePlanner interface {
+	EnsureCollectionPlan(ctx context.Context, spec CollectionSpec) (EnsurePlan, error)
+}
+
+// EnsureCollectionPlan returns what EnsureCollection would do with spec
+// against the current schema, without changing it, e.g. for review before a
+// deployment. It fails where EnsureCollection would, such as with
+// ErrSchemaMismatch on a strict mismatch. Stores that are not EnsurePlanners
+// fail with errors.ErrUnsupported.
+func EnsureCollectionPlan(ctx context.Context, store VectorStore, spec CollectionSpec) (EnsurePlan, error) {
+	planner, ok := store.(EnsurePlanner)
+	if !ok {
+		return EnsurePlan{}, fmt.Errorf("%w: store does not support EnsureCollectionPlan", errors.ErrUnsupported)
+	}
+	return planner.EnsureCollectionPlan(ctx, spec)
+}
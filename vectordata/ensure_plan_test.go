@@ -0,0 +1,24 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type plainStore struct {
+	VectorStore
+}
+
+func TestEnsureCollectionPlanRequiresPlanner(t *testing.T) {
+	// Act
+	plan, err := EnsureCollectionPlan(context.Background(), plainStore{}, CollectionSpec{Name: "docs", Dimension: 2})
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if !plan.NoOp() {
+		t.Fatalf("expected an empty plan, got %+v", plan)
+	}
+}
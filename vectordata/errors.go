@@ -1,10 +1,49 @@
 package vectordata
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	ErrNotFound          = errors.New("vectordata: record not found")
 	ErrDimensionMismatch = errors.New("vectordata: vector dimension mismatch")
 	ErrSchemaMismatch    = errors.New("vectordata: schema mismatch")
 	ErrInvalidFilter     = errors.New("vectordata: invalid filter")
+	// ErrInvalidRecord reports a record rejected by RecordLimits.
+	ErrInvalidRecord = errors.New("vectordata: invalid record")
+	// ErrUnavailable reports a store that cannot be reached.
+	ErrUnavailable = errors.New("vectordata: store unavailable")
+	// ErrNotReady reports a reachable store missing a prerequisite, such as
+	// an extension or schema privileges.
+	ErrNotReady = errors.New("vectordata: store not ready")
+	// ErrConflict reports a write that conflicts with existing data, such as
+	// a duplicate ID on Insert, or with a concurrent transaction.
+	ErrConflict = errors.New("vectordata: conflict")
+	// ErrTimeout reports an operation that ran out of time, on the client or
+	// on the server.
+	ErrTimeout = errors.New("vectordata: timeout")
+	// ErrTooLarge reports a request, record or result beyond a backend limit.
+	ErrTooLarge = errors.New("vectordata: too large")
+	// ErrInvalidCursor reports a SearchOptions.Cursor that is malformed or
+	// was issued for a different query.
+	ErrInvalidCursor = errors.New("vectordata: invalid cursor")
 )
+
+// IsRetryable reports whether retrying the failed operation may succeed:
+// err wraps ErrUnavailable or ErrTimeout, or an error whose Temporary method
+// reports true, such as a serialization failure. Cancellation and deadlines
+// of the caller's context are not retryable, nor are errors describing the
+// request itself.
+func IsRetryable(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, ErrUnavailable), errors.Is(err, ErrTimeout):
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
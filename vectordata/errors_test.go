@@ -0,0 +1,44 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type temporaryError struct{ temporary bool }
+
+func (e temporaryError) Error() string   { return "temporary" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unavailable", fmt.Errorf("%w: connection refused", ErrUnavailable), true},
+		{"timeout", fmt.Errorf("%w: statement timeout", ErrTimeout), true},
+		{"temporary", fmt.Errorf("%w: %w", ErrConflict, temporaryError{temporary: true}), true},
+		{"not temporary", temporaryError{}, false},
+		{"conflict", fmt.Errorf("%w: duplicate key", ErrConflict), false},
+		{"too large", ErrTooLarge, false},
+		{"not found", ErrNotFound, false},
+		{"caller deadline", fmt.Errorf("%w: %w", ErrTimeout, context.DeadlineExceeded), false},
+		{"canceled", context.Canceled, false},
+		{"unknown", errors.New("boom"), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			got := IsRetryable(tc.err)
+
+			// Assert
+			if got != tc.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Evictor is implemented by collections that can be bounded with
+// CollectionSpec.MaxRecords.
+type Evictor interface {
+	// Evict deletes the records past the handle's MaxRecords and returns how
+	// many it deleted; handles without MaxRecords delete nothing.
+	Evict(ctx context.Context) (int64, error)
+}
+
+// Evict runs eviction on a collection bounded with CollectionSpec.MaxRecords,
+// e.g. from a maintenance job when writes bypass the bounded handle. Other
+// collections fail with errors.ErrUnsupported.
+func Evict(ctx context.Context, collection Collection) (int64, error) {
+	evictor, ok := collection.(Evictor)
+	if !ok {
+		return 0, fmt.Errorf("%w: collection %q does not support eviction", errors.ErrUnsupported, collection.Name())
+	}
+	return evictor.Evict(ctx)
+}
+
+// ValidateEviction checks the MaxRecords and EvictBy fields of spec and
+// returns it with EvictBy trimmed.
+func ValidateEviction(spec CollectionSpec) (CollectionSpec, error) {
+	spec.EvictBy = strings.TrimSpace(spec.EvictBy)
+	if spec.MaxRecords < 0 {
+		return CollectionSpec{}, fmt.Errorf("%w: MaxRecords must be >= 0, got %d", ErrSchemaMismatch, spec.MaxRecords)
+	}
+	if spec.EvictBy != "" && spec.MaxRecords == 0 {
+		return CollectionSpec{}, fmt.Errorf("%w: EvictBy requires MaxRecords", ErrSchemaMismatch)
+	}
+	return spec, nil
+}
+
+// SortForEviction orders records the way CollectionSpec.EvictBy evicts them:
+// records without a valid timestamp first, then oldest first, ties in ID
+// order.
+func SortForEviction(records []Record, evictBy string) {
+	type key struct {
+		at    time.Time
+		valid bool
+	}
+	keys := make(map[string]key, len(records))
+	if evictBy != "" {
+		for _, record := range records {
+			at, valid := metadataTimestamp(record.Metadata[evictBy])
+			keys[record.ID] = key{at: at, valid: valid}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := keys[records[i].ID], keys[records[j].ID]
+		if a.valid != b.valid {
+			return !a.valid
+		}
+		if c := a.at.Compare(b.at); c != 0 {
+			return c < 0
+		}
+		return records[i].ID < records[j].ID
+	})
+}
@@ -0,0 +1,46 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSortForEvictionPutsInvalidAndOldestFirst(t *testing.T) {
+	// Arrange
+	records := []Record{
+		{ID: "new", Metadata: map[string]any{"used": "2026-03-01T00:00:00Z"}},
+		{ID: "old", Metadata: map[string]any{"used": "2026-01-01T00:00:00+02:00"}},
+		{ID: "b-missing"},
+		{ID: "a-invalid", Metadata: map[string]any{"used": "yesterday"}},
+		{ID: "tie", Metadata: map[string]any{"used": "2026-03-01T00:00:00Z"}},
+	}
+
+	// Act
+	SortForEviction(records, "used")
+
+	// Assert
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	expected := []string{"a-invalid", "b-missing", "old", "new", "tie"}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("unexpected order %v, expected %v", ids, expected)
+		}
+	}
+}
+
+func TestEvictRequiresEvictor(t *testing.T) {
+	// Arrange
+	collection := &mapCollection{records: map[string]Record{}}
+
+	// Act
+	_, err := Evict(context.Background(), collection)
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
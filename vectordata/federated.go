@@ -0,0 +1,251 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MergeStrategy selects how a federated collection ranks member results
+// against each other.
+type MergeStrategy string
+
+const (
+	// MergeMinMax rescales each member's scores to [0, 1] before merging, so
+	// members with different metrics or score ranges compete evenly.
+	MergeMinMax MergeStrategy = "min_max"
+	// MergeScore merges by the members' scores as returned.
+	MergeScore MergeStrategy = "score"
+	// MergeReciprocalRank scores a result 1/(k+rank) by its rank within its
+	// member, ignoring score values.
+	MergeReciprocalRank MergeStrategy = "reciprocal_rank"
+)
+
+const defaultReciprocalRankK = 60
+
+// MergePolicy configures how FederatedCollection combines its members.
+type MergePolicy struct {
+	// Strategy defaults to MergeMinMax.
+	Strategy MergeStrategy
+	// ReciprocalRankK is the k of MergeReciprocalRank (default 60).
+	ReciprocalRankK int
+	// AllowPartial returns the results of the members that answered when
+	// others fail. Calls fail only when every member fails.
+	AllowPartial bool
+}
+
+// FederatedCollection returns a read-only collection fanning SearchByVector,
+// Count, Get and EnsureIndexes out to members concurrently. Searches ask every
+// member for topK results and merge them under policy, keeping the best
+// result per ID; the merged Score replaces the members' scores and Distance
+// is kept as returned. Count sums the members' counts, so records held by
+// several members are counted once per member. Get returns the record from
+// the first member, in order, that has it. Insert, Upsert and Delete fail:
+// writes go to the members directly.
+//
+// Members must share a dimension; the federated collection reports the name
+// of its members joined with "+" and the metric of the first one.
+func FederatedCollection(members []Collection, policy MergePolicy) (Collection, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: federated collection has no members", ErrSchemaMismatch)
+	}
+	names := make([]string, len(members))
+	for i, member := range members {
+		if member.Dimension() != members[0].Dimension() {
+			return nil, fmt.Errorf("%w: member %q has dimension %d, expected %d", ErrDimensionMismatch, member.Name(), member.Dimension(), members[0].Dimension())
+		}
+		names[i] = member.Name()
+	}
+	if policy.Strategy == "" {
+		policy.Strategy = MergeMinMax
+	}
+	switch policy.Strategy {
+	case MergeMinMax, MergeScore, MergeReciprocalRank:
+	default:
+		return nil, fmt.Errorf("%w: unsupported merge strategy %q", ErrSchemaMismatch, policy.Strategy)
+	}
+	if policy.ReciprocalRankK <= 0 {
+		policy.ReciprocalRankK = defaultReciprocalRankK
+	}
+	return &federatedCollection{
+		members: append([]Collection(nil), members...),
+		name:    strings.Join(names, "+"),
+		policy:  policy,
+	}, nil
+}
+
+type federatedCollection struct {
+	members []Collection
+	name    string
+	policy  MergePolicy
+}
+
+func (c *federatedCollection) Name() string           { return c.name }
+func (c *federatedCollection) Dimension() int         { return c.members[0].Dimension() }
+func (c *federatedCollection) Metric() DistanceMetric { return c.members[0].Metric() }
+
+func (c *federatedCollection) Insert(context.Context, []Record) error {
+	return c.readOnly()
+}
+
+func (c *federatedCollection) Upsert(context.Context, []Record) error {
+	return c.readOnly()
+}
+
+func (c *federatedCollection) Delete(context.Context, []string) (int64, error) {
+	return 0, c.readOnly()
+}
+
+func (c *federatedCollection) readOnly() error {
+	return fmt.Errorf("%w: federated collection %q is read-only", ErrSchemaMismatch, c.name)
+}
+
+func (c *federatedCollection) Get(ctx context.Context, id string) (Record, error) {
+	var failures []error
+	for _, member := range c.members {
+		record, err := member.Get(ctx, id)
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			failures = append(failures, memberError(member, err))
+		}
+	}
+	if len(failures) > 0 && (!c.policy.AllowPartial || len(failures) == len(c.members)) {
+		return Record{}, errors.Join(failures...)
+	}
+	return Record{}, ErrNotFound
+}
+
+func (c *federatedCollection) Count(ctx context.Context, filter Filter) (int64, error) {
+	counts := make([]int64, len(c.members))
+	err := c.fanOut(ctx, func(ctx context.Context, i int, member Collection) error {
+		var err error
+		counts[i], err = member.Count(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sum(counts), nil
+}
+
+func (c *federatedCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: federated results are ranked by merged scores, which cursors cannot page through", errors.ErrUnsupported)
+	}
+	perMember := make([][]SearchResult, len(c.members))
+	err := c.fanOut(ctx, func(ctx context.Context, i int, member Collection) error {
+		var err error
+		perMember[i], err = member.SearchByVector(ctx, vector, topK, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.merge(perMember, topK, opts.OrderBy)
+}
+
+func (c *federatedCollection) EnsureIndexes(ctx context.Context, opts IndexOptions) error {
+	return c.fanOut(ctx, func(ctx context.Context, _ int, member Collection) error {
+		return member.EnsureIndexes(ctx, opts)
+	})
+}
+
+// fanOut calls fn for every member concurrently. It fails when any member
+// fails, or with AllowPartial only when all of them do.
+func (c *federatedCollection) fanOut(ctx context.Context, fn func(ctx context.Context, i int, member Collection) error) error {
+	var failures []error
+	for _, err := range fanOut(ctx, c.members, fn) {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) == 0 || (c.policy.AllowPartial && len(failures) < len(c.members)) {
+		return nil
+	}
+	return errors.Join(failures...)
+}
+
+// fanOut calls fn for every collection concurrently and returns their
+// errors, wrapped with the collection name, by index.
+func fanOut(ctx context.Context, collections []Collection, fn func(ctx context.Context, i int, collection Collection) error) []error {
+	errs := make([]error, len(collections))
+	var wg sync.WaitGroup
+	for i, collection := range collections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, i, collection); err != nil {
+				errs[i] = memberError(collection, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func memberError(member Collection, err error) error {
+	return fmt.Errorf("collection %q: %w", member.Name(), err)
+}
+
+// merge rescores member results under the policy, keeps the best result per
+// ID and returns the topK best. Ties are ordered by orderBy, or keep member
+// order without it.
+func (c *federatedCollection) merge(perMember [][]SearchResult, topK int, orderBy []OrderSpec) ([]SearchResult, error) {
+	best := make(map[string]int)
+	var merged []SearchResult
+	for _, results := range perMember {
+		scores := c.mergedScores(results)
+		for i, result := range results {
+			result.Score = scores[i]
+			if at, ok := best[result.Record.ID]; ok {
+				if result.Score > merged[at].Score {
+					merged[at] = result
+				}
+				continue
+			}
+			best[result.Record.ID] = len(merged)
+			merged = append(merged, result)
+		}
+	}
+	if err := sortByScore(merged, orderBy); err != nil {
+		return nil, err
+	}
+	if topK >= 0 && len(merged) > topK {
+		merged = merged[:topK]
+	}
+	return merged, nil
+}
+
+func (c *federatedCollection) mergedScores(results []SearchResult) []float64 {
+	scores := make([]float64, len(results))
+	switch c.policy.Strategy {
+	case MergeScore:
+		for i, result := range results {
+			scores[i] = result.Score
+		}
+	case MergeReciprocalRank:
+		for i := range results {
+			scores[i] = 1 / float64(c.policy.ReciprocalRankK+i+1)
+		}
+	default:
+		if len(results) == 0 {
+			return scores
+		}
+		low, high := results[0].Score, results[0].Score
+		for _, result := range results {
+			low, high = min(low, result.Score), max(high, result.Score)
+		}
+		for i, result := range results {
+			if high == low {
+				scores[i] = 1
+				continue
+			}
+			scores[i] = (result.Score - low) / (high - low)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,165 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type memberCollection struct {
+	Collection
+	name      string
+	dimension int
+	results   []SearchResult
+	records   map[string]Record
+	count     int64
+	err       error
+}
+
+func (m *memberCollection) Name() string           { return m.name }
+func (m *memberCollection) Dimension() int         { return m.dimension }
+func (m *memberCollection) Metric() DistanceMetric { return DistanceCosine }
+
+func (m *memberCollection) SearchByVector(context.Context, []float32, int, SearchOptions) ([]SearchResult, error) {
+	return m.results, m.err
+}
+
+func (m *memberCollection) Count(context.Context, Filter) (int64, error) {
+	return m.count, m.err
+}
+
+func (m *memberCollection) Get(_ context.Context, id string) (Record, error) {
+	if m.err != nil {
+		return Record{}, m.err
+	}
+	record, ok := m.records[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func scored(id string, score float64) SearchResult {
+	return SearchResult{Record: Record{ID: id}, Score: score}
+}
+
+func resultIDs(results []SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Record.ID
+	}
+	return ids
+}
+
+func TestFederatedCollection_MergesByNormalizedScore(t *testing.T) {
+	// Arrange
+	postgres := &memberCollection{name: "pg", dimension: 2, results: []SearchResult{scored("a", 0.9), scored("b", 0.8), scored("c", 0.7)}}
+	other := &memberCollection{name: "other", dimension: 2, results: []SearchResult{scored("d", 40), scored("b", 30), scored("e", 10)}}
+	federated, err := FederatedCollection([]Collection{postgres, other}, MergePolicy{})
+	if err != nil {
+		t.Fatalf("FederatedCollection: %v", err)
+	}
+
+	// Act
+	results, err := federated.SearchByVector(context.Background(), []float32{1, 0}, 4, SearchOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	got := resultIDs(results)
+	want := []string{"a", "d", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if results[2].Score != 2.0/3 {
+		t.Fatalf("expected b to keep its best normalized score 2/3, got %v", results[2].Score)
+	}
+}
+
+func TestFederatedCollection_ReciprocalRank(t *testing.T) {
+	// Arrange
+	first := &memberCollection{name: "one", dimension: 2, results: []SearchResult{scored("a", 0.9), scored("b", 0.1)}}
+	second := &memberCollection{name: "two", dimension: 2, results: []SearchResult{scored("c", 100)}}
+	federated, _ := FederatedCollection([]Collection{first, second}, MergePolicy{Strategy: MergeReciprocalRank, ReciprocalRankK: 1})
+
+	// Act
+	results, err := federated.SearchByVector(context.Background(), []float32{1, 0}, 10, SearchOptions{})
+
+	// Assert
+	if err != nil || len(results) != 3 || results[0].Score != 0.5 || results[2].Record.ID != "b" {
+		t.Fatalf("unexpected results %+v, %v", results, err)
+	}
+}
+
+func TestFederatedCollection_CountAndGet(t *testing.T) {
+	// Arrange
+	first := &memberCollection{name: "one", dimension: 2, count: 3, records: map[string]Record{}}
+	second := &memberCollection{name: "two", dimension: 2, count: 4, records: map[string]Record{"r1": {ID: "r1"}}}
+	federated, _ := FederatedCollection([]Collection{first, second}, MergePolicy{})
+	ctx := context.Background()
+
+	// Act
+	count, countErr := federated.Count(ctx, nil)
+	record, getErr := federated.Get(ctx, "r1")
+	_, missingErr := federated.Get(ctx, "missing")
+
+	// Assert
+	if countErr != nil || count != 7 {
+		t.Fatalf("expected a summed count of 7, got %d, %v", count, countErr)
+	}
+	if getErr != nil || record.ID != "r1" {
+		t.Fatalf("expected r1 from the second member, got %+v, %v", record, getErr)
+	}
+	if !errors.Is(missingErr, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
+
+func TestFederatedCollection_MemberFailures(t *testing.T) {
+	// Arrange
+	errDown := errors.New("down")
+	healthy := &memberCollection{name: "one", dimension: 2, results: []SearchResult{scored("a", 1)}}
+	failing := &memberCollection{name: "two", dimension: 2, err: errDown}
+	strict, _ := FederatedCollection([]Collection{healthy, failing}, MergePolicy{})
+	partial, _ := FederatedCollection([]Collection{healthy, failing}, MergePolicy{AllowPartial: true})
+	ctx := context.Background()
+
+	// Act
+	_, strictErr := strict.SearchByVector(ctx, []float32{1, 0}, 5, SearchOptions{})
+	results, partialErr := partial.SearchByVector(ctx, []float32{1, 0}, 5, SearchOptions{})
+
+	// Assert
+	if !errors.Is(strictErr, errDown) {
+		t.Fatalf("expected the member error, got %v", strictErr)
+	}
+	if partialErr != nil || len(results) != 1 {
+		t.Fatalf("expected the healthy member's results, got %+v, %v", results, partialErr)
+	}
+}
+
+func TestFederatedCollection_Validation(t *testing.T) {
+	// Arrange
+	small := &memberCollection{name: "small", dimension: 2}
+	large := &memberCollection{name: "large", dimension: 3}
+
+	// Act
+	_, emptyErr := FederatedCollection(nil, MergePolicy{})
+	_, dimensionErr := FederatedCollection([]Collection{small, large}, MergePolicy{})
+	_, strategyErr := FederatedCollection([]Collection{small}, MergePolicy{Strategy: "best"})
+	federated, _ := FederatedCollection([]Collection{small}, MergePolicy{})
+	writeErr := federated.Upsert(context.Background(), []Record{{ID: "r1"}})
+
+	// Assert
+	if !errors.Is(emptyErr, ErrSchemaMismatch) || !errors.Is(dimensionErr, ErrDimensionMismatch) || !errors.Is(strategyErr, ErrSchemaMismatch) {
+		t.Fatalf("unexpected validation errors: %v, %v, %v", emptyErr, dimensionErr, strategyErr)
+	}
+	if !errors.Is(writeErr, ErrSchemaMismatch) {
+		t.Fatalf("expected writes to be rejected, got %v", writeErr)
+	}
+}
@@ -71,6 +71,24 @@ type ExistsFilter struct {
 
 func (ExistsFilter) isFilter() {}
 
+// ContainsFilter checks case-insensitive substring containment in the text
+// form of a field.
+type ContainsFilter struct {
+	Field FieldRef
+	Value string
+}
+
+func (ContainsFilter) isFilter() {}
+
+// SimilarFilter checks trigram similarity between the text form of a field and
+// Value, using the backend similarity threshold (pg_trgm semantics).
+type SimilarFilter struct {
+	Field FieldRef
+	Value string
+}
+
+func (SimilarFilter) isFilter() {}
+
 // AndFilter combines filters with AND.
 type AndFilter struct {
 	Children []Filter
@@ -119,6 +137,16 @@ func Exists(field FieldRef) Filter {
 	return ExistsFilter{Field: field}
 }
 
+// Contains constructs a case-insensitive substring filter.
+func Contains(field FieldRef, value string) Filter {
+	return ContainsFilter{Field: field, Value: value}
+}
+
+// Similar constructs a trigram similarity filter.
+func Similar(field FieldRef, value string) Filter {
+	return SimilarFilter{Field: field, Value: value}
+}
+
 // And constructs an AND filter.
 func And(children ...Filter) Filter {
 	cp := make([]Filter, len(children))
@@ -0,0 +1,194 @@
+package vectordata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// filterJSON is a node of the JSON filter DSL:
+//
+//	{"op": "eq", "field": "metadata.category", "value": "news"}
+//	{"op": "in", "field": "id", "values": ["a", "b"]}
+//	{"op": "gt", "field": "metadata.rank", "value": 5}
+//	{"op": "exists", "field": "metadata.author"}
+//	{"op": "and", "filters": [...]}
+//	{"op": "not", "filter": {...}}
+//
+// The ops are eq, in, gt, lt, exists, contains, similar, and, or and not.
+// Fields are column names such as "id" and "content", or "metadata."
+// followed by a dot-separated path. Path, when set, replaces the metadata
+// path of field "metadata", for keys containing dots.
+type filterJSON struct {
+	Op      string        `json:"op"`
+	Field   string        `json:"field,omitempty"`
+	Path    []string      `json:"path,omitempty"`
+	Value   any           `json:"value,omitempty"`
+	Values  []any         `json:"values,omitempty"`
+	Filters []*filterJSON `json:"filters,omitempty"`
+	Filter  *filterJSON   `json:"filter,omitempty"`
+}
+
+// ParseFilterJSON parses a filter written in the JSON filter DSL, as used by
+// HTTP APIs and tools. Empty input and null parse to a nil filter. Malformed
+// filters fail with ErrInvalidFilter.
+func ParseFilterJSON(data []byte) (Filter, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	var node *filterJSON
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	return node.filter()
+}
+
+// MarshalFilterJSON encodes filter in the JSON filter DSL. A nil filter
+// encodes as null.
+func MarshalFilterJSON(filter Filter) ([]byte, error) {
+	if filter == nil {
+		return []byte("null"), nil
+	}
+	node, err := newFilterJSON(filter)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(node)
+}
+
+func (n *filterJSON) filter() (Filter, error) {
+	if n == nil {
+		return nil, fmt.Errorf("%w: null filter node", ErrInvalidFilter)
+	}
+	switch n.Op {
+	case "and", "or":
+		if len(n.Filters) == 0 {
+			return nil, fmt.Errorf("%w: %q needs filters", ErrInvalidFilter, n.Op)
+		}
+		children := make([]Filter, len(n.Filters))
+		for i, child := range n.Filters {
+			filter, err := child.filter()
+			if err != nil {
+				return nil, err
+			}
+			children[i] = filter
+		}
+		if n.Op == "and" {
+			return And(children...), nil
+		}
+		return Or(children...), nil
+	case "not":
+		child, err := n.Filter.filter()
+		if err != nil {
+			return nil, err
+		}
+		return Not(child), nil
+	}
+
+	field, err := n.fieldRef()
+	if err != nil {
+		return nil, err
+	}
+	switch n.Op {
+	case "eq":
+		return Eq(field, n.Value), nil
+	case "in":
+		return In(field, n.Values...), nil
+	case "gt":
+		return Gt(field, n.Value), nil
+	case "lt":
+		return Lt(field, n.Value), nil
+	case "exists":
+		return Exists(field), nil
+	case "contains", "similar":
+		text, ok := n.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q needs a string value", ErrInvalidFilter, n.Op)
+		}
+		if n.Op == "contains" {
+			return Contains(field, text), nil
+		}
+		return Similar(field, text), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidFilter, n.Op)
+	}
+}
+
+func (n *filterJSON) fieldRef() (FieldRef, error) {
+	switch {
+	case n.Field == "":
+		return FieldRef{}, fmt.Errorf("%w: %q needs a field", ErrInvalidFilter, n.Op)
+	case n.Field == "metadata" && len(n.Path) > 0:
+		return Metadata(n.Path...), nil
+	case strings.HasPrefix(n.Field, "metadata."):
+		return Metadata(strings.Split(strings.TrimPrefix(n.Field, "metadata."), ".")...), nil
+	case n.Field == "metadata":
+		return FieldRef{}, fmt.Errorf("%w: field \"metadata\" needs a path", ErrInvalidFilter)
+	default:
+		return Column(n.Field), nil
+	}
+}
+
+func newFilterJSON(filter Filter) (*filterJSON, error) {
+	switch f := filter.(type) {
+	case EqFilter:
+		return fieldFilterJSON("eq", f.Field, f.Value), nil
+	case InFilter:
+		node := fieldFilterJSON("in", f.Field, nil)
+		node.Values = f.Values
+		return node, nil
+	case GtFilter:
+		return fieldFilterJSON("gt", f.Field, f.Value), nil
+	case LtFilter:
+		return fieldFilterJSON("lt", f.Field, f.Value), nil
+	case ExistsFilter:
+		return fieldFilterJSON("exists", f.Field, nil), nil
+	case ContainsFilter:
+		return fieldFilterJSON("contains", f.Field, f.Value), nil
+	case SimilarFilter:
+		return fieldFilterJSON("similar", f.Field, f.Value), nil
+	case AndFilter:
+		return groupFilterJSON("and", f.Children)
+	case OrFilter:
+		return groupFilterJSON("or", f.Children)
+	case NotFilter:
+		child, err := newFilterJSON(f.Child)
+		if err != nil {
+			return nil, err
+		}
+		return &filterJSON{Op: "not", Filter: child}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported filter type %T", ErrInvalidFilter, filter)
+	}
+}
+
+func groupFilterJSON(op string, children []Filter) (*filterJSON, error) {
+	node := &filterJSON{Op: op}
+	for _, child := range children {
+		encoded, err := newFilterJSON(child)
+		if err != nil {
+			return nil, err
+		}
+		node.Filters = append(node.Filters, encoded)
+	}
+	return node, nil
+}
+
+func fieldFilterJSON(op string, field FieldRef, value any) *filterJSON {
+	node := &filterJSON{Op: op, Value: value}
+	if field.Kind == FieldColumn {
+		node.Field = field.Name
+		return node
+	}
+	node.Field = "metadata." + strings.Join(field.Path, ".")
+	for _, key := range field.Path {
+		if strings.Contains(key, ".") {
+			node.Field, node.Path = "metadata", field.Path
+			break
+		}
+	}
+	return node
+}
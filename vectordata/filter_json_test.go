@@ -0,0 +1,98 @@
+package vectordata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterJSON_Tree(t *testing.T) {
+	// Arrange
+	input := `{"op":"and","filters":[
+		{"op":"eq","field":"metadata.category","value":"news"},
+		{"op":"or","filters":[
+			{"op":"in","field":"id","values":["a","b"]},
+			{"op":"not","filter":{"op":"exists","field":"metadata","path":["a.b"]}}
+		]},
+		{"op":"gt","field":"metadata.stats.rank","value":5},
+		{"op":"contains","field":"content","value":"go"}
+	]}`
+
+	// Act
+	filter, err := ParseFilterJSON([]byte(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseFilterJSON: %v", err)
+	}
+	want := And(
+		Eq(Metadata("category"), "news"),
+		Or(In(Column("id"), "a", "b"), Not(Exists(Metadata("a.b")))),
+		Gt(Metadata("stats", "rank"), float64(5)),
+		Contains(Column("content"), "go"),
+	)
+	if !reflect.DeepEqual(filter, want) {
+		t.Fatalf("filter = %#v, want %#v", filter, want)
+	}
+}
+
+func TestParseFilterJSON_EmptyIsNil(t *testing.T) {
+	for _, input := range []string{"", "  ", "null"} {
+		// Act
+		filter, err := ParseFilterJSON([]byte(input))
+
+		// Assert
+		if err != nil || filter != nil {
+			t.Fatalf("ParseFilterJSON(%q) = %v, %v; want nil, nil", input, filter, err)
+		}
+	}
+}
+
+func TestParseFilterJSON_Invalid(t *testing.T) {
+	for _, input := range []string{
+		`{"op":"eq","value":1}`,
+		`{"op":"between","field":"id"}`,
+		`{"op":"and","filters":[]}`,
+		`{"op":"not"}`,
+		`{"op":"contains","field":"content","value":3}`,
+		`{"op":"exists","field":"metadata"}`,
+		`[1,2]`,
+	} {
+		// Act
+		_, err := ParseFilterJSON([]byte(input))
+
+		// Assert
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("ParseFilterJSON(%s) error = %v, want ErrInvalidFilter", input, err)
+		}
+	}
+}
+
+func TestMarshalFilterJSON_RoundTrip(t *testing.T) {
+	// Arrange
+	filter := Or(
+		Eq(Column("id"), "a"),
+		And(Lt(Metadata("price"), float64(10)), Similar(Metadata("title.en"), "gopher")),
+		Not(In(Metadata("tags"), "x", "y")),
+	)
+
+	// Act
+	data, err := MarshalFilterJSON(filter)
+	if err != nil {
+		t.Fatalf("MarshalFilterJSON: %v", err)
+	}
+	parsed, err := ParseFilterJSON(data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseFilterJSON(%s): %v", data, err)
+	}
+	want := Or(
+		Eq(Column("id"), "a"),
+		And(Lt(Metadata("price"), float64(10)), Similar(Metadata("title.en"), "gopher")),
+		Not(In(Metadata("tags"), "x", "y")),
+	)
+	if !reflect.DeepEqual(parsed, want) {
+		t.Fatalf("round trip = %#v, want %#v", parsed, want)
+	}
+}
@@ -0,0 +1,379 @@
+package vectordata
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	numericTextRegexp   = regexp.MustCompile(numericTextPattern)
+	timestampTextRegexp = regexp.MustCompile(TimestampTextPattern)
+)
+
+// matchResult is a three-valued logic result mirroring SQL NULL handling.
+type matchResult int
+
+const (
+	matchFalse matchResult = iota
+	matchTrue
+	matchUnknown
+)
+
+// MatchFilter reports whether a record satisfies a filter, evaluated in Go
+// with the same semantics as CompileFilterSQL. Comparisons against missing
+// fields are unknown rather than false, so Not(Eq(...)) does not match records
+// that lack the field, exactly as in SQL.
+func MatchFilter(filter Filter, record Record) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	result, err := matchNode(filter, record)
+	if err != nil {
+		return false, err
+	}
+	return result == matchTrue, nil
+}
+
+func matchNode(f Filter, record Record) (matchResult, error) {
+	switch node := f.(type) {
+	case EqFilter:
+		return matchEq(node.Field, node.Value, record)
+	case InFilter:
+		if len(node.Values) == 0 {
+			return matchFalse, fmt.Errorf("%w: IN requires at least one value", ErrInvalidFilter)
+		}
+		result := matchFalse
+		for _, v := range node.Values {
+			r, err := matchEq(node.Field, v, record)
+			if err != nil {
+				return matchFalse, err
+			}
+			if r == matchTrue {
+				return matchTrue, nil
+			}
+			if r == matchUnknown {
+				result = matchUnknown
+			}
+		}
+		return result, nil
+	case GtFilter:
+		return matchCompare(node.Field, node.Value, record, 1)
+	case LtFilter:
+		return matchCompare(node.Field, node.Value, record, -1)
+	case ExistsFilter:
+		field, err := NormalizeFieldRef(node.Field)
+		if err != nil {
+			return matchFalse, err
+		}
+		_, present, err := resolveMatchField(field, record)
+		if err != nil {
+			return matchFalse, err
+		}
+		return boolResult(present), nil
+	case ContainsFilter:
+		return matchText(node.Field, record, func(text string) bool {
+			return strings.Contains(strings.ToLower(text), strings.ToLower(node.Value))
+		})
+	case SimilarFilter:
+		return matchText(node.Field, record, func(text string) bool {
+			return trigramSimilarity(text, node.Value) >= defaultSimilarityThreshold
+		})
+	case AndFilter:
+		return matchLogical(node.Children, record, true)
+	case OrFilter:
+		return matchLogical(node.Children, record, false)
+	case NotFilter:
+		if node.Child == nil {
+			return matchFalse, fmt.Errorf("%w: NOT requires a child", ErrInvalidFilter)
+		}
+		r, err := matchNode(node.Child, record)
+		if err != nil {
+			return matchFalse, err
+		}
+		switch r {
+		case matchTrue:
+			return matchFalse, nil
+		case matchFalse:
+			return matchTrue, nil
+		default:
+			return matchUnknown, nil
+		}
+	default:
+		return matchFalse, fmt.Errorf("%w: unsupported node type %T", ErrInvalidFilter, f)
+	}
+}
+
+func matchLogical(children []Filter, record Record, isAnd bool) (matchResult, error) {
+	if len(children) == 0 {
+		return matchFalse, fmt.Errorf("%w: logical filter requires at least one child", ErrInvalidFilter)
+	}
+	// AND short-circuits on false, OR on true; unknown wins over the other outcome.
+	decisive, result := matchTrue, matchFalse
+	if isAnd {
+		decisive, result = matchFalse, matchTrue
+	}
+	for _, child := range children {
+		if child == nil {
+			return matchFalse, fmt.Errorf("%w: logical filter contains nil child", ErrInvalidFilter)
+		}
+		r, err := matchNode(child, record)
+		if err != nil {
+			return matchFalse, err
+		}
+		if r == decisive {
+			return decisive, nil
+		}
+		if r == matchUnknown {
+			result = matchUnknown
+		}
+	}
+	return result, nil
+}
+
+func matchEq(ref FieldRef, value any, record Record) (matchResult, error) {
+	field, err := NormalizeFieldRef(ref)
+	if err != nil {
+		return matchFalse, err
+	}
+	actual, present, err := resolveMatchField(field, record)
+	if err != nil {
+		return matchFalse, err
+	}
+	if !present {
+		return matchUnknown, nil
+	}
+	if field.Kind == FieldColumn {
+		return boolResult(fmt.Sprint(actual) == fmt.Sprint(value)), nil
+	}
+
+	expected, err := normalizeJSONValue(value)
+	if err != nil {
+		return matchFalse, fmt.Errorf("%w: JSON encode value: %v", ErrInvalidFilter, err)
+	}
+	normalized, err := normalizeJSONValue(actual)
+	if err != nil {
+		return matchFalse, fmt.Errorf("normalize metadata value: %w", err)
+	}
+	return boolResult(reflect.DeepEqual(normalized, expected)), nil
+}
+
+// metadataTimestamp parses an RFC 3339 metadata string the way
+// FilterSQLConfig.TimestampFunc does.
+func metadataTimestamp(value any) (time.Time, bool) {
+	text, ok := metadataText(value)
+	if !ok || !timestampTextRegexp.MatchString(text) {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, strings.Replace(text, " ", "T", 1))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// matchCompare evaluates Gt (sign 1) and Lt (sign -1). Numeric filter values
+// compare against numeric metadata (including numeric strings) and time values
+// against RFC 3339 metadata strings, as with FilterSQLConfig.TimestampFunc set;
+// both are false otherwise. Other values compare against the text form of the
+// field.
+func matchCompare(ref FieldRef, value any, record Record, sign int) (matchResult, error) {
+	field, err := NormalizeFieldRef(ref)
+	if err != nil {
+		return matchFalse, err
+	}
+	actual, present, err := resolveMatchField(field, record)
+	if err != nil {
+		return matchFalse, err
+	}
+
+	if num, ok := toFloat64(value); ok && field.Kind == FieldMetadata {
+		if !present {
+			return matchFalse, nil
+		}
+		text, ok := metadataText(actual)
+		if !ok || !numericTextRegexp.MatchString(text) {
+			return matchFalse, nil
+		}
+		parsed, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return matchFalse, nil
+		}
+		return boolResult(compareOrdered(parsed, num) == sign), nil
+	}
+	if ts, ok := value.(time.Time); ok && field.Kind == FieldMetadata {
+		if !present {
+			return matchFalse, nil
+		}
+		parsed, ok := metadataTimestamp(actual)
+		if !ok {
+			return matchFalse, nil
+		}
+		return boolResult(parsed.Compare(ts) == sign), nil
+	}
+
+	if !present {
+		return matchUnknown, nil
+	}
+	text := fmt.Sprint(actual)
+	if field.Kind == FieldMetadata {
+		metadataString, ok := metadataText(actual)
+		if !ok {
+			return matchUnknown, nil
+		}
+		text = metadataString
+	}
+	target := fmt.Sprint(value)
+	switch {
+	case text > target:
+		return boolResult(sign == 1), nil
+	case text < target:
+		return boolResult(sign == -1), nil
+	default:
+		return matchFalse, nil
+	}
+}
+
+// matchText applies a predicate to the text form of a field, which is
+// unknown when the field is missing or JSON null.
+func matchText(ref FieldRef, record Record, predicate func(string) bool) (matchResult, error) {
+	field, err := NormalizeFieldRef(ref)
+	if err != nil {
+		return matchFalse, err
+	}
+	actual, present, err := resolveMatchField(field, record)
+	if err != nil {
+		return matchFalse, err
+	}
+	if !present {
+		return matchUnknown, nil
+	}
+	text := fmt.Sprint(actual)
+	if field.Kind == FieldMetadata {
+		metadataString, ok := metadataText(actual)
+		if !ok {
+			return matchUnknown, nil
+		}
+		text = metadataString
+	}
+	return boolResult(predicate(text)), nil
+}
+
+// defaultSimilarityThreshold is pg_trgm.similarity_threshold's default.
+const defaultSimilarityThreshold = 0.3
+
+// trigramSimilarity mirrors pg_trgm's similarity(): both strings are split
+// into lowercased alphanumeric words, each word is padded with two leading
+// spaces and one trailing space, and the result is the shared trigram count
+// divided by the size of the trigram union.
+func trigramSimilarity(a, b string) float64 {
+	left, right := trigrams(a), trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range left {
+		if _, ok := right[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(left)+len(right)-shared)
+}
+
+func trigrams(s string) map[string]struct{} {
+	out := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			out[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return out
+}
+
+// resolveMatchField returns the field value and whether it is present.
+// JSON null metadata values are present; a nil content column is not.
+func resolveMatchField(field FieldRef, record Record) (any, bool, error) {
+	if field.Kind == FieldColumn {
+		switch field.Name {
+		case "id":
+			return record.ID, true, nil
+		case "content":
+			if record.Content == nil {
+				return nil, false, nil
+			}
+			return *record.Content, true, nil
+		default:
+			return nil, false, fmt.Errorf("%w: unknown column %q", ErrInvalidFilter, field.Name)
+		}
+	}
+
+	var current any = record.Metadata
+	for _, segment := range field.Path {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false, nil
+		}
+		next, ok := object[segment]
+		if !ok {
+			return nil, false, nil
+		}
+		current = next
+	}
+	return current, true, nil
+}
+
+// metadataText mirrors jsonb_extract_path_text: strings are returned raw,
+// JSON null yields SQL NULL, and other values use their JSON encoding.
+func metadataText(v any) (string, bool) {
+	switch typed := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return typed, true
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return "", false
+		}
+		return string(encoded), true
+	}
+}
+
+func normalizeJSONValue(v any) (any, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func boolResult(ok bool) matchResult {
+	if ok {
+		return matchTrue
+	}
+	return matchFalse
+}
@@ -0,0 +1,122 @@
+package vectordata
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func matchTestRecord() Record {
+	content := "hello"
+	return Record{
+		ID:      "r1",
+		Content: &content,
+		Metadata: map[string]any{
+			"category": "news",
+			"rank":     7,
+			"score":    "12.5",
+			"pinned":   true,
+			"nothing":  nil,
+			"flags":    map[string]any{"beta": true},
+			"created":  "2024-05-01T12:00:00+02:00",
+		},
+	}
+}
+
+func TestMatchFilter_Basic(t *testing.T) {
+	cases := map[string]struct {
+		filter   Filter
+		expected bool
+	}{
+		"nil filter":           {nil, true},
+		"column eq":            {Eq(Column("id"), "r1"), true},
+		"content eq":           {Eq(Column("content"), "hello"), true},
+		"metadata eq":          {Eq(Metadata("category"), "news"), true},
+		"numeric eq float":     {Eq(Metadata("rank"), 7.0), true},
+		"typed eq mismatch":    {Eq(Metadata("rank"), "7"), false},
+		"nested eq":            {Eq(Metadata("flags", "beta"), true), true},
+		"json null eq":         {Eq(Metadata("nothing"), nil), true},
+		"in":                   {In(Metadata("category"), "sports", "news"), true},
+		"gt numeric":           {Gt(Metadata("rank"), 5), true},
+		"gt numeric string":    {Gt(Metadata("score"), 12), true},
+		"lt on bool is false":  {Lt(Metadata("pinned"), 100), false},
+		"text comparison":      {Lt(Metadata("category"), "zzz"), true},
+		"exists":               {Exists(Metadata("nothing")), true},
+		"exists missing":       {Exists(Metadata("missing")), false},
+		"and":                  {And(Eq(Column("id"), "r1"), Gt(Metadata("rank"), 1)), true},
+		"or":                   {Or(Eq(Column("id"), "x"), Eq(Metadata("pinned"), true)), true},
+		"not":                  {Not(Eq(Metadata("category"), "sports")), true},
+		"not eq missing":       {Not(Eq(Metadata("missing"), "x")), false},
+		"not numeric missing":  {Not(Gt(Metadata("missing"), 1)), true},
+		"or unknown and true":  {Or(Eq(Metadata("missing"), "x"), Eq(Column("id"), "r1")), true},
+		"and unknown and true": {Not(And(Eq(Metadata("missing"), "x"), Eq(Column("id"), "r1"))), false},
+		"contains content":     {Contains(Column("content"), "ELL"), true},
+		"contains metadata":    {Contains(Metadata("category"), "ew"), true},
+		"not contains missing": {Not(Contains(Metadata("missing"), "x")), false},
+		"similar typo":         {Similar(Metadata("category"), "newz"), true},
+		"similar unrelated":    {Similar(Column("content"), "world"), false},
+		"gt timestamp":         {Gt(Metadata("created"), time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)), true},
+		"lt timestamp":         {Lt(Metadata("created"), time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)), false},
+		"not timestamp text":   {Not(Gt(Metadata("category"), time.Now())), true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			matched, err := MatchFilter(tc.filter, matchTestRecord())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("MatchFilter error: %v", err)
+			}
+			if matched != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, matched)
+			}
+		})
+	}
+}
+
+func TestMatchFilter_InvalidFilter(t *testing.T) {
+	// Arrange
+	filters := []Filter{
+		In(Metadata("a")),
+		And(),
+		Not(nil),
+		Eq(Column("vector"), "x"),
+		Eq(Metadata(" "), "x"),
+	}
+
+	for _, filter := range filters {
+		// Act
+		_, err := MatchFilter(filter, matchTestRecord())
+
+		// Assert
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("expected ErrInvalidFilter for %#v, got %v", filter, err)
+		}
+	}
+}
+
+func TestTrigramSimilarity_MatchesPgTrgm(t *testing.T) {
+	// Arrange
+	// Expected values are from PostgreSQL: SELECT similarity(a, b).
+	cases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"word", "two words", 0.36363637},
+		{"hello", "hello", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+	}
+
+	for _, tc := range cases {
+		// Act
+		got := trigramSimilarity(tc.a, tc.b)
+
+		// Assert
+		if diff := got - tc.expected; diff > 1e-6 || diff < -1e-6 {
+			t.Fatalf("similarity(%q, %q): want %v, got %v", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
-const numericTextPattern = `^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$`
+const (
+	numericTextPattern = `^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$`
+	// TimestampTextPattern matches RFC 3339 timestamps with an explicit
+	// offset, which parse to the same instant under any session time zone.
+	TimestampTextPattern = `^[0-9]{4}-[0-9]{2}-[0-9]{2}[T ][0-9]{2}:[0-9]{2}:[0-9]{2}([.][0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})$`
+)
 
 // FilterSQLConfig configures filter compilation into SQL expressions.
 type FilterSQLConfig struct {
@@ -14,6 +20,11 @@ type FilterSQLConfig struct {
 	ColumnExpr map[string]string
 	// MetadataExpr is the SQL expression of the metadata JSONB column.
 	MetadataExpr string
+	// TimestampFunc is an immutable text -> timestamptz function returning
+	// NULL for text that does not match TimestampTextPattern. When set,
+	// metadata Gt/Lt with time.Time values compare through it; otherwise they
+	// compare RFC 3339 UTC text.
+	TimestampFunc string
 }
 
 // CompileFilterSQL compiles a Filter tree into SQL WHERE fragment and args.
@@ -55,6 +66,10 @@ func (c *filterCompiler) compile(f Filter) (string, error) {
 		return c.compileLt(node)
 	case ExistsFilter:
 		return c.compileExists(node)
+	case ContainsFilter:
+		return c.compileText(node.Field, "ILIKE", "%"+escapeLikePattern(node.Value)+"%")
+	case SimilarFilter:
+		return c.compileText(node.Field, "%", node.Value)
 	case AndFilter:
 		return c.compileLogical("AND", node.Children)
 	case OrFilter:
@@ -124,10 +139,7 @@ func (c *filterCompiler) compileGt(node GtFilter) (string, error) {
 	if !isMetadata {
 		return fmt.Sprintf("(%s > %s)", fieldExpr, c.bind(node.Value)), nil
 	}
-	if num, ok := toFloat64(node.Value); ok {
-		return c.compileMetadataNumericCompare(fieldExpr, path, ">", num), nil
-	}
-	return fmt.Sprintf("(%s > %s)", metadataPathTextExpr(fieldExpr, path), c.bind(fmt.Sprint(node.Value))), nil
+	return c.compileMetadataCompare(fieldExpr, path, ">", node.Value), nil
 }
 
 func (c *filterCompiler) compileLt(node LtFilter) (string, error) {
@@ -138,22 +150,29 @@ func (c *filterCompiler) compileLt(node LtFilter) (string, error) {
 	if !isMetadata {
 		return fmt.Sprintf("(%s < %s)", fieldExpr, c.bind(node.Value)), nil
 	}
-	if num, ok := toFloat64(node.Value); ok {
-		return c.compileMetadataNumericCompare(fieldExpr, path, "<", num), nil
+	return c.compileMetadataCompare(fieldExpr, path, "<", node.Value), nil
+}
+
+// compileMetadataCompare compares a metadata path by the type of value.
+// Numeric and time values compare a typed expression that is NULL for values
+// of another type; the IS NOT NULL conjunct keeps such rows false rather than
+// unknown, so NOT matches them, while the typed comparison can still use an
+// expression index built with MetadataPathNumericSQL or MetadataPathTimestampSQL.
+func (c *filterCompiler) compileMetadataCompare(metadataExpr string, path []string, op string, value any) string {
+	if num, ok := toFloat64(value); ok {
+		return c.compileTypedCompare(metadataPathNumericExpr(metadataExpr, path), op, num)
 	}
-	return fmt.Sprintf("(%s < %s)", metadataPathTextExpr(fieldExpr, path), c.bind(fmt.Sprint(node.Value))), nil
+	if ts, ok := value.(time.Time); ok {
+		if c.cfg.TimestampFunc != "" {
+			return c.compileTypedCompare(MetadataPathTimestampSQL(metadataExpr, path, c.cfg.TimestampFunc), op, ts)
+		}
+		value = ts.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("(%s %s %s)", metadataPathTextExpr(metadataExpr, path), op, c.bind(fmt.Sprint(value)))
 }
 
-func (c *filterCompiler) compileMetadataNumericCompare(metadataExpr string, path []string, op string, value float64) string {
-	textExpr := metadataPathTextExpr(metadataExpr, path)
-	return fmt.Sprintf(
-		"(CASE WHEN (%s) ~ %s THEN ((%s)::double precision %s %s) ELSE FALSE END)",
-		textExpr,
-		singleQuoted(numericTextPattern),
-		textExpr,
-		op,
-		c.bind(value),
-	)
+func (c *filterCompiler) compileTypedCompare(typedExpr string, op string, value any) string {
+	return fmt.Sprintf("((%s %s %s) AND (%s IS NOT NULL))", typedExpr, op, c.bind(value), typedExpr)
 }
 
 func (c *filterCompiler) compileExists(node ExistsFilter) (string, error) {
@@ -167,6 +186,19 @@ func (c *filterCompiler) compileExists(node ExistsFilter) (string, error) {
 	return fmt.Sprintf("(%s IS NOT NULL)", metadataPathJSONBExpr(fieldExpr, path)), nil
 }
 
+// compileText compares the text form of a field, which lets pg_trgm GIN
+// indexes serve ILIKE and % (similarity) predicates.
+func (c *filterCompiler) compileText(ref FieldRef, op string, value string) (string, error) {
+	fieldExpr, isMetadata, path, err := c.resolveField(ref)
+	if err != nil {
+		return "", err
+	}
+	if isMetadata {
+		fieldExpr = metadataPathTextExpr(fieldExpr, path)
+	}
+	return fmt.Sprintf("(%s %s %s)", fieldExpr, op, c.bind(value)), nil
+}
+
 func (c *filterCompiler) compileLogical(op string, children []Filter) (string, error) {
 	if len(children) == 0 {
 		return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, op)
@@ -235,6 +267,41 @@ func metadataPathJSONBExpr(metadataExpr string, path []string) string {
 	return fmt.Sprintf("(%s #> ARRAY[%s])", metadataExpr, pathArraySQL(path))
 }
 
+// MetadataPathTextSQL returns the expression compiled filters use for the
+// text value of a metadata path, so backends can build matching expression
+// indexes.
+func MetadataPathTextSQL(metadataExpr string, path []string) string {
+	return metadataPathTextExpr(metadataExpr, path)
+}
+
+// MetadataPathNumericSQL returns the expression compiled filters compare for
+// numeric values: the path as double precision, or NULL when its text is not
+// numeric.
+func MetadataPathNumericSQL(metadataExpr string, path []string) string {
+	return metadataPathNumericExpr(metadataExpr, path)
+}
+
+// MetadataPathTimestampSQL returns the expression compiled filters compare for
+// time values, given FilterSQLConfig.TimestampFunc.
+func MetadataPathTimestampSQL(metadataExpr string, path []string, timestampFunc string) string {
+	return fmt.Sprintf("%s(%s)", timestampFunc, metadataPathTextExpr(metadataExpr, path))
+}
+
+func metadataPathNumericExpr(metadataExpr string, path []string) string {
+	textExpr := metadataPathTextExpr(metadataExpr, path)
+	return fmt.Sprintf(
+		"(CASE WHEN (%s) ~ %s THEN (%s)::double precision END)",
+		textExpr,
+		singleQuoted(numericTextPattern),
+		textExpr,
+	)
+}
+
+// escapeLikePattern escapes LIKE wildcards using the default backslash escape.
+func escapeLikePattern(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(v)
+}
+
 func metadataPathTextExpr(metadataExpr string, path []string) string {
 	parts := make([]string, 0, len(path))
 	for _, p := range path {
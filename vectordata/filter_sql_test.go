@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func testFilterConfig() FilterSQLConfig {
@@ -34,7 +35,7 @@ func TestCompileFilterSQL_Complex(t *testing.T) {
 		t.Fatalf("CompileFilterSQL error: %v", err)
 	}
 
-	expectedSQL := `(("id" = $1) AND ((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN ((jsonb_extract_path_text("metadata", 'rank'))::double precision > $2) ELSE FALSE END) OR (("metadata" #> ARRAY['flags', 'pinned']) IS NOT NULL)))`
+	expectedSQL := `(("id" = $1) AND ((((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN (jsonb_extract_path_text("metadata", 'rank'))::double precision END) > $2) AND ((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN (jsonb_extract_path_text("metadata", 'rank'))::double precision END) IS NOT NULL)) OR (("metadata" #> ARRAY['flags', 'pinned']) IS NOT NULL)))`
 	if sql != expectedSQL {
 		t.Fatalf("unexpected SQL\nwant: %s\n got: %s", expectedSQL, sql)
 	}
@@ -60,7 +61,7 @@ func TestCompileFilterSQL_MetadataGtNumericUsesSafeCast(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CompileFilterSQL error: %v", err)
 	}
-	expectedSQL := `(CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN ((jsonb_extract_path_text("metadata", 'rank'))::double precision > $1) ELSE FALSE END)`
+	expectedSQL := `(((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN (jsonb_extract_path_text("metadata", 'rank'))::double precision END) > $1) AND ((CASE WHEN (jsonb_extract_path_text("metadata", 'rank')) ~ '^[+-]?([0-9]+([.][0-9]*)?|[.][0-9]+)([eE][+-]?[0-9]+)?$' THEN (jsonb_extract_path_text("metadata", 'rank'))::double precision END) IS NOT NULL))`
 	if sql != expectedSQL {
 		t.Fatalf("unexpected SQL\nwant: %s\n got: %s", expectedSQL, sql)
 	}
@@ -73,6 +74,32 @@ func TestCompileFilterSQL_MetadataGtNumericUsesSafeCast(t *testing.T) {
 	}
 }
 
+func TestCompileFilterSQL_MetadataTimestampCompare(t *testing.T) {
+	// Arrange
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testFilterConfig()
+	cfg.TimestampFunc = `"public"."to_ts"`
+
+	// Act
+	withFunc, withFuncArgs, _, withFuncErr := CompileFilterSQL(Lt(Metadata("created"), at), cfg, 1)
+	asText, asTextArgs, _, asTextErr := CompileFilterSQL(Lt(Metadata("created"), at), testFilterConfig(), 1)
+
+	// Assert
+	if withFuncErr != nil || asTextErr != nil {
+		t.Fatalf("CompileFilterSQL errors: %v, %v", withFuncErr, asTextErr)
+	}
+	expected := `(("public"."to_ts"(jsonb_extract_path_text("metadata", 'created')) < $1) AND ("public"."to_ts"(jsonb_extract_path_text("metadata", 'created')) IS NOT NULL))`
+	if withFunc != expected {
+		t.Fatalf("unexpected SQL\nwant: %s\n got: %s", expected, withFunc)
+	}
+	if !reflect.DeepEqual(withFuncArgs, []any{at}) {
+		t.Fatalf("unexpected args %#v", withFuncArgs)
+	}
+	if asText != `(jsonb_extract_path_text("metadata", 'created') < $1)` || !reflect.DeepEqual(asTextArgs, []any{"2024-05-01T12:00:00Z"}) {
+		t.Fatalf("unexpected text comparison %s %#v", asText, asTextArgs)
+	}
+}
+
 func TestCompileFilterSQL_StartArgOffset(t *testing.T) {
 	// Arrange
 	filter := Eq(Column("content"), "hello")
@@ -189,3 +216,30 @@ func TestCompileFilterSQL_MetadataPathRejectsWhitespaceSegment(t *testing.T) {
 		t.Fatalf("expected ErrInvalidFilter, got %v", err)
 	}
 }
+
+func TestCompileFilterSQL_TextPredicates(t *testing.T) {
+	// Arrange
+	filter := And(
+		Contains(Column("content"), "50%_off"),
+		Similar(Metadata("title", "en"), "postgress"),
+	)
+
+	// Act
+	sql, args, next, err := CompileFilterSQL(filter, testFilterConfig(), 1)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CompileFilterSQL error: %v", err)
+	}
+	expectedSQL := `(("content" ILIKE $1) AND (jsonb_extract_path_text("metadata", 'title', 'en') % $2))`
+	if sql != expectedSQL {
+		t.Fatalf("unexpected SQL\nwant: %s\n got: %s", expectedSQL, sql)
+	}
+	expectedArgs := []any{`%50\%\_off%`, "postgress"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Fatalf("unexpected args\nwant: %#v\n got: %#v", expectedArgs, args)
+	}
+	if next != 3 {
+		t.Fatalf("unexpected next arg index: want 3 got %d", next)
+	}
+}
@@ -0,0 +1,92 @@
+package vectordata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilterComplexity reports the number of nodes in a filter AST and its depth.
+// A nil filter has neither.
+func FilterComplexity(filter Filter) (nodes, depth int) {
+	if filter == nil {
+		return 0, 0
+	}
+
+	var children []Filter
+	switch f := filter.(type) {
+	case AndFilter:
+		children = f.Children
+	case OrFilter:
+		children = f.Children
+	case NotFilter:
+		children = []Filter{f.Child}
+	}
+
+	nodes = 1
+	for _, child := range children {
+		childNodes, childDepth := FilterComplexity(child)
+		nodes += childNodes
+		depth = max(depth, childDepth)
+	}
+	return nodes, depth + 1
+}
+
+// DescribeFilter renders the shape of a filter without its values, e.g.
+// and(eq(metadata.category), in[3](id)), for logs that must not carry data.
+func DescribeFilter(filter Filter) string {
+	var b strings.Builder
+	describeFilter(&b, filter)
+	return b.String()
+}
+
+func describeFilter(b *strings.Builder, filter Filter) {
+	switch f := filter.(type) {
+	case nil:
+	case EqFilter:
+		describeLeaf(b, "eq", f.Field)
+	case InFilter:
+		describeLeaf(b, fmt.Sprintf("in[%d]", len(f.Values)), f.Field)
+	case GtFilter:
+		describeLeaf(b, "gt", f.Field)
+	case LtFilter:
+		describeLeaf(b, "lt", f.Field)
+	case ExistsFilter:
+		describeLeaf(b, "exists", f.Field)
+	case ContainsFilter:
+		describeLeaf(b, "contains", f.Field)
+	case SimilarFilter:
+		describeLeaf(b, "similar", f.Field)
+	case AndFilter:
+		describeGroup(b, "and", f.Children)
+	case OrFilter:
+		describeGroup(b, "or", f.Children)
+	case NotFilter:
+		describeGroup(b, "not", []Filter{f.Child})
+	default:
+		fmt.Fprintf(b, "%T", filter)
+	}
+}
+
+func describeLeaf(b *strings.Builder, op string, field FieldRef) {
+	b.WriteString(op)
+	b.WriteByte('(')
+	if field.Kind == FieldMetadata {
+		b.WriteString("metadata.")
+		b.WriteString(strings.Join(field.Path, "."))
+	} else {
+		b.WriteString(field.Name)
+	}
+	b.WriteByte(')')
+}
+
+func describeGroup(b *strings.Builder, op string, children []Filter) {
+	b.WriteString(op)
+	b.WriteByte('(')
+	for i, child := range children {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		describeFilter(b, child)
+	}
+	b.WriteByte(')')
+}
@@ -0,0 +1,53 @@
+package vectordata
+
+import "testing"
+
+func TestFilterComplexity(t *testing.T) {
+	cases := map[string]struct {
+		filter Filter
+		nodes  int
+		depth  int
+	}{
+		"nil":  {nil, 0, 0},
+		"leaf": {Eq(Metadata("category"), "news"), 1, 1},
+		"and":  {And(Eq(Column("id"), "r1"), Gt(Metadata("rank"), 5)), 3, 2},
+		"nested": {
+			Or(Not(Exists(Metadata("author"))), And(In(Metadata("category"), "a", "b"), Lt(Metadata("rank"), 3))),
+			6, 3,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			nodes, depth := FilterComplexity(tc.filter)
+
+			// Assert
+			if nodes != tc.nodes || depth != tc.depth {
+				t.Fatalf("expected %d nodes at depth %d, got %d at depth %d", tc.nodes, tc.depth, nodes, depth)
+			}
+		})
+	}
+}
+
+func TestDescribeFilter(t *testing.T) {
+	// Arrange
+	filter := And(
+		Eq(Metadata("author", "name"), "secret"),
+		Or(In(Column("id"), "a", "b", "c"), Not(Exists(Metadata("draft")))),
+		Gt(Metadata("rank"), 5),
+	)
+
+	// Act
+	described := DescribeFilter(filter)
+	empty := DescribeFilter(nil)
+
+	// Assert
+	expected := "and(eq(metadata.author.name), or(in[3](id), not(exists(metadata.draft))), gt(metadata.rank))"
+	if described != expected {
+		t.Fatalf("expected %q, got %q", expected, described)
+	}
+	if empty != "" {
+		t.Fatalf("expected an empty description for a nil filter, got %q", empty)
+	}
+}
@@ -0,0 +1,194 @@
+package vectordata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gabisonia/go-vectorstore/vectordata/vectormath"
+)
+
+const (
+	defaultHighlightStartSel = "<b>"
+	defaultHighlightStopSel  = "</b>"
+	defaultHighlightMaxWords = 35
+)
+
+// HighlightOptions configures the snippets of matching content returned in
+// SearchResult.Snippets. On Postgres, text and hybrid searches build them
+// with ts_headline; KeywordSnippets and EmbeddingSnippets build them in Go,
+// e.g. for vector search results.
+type HighlightOptions struct {
+	// StartSel and StopSel wrap matching words (default "<b>" and "</b>").
+	// Content is not escaped.
+	StartSel string
+	StopSel  string
+	// MaxWords is the length of a snippet in words (default 35).
+	MaxWords int
+	// MaxFragments is the number of snippets to return. Zero returns one;
+	// ts_headline then returns a headline rather than fragments, and joins
+	// several fragments into one snippet with " ... ".
+	MaxFragments int
+}
+
+// Validate reports a MaxWords of 1, which leaves no room for context, and
+// negative counts.
+func (o HighlightOptions) Validate() error {
+	if o.MaxWords < 0 || o.MaxWords == 1 || o.MaxFragments < 0 {
+		return fmt.Errorf("%w: highlight MaxWords must be 0 or >= 2 and MaxFragments >= 0", ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// WithDefaults returns o with zero fields set to their defaults.
+func (o HighlightOptions) WithDefaults() HighlightOptions {
+	if o.StartSel == "" && o.StopSel == "" {
+		o.StartSel, o.StopSel = defaultHighlightStartSel, defaultHighlightStopSel
+	}
+	if o.MaxWords == 0 {
+		o.MaxWords = defaultHighlightMaxWords
+	}
+	return o
+}
+
+func (o HighlightOptions) fragments() int {
+	return max(o.MaxFragments, 1)
+}
+
+// HighlightResults sets the Snippets of every result with content to what
+// snippets returns for it. Results need content in their projection.
+func HighlightResults(results []SearchResult, snippets func(content string) ([]string, error)) error {
+	for i := range results {
+		if results[i].Record.Content == nil {
+			continue
+		}
+		var err error
+		if results[i].Snippets, err = snippets(*results[i].Record.Content); err != nil {
+			return fmt.Errorf("highlight %q: %w", results[i].Record.ID, err)
+		}
+	}
+	return nil
+}
+
+// KeywordSnippets returns the non-overlapping windows of MaxWords words of
+// content holding the most words of query, best first, with those words wrapped in StartSel
+// and StopSel. Words match case-insensitively, ignoring surrounding
+// punctuation. Without any match it returns the start of content.
+func KeywordSnippets(content, query string, opts HighlightOptions) []string {
+	opts = opts.WithDefaults()
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return nil
+	}
+	terms := make(map[string]bool)
+	for _, term := range strings.Fields(query) {
+		if term = normalizeWord(term); term != "" {
+			terms[term] = true
+		}
+	}
+	matched := make([]bool, len(words))
+	for i, word := range words {
+		matched[i] = terms[normalizeWord(word)]
+	}
+
+	// Every window start is a candidate; fragments must not overlap.
+	size := min(opts.MaxWords, len(words))
+	hits := make([]int, len(words)-size+1)
+	for start := range hits {
+		for _, m := range matched[start : start+size] {
+			if m {
+				hits[start]++
+			}
+		}
+	}
+	var windows [][2]int
+	for _, start := range bestWindows(hits, len(hits)) {
+		if len(windows) == opts.fragments() {
+			break
+		}
+		overlaps := false
+		for _, window := range windows {
+			overlaps = overlaps || (start < window[1] && window[0] < start+size)
+		}
+		if !overlaps {
+			windows = append(windows, [2]int{start, start + size})
+		}
+	}
+	var snippets []string
+	for _, window := range windows {
+		if hits[window[0]] == 0 && len(snippets) > 0 {
+			break
+		}
+		marked := make([]string, 0, window[1]-window[0])
+		for j := window[0]; j < window[1]; j++ {
+			if matched[j] {
+				marked = append(marked, opts.StartSel+words[j]+opts.StopSel)
+				continue
+			}
+			marked = append(marked, words[j])
+		}
+		snippets = append(snippets, strings.Join(marked, " "))
+	}
+	return snippets
+}
+
+// EmbeddingSnippets splits content into windows of MaxWords words, embeds
+// each with embedder and returns the windows closest to vector by cosine
+// similarity, best first. It calls the embedder once per window, so use it on
+// the few results a page shows.
+func EmbeddingSnippets(ctx context.Context, embedder Embedder, content string, vector []float32, opts HighlightOptions) ([]string, error) {
+	opts = opts.WithDefaults()
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	windows := wordWindows(len(words), opts.MaxWords)
+	texts := make([]string, len(windows))
+	similarities := make([]float64, len(windows))
+	for i, window := range windows {
+		texts[i] = strings.Join(words[window[0]:window[1]], " ")
+		embedded, err := embedder.Embed(ctx, texts[i])
+		if err != nil {
+			return nil, fmt.Errorf("embed snippet: %w", err)
+		}
+		if len(embedded) != len(vector) {
+			return nil, fmt.Errorf("%w: snippet embedding has dimension %d, query has %d", ErrDimensionMismatch, len(embedded), len(vector))
+		}
+		similarities[i] = vectormath.CosineSimilarity(embedded, vector)
+	}
+	best := bestWindows(similarities, opts.fragments())
+	snippets := make([]string, len(best))
+	for i, window := range best {
+		snippets[i] = texts[window]
+	}
+	return snippets, nil
+}
+
+// wordWindows splits n words into consecutive [start, end) windows of size
+// words, the last one shorter.
+func wordWindows(n, size int) [][2]int {
+	var windows [][2]int
+	for start := 0; start < n; start += size {
+		windows = append(windows, [2]int{start, min(start+size, n)})
+	}
+	return windows
+}
+
+// bestWindows returns the indexes of the n highest scores, best first; ties
+// keep document order.
+func bestWindows[S int | float64](scores []S, n int) []int {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	return order[:min(n, len(order))]
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}))
+}
@@ -0,0 +1,77 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeywordSnippetsPicksWindowsWithMostMatches(t *testing.T) {
+	// Arrange
+	content := "Postgres stores rows. Vectors are indexed with HNSW. Search vectors, then rank: vectors win."
+
+	// Act
+	snippets := KeywordSnippets(content, "search VECTORS", HighlightOptions{MaxWords: 5, MaxFragments: 2})
+
+	// Assert
+	want := []string{"<b>Search</b> <b>vectors,</b> then rank: <b>vectors</b>", "Postgres stores rows. <b>Vectors</b> are"}
+	if !reflect.DeepEqual(snippets, want) {
+		t.Fatalf("expected %q, got %q", want, snippets)
+	}
+}
+
+func TestKeywordSnippetsWithoutMatchReturnsStart(t *testing.T) {
+	// Act
+	snippets := KeywordSnippets("one two three four", "missing", HighlightOptions{MaxWords: 2, MaxFragments: 3})
+
+	// Assert
+	if !reflect.DeepEqual(snippets, []string{"one two"}) {
+		t.Fatalf("expected the start of the content, got %q", snippets)
+	}
+}
+
+func TestEmbeddingSnippetsRanksWindowsBySimilarity(t *testing.T) {
+	// Arrange
+	embedder := EmbedderFunc(func(_ context.Context, text string) ([]float32, error) {
+		if strings.Contains(text, "cats") {
+			return []float32{1, 0}, nil
+		}
+		return []float32{0, 1}, nil
+	})
+
+	// Act
+	snippets, err := EmbeddingSnippets(context.Background(), embedder, "dogs bark loudly cats purr softly", []float32{2, 0}, HighlightOptions{MaxWords: 3})
+
+	// Assert
+	if err != nil || !reflect.DeepEqual(snippets, []string{"cats purr softly"}) {
+		t.Fatalf("expected the cats window, got %q (%v)", snippets, err)
+	}
+}
+
+func TestHighlightResultsSkipsResultsWithoutContent(t *testing.T) {
+	// Arrange
+	content := "vector search in Go"
+	results := []SearchResult{{Record: Record{ID: "a", Content: &content}}, {Record: Record{ID: "b"}}}
+
+	// Act
+	err := HighlightResults(results, func(content string) ([]string, error) {
+		return KeywordSnippets(content, "go", HighlightOptions{}), nil
+	})
+
+	// Assert
+	if err != nil || !reflect.DeepEqual(results[0].Snippets, []string{"vector search in <b>Go</b>"}) || results[1].Snippets != nil {
+		t.Fatalf("unexpected snippets %q, %q (%v)", results[0].Snippets, results[1].Snippets, err)
+	}
+}
+
+func TestHighlightOptionsValidate(t *testing.T) {
+	// Act
+	err := HighlightOptions{MaxWords: 1}.Validate()
+
+	// Assert
+	if !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package vectordata
+
+import (
+	"context"
+	"time"
+)
+
+// WriteEvent describes a successful Insert, Upsert or Delete seen by Hooks.
+type WriteEvent struct {
+	Operation  Operation
+	Collection string
+	// IDs are the IDs of the records written, or the IDs passed to Delete,
+	// whether or not they existed.
+	IDs []string
+	// Count is the number of records written, or deleted by Delete.
+	Count    int64
+	Duration time.Duration
+}
+
+// Hooks are callbacks run after successful mutations, e.g. to invalidate
+// application caches or record analytics. Nil hooks are skipped. Hooks run
+// synchronously on the writing goroutine once the write has returned, so
+// slow work belongs in a goroutine of its own; they cannot fail the write.
+type Hooks struct {
+	AfterInsert func(ctx context.Context, event WriteEvent)
+	AfterUpsert func(ctx context.Context, event WriteEvent)
+	AfterDelete func(ctx context.Context, event WriteEvent)
+}
+
+// WithHooks returns collection with hooks run after its successful writes.
+// Like any middleware-wrapped collection, it keeps TextSearcher and
+// HybridSearcher but not the other optional interfaces of collection.
+func WithHooks(collection Collection, hooks Hooks) Collection {
+	return WrapCollection(collection, hooks.Middleware())
+}
+
+// Middleware returns the hooks as middleware, to use in a chain or with
+// WrapStore.
+func (h Hooks) Middleware() CollectionMiddleware {
+	return func(ctx context.Context, call *Call, next CallHandler) error {
+		var hook func(context.Context, WriteEvent)
+		switch call.Operation {
+		case OpInsert:
+			hook = h.AfterInsert
+		case OpUpsert:
+			hook = h.AfterUpsert
+		case OpDelete:
+			hook = h.AfterDelete
+		}
+		if hook == nil {
+			return next(ctx, call)
+		}
+
+		started := time.Now()
+		if err := next(ctx, call); err != nil {
+			return err
+		}
+		event := WriteEvent{
+			Operation:  call.Operation,
+			Collection: call.Collection.Name(),
+			Count:      call.Count,
+			Duration:   time.Since(started),
+		}
+		if call.Operation == OpDelete {
+			event.IDs = append([]string(nil), call.IDs...)
+		} else {
+			event.IDs = make([]string, len(call.Records))
+			for i, record := range call.Records {
+				event.IDs[i] = record.ID
+			}
+			event.Count = int64(len(call.Records))
+		}
+		hook(ctx, event)
+		return nil
+	}
+}
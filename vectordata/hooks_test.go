@@ -0,0 +1,48 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type failingUpsertCollection struct {
+	mapCollection
+}
+
+func (c *failingUpsertCollection) Upsert(context.Context, []Record) error {
+	return ErrConflict
+}
+
+func TestWithHooksReportsSuccessfulWrites(t *testing.T) {
+	// Arrange
+	base := &failingUpsertCollection{mapCollection{records: map[string]Record{}}}
+	var events []WriteEvent
+	record := func(_ context.Context, event WriteEvent) { events = append(events, event) }
+	collection := WithHooks(base, Hooks{AfterInsert: record, AfterUpsert: record, AfterDelete: record})
+	ctx := context.Background()
+
+	// Act
+	insertErr := collection.Insert(ctx, []Record{{ID: "a"}, {ID: "b"}})
+	upsertErr := collection.Upsert(ctx, []Record{{ID: "c"}})
+	deleted, deleteErr := collection.Delete(ctx, []string{"a", "missing"})
+
+	// Assert
+	if insertErr != nil || deleteErr != nil || deleted != 1 {
+		t.Fatalf("write: %v, %d, %v", insertErr, deleted, deleteErr)
+	}
+	if !errors.Is(upsertErr, ErrConflict) {
+		t.Fatalf("expected the upsert error to pass through, got %v", upsertErr)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected events for the insert and delete only, got %+v", events)
+	}
+	insert, del := events[0], events[1]
+	if insert.Operation != OpInsert || insert.Collection != "docs" || insert.Count != 2 || !slices.Equal(insert.IDs, []string{"a", "b"}) {
+		t.Fatalf("unexpected insert event %+v", insert)
+	}
+	if del.Operation != OpDelete || del.Count != 1 || !slices.Equal(del.IDs, []string{"a", "missing"}) {
+		t.Fatalf("unexpected delete event %+v", del)
+	}
+}
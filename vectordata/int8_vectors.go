@@ -0,0 +1,58 @@
+package vectordata
+
+import "math/bits"
+
+// Float32VectorFromInt8 converts an int8 vector to the float32 vectors
+// backends index. The conversion is exact.
+func Float32VectorFromInt8(vector []int8) []float32 {
+	out := make([]float32, len(vector))
+	for i, component := range vector {
+		out[i] = float32(component)
+	}
+	return out
+}
+
+// Int8DotProduct returns the dot product of two int8 vectors of equal
+// length. It cannot overflow for vectors shorter than 2^17 components.
+func Int8DotProduct(a, b []int8) int32 {
+	var sum int32
+	for i := range a {
+		sum += int32(a[i]) * int32(b[i])
+	}
+	return sum
+}
+
+// HammingDistance returns the number of differing bits between two vectors
+// of equal length, such as binary-quantized embeddings packed into int8.
+func HammingDistance(a, b []int8) int {
+	var distance int
+	for i := range a {
+		distance += bits.OnesCount8(uint8(a[i] ^ b[i]))
+	}
+	return distance
+}
+
+// Int8Bytes returns vector as bytes in two's complement, the layout backends
+// store int8 vectors in.
+func Int8Bytes(vector []int8) []byte {
+	if vector == nil {
+		return nil
+	}
+	out := make([]byte, len(vector))
+	for i, component := range vector {
+		out[i] = byte(component)
+	}
+	return out
+}
+
+// Int8sFromBytes is the inverse of Int8Bytes.
+func Int8sFromBytes(raw []byte) []int8 {
+	if raw == nil {
+		return nil
+	}
+	out := make([]int8, len(raw))
+	for i, b := range raw {
+		out[i] = int8(b)
+	}
+	return out
+}
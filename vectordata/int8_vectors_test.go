@@ -0,0 +1,45 @@
+package vectordata
+
+import "testing"
+
+func TestInt8DotProduct(t *testing.T) {
+	// Act
+	got := Int8DotProduct([]int8{-128, 127, 3}, []int8{-128, 127, -2})
+
+	// Assert
+	if got != 16384+16129-6 {
+		t.Fatalf("unexpected dot product %d", got)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	// Act
+	got := HammingDistance([]int8{0, -1, 0b0101}, []int8{0, 0, 0b0011})
+
+	// Assert
+	if got != 10 {
+		t.Fatalf("expected 10 differing bits, got %d", got)
+	}
+}
+
+func TestInt8BytesRoundTrip(t *testing.T) {
+	// Arrange
+	vector := []int8{-128, -1, 0, 127}
+
+	// Act
+	raw := Int8Bytes(vector)
+	got := Int8sFromBytes(raw)
+
+	// Assert
+	if raw[0] != 0x80 || raw[1] != 0xff || raw[3] != 0x7f {
+		t.Fatalf("expected two's complement bytes, got %x", raw)
+	}
+	for i := range vector {
+		if got[i] != vector[i] {
+			t.Fatalf("expected %v, got %v", vector, got)
+		}
+	}
+	if Float32VectorFromInt8(vector)[0] != -128 {
+		t.Fatal("expected an exact float32 conversion")
+	}
+}
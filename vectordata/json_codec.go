@@ -0,0 +1,146 @@
+package vectordata
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONSchema validates JSON documents decoded into any by encoding/json.
+// Adapters for JSON Schema libraries implement it.
+type JSONSchema interface {
+	Validate(document any) error
+}
+
+// JSONSchemaFunc adapts a function to JSONSchema.
+type JSONSchemaFunc func(document any) error
+
+func (f JSONSchemaFunc) Validate(document any) error { return f(document) }
+
+// JSONCodecOptions configures a JSONCodec.
+type JSONCodecOptions struct {
+	// IDField is the JSON property holding the record ID (default "id").
+	IDField string
+	// VectorField, when set, is the JSON property holding the vector. It is
+	// moved to Record.Vector instead of being stored in Content.
+	VectorField string
+	// MetadataFields lists the top-level JSON properties copied into
+	// Metadata for filtering.
+	MetadataFields []string
+	// Schema, when set, validates documents on Encode and Decode.
+	Schema JSONSchema
+}
+
+// JSONCodec is a Codec storing values as JSON documents in Record.Content.
+// With a Schema, records holding documents of another shape fail to decode
+// instead of producing partially filled values, so document types can
+// share a collection.
+type JSONCodec[T any] struct {
+	opts JSONCodecOptions
+}
+
+// NewJSONCodec returns a JSONCodec for T, which must encode to a JSON
+// object.
+func NewJSONCodec[T any](opts JSONCodecOptions) *JSONCodec[T] {
+	if opts.IDField == "" {
+		opts.IDField = "id"
+	}
+	return &JSONCodec[T]{opts: opts}
+}
+
+// Encode stores value as a JSON document.
+func (c *JSONCodec[T]) Encode(value T) (Record, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return Record{}, fmt.Errorf("json codec: encode: %w", err)
+	}
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil || document == nil {
+		return Record{}, fmt.Errorf("json codec: %T does not encode to a JSON object", value)
+	}
+	id, _ := document[c.opts.IDField].(string)
+	if id == "" {
+		return Record{}, fmt.Errorf("%w: json codec: property %q is not a non-empty string", ErrInvalidRecord, c.opts.IDField)
+	}
+	if err := c.validate(id, document); err != nil {
+		return Record{}, err
+	}
+
+	record := Record{ID: id}
+	if c.opts.VectorField != "" {
+		if raw, ok := document[c.opts.VectorField]; ok && raw != nil {
+			vector, err := jsonVector(raw)
+			if err != nil {
+				return Record{}, fmt.Errorf("%w: json codec: record %q: property %q: %v", ErrInvalidRecord, id, c.opts.VectorField, err)
+			}
+			record.Vector = vector
+		}
+		delete(document, c.opts.VectorField)
+		if raw, err = json.Marshal(document); err != nil {
+			return Record{}, fmt.Errorf("json codec: encode: %w", err)
+		}
+	}
+	for _, field := range c.opts.MetadataFields {
+		if value, ok := document[field]; ok {
+			if record.Metadata == nil {
+				record.Metadata = make(map[string]any, len(c.opts.MetadataFields))
+			}
+			record.Metadata[field] = value
+		}
+	}
+	content := string(raw)
+	record.Content = &content
+	return record, nil
+}
+
+// Decode parses the JSON document in record.Content.
+func (c *JSONCodec[T]) Decode(record Record) (T, error) {
+	var out T
+	if record.Content == nil {
+		return out, fmt.Errorf("%w: json codec: record %q has no content", ErrInvalidRecord, record.ID)
+	}
+	var document map[string]any
+	if err := json.Unmarshal([]byte(*record.Content), &document); err != nil || document == nil {
+		return out, fmt.Errorf("%w: json codec: record %q does not hold a JSON object", ErrInvalidRecord, record.ID)
+	}
+	if c.opts.VectorField != "" && record.Vector != nil {
+		document[c.opts.VectorField] = record.Vector
+	}
+	if err := c.validate(record.ID, document); err != nil {
+		return out, err
+	}
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return out, fmt.Errorf("json codec: record %q: %w", record.ID, err)
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, fmt.Errorf("%w: json codec: record %q: %v", ErrInvalidRecord, record.ID, err)
+	}
+	return out, nil
+}
+
+func (c *JSONCodec[T]) validate(id string, document map[string]any) error {
+	if c.opts.Schema == nil {
+		return nil
+	}
+	if err := c.opts.Schema.Validate(document); err != nil {
+		return fmt.Errorf("%w: json codec: record %q fails schema validation: %w", ErrInvalidRecord, id, err)
+	}
+	return nil
+}
+
+// jsonVector converts a decoded JSON array of numbers to a vector.
+func jsonVector(raw any) ([]float32, error) {
+	values, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array of numbers, got %T", raw)
+	}
+	vector := make([]float32, len(values))
+	for i, value := range values {
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("component %d is %T, not a number", i, value)
+		}
+		vector[i] = float32(number)
+	}
+	return vector, nil
+}
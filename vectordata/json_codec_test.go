@@ -0,0 +1,68 @@
+package vectordata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type jsonArticle struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Tag       string    `json:"tag"`
+	Embedding []float32 `json:"embedding,omitempty"`
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	// Arrange
+	codec := NewJSONCodec[jsonArticle](JSONCodecOptions{VectorField: "embedding", MetadataFields: []string{"tag"}})
+	article := jsonArticle{ID: "a", Title: "Hello", Tag: "news", Embedding: []float32{0.5, 1}}
+
+	// Act
+	record, encodeErr := codec.Encode(article)
+	decoded, decodeErr := codec.Decode(record)
+
+	// Assert
+	if encodeErr != nil || decodeErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", encodeErr, decodeErr)
+	}
+	if record.ID != "a" || record.Metadata["tag"] != "news" || len(record.Vector) != 2 || strings.Contains(*record.Content, "embedding") {
+		t.Fatalf("unexpected record %+v with content %s", record, *record.Content)
+	}
+	if decoded.Title != "Hello" || len(decoded.Embedding) != 2 || decoded.Embedding[0] != 0.5 {
+		t.Fatalf("unexpected decoded value %+v", decoded)
+	}
+}
+
+func TestJSONCodecSchemaRejectsOtherDocuments(t *testing.T) {
+	// Arrange
+	requireTitle := JSONSchemaFunc(func(document any) error {
+		if _, ok := document.(map[string]any)["title"].(string); !ok {
+			return errors.New("title is required")
+		}
+		return nil
+	})
+	codec := NewJSONCodec[jsonArticle](JSONCodecOptions{Schema: requireTitle})
+	content := `{"id":"b","sku":"x-1"}`
+
+	// Act
+	_, err := codec.Decode(Record{ID: "b", Content: &content})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidRecord) || !strings.Contains(err.Error(), "title is required") {
+		t.Fatalf("expected a schema validation error, got %v", err)
+	}
+}
+
+func TestJSONCodecRequiresID(t *testing.T) {
+	// Arrange
+	codec := NewJSONCodec[jsonArticle](JSONCodecOptions{})
+
+	// Act
+	_, err := codec.Encode(jsonArticle{Title: "untitled"})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+}
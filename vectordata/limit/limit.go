@@ -0,0 +1,205 @@
+// Package limit bounds the load vectordata collections put on a backend.
+//
+// A Limiter enforces, per collection name, a concurrency limit and a token
+// bucket QPS limit, separately for reads (Get, Count, searches) and writes
+// (Insert, Upsert, Delete, EnsureIndexes):
+//
+//	limiter := limit.New(limit.Options{
+//		Reads:  limit.Limits{MaxConcurrent: 16},
+//		Writes: limit.Limits{MaxConcurrent: 4, QPS: 50},
+//	})
+//	store = vectordata.WrapStore(store, limiter.Middleware())
+//
+// Calls over a limit wait for their turn until their context is done, or
+// fail with ErrLimited at once when FailFast is set.
+package limit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// ErrLimited is returned for calls rejected by a FailFast limiter.
+var ErrLimited = errors.New("limit: rate or concurrency limit exceeded")
+
+// Limits bounds one kind of call to a collection. Zero or negative fields
+// are unlimited.
+type Limits struct {
+	// MaxConcurrent is the number of calls in flight at once.
+	MaxConcurrent int
+	// QPS is the sustained number of calls started per second.
+	QPS float64
+	// Burst is the number of calls that may start at once above QPS
+	// (default QPS rounded up, at least 1).
+	Burst int
+}
+
+// Options configures a Limiter.
+type Options struct {
+	Reads  Limits
+	Writes Limits
+	// FailFast rejects calls over a limit with ErrLimited instead of waiting.
+	FailFast bool
+}
+
+// Limiter holds the limits of a store's collections. It is safe for
+// concurrent use.
+type Limiter struct {
+	opts Options
+	now  func() time.Time
+
+	mu          sync.Mutex
+	collections map[string]*collectionLimits
+}
+
+type collectionLimits struct {
+	reads  *lane
+	writes *lane
+}
+
+// New returns a limiter applying opts to every collection.
+func New(opts Options) *Limiter {
+	return &Limiter{opts: opts, now: time.Now, collections: make(map[string]*collectionLimits)}
+}
+
+// Collection returns base behind a limiter of its own.
+func Collection(base vectordata.Collection, opts Options) vectordata.Collection {
+	return vectordata.WrapCollection(base, New(opts).Middleware())
+}
+
+// Middleware returns the collection middleware enforcing the limits.
+func (l *Limiter) Middleware() vectordata.CollectionMiddleware {
+	return l.middleware
+}
+
+func (l *Limiter) middleware(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+	limits := l.limitsFor(call.Collection.Name())
+	lane := limits.writes
+	if isRead(call.Operation) {
+		lane = limits.reads
+	}
+
+	release, err := lane.acquire(ctx, l.opts.FailFast)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return next(ctx, call)
+}
+
+func isRead(op vectordata.Operation) bool {
+	switch op {
+	case vectordata.OpGet, vectordata.OpCount, vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Limiter) limitsFor(name string) *collectionLimits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limits, ok := l.collections[name]
+	if !ok {
+		limits = &collectionLimits{reads: newLane(l.opts.Reads, l.now), writes: newLane(l.opts.Writes, l.now)}
+		l.collections[name] = limits
+	}
+	return limits
+}
+
+// lane enforces one Limits.
+type lane struct {
+	slots  chan struct{} // nil when concurrency is unlimited
+	bucket *tokenBucket  // nil when QPS is unlimited
+}
+
+func newLane(limits Limits, now func() time.Time) *lane {
+	l := &lane{}
+	if limits.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	if limits.QPS > 0 {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(limits.QPS)))
+		}
+		l.bucket = &tokenBucket{rate: limits.QPS, burst: float64(burst), tokens: float64(burst), last: now(), now: now}
+	}
+	return l
+}
+
+// acquire takes a token and a slot, returning the function releasing the
+// slot.
+func (l *lane) acquire(ctx context.Context, failFast bool) (func(), error) {
+	if l.bucket != nil {
+		if err := l.bucket.take(ctx, failFast); err != nil {
+			return nil, err
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	if failFast {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			return nil, fmt.Errorf("%w: %d calls in flight", ErrLimited, cap(l.slots))
+		}
+	} else {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return func() { <-l.slots }, nil
+}
+
+// tokenBucket refills at rate tokens per second up to burst. Waiting callers
+// reserve a token ahead of time, driving tokens negative.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(ctx context.Context, failFast bool) error {
+	b.mu.Lock()
+	now := b.now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.mu.Unlock()
+		return nil
+	}
+	if failFast {
+		b.mu.Unlock()
+		return fmt.Errorf("%w: over %g calls per second", ErrLimited, b.rate)
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	b.tokens--
+	b.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
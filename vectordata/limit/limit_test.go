@@ -0,0 +1,150 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// blockingCollection holds calls until release is closed.
+type blockingCollection struct {
+	vectordata.Collection
+	release chan struct{}
+	started chan struct{}
+}
+
+func newBlockingCollection() *blockingCollection {
+	return &blockingCollection{release: make(chan struct{}), started: make(chan struct{}, 16)}
+}
+
+func (c *blockingCollection) Name() string { return "docs" }
+
+func (c *blockingCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return 1, nil
+}
+
+func (c *blockingCollection) Upsert(context.Context, []vectordata.Record) error {
+	return nil
+}
+
+func TestLimiter_FailFastConcurrency(t *testing.T) {
+	// Arrange
+	base := newBlockingCollection()
+	collection := Collection(base, Options{Reads: Limits{MaxConcurrent: 1}, FailFast: true})
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = collection.Count(ctx, nil)
+	}()
+	<-base.started
+
+	// Act
+	_, readErr := collection.Count(ctx, nil)
+	writeErr := collection.Upsert(ctx, []vectordata.Record{{ID: "r1"}})
+	close(base.release)
+	wg.Wait()
+	_, afterErr := collection.Count(ctx, nil)
+
+	// Assert
+	if !errors.Is(readErr, ErrLimited) {
+		t.Fatalf("expected ErrLimited while the slot is taken, got %v", readErr)
+	}
+	if writeErr != nil || afterErr != nil {
+		t.Fatalf("expected writes and later reads to proceed, got %v and %v", writeErr, afterErr)
+	}
+}
+
+func TestLimiter_BlockingConcurrencyHonorsContext(t *testing.T) {
+	// Arrange
+	base := newBlockingCollection()
+	collection := Collection(base, Options{Reads: Limits{MaxConcurrent: 1}})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = collection.Count(context.Background(), nil)
+	}()
+	<-base.started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := collection.Count(ctx, nil)
+	close(base.release)
+	wg.Wait()
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the waiting call to give up with its context, got %v", err)
+	}
+}
+
+func TestTokenBucket_FailFast(t *testing.T) {
+	// Arrange
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	l := newLane(Limits{QPS: 2}, clock)
+	ctx := context.Background()
+
+	// Act
+	first := l.bucket.take(ctx, true)
+	second := l.bucket.take(ctx, true)
+	third := l.bucket.take(ctx, true)
+	now = now.Add(500 * time.Millisecond)
+	refilled := l.bucket.take(ctx, true)
+
+	// Assert
+	if first != nil || second != nil {
+		t.Fatalf("expected the burst to pass, got %v and %v", first, second)
+	}
+	if !errors.Is(third, ErrLimited) {
+		t.Fatalf("expected ErrLimited once the burst is spent, got %v", third)
+	}
+	if refilled != nil {
+		t.Fatalf("expected a token after half a second at 2 QPS, got %v", refilled)
+	}
+}
+
+func TestTokenBucket_BlockingWaitsForToken(t *testing.T) {
+	// Arrange
+	l := newLane(Limits{QPS: 50, Burst: 1}, time.Now)
+	ctx := context.Background()
+	_ = l.bucket.take(ctx, false)
+
+	// Act
+	started := time.Now()
+	err := l.bucket.take(ctx, false)
+	elapsed := time.Since(started)
+
+	// Assert
+	if err != nil || elapsed < 10*time.Millisecond {
+		t.Fatalf("expected to wait about 20ms for a token, got %v after %v", err, elapsed)
+	}
+}
+
+func TestTokenBucket_CanceledWaitReturnsToken(t *testing.T) {
+	// Arrange
+	now := time.Unix(0, 0)
+	l := newLane(Limits{QPS: 1, Burst: 1}, func() time.Time { return now })
+	_ = l.bucket.take(context.Background(), false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := l.bucket.take(ctx, false)
+	now = now.Add(time.Second)
+	next := l.bucket.take(context.Background(), true)
+
+	// Assert
+	if !errors.Is(err, context.Canceled) || next != nil {
+		t.Fatalf("expected the canceled reservation to be returned, got %v and %v", err, next)
+	}
+}
@@ -0,0 +1,54 @@
+package vectordata
+
+import (
+	"context"
+	"maps"
+)
+
+// MetadataPatcher is implemented by collections that apply metadata merge
+// patches atomically on the server.
+type MetadataPatcher interface {
+	PatchMetadata(ctx context.Context, id string, patch map[string]any) error
+}
+
+// PatchMetadata applies patch to the metadata of record id with JSON merge
+// patch (RFC 7386) semantics: keys set to nil are removed, nested objects
+// are patched recursively and other values replace the existing ones.
+// Writers patching different keys therefore do not overwrite each other.
+// It fails with ErrNotFound when the record does not exist.
+//
+// MetadataPatchers, such as the Postgres and FAISS stores, patch atomically.
+// Other collections Get the record, patch it in Go and Upsert it, so a
+// concurrent write between the two can be lost.
+func PatchMetadata(ctx context.Context, collection Collection, id string, patch map[string]any) error {
+	if patcher, ok := collection.(MetadataPatcher); ok {
+		return patcher.PatchMetadata(ctx, id, patch)
+	}
+	record, err := collection.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	record.Metadata = MergePatch(record.Metadata, patch)
+	return collection.Upsert(ctx, []Record{record})
+}
+
+// MergePatch returns target with patch applied as a JSON merge patch
+// (RFC 7386). Neither argument is modified.
+func MergePatch(target, patch map[string]any) map[string]any {
+	out := maps.Clone(target)
+	if out == nil {
+		out = make(map[string]any, len(patch))
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(out, key)
+		case map[string]any:
+			existing, _ := out[key].(map[string]any)
+			out[key] = MergePatch(existing, value)
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatchFollowsRFC7386(t *testing.T) {
+	// Arrange
+	target := map[string]any{
+		"title":  "Goodbye!",
+		"author": map[string]any{"givenName": "John", "familyName": "Doe"},
+		"tags":   []any{"example", "sample"},
+		"phone":  "+01-123-456-7890",
+	}
+	patch := map[string]any{
+		"title":       "Hello!",
+		"phoneNumber": "+01-987-654-3210",
+		"author":      map[string]any{"familyName": nil},
+		"tags":        []any{"example"},
+		"phone":       nil,
+		"rank":        map[string]any{"value": 1},
+	}
+
+	// Act
+	patched := MergePatch(target, patch)
+
+	// Assert
+	expected := map[string]any{
+		"title":       "Hello!",
+		"author":      map[string]any{"givenName": "John"},
+		"tags":        []any{"example"},
+		"phoneNumber": "+01-987-654-3210",
+		"rank":        map[string]any{"value": 1},
+	}
+	if !reflect.DeepEqual(patched, expected) {
+		t.Fatalf("unexpected result\nwant: %v\n got: %v", expected, patched)
+	}
+	if target["phone"] == nil || target["title"] != "Goodbye!" {
+		t.Fatalf("expected the target to be left alone, got %v", target)
+	}
+}
+
+func TestPatchMetadataFallsBackToGetAndUpsert(t *testing.T) {
+	// Arrange
+	collection := &mapCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{1}, Metadata: map[string]any{"lang": "en", "draft": true}},
+	}}
+
+	// Act
+	err := PatchMetadata(context.Background(), collection, "a", map[string]any{"draft": nil, "rank": 2})
+	missingErr := PatchMetadata(context.Background(), collection, "missing", map[string]any{"rank": 2})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PatchMetadata: %v", err)
+	}
+	if !reflect.DeepEqual(collection.records["a"].Metadata, map[string]any{"lang": "en", "rank": 2}) || collection.records["a"].Vector[0] != 1 {
+		t.Fatalf("unexpected record %+v", collection.records["a"])
+	}
+	if !errors.Is(missingErr, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", missingErr)
+	}
+}
@@ -0,0 +1,255 @@
+package vectordata
+
+import "context"
+
+// Operation names a collection method seen by middleware.
+type Operation string
+
+const (
+	OpInsert         Operation = "Insert"
+	OpUpsert         Operation = "Upsert"
+	OpGet            Operation = "Get"
+	OpDelete         Operation = "Delete"
+	OpCount          Operation = "Count"
+	OpSearchByVector Operation = "SearchByVector"
+	OpSearchByText   Operation = "SearchByText"
+	OpHybridSearch   Operation = "HybridSearch"
+	OpEnsureIndexes  Operation = "EnsureIndexes"
+)
+
+// Call carries the arguments and results of one collection method call
+// through a middleware chain. Only the fields of its Operation are used.
+// Middleware may rewrite arguments before calling next, and results after.
+type Call struct {
+	Operation  Operation
+	Collection Collection
+
+	// Arguments.
+	Records []Record // Insert, Upsert
+	ID      string   // Get
+	IDs     []string // Delete
+	Filter  Filter   // Count; searches carry theirs in their options
+	Vector  []float32
+	Text    string
+	TopK    int
+
+	SearchOptions       SearchOptions
+	TextSearchOptions   TextSearchOptions
+	HybridSearchOptions HybridSearchOptions
+	IndexOptions        IndexOptions
+
+	// Results.
+	Record  Record         // Get
+	Results []SearchResult // searches
+	// Count is the number of records deleted by Delete or counted by Count.
+	Count int64
+}
+
+// ActiveFilter returns the filter of a Count or search call.
+func (c *Call) ActiveFilter() Filter {
+	switch c.Operation {
+	case OpSearchByVector:
+		return c.SearchOptions.Filter
+	case OpSearchByText:
+		return c.TextSearchOptions.Filter
+	case OpHybridSearch:
+		return c.HybridSearchOptions.Filter
+	default:
+		return c.Filter
+	}
+}
+
+// CallHandler performs a call, filling its results.
+type CallHandler func(ctx context.Context, call *Call) error
+
+// CollectionMiddleware runs around a collection call. It calls next to
+// continue the chain, or returns without calling it to short-circuit (e.g. a
+// cache hit), in which case it fills the results itself.
+type CollectionMiddleware func(ctx context.Context, call *Call, next CallHandler) error
+
+// WrapCollection returns base with middlewares applied to every call except
+// Name, Dimension, Metric and the capability methods. The first middleware is
+// the outermost. The result implements TextSearcher and HybridSearcher exactly
+// when base does, and reports the capabilities of base.
+func WrapCollection(base Collection, middlewares ...CollectionMiddleware) Collection {
+	if len(middlewares) == 0 {
+		return base
+	}
+
+	c := &wrappedCollection{base: base, handler: chainHandler(base, middlewares)}
+	_, isText := base.(TextSearcher)
+	_, isHybrid := base.(HybridSearcher)
+	switch {
+	case isText && isHybrid:
+		return struct {
+			*wrappedCollection
+			wrappedTextSearch
+			wrappedHybridSearch
+		}{c, wrappedTextSearch{c}, wrappedHybridSearch{c}}
+	case isText:
+		return struct {
+			*wrappedCollection
+			wrappedTextSearch
+		}{c, wrappedTextSearch{c}}
+	case isHybrid:
+		return struct {
+			*wrappedCollection
+			wrappedHybridSearch
+		}{c, wrappedHybridSearch{c}}
+	default:
+		return c
+	}
+}
+
+// WrapStore returns store with middlewares applied to every collection it
+// returns.
+func WrapStore(store VectorStore, middlewares ...CollectionMiddleware) VectorStore {
+	if len(middlewares) == 0 {
+		return store
+	}
+	return &wrappedStore{base: store, middlewares: middlewares}
+}
+
+type wrappedStore struct {
+	base        VectorStore
+	middlewares []CollectionMiddleware
+}
+
+func (s *wrappedStore) EnsureCollection(ctx context.Context, spec CollectionSpec) (Collection, error) {
+	collection, err := s.base.EnsureCollection(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return WrapCollection(collection, s.middlewares...), nil
+}
+
+func (s *wrappedStore) Collection(name string, dimension int, metric DistanceMetric) Collection {
+	return WrapCollection(s.base.Collection(name, dimension, metric), s.middlewares...)
+}
+
+// Capabilities reports those of the wrapped store.
+func (s *wrappedStore) Capabilities() Capabilities {
+	capabilities, _ := CapabilitiesOf(s.base)
+	return capabilities
+}
+
+func chainHandler(base Collection, middlewares []CollectionMiddleware) CallHandler {
+	handler := invokeCollection(base)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(ctx context.Context, call *Call) error {
+			return middleware(ctx, call, next)
+		}
+	}
+	return handler
+}
+
+// invokeCollection is the innermost handler: it calls base.
+func invokeCollection(base Collection) CallHandler {
+	return func(ctx context.Context, call *Call) error {
+		var err error
+		switch call.Operation {
+		case OpInsert:
+			err = base.Insert(ctx, call.Records)
+		case OpUpsert:
+			err = base.Upsert(ctx, call.Records)
+		case OpGet:
+			call.Record, err = base.Get(ctx, call.ID)
+		case OpDelete:
+			call.Count, err = base.Delete(ctx, call.IDs)
+		case OpCount:
+			call.Count, err = base.Count(ctx, call.Filter)
+		case OpSearchByVector:
+			call.Results, err = base.SearchByVector(ctx, call.Vector, call.TopK, call.SearchOptions)
+		case OpSearchByText:
+			call.Results, err = base.(TextSearcher).SearchByText(ctx, call.Text, call.TopK, call.TextSearchOptions)
+		case OpHybridSearch:
+			call.Results, err = base.(HybridSearcher).HybridSearch(ctx, call.Vector, call.Text, call.TopK, call.HybridSearchOptions)
+		case OpEnsureIndexes:
+			err = base.EnsureIndexes(ctx, call.IndexOptions)
+		}
+		return err
+	}
+}
+
+type wrappedCollection struct {
+	base    Collection
+	handler CallHandler
+}
+
+func (c *wrappedCollection) call(ctx context.Context, call *Call) error {
+	call.Collection = c.base
+	return c.handler(ctx, call)
+}
+
+func (c *wrappedCollection) Name() string           { return c.base.Name() }
+func (c *wrappedCollection) Dimension() int         { return c.base.Dimension() }
+func (c *wrappedCollection) Metric() DistanceMetric { return c.base.Metric() }
+
+// Capabilities and SupportsFilter report those of base.
+func (c *wrappedCollection) Capabilities() Capabilities {
+	capabilities, _ := CapabilitiesOf(c.base)
+	return capabilities
+}
+
+func (c *wrappedCollection) SupportsFilter(filter Filter) error {
+	return SupportsFilter(c.base, filter)
+}
+
+func (c *wrappedCollection) Insert(ctx context.Context, records []Record) error {
+	return c.call(ctx, &Call{Operation: OpInsert, Records: records})
+}
+
+func (c *wrappedCollection) Upsert(ctx context.Context, records []Record) error {
+	return c.call(ctx, &Call{Operation: OpUpsert, Records: records})
+}
+
+func (c *wrappedCollection) Get(ctx context.Context, id string) (Record, error) {
+	call := &Call{Operation: OpGet, ID: id}
+	err := c.call(ctx, call)
+	return call.Record, err
+}
+
+func (c *wrappedCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	call := &Call{Operation: OpDelete, IDs: ids}
+	err := c.call(ctx, call)
+	return call.Count, err
+}
+
+func (c *wrappedCollection) Count(ctx context.Context, filter Filter) (int64, error) {
+	call := &Call{Operation: OpCount, Filter: filter}
+	err := c.call(ctx, call)
+	return call.Count, err
+}
+
+func (c *wrappedCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	call := &Call{Operation: OpSearchByVector, Vector: vector, TopK: topK, SearchOptions: opts}
+	err := c.call(ctx, call)
+	return call.Results, err
+}
+
+func (c *wrappedCollection) EnsureIndexes(ctx context.Context, opts IndexOptions) error {
+	return c.call(ctx, &Call{Operation: OpEnsureIndexes, IndexOptions: opts})
+}
+
+// wrappedTextSearch and wrappedHybridSearch add the optional searches to a
+// wrapped collection whose base implements them.
+type wrappedTextSearch struct {
+	c *wrappedCollection
+}
+
+func (t wrappedTextSearch) SearchByText(ctx context.Context, text string, topK int, opts TextSearchOptions) ([]SearchResult, error) {
+	call := &Call{Operation: OpSearchByText, Text: text, TopK: topK, TextSearchOptions: opts}
+	err := t.c.call(ctx, call)
+	return call.Results, err
+}
+
+type wrappedHybridSearch struct {
+	c *wrappedCollection
+}
+
+func (h wrappedHybridSearch) HybridSearch(ctx context.Context, vector []float32, text string, topK int, opts HybridSearchOptions) ([]SearchResult, error) {
+	call := &Call{Operation: OpHybridSearch, Vector: vector, Text: text, TopK: topK, HybridSearchOptions: opts}
+	err := h.c.call(ctx, call)
+	return call.Results, err
+}
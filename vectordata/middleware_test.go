@@ -0,0 +1,155 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type stubCollection struct {
+	Collection
+	searches int
+	gets     []string
+	results  []SearchResult
+}
+
+func (s *stubCollection) Name() string { return "docs" }
+
+func (s *stubCollection) Get(_ context.Context, id string) (Record, error) {
+	s.gets = append(s.gets, id)
+	if id == "missing" {
+		return Record{}, ErrNotFound
+	}
+	return Record{ID: id}, nil
+}
+
+func (s *stubCollection) SearchByVector(context.Context, []float32, int, SearchOptions) ([]SearchResult, error) {
+	s.searches++
+	return s.results, nil
+}
+
+type stubTextCollection struct {
+	*stubCollection
+}
+
+func (s stubTextCollection) SearchByText(_ context.Context, text string, _ int, _ TextSearchOptions) ([]SearchResult, error) {
+	return []SearchResult{{Record: Record{ID: text}}}, nil
+}
+
+func TestWrapCollection_RunsMiddlewaresOutermostFirst(t *testing.T) {
+	// Arrange
+	var trace []string
+	record := func(name string) CollectionMiddleware {
+		return func(ctx context.Context, call *Call, next CallHandler) error {
+			trace = append(trace, name+" before "+string(call.Operation))
+			err := next(ctx, call)
+			trace = append(trace, name+" after "+call.Record.ID)
+			return err
+		}
+	}
+	base := &stubCollection{}
+	collection := WrapCollection(base, record("outer"), record("inner"))
+
+	// Act
+	got, err := collection.Get(context.Background(), "r1")
+
+	// Assert
+	if err != nil || got.ID != "r1" {
+		t.Fatalf("expected record r1, got %+v (%v)", got, err)
+	}
+	expected := []string{"outer before Get", "inner before Get", "inner after r1", "outer after r1"}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Fatalf("expected %v, got %v", expected, trace)
+	}
+}
+
+func TestWrapCollection_MiddlewareCanRewriteAndShortCircuit(t *testing.T) {
+	// Arrange
+	base := &stubCollection{results: []SearchResult{{Record: Record{ID: "from-base"}}}}
+	rewrite := func(ctx context.Context, call *Call, next CallHandler) error {
+		if call.Operation == OpGet {
+			call.ID = "rewritten"
+		}
+		return next(ctx, call)
+	}
+	cached := func(ctx context.Context, call *Call, next CallHandler) error {
+		if call.Operation == OpSearchByVector && call.TopK == 1 {
+			call.Results = []SearchResult{{Record: Record{ID: "from-cache"}}}
+			return nil
+		}
+		return next(ctx, call)
+	}
+	collection := WrapCollection(base, rewrite, cached)
+
+	// Act
+	got, getErr := collection.Get(context.Background(), "original")
+	hit, hitErr := collection.SearchByVector(context.Background(), []float32{1}, 1, SearchOptions{})
+	miss, missErr := collection.SearchByVector(context.Background(), []float32{1}, 2, SearchOptions{})
+	_, notFoundErr := WrapCollection(base, cached).Get(context.Background(), "missing")
+
+	// Assert
+	if getErr != nil || got.ID != "rewritten" || !reflect.DeepEqual(base.gets, []string{"rewritten", "missing"}) {
+		t.Fatalf("expected the rewritten ID to reach the base, got %+v and %v", got, base.gets)
+	}
+	if hitErr != nil || len(hit) != 1 || hit[0].Record.ID != "from-cache" {
+		t.Fatalf("expected the cached result, got %+v (%v)", hit, hitErr)
+	}
+	if missErr != nil || len(miss) != 1 || miss[0].Record.ID != "from-base" || base.searches != 1 {
+		t.Fatalf("expected one search to reach the base, got %+v after %d searches", miss, base.searches)
+	}
+	if !errors.Is(notFoundErr, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound through the chain, got %v", notFoundErr)
+	}
+}
+
+func TestWrapCollection_PreservesOptionalInterfaces(t *testing.T) {
+	// Arrange
+	var operations []Operation
+	observe := func(ctx context.Context, call *Call, next CallHandler) error {
+		operations = append(operations, call.Operation)
+		return next(ctx, call)
+	}
+
+	// Act
+	plain := WrapCollection(&stubCollection{}, observe)
+	text := WrapCollection(stubTextCollection{&stubCollection{}}, observe)
+	unwrapped := WrapCollection(&stubCollection{})
+
+	// Assert
+	if _, ok := plain.(TextSearcher); ok {
+		t.Fatal("expected no text search on a plain collection")
+	}
+	if _, ok := text.(HybridSearcher); ok {
+		t.Fatal("expected no hybrid search on a text-only collection")
+	}
+	searcher, ok := text.(TextSearcher)
+	if !ok {
+		t.Fatal("expected text search to be preserved")
+	}
+	results, err := searcher.SearchByText(context.Background(), "query", 1, TextSearchOptions{})
+	if err != nil || len(results) != 1 || results[0].Record.ID != "query" {
+		t.Fatalf("unexpected text results %+v (%v)", results, err)
+	}
+	if !reflect.DeepEqual(operations, []Operation{OpSearchByText}) {
+		t.Fatalf("expected the middleware to see SearchByText, got %v", operations)
+	}
+	if _, ok := unwrapped.(*stubCollection); !ok {
+		t.Fatal("expected no wrapper without middleware")
+	}
+}
+
+func TestCall_ActiveFilter(t *testing.T) {
+	// Arrange
+	count := Eq(Column("id"), "a")
+	search := Eq(Column("id"), "b")
+
+	// Act
+	countFilter := (&Call{Operation: OpCount, Filter: count}).ActiveFilter()
+	searchFilter := (&Call{Operation: OpSearchByVector, SearchOptions: SearchOptions{Filter: search}}).ActiveFilter()
+
+	// Assert
+	if !reflect.DeepEqual(countFilter, count) || !reflect.DeepEqual(searchFilter, search) {
+		t.Fatalf("unexpected filters %v and %v", countFilter, searchFilter)
+	}
+}
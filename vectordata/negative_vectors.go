@@ -0,0 +1,43 @@
+package vectordata
+
+import "fmt"
+
+// NegativeVector is an example the results of SearchByVector should move
+// away from, as in "more like this, less like that".
+type NegativeVector struct {
+	Vector []float32
+	// Weight scales how far the query moves away from Vector (default 1).
+	Weight float64
+}
+
+// AdjustQueryVector returns vector minus the weighted sum of negatives, the
+// query SearchByVector runs when SearchOptions.Negatives is set. Without
+// negatives, vector is returned as is; otherwise the result is a new slice.
+// Negatives must have the dimension of vector and non-negative weights. For
+// cosine and normalized inner product collections, pass unit-length vectors
+// so weights are relative to the query.
+func AdjustQueryVector(vector []float32, negatives []NegativeVector) ([]float32, error) {
+	if len(negatives) == 0 {
+		return vector, nil
+	}
+	out := make([]float64, len(vector))
+	for i, component := range vector {
+		out[i] = float64(component)
+	}
+	for i, negative := range negatives {
+		if len(negative.Vector) != len(vector) {
+			return nil, fmt.Errorf("%w: negative vector %d has dimension %d, query has %d", ErrDimensionMismatch, i, len(negative.Vector), len(vector))
+		}
+		weight := negative.Weight
+		switch {
+		case weight < 0:
+			return nil, fmt.Errorf("%w: negative vector %d has weight %v, must be >= 0", ErrSchemaMismatch, i, weight)
+		case weight == 0:
+			weight = 1
+		}
+		for j, component := range negative.Vector {
+			out[j] -= weight * float64(component)
+		}
+	}
+	return Float32Vector(out), nil
+}
@@ -0,0 +1,51 @@
+package vectordata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAdjustQueryVectorSubtractsWeightedNegatives(t *testing.T) {
+	// Arrange
+	query := []float32{1, 1}
+	negatives := []NegativeVector{{Vector: []float32{0, 1}}, {Vector: []float32{1, 0}, Weight: 0.5}}
+
+	// Act
+	got, err := AdjustQueryVector(query, negatives)
+
+	// Assert
+	if err != nil || !reflect.DeepEqual(got, []float32{0.5, 0}) {
+		t.Fatalf("expected [0.5 0], got %v (%v)", got, err)
+	}
+	if query[0] != 1 || query[1] != 1 {
+		t.Fatalf("expected the query to be left unchanged, got %v", query)
+	}
+}
+
+func TestAdjustQueryVectorWithoutNegatives(t *testing.T) {
+	// Arrange
+	query := []float32{1, 2}
+
+	// Act
+	got, err := AdjustQueryVector(query, nil)
+
+	// Assert
+	if err != nil || &got[0] != &query[0] {
+		t.Fatalf("expected the query itself, got %v (%v)", got, err)
+	}
+}
+
+func TestAdjustQueryVectorValidation(t *testing.T) {
+	// Act
+	_, dimErr := AdjustQueryVector([]float32{1, 0}, []NegativeVector{{Vector: []float32{1}}})
+	_, weightErr := AdjustQueryVector([]float32{1, 0}, []NegativeVector{{Vector: []float32{0, 1}, Weight: -1}})
+
+	// Assert
+	if !errors.Is(dimErr, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", dimErr)
+	}
+	if !errors.Is(weightErr, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", weightErr)
+	}
+}
@@ -0,0 +1,121 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// neighborSearches bounds the concurrent searches of SearchNeighbors.
+const neighborSearches = 8
+
+// NeighborsFor returns the topK nearest neighbors of every stored record in
+// ids, keyed by ID. A record is never its own neighbor, and IDs that do not
+// exist are left out of the map. opts applies to every search as it would
+// to SearchByVector, except that Queries must be empty: each search starts
+// from the stored vector of its record.
+//
+// NeighborFinders, such as the Postgres store, answer in one query. Other
+// collections are searched once per record by SearchNeighbors.
+func NeighborsFor(ctx context.Context, collection Collection, ids []string, topK int, opts SearchOptions) (map[string][]SearchResult, error) {
+	if finder, ok := collection.(NeighborFinder); ok {
+		return finder.NeighborsFor(ctx, ids, topK, opts)
+	}
+	return SearchNeighbors(ctx, collection, ids, topK, opts)
+}
+
+// SearchNeighbors implements NeighborsFor with one SearchByVector per record,
+// a few at a time. Records are read with GetMany when collection is a
+// BatchGetter and with Get otherwise. NeighborFinders call it for options
+// they cannot run in one query.
+func SearchNeighbors(ctx context.Context, collection Collection, ids []string, topK int, opts SearchOptions) (map[string][]SearchResult, error) {
+	if topK <= 0 {
+		return nil, fmt.Errorf("topK must be > 0")
+	}
+	if len(opts.Queries) > 0 {
+		return nil, fmt.Errorf("%w: neighbors are searched from stored vectors, SearchOptions.Queries must be empty", ErrSchemaMismatch)
+	}
+	if opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: neighbors of several records cannot be paged with one SearchOptions.Cursor", ErrSchemaMismatch)
+	}
+	records, err := getRecords(ctx, collection, uniqueIDs(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := make([][]SearchResult, len(records))
+	errs := make([]error, len(records))
+	slots := make(chan struct{}, neighborSearches)
+	var wg sync.WaitGroup
+	for i, record := range records {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			// One extra result makes up for the record finding itself.
+			results, err := collection.SearchByVector(ctx, record.Vector, topK+1, opts)
+			if err != nil {
+				errs[i] = fmt.Errorf("neighbors of %q: %w", record.ID, err)
+				return
+			}
+			neighbors[i] = withoutRecord(results, record.ID, topK)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]SearchResult, len(records))
+	for i, record := range records {
+		out[record.ID] = neighbors[i]
+	}
+	return out, nil
+}
+
+// uniqueIDs returns ids without repeats, in order of first appearance.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+func getRecords(ctx context.Context, collection Collection, ids []string) ([]Record, error) {
+	if getter, ok := collection.(BatchGetter); ok {
+		return getter.GetMany(ctx, ids)
+	}
+	records := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		record, err := collection.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// withoutRecord drops the result for id and keeps at most topK others.
+func withoutRecord(results []SearchResult, id string, topK int) []SearchResult {
+	out := make([]SearchResult, 0, min(len(results), topK))
+	for _, result := range results {
+		if result.Record.ID != id && len(out) < topK {
+			out = append(out, result)
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+)
+
+type neighborCollection struct {
+	Collection
+	records  map[string]Record
+	mu       sync.Mutex
+	searches int
+}
+
+func (c *neighborCollection) Name() string { return "docs" }
+
+func (c *neighborCollection) Get(_ context.Context, id string) (Record, error) {
+	record, ok := c.records[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *neighborCollection) SearchByVector(_ context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	c.mu.Lock()
+	c.searches++
+	c.mu.Unlock()
+	results := make([]SearchResult, 0, len(c.records))
+	for _, record := range c.records {
+		distance := float64(record.Vector[0] - vector[0])
+		if opts.Threshold != nil && distance*distance > *opts.Threshold {
+			continue
+		}
+		results = append(results, SearchResult{Record: record, Distance: distance * distance})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Distance < results[j].Distance })
+	return results[:min(topK, len(results))], nil
+}
+
+func TestNeighborsForExcludesSelf(t *testing.T) {
+	// Arrange
+	collection := &neighborCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{0}},
+		"b": {ID: "b", Vector: []float32{1}},
+		"c": {ID: "c", Vector: []float32{3}},
+	}}
+
+	// Act
+	neighbors, err := NeighborsFor(context.Background(), collection, []string{"a", "c", "a", "missing"}, 1, SearchOptions{})
+	_, queriesErr := NeighborsFor(context.Background(), collection, []string{"a"}, 1, SearchOptions{Queries: []QueryVector{{Vector: []float32{1}}}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("NeighborsFor: %v", err)
+	}
+	if len(neighbors) != 2 || collection.searches != 2 {
+		t.Fatalf("expected one search for each of a and c, got %v after %d searches", neighbors, collection.searches)
+	}
+	if len(neighbors["a"]) != 1 || neighbors["a"][0].Record.ID != "b" {
+		t.Fatalf("expected b next to a, got %v", neighbors["a"])
+	}
+	if len(neighbors["c"]) != 1 || neighbors["c"][0].Record.ID != "b" {
+		t.Fatalf("expected b next to c, got %v", neighbors["c"])
+	}
+	if !errors.Is(queriesErr, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch for Queries, got %v", queriesErr)
+	}
+}
@@ -0,0 +1,30 @@
+package vectordata
+
+import "github.com/gabisonia/go-vectorstore/vectordata/vectormath"
+
+// NormalizeVector returns a copy of vector scaled to unit L2 length. A zero
+// vector, which has no direction, is copied unchanged.
+func NormalizeVector(vector []float32) []float32 {
+	return vectormath.Normalize(vector)
+}
+
+// Float32Vector converts a float64 vector to the float32 vectors backends
+// index, rounding each component to the nearest float32.
+func Float32Vector(vector []float64) []float32 {
+	out := make([]float32, len(vector))
+	for i, component := range vector {
+		out[i] = float32(component)
+	}
+	return out
+}
+
+// NormalizeRecordVectors returns a copy of records whose vectors are scaled
+// to unit length. The records passed in are not modified.
+func NormalizeRecordVectors(records []Record) []Record {
+	out := make([]Record, len(records))
+	for i, record := range records {
+		record.Vector = NormalizeVector(record.Vector)
+		out[i] = record
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package vectordata
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNormalizeVector(t *testing.T) {
+	// Arrange
+	vector := []float32{3, 4}
+
+	// Act
+	got := NormalizeVector(vector)
+
+	// Assert
+	if math.Abs(float64(got[0])-0.6) > 1e-6 || math.Abs(float64(got[1])-0.8) > 1e-6 {
+		t.Fatalf("expected [0.6 0.8], got %v", got)
+	}
+	if vector[0] != 3 || vector[1] != 4 {
+		t.Fatalf("expected input to be left unchanged, got %v", vector)
+	}
+}
+
+func TestNormalizeVectorKeepsZeroVector(t *testing.T) {
+	// Act
+	got := NormalizeVector([]float32{0, 0})
+
+	// Assert
+	if got[0] != 0 || got[1] != 0 {
+		t.Fatalf("expected zero vector, got %v", got)
+	}
+}
+
+func TestNormalizeRecordVectorsCopiesRecords(t *testing.T) {
+	// Arrange
+	records := []Record{{ID: "a", Vector: []float32{0, 2}}}
+
+	// Act
+	got := NormalizeRecordVectors(records)
+
+	// Assert
+	if got[0].ID != "a" || got[0].Vector[1] != 1 {
+		t.Fatalf("unexpected records: %#v", got)
+	}
+	if records[0].Vector[1] != 2 {
+		t.Fatalf("expected input records to be left unchanged, got %v", records[0].Vector)
+	}
+}
+
+func TestFloat32Vector(t *testing.T) {
+	// Act
+	got := Float32Vector([]float64{0.1, 1e-50, 2})
+
+	// Assert
+	if got[0] != float32(0.1) || got[1] != 0 || got[2] != 2 {
+		t.Fatalf("unexpected conversion %v", got)
+	}
+}
+
+func TestVectorElementTypeValidate(t *testing.T) {
+	for _, valid := range []VectorElementType{"", ElementFloat32, ElementFloat64, ElementInt8} {
+		if err := valid.Validate(); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+	if err := VectorElementType("float16").Validate(); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+}
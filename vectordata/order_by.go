@@ -0,0 +1,182 @@
+package vectordata
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OrderSpec orders search results that tie in rank, i.e. in distance, or in
+// score for searches ranked by a boosted or fused score. Metadata values
+// compare as Postgres compares jsonb: null < strings < numbers < booleans <
+// arrays < objects, strings by bytes and numbers by value. Missing fields
+// sort last in either direction.
+type OrderSpec struct {
+	Field      FieldRef
+	Descending bool
+}
+
+// Asc orders by field, smallest first.
+func Asc(field FieldRef) OrderSpec {
+	return OrderSpec{Field: field}
+}
+
+// Desc orders by field, largest first, e.g. Desc(Metadata("published_at"))
+// for the newest of equally similar records.
+func Desc(field FieldRef) OrderSpec {
+	return OrderSpec{Field: field, Descending: true}
+}
+
+// OrderBySQL returns the expression and direction of spec for an ORDER BY
+// clause, with the field resolved as CompileFilterSQL resolves it: metadata
+// paths order by their jsonb value, and NULL sorts last.
+func OrderBySQL(spec OrderSpec, cfg FilterSQLConfig) (expr, direction string, err error) {
+	c := &filterCompiler{cfg: cfg}
+	resolved, isMetadata, path, err := c.resolveField(spec.Field)
+	if err != nil {
+		return "", "", err
+	}
+	if isMetadata {
+		resolved = metadataPathJSONBExpr(resolved, path)
+	}
+	if spec.Descending {
+		return resolved, "DESC NULLS LAST", nil
+	}
+	return resolved, "ASC NULLS LAST", nil
+}
+
+// OrderResults sorts results by Score, best first, then by orderBy, then by
+// ID, for stores that cannot order in the query and for merges of result
+// sets. Fields are read from the returned records, so metadata fields only
+// order results whose projection includes metadata.
+func OrderResults(results []SearchResult, orderBy []OrderSpec) error {
+	fields := make([]FieldRef, len(orderBy))
+	for i, spec := range orderBy {
+		field, err := NormalizeFieldRef(spec.Field)
+		if err != nil {
+			return err
+		}
+		fields[i] = field
+	}
+
+	type keyed struct {
+		result SearchResult
+		keys   []orderKey
+	}
+	sorted := make([]keyed, len(results))
+	for i, result := range results {
+		keys := make([]orderKey, len(fields))
+		for j, field := range fields {
+			value, present, err := resolveMatchField(field, result.Record)
+			if err != nil {
+				return err
+			}
+			if present && field.Kind == FieldMetadata {
+				if value, err = normalizeJSONValue(value); err != nil {
+					return fmt.Errorf("normalize metadata value: %w", err)
+				}
+			}
+			keys[j] = orderKey{value: value, present: present}
+		}
+		sorted[i] = keyed{result: result, keys: keys}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.result.Score != b.result.Score {
+			return a.result.Score > b.result.Score
+		}
+		for k, spec := range orderBy {
+			if c := compareOrderKeys(a.keys[k], b.keys[k], spec.Descending); c != 0 {
+				return c < 0
+			}
+		}
+		return a.result.Record.ID < b.result.Record.ID
+	})
+	for i := range sorted {
+		results[i] = sorted[i].result
+	}
+	return nil
+}
+
+type orderKey struct {
+	value   any
+	present bool
+}
+
+// compareOrderKeys compares two values of a field in the direction of the
+// spec, with missing values last.
+func compareOrderKeys(a, b orderKey, descending bool) int {
+	switch {
+	case !a.present || !b.present:
+		return cmp.Compare(orderPresence(a), orderPresence(b))
+	case descending:
+		return compareOrderValues(b.value, a.value)
+	default:
+		return compareOrderValues(a.value, b.value)
+	}
+}
+
+func orderPresence(key orderKey) int {
+	if key.present {
+		return 0
+	}
+	return 1
+}
+
+// compareOrderValues compares JSON values in jsonb order. Arrays and objects
+// compare equal to others of their type.
+func compareOrderValues(a, b any) int {
+	if c := cmp.Compare(orderTypeRank(a), orderTypeRank(b)); c != 0 {
+		return c
+	}
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case y:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if x, ok := toFloat64(a); ok {
+		y, _ := toFloat64(b)
+		return cmp.Compare(x, y)
+	}
+	return 0
+}
+
+func orderTypeRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case string:
+		return 1
+	case bool:
+		return 3
+	case []any:
+		return 4
+	case map[string]any:
+		return 5
+	}
+	if _, ok := toFloat64(v); ok {
+		return 2
+	}
+	return 5
+}
+
+// sortByScore sorts results by Score, best first, with ties in orderBy
+// order, or in their current order without it.
+func sortByScore(results []SearchResult, orderBy []OrderSpec) error {
+	if len(orderBy) > 0 {
+		return OrderResults(results, orderBy)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return nil
+}
@@ -0,0 +1,83 @@
+package vectordata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderResultsBreaksTiesByFields(t *testing.T) {
+	// Arrange
+	results := []SearchResult{
+		{Record: Record{ID: "old", Metadata: map[string]any{"published_at": "2024-01-01T00:00:00Z"}}, Score: 0.5},
+		{Record: Record{ID: "undated", Metadata: map[string]any{}}, Score: 0.5},
+		{Record: Record{ID: "best", Metadata: map[string]any{"published_at": "2020-01-01T00:00:00Z"}}, Score: 0.9},
+		{Record: Record{ID: "new", Metadata: map[string]any{"published_at": "2024-06-01T00:00:00Z"}}, Score: 0.5},
+		{Record: Record{ID: "also-undated"}, Score: 0.5},
+	}
+
+	// Act
+	err := OrderResults(results, []OrderSpec{Desc(Metadata("published_at"))})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("OrderResults: %v", err)
+	}
+	want := []string{"best", "new", "old", "also-undated", "undated"}
+	if got := resultIDs(results); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOrderResultsComparesLikeJSONB(t *testing.T) {
+	// Arrange
+	results := []SearchResult{
+		{Record: Record{ID: "true", Metadata: map[string]any{"v": true}}},
+		{Record: Record{ID: "ten", Metadata: map[string]any{"v": 10}}},
+		{Record: Record{ID: "two", Metadata: map[string]any{"v": 2.5}}},
+		{Record: Record{ID: "text", Metadata: map[string]any{"v": "9"}}},
+		{Record: Record{ID: "null", Metadata: map[string]any{"v": nil}}},
+	}
+
+	// Act
+	err := OrderResults(results, []OrderSpec{Asc(Metadata("v"))})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("OrderResults: %v", err)
+	}
+	want := []string{"null", "text", "two", "ten", "true"}
+	if got := resultIDs(results); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOrderResultsRejectsUnknownFields(t *testing.T) {
+	// Act
+	err := OrderResults([]SearchResult{{Record: Record{ID: "a"}}}, []OrderSpec{Asc(Column("vector"))})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestOrderBySQL(t *testing.T) {
+	// Arrange
+	cfg := FilterSQLConfig{ColumnExpr: map[string]string{"id": `"id"`}, MetadataExpr: `"metadata"`}
+
+	// Act
+	metadataExpr, metadataDirection, metadataErr := OrderBySQL(Desc(Metadata("author", "name")), cfg)
+	columnExpr, columnDirection, columnErr := OrderBySQL(Asc(Column("id")), cfg)
+
+	// Assert
+	if metadataErr != nil || columnErr != nil {
+		t.Fatalf("unexpected errors: %v %v", metadataErr, columnErr)
+	}
+	if metadataExpr != `("metadata" #> ARRAY['author', 'name'])` || metadataDirection != "DESC NULLS LAST" {
+		t.Fatalf("unexpected metadata order: %s %s", metadataExpr, metadataDirection)
+	}
+	if columnExpr != `"id"` || columnDirection != "ASC NULLS LAST" {
+		t.Fatalf("unexpected column order: %s %s", columnExpr, columnDirection)
+	}
+}
@@ -0,0 +1,164 @@
+// Package otelvectorstore traces vectordata stores and collections with
+// OpenTelemetry.
+//
+// Wrap a store once and use it in place of the original; every collection
+// it returns is traced as well:
+//
+//	store = otelvectorstore.WrapStore(store, otelvectorstore.Options{Backend: "postgresql"})
+//
+// Each operation starts a client span named after the operation and the
+// collection (e.g. "SearchByVector docs") carrying the collection, backend,
+// topK, filter complexity, and the number of records written or returned.
+// Errors are recorded on the span, except ErrNotFound from Get, which is an
+// expected outcome.
+package otelvectorstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/gabisonia/go-vectorstore/vectordata/otelvectorstore"
+
+// Attribute keys set on spans.
+const (
+	AttrBackend     = attribute.Key("db.system")
+	AttrCollection  = attribute.Key("vectorstore.collection")
+	AttrTopK        = attribute.Key("vectorstore.top_k")
+	AttrFilterNodes = attribute.Key("vectorstore.filter.nodes")
+	AttrFilterDepth = attribute.Key("vectorstore.filter.depth")
+	AttrRecords     = attribute.Key("vectorstore.records")
+	AttrRows        = attribute.Key("vectorstore.rows")
+)
+
+// Options configures tracing.
+type Options struct {
+	// TracerProvider creates the tracer (default otel.GetTracerProvider()).
+	TracerProvider trace.TracerProvider
+	// Backend is reported as db.system, e.g. "postgresql" or "faiss".
+	Backend string
+}
+
+type tracer struct {
+	tracer  trace.Tracer
+	backend string
+}
+
+func newTracer(opts Options) tracer {
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return tracer{tracer: provider.Tracer(instrumentationName), backend: opts.Backend}
+}
+
+func (t tracer) start(ctx context.Context, op, collection string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, AttrCollection.String(collection))
+	if t.backend != "" {
+		attrs = append(attrs, AttrBackend.String(t.backend))
+	}
+	return t.tracer.Start(ctx, op+" "+collection,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end records err, if any, and ends the span.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func filterAttributes(filter vectordata.Filter) []attribute.KeyValue {
+	nodes, depth := vectordata.FilterComplexity(filter)
+	return []attribute.KeyValue{AttrFilterNodes.Int(nodes), AttrFilterDepth.Int(depth)}
+}
+
+// Middleware returns a collection middleware that traces each call, for
+// composing with other middleware in vectordata.WrapCollection.
+func Middleware(opts Options) vectordata.CollectionMiddleware {
+	return newTracer(opts).middleware
+}
+
+func (t tracer) middleware(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+	var attrs []attribute.KeyValue
+	switch call.Operation {
+	case vectordata.OpInsert, vectordata.OpUpsert:
+		attrs = append(attrs, AttrRecords.Int(len(call.Records)))
+	case vectordata.OpDelete:
+		attrs = append(attrs, AttrRecords.Int(len(call.IDs)))
+	case vectordata.OpCount:
+		attrs = filterAttributes(call.Filter)
+	case vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		attrs = append(filterAttributes(call.ActiveFilter()), AttrTopK.Int(call.TopK))
+	}
+
+	ctx, span := t.start(ctx, string(call.Operation), call.Collection.Name(), attrs...)
+	err := next(ctx, call)
+	if call.Operation == vectordata.OpGet && errors.Is(err, vectordata.ErrNotFound) {
+		span.SetAttributes(AttrRows.Int(0))
+		end(span, nil)
+		return err
+	}
+	if rows, ok := resultRows(call); ok && err == nil {
+		span.SetAttributes(AttrRows.Int64(rows))
+	}
+	end(span, err)
+	return err
+}
+
+// resultRows returns the number of records a call returned, deleted or
+// counted.
+func resultRows(call *vectordata.Call) (int64, bool) {
+	switch call.Operation {
+	case vectordata.OpGet:
+		return 1, true
+	case vectordata.OpDelete, vectordata.OpCount:
+		return call.Count, true
+	case vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		return int64(len(call.Results)), true
+	default:
+		return 0, false
+	}
+}
+
+// store traces a VectorStore.
+type store struct {
+	base   vectordata.VectorStore
+	tracer tracer
+}
+
+// WrapStore returns a store that traces base and the collections it returns.
+func WrapStore(base vectordata.VectorStore, opts Options) vectordata.VectorStore {
+	return &store{base: base, tracer: newTracer(opts)}
+}
+
+func (s *store) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	ctx, span := s.tracer.start(ctx, "EnsureCollection", spec.Name)
+	collection, err := s.base.EnsureCollection(ctx, spec)
+	end(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return vectordata.WrapCollection(collection, s.tracer.middleware), nil
+}
+
+func (s *store) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return vectordata.WrapCollection(s.base.Collection(name, dimension, metric), s.tracer.middleware)
+}
+
+// WrapCollection returns a collection that traces base. It implements
+// vectordata.TextSearcher and vectordata.HybridSearcher exactly when base
+// does.
+func WrapCollection(base vectordata.Collection, opts Options) vectordata.Collection {
+	return vectordata.WrapCollection(base, Middleware(opts))
+}
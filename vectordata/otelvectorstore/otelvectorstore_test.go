@@ -0,0 +1,169 @@
+package otelvectorstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	err    error
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) { s.err = err }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string)           { s.status = code }
+func (s *recordedSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+
+type recordingProvider struct {
+	noop.TracerProvider
+	spans []*recordedSpan
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{provider: p}
+}
+
+type recordingTracer struct {
+	noop.Tracer
+	provider *recordingProvider
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{name: name, kind: cfg.SpanKind(), attrs: make(map[attribute.Key]attribute.Value)}
+	span.SetAttributes(cfg.Attributes()...)
+	t.provider.spans = append(t.provider.spans, span)
+	return ctx, span
+}
+
+type fakeCollection struct {
+	vectordata.Collection
+	results []vectordata.SearchResult
+	err     error
+}
+
+func (f *fakeCollection) Name() string { return "docs" }
+
+func (f *fakeCollection) SearchByVector(context.Context, []float32, int, vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	return f.results, f.err
+}
+
+func (f *fakeCollection) Get(context.Context, string) (vectordata.Record, error) {
+	return vectordata.Record{}, f.err
+}
+
+type fakeTextCollection struct {
+	*fakeCollection
+}
+
+func (f fakeTextCollection) SearchByText(context.Context, string, int, vectordata.TextSearchOptions) ([]vectordata.SearchResult, error) {
+	return f.results, f.err
+}
+
+func TestSearchByVectorRecordsAttributes(t *testing.T) {
+	// Arrange
+	provider := &recordingProvider{}
+	base := &fakeCollection{results: make([]vectordata.SearchResult, 2)}
+	collection := WrapCollection(base, Options{TracerProvider: provider, Backend: "postgresql"})
+	filter := vectordata.And(vectordata.Eq(vectordata.Metadata("category"), "news"), vectordata.Gt(vectordata.Metadata("rank"), 3))
+
+	// Act
+	_, err := collection.SearchByVector(context.Background(), []float32{1, 0}, 5, vectordata.SearchOptions{Filter: filter})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	if len(provider.spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(provider.spans))
+	}
+	span := provider.spans[0]
+	if span.name != "SearchByVector docs" || span.kind != trace.SpanKindClient || !span.ended {
+		t.Fatalf("unexpected span name=%q kind=%v ended=%v", span.name, span.kind, span.ended)
+	}
+	expected := map[attribute.Key]attribute.Value{
+		AttrCollection:  attribute.StringValue("docs"),
+		AttrBackend:     attribute.StringValue("postgresql"),
+		AttrTopK:        attribute.IntValue(5),
+		AttrFilterNodes: attribute.IntValue(3),
+		AttrFilterDepth: attribute.IntValue(2),
+		AttrRows:        attribute.IntValue(2),
+	}
+	for key, value := range expected {
+		if span.attrs[key] != value {
+			t.Fatalf("expected %s=%v, got %v", key, value.Emit(), span.attrs[key].Emit())
+		}
+	}
+}
+
+func TestErrorsAreRecordedExceptNotFound(t *testing.T) {
+	// Arrange
+	provider := &recordingProvider{}
+	failure := errors.New("boom")
+	failing := WrapCollection(&fakeCollection{err: failure}, Options{TracerProvider: provider})
+	missing := WrapCollection(&fakeCollection{err: vectordata.ErrNotFound}, Options{TracerProvider: provider})
+
+	// Act
+	_, searchErr := failing.SearchByVector(context.Background(), []float32{1}, 1, vectordata.SearchOptions{})
+	_, getErr := missing.Get(context.Background(), "missing")
+
+	// Assert
+	if !errors.Is(searchErr, failure) || !errors.Is(getErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected errors to pass through, got %v and %v", searchErr, getErr)
+	}
+	if provider.spans[0].status != codes.Error || provider.spans[0].err != failure {
+		t.Fatalf("expected the search error on the span, got %v (%v)", provider.spans[0].err, provider.spans[0].status)
+	}
+	if provider.spans[1].status != codes.Unset || provider.spans[1].err != nil {
+		t.Fatalf("expected ErrNotFound not to mark the span failed, got %v", provider.spans[1].err)
+	}
+	if _, ok := provider.spans[0].attrs[AttrRows]; ok {
+		t.Fatal("expected no row count on a failed search")
+	}
+}
+
+func TestWrapCollectionPreservesOptionalInterfaces(t *testing.T) {
+	// Arrange
+	provider := &recordingProvider{}
+	base := &fakeCollection{results: make([]vectordata.SearchResult, 1)}
+
+	// Act
+	plain := WrapCollection(base, Options{TracerProvider: provider})
+	text := WrapCollection(fakeTextCollection{base}, Options{TracerProvider: provider})
+
+	// Assert
+	if _, ok := plain.(vectordata.TextSearcher); ok {
+		t.Fatal("expected the wrapper not to add text search")
+	}
+	if _, ok := plain.(vectordata.HybridSearcher); ok {
+		t.Fatal("expected the wrapper not to add hybrid search")
+	}
+	searcher, ok := text.(vectordata.TextSearcher)
+	if !ok {
+		t.Fatal("expected the wrapper to keep text search")
+	}
+	if _, err := searcher.SearchByText(context.Background(), "query", 3, vectordata.TextSearchOptions{}); err != nil {
+		t.Fatalf("SearchByText: %v", err)
+	}
+	if len(provider.spans) != 1 || provider.spans[0].name != "SearchByText docs" {
+		t.Fatalf("expected a SearchByText span, got %d spans", len(provider.spans))
+	}
+}
@@ -0,0 +1,114 @@
+package vectordata
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// OutlierOptions configures FindOutliers.
+type OutlierOptions struct {
+	// Filter restricts both the scanned records and their neighbors.
+	Filter Filter
+	// Neighbors is k, the number of nearest neighbors averaged (default 10).
+	Neighbors int
+	// Threshold is the mean neighbor distance above which a record is an
+	// outlier, and must be > 0.
+	Threshold float64
+	// BatchSize is how many records are listed and searched at a time
+	// (default 500).
+	BatchSize int
+	// MetadataField, when set, is the metadata key each outlier's mean
+	// distance is written to. Records that are not outliers are left as
+	// they are.
+	MetadataField string
+}
+
+// Outlier is a record whose neighbors are unusually far away.
+type Outlier struct {
+	ID string
+	// MeanDistance is the mean distance to its nearest neighbors.
+	MeanDistance float64
+}
+
+// OutlierReport is the result of FindOutliers.
+type OutlierReport struct {
+	// Scanned is how many records were checked.
+	Scanned int
+	// Outliers are sorted by descending MeanDistance.
+	Outliers []Outlier
+}
+
+// FindOutliers computes the mean distance of every record to its k nearest
+// neighbors and reports those above opts.Threshold, e.g. to catch garbage
+// embeddings after an ingestion bug. Records are listed and searched in
+// batches like FindDuplicates, so the collection must be a RecordLister.
+// Records without neighbors are not outliers.
+func FindOutliers(ctx context.Context, collection Collection, opts OutlierOptions) (OutlierReport, error) {
+	if opts.Threshold <= 0 {
+		return OutlierReport{}, fmt.Errorf("%w: outlier threshold must be > 0", ErrSchemaMismatch)
+	}
+	if opts.Neighbors < 0 || opts.BatchSize < 0 {
+		return OutlierReport{}, fmt.Errorf("%w: Neighbors and BatchSize must be >= 0", ErrSchemaMismatch)
+	}
+
+	var report OutlierReport
+	search := SearchOptions{Filter: opts.Filter, Projection: &Projection{}}
+	err := scanNeighbors(ctx, collection, opts.BatchSize, cmp.Or(opts.Neighbors, 10), search, func(page []Record, neighbors map[string][]SearchResult) error {
+		report.Scanned += len(page)
+		for _, id := range slices.Sorted(maps.Keys(neighbors)) {
+			results := neighbors[id]
+			if len(results) == 0 {
+				continue
+			}
+			var total float64
+			for _, result := range results {
+				total += result.Distance
+			}
+			if mean := total / float64(len(results)); mean > opts.Threshold {
+				report.Outliers = append(report.Outliers, Outlier{ID: id, MeanDistance: mean})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return OutlierReport{}, err
+	}
+	sort.SliceStable(report.Outliers, func(i, j int) bool {
+		return report.Outliers[i].MeanDistance > report.Outliers[j].MeanDistance
+	})
+
+	if opts.MetadataField != "" && len(report.Outliers) > 0 {
+		if err := markOutliers(ctx, collection, opts.MetadataField, report.Outliers); err != nil {
+			return OutlierReport{}, err
+		}
+	}
+	return report, nil
+}
+
+// markOutliers writes each outlier's mean distance to field, rereading the
+// records so the upsert keeps their other fields.
+func markOutliers(ctx context.Context, collection Collection, field string, outliers []Outlier) error {
+	means := make(map[string]float64, len(outliers))
+	ids := make([]string, len(outliers))
+	for i, outlier := range outliers {
+		means[outlier.ID] = outlier.MeanDistance
+		ids[i] = outlier.ID
+	}
+	records, err := getRecords(ctx, collection, ids)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		metadata := maps.Clone(records[i].Metadata)
+		if metadata == nil {
+			metadata = make(map[string]any, 1)
+		}
+		metadata[field] = means[records[i].ID]
+		records[i].Metadata = metadata
+	}
+	return collection.Upsert(ctx, records)
+}
@@ -0,0 +1,55 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+type upsertingNeighborCollection struct {
+	listedNeighborCollection
+	upserted []Record
+}
+
+func (c *upsertingNeighborCollection) Upsert(_ context.Context, records []Record) error {
+	c.upserted = append(c.upserted, records...)
+	return nil
+}
+
+func TestFindOutliersReportsAndMarksFarRecords(t *testing.T) {
+	// Arrange
+	collection := &upsertingNeighborCollection{listedNeighborCollection: listedNeighborCollection{&neighborCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{0}, Metadata: map[string]any{"lang": "en"}},
+		"b": {ID: "b", Vector: []float32{1}},
+		"c": {ID: "c", Vector: []float32{2}},
+		"z": {ID: "z", Vector: []float32{10}},
+	}}}}
+
+	// Act
+	report, err := FindOutliers(context.Background(), collection, OutlierOptions{
+		Neighbors:     2,
+		Threshold:     10,
+		BatchSize:     3,
+		MetadataField: "outlier_distance",
+	})
+	_, thresholdErr := FindOutliers(context.Background(), collection, OutlierOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindOutliers: %v", err)
+	}
+	if report.Scanned != 4 || len(report.Outliers) != 1 || report.Outliers[0].ID != "z" {
+		t.Fatalf("expected z as the only outlier of 4, got %+v", report)
+	}
+	// z is 64 and 81 away in squared distance from c and b.
+	if math.Abs(report.Outliers[0].MeanDistance-72.5) > 1e-9 {
+		t.Fatalf("expected mean distance 72.5, got %v", report.Outliers[0].MeanDistance)
+	}
+	if len(collection.upserted) != 1 || collection.upserted[0].Metadata["outlier_distance"] != 72.5 || len(collection.upserted[0].Vector) != 1 {
+		t.Fatalf("expected z upserted with its mean distance, got %+v", collection.upserted)
+	}
+	if !errors.Is(thresholdErr, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch without a threshold, got %v", thresholdErr)
+	}
+}
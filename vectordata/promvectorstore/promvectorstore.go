@@ -0,0 +1,191 @@
+// Package promvectorstore records Prometheus metrics for vectordata stores
+// and collections.
+//
+// Register the metrics once and wrap each store with them:
+//
+//	metrics, err := promvectorstore.NewMetrics(prometheus.DefaultRegisterer, promvectorstore.Options{Backend: "postgres"})
+//	store = metrics.WrapStore(store)
+//
+// Every series carries the backend, collection and operation labels:
+//
+//   - <namespace>_operation_duration_seconds: latency histogram
+//   - <namespace>_operation_errors_total: failures by error type
+//   - <namespace>_search_top_k: requested topK per search
+//   - <namespace>_search_results: results returned per search
+package promvectorstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultNamespace = "vectorstore"
+
+// Error types reported in the type label of the errors counter.
+const (
+	ErrorTypeNotFound          = "not_found"
+	ErrorTypeDimensionMismatch = "dimension_mismatch"
+	ErrorTypeSchemaMismatch    = "schema_mismatch"
+	ErrorTypeInvalidFilter     = "invalid_filter"
+	ErrorTypeCanceled          = "canceled"
+	ErrorTypeDeadlineExceeded  = "deadline_exceeded"
+	ErrorTypeOther             = "other"
+)
+
+// Options configures the metrics.
+type Options struct {
+	// Namespace prefixes metric names (default "vectorstore").
+	Namespace string
+	// Backend is the value of the backend label, e.g. "postgres".
+	Backend string
+	// DurationBuckets are the latency buckets in seconds (default
+	// prometheus.DefBuckets).
+	DurationBuckets []float64
+	// SizeBuckets are the topK and result count buckets (default powers of
+	// two from 1 to 1024).
+	SizeBuckets []float64
+}
+
+// Metrics holds the collectors shared by every wrapped store and collection.
+type Metrics struct {
+	backend  string
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	topK     *prometheus.HistogramVec
+	results  *prometheus.HistogramVec
+}
+
+// NewMetrics creates the collectors and registers them with reg.
+func NewMetrics(reg prometheus.Registerer, opts Options) (*Metrics, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = defaultNamespace
+	}
+	if opts.DurationBuckets == nil {
+		opts.DurationBuckets = prometheus.DefBuckets
+	}
+	if opts.SizeBuckets == nil {
+		opts.SizeBuckets = prometheus.ExponentialBuckets(1, 2, 11)
+	}
+
+	labels := []string{"backend", "collection", "operation"}
+	m := &Metrics{
+		backend: opts.Backend,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of vector store operations.",
+			Buckets:   opts.DurationBuckets,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "operation_errors_total",
+			Help:      "Failed vector store operations by error type.",
+		}, append(labels, "type")),
+		topK: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "search_top_k",
+			Help:      "Requested topK of searches.",
+			Buckets:   opts.SizeBuckets,
+		}, labels),
+		results: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "search_results",
+			Help:      "Results returned by searches.",
+			Buckets:   opts.SizeBuckets,
+		}, labels),
+	}
+
+	for _, collector := range []prometheus.Collector{m.duration, m.errors, m.topK, m.results} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ErrorType classifies err for the type label.
+func ErrorType(err error) string {
+	switch {
+	case errors.Is(err, vectordata.ErrNotFound):
+		return ErrorTypeNotFound
+	case errors.Is(err, vectordata.ErrDimensionMismatch):
+		return ErrorTypeDimensionMismatch
+	case errors.Is(err, vectordata.ErrSchemaMismatch):
+		return ErrorTypeSchemaMismatch
+	case errors.Is(err, vectordata.ErrInvalidFilter):
+		return ErrorTypeInvalidFilter
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeDeadlineExceeded
+	default:
+		return ErrorTypeOther
+	}
+}
+
+// Middleware returns a collection middleware that records each call, for
+// composing with other middleware in vectordata.WrapCollection.
+func (m *Metrics) Middleware() vectordata.CollectionMiddleware {
+	return m.middleware
+}
+
+func (m *Metrics) middleware(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+	start := time.Now()
+	err := next(ctx, call)
+	m.observe(call.Collection.Name(), string(call.Operation), start, err)
+
+	switch call.Operation {
+	case vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		labels := []string{m.backend, call.Collection.Name(), string(call.Operation)}
+		m.topK.WithLabelValues(labels...).Observe(float64(call.TopK))
+		if err == nil {
+			m.results.WithLabelValues(labels...).Observe(float64(len(call.Results)))
+		}
+	}
+	return err
+}
+
+// observe records an operation that started at start.
+func (m *Metrics) observe(collection, op string, start time.Time, err error) {
+	m.duration.WithLabelValues(m.backend, collection, op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(m.backend, collection, op, ErrorType(err)).Inc()
+	}
+}
+
+// store records metrics for a VectorStore.
+type store struct {
+	base    vectordata.VectorStore
+	metrics *Metrics
+}
+
+// WrapStore returns a store that records metrics for base and the
+// collections it returns.
+func (m *Metrics) WrapStore(base vectordata.VectorStore) vectordata.VectorStore {
+	return &store{base: base, metrics: m}
+}
+
+func (s *store) EnsureCollection(ctx context.Context, spec vectordata.CollectionSpec) (vectordata.Collection, error) {
+	start := time.Now()
+	collection, err := s.base.EnsureCollection(ctx, spec)
+	s.metrics.observe(spec.Name, "EnsureCollection", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return s.metrics.WrapCollection(collection), nil
+}
+
+func (s *store) Collection(name string, dimension int, metric vectordata.DistanceMetric) vectordata.Collection {
+	return s.metrics.WrapCollection(s.base.Collection(name, dimension, metric))
+}
+
+// WrapCollection returns a collection that records metrics for base. It
+// implements vectordata.TextSearcher and vectordata.HybridSearcher exactly
+// when base does.
+func (m *Metrics) WrapCollection(base vectordata.Collection) vectordata.Collection {
+	return vectordata.WrapCollection(base, m.middleware)
+}
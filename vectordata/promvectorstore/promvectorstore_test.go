@@ -0,0 +1,144 @@
+package promvectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeCollection struct {
+	vectordata.Collection
+	results []vectordata.SearchResult
+	err     error
+}
+
+func (f *fakeCollection) Name() string { return "docs" }
+
+func (f *fakeCollection) SearchByVector(context.Context, []float32, int, vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	return f.results, f.err
+}
+
+func (f *fakeCollection) Get(context.Context, string) (vectordata.Record, error) {
+	return vectordata.Record{}, f.err
+}
+
+type fakeHybridCollection struct {
+	*fakeCollection
+}
+
+func (f fakeHybridCollection) HybridSearch(context.Context, []float32, string, int, vectordata.HybridSearchOptions) ([]vectordata.SearchResult, error) {
+	return f.results, f.err
+}
+
+func newTestMetrics(t *testing.T) (*Metrics, *prometheus.Registry) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(reg, Options{Backend: "postgres"})
+	if err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+	return metrics, reg
+}
+
+func TestSearchRecordsLatencyTopKAndResults(t *testing.T) {
+	// Arrange
+	metrics, reg := newTestMetrics(t)
+	collection := metrics.WrapCollection(&fakeCollection{results: make([]vectordata.SearchResult, 3)})
+
+	// Act
+	_, err := collection.SearchByVector(context.Background(), []float32{1}, 10, vectordata.SearchOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	count, err := testutil.GatherAndCount(reg, "vectorstore_operation_duration_seconds", "vectorstore_search_top_k", "vectorstore_search_results")
+	if err != nil || count != 3 {
+		t.Fatalf("expected one series per histogram, got %d (%v)", count, err)
+	}
+	results := metrics.results.WithLabelValues("postgres", "docs", "SearchByVector").(prometheus.Histogram)
+	if got := testutil.CollectAndCount(metrics.errors); got != 0 {
+		t.Fatalf("expected no error series, got %d", got)
+	}
+	if sum := histogramSum(t, results); sum != 3 {
+		t.Fatalf("expected 3 results observed, got %v", sum)
+	}
+}
+
+func TestErrorsAreCountedByType(t *testing.T) {
+	// Arrange
+	metrics, _ := newTestMetrics(t)
+	failing := metrics.WrapCollection(&fakeCollection{err: fmt.Errorf("compile: %w", vectordata.ErrInvalidFilter)})
+	missing := metrics.WrapCollection(&fakeCollection{err: vectordata.ErrNotFound})
+
+	// Act
+	_, searchErr := failing.SearchByVector(context.Background(), []float32{1}, 5, vectordata.SearchOptions{})
+	_, getErr := missing.Get(context.Background(), "missing")
+
+	// Assert
+	if !errors.Is(searchErr, vectordata.ErrInvalidFilter) || !errors.Is(getErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected errors to pass through, got %v and %v", searchErr, getErr)
+	}
+	if got := testutil.ToFloat64(metrics.errors.WithLabelValues("postgres", "docs", "SearchByVector", ErrorTypeInvalidFilter)); got != 1 {
+		t.Fatalf("expected 1 invalid filter error, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.errors.WithLabelValues("postgres", "docs", "Get", ErrorTypeNotFound)); got != 1 {
+		t.Fatalf("expected 1 not found error, got %v", got)
+	}
+	if sum := histogramSum(t, metrics.results.WithLabelValues("postgres", "docs", "SearchByVector").(prometheus.Histogram)); sum != 0 {
+		t.Fatalf("expected no results observed for a failed search, got %v", sum)
+	}
+}
+
+func TestNewMetricsRejectsDuplicateRegistration(t *testing.T) {
+	// Arrange
+	reg := prometheus.NewRegistry()
+	if _, err := NewMetrics(reg, Options{}); err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+
+	// Act
+	_, err := NewMetrics(reg, Options{})
+
+	// Assert
+	var already prometheus.AlreadyRegisteredError
+	if !errors.As(err, &already) {
+		t.Fatalf("expected AlreadyRegisteredError, got %v", err)
+	}
+}
+
+func TestWrapCollectionPreservesOptionalInterfaces(t *testing.T) {
+	// Arrange
+	metrics, _ := newTestMetrics(t)
+
+	// Act
+	plain := metrics.WrapCollection(&fakeCollection{})
+	hybrid := metrics.WrapCollection(fakeHybridCollection{&fakeCollection{}})
+
+	// Assert
+	if _, ok := plain.(vectordata.HybridSearcher); ok {
+		t.Fatal("expected the wrapper not to add hybrid search")
+	}
+	if _, ok := hybrid.(vectordata.HybridSearcher); !ok {
+		t.Fatal("expected the wrapper to keep hybrid search")
+	}
+	if _, ok := hybrid.(vectordata.TextSearcher); ok {
+		t.Fatal("expected the wrapper not to add text search")
+	}
+}
+
+func histogramSum(t *testing.T, histogram prometheus.Histogram) float64 {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(histogram)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	return families[0].GetMetric()[0].GetHistogram().GetSampleSum()
+}
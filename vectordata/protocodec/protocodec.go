@@ -0,0 +1,188 @@
+// Package protocodec stores protobuf messages in vectordata collections.
+//
+//	codec, err := protocodec.New(&pb.Article{}, protocodec.Options{
+//		VectorField:    "embedding",
+//		MetadataFields: []protoreflect.Name{"category", "published_at"},
+//	})
+//	articles := vectordata.NewTypedCollection(collection, codec)
+//
+// The serialized message is stored base64-encoded in Record.Content, and
+// selected fields are promoted into Metadata for filtering. Messages are
+// handled through proto reflection, so generated and dynamic messages (e.g.
+// from a schema registry) both work.
+package protocodec
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Options configures a Codec.
+type Options struct {
+	// IDField is the string field holding the record ID (default "id").
+	IDField protoreflect.Name
+	// VectorField, when set, is a repeated float or double field moved to
+	// Record.Vector instead of being serialized into Content.
+	VectorField protoreflect.Name
+	// MetadataFields lists scalar or enum fields, singular or repeated,
+	// copied into Metadata. Integers are stored as int64 or uint64, enums by
+	// value name and bytes base64-encoded.
+	MetadataFields []protoreflect.Name
+}
+
+// Codec is a vectordata.Codec for messages of type M.
+type Codec[M proto.Message] struct {
+	prototype protoreflect.Message
+	id        protoreflect.FieldDescriptor
+	vector    protoreflect.FieldDescriptor
+	metadata  []protoreflect.FieldDescriptor
+}
+
+// New returns a codec for messages shaped like prototype, which is only used
+// for its type. Fields named in opts are checked against the message
+// descriptor.
+func New[M proto.Message](prototype M, opts Options) (*Codec[M], error) {
+	message := prototype.ProtoReflect()
+	descriptor := message.Descriptor()
+	field := func(name protoreflect.Name) (protoreflect.FieldDescriptor, error) {
+		fd := descriptor.Fields().ByName(name)
+		if fd == nil {
+			return nil, fmt.Errorf("protocodec %s: no field %q", descriptor.FullName(), name)
+		}
+		return fd, nil
+	}
+
+	if opts.IDField == "" {
+		opts.IDField = "id"
+	}
+	c := &Codec[M]{prototype: message}
+	var err error
+	if c.id, err = field(opts.IDField); err != nil {
+		return nil, err
+	}
+	if c.id.Kind() != protoreflect.StringKind || c.id.IsList() {
+		return nil, fmt.Errorf("protocodec %s: id field %q must be a singular string", descriptor.FullName(), opts.IDField)
+	}
+	if opts.VectorField != "" {
+		if c.vector, err = field(opts.VectorField); err != nil {
+			return nil, err
+		}
+		if !c.vector.IsList() || (c.vector.Kind() != protoreflect.FloatKind && c.vector.Kind() != protoreflect.DoubleKind) {
+			return nil, fmt.Errorf("protocodec %s: vector field %q must be a repeated float or double", descriptor.FullName(), opts.VectorField)
+		}
+	}
+	for _, name := range opts.MetadataFields {
+		fd, err := field(name)
+		if err != nil {
+			return nil, err
+		}
+		if fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			return nil, fmt.Errorf("protocodec %s: metadata field %q must be a scalar or enum", descriptor.FullName(), name)
+		}
+		c.metadata = append(c.metadata, fd)
+	}
+	return c, nil
+}
+
+// Encode serializes message into a Record.
+func (c *Codec[M]) Encode(message M) (vectordata.Record, error) {
+	m := message.ProtoReflect()
+	record := vectordata.Record{ID: m.Get(c.id).String()}
+	if record.ID == "" {
+		return vectordata.Record{}, fmt.Errorf("%w: protocodec: field %q is empty", vectordata.ErrInvalidRecord, c.id.Name())
+	}
+	for _, fd := range c.metadata {
+		if !m.Has(fd) {
+			continue
+		}
+		if record.Metadata == nil {
+			record.Metadata = make(map[string]any, len(c.metadata))
+		}
+		record.Metadata[string(fd.Name())] = metadataValue(fd, m.Get(fd))
+	}
+
+	if c.vector != nil && m.Has(c.vector) {
+		list := m.Get(c.vector).List()
+		record.Vector = make([]float32, list.Len())
+		for i := range record.Vector {
+			record.Vector[i] = float32(list.Get(i).Float())
+		}
+		// The vector is stored once, in Record.Vector.
+		clone := proto.Clone(message).ProtoReflect()
+		clone.Clear(c.vector)
+		m = clone
+	}
+	raw, err := proto.Marshal(m.Interface())
+	if err != nil {
+		return vectordata.Record{}, fmt.Errorf("protocodec: record %q: %w", record.ID, err)
+	}
+	content := base64.StdEncoding.EncodeToString(raw)
+	record.Content = &content
+	return record, nil
+}
+
+// Decode parses the message serialized in record.Content and restores its
+// vector from Record.Vector.
+func (c *Codec[M]) Decode(record vectordata.Record) (M, error) {
+	var zero M
+	if record.Content == nil {
+		return zero, fmt.Errorf("%w: protocodec: record %q has no content", vectordata.ErrInvalidRecord, record.ID)
+	}
+	raw, err := base64.StdEncoding.DecodeString(*record.Content)
+	if err != nil {
+		return zero, fmt.Errorf("%w: protocodec: record %q: %v", vectordata.ErrInvalidRecord, record.ID, err)
+	}
+	m := c.prototype.New()
+	if err := proto.Unmarshal(raw, m.Interface()); err != nil {
+		return zero, fmt.Errorf("%w: protocodec: record %q: %v", vectordata.ErrInvalidRecord, record.ID, err)
+	}
+	if c.vector != nil && len(record.Vector) > 0 {
+		list := m.Mutable(c.vector).List()
+		for _, component := range record.Vector {
+			if c.vector.Kind() == protoreflect.FloatKind {
+				list.Append(protoreflect.ValueOfFloat32(component))
+			} else {
+				list.Append(protoreflect.ValueOfFloat64(float64(component)))
+			}
+		}
+	}
+	return m.Interface().(M), nil
+}
+
+// metadataValue converts a field value to a JSON-friendly metadata value.
+func metadataValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	if fd.IsList() {
+		list := value.List()
+		values := make([]any, list.Len())
+		for i := range values {
+			values[i] = scalarValue(fd, list.Get(i))
+		}
+		return values
+	}
+	return scalarValue(fd, value)
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if enum := fd.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return string(enum.Name())
+		}
+		return int64(value.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return value.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,121 @@
+package protocodec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newArticle returns an empty dynamic message of:
+//
+//	message Article {
+//	  string id = 1;
+//	  string category = 2;
+//	  repeated float embedding = 3;
+//	  int32 rank = 4;
+//	  Status status = 5;
+//	}
+func newArticle(t *testing.T) *dynamicpb.Message {
+	t.Helper()
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: kind.Enum(), Label: label.Enum()}
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	status := field("status", 5, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional)
+	status.TypeName = proto.String(".test.Status")
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("article.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("DRAFT"), Number: proto.Int32(0)},
+				{Name: proto.String("PUBLISHED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Article"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("category", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("embedding", 3, descriptorpb.FieldDescriptorProto_TYPE_FLOAT, repeated),
+				field("rank", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional),
+				status,
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("build descriptor: %v", err)
+	}
+	return dynamicpb.NewMessage(file.Messages().ByName("Article"))
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	// Arrange
+	article := newArticle(t)
+	codec, err := New(article, Options{VectorField: "embedding", MetadataFields: []protoreflect.Name{"category", "rank", "status"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	fields := article.Descriptor().Fields()
+	article.Set(fields.ByName("id"), protoreflect.ValueOfString("a"))
+	article.Set(fields.ByName("category"), protoreflect.ValueOfString("news"))
+	article.Set(fields.ByName("rank"), protoreflect.ValueOfInt32(7))
+	article.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	embedding := article.Mutable(fields.ByName("embedding")).List()
+	embedding.Append(protoreflect.ValueOfFloat32(0.5))
+	embedding.Append(protoreflect.ValueOfFloat32(1))
+
+	// Act
+	record, encodeErr := codec.Encode(article)
+	decoded, decodeErr := codec.Decode(record)
+
+	// Assert
+	if encodeErr != nil || decodeErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", encodeErr, decodeErr)
+	}
+	if record.ID != "a" || len(record.Vector) != 2 || record.Metadata["category"] != "news" || record.Metadata["rank"] != int64(7) || record.Metadata["status"] != "PUBLISHED" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if !proto.Equal(decoded, article) {
+		t.Fatalf("expected the message to round-trip, got %v", decoded)
+	}
+	if article.Get(fields.ByName("embedding")).List().Len() != 2 {
+		t.Fatal("expected Encode to leave the message unchanged")
+	}
+}
+
+func TestNewRejectsInvalidFields(t *testing.T) {
+	article := newArticle(t)
+	cases := []Options{
+		{IDField: "rank"},
+		{VectorField: "category"},
+		{MetadataFields: []protoreflect.Name{"missing"}},
+	}
+	for _, opts := range cases {
+		if _, err := New(article, opts); err == nil {
+			t.Fatalf("expected options %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestDecodeRejectsInvalidContent(t *testing.T) {
+	// Arrange
+	codec, _ := New(newArticle(t), Options{})
+	content := "not base64!"
+
+	// Act
+	_, err := codec.Decode(vectordata.Record{ID: "a", Content: &content})
+
+	// Assert
+	if !errors.Is(err, vectordata.ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package vectordata
+
+import (
+	"context"
+	"fmt"
+)
+
+// QueryFusion selects how SearchByVector combines SearchOptions.Queries.
+type QueryFusion string
+
+const (
+	// QueryFusionCombine searches once with the weighted mean of the query
+	// vectors.
+	QueryFusionCombine QueryFusion = ""
+	// QueryFusionScores searches once per query vector, concurrently, and
+	// ranks records by the weighted sum of their scores. A record missing
+	// from the results of a query scores 0 for it.
+	QueryFusionScores QueryFusion = "scores"
+)
+
+// QueryVector is one of several weighted query vectors in
+// SearchOptions.Queries.
+type QueryVector struct {
+	Vector []float32
+	// Weight is the share of this vector in the query (default 1).
+	Weight float64
+}
+
+// SearchQueries runs a search whose opts set Queries, on behalf of
+// collection's SearchByVector, which calls it before anything else:
+//
+//	if len(opts.Queries) > 0 {
+//		return vectordata.SearchQueries(ctx, c, vector, topK, opts)
+//	}
+//
+// The query vectors replace vector, which must be empty. Every search it
+// runs has Queries cleared and otherwise keeps opts, so filters, thresholds,
+// negatives and score normalization apply per query. With
+// QueryFusionScores, Distance is the smallest distance to any query.
+func SearchQueries(ctx context.Context, collection Collection, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	if err := cursorRanking(opts); err != nil {
+		return nil, err
+	}
+	queries := opts.Queries
+	if len(vector) > 0 {
+		return nil, fmt.Errorf("%w: pass either a query vector or SearchOptions.Queries", ErrSchemaMismatch)
+	}
+	weights := make([]float64, len(queries))
+	for i, query := range queries {
+		switch {
+		case query.Weight < 0:
+			return nil, fmt.Errorf("%w: query vector %d has weight %v, must be >= 0", ErrSchemaMismatch, i, query.Weight)
+		case query.Weight == 0:
+			weights[i] = 1
+		default:
+			weights[i] = query.Weight
+		}
+		if len(query.Vector) != len(queries[0].Vector) {
+			return nil, fmt.Errorf("%w: query vector %d has dimension %d, query vector 0 has %d", ErrDimensionMismatch, i, len(query.Vector), len(queries[0].Vector))
+		}
+	}
+	opts.Queries = nil
+
+	switch opts.QueryFusion {
+	case QueryFusionCombine:
+		return collection.SearchByVector(ctx, combineQueries(queries, weights), topK, opts)
+	case QueryFusionScores:
+		perQuery := make([][]SearchResult, len(queries))
+		errs := fanOut(ctx, repeat(collection, len(queries)), func(ctx context.Context, i int, collection Collection) error {
+			var err error
+			perQuery[i], err = collection.SearchByVector(ctx, queries[i].Vector, topK, opts)
+			return err
+		})
+		for i, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("query vector %d: %w", i, err)
+			}
+		}
+		return fuseScores(perQuery, weights, topK, opts.OrderBy)
+	default:
+		return nil, fmt.Errorf("%w: unsupported query fusion %q", ErrSchemaMismatch, opts.QueryFusion)
+	}
+}
+
+func combineQueries(queries []QueryVector, weights []float64) []float32 {
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	out := make([]float64, len(queries[0].Vector))
+	for i, query := range queries {
+		for j, component := range query.Vector {
+			out[j] += weights[i] / total * float64(component)
+		}
+	}
+	return Float32Vector(out)
+}
+
+// fuseScores sums the weighted scores of each record across queries and
+// returns the topK best. Ties are ordered by orderBy, or keep first-seen
+// order without it.
+func fuseScores(perQuery [][]SearchResult, weights []float64, topK int, orderBy []OrderSpec) ([]SearchResult, error) {
+	at := make(map[string]int)
+	var fused []SearchResult
+	for i, results := range perQuery {
+		for _, result := range results {
+			j, ok := at[result.Record.ID]
+			if !ok {
+				at[result.Record.ID] = len(fused)
+				result.Score *= weights[i]
+				fused = append(fused, result)
+				continue
+			}
+			fused[j].Score += weights[i] * result.Score
+			fused[j].Distance = min(fused[j].Distance, result.Distance)
+		}
+	}
+	if err := sortByScore(fused, orderBy); err != nil {
+		return nil, err
+	}
+	if len(fused) > topK {
+		fused = fused[:topK]
+	}
+	return fused, nil
+}
+
+func repeat(collection Collection, n int) []Collection {
+	out := make([]Collection, n)
+	for i := range out {
+		out[i] = collection
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// queryRecordingCollection answers fixed results per query vector and
+// records the vectors it was searched with.
+type queryRecordingCollection struct {
+	Collection
+	results map[float32][]SearchResult
+
+	mu      sync.Mutex
+	vectors [][]float32
+}
+
+func (c *queryRecordingCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	if len(opts.Queries) > 0 {
+		return SearchQueries(ctx, c, vector, topK, opts)
+	}
+	c.mu.Lock()
+	c.vectors = append(c.vectors, vector)
+	c.mu.Unlock()
+	return c.results[vector[0]], nil
+}
+
+func TestSearchQueriesCombinesWeightedMean(t *testing.T) {
+	// Arrange
+	collection := &queryRecordingCollection{}
+	opts := SearchOptions{Queries: []QueryVector{{Vector: []float32{1, 0}, Weight: 0.75}, {Vector: []float32{0, 1}, Weight: 0.25}}}
+
+	// Act
+	_, err := collection.SearchByVector(context.Background(), nil, 3, opts)
+
+	// Assert
+	if err != nil || !reflect.DeepEqual(collection.vectors, [][]float32{{0.75, 0.25}}) {
+		t.Fatalf("expected one search with [0.75 0.25], got %v (%v)", collection.vectors, err)
+	}
+}
+
+func TestSearchQueriesFusesWeightedScores(t *testing.T) {
+	// Arrange
+	result := func(id string, distance, score float64) SearchResult {
+		return SearchResult{Record: Record{ID: id}, Distance: distance, Score: score}
+	}
+	collection := &queryRecordingCollection{results: map[float32][]SearchResult{
+		1: {result("a", 0.1, 0.9), result("b", 0.5, 0.5)},
+		2: {result("b", 0.2, 0.8), result("c", 0.3, 0.7)},
+	}}
+	opts := SearchOptions{
+		Queries:     []QueryVector{{Vector: []float32{1}, Weight: 0.5}, {Vector: []float32{2}}},
+		QueryFusion: QueryFusionScores,
+	}
+
+	// Act
+	results, err := collection.SearchByVector(context.Background(), nil, 2, opts)
+
+	// Assert
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected 2 results, got %v (%v)", results, err)
+	}
+	if results[0].Record.ID != "b" || results[0].Score != 1.05 || results[0].Distance != 0.2 {
+		t.Fatalf("expected b first with score 1.05 and distance 0.2, got %+v", results[0])
+	}
+	if results[1].Record.ID != "c" || results[1].Score != 0.7 {
+		t.Fatalf("expected c second with score 0.7, got %+v", results[1])
+	}
+}
+
+func TestSearchQueriesValidation(t *testing.T) {
+	// Arrange
+	collection := &queryRecordingCollection{}
+	ctx := context.Background()
+	queries := []QueryVector{{Vector: []float32{1, 0}}, {Vector: []float32{0, 1}}}
+
+	// Act
+	_, vectorErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, SearchOptions{Queries: queries})
+	_, dimErr := collection.SearchByVector(ctx, nil, 1, SearchOptions{Queries: append(queries, QueryVector{Vector: []float32{1}})})
+	_, weightErr := collection.SearchByVector(ctx, nil, 1, SearchOptions{Queries: []QueryVector{{Vector: []float32{1}, Weight: -1}}})
+	_, fusionErr := collection.SearchByVector(ctx, nil, 1, SearchOptions{Queries: queries, QueryFusion: "max"})
+
+	// Assert
+	if !errors.Is(vectorErr, ErrSchemaMismatch) || !errors.Is(weightErr, ErrSchemaMismatch) || !errors.Is(fusionErr, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v, %v, %v", vectorErr, weightErr, fusionErr)
+	}
+	if !errors.Is(dimErr, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch, got %v", dimErr)
+	}
+	if len(collection.vectors) != 0 {
+		t.Fatalf("expected no searches, got %v", collection.vectors)
+	}
+}
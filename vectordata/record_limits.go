@@ -0,0 +1,113 @@
+package vectordata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// RecordLimits validates records in a store's write path, before anything is
+// sent to the backend. Zero or negative limits and nil fields disable their
+// check.
+type RecordLimits struct {
+	// MaxIDLength is the maximum ID length in bytes.
+	MaxIDLength int
+	// IDPattern must match every ID, e.g. regexp.MustCompile(`^[A-Za-z0-9_-]+$`).
+	IDPattern *regexp.Regexp
+	// MaxMetadataBytes is the maximum size of the JSON-encoded metadata.
+	MaxMetadataBytes int
+	// MaxContentLength is the maximum content length in bytes.
+	MaxContentLength int
+	// RejectNonFinite rejects vectors with NaN or infinite components.
+	RejectNonFinite bool
+	// Validate runs after the built-in checks, for application rules.
+	Validate func(Record) error
+}
+
+// Check validates records and returns every failure as a *RecordError,
+// joined, or nil.
+func (l RecordLimits) Check(records []Record) error {
+	var errs []error
+	for i, record := range records {
+		if err := l.check(record); err != nil {
+			err.Index, err.ID = i, record.ID
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l RecordLimits) check(record Record) *RecordError {
+	if l.MaxIDLength > 0 && len(record.ID) > l.MaxIDLength {
+		return &RecordError{Field: "id", Reason: fmt.Sprintf("%d bytes exceeds limit of %d", len(record.ID), l.MaxIDLength), tooLarge: true}
+	}
+	if l.IDPattern != nil && !l.IDPattern.MatchString(record.ID) {
+		return &RecordError{Field: "id", Reason: fmt.Sprintf("does not match %s", l.IDPattern)}
+	}
+	if l.MaxMetadataBytes > 0 && record.Metadata != nil {
+		encoded, err := json.Marshal(record.Metadata)
+		if err != nil {
+			return &RecordError{Field: "metadata", Reason: "cannot be encoded", Err: err}
+		}
+		if len(encoded) > l.MaxMetadataBytes {
+			return &RecordError{Field: "metadata", Reason: fmt.Sprintf("%d bytes exceeds limit of %d", len(encoded), l.MaxMetadataBytes), tooLarge: true}
+		}
+	}
+	if l.MaxContentLength > 0 && record.Content != nil && len(*record.Content) > l.MaxContentLength {
+		return &RecordError{Field: "content", Reason: fmt.Sprintf("%d bytes exceeds limit of %d", len(*record.Content), l.MaxContentLength), tooLarge: true}
+	}
+	if l.RejectNonFinite {
+		for i, component := range record.Vector {
+			if math.IsNaN(float64(component)) || math.IsInf(float64(component), 0) {
+				return &RecordError{Field: "vector", Reason: fmt.Sprintf("component %d is %v", i, component)}
+			}
+		}
+	}
+	if l.Validate != nil {
+		if err := l.Validate(record); err != nil {
+			return &RecordError{Reason: "rejected by validator", Err: err}
+		}
+	}
+	return nil
+}
+
+// RecordError reports an invalid record of a write batch. It wraps
+// ErrInvalidRecord, ErrTooLarge when a size limit was exceeded, and the
+// validator's error if any.
+type RecordError struct {
+	// Index is the position of the record in the batch.
+	Index int
+	ID    string
+	// Field is "id", "metadata", "content" or "vector", or empty for
+	// validator errors.
+	Field  string
+	Reason string
+	Err    error
+
+	tooLarge bool
+}
+
+func (e *RecordError) Error() string {
+	msg := fmt.Sprintf("record %d (%q)", e.Index, e.ID)
+	if e.Field != "" {
+		msg += " " + e.Field
+	}
+	msg += ": " + e.Reason
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *RecordError) Unwrap() []error {
+	errs := []error{ErrInvalidRecord}
+	if e.tooLarge {
+		errs = append(errs, ErrTooLarge)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
@@ -0,0 +1,105 @@
+package vectordata
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRecordLimits_Check(t *testing.T) {
+	// Arrange
+	limits := RecordLimits{
+		MaxIDLength:      8,
+		IDPattern:        regexp.MustCompile(`^[a-z0-9-]+$`),
+		MaxMetadataBytes: 20,
+		MaxContentLength: 5,
+		RejectNonFinite:  true,
+	}
+	content := "too long"
+	records := []Record{
+		{ID: "ok", Vector: []float32{1, 0}, Metadata: map[string]any{"a": 1}},
+		{ID: "much-too-long", Vector: []float32{1, 0}},
+		{ID: "Bad ID", Vector: []float32{1, 0}},
+		{ID: "meta", Vector: []float32{1, 0}, Metadata: map[string]any{"tags": "abcdefghijklmnop"}},
+		{ID: "content", Vector: []float32{1, 0}, Content: &content},
+		{ID: "nan", Vector: []float32{1, float32(math.NaN())}},
+		{ID: "inf", Vector: []float32{float32(math.Inf(1)), 0}},
+	}
+
+	// Act
+	err := limits.Check(records)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+	var got []string
+	for _, joined := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var recordErr *RecordError
+		if !errors.As(joined, &recordErr) {
+			t.Fatalf("expected *RecordError, got %T", joined)
+		}
+		got = append(got, recordErr.ID+":"+recordErr.Field)
+	}
+	want := []string{"much-too-long:id", "Bad ID:id", "meta:metadata", "content:content", "nan:vector", "inf:vector"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected failures %v, got %v", want, got)
+	}
+}
+
+func TestRecordLimits_SizeFailuresWrapErrTooLarge(t *testing.T) {
+	// Arrange
+	limits := RecordLimits{MaxIDLength: 2, IDPattern: regexp.MustCompile(`^[a-z]+$`)}
+
+	// Act
+	tooLong := limits.Check([]Record{{ID: "abc"}})
+	badCharset := limits.Check([]Record{{ID: "A"}})
+
+	// Assert
+	var recordErr *RecordError
+	if !errors.As(tooLong, &recordErr) || recordErr.Index != 0 || !errors.Is(tooLong, ErrTooLarge) {
+		t.Fatalf("expected oversized ID to wrap ErrTooLarge, got %v", tooLong)
+	}
+	if errors.Is(badCharset, ErrTooLarge) || !errors.Is(badCharset, ErrInvalidRecord) {
+		t.Fatalf("expected charset failure to wrap only ErrInvalidRecord, got %v", badCharset)
+	}
+}
+
+func TestRecordLimits_Validate(t *testing.T) {
+	// Arrange
+	errNoTenant := errors.New("tenant is required")
+	limits := RecordLimits{Validate: func(record Record) error {
+		if _, ok := record.Metadata["tenant"]; !ok {
+			return errNoTenant
+		}
+		return nil
+	}}
+
+	// Act
+	err := limits.Check([]Record{{ID: "a", Metadata: map[string]any{"tenant": "t1"}}, {ID: "b"}})
+
+	// Assert
+	var recordErr *RecordError
+	if !errors.As(err, &recordErr) || recordErr.Index != 1 || !errors.Is(err, errNoTenant) {
+		t.Fatalf("expected record 1 to fail with the validator error, got %v", err)
+	}
+	if want := `record 1 ("b"): rejected by validator: tenant is required`; err.Error() != want {
+		t.Fatalf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestRecordLimits_ZeroValueAcceptsEverything(t *testing.T) {
+	// Arrange
+	content := strings.Repeat("x", 1<<16)
+	records := []Record{{ID: strings.Repeat("id", 1000), Vector: []float32{float32(math.NaN())}, Content: &content}}
+
+	// Act
+	err := RecordLimits{}.Check(records)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package vectordata
+
+import (
+	"context"
+	"maps"
+	"regexp"
+)
+
+const defaultRedactionReplacement = "[REDACTED]"
+
+var (
+	// EmailPattern matches email addresses, for Redaction.ContentPatterns.
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// PhoneNumberPattern matches phone numbers whose groups are separated by
+	// spaces, dots or dashes, optionally with a +country code or an area
+	// code in parentheses, e.g. "+44 20 7946 0958" or "(555) 123-4567".
+	// Numbers written without separators are not matched.
+	PhoneNumberPattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{2,4}\)[\s.-]?|\d{2,4}[\s.-])\d{3,4}[\s.-]?\d{3,4}`)
+)
+
+// Redaction masks sensitive values in returned records, so consumers that
+// must not see them can share collections with those that may. Set it as
+// Projection.Redact for one search, or wrap a collection with Middleware to
+// enforce it for every read of a handle. Stored records are never changed.
+type Redaction struct {
+	// MetadataKeys are top-level metadata keys whose values are replaced.
+	MetadataKeys []string
+	// ContentPatterns are replaced wherever they match in content and
+	// snippets, e.g. EmailPattern and PhoneNumberPattern.
+	ContentPatterns []*regexp.Regexp
+	// Replacement replaces redacted values and matches (default
+	// "[REDACTED]").
+	Replacement string
+}
+
+func (r *Redaction) replacement() string {
+	if r.Replacement == "" {
+		return defaultRedactionReplacement
+	}
+	return r.Replacement
+}
+
+func (r *Redaction) redactText(text string) string {
+	for _, pattern := range r.ContentPatterns {
+		text = pattern.ReplaceAllLiteralString(text, r.replacement())
+	}
+	return text
+}
+
+// Record returns record with its metadata keys and content matches
+// replaced. Metadata is copied before it is changed. A nil Redaction returns
+// record unchanged.
+func (r *Redaction) Record(record Record) Record {
+	if r == nil {
+		return record
+	}
+	if record.Metadata != nil && len(r.MetadataKeys) > 0 {
+		metadata := maps.Clone(record.Metadata)
+		for _, key := range r.MetadataKeys {
+			if _, ok := metadata[key]; ok {
+				metadata[key] = r.replacement()
+			}
+		}
+		record.Metadata = metadata
+	}
+	if record.Content != nil && len(r.ContentPatterns) > 0 {
+		content := r.redactText(*record.Content)
+		record.Content = &content
+	}
+	return record
+}
+
+// Result returns result with Record applied to its record and the content
+// patterns replaced in its snippets. A nil Redaction returns result
+// unchanged.
+func (r *Redaction) Result(result SearchResult) SearchResult {
+	if r == nil {
+		return result
+	}
+	result.Record = r.Record(result.Record)
+	if len(result.Snippets) > 0 && len(r.ContentPatterns) > 0 {
+		snippets := make([]string, len(result.Snippets))
+		for i, snippet := range result.Snippets {
+			snippets[i] = r.redactText(snippet)
+		}
+		result.Snippets = snippets
+	}
+	return result
+}
+
+// RedactResults applies the Redact of projection, if any, to results in
+// place and returns them. Stores call it on the results of reads that take a
+// Projection.
+func RedactResults(projection *Projection, results []SearchResult) []SearchResult {
+	if projection == nil || projection.Redact == nil {
+		return results
+	}
+	for i := range results {
+		results[i] = projection.Redact.Result(results[i])
+	}
+	return results
+}
+
+// RedactRecords is RedactResults for records, e.g. pages of a List.
+func RedactRecords(projection *Projection, records []Record) []Record {
+	if projection == nil || projection.Redact == nil {
+		return records
+	}
+	for i := range records {
+		records[i] = projection.Redact.Record(records[i])
+	}
+	return records
+}
+
+// Middleware returns a collection middleware that applies r to the results
+// of Get and of every search, whatever projection the caller asks for.
+func (r *Redaction) Middleware() CollectionMiddleware {
+	return func(ctx context.Context, call *Call, next CallHandler) error {
+		if err := next(ctx, call); err != nil {
+			return err
+		}
+		switch call.Operation {
+		case OpGet:
+			call.Record = r.Record(call.Record)
+		case OpSearchByVector, OpSearchByText, OpHybridSearch:
+			for i := range call.Results {
+				call.Results[i] = r.Result(call.Results[i])
+			}
+		}
+		return nil
+	}
+}
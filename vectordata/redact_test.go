@@ -0,0 +1,79 @@
+package vectordata
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestRedaction_MasksMetadataKeysAndContentPatterns(t *testing.T) {
+	// Arrange
+	redaction := &Redaction{
+		MetadataKeys:    []string{"owner", "missing"},
+		ContentPatterns: []*regexp.Regexp{EmailPattern, PhoneNumberPattern, regexp.MustCompile(`ACCT-\d+`)},
+	}
+	content := "Mail ana@example.com or call +44 20 7946 0958 about ACCT-42 on 2024-06-01."
+	metadata := map[string]any{"owner": "ana", "kind": "ticket"}
+	result := SearchResult{
+		Record:   Record{ID: "a", Metadata: metadata, Content: &content},
+		Snippets: []string{"call (555) 123-4567"},
+	}
+
+	// Act
+	redacted := redaction.Result(result)
+
+	// Assert
+	want := "Mail [REDACTED] or call [REDACTED] about [REDACTED] on 2024-06-01."
+	if *redacted.Record.Content != want {
+		t.Fatalf("unexpected content %q", *redacted.Record.Content)
+	}
+	if redacted.Record.Metadata["owner"] != "[REDACTED]" || redacted.Record.Metadata["kind"] != "ticket" {
+		t.Fatalf("unexpected metadata %v", redacted.Record.Metadata)
+	}
+	if _, ok := redacted.Record.Metadata["missing"]; ok {
+		t.Fatal("expected absent keys to stay absent")
+	}
+	if redacted.Snippets[0] != "call [REDACTED]" {
+		t.Fatalf("unexpected snippet %q", redacted.Snippets[0])
+	}
+	if metadata["owner"] != "ana" || result.Snippets[0] != "call (555) 123-4567" {
+		t.Fatal("expected the input to be left unchanged")
+	}
+}
+
+func TestRedactResults_WithoutRedactionKeepsResults(t *testing.T) {
+	// Arrange
+	content := "ana@example.com"
+	results := []SearchResult{{Record: Record{ID: "a", Content: &content}}}
+
+	// Act
+	got := RedactResults(&Projection{IncludeContent: true}, results)
+	fromNil := RedactResults(nil, results)
+
+	// Assert
+	if *got[0].Record.Content != content || *fromNil[0].Record.Content != content {
+		t.Fatal("expected content to be returned as is")
+	}
+}
+
+func TestRedaction_MiddlewareEnforcesRedaction(t *testing.T) {
+	// Arrange
+	content := "reach me at ana@example.com"
+	base := &mapCollection{dimension: 2, records: map[string]Record{"a": {ID: "a", Content: &content}}}
+	redaction := &Redaction{ContentPatterns: []*regexp.Regexp{EmailPattern}, Replacement: "***"}
+	collection := WrapCollection(base, redaction.Middleware())
+
+	// Act
+	record, err := collection.Get(context.Background(), "a")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if *record.Content != "reach me at ***" {
+		t.Fatalf("unexpected content %q", *record.Content)
+	}
+	if *base.records["a"].Content != content {
+		t.Fatal("expected the stored record to be left unchanged")
+	}
+}
@@ -0,0 +1,26 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Reindexer is implemented by collections that can replace their vector
+// index without a window in which searches have no index.
+type Reindexer interface {
+	Reindex(ctx context.Context, opts VectorIndexOptions) error
+}
+
+// Reindex rebuilds the vector index of collection with opts, e.g. to change
+// HNSW parameters on a live collection. The index named by opts (or the
+// store's default name) is replaced once the new one is built; until then,
+// searches use the old one. Collections that are not Reindexers fail with
+// errors.ErrUnsupported: EnsureIndexes leaves existing indexes as they are.
+func Reindex(ctx context.Context, collection Collection, opts VectorIndexOptions) error {
+	reindexer, ok := collection.(Reindexer)
+	if !ok {
+		return fmt.Errorf("%w: collection %q does not support Reindex", errors.ErrUnsupported, collection.Name())
+	}
+	return reindexer.Reindex(ctx, opts)
+}
@@ -0,0 +1,20 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReindexRequiresReindexer(t *testing.T) {
+	// Arrange
+	collection := &mapCollection{dimension: 2, records: map[string]Record{}}
+
+	// Act
+	err := Reindex(context.Background(), collection, VectorIndexOptions{})
+
+	// Assert
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
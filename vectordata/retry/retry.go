@@ -0,0 +1,149 @@
+// Package retry retries idempotent vectordata collection operations that fail
+// with transient errors.
+//
+//	collection = retry.Collection(collection, retry.Policy{
+//		IsRetryable: func(err error) bool { return errors.Is(err, errOverloaded) },
+//	})
+//
+// Only Get, Count and the searches are retried; writes pass through once,
+// since a write whose reply was lost may have been applied. Backends with
+// their own retries (e.g. postgres.StoreOptions.Retry) know which writes are
+// safe to repeat.
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 50 * time.Millisecond
+	defaultMaxBackoff     = 2 * time.Second
+)
+
+// Policy configures retries.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// (default 3).
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; each further retry
+	// doubles it up to MaxBackoff (defaults 50ms and 2s).
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// IsRetryable classifies errors as transient (default
+	// DefaultIsRetryable).
+	IsRetryable func(err error) bool
+}
+
+// DefaultIsRetryable retries errors vectordata.IsRetryable accepts and
+// uncategorized errors. It rejects context errors, errors.ErrUnsupported and
+// the other vectordata errors, which describe the request or a state retrying
+// cannot fix.
+func DefaultIsRetryable(err error) bool {
+	if vectordata.IsRetryable(err) {
+		return true
+	}
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, vectordata.ErrNotFound),
+		errors.Is(err, vectordata.ErrDimensionMismatch),
+		errors.Is(err, vectordata.ErrSchemaMismatch),
+		errors.Is(err, vectordata.ErrInvalidFilter),
+		errors.Is(err, vectordata.ErrInvalidRecord),
+		errors.Is(err, vectordata.ErrNotReady),
+		errors.Is(err, vectordata.ErrConflict),
+		errors.Is(err, vectordata.ErrTooLarge),
+		errors.Is(err, vectordata.ErrInvalidCursor),
+		errors.Is(err, errors.ErrUnsupported):
+		return false
+	default:
+		return true
+	}
+}
+
+// Collection returns base with idempotent operations retried under policy.
+func Collection(base vectordata.Collection, policy Policy) vectordata.Collection {
+	return vectordata.WrapCollection(base, Middleware(policy))
+}
+
+// Middleware returns a collection middleware retrying idempotent operations
+// under policy. Zero or negative fields take their defaults.
+func Middleware(policy Policy) vectordata.CollectionMiddleware {
+	policy = policy.withDefaults()
+	return func(ctx context.Context, call *vectordata.Call, next vectordata.CallHandler) error {
+		if !idempotent(call.Operation) {
+			return next(ctx, call)
+		}
+		return policy.do(ctx, func() error { return next(ctx, call) })
+	}
+}
+
+func idempotent(op vectordata.Operation) bool {
+	switch op {
+	case vectordata.OpGet, vectordata.OpCount, vectordata.OpSearchByVector, vectordata.OpSearchByText, vectordata.OpHybridSearch:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.IsRetryable == nil {
+		p.IsRetryable = DefaultIsRetryable
+	}
+	return p
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// do runs fn until it succeeds, fails with an error that is not retryable,
+// or runs out of attempts. It stops when the context is done or its deadline
+// would pass before the next attempt, returning the last error.
+func (p Policy) do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !p.IsRetryable(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
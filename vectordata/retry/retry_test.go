@@ -0,0 +1,187 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// flakyCollection fails the first failures calls of each operation.
+type flakyCollection struct {
+	vectordata.Collection
+	failures int
+	err      error
+	calls    map[string]int
+}
+
+func newFlakyCollection(failures int, err error) *flakyCollection {
+	return &flakyCollection{failures: failures, err: err, calls: map[string]int{}}
+}
+
+func (c *flakyCollection) attempt(op string) error {
+	c.calls[op]++
+	if c.calls[op] <= c.failures {
+		return c.err
+	}
+	return nil
+}
+
+func (c *flakyCollection) Get(_ context.Context, id string) (vectordata.Record, error) {
+	if err := c.attempt("get"); err != nil {
+		return vectordata.Record{}, err
+	}
+	return vectordata.Record{ID: id}, nil
+}
+
+func (c *flakyCollection) Count(context.Context, vectordata.Filter) (int64, error) {
+	if err := c.attempt("count"); err != nil {
+		return 0, err
+	}
+	return 3, nil
+}
+
+func (c *flakyCollection) SearchByVector(context.Context, []float32, int, vectordata.SearchOptions) ([]vectordata.SearchResult, error) {
+	if err := c.attempt("search"); err != nil {
+		return nil, err
+	}
+	return []vectordata.SearchResult{{Record: vectordata.Record{ID: "r1"}}}, nil
+}
+
+func (c *flakyCollection) Upsert(context.Context, []vectordata.Record) error {
+	return c.attempt("upsert")
+}
+
+var fastPolicy = Policy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestCollection_RetriesIdempotentOperations(t *testing.T) {
+	// Arrange
+	base := newFlakyCollection(2, errUnavailable)
+	collection := Collection(base, fastPolicy)
+	ctx := context.Background()
+
+	// Act
+	record, getErr := collection.Get(ctx, "r1")
+	count, countErr := collection.Count(ctx, nil)
+	results, searchErr := collection.SearchByVector(ctx, []float32{1}, 1, vectordata.SearchOptions{})
+
+	// Assert
+	if getErr != nil || countErr != nil || searchErr != nil {
+		t.Fatalf("expected retries to succeed, got %v, %v, %v", getErr, countErr, searchErr)
+	}
+	if record.ID != "r1" || count != 3 || len(results) != 1 {
+		t.Fatalf("unexpected results: %+v, %d, %+v", record, count, results)
+	}
+	if base.calls["get"] != 3 || base.calls["count"] != 3 || base.calls["search"] != 3 {
+		t.Fatalf("expected three attempts per operation, got %v", base.calls)
+	}
+}
+
+func TestCollection_DoesNotRetryWrites(t *testing.T) {
+	// Arrange
+	base := newFlakyCollection(1, errUnavailable)
+	collection := Collection(base, fastPolicy)
+
+	// Act
+	err := collection.Upsert(context.Background(), []vectordata.Record{{ID: "r1"}})
+
+	// Assert
+	if !errors.Is(err, errUnavailable) || base.calls["upsert"] != 1 {
+		t.Fatalf("expected a single failed upsert, got %v after %d calls", err, base.calls["upsert"])
+	}
+}
+
+func TestCollection_StopsAtMaxAttempts(t *testing.T) {
+	// Arrange
+	base := newFlakyCollection(10, errUnavailable)
+	policy := fastPolicy
+	policy.MaxAttempts = 4
+	collection := Collection(base, policy)
+
+	// Act
+	_, err := collection.Get(context.Background(), "r1")
+
+	// Assert
+	if !errors.Is(err, errUnavailable) || base.calls["get"] != 4 {
+		t.Fatalf("expected the last error after 4 attempts, got %v after %d", err, base.calls["get"])
+	}
+}
+
+func TestCollection_UsesClassifier(t *testing.T) {
+	// Arrange
+	base := newFlakyCollection(1, fmt.Errorf("wrapped: %w", errUnavailable))
+	policy := fastPolicy
+	policy.IsRetryable = func(err error) bool { return !errors.Is(err, errUnavailable) }
+	collection := Collection(base, policy)
+
+	// Act
+	_, err := collection.Count(context.Background(), nil)
+
+	// Assert
+	if !errors.Is(err, errUnavailable) || base.calls["count"] != 1 {
+		t.Fatalf("expected the classifier to stop retries, got %v after %d calls", err, base.calls["count"])
+	}
+}
+
+func TestCollection_RespectsDeadline(t *testing.T) {
+	// Arrange
+	base := newFlakyCollection(10, errUnavailable)
+	collection := Collection(base, Policy{MaxAttempts: 5, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Act
+	started := time.Now()
+	_, err := collection.Get(ctx, "r1")
+
+	// Assert
+	if !errors.Is(err, errUnavailable) || base.calls["get"] != 1 || time.Since(started) > 500*time.Millisecond {
+		t.Fatalf("expected no retry past the deadline, got %v after %d calls", err, base.calls["get"])
+	}
+}
+
+func TestDefaultIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errUnavailable, true},
+		{fmt.Errorf("get: %w", vectordata.ErrNotFound), false},
+		{vectordata.ErrInvalidFilter, false},
+		{vectordata.ErrDimensionMismatch, false},
+		{fmt.Errorf("%w: duplicate key", vectordata.ErrConflict), false},
+		{vectordata.ErrTooLarge, false},
+		{fmt.Errorf("%w: malformed cursor", vectordata.ErrInvalidCursor), false},
+		{fmt.Errorf("%w: cursors cannot be combined with OrderBy", errors.ErrUnsupported), false},
+		{fmt.Errorf("%w: connection reset", vectordata.ErrUnavailable), true},
+		{fmt.Errorf("%w: %w", vectordata.ErrTimeout, context.DeadlineExceeded), false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tc := range cases {
+		if got := DefaultIsRetryable(tc.err); got != tc.want {
+			t.Fatalf("DefaultIsRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	// Arrange
+	policy := Policy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond}.withDefaults()
+
+	// Act
+	delays := []time.Duration{policy.backoff(1), policy.backoff(2), policy.backoff(3), policy.backoff(4)}
+
+	// Assert
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond, 35 * time.Millisecond}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("backoff(%d) = %v, want %v", i+1, delays[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package vectordata
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScoreNormalizationMethod selects how SearchByVector rescales scores.
+type ScoreNormalizationMethod string
+
+const (
+	// ScoreNormalizationNone keeps ScoreFromDistance scores.
+	ScoreNormalizationNone ScoreNormalizationMethod = ""
+	// ScoreNormalizationMinMax rescales the scores of a result set to
+	// [0, 1]: the best result scores 1 and the worst 0. Scores are only
+	// comparable within one result set.
+	ScoreNormalizationMinMax ScoreNormalizationMethod = "min_max"
+	// ScoreNormalizationSigmoid maps the metric's similarity (1 - distance
+	// for cosine, -distance for L2 and inner product) through a logistic
+	// function with the configured temperature, into (0, 1).
+	ScoreNormalizationSigmoid ScoreNormalizationMethod = "sigmoid"
+	// ScoreNormalizationCosine converts distances to the cosine similarity
+	// they imply for unit-length vectors, in [-1, 1]: 1 - d²/2 for L2 and
+	// -distance for inner product. It is only meaningful when stored and
+	// query vectors are normalized.
+	ScoreNormalizationCosine ScoreNormalizationMethod = "cosine_equivalent"
+)
+
+const defaultSigmoidTemperature = 1.0
+
+// ScoreNormalization configures score rescaling in SearchOptions.
+type ScoreNormalization struct {
+	Method ScoreNormalizationMethod
+	// Temperature scales similarities before ScoreNormalizationSigmoid;
+	// lower values spread scores apart (default 1).
+	Temperature float64
+}
+
+// Validate reports unsupported methods and negative temperatures.
+func (n ScoreNormalization) Validate() error {
+	switch n.Method {
+	case ScoreNormalizationNone, ScoreNormalizationMinMax, ScoreNormalizationSigmoid, ScoreNormalizationCosine:
+	default:
+		return fmt.Errorf("%w: unsupported score normalization %q", ErrSchemaMismatch, n.Method)
+	}
+	if n.Temperature < 0 {
+		return fmt.Errorf("%w: score normalization temperature must be >= 0", ErrSchemaMismatch)
+	}
+	return nil
+}
+
+// NormalizeScores rewrites the scores of results, ranked by distance under
+// metric, as configured by n. Distances are kept. Results are returned for
+// convenience.
+func NormalizeScores(metric DistanceMetric, results []SearchResult, n ScoreNormalization) []SearchResult {
+	metric = normalizeMetric(metric)
+	switch n.Method {
+	case ScoreNormalizationMinMax:
+		if len(results) == 0 {
+			return results
+		}
+		low, high := results[0].Score, results[0].Score
+		for _, result := range results {
+			low, high = min(low, result.Score), max(high, result.Score)
+		}
+		for i := range results {
+			if high == low {
+				results[i].Score = 1
+				continue
+			}
+			results[i].Score = (results[i].Score - low) / (high - low)
+		}
+	case ScoreNormalizationSigmoid:
+		temperature := n.Temperature
+		if temperature == 0 {
+			temperature = defaultSigmoidTemperature
+		}
+		for i := range results {
+			results[i].Score = 1 / (1 + math.Exp(-similarity(metric, results[i].Distance)/temperature))
+		}
+	case ScoreNormalizationCosine:
+		for i := range results {
+			results[i].Score = cosineEquivalent(metric, results[i].Distance)
+		}
+	}
+	return results
+}
+
+func similarity(metric DistanceMetric, distance float64) float64 {
+	if metric == DistanceCosine {
+		return 1 - distance
+	}
+	return -distance
+}
+
+func cosineEquivalent(metric DistanceMetric, distance float64) float64 {
+	switch metric {
+	case DistanceL2:
+		return 1 - distance*distance/2
+	case DistanceInnerProduct:
+		return -distance
+	default:
+		return 1 - distance
+	}
+}
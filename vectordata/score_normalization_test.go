@@ -0,0 +1,93 @@
+package vectordata
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func distances(metric DistanceMetric, values ...float64) []SearchResult {
+	results := make([]SearchResult, len(values))
+	for i, distance := range values {
+		results[i] = SearchResult{Distance: distance, Score: ScoreFromDistance(metric, distance)}
+	}
+	return results
+}
+
+func assertScores(t *testing.T, results []SearchResult, want ...float64) {
+	t.Helper()
+	for i := range want {
+		if math.Abs(results[i].Score-want[i]) > 1e-9 {
+			t.Fatalf("score %d: want %v, got %v", i, want[i], results[i].Score)
+		}
+	}
+}
+
+func TestNormalizeScores_MinMax(t *testing.T) {
+	// Arrange
+	results := distances(DistanceL2, 0, 1, 3)
+
+	// Act
+	NormalizeScores(DistanceL2, results, ScoreNormalization{Method: ScoreNormalizationMinMax})
+
+	// Assert
+	assertScores(t, results, 1, 1.0/3, 0)
+	if results[1].Distance != 1 {
+		t.Fatalf("expected distances to be kept, got %v", results[1].Distance)
+	}
+}
+
+func TestNormalizeScores_Sigmoid(t *testing.T) {
+	// Arrange
+	cosine := distances(DistanceCosine, 0, 1)
+	innerProduct := distances(DistanceInnerProduct, -2)
+
+	// Act
+	NormalizeScores(DistanceCosine, cosine, ScoreNormalization{Method: ScoreNormalizationSigmoid, Temperature: 0.5})
+	NormalizeScores(DistanceInnerProduct, innerProduct, ScoreNormalization{Method: ScoreNormalizationSigmoid})
+
+	// Assert
+	assertScores(t, cosine, 1/(1+math.Exp(-2)), 0.5)
+	assertScores(t, innerProduct, 1/(1+math.Exp(-2)))
+}
+
+func TestNormalizeScores_CosineEquivalent(t *testing.T) {
+	// Arrange: unit vectors at 90 degrees have L2 distance sqrt(2), inner
+	// product 0 and cosine distance 1.
+	l2 := distances(DistanceL2, 0, math.Sqrt2)
+	innerProduct := distances(DistanceInnerProduct, -1, 0)
+	cosine := distances(DistanceCosine, 0, 1)
+
+	// Act
+	NormalizeScores(DistanceL2, l2, ScoreNormalization{Method: ScoreNormalizationCosine})
+	NormalizeScores(DistanceInnerProduct, innerProduct, ScoreNormalization{Method: ScoreNormalizationCosine})
+	NormalizeScores(DistanceCosine, cosine, ScoreNormalization{Method: ScoreNormalizationCosine})
+
+	// Assert
+	assertScores(t, l2, 1, 0)
+	assertScores(t, innerProduct, 1, 0)
+	assertScores(t, cosine, 1, 0)
+}
+
+func TestNormalizeScores_NoneKeepsScores(t *testing.T) {
+	// Arrange
+	results := distances(DistanceL2, 1)
+
+	// Act
+	NormalizeScores(DistanceL2, results, ScoreNormalization{})
+
+	// Assert
+	assertScores(t, results, 0.5)
+}
+
+func TestScoreNormalization_Validate(t *testing.T) {
+	// Act
+	unknown := ScoreNormalization{Method: "zscore"}.Validate()
+	negative := ScoreNormalization{Method: ScoreNormalizationSigmoid, Temperature: -1}.Validate()
+	valid := ScoreNormalization{Method: ScoreNormalizationMinMax}.Validate()
+
+	// Assert
+	if !errors.Is(unknown, ErrSchemaMismatch) || !errors.Is(negative, ErrSchemaMismatch) || valid != nil {
+		t.Fatalf("unexpected validation results: %v, %v, %v", unknown, negative, valid)
+	}
+}
@@ -0,0 +1,214 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// ShardedCollection returns a collection named name that spreads records
+// across shards by a hash of their ID (see ShardFor). Insert, Upsert, Delete
+// and Get go to the shards owning the IDs; Count, EnsureIndexes and searches
+// run on every shard concurrently, searches merging the shards' topK results
+// by Score. The result implements TextSearcher and HybridSearcher when every
+// shard does.
+//
+// Shards must share a dimension and metric, and their order must not change
+// once records are written: adding or reordering shards moves IDs to other
+// shards. Writes spanning several shards are not atomic; a failure leaves the
+// other shards' part applied.
+func ShardedCollection(name string, shards []Collection) (Collection, error) {
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("%w: sharded collection %q has no shards", ErrSchemaMismatch, name)
+	}
+	for _, shard := range shards {
+		if shard.Dimension() != shards[0].Dimension() {
+			return nil, fmt.Errorf("%w: shard %q has dimension %d, expected %d", ErrDimensionMismatch, shard.Name(), shard.Dimension(), shards[0].Dimension())
+		}
+		if normalizeMetric(shard.Metric()) != normalizeMetric(shards[0].Metric()) {
+			return nil, fmt.Errorf("%w: shard %q has metric %q, expected %q", ErrSchemaMismatch, shard.Name(), shard.Metric(), shards[0].Metric())
+		}
+	}
+
+	c := &shardedCollection{name: name, shards: append([]Collection(nil), shards...)}
+	isText, isHybrid := true, true
+	for _, shard := range shards {
+		_, text := shard.(TextSearcher)
+		_, hybrid := shard.(HybridSearcher)
+		isText, isHybrid = isText && text, isHybrid && hybrid
+	}
+	switch {
+	case isText && isHybrid:
+		return struct {
+			*shardedCollection
+			shardedTextSearch
+			shardedHybridSearch
+		}{c, shardedTextSearch{c}, shardedHybridSearch{c}}, nil
+	case isText:
+		return struct {
+			*shardedCollection
+			shardedTextSearch
+		}{c, shardedTextSearch{c}}, nil
+	case isHybrid:
+		return struct {
+			*shardedCollection
+			shardedHybridSearch
+		}{c, shardedHybridSearch{c}}, nil
+	default:
+		return c, nil
+	}
+}
+
+// ShardFor returns the index of the shard owning id among n shards: the
+// 64-bit FNV-1a hash of id modulo n.
+func ShardFor(id string, n int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return int(h.Sum64() % uint64(n))
+}
+
+type shardedCollection struct {
+	name   string
+	shards []Collection
+}
+
+func (c *shardedCollection) Name() string           { return c.name }
+func (c *shardedCollection) Dimension() int         { return c.shards[0].Dimension() }
+func (c *shardedCollection) Metric() DistanceMetric { return c.shards[0].Metric() }
+
+func (c *shardedCollection) Insert(ctx context.Context, records []Record) error {
+	return c.writeRecords(ctx, records, Collection.Insert)
+}
+
+func (c *shardedCollection) Upsert(ctx context.Context, records []Record) error {
+	return c.writeRecords(ctx, records, Collection.Upsert)
+}
+
+func (c *shardedCollection) writeRecords(ctx context.Context, records []Record, write func(Collection, context.Context, []Record) error) error {
+	groups := make([][]Record, len(c.shards))
+	for _, record := range records {
+		shard := ShardFor(record.ID, len(c.shards))
+		groups[shard] = append(groups[shard], record)
+	}
+	return errors.Join(fanOut(ctx, c.shards, func(ctx context.Context, i int, shard Collection) error {
+		if len(groups[i]) == 0 {
+			return nil
+		}
+		return write(shard, ctx, groups[i])
+	})...)
+}
+
+func (c *shardedCollection) Get(ctx context.Context, id string) (Record, error) {
+	return c.shards[ShardFor(id, len(c.shards))].Get(ctx, id)
+}
+
+func (c *shardedCollection) Delete(ctx context.Context, ids []string) (int64, error) {
+	groups := make([][]string, len(c.shards))
+	for _, id := range ids {
+		shard := ShardFor(id, len(c.shards))
+		groups[shard] = append(groups[shard], id)
+	}
+	deleted := make([]int64, len(c.shards))
+	err := errors.Join(fanOut(ctx, c.shards, func(ctx context.Context, i int, shard Collection) error {
+		if len(groups[i]) == 0 {
+			return nil
+		}
+		var err error
+		deleted[i], err = shard.Delete(ctx, groups[i])
+		return err
+	})...)
+	return sum(deleted), err
+}
+
+func (c *shardedCollection) Count(ctx context.Context, filter Filter) (int64, error) {
+	counts := make([]int64, len(c.shards))
+	err := errors.Join(fanOut(ctx, c.shards, func(ctx context.Context, i int, shard Collection) error {
+		var err error
+		counts[i], err = shard.Count(ctx, filter)
+		return err
+	})...)
+	if err != nil {
+		return 0, err
+	}
+	return sum(counts), nil
+}
+
+func (c *shardedCollection) SearchByVector(ctx context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	search := func(ctx context.Context, shard Collection) ([]SearchResult, error) {
+		return shard.SearchByVector(ctx, vector, topK, opts)
+	}
+	if opts.Cursor == "" {
+		return c.search(ctx, topK, opts.OrderBy, search)
+	}
+	// Every shard pages in distance and ID order from the cursor, so their
+	// pages are merged in that order too.
+	results, err := c.search(ctx, -1, nil, search)
+	if err != nil {
+		return nil, err
+	}
+	SortResults(results)
+	return results[:min(topK, len(results))], nil
+}
+
+func (c *shardedCollection) EnsureIndexes(ctx context.Context, opts IndexOptions) error {
+	return errors.Join(fanOut(ctx, c.shards, func(ctx context.Context, _ int, shard Collection) error {
+		return shard.EnsureIndexes(ctx, opts)
+	})...)
+}
+
+// search runs fn on every shard and merges the results by Score, then
+// orderBy, then ID.
+func (c *shardedCollection) search(ctx context.Context, topK int, orderBy []OrderSpec, fn func(ctx context.Context, shard Collection) ([]SearchResult, error)) ([]SearchResult, error) {
+	perShard := make([][]SearchResult, len(c.shards))
+	err := errors.Join(fanOut(ctx, c.shards, func(ctx context.Context, i int, shard Collection) error {
+		var err error
+		perShard[i], err = fn(ctx, shard)
+		return err
+	})...)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []SearchResult
+	for _, results := range perShard {
+		merged = append(merged, results...)
+	}
+	if err := OrderResults(merged, orderBy); err != nil {
+		return nil, err
+	}
+	if topK >= 0 && len(merged) > topK {
+		merged = merged[:topK]
+	}
+	return merged, nil
+}
+
+func sum(values []int64) int64 {
+	var total int64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// shardedTextSearch and shardedHybridSearch add the optional searches to a
+// sharded collection whose shards all implement them.
+type shardedTextSearch struct {
+	c *shardedCollection
+}
+
+func (t shardedTextSearch) SearchByText(ctx context.Context, text string, topK int, opts TextSearchOptions) ([]SearchResult, error) {
+	return t.c.search(ctx, topK, nil, func(ctx context.Context, shard Collection) ([]SearchResult, error) {
+		return shard.(TextSearcher).SearchByText(ctx, text, topK, opts)
+	})
+}
+
+type shardedHybridSearch struct {
+	c *shardedCollection
+}
+
+func (h shardedHybridSearch) HybridSearch(ctx context.Context, vector []float32, text string, topK int, opts HybridSearchOptions) ([]SearchResult, error) {
+	return h.c.search(ctx, topK, nil, func(ctx context.Context, shard Collection) ([]SearchResult, error) {
+		return shard.(HybridSearcher).HybridSearch(ctx, vector, text, topK, opts)
+	})
+}
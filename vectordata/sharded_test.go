@@ -0,0 +1,222 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// shardStub keeps records in memory; searches score records by their first
+// vector component, and page through them with SearchOptions.Cursor.
+type shardStub struct {
+	Collection
+	name    string
+	records map[string]Record
+}
+
+func newShardStubs(n int) []*shardStub {
+	shards := make([]*shardStub, n)
+	for i := range shards {
+		shards[i] = &shardStub{name: fmt.Sprintf("docs_%d", i), records: map[string]Record{}}
+	}
+	return shards
+}
+
+func (s *shardStub) Name() string           { return s.name }
+func (s *shardStub) Dimension() int         { return 1 }
+func (s *shardStub) Metric() DistanceMetric { return DistanceCosine }
+
+func (s *shardStub) Upsert(_ context.Context, records []Record) error {
+	for _, record := range records {
+		s.records[record.ID] = record
+	}
+	return nil
+}
+
+func (s *shardStub) Get(_ context.Context, id string) (Record, error) {
+	record, ok := s.records[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *shardStub) Delete(_ context.Context, ids []string) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := s.records[id]; ok {
+			delete(s.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *shardStub) Count(context.Context, Filter) (int64, error) {
+	return int64(len(s.records)), nil
+}
+
+func (s *shardStub) SearchByVector(_ context.Context, vector []float32, topK int, opts SearchOptions) ([]SearchResult, error) {
+	cursor, paged, err := DecodeCursor(vector, opts)
+	if err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, record := range s.records {
+		score := float64(record.Vector[0])
+		if paged && !cursor.After(-score, record.ID) {
+			continue
+		}
+		results = append(results, SearchResult{Record: record, Distance: -score, Score: score})
+	}
+	SortResults(results)
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func asCollections(shards []*shardStub) []Collection {
+	collections := make([]Collection, len(shards))
+	for i, shard := range shards {
+		collections[i] = shard
+	}
+	return collections
+}
+
+func TestShardedCollection_RoutesByID(t *testing.T) {
+	// Arrange
+	shards := newShardStubs(4)
+	sharded, err := ShardedCollection("docs", asCollections(shards))
+	if err != nil {
+		t.Fatalf("ShardedCollection: %v", err)
+	}
+	ctx := context.Background()
+	var records []Record
+	for i := 0; i < 40; i++ {
+		records = append(records, Record{ID: fmt.Sprintf("r%d", i), Vector: []float32{float32(i)}})
+	}
+
+	// Act
+	upsertErr := sharded.Upsert(ctx, records)
+	record, getErr := sharded.Get(ctx, "r7")
+	deleted, deleteErr := sharded.Delete(ctx, []string{"r1", "r2", "missing"})
+	count, countErr := sharded.Count(ctx, nil)
+
+	// Assert
+	if upsertErr != nil || getErr != nil || deleteErr != nil || countErr != nil {
+		t.Fatalf("unexpected errors: %v, %v, %v, %v", upsertErr, getErr, deleteErr, countErr)
+	}
+	if record.ID != "r7" || deleted != 2 || count != 38 {
+		t.Fatalf("unexpected results: %+v, deleted %d, count %d", record, deleted, count)
+	}
+	for i, shard := range shards {
+		if len(shard.records) == 0 {
+			t.Fatalf("expected every shard to hold records, shard %d is empty", i)
+		}
+		for id := range shard.records {
+			if ShardFor(id, len(shards)) != i {
+				t.Fatalf("record %s stored on shard %d, expected %d", id, i, ShardFor(id, len(shards)))
+			}
+		}
+	}
+}
+
+func TestShardedCollection_MergesSearches(t *testing.T) {
+	// Arrange
+	shards := newShardStubs(3)
+	sharded, _ := ShardedCollection("docs", asCollections(shards))
+	ctx := context.Background()
+	var records []Record
+	for i := 0; i < 30; i++ {
+		records = append(records, Record{ID: fmt.Sprintf("r%d", i), Vector: []float32{float32(i)}})
+	}
+	_ = sharded.Upsert(ctx, records)
+
+	// Act
+	results, err := sharded.SearchByVector(ctx, []float32{1}, 5, SearchOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SearchByVector: %v", err)
+	}
+	want := []string{"r29", "r28", "r27", "r26", "r25"}
+	got := resultIDs(results)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestShardedCollection_PagesWithCursor(t *testing.T) {
+	// Arrange
+	shards := newShardStubs(3)
+	sharded, _ := ShardedCollection("docs", asCollections(shards))
+	ctx := context.Background()
+	var records []Record
+	for i := 0; i < 10; i++ {
+		// Pairs of records tie, so pages must break ties by ID across shards.
+		records = append(records, Record{ID: fmt.Sprintf("r%d", i), Vector: []float32{float32(i / 2)}})
+	}
+	_ = sharded.Upsert(ctx, records)
+	query := []float32{1}
+	opts := SearchOptions{}
+
+	// Act
+	var got []string
+	for range 4 {
+		page, err := sharded.SearchByVector(ctx, query, 3, opts)
+		if err != nil {
+			t.Fatalf("SearchByVector: %v", err)
+		}
+		got = append(got, resultIDs(page)...)
+		opts.Cursor = NextCursor(query, opts, page)
+	}
+
+	// Assert
+	want := []string{"r8", "r9", "r6", "r7", "r4", "r5", "r2", "r3", "r0", "r1"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestShardedCollection_Validation(t *testing.T) {
+	// Arrange
+	shard := &shardStub{name: "a", records: map[string]Record{}}
+	other := &memberCollection{name: "b", dimension: 3}
+
+	// Act
+	_, emptyErr := ShardedCollection("docs", nil)
+	_, dimensionErr := ShardedCollection("docs", []Collection{shard, other})
+	sharded, _ := ShardedCollection("docs", []Collection{shard})
+	_, isText := sharded.(TextSearcher)
+
+	// Assert
+	if !errors.Is(emptyErr, ErrSchemaMismatch) || !errors.Is(dimensionErr, ErrDimensionMismatch) {
+		t.Fatalf("unexpected validation errors: %v, %v", emptyErr, dimensionErr)
+	}
+	if isText {
+		t.Fatalf("expected no text search without text-capable shards")
+	}
+}
+
+func TestShardFor_IsStable(t *testing.T) {
+	// Arrange
+	ids := []string{"a", "b", "doc-1"}
+
+	// Act
+	first := []int{ShardFor(ids[0], 8), ShardFor(ids[1], 8), ShardFor(ids[2], 8)}
+	second := []int{ShardFor(ids[0], 8), ShardFor(ids[1], 8), ShardFor(ids[2], 8)}
+
+	// Assert
+	for i := range ids {
+		if first[i] != second[i] || first[i] < 0 || first[i] >= 8 {
+			t.Fatalf("unstable or out of range shard for %s: %d, %d", ids[i], first[i], second[i])
+		}
+	}
+}
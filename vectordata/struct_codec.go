@@ -0,0 +1,317 @@
+package vectordata
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StructCodec is a Codec for structs whose fields are mapped to the Record
+// model with `vector` tags:
+//
+//	type Doc struct {
+//		ID        string    `vector:"id"`
+//		Embedding []float32 `vector:"embedding,dim=1536"`
+//		Body      string    `vector:"content"`
+//		Category  string    `vector:"meta,name=category"`
+//		Rank      int       `vector:"meta,omitempty"`
+//	}
+//
+// The id field is a string and is required. The embedding field is a
+// []float32, or a []float64 or []int8 for collections with the matching
+// ElementType; its dim option is reported by Dimension. The content field is
+// a string or *string. Meta fields are stored under their name option, or
+// the field name, and omitempty skips zero values. Untagged fields and
+// fields tagged "-" are ignored. T is a struct or a pointer to one.
+type StructCodec[T any] struct {
+	layout *structLayout
+}
+
+// NewStructCodec returns the codec for T. The layout of each type is derived
+// once and cached.
+func NewStructCodec[T any]() (*StructCodec[T], error) {
+	layout, err := structLayoutOf(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &StructCodec[T]{layout: layout}, nil
+}
+
+// Dimension returns the dim option of the embedding field, or 0.
+func (c *StructCodec[T]) Dimension() int {
+	return c.layout.dimension
+}
+
+// Encode maps value to a Record.
+func (c *StructCodec[T]) Encode(value T) (Record, error) {
+	v := reflect.ValueOf(&value).Elem()
+	if c.layout.pointer {
+		if v.IsNil() {
+			return Record{}, fmt.Errorf("struct codec %s: cannot encode a nil pointer", c.layout.typ)
+		}
+		v = v.Elem()
+	}
+
+	var record Record
+	record.ID = v.FieldByIndex(c.layout.id).String()
+	if c.layout.embedding != nil {
+		embedding := v.FieldByIndex(c.layout.embedding).Interface()
+		switch vector := embedding.(type) {
+		case []float32:
+			record.Vector = vector
+		case []float64:
+			record.Vector64 = vector
+		case []int8:
+			record.VectorInt8 = vector
+		}
+	}
+	if c.layout.content != nil {
+		content := v.FieldByIndex(c.layout.content)
+		if content.Kind() == reflect.Pointer {
+			if !content.IsNil() {
+				text := content.Elem().String()
+				record.Content = &text
+			}
+		} else {
+			text := content.String()
+			record.Content = &text
+		}
+	}
+	for _, field := range c.layout.meta {
+		fv := v.FieldByIndex(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if record.Metadata == nil {
+			record.Metadata = make(map[string]any, len(c.layout.meta))
+		}
+		record.Metadata[field.key] = fv.Interface()
+	}
+	return record, nil
+}
+
+// Decode maps record back to T. Metadata values are converted to the field
+// types, including numbers decoded from JSON as float64; missing keys leave
+// fields zero.
+func (c *StructCodec[T]) Decode(record Record) (T, error) {
+	var out T
+	v := reflect.ValueOf(&out).Elem()
+	if c.layout.pointer {
+		v.Set(reflect.New(c.layout.typ))
+		v = v.Elem()
+	}
+
+	v.FieldByIndex(c.layout.id).SetString(record.ID)
+	if c.layout.embedding != nil {
+		field := v.FieldByIndex(c.layout.embedding)
+		switch field.Interface().(type) {
+		case []float32:
+			field.Set(reflect.ValueOf(record.Vector))
+		case []float64:
+			field.Set(reflect.ValueOf(record.Vector64))
+		case []int8:
+			field.Set(reflect.ValueOf(record.VectorInt8))
+		}
+	}
+	if c.layout.content != nil && record.Content != nil {
+		field := v.FieldByIndex(c.layout.content)
+		if field.Kind() == reflect.Pointer {
+			text := *record.Content
+			field.Set(reflect.ValueOf(&text))
+		} else {
+			field.SetString(*record.Content)
+		}
+	}
+	for _, field := range c.layout.meta {
+		value, ok := record.Metadata[field.key]
+		if !ok || value == nil {
+			continue
+		}
+		if err := assignMetadata(v.FieldByIndex(field.index), value); err != nil {
+			var zero T
+			return zero, fmt.Errorf("struct codec %s: record %q: metadata %q: %w", c.layout.typ, record.ID, field.key, err)
+		}
+	}
+	return out, nil
+}
+
+// assignMetadata sets field to value, converting through JSON when the types
+// differ.
+func assignMetadata(field reflect.Value, value any) error {
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	target := reflect.New(field.Type())
+	if err := json.Unmarshal(raw, target.Interface()); err != nil {
+		return fmt.Errorf("cannot convert %T to %s: %w", value, field.Type(), err)
+	}
+	field.Set(target.Elem())
+	return nil
+}
+
+// structLayout is the field mapping of a struct type. Indexes are paths for
+// reflect.Value.FieldByIndex.
+type structLayout struct {
+	typ       reflect.Type
+	pointer   bool
+	id        []int
+	embedding []int
+	dimension int
+	content   []int
+	meta      []metaField
+	// filterable maps the names of the id, content and meta fields to the
+	// filter fields they are stored in.
+	filterable map[string]filterableField
+}
+
+type filterableField struct {
+	ref FieldRef
+	// typ is the Go type of filter values.
+	typ reflect.Type
+}
+
+type metaField struct {
+	index     []int
+	key       string
+	omitEmpty bool
+}
+
+var structLayouts sync.Map // reflect.Type -> *structLayout
+
+func structLayoutOf(t reflect.Type) (*structLayout, error) {
+	if cached, ok := structLayouts.Load(t); ok {
+		return cached.(*structLayout), nil
+	}
+	layout, err := buildStructLayout(t)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := structLayouts.LoadOrStore(t, layout)
+	return actual.(*structLayout), nil
+}
+
+func buildStructLayout(t reflect.Type) (*structLayout, error) {
+	layout := &structLayout{typ: t, filterable: make(map[string]filterableField)}
+	if t.Kind() == reflect.Pointer {
+		layout.pointer = true
+		layout.typ = t.Elem()
+	}
+	if layout.typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct codec %s: type is not a struct or a pointer to one", t)
+	}
+
+	keys := make(map[string]string)
+	for _, field := range reflect.VisibleFields(layout.typ) {
+		tag, ok := field.Tag.Lookup("vector")
+		if !ok || tag == "-" {
+			continue
+		}
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("struct codec %s: field %s: %s", layout.typ, field.Name, fmt.Sprintf(format, args...))
+		}
+		if !field.IsExported() {
+			return nil, fail("tagged field is not exported")
+		}
+		if throughPointer(layout.typ, field.Index) {
+			return nil, fail("tagged field is promoted through an embedded pointer")
+		}
+
+		role, options, _ := strings.Cut(tag, ",")
+		switch role {
+		case "id":
+			if layout.id != nil {
+				return nil, fail("duplicate id field")
+			}
+			if field.Type.Kind() != reflect.String {
+				return nil, fail("id must be a string, got %s", field.Type)
+			}
+			layout.id = field.Index
+			layout.filterable[field.Name] = filterableField{ref: Column("id"), typ: field.Type}
+		case "embedding":
+			if layout.embedding != nil {
+				return nil, fail("duplicate embedding field")
+			}
+			switch field.Type {
+			case reflect.TypeFor[[]float32](), reflect.TypeFor[[]float64](), reflect.TypeFor[[]int8]():
+			default:
+				return nil, fail("embedding must be []float32, []float64 or []int8, got %s", field.Type)
+			}
+			layout.embedding = field.Index
+			for _, option := range strings.Split(options, ",") {
+				switch {
+				case option == "":
+				case strings.HasPrefix(option, "dim="):
+					dimension, err := strconv.Atoi(strings.TrimPrefix(option, "dim="))
+					if err != nil || dimension <= 0 {
+						return nil, fail("dim must be a positive integer, got %q", strings.TrimPrefix(option, "dim="))
+					}
+					layout.dimension = dimension
+				default:
+					return nil, fail("unknown option %q", option)
+				}
+			}
+		case "content":
+			if layout.content != nil {
+				return nil, fail("duplicate content field")
+			}
+			if field.Type.Kind() != reflect.String && field.Type != reflect.TypeFor[*string]() {
+				return nil, fail("content must be a string or *string, got %s", field.Type)
+			}
+			layout.content = field.Index
+			layout.filterable[field.Name] = filterableField{ref: Column("content"), typ: reflect.TypeFor[string]()}
+		case "meta":
+			meta := metaField{index: field.Index, key: field.Name}
+			for _, option := range strings.Split(options, ",") {
+				switch {
+				case option == "":
+				case option == "omitempty":
+					meta.omitEmpty = true
+				case strings.HasPrefix(option, "name="):
+					meta.key = strings.TrimPrefix(option, "name=")
+					if meta.key == "" {
+						return nil, fail("empty metadata name")
+					}
+				default:
+					return nil, fail("unknown option %q", option)
+				}
+			}
+			if other, ok := keys[meta.key]; ok {
+				return nil, fail("metadata key %q is also used by field %s", meta.key, other)
+			}
+			keys[meta.key] = field.Name
+			layout.meta = append(layout.meta, meta)
+			layout.filterable[field.Name] = filterableField{ref: Metadata(meta.key), typ: field.Type}
+		default:
+			return nil, fail("unknown role %q, expected id, embedding, content or meta", role)
+		}
+		if role != "meta" && role != "embedding" && options != "" {
+			return nil, fail("role %q takes no options", role)
+		}
+	}
+	if layout.id == nil {
+		return nil, fmt.Errorf("struct codec %s: no field tagged `vector:\"id\"`", layout.typ)
+	}
+	return layout, nil
+}
+
+// throughPointer reports whether the field at index is reached through an
+// embedded pointer, which FieldByIndex cannot follow when it is nil.
+func throughPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Pointer {
+			return true
+		}
+		t = field.Type
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package vectordata
+
+import (
+	"strings"
+	"testing"
+)
+
+type structCodecDoc struct {
+	ID        string    `vector:"id"`
+	Embedding []float32 `vector:"embedding"`
+	Body      *string   `vector:"content"`
+	Category  string    `vector:"meta,name=category"`
+	Rank      int       `vector:"meta,omitempty"`
+	Tags      []string  `vector:"meta,name=tags,omitempty"`
+	Internal  string
+}
+
+func TestStructCodecRoundTrip(t *testing.T) {
+	// Arrange
+	codec, err := NewStructCodec[structCodecDoc]()
+	if err != nil {
+		t.Fatalf("NewStructCodec: %v", err)
+	}
+	body := "hello"
+	doc := structCodecDoc{ID: "a", Embedding: []float32{1, 0}, Body: &body, Category: "news", Rank: 3, Internal: "x"}
+
+	// Act
+	record, encodeErr := codec.Encode(doc)
+	// Metadata read back from a store holds JSON numbers and arrays.
+	record.Metadata["Rank"] = float64(3)
+	record.Metadata["tags"] = []any{"go"}
+	decoded, decodeErr := codec.Decode(record)
+
+	// Assert
+	if encodeErr != nil || decodeErr != nil {
+		t.Fatalf("unexpected errors: %v, %v", encodeErr, decodeErr)
+	}
+	if record.ID != "a" || len(record.Vector) != 2 || *record.Content != "hello" || record.Metadata["category"] != "news" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if _, ok := record.Metadata["Internal"]; ok {
+		t.Fatal("expected untagged fields to be ignored")
+	}
+	if decoded.ID != "a" || *decoded.Body != "hello" || decoded.Category != "news" || decoded.Rank != 3 || len(decoded.Tags) != 1 || decoded.Internal != "" {
+		t.Fatalf("unexpected decoded value %+v", decoded)
+	}
+}
+
+func TestStructCodecOmitEmpty(t *testing.T) {
+	// Arrange
+	codec, _ := NewStructCodec[*structCodecDoc]()
+
+	// Act
+	record, err := codec.Encode(&structCodecDoc{ID: "a"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, ok := record.Metadata["Rank"]; ok || record.Metadata["category"] != "" || record.Content != nil {
+		t.Fatalf("expected omitempty fields to be skipped, got %+v", record)
+	}
+}
+
+func TestStructCodecDecodeReportsConversionErrors(t *testing.T) {
+	// Arrange
+	codec, _ := NewStructCodec[structCodecDoc]()
+
+	// Act
+	_, err := codec.Decode(Record{ID: "a", Metadata: map[string]any{"Rank": "high"}})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), `record "a": metadata "Rank"`) {
+		t.Fatalf("expected a conversion error naming the record and key, got %v", err)
+	}
+}
+
+func TestNewStructCodecRejectsInvalidTags(t *testing.T) {
+	type noID struct {
+		Name string `vector:"meta"`
+	}
+	type badEmbedding struct {
+		ID     string `vector:"id"`
+		Vector []int  `vector:"embedding"`
+	}
+	type duplicateKey struct {
+		ID string `vector:"id"`
+		A  string `vector:"meta,name=k"`
+		B  string `vector:"meta,name=k"`
+	}
+	type unknownRole struct {
+		ID string `vector:"id"`
+		X  string `vector:"vector"`
+	}
+
+	cases := map[string]error{
+		"no field tagged":       codecError[noID](),
+		"embedding must be":     codecError[badEmbedding](),
+		"also used by field A":  codecError[duplicateKey](),
+		`unknown role "vector"`: codecError[unknownRole](),
+		"not a struct":          codecError[string](),
+	}
+	for want, err := range cases {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected an error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func codecError[T any]() error {
+	_, err := NewStructCodec[T]()
+	return err
+}
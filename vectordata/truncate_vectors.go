@@ -0,0 +1,29 @@
+package vectordata
+
+import "fmt"
+
+// TruncateVector returns the first dimension components of vector scaled
+// back to unit length. Matryoshka embeddings (e.g. OpenAI
+// text-embedding-3) keep most of their quality when reduced this way.
+func TruncateVector(vector []float32, dimension int) ([]float32, error) {
+	if dimension <= 0 || dimension > len(vector) {
+		return nil, fmt.Errorf("%w: cannot truncate a vector of dimension %d to %d", ErrDimensionMismatch, len(vector), dimension)
+	}
+	return NormalizeVector(vector[:dimension]), nil
+}
+
+// TruncateRecordVectors returns a copy of records whose vectors are
+// truncated with TruncateVector, e.g. to copy a collection into a smaller
+// one. The records passed in are not modified.
+func TruncateRecordVectors(records []Record, dimension int) ([]Record, error) {
+	out := make([]Record, len(records))
+	for i, record := range records {
+		vector, err := TruncateVector(record.Vector, dimension)
+		if err != nil {
+			return nil, fmt.Errorf("record %q: %w", record.ID, err)
+		}
+		record.Vector = vector
+		out[i] = record
+	}
+	return out, nil
+}
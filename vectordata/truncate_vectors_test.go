@@ -0,0 +1,51 @@
+package vectordata
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTruncateVector(t *testing.T) {
+	// Arrange
+	vector := []float32{3, 4, 12}
+
+	// Act
+	got, err := TruncateVector(vector, 2)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("TruncateVector: %v", err)
+	}
+	if len(got) != 2 || math.Abs(float64(got[0])-0.6) > 1e-6 || math.Abs(float64(got[1])-0.8) > 1e-6 {
+		t.Fatalf("expected the renormalized prefix, got %v", got)
+	}
+	if vector[0] != 3 {
+		t.Fatalf("expected the input to be unchanged, got %v", vector)
+	}
+}
+
+func TestTruncateVectorRejectsInvalidDimension(t *testing.T) {
+	for _, dimension := range []int{0, 4} {
+		if _, err := TruncateVector([]float32{1, 2, 3}, dimension); !errors.Is(err, ErrDimensionMismatch) {
+			t.Fatalf("dimension %d: expected ErrDimensionMismatch, got %v", dimension, err)
+		}
+	}
+}
+
+func TestTruncateRecordVectors(t *testing.T) {
+	// Arrange
+	records := []Record{{ID: "a", Vector: []float32{0, 2, 5}}, {ID: "b", Vector: []float32{1}}}
+
+	// Act
+	_, shortErr := TruncateRecordVectors(records, 2)
+	got, err := TruncateRecordVectors(records[:1], 2)
+
+	// Assert
+	if !errors.Is(shortErr, ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch for a short record, got %v", shortErr)
+	}
+	if err != nil || got[0].Vector[1] != 1 || len(records[0].Vector) != 3 {
+		t.Fatalf("expected a truncated copy, got %v (%v)", got, err)
+	}
+}
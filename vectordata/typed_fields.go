@@ -0,0 +1,93 @@
+package vectordata
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Field is a typed reference to a field of a struct mapped by a StructCodec.
+// Its filters take values of the field's Go type, so they cannot drift from
+// the struct. Fields are bound with BindFields.
+type Field[V any] struct {
+	ref FieldRef
+}
+
+// Ref returns the field reference, for filters without a typed method such
+// as Contains.
+func (f Field[V]) Ref() FieldRef { return f.ref }
+
+// Eq constructs an equality filter.
+func (f Field[V]) Eq(value V) Filter { return Eq(f.ref, value) }
+
+// Ne constructs a negated equality filter.
+func (f Field[V]) Ne(value V) Filter { return Not(Eq(f.ref, value)) }
+
+// Gt constructs a greater-than filter.
+func (f Field[V]) Gt(value V) Filter { return Gt(f.ref, value) }
+
+// Lt constructs a less-than filter.
+func (f Field[V]) Lt(value V) Filter { return Lt(f.ref, value) }
+
+// Exists constructs an exists filter.
+func (f Field[V]) Exists() Filter { return Exists(f.ref) }
+
+// In constructs an IN filter.
+func (f Field[V]) In(values ...V) Filter {
+	anys := make([]any, len(values))
+	for i, value := range values {
+		anys[i] = value
+	}
+	return In(f.ref, anys...)
+}
+
+func (f *Field[V]) bind(ref FieldRef) { f.ref = ref }
+
+func (Field[V]) valueType() reflect.Type { return reflect.TypeFor[V]() }
+
+type fieldBinder interface {
+	bind(ref FieldRef)
+	valueType() reflect.Type
+}
+
+// BindFields returns a fields struct F whose Field members reference the
+// fields of T with the same names:
+//
+//	type DocFields struct {
+//		Category vectordata.Field[string]
+//		Rank     vectordata.Field[int]
+//	}
+//
+//	fields, err := vectordata.BindFields[DocFields](codec)
+//	filter := fields.Rank.Gt(5)
+//
+// Every field of F must be a Field whose value type is that of the id,
+// content or meta field of T it names; content fields take string values.
+// Mismatches are reported, so a renamed or retyped struct field fails
+// binding instead of producing filters that silently match nothing.
+func BindFields[F any, T any](codec *StructCodec[T]) (F, error) {
+	var fields F
+	v := reflect.ValueOf(&fields).Elem()
+	if v.Kind() != reflect.Struct {
+		return fields, fmt.Errorf("bind fields %s: type is not a struct", v.Type())
+	}
+	layout := codec.layout
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		binder, ok := v.Field(i).Addr().Interface().(fieldBinder)
+		if !ok {
+			return fields, fmt.Errorf("bind fields %s: field %s is not a vectordata.Field", v.Type(), field.Name)
+		}
+		target, ok := layout.filterable[field.Name]
+		if !ok {
+			return fields, fmt.Errorf("bind fields %s: %s has no id, content or meta field %s", v.Type(), layout.typ, field.Name)
+		}
+		if binder.valueType() != target.typ {
+			return fields, fmt.Errorf("bind fields %s: field %s takes %s values, %s.%s is %s", v.Type(), field.Name, binder.valueType(), layout.typ, field.Name, target.typ)
+		}
+		binder.bind(target.ref)
+	}
+	return fields, nil
+}
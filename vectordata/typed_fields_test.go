@@ -0,0 +1,69 @@
+package vectordata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type structCodecDocFields struct {
+	ID       Field[string]
+	Body     Field[string]
+	Category Field[string]
+	Rank     Field[int]
+}
+
+func TestBindFields(t *testing.T) {
+	// Arrange
+	codec, err := NewStructCodec[structCodecDoc]()
+	if err != nil {
+		t.Fatalf("NewStructCodec: %v", err)
+	}
+
+	// Act
+	fields, err := BindFields[structCodecDocFields](codec)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("BindFields: %v", err)
+	}
+	filter := And(fields.Category.Eq("news"), fields.Rank.Gt(5), fields.ID.In("a", "b"), fields.Body.Exists())
+	expected := And(
+		Eq(Metadata("category"), "news"),
+		Gt(Metadata("Rank"), 5),
+		In(Column("id"), "a", "b"),
+		Exists(Column("content")),
+	)
+	if !reflect.DeepEqual(filter, expected) {
+		t.Fatalf("unexpected filter\nwant: %#v\n got: %#v", expected, filter)
+	}
+}
+
+func TestBindFieldsRejectsDrift(t *testing.T) {
+	type renamed struct {
+		Score Field[int]
+	}
+	type retyped struct {
+		Rank Field[string]
+	}
+	type untyped struct {
+		Rank int
+	}
+	codec, _ := NewStructCodec[structCodecDoc]()
+
+	cases := map[string]error{
+		"no id, content or meta field Score": bindError[renamed](codec),
+		"takes string values":                bindError[retyped](codec),
+		"is not a vectordata.Field":          bindError[untyped](codec),
+	}
+	for want, err := range cases {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected an error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func bindError[F any](codec *StructCodec[structCodecDoc]) error {
+	_, err := BindFields[F](codec)
+	return err
+}
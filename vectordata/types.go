@@ -1,6 +1,10 @@
 package vectordata
 
-import "context"
+import (
+	"context"
+
+	"github.com/gabisonia/go-vectorstore/vectordata/vectormath"
+)
 
 // DistanceMetric selects the similarity distance function used by a collection.
 type DistanceMetric string
@@ -19,6 +23,11 @@ const (
 	EnsureStrict EnsureMode = "strict"
 	// EnsureAutoMigrate creates missing optional columns where possible.
 	EnsureAutoMigrate EnsureMode = "auto_migrate"
+	// EnsureRecreate drops a collection whose existing schema does not match
+	// CollectionSpec, with all of its records, and creates it again. It is
+	// meant for test environments, and stores that support it require an
+	// explicit store option to accept it.
+	EnsureRecreate EnsureMode = "recreate"
 )
 
 // CollectionSpec defines physical collection requirements.
@@ -27,14 +36,83 @@ type CollectionSpec struct {
 	Dimension int
 	Metric    DistanceMetric
 	Mode      EnsureMode
+	// Partition declares table partitioning where the backend supports it.
+	Partition *PartitionSpec
+	// NormalizeVectors scales vectors to unit length on Insert and Upsert and
+	// at query time. It is recorded with the collection, and ensuring the
+	// collection again with a different setting fails with ErrSchemaMismatch.
+	NormalizeVectors bool
+	// ElementType selects the precision vectors are kept in (default
+	// ElementFloat32). It is recorded with the collection like
+	// NormalizeVectors.
+	ElementType VectorElementType
+	// FastDimension, when set, keeps each vector truncated to its first
+	// FastDimension components and renormalized, as Matryoshka embeddings
+	// allow. Searches rank candidates by the reduced vector and rescore them
+	// with the full one. It is recorded with the collection like
+	// NormalizeVectors.
+	FastDimension int
+	// MaxRecords, when > 0, bounds the collection: after every Insert and
+	// Upsert through the returned handle, and on Evict, the records past
+	// MaxRecords are deleted in EvictBy order. It applies to that handle
+	// only and is not recorded with the collection.
+	MaxRecords int64
+	// EvictBy names the metadata key ordering eviction, oldest first: an RFC
+	// 3339 timestamp such as a creation time, or a last-used time the
+	// application refreshes on hits for LRU. Records without a valid
+	// timestamp are evicted first. Empty evicts in ID order.
+	EvictBy string
+}
+
+// VectorElementType is the precision a collection keeps vectors in.
+type VectorElementType string
+
+const (
+	// ElementFloat32 stores Record.Vector. It is the default and the fast
+	// path of every backend.
+	ElementFloat32 VectorElementType = "float32"
+	// ElementFloat64 stores Record.Vector64 at full precision alongside a
+	// float32 copy that backs indexes and searches.
+	ElementFloat64 VectorElementType = "float64"
+	// ElementInt8 stores Record.VectorInt8, the output of quantized models,
+	// alongside a float32 copy that backs indexes and searches.
+	ElementInt8 VectorElementType = "int8"
+)
+
+// PartitionMethod selects how a partitioned collection splits records.
+type PartitionMethod string
+
+const (
+	PartitionList PartitionMethod = "list"
+	PartitionHash PartitionMethod = "hash"
+)
+
+// PartitionSpec partitions a collection by a top-level metadata key (e.g. a
+// tenant id) that the backend promotes to a dedicated column. Every record
+// must carry the key.
+type PartitionSpec struct {
+	Method PartitionMethod
+	Key    string
+	// Values pre-creates LIST partitions; other values get a partition on
+	// first write.
+	Values []string
+	// Modulus is the number of HASH partitions.
+	Modulus int
 }
 
 // Record is the base storage model for a vector collection.
 type Record struct {
-	ID       string
-	Vector   []float32
-	Metadata map[string]any
-	Content  *string
+	ID     string
+	Vector []float32
+	// Vector64 is the full-precision vector of a collection with
+	// ElementFloat64. Writes to such collections take it instead of Vector,
+	// which the store derives; reads that include vectors return both.
+	Vector64 []float64
+	// VectorInt8 is the quantized vector of a collection with ElementInt8,
+	// handled like Vector64.
+	VectorInt8 []int8
+	Metadata   map[string]any
+	Content    *string
 }
 
 // SearchResult contains a matched record plus ranking values.
@@ -42,6 +120,9 @@ type SearchResult struct {
 	Record   Record
 	Distance float64
 	Score    float64
+	// Snippets are the matching portions of the content, when highlighting
+	// was requested; see HighlightOptions.
+	Snippets []string
 }
 
 // Projection configures which optional fields are returned by search operations.
@@ -49,6 +130,9 @@ type Projection struct {
 	IncludeVector   bool
 	IncludeMetadata bool
 	IncludeContent  bool
+	// Redact, when set, masks metadata keys and content patterns in the
+	// returned records before they leave the store.
+	Redact *Redaction
 }
 
 // DefaultProjection returns the default projection used by SearchByVector.
@@ -61,6 +145,160 @@ type SearchOptions struct {
 	Filter     Filter
 	Projection *Projection
 	Threshold  *float64
+	// SessionSettings are backend runtime parameters scoped to this query,
+	// e.g. "hnsw.ef_search" or "work_mem" on Postgres. Backends without
+	// session settings ignore them.
+	SessionSettings map[string]string
+	// ScoreNormalization rescales Score so results of different metrics and
+	// collections can be compared or fused. Distance is unaffected.
+	ScoreNormalization ScoreNormalization
+	// Negatives move the query away from examples the results should not
+	// resemble; see AdjustQueryVector. Distances and Threshold apply to the
+	// adjusted query.
+	Negatives []NegativeVector
+	// Queries searches with several weighted query vectors instead of the
+	// vector argument, fused as QueryFusion selects; see SearchQueries.
+	Queries     []QueryVector
+	QueryFusion QueryFusion
+	// TimeDecay and Boosts adjust scores, e.g. for freshness or business
+	// rules, and rank by the boosted score; see Boost. They cannot be
+	// combined with ScoreNormalization.
+	TimeDecay *TimeDecay
+	Boosts    []Boost
+	// BoostCandidates is how many nearest records stores that boost in
+	// process rerank (default 4 × topK). Stores that rank in the query, such
+	// as Postgres, ignore it.
+	BoostCandidates int
+	// Cursor resumes a search after the last result of the previous page,
+	// as returned by NextCursor. Results are ordered by distance, then ID,
+	// so pages neither skip nor repeat records that did not change. Stores
+	// that cannot page this way fail with errors.ErrUnsupported.
+	Cursor string
+	// OrderBy orders results that tie in rank by metadata paths or columns,
+	// e.g. Desc(Metadata("published_at")). Postgres orders in the query;
+	// other stores order the returned results with OrderResults. It cannot
+	// be combined with Cursor.
+	OrderBy []OrderSpec
+}
+
+// HybridSearchOptions configures combined vector and lexical search.
+type HybridSearchOptions struct {
+	Filter     Filter
+	Projection *Projection
+	// RankProfile selects a backend-native ranking profile when the backend has one.
+	RankProfile string
+	// VectorWeight and TextWeight balance the two relevance signals.
+	// Zero values default to 1.
+	VectorWeight float64
+	TextWeight   float64
+	// Highlight, when set, returns snippets of the content matching the text
+	// in SearchResult.Snippets, where the store supports it.
+	Highlight *HighlightOptions
+}
+
+// HybridSearcher is implemented by collections that rank by vector similarity
+// and text relevance in a single query.
+type HybridSearcher interface {
+	HybridSearch(ctx context.Context, vector []float32, text string, topK int, opts HybridSearchOptions) ([]SearchResult, error)
+}
+
+// TextSearchOptions configures lexical search over record content.
+type TextSearchOptions struct {
+	Filter     Filter
+	Projection *Projection
+	// Highlight, when set, returns snippets of the content matching the
+	// query in SearchResult.Snippets, where the store supports it.
+	Highlight *HighlightOptions
+}
+
+// HealthChecker is implemented by stores that can report whether they are
+// usable, e.g. for liveness and readiness probes. Ping fails with an error
+// wrapping ErrUnavailable; Ready also checks the store's prerequisites and
+// fails with ErrUnavailable or ErrNotReady.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+	Ready(ctx context.Context) error
+}
+
+// TextSearcher is implemented by collections that rank records by text
+// relevance of Content alone.
+type TextSearcher interface {
+	SearchByText(ctx context.Context, text string, topK int, opts TextSearchOptions) ([]SearchResult, error)
+}
+
+// BatchGetter is implemented by collections that fetch several records in
+// one call. Records come back in the order of ids, without the IDs that do
+// not exist.
+type BatchGetter interface {
+	GetMany(ctx context.Context, ids []string) ([]Record, error)
+}
+
+// ListOptions selects a page of records in ascending ID order.
+type ListOptions struct {
+	Filter Filter
+	// After resumes listing after this ID, typically the last ID of the
+	// previous page.
+	After string
+	// Limit is the page size and must be > 0.
+	Limit      int
+	Projection *Projection
+}
+
+// RecordLister is implemented by collections that page through their
+// records.
+type RecordLister interface {
+	List(ctx context.Context, opts ListOptions) ([]Record, error)
+}
+
+// CountEstimator is implemented by collections that estimate counts without
+// scanning every record. Estimates are approximate: use Count when the exact
+// number matters.
+type CountEstimator interface {
+	EstimateCount(ctx context.Context, filter Filter) (int64, error)
+}
+
+// SampleOptions configures Sampler.Sample.
+type SampleOptions struct {
+	Filter Filter
+	// Seed, when set, makes samples reproducible while the collection does
+	// not change. Stores that cannot seed their sampling fail with
+	// errors.ErrUnsupported.
+	Seed *int64
+}
+
+// Sampler is implemented by collections that return random samples of their
+// records, e.g. to evaluate embedding quality. Samples hold up to n complete
+// records, vectors included, in random order; fewer when fewer records match.
+type Sampler interface {
+	Sample(ctx context.Context, n int, opts SampleOptions) ([]Record, error)
+}
+
+// NeighborFinder is implemented by collections that find the nearest
+// neighbors of many stored records in one query. Use NeighborsFor, which
+// falls back to one search per record for other collections.
+type NeighborFinder interface {
+	NeighborsFor(ctx context.Context, ids []string, topK int, opts SearchOptions) (map[string][]SearchResult, error)
+}
+
+// CentroidCalculator is implemented by collections that average vectors per
+// group on the server. Use Centroids, which falls back to listing the
+// records for other collections.
+type CentroidCalculator interface {
+	Centroids(ctx context.Context, groupBy FieldRef, filter Filter) ([]Centroid, error)
+}
+
+// FilterDeleter is implemented by collections that delete every record
+// matching a filter. A nil filter fails with ErrInvalidFilter rather than
+// deleting everything.
+type FilterDeleter interface {
+	DeleteByFilter(ctx context.Context, filter Filter) (int64, error)
+}
+
+// ReturningDeleter is implemented by collections that report which of the
+// given IDs a delete removed, in one statement. Use DeleteWithResults, which
+// falls back to one Delete per ID for other collections.
+type ReturningDeleter interface {
+	DeleteReturning(ctx context.Context, ids []string) ([]string, error)
 }
 
 // IndexMethod selects a vector index implementation.
@@ -89,18 +327,56 @@ type VectorIndexOptions struct {
 	Metric  DistanceMetric
 	HNSW    HNSWOptions
 	IVFFlat IVFFlatOptions
+	// Concurrent builds the index without blocking writes where the backend
+	// supports it (CREATE INDEX CONCURRENTLY on Postgres).
+	Concurrent bool
 }
 
 // MetadataIndexOptions configures creation of a metadata JSONB index.
 type MetadataIndexOptions struct {
 	Name       string
 	UsePathOps bool
+	// Keys adds typed btree expression indexes on individual metadata paths,
+	// which serve range filters the JSONB index cannot.
+	Keys []MetadataKeyIndex
+}
+
+// MetadataKeyType selects how a metadata key index reads the value.
+type MetadataKeyType string
+
+const (
+	MetadataKeyText      MetadataKeyType = "text"
+	MetadataKeyNumeric   MetadataKeyType = "numeric"
+	MetadataKeyTimestamp MetadataKeyType = "timestamp"
+)
+
+// MetadataKeyIndex configures an expression index over one metadata path.
+// Numeric keys serve Gt/Lt with numeric values, timestamp keys serve Gt/Lt
+// with time.Time values and text keys serve Gt/Lt with other values.
+type MetadataKeyIndex struct {
+	Name string
+	Path []string
+	Type MetadataKeyType
+}
+
+// TextIndexOptions configures creation of a full-text index over Content.
+type TextIndexOptions struct {
+	Name string
+}
+
+// TrigramIndexOptions configures trigram indexes that serve Contains and
+// Similar filters on Content and on metadata paths.
+type TrigramIndexOptions struct {
+	Content       bool
+	MetadataPaths [][]string
 }
 
 // IndexOptions configures collection index creation.
 type IndexOptions struct {
 	Vector   *VectorIndexOptions
 	Metadata *MetadataIndexOptions
+	Text     *TextIndexOptions
+	Trigram  *TrigramIndexOptions
 }
 
 // VectorStore creates and resolves vector collections.
@@ -125,6 +401,21 @@ type Collection interface {
 	EnsureIndexes(ctx context.Context, opts IndexOptions) error
 }
 
+// Distance returns the exact distance between a and b under metric, as the
+// backends report it: Euclidean for l2, 1 - cosine similarity for cosine and
+// the negated inner product for inner_product. It panics if the lengths of a
+// and b differ.
+func Distance(metric DistanceMetric, a, b []float32) float64 {
+	switch metric {
+	case DistanceL2:
+		return vectormath.L2Distance(a, b)
+	case DistanceInnerProduct:
+		return -vectormath.Dot(a, b)
+	default:
+		return vectormath.CosineDistance(a, b)
+	}
+}
+
 // ScoreFromDistance converts backend distance into a monotonic score (higher is better).
 func ScoreFromDistance(metric DistanceMetric, distance float64) float64 {
 	switch metric {
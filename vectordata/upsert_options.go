@@ -0,0 +1,111 @@
+package vectordata
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// ConflictStrategy selects what UpsertWithOptions does with records whose ID
+// already exists.
+type ConflictStrategy string
+
+const (
+	// ConflictReplace replaces the existing record, as Upsert does.
+	ConflictReplace ConflictStrategy = ""
+	// ConflictSkip keeps the existing record and drops the new one.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictMergeMetadata replaces the vector and content and merges the
+	// new metadata into the existing metadata: top-level keys of the new
+	// record win, other existing keys are kept.
+	ConflictMergeMetadata ConflictStrategy = "merge_metadata"
+)
+
+// Validate reports whether s is a known strategy.
+func (s ConflictStrategy) Validate() error {
+	switch s {
+	case ConflictReplace, ConflictSkip, ConflictMergeMetadata:
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported conflict strategy %q", ErrSchemaMismatch, s)
+	}
+}
+
+// UpsertOptions configures UpsertWithOptions.
+type UpsertOptions struct {
+	OnConflict ConflictStrategy
+}
+
+// ConflictUpserter is implemented by collections that resolve write
+// conflicts atomically with the write.
+type ConflictUpserter interface {
+	UpsertWithOptions(ctx context.Context, records []Record, opts UpsertOptions) error
+}
+
+// UpsertWithOptions writes records, resolving records whose ID exists with
+// opts.OnConflict. ConflictUpserters, such as the Postgres and FAISS stores,
+// resolve conflicts in the write itself. Other collections read the
+// existing records first and then write, so a concurrent writer can slip in
+// between: a skipped record may be overwritten, or an Insert of absent
+// records may fail with ErrConflict.
+func UpsertWithOptions(ctx context.Context, collection Collection, records []Record, opts UpsertOptions) error {
+	if err := opts.OnConflict.Validate(); err != nil {
+		return err
+	}
+	if upserter, ok := collection.(ConflictUpserter); ok {
+		return upserter.UpsertWithOptions(ctx, records, opts)
+	}
+	if opts.OnConflict == ConflictReplace || len(records) == 0 {
+		return collection.Upsert(ctx, records)
+	}
+
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	found, err := getRecords(ctx, collection, uniqueIDs(ids))
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]Record, len(found))
+	for _, record := range found {
+		existing[record.ID] = record
+	}
+
+	if opts.OnConflict == ConflictSkip {
+		absent := make([]Record, 0, len(records))
+		seen := make(map[string]bool, len(records))
+		for _, record := range records {
+			if _, ok := existing[record.ID]; ok || seen[record.ID] {
+				continue
+			}
+			seen[record.ID] = true
+			absent = append(absent, record)
+		}
+		if len(absent) == 0 {
+			return nil
+		}
+		return collection.Insert(ctx, absent)
+	}
+
+	merged := make([]Record, len(records))
+	for i, record := range records {
+		if current, ok := existing[record.ID]; ok {
+			record.Metadata = MergeMetadata(current.Metadata, record.Metadata)
+			existing[record.ID] = record
+		}
+		merged[i] = record
+	}
+	return collection.Upsert(ctx, merged)
+}
+
+// MergeMetadata returns a copy of existing with the top-level keys of update
+// set, the way Postgres merges jsonb objects with ||.
+func MergeMetadata(existing, update map[string]any) map[string]any {
+	out := maps.Clone(existing)
+	if out == nil {
+		out = make(map[string]any, len(update))
+	}
+	maps.Copy(out, update)
+	return out
+}
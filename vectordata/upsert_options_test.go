@@ -0,0 +1,89 @@
+package vectordata
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mapCollection struct {
+	Collection
+	dimension int
+	records   map[string]Record
+	writes    []string
+}
+
+func (c *mapCollection) Name() string   { return "docs" }
+func (c *mapCollection) Dimension() int { return c.dimension }
+
+func (c *mapCollection) Get(_ context.Context, id string) (Record, error) {
+	record, ok := c.records[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (c *mapCollection) Insert(_ context.Context, records []Record) error {
+	c.writes = append(c.writes, "insert")
+	for _, record := range records {
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *mapCollection) Upsert(_ context.Context, records []Record) error {
+	c.writes = append(c.writes, "upsert")
+	for _, record := range records {
+		c.records[record.ID] = record
+	}
+	return nil
+}
+
+func (c *mapCollection) Delete(_ context.Context, ids []string) (int64, error) {
+	c.writes = append(c.writes, "delete")
+	var deleted int64
+	for _, id := range ids {
+		if _, ok := c.records[id]; ok {
+			delete(c.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestUpsertWithOptionsFallsBackToReadThenWrite(t *testing.T) {
+	// Arrange
+	collection := &mapCollection{records: map[string]Record{
+		"a": {ID: "a", Vector: []float32{1}, Metadata: map[string]any{"lang": "en", "rank": 1}},
+	}}
+
+	// Act
+	skipErr := UpsertWithOptions(context.Background(), collection, []Record{
+		{ID: "a", Vector: []float32{9}},
+		{ID: "b", Vector: []float32{2}},
+		{ID: "b", Vector: []float32{3}},
+	}, UpsertOptions{OnConflict: ConflictSkip})
+	mergeErr := UpsertWithOptions(context.Background(), collection, []Record{
+		{ID: "a", Vector: []float32{4}, Metadata: map[string]any{"rank": 2}},
+	}, UpsertOptions{OnConflict: ConflictMergeMetadata})
+	invalidErr := UpsertWithOptions(context.Background(), collection, nil, UpsertOptions{OnConflict: "newest"})
+
+	// Assert
+	if skipErr != nil || mergeErr != nil {
+		t.Fatalf("UpsertWithOptions: %v, %v", skipErr, mergeErr)
+	}
+	if collection.records["b"].Vector[0] != 2 {
+		t.Fatalf("expected the first b to be inserted, got %v", collection.records["b"])
+	}
+	a := collection.records["a"]
+	if a.Vector[0] != 4 || a.Metadata["lang"] != "en" || a.Metadata["rank"] != 2 {
+		t.Fatalf("expected a new vector with merged metadata, got %+v", a)
+	}
+	if len(collection.writes) != 2 || collection.writes[0] != "insert" || collection.writes[1] != "upsert" {
+		t.Fatalf("expected an insert then an upsert, got %v", collection.writes)
+	}
+	if !errors.Is(invalidErr, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", invalidErr)
+	}
+}
@@ -11,6 +11,17 @@ func (m DistanceMetric) Validate() error {
 	}
 }
 
+// Validate reports unsupported element types. The empty type is
+// ElementFloat32.
+func (t VectorElementType) Validate() error {
+	switch t {
+	case "", ElementFloat32, ElementFloat64, ElementInt8:
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported vector element type %q", ErrSchemaMismatch, t)
+	}
+}
+
 func normalizeMetric(metric DistanceMetric) DistanceMetric {
 	if metric == "" {
 		return DistanceCosine
@@ -0,0 +1,380 @@
+package vectordatatest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+// distanceTolerance absorbs float32 storage and backend arithmetic.
+const distanceTolerance = 1e-4
+
+// Options configures the conformance suite.
+type Options struct {
+	// NewStore returns the store under test. It is called once per test;
+	// collection names are unique, so stores may be shared.
+	NewStore func(t *testing.T) vectordata.VectorStore
+	// Metrics lists the metrics to check (default cosine, l2 and inner
+	// product).
+	Metrics []vectordata.DistanceMetric
+	// Skip lists tests the implementation does not support, by their path
+	// below the suite, e.g. "Filters/not_in" or "Thresholds".
+	Skip []string
+}
+
+var collectionSeq atomic.Uint64
+
+// Run runs the conformance suite as subtests of t.
+func Run(t *testing.T, opts Options) {
+	t.Helper()
+	if opts.NewStore == nil {
+		t.Fatal("vectordatatest: Options.NewStore is required")
+	}
+	if len(opts.Metrics) == 0 {
+		opts.Metrics = []vectordata.DistanceMetric{
+			vectordata.DistanceCosine,
+			vectordata.DistanceL2,
+			vectordata.DistanceInnerProduct,
+		}
+	}
+
+	s := suite{opts: opts, root: t.Name()}
+	s.run(t, "EnsureCollection", s.testEnsureCollection)
+	s.run(t, "CRUD", s.testCRUD)
+	s.run(t, "Errors", s.testErrors)
+	s.run(t, "Ordering", func(t *testing.T) {
+		for _, metric := range opts.Metrics {
+			s.run(t, string(metric), func(t *testing.T) { s.testOrdering(t, metric) })
+		}
+	})
+	s.run(t, "Filters", s.testFilters)
+	s.run(t, "Projections", s.testProjections)
+	s.run(t, "Thresholds", s.testThresholds)
+}
+
+type suite struct {
+	opts Options
+	root string
+}
+
+// run starts a subtest unless its path below the suite is in Options.Skip.
+func (s suite) run(t *testing.T, name string, fn func(t *testing.T)) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		path := strings.TrimPrefix(t.Name(), s.root+"/")
+		for _, skip := range s.opts.Skip {
+			if skip == path {
+				t.Skipf("skipped by Options.Skip")
+			}
+		}
+		fn(t)
+	})
+}
+
+// collection ensures a fresh collection with the fixture records.
+func (s suite) collection(t *testing.T, metric vectordata.DistanceMetric) vectordata.Collection {
+	t.Helper()
+	collection := s.emptyCollection(t, metric)
+	if err := collection.Insert(context.Background(), fixtureRecords()); err != nil {
+		t.Fatalf("Insert fixture records: %v", err)
+	}
+	return collection
+}
+
+func (s suite) emptyCollection(t *testing.T, metric vectordata.DistanceMetric) vectordata.Collection {
+	t.Helper()
+	name := fmt.Sprintf("conformance_%d", collectionSeq.Add(1))
+	collection, err := s.opts.NewStore(t).EnsureCollection(context.Background(), vectordata.CollectionSpec{
+		Name:      name,
+		Dimension: fixtureDimension,
+		Metric:    metric,
+	})
+	if err != nil {
+		t.Fatalf("EnsureCollection %s: %v", name, err)
+	}
+	return collection
+}
+
+func (s suite) testEnsureCollection(t *testing.T) {
+	// Arrange
+	metric := s.opts.Metrics[0]
+
+	// Act
+	collection := s.emptyCollection(t, metric)
+	count, err := collection.Count(context.Background(), nil)
+
+	// Assert
+	if collection.Dimension() != fixtureDimension || collection.Metric() != metric || collection.Name() == "" {
+		t.Fatalf("unexpected handle name=%q dimension=%d metric=%q", collection.Name(), collection.Dimension(), collection.Metric())
+	}
+	if err != nil || count != 0 {
+		t.Fatalf("expected an empty collection, got %d (%v)", count, err)
+	}
+}
+
+func (s suite) testCRUD(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := s.collection(t, s.opts.Metrics[0])
+	replacement := "replaced"
+	fixtures := fixtureRecords()
+
+	// Act
+	got, getErr := collection.Get(ctx, "r1")
+	duplicateErr := collection.Insert(ctx, []vectordata.Record{{ID: "r1", Vector: []float32{0, 1, 0}}})
+	upsertErr := collection.Upsert(ctx, []vectordata.Record{
+		{ID: "r2", Vector: []float32{0, 0, 1}, Metadata: map[string]any{"category": "updated"}, Content: &replacement},
+		{ID: "r6", Vector: []float32{1, 1, 1}},
+	})
+	upserted, upsertedErr := collection.Get(ctx, "r2")
+	deleted, deleteErr := collection.Delete(ctx, []string{"r3", "missing"})
+	_, deletedGetErr := collection.Get(ctx, "r3")
+	count, countErr := collection.Count(ctx, nil)
+	noop, noopErr := collection.Delete(ctx, nil)
+
+	// Assert
+	for name, err := range map[string]error{"Get": getErr, "Upsert": upsertErr, "Get upserted": upsertedErr, "Delete": deleteErr, "Count": countErr, "Delete nil": noopErr} {
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	assertRecord(t, got, fixtures[0])
+	if duplicateErr == nil {
+		t.Fatal("expected Insert of an existing ID to fail")
+	}
+	assertRecord(t, upserted, vectordata.Record{ID: "r2", Vector: []float32{0, 0, 1}, Metadata: map[string]any{"category": "updated"}, Content: &replacement})
+	if deleted != 1 {
+		t.Fatalf("expected Delete to count 1 existing record, got %d", deleted)
+	}
+	if !errors.Is(deletedGetErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after Delete, got %v", deletedGetErr)
+	}
+	if count != int64(len(fixtures)) {
+		t.Fatalf("expected count %d, got %d", len(fixtures), count)
+	}
+	if noop != 0 {
+		t.Fatalf("expected Delete with no IDs to delete nothing, got %d", noop)
+	}
+}
+
+func (s suite) testErrors(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := s.collection(t, s.opts.Metrics[0])
+	query := []float32{1, 0, 0}
+
+	// Act
+	_, missingErr := collection.Get(ctx, "missing")
+	insertDimErr := collection.Insert(ctx, []vectordata.Record{{ID: "bad", Vector: []float32{1, 0}}})
+	_, searchDimErr := collection.SearchByVector(ctx, []float32{1, 0}, 1, vectordata.SearchOptions{})
+	_, topKErr := collection.SearchByVector(ctx, query, 0, vectordata.SearchOptions{})
+	_, searchFilterErr := collection.SearchByVector(ctx, query, 1, vectordata.SearchOptions{Filter: vectordata.In(vectordata.Metadata("category"))})
+	_, countFilterErr := collection.Count(ctx, vectordata.And())
+
+	// Assert
+	if !errors.Is(missingErr, vectordata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing ID, got %v", missingErr)
+	}
+	if !errors.Is(insertDimErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch on Insert, got %v", insertDimErr)
+	}
+	if !errors.Is(searchDimErr, vectordata.ErrDimensionMismatch) {
+		t.Fatalf("expected ErrDimensionMismatch on SearchByVector, got %v", searchDimErr)
+	}
+	if topKErr == nil {
+		t.Fatal("expected an error for topK <= 0")
+	}
+	if !errors.Is(searchFilterErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for an empty IN, got %v", searchFilterErr)
+	}
+	if !errors.Is(countFilterErr, vectordata.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for an empty AND, got %v", countFilterErr)
+	}
+}
+
+func (s suite) testOrdering(t *testing.T, metric vectordata.DistanceMetric) {
+	// Arrange
+	collection := s.collection(t, metric)
+	expected := rankFixtures(metric, fixtureQuery)
+
+	// Act
+	results, err := collection.SearchByVector(context.Background(), fixtureQuery, len(expected), vectordata.SearchOptions{})
+	top, topErr := collection.SearchByVector(context.Background(), fixtureQuery, 2, vectordata.SearchOptions{})
+
+	// Assert
+	if err != nil || topErr != nil {
+		t.Fatalf("SearchByVector: %v, %v", err, topErr)
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, result := range results {
+		if result.Record.ID != expected[i].id {
+			t.Fatalf("expected %s at rank %d, got %s", expected[i].id, i, result.Record.ID)
+		}
+		if math.Abs(result.Distance-expected[i].distance) > distanceTolerance {
+			t.Fatalf("expected distance %v for %s, got %v", expected[i].distance, result.Record.ID, result.Distance)
+		}
+		if math.Abs(result.Score-vectordata.ScoreFromDistance(metric, result.Distance)) > distanceTolerance {
+			t.Fatalf("expected score %v for %s, got %v", vectordata.ScoreFromDistance(metric, result.Distance), result.Record.ID, result.Score)
+		}
+	}
+	if len(top) != 2 || top[0].Record.ID != expected[0].id || top[1].Record.ID != expected[1].id {
+		t.Fatalf("expected topK to keep the 2 nearest records, got %v", resultIDs(top))
+	}
+}
+
+func (s suite) testFilters(t *testing.T) {
+	collection := s.collection(t, s.opts.Metrics[0])
+	fixtures := fixtureRecords()
+
+	for _, tc := range fixtureFilters() {
+		s.run(t, tc.name, func(t *testing.T) {
+			// Arrange
+			expected := make([]string, 0)
+			for _, record := range fixtures {
+				matched, err := vectordata.MatchFilter(tc.filter, record)
+				if err != nil {
+					t.Fatalf("MatchFilter: %v", err)
+				}
+				if matched {
+					expected = append(expected, record.ID)
+				}
+			}
+
+			// Act
+			results, searchErr := collection.SearchByVector(context.Background(), fixtureQuery, len(fixtures), vectordata.SearchOptions{Filter: tc.filter})
+			count, countErr := collection.Count(context.Background(), tc.filter)
+
+			// Assert
+			if searchErr != nil || countErr != nil {
+				t.Fatalf("filter errors: %v, %v", searchErr, countErr)
+			}
+			ids := resultIDs(results)
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, expected) {
+				t.Fatalf("expected %v, got %v", expected, ids)
+			}
+			if count != int64(len(expected)) {
+				t.Fatalf("expected count %d, got %d", len(expected), count)
+			}
+		})
+	}
+}
+
+func (s suite) testProjections(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	collection := s.collection(t, s.opts.Metrics[0])
+	fixtures := fixtureRecords()
+
+	// Act
+	defaults, defaultErr := collection.SearchByVector(ctx, fixtures[0].Vector, 1, vectordata.SearchOptions{})
+	full, fullErr := collection.SearchByVector(ctx, fixtures[0].Vector, 1, vectordata.SearchOptions{
+		Projection: &vectordata.Projection{IncludeVector: true, IncludeMetadata: true, IncludeContent: true},
+	})
+	bare, bareErr := collection.SearchByVector(ctx, fixtures[0].Vector, 1, vectordata.SearchOptions{
+		Projection: &vectordata.Projection{},
+	})
+
+	// Assert
+	if defaultErr != nil || fullErr != nil || bareErr != nil {
+		t.Fatalf("SearchByVector: %v, %v, %v", defaultErr, fullErr, bareErr)
+	}
+	if len(defaults) != 1 || len(full) != 1 || len(bare) != 1 {
+		t.Fatalf("expected one result per search, got %d, %d, %d", len(defaults), len(full), len(bare))
+	}
+	if defaults[0].Record.Vector != nil || !jsonEqual(defaults[0].Record.Metadata, fixtures[0].Metadata) || !stringPtrEqual(defaults[0].Record.Content, fixtures[0].Content) {
+		t.Fatalf("expected metadata and content without vector by default, got %#v", defaults[0].Record)
+	}
+	assertRecord(t, full[0].Record, fixtures[0])
+	if bare[0].Record.ID != fixtures[0].ID || bare[0].Record.Vector != nil || len(bare[0].Record.Metadata) != 0 || bare[0].Record.Content != nil {
+		t.Fatalf("expected only the ID with an empty projection, got %#v", bare[0].Record)
+	}
+}
+
+func (s suite) testThresholds(t *testing.T) {
+	for _, metric := range s.opts.Metrics {
+		s.run(t, string(metric), func(t *testing.T) {
+			// Arrange
+			collection := s.collection(t, metric)
+			ranked := rankFixtures(metric, fixtureQuery)
+			threshold := (ranked[1].distance + ranked[2].distance) / 2
+
+			// Act
+			results, err := collection.SearchByVector(context.Background(), fixtureQuery, len(ranked), vectordata.SearchOptions{Threshold: &threshold})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("SearchByVector: %v", err)
+			}
+			ids := resultIDs(results)
+			if !reflect.DeepEqual(ids, []string{ranked[0].id, ranked[1].id}) {
+				t.Fatalf("expected records within distance %v, got %v", threshold, ids)
+			}
+		})
+	}
+}
+
+func assertRecord(t *testing.T, got, expected vectordata.Record) {
+	t.Helper()
+	if got.ID != expected.ID {
+		t.Fatalf("expected ID %q, got %q", expected.ID, got.ID)
+	}
+	if len(got.Vector) != len(expected.Vector) {
+		t.Fatalf("expected vector %v for %s, got %v", expected.Vector, expected.ID, got.Vector)
+	}
+	for i := range expected.Vector {
+		if math.Abs(float64(got.Vector[i]-expected.Vector[i])) > distanceTolerance {
+			t.Fatalf("expected vector %v for %s, got %v", expected.Vector, expected.ID, got.Vector)
+		}
+	}
+	if !jsonEqual(got.Metadata, expected.Metadata) {
+		t.Fatalf("expected metadata %v for %s, got %v", expected.Metadata, expected.ID, got.Metadata)
+	}
+	if !stringPtrEqual(got.Content, expected.Content) {
+		t.Fatalf("expected content %v for %s, got %v", expected.Content, expected.ID, got.Content)
+	}
+}
+
+// jsonEqual compares metadata by its JSON form, treating nil as empty, since
+// backends return decoded JSON (e.g. float64 for integers).
+func jsonEqual(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	if errLeft != nil || errRight != nil {
+		return false
+	}
+	var l, r any
+	if json.Unmarshal(left, &l) != nil || json.Unmarshal(right, &r) != nil {
+		return false
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+func stringPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func resultIDs(results []vectordata.SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Record.ID)
+	}
+	return ids
+}
@@ -0,0 +1,13 @@
+// Package vectordatatest provides a conformance suite for vectordata
+// implementations. A backend runs it from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		vectordatatest.Run(t, vectordatatest.Options{
+//			NewStore: func(t *testing.T) vectordata.VectorStore { return newStore(t) },
+//		})
+//	}
+//
+// The suite checks CRUD, ordering and distances for every metric, filters
+// (against vectordata.MatchFilter as the reference), projections, thresholds
+// and the sentinel errors. Writes must be visible to reads once they return.
+package vectordatatest
@@ -0,0 +1,64 @@
+package vectordatatest
+
+import (
+	"sort"
+
+	"github.com/gabisonia/go-vectorstore/vectordata"
+)
+
+const fixtureDimension = 3
+
+// fixtureQuery ranks the fixtures in a different order for each metric, with
+// no ties.
+var fixtureQuery = []float32{1, 0.05, 0}
+
+func fixtureRecords() []vectordata.Record {
+	alpha, beta, gamma := "alpha report", "beta notes", "gamma digest"
+	return []vectordata.Record{
+		{ID: "r1", Vector: []float32{1, 0, 0}, Metadata: map[string]any{"category": "news", "rank": 1, "author": map[string]any{"name": "ann"}}, Content: &alpha},
+		{ID: "r2", Vector: []float32{0.7, 0.3, 0}, Metadata: map[string]any{"category": "news", "rank": 5, "pinned": true}, Content: &beta},
+		{ID: "r3", Vector: []float32{0, 1, 0}, Metadata: map[string]any{"category": "blog", "rank": 10, "author": map[string]any{"name": "bob"}}, Content: &gamma},
+		{ID: "r4", Vector: []float32{0, 0, 2}, Metadata: map[string]any{"category": "blog", "rank": 7}},
+		{ID: "r5", Vector: []float32{0.5, 0.5, 0.5}, Metadata: map[string]any{"rank": 3}},
+	}
+}
+
+type filterCase struct {
+	name   string
+	filter vectordata.Filter
+}
+
+func fixtureFilters() []filterCase {
+	return []filterCase{
+		{"eq_metadata", vectordata.Eq(vectordata.Metadata("category"), "news")},
+		{"eq_nested", vectordata.Eq(vectordata.Metadata("author", "name"), "bob")},
+		{"eq_column", vectordata.Eq(vectordata.Column("id"), "r4")},
+		{"eq_bool", vectordata.Eq(vectordata.Metadata("pinned"), true)},
+		{"in", vectordata.In(vectordata.Metadata("category"), "blog", "other")},
+		{"gt_numeric", vectordata.Gt(vectordata.Metadata("rank"), 4)},
+		{"lt_numeric", vectordata.Lt(vectordata.Metadata("rank"), 5)},
+		{"exists", vectordata.Exists(vectordata.Metadata("author"))},
+		{"and", vectordata.And(vectordata.Eq(vectordata.Metadata("category"), "blog"), vectordata.Gt(vectordata.Metadata("rank"), 8))},
+		{"or", vectordata.Or(vectordata.Eq(vectordata.Column("id"), "r1"), vectordata.Eq(vectordata.Metadata("category"), "blog"))},
+		{"not_eq", vectordata.Not(vectordata.Eq(vectordata.Metadata("category"), "news"))},
+		{"not_in", vectordata.Not(vectordata.In(vectordata.Metadata("category"), "news", "blog"))},
+		{"not_exists", vectordata.Not(vectordata.Exists(vectordata.Metadata("category")))},
+	}
+}
+
+type rankedFixture struct {
+	id       string
+	distance float64
+}
+
+// rankFixtures orders the fixtures by reference distance: cosine distance,
+// Euclidean distance, or negative inner product.
+func rankFixtures(metric vectordata.DistanceMetric, query []float32) []rankedFixture {
+	records := fixtureRecords()
+	ranked := make([]rankedFixture, 0, len(records))
+	for _, record := range records {
+		ranked = append(ranked, rankedFixture{id: record.ID, distance: vectordata.Distance(metric, record.Vector, query)})
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].distance < ranked[j].distance })
+	return ranked
+}
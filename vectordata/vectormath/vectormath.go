@@ -0,0 +1,108 @@
+// Package vectormath implements the vector arithmetic the stores use to
+// recompute distances and normalize vectors in Go:
+//
+//	similarity := vectormath.CosineSimilarity(a, b)
+//	unit := vectormath.Normalize(v)
+//
+// Sums are accumulated in float64 over four independent lanes, which keeps
+// the loops free of bounds checks and lets the compiler pipeline them.
+// Functions taking two vectors panic if their lengths differ.
+package vectormath
+
+import "math"
+
+// Dot returns the inner product of a and b.
+func Dot(a, b []float32) float64 {
+	b = sameLength(a, b)
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += float64(a[i]) * float64(b[i])
+		s1 += float64(a[i+1]) * float64(b[i+1])
+		s2 += float64(a[i+2]) * float64(b[i+2])
+		s3 += float64(a[i+3]) * float64(b[i+3])
+	}
+	for ; i < len(a); i++ {
+		s0 += float64(a[i]) * float64(b[i])
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// SquaredL2 returns the squared Euclidean distance between a and b.
+func SquaredL2(a, b []float32) float64 {
+	b = sameLength(a, b)
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0 := float64(a[i]) - float64(b[i])
+		d1 := float64(a[i+1]) - float64(b[i+1])
+		d2 := float64(a[i+2]) - float64(b[i+2])
+		d3 := float64(a[i+3]) - float64(b[i+3])
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := float64(a[i]) - float64(b[i])
+		s0 += d * d
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// L2Distance returns the Euclidean distance between a and b.
+func L2Distance(a, b []float32) float64 {
+	return math.Sqrt(SquaredL2(a, b))
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float32) float64 {
+	return math.Sqrt(Dot(v, v))
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 if
+// either is a zero vector.
+func CosineSimilarity(a, b []float32) float64 {
+	b = sameLength(a, b)
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// CosineDistance returns 1 - CosineSimilarity(a, b), as pgvector's <=>
+// reports it; zero vectors are at distance 1 from everything.
+func CosineDistance(a, b []float32) float64 {
+	return 1 - CosineSimilarity(a, b)
+}
+
+// Normalize returns a copy of v scaled to unit length. A zero vector, which
+// has no direction, is copied unchanged.
+func Normalize(v []float32) []float32 {
+	out := make([]float32, len(v))
+	norm := Norm(v)
+	if norm == 0 {
+		copy(out, v)
+		return out
+	}
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// sameLength panics unless a and b have the same length and returns b
+// resliced so the compiler can drop bounds checks on b[i].
+func sameLength(a, b []float32) []float32 {
+	if len(a) != len(b) {
+		panic("vectormath: vectors have different lengths")
+	}
+	return b[:len(a)]
+}
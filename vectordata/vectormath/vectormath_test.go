@@ -0,0 +1,95 @@
+package vectormath
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDistances(t *testing.T) {
+	// Arrange
+	a := []float32{1, 2, 3, 4, 5}
+	b := []float32{5, 4, 3, 2, 1}
+
+	// Act
+	dot := Dot(a, b)
+	squared := SquaredL2(a, b)
+	l2 := L2Distance(a, b)
+	cosine := CosineDistance(a, b)
+	zero := CosineDistance(a, make([]float32, 5))
+
+	// Assert
+	if dot != 35 || squared != 40 || math.Abs(l2-math.Sqrt(40)) > 1e-12 {
+		t.Fatalf("unexpected dot %v, squared L2 %v or L2 %v", dot, squared, l2)
+	}
+	if math.Abs(cosine-(1-35.0/55)) > 1e-12 || zero != 1 {
+		t.Fatalf("unexpected cosine distances %v and %v", cosine, zero)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	// Arrange
+	v := []float32{3, 4}
+
+	// Act
+	unit := Normalize(v)
+	zero := Normalize([]float32{0, 0})
+
+	// Assert
+	if unit[0] != 0.6 || unit[1] != 0.8 || v[0] != 3 || math.Abs(Norm(unit)-1) > 1e-6 {
+		t.Fatalf("expected a unit copy of [3 4], got %v from %v", unit, v)
+	}
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Fatalf("expected the zero vector unchanged, got %v", zero)
+	}
+}
+
+func TestLengthMismatchPanics(t *testing.T) {
+	// Arrange
+	defer func() {
+		// Assert
+		if recover() == nil {
+			t.Fatal("expected a panic for vectors of different lengths")
+		}
+	}()
+
+	// Act
+	Dot([]float32{1, 2}, []float32{1})
+}
+
+func benchmarkVectors(dimension int) ([]float32, []float32) {
+	random := rand.New(rand.NewPCG(1, 2))
+	a, b := make([]float32, dimension), make([]float32, dimension)
+	for i := range a {
+		a[i], b[i] = random.Float32(), random.Float32()
+	}
+	return a, b
+}
+
+func BenchmarkDot1536(b *testing.B) {
+	x, y := benchmarkVectors(1536)
+	for b.Loop() {
+		Dot(x, y)
+	}
+}
+
+func BenchmarkSquaredL21536(b *testing.B) {
+	x, y := benchmarkVectors(1536)
+	for b.Loop() {
+		SquaredL2(x, y)
+	}
+}
+
+func BenchmarkCosineDistance1536(b *testing.B) {
+	x, y := benchmarkVectors(1536)
+	for b.Loop() {
+		CosineDistance(x, y)
+	}
+}
+
+func BenchmarkNormalize1536(b *testing.B) {
+	x, _ := benchmarkVectors(1536)
+	for b.Loop() {
+		Normalize(x)
+	}
+}